@@ -8,6 +8,7 @@ import "@openzeppelin/contracts-upgradeable/proxy/utils/Initializable.sol";
 import "@openzeppelin/contracts/token/ERC20/IERC20.sol";
 import "@openzeppelin/contracts/token/ERC20/utils/SafeERC20.sol";
 import "@openzeppelin/contracts/utils/cryptography/ECDSA.sol";
+import "@openzeppelin/contracts/utils/cryptography/MerkleProof.sol";
 import "@openzeppelin/contracts-upgradeable/utils/cryptography/EIP712Upgradeable.sol";
 
 /**
@@ -61,6 +62,18 @@ contract OptimizedSettlement is
         CompactOrder[] makerOrders;
     }
 
+    // 按订单去重的批量成交结构：同一订单在批次中只传一次，成交通过下标引用订单
+    struct IndexedBatchFill {
+        CompactOrder[] orders;
+        bytes32[] orderHashes;
+        bytes[] signatures;
+        uint16[] takerIndexes;
+        uint16[] makerIndexes;
+        uint128[] prices;
+        uint128[] amounts;
+        uint8[] takerSides;
+    }
+
     // 优化的成交记录 (减少存储)
     struct CompactFillRecord {
         bytes32 takerHash;
@@ -108,6 +121,9 @@ contract OptimizedSettlement is
     bool public emergencyPaused;
     mapping(address => bool) public tokenBlacklist;
 
+    // 默克尔批次：仅提交成交默克尔根，逐笔明细与包含证明由链下服务提供
+    mapping(bytes32 => uint256) public settlementRoots; // root => 提交时间
+
     // ==== 事件优化 ====
     
     event BatchTradeSettled(
@@ -120,6 +136,8 @@ contract OptimizedSettlement is
     event EmergencyPauseToggled(bool paused, string reason);
     
     event TokenBlacklisted(address token, bool blacklisted);
+
+    event SettlementRootSubmitted(bytes32 indexed root, uint256 fillCount, uint256 timestamp);
     
     event FeeRateUpdated(
         string feeType,
@@ -209,6 +227,104 @@ contract OptimizedSettlement is
         emit BatchTradeSettled(fillHashesArray, totalVolume, totalProtocolFees, gasUsed);
     }
     
+    /**
+     * @dev 按订单去重的批量清算
+     * 同一maker订单被多笔成交引用时只传一次订单结构和签名，签名也只验证一次，减少calldata和Gas
+     */
+    function batchSettleTradesIndexed(
+        IndexedBatchFill calldata fills
+    ) external
+        whenNotPaused
+        notEmergencyPaused
+        nonReentrant
+        validBatchSize(fills.takerIndexes.length)
+    {
+        uint256 gasStart = gasleft();
+        require(_validateIndexedBatchArrays(fills), "Array length mismatch");
+
+        // 每个订单只验证一次签名和有效性
+        for (uint256 i = 0; i < fills.orders.length; i++) {
+            _validateOrderSignature(fills.orders[i], fills.signatures[i]);
+        }
+
+        uint256 totalVolume = 0;
+        uint256 totalProtocolFees = 0;
+        bytes32[] memory fillHashesArray = new bytes32[](fills.takerIndexes.length);
+
+        for (uint256 i = 0; i < fills.takerIndexes.length; i++) {
+            require(gasleft() > MAX_GAS_PER_FILL, "Insufficient gas");
+
+            uint256 takerIndex = fills.takerIndexes[i];
+            uint256 makerIndex = fills.makerIndexes[i];
+            require(takerIndex < fills.orders.length && makerIndex < fills.orders.length, "Invalid order index");
+
+            (bytes32 fillHash, uint256 volume, uint256 fees) = _executeTrade(
+                fills.orderHashes[takerIndex],
+                fills.orderHashes[makerIndex],
+                fills.prices[i],
+                fills.amounts[i],
+                fills.takerSides[i],
+                fills.orders[takerIndex],
+                fills.orders[makerIndex]
+            );
+
+            fillHashesArray[i] = fillHash;
+            totalVolume += volume;
+            totalProtocolFees += fees;
+        }
+
+        uint256 gasUsed = gasStart - gasleft();
+
+        emit BatchTradeSettled(fillHashesArray, totalVolume, totalProtocolFees, gasUsed);
+    }
+
+    /**
+     * @dev 提交一批成交的默克尔根
+     * 只记录根，不逐笔转移资产；用户和监督方通过链下服务获取包含证明后调用 verifyFillInclusion 核验
+     */
+    function submitSettlementRoot(
+        bytes32 root,
+        uint256 fillCount
+    ) external
+        onlyOwner
+        whenNotPaused
+        notEmergencyPaused
+    {
+        require(root != bytes32(0), "Empty root");
+        require(fillCount > 0, "Empty batch");
+        require(settlementRoots[root] == 0, "Root already submitted");
+
+        settlementRoots[root] = block.timestamp;
+        emit SettlementRootSubmitted(root, fillCount, block.timestamp);
+    }
+
+    /**
+     * @dev 成交的默克尔叶子（双重哈希，避免与内部节点混淆）
+     */
+    function fillLeaf(
+        bytes16 fillId,
+        bytes32 takerOrderHash,
+        bytes32 makerOrderHash,
+        uint128 price,
+        uint128 amount,
+        uint8 takerSide
+    ) public pure returns (bytes32) {
+        return keccak256(bytes.concat(keccak256(abi.encode(
+            fillId, takerOrderHash, makerOrderHash, price, amount, takerSide
+        ))));
+    }
+
+    /**
+     * @dev 核验成交是否包含在已提交的默克尔根中（节点按排序后的哈希对计算）
+     */
+    function verifyFillInclusion(
+        bytes32 root,
+        bytes32 leaf,
+        bytes32[] calldata proof
+    ) external view returns (bool) {
+        return settlementRoots[root] != 0 && MerkleProof.verifyCalldata(proof, root, leaf);
+    }
+
     /**
      * @dev 单笔交易清算（向后兼容）
      */
@@ -382,6 +498,20 @@ contract OptimizedSettlement is
         );
     }
     
+    function _validateIndexedBatchArrays(IndexedBatchFill calldata fills) internal pure returns (bool) {
+        uint256 len = fills.takerIndexes.length;
+        uint256 orderCount = fills.orders.length;
+        return (
+            orderCount <= MAX_BATCH_SIZE * 2 &&
+            fills.orderHashes.length == orderCount &&
+            fills.signatures.length == orderCount &&
+            fills.makerIndexes.length == len &&
+            fills.prices.length == len &&
+            fills.amounts.length == len &&
+            fills.takerSides.length == len
+        );
+    }
+
     function _validateOrderSignature(
         CompactOrder calldata order,
         bytes calldata signature
@@ -479,7 +479,7 @@ const OrderBookTrade = () => {
       
       const domain = {
         name: 'OrderBook DEX',
-        version: '1.0',
+        version: '1',
         chainId: await signer.provider.getNetwork().then(n => n.chainId),
         verifyingContract: '0xf4B146FbA71F41E0592668ffbF264F1D186b2Ca8' // 从config.yaml获取的合约地址
       }
@@ -8,7 +8,7 @@ class OrderSigner {
   constructor(chainId = 31337, contractAddress = '') {
     this.domain = {
       name: 'OrderBook DEX',
-      version: '1.0',
+      version: '1',
       chainId,
       verifyingContract: contractAddress
     };
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/viper"
+
+	"orderbook-engine/internal/analytics"
+)
+
+// initAnalyticsSink 按 analytics.driver 创建成交分析存储
+func initAnalyticsSink() (analytics.Sink, error) {
+	switch driver := viper.GetString("analytics.driver"); driver {
+	case "timescale":
+		return analytics.NewTimescaleSink(viper.GetString("analytics.timescale.dsn"))
+	case "clickhouse":
+		return analytics.NewClickHouseSink(analytics.ClickHouseConfig{
+			URL:      viper.GetString("analytics.clickhouse.url"),
+			Database: viper.GetString("analytics.clickhouse.database"),
+			Username: viper.GetString("analytics.clickhouse.username"),
+			Password: viper.GetString("analytics.clickhouse.password"),
+			Timeout:  viper.GetDuration("analytics.insert_timeout"),
+		})
+	case "memory":
+		return analytics.NewMemorySink(), nil
+	default:
+		return nil, fmt.Errorf("unsupported analytics driver %q", driver)
+	}
+}
+
+// analyticsConfig 由配置生成成交归档配置
+func analyticsConfig() analytics.Config {
+	return analytics.Config{
+		BatchSize:     viper.GetInt("analytics.batch_size"),
+		FlushInterval: viper.GetDuration("analytics.flush_interval"),
+		InsertTimeout: viper.GetDuration("analytics.insert_timeout"),
+		RetryBackoff:  viper.GetDuration("analytics.retry_backoff"),
+		MaxBackoff:    viper.GetDuration("analytics.max_backoff"),
+	}
+}
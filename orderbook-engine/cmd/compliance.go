@@ -0,0 +1,36 @@
+package main
+
+import (
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+
+	"orderbook-engine/internal/compliance"
+)
+
+// initCompliance 初始化地址合规筛查：compliance.denylist_file 为本地拒绝名单，compliance.api.url 为外部筛查接口
+func initCompliance(logger *logrus.Logger) *compliance.Checker {
+	var screeners []compliance.Screener
+	if path := viper.GetString("compliance.denylist_file"); path != "" {
+		denylist, err := compliance.NewDenylist(path)
+		if err != nil {
+			logger.WithError(err).Fatal("Failed to load compliance denylist")
+		}
+		screeners = append(screeners, denylist)
+		logger.WithField("addresses", denylist.Len()).Info("Compliance denylist loaded")
+	}
+	if url := viper.GetString("compliance.api.url"); url != "" {
+		screeners = append(screeners, compliance.NewAPIScreener(url, viper.GetString("compliance.api.key"), viper.GetDuration("compliance.timeout")))
+	}
+
+	config := compliance.Config{
+		CacheTTL: viper.GetDuration("compliance.cache_ttl"),
+		Timeout:  viper.GetDuration("compliance.timeout"),
+		FailOpen: viper.GetBool("compliance.fail_open"),
+	}
+	logger.WithFields(logrus.Fields{
+		"sources":   len(screeners),
+		"cache_ttl": config.CacheTTL.String(),
+		"fail_open": config.FailOpen,
+	}).Info("Compliance screening enabled")
+	return compliance.NewChecker(config, logger, screeners...)
+}
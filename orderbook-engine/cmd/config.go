@@ -0,0 +1,315 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/fsnotify/fsnotify"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+
+	"orderbook-engine/internal/circuitbreaker"
+	"orderbook-engine/internal/matching"
+	"orderbook-engine/internal/riskcontrol"
+	"orderbook-engine/internal/txsigner"
+	"orderbook-engine/internal/wallet"
+	"orderbook-engine/internal/websocket"
+)
+
+// validateConfig 启动前校验配置，一次报告全部问题，避免在子系统初始化深处失败
+func validateConfig() error {
+	var errs []error
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+	positive := func(key string) {
+		check(viper.GetDuration(key) > 0, "%s must be a positive duration, got %q", key, viper.GetString(key))
+	}
+
+	if _, err := logrus.ParseLevel(viper.GetString("log.level")); err != nil {
+		errs = append(errs, fmt.Errorf("log.level: %w", err))
+	}
+	positive("server.read_timeout")
+	positive("server.write_timeout")
+	positive("trading.expiry_sweep_interval")
+	positive("drain.timeout")
+
+	configs, err := chainConfigs()
+	if err != nil {
+		errs = append(errs, fmt.Errorf("chains: %w", err))
+	}
+	for _, chain := range configs {
+		label := fmt.Sprintf("chain %d (%s)", chain.ChainID, chain.Name)
+		check(chain.ChainID != 0, "chain %q: chain_id is required", chain.Name)
+		check(chain.ContractAddress == "" || common.IsHexAddress(chain.ContractAddress), "%s: invalid contract_address %q", label, chain.ContractAddress)
+		check(chain.SettlementAddress == "" || common.IsHexAddress(chain.SettlementAddress), "%s: invalid settlement_address %q", label, chain.SettlementAddress)
+		if chain.RPCURL != "" && viper.GetBool("settlement.enabled") {
+			check(chain.SettlementAddress != "", "%s: settlement_address is required when settlement is enabled", label)
+			check(chain.PrivateKey != "" || viper.GetString("settlement.signer.backend") != txsigner.BackendLocal,
+				"%s: private_key is required when settlement is enabled with the local signer", label)
+		}
+	}
+
+	switch viper.GetString("settlement.signer.backend") {
+	case txsigner.BackendLocal:
+	case txsigner.BackendKMS:
+		check(viper.GetString("settlement.signer.kms.key_id") != "", "settlement.signer.kms.key_id is required for the kms signer")
+	case txsigner.BackendVault:
+		check(viper.GetString("settlement.signer.vault.path") != "", "settlement.signer.vault.path is required for the vault signer")
+	default:
+		check(false, "settlement.signer.backend must be local, kms or vault, got %q", viper.GetString("settlement.signer.backend"))
+	}
+	positive("settlement.signer.timeout")
+	positive("settlement.key_rotation_timeout")
+
+	for _, chain := range configs {
+		if _, err := gasBudgetConfig(chain.ChainID); err != nil {
+			errs = append(errs, fmt.Errorf("chain %d: %w", chain.ChainID, err))
+		}
+	}
+
+	check(!viper.GetBool("settlement.merkle.enabled") || viper.GetString("settlement.merkle.store_path") != "", "settlement.merkle.store_path is required when merkle settlement is enabled")
+
+	check(viper.GetFloat64("simulation.max_seed_amount") >= 0, "simulation.max_seed_amount must not be negative")
+	if viper.GetBool("referral.enabled") {
+		if err := referralConfig().Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("referral: %w", err))
+		}
+	}
+	if viper.GetBool("rewards.enabled") {
+		if err := rewardsConfig().Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("rewards: %w", err))
+		}
+	}
+	if viper.GetBool("mm_obligations.enabled") {
+		positive("mm_obligations.sample_interval")
+		positive("mm_obligations.report_period")
+		check(viper.GetUint64("mm_obligations.penalty_fee_bps") <= 1000, "mm_obligations.penalty_fee_bps must not exceed 1000")
+		if _, err := obligationList(); err != nil {
+			errs = append(errs, fmt.Errorf("mm_obligations.market_makers: %w", err))
+		}
+	}
+
+	if viper.GetBool("analytics.enabled") {
+		switch driver := viper.GetString("analytics.driver"); driver {
+		case "timescale":
+			check(viper.GetString("analytics.timescale.dsn") != "", "analytics.timescale.dsn is required when analytics.driver is timescale")
+		case "clickhouse":
+			check(viper.GetString("analytics.clickhouse.url") != "", "analytics.clickhouse.url is required when analytics.driver is clickhouse")
+		case "memory":
+		default:
+			errs = append(errs, fmt.Errorf("analytics.driver: unsupported driver %q", driver))
+		}
+		check(viper.GetInt("analytics.batch_size") > 0, "analytics.batch_size must be positive")
+		positive("analytics.flush_interval")
+	}
+
+	positive("health.timeout")
+
+	if viper.GetBool("system.halted") {
+		check(viper.GetString("system.halt_reason") != "", "system.halt_reason is required when system.halted is set")
+	}
+
+	if viper.GetBool("lifecycle.enabled") {
+		switch driver := viper.GetString("lifecycle.driver"); driver {
+		case "file":
+			check(viper.GetString("lifecycle.file.dir") != "", "lifecycle.file.dir is required when lifecycle.driver is file")
+		case "s3":
+			check(viper.GetString("lifecycle.s3.endpoint") != "", "lifecycle.s3.endpoint is required when lifecycle.driver is s3")
+			check(viper.GetString("lifecycle.s3.bucket") != "", "lifecycle.s3.bucket is required when lifecycle.driver is s3")
+		default:
+			errs = append(errs, fmt.Errorf("lifecycle.driver: unsupported driver %q", driver))
+		}
+		check(viper.GetInt("lifecycle.retention_days") > 0, "lifecycle.retention_days must be positive")
+		check(viper.GetInt("lifecycle.batch_size") > 0, "lifecycle.batch_size must be positive")
+		positive("lifecycle.interval")
+	}
+
+	for _, concern := range storageConcerns {
+		driver := storageDriver(concern)
+		_, exists := storageDrivers[driver]
+		check(exists, "storage.%s.driver: unsupported storage driver %q", concern, driver)
+	}
+
+	if err := priceBands().Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("trading.price_band: %w", err))
+	}
+
+	if _, err := matching.ParseOverflowPolicy(viper.GetString("matching.event_overflow")); err != nil {
+		errs = append(errs, fmt.Errorf("matching.event_overflow: %w", err))
+	}
+	check(viper.GetInt("matching.event_buffer") >= 0, "matching.event_buffer must not be negative")
+
+	if _, err := websocket.ParseSlowConsumerPolicy(viper.GetString("websocket.slow_consumer_policy")); err != nil {
+		errs = append(errs, fmt.Errorf("websocket.slow_consumer_policy: %w", err))
+	}
+	if viper.GetBool("websocket.bridge.enabled") {
+		check(viper.GetString("websocket.bridge.nats_url") != "", "websocket.bridge.nats_url is required when websocket.bridge.enabled is true")
+	}
+
+	if viper.GetBool("eventbus.enabled") {
+		switch driver := viper.GetString("eventbus.driver"); driver {
+		case "kafka":
+			check(len(viper.GetStringSlice("eventbus.kafka.brokers")) > 0, "eventbus.kafka.brokers is required when eventbus.driver is kafka")
+		case "nats":
+			check(viper.GetString("eventbus.nats.url") != "", "eventbus.nats.url is required when eventbus.driver is nats")
+		default:
+			check(false, "eventbus.driver must be kafka or nats, got %q", driver)
+		}
+	}
+
+	if viper.GetBool("leader.enabled") {
+		// 进程内租约无法在多个实例之间互斥，每个实例都会当选主实例，只能用于测试
+		switch driver := viper.GetString("leader.driver"); driver {
+		case "postgres":
+			check(viper.GetString("leader.postgres_dsn") != "", "leader.postgres_dsn is required when leader.driver is postgres")
+		case "memory":
+			check(false, "leader.driver memory only works within one process, use postgres for leader election")
+		default:
+			check(false, "leader.driver must be postgres, got %q", driver)
+		}
+		positive("leader.renew_interval")
+		positive("leader.sync_interval")
+		check(viper.GetDuration("leader.lease_ttl") > viper.GetDuration("leader.renew_interval"), "leader.lease_ttl must be longer than leader.renew_interval")
+	}
+
+	if viper.GetBool("orderbook_history.enabled") {
+		positive("orderbook_history.interval")
+		check(viper.GetInt("orderbook_history.depth") > 0, "orderbook_history.depth must be positive")
+	}
+
+	check(!viper.GetBool("liquidity.enabled") || viper.GetString("liquidity.account") != "", "liquidity.account is required when liquidity is enabled")
+	if err := feeSchedule().Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("trading: %w", err))
+	}
+	check(len(viper.GetStringSlice("trading.halted_pairs")) == 0 || viper.GetBool("circuit_breaker.enabled"), "trading.halted_pairs requires circuit_breaker.enabled")
+
+	if _, err := preflightConfig(); err != nil {
+		errs = append(errs, err)
+	}
+	positive("preflight.timeout")
+	if viper.GetBool("compliance.enabled") {
+		check(viper.GetString("compliance.denylist_file") != "" || viper.GetString("compliance.api.url") != "",
+			"compliance.denylist_file or compliance.api.url is required when compliance is enabled")
+		positive("compliance.timeout")
+	}
+
+	check(viper.GetFloat64("risk.max_exposure") >= 0, "risk.max_exposure must not be negative")
+	if config, err := orderFlowConfig(); err != nil {
+		errs = append(errs, err)
+	} else if viper.GetBool("risk.enabled") {
+		// 撤单率按风控统计窗口计算，最长的订单流窗口决定保留的数据
+		longest := config.Windows[0]
+		for _, window := range config.Windows {
+			if window > longest {
+				longest = window
+			}
+		}
+		check(longest >= riskConfig().CancelRatioWindow, "orderflow.windows must include a window of at least %s for the risk cancel ratio check", riskConfig().CancelRatioWindow)
+	}
+	for pair, pairConfig := range riskPairConfigs() {
+		if err := pairConfig.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("risk.pairs.%s: %w", strings.ToLower(pair), err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// configReloader 配置热更新
+// 配置文件变化时重新应用风控限额、提现手续费、交易对暂停状态与日志级别，其余配置仍需重启生效
+type configReloader struct {
+	mu       sync.Mutex
+	logger   *logrus.Logger
+	risk     *riskcontrol.RiskController // 可选，为空时不更新风控配置
+	balances *wallet.BalanceManager
+	breaker  *circuitbreaker.CircuitBreaker // 可选，为空时不支持按配置暂停交易对
+	halted   map[string]bool                // 由 trading.halted_pairs 暂停的交易对
+}
+
+// newConfigReloader 创建配置热更新
+func newConfigReloader(risk *riskcontrol.RiskController, balances *wallet.BalanceManager, breaker *circuitbreaker.CircuitBreaker, logger *logrus.Logger) *configReloader {
+	return &configReloader{
+		logger:   logger,
+		risk:     risk,
+		balances: balances,
+		breaker:  breaker,
+		halted:   make(map[string]bool),
+	}
+}
+
+// Start 应用配置中的交易对暂停状态并监听配置文件变化，校验失败的配置不生效
+func (r *configReloader) Start() {
+	r.mu.Lock()
+	r.applyHaltedPairs()
+	r.mu.Unlock()
+
+	if viper.ConfigFileUsed() == "" {
+		r.logger.Warn("No config file loaded - config hot reload disabled")
+		return
+	}
+
+	viper.OnConfigChange(func(event fsnotify.Event) {
+		if err := validateConfig(); err != nil {
+			r.logger.WithError(err).WithField("file", event.Name).Error("Config reload rejected")
+			return
+		}
+		r.Apply()
+		r.logger.WithField("file", event.Name).Info("🔄 Config reloaded")
+	})
+	viper.WatchConfig()
+}
+
+// Apply 应用可热更新的配置
+func (r *configReloader) Apply() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if level, err := logrus.ParseLevel(viper.GetString("log.level")); err == nil {
+		r.logger.SetLevel(level)
+	}
+
+	// 配置文件中的交易对覆盖重新应用，通过管理接口设置的其他交易对覆盖保留
+	if r.risk != nil {
+		r.risk.SetConfig(riskConfig())
+		for pair, pairConfig := range riskPairConfigs() {
+			if err := r.risk.SetPairConfig(pair, pairConfig); err != nil {
+				r.logger.WithError(err).WithField("trading_pair", pair).Error("Invalid pair risk config")
+			}
+		}
+	}
+
+	if err := applyWithdrawalFees(r.balances); err != nil {
+		r.logger.WithError(err).Error("Invalid withdrawal fee config")
+	}
+
+	r.applyHaltedPairs()
+}
+
+// applyHaltedPairs 暂停新加入 trading.halted_pairs 的交易对，恢复已移出的交易对（调用方持有 r.mu）
+func (r *configReloader) applyHaltedPairs() {
+	halted := make(map[string]bool)
+	for _, pair := range viper.GetStringSlice("trading.halted_pairs") {
+		halted[strings.ToUpper(pair)] = true
+	}
+	if r.breaker == nil {
+		return
+	}
+
+	for pair := range halted {
+		if !r.halted[pair] {
+			r.breaker.Halt(pair, "config", 0)
+		}
+	}
+	for pair := range r.halted {
+		if !halted[pair] {
+			r.breaker.Resume(pair, "config")
+		}
+	}
+	r.halted = halted
+}
@@ -0,0 +1,116 @@
+// enginebench 在进程内直接压测撮合引擎：按幂律深度建簿，执行撤单密集的做市商式混合负载，
+// 输出挂单、撤单、撮合的延迟分位数（JSON），可导出 pprof，超过阈值时以退出码 1 结束，便于 CI 回归
+//
+//	go run ./cmd/enginebench -ops 500000 -cpuprofile cpu.out -max-cancel-p99 50us -min-throughput 100000
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"runtime"
+
+	"github.com/sirupsen/logrus"
+
+	"orderbook-engine/internal/enginebench"
+	"orderbook-engine/internal/matching"
+)
+
+func main() {
+	defaults := enginebench.DefaultConfig()
+	pair := flag.String("pair", defaults.TradingPair, "trading pair name")
+	mid := flag.Int64("mid", defaults.MidPrice, "initial mid price (integer price units)")
+	tick := flag.Int64("tick", defaults.TickSize, "price tick size")
+	levels := flag.Int("levels", defaults.Levels, "initial price levels per side")
+	topOrders := flag.Int("top-orders", defaults.TopOrders, "orders at the best level; deeper levels decay by power law")
+	exponent := flag.Float64("depth-exponent", defaults.DepthExponent, "power-law exponent of depth decay")
+	maxAmount := flag.Int64("max-amount", defaults.MaxAmount, "maximum order amount (integer units)")
+	users := flag.Int("users", defaults.Users, "number of distinct order owners")
+	ops := flag.Int("ops", defaults.Operations, "operations after the book is built")
+	cancelRatio := flag.Float64("cancel-ratio", defaults.CancelRatio, "fraction of cancel operations")
+	crossRatio := flag.Float64("cross-ratio", defaults.CrossRatio, "fraction of crossing limit orders")
+	marketRatio := flag.Float64("market-ratio", defaults.MarketRatio, "fraction of market orders")
+	seed := flag.Int64("seed", defaults.Seed, "random seed for a reproducible operation sequence")
+	cpuProfile := flag.String("cpuprofile", "", "write a CPU profile of the measured run to this file")
+	memProfile := flag.String("memprofile", "", "write a heap profile after the run to this file")
+	allocsProfile := flag.String("allocsprofile", "", "write an allocation profile after the run to this file")
+	mutexProfile := flag.String("mutexprofile", "", "write a mutex contention profile after the run to this file")
+	maxAddP99 := flag.Duration("max-add-p99", 0, "exit 1 when add p99 latency exceeds this value, 0 disables")
+	maxCancelP99 := flag.Duration("max-cancel-p99", 0, "exit 1 when cancel p99 latency exceeds this value, 0 disables")
+	maxMatchP99 := flag.Duration("max-match-p99", 0, "exit 1 when match p99 latency exceeds this value, 0 disables")
+	minThroughput := flag.Float64("min-throughput", 0, "exit 1 when operations per second fall below this value, 0 disables")
+	flag.Parse()
+
+	logger := logrus.New()
+	logger.SetOutput(os.Stderr)
+	// 引擎逐笔日志会主导延迟，压测时只保留告警
+	logger.SetLevel(logrus.WarnLevel)
+
+	config := enginebench.Config{
+		TradingPair:   *pair,
+		MidPrice:      *mid,
+		TickSize:      *tick,
+		Levels:        *levels,
+		TopOrders:     *topOrders,
+		DepthExponent: *exponent,
+		MaxAmount:     *maxAmount,
+		Users:         *users,
+		Operations:    *ops,
+		CancelRatio:   *cancelRatio,
+		CrossRatio:    *crossRatio,
+		MarketRatio:   *marketRatio,
+		Seed:          *seed,
+	}
+	workload, err := enginebench.NewWorkload(matching.NewMatchingEngine(logger), config)
+	if err != nil {
+		logger.WithError(err).Fatal("Invalid benchmark configuration")
+	}
+
+	if *mutexProfile != "" {
+		runtime.SetMutexProfileFraction(1)
+	}
+	if *cpuProfile != "" {
+		stop, err := enginebench.StartCPUProfile(*cpuProfile)
+		if err != nil {
+			logger.WithError(err).Fatal("Failed to start CPU profile")
+		}
+		defer func() {
+			if err := stop(); err != nil {
+				logger.WithError(err).Error("Failed to write CPU profile")
+			}
+		}()
+	}
+
+	report, err := workload.Run()
+	if err != nil {
+		logger.WithError(err).Fatal("Benchmark failed")
+	}
+
+	for name, path := range map[string]string{"heap": *memProfile, "allocs": *allocsProfile, "mutex": *mutexProfile} {
+		if path == "" {
+			continue
+		}
+		if err := enginebench.WriteProfile(name, path); err != nil {
+			logger.WithError(err).WithField("profile", name).Error("Failed to write profile")
+		}
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		logger.WithError(err).Error("Failed to write benchmark report")
+	}
+
+	violations := report.Violations(enginebench.Thresholds{
+		AddP99:        *maxAddP99,
+		CancelP99:     *maxCancelP99,
+		MatchP99:      *maxMatchP99,
+		MinThroughput: *minThroughput,
+	})
+	for _, violation := range violations {
+		logger.Error(violation)
+	}
+	if len(violations) > 0 {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+
+	"orderbook-engine/internal/gasbudget"
+	"orderbook-engine/internal/types"
+	"orderbook-engine/internal/websocket"
+)
+
+// gasBudgetConfig 由配置生成链的结算Gas支出预算：settlement.gas_budget.* 为默认值，
+// settlement.gas_budget.chains.<chain_id>.* 按链覆盖（不同链的Gas成本差异很大）
+func gasBudgetConfig(chainID uint64) (gasbudget.Config, error) {
+	key := func(name string) string {
+		if chainKey := fmt.Sprintf("settlement.gas_budget.chains.%d.%s", chainID, name); viper.IsSet(chainKey) {
+			return chainKey
+		}
+		return "settlement.gas_budget." + name
+	}
+	amount := func(name string) (decimal.Decimal, error) {
+		value := viper.GetString(key(name))
+		if value == "" {
+			return decimal.Zero, nil
+		}
+		budget, err := decimal.NewFromString(value)
+		if err != nil || budget.IsNegative() {
+			return decimal.Zero, fmt.Errorf("%s must be a non-negative ETH amount, got %q", key(name), value)
+		}
+		return budget, nil
+	}
+
+	hourly, err := amount("hourly_eth")
+	if err != nil {
+		return gasbudget.Config{}, err
+	}
+	daily, err := amount("daily_eth")
+	if err != nil {
+		return gasbudget.Config{}, err
+	}
+	action, err := gasbudget.ParseAction(viper.GetString(key("action")))
+	if err != nil {
+		return gasbudget.Config{}, fmt.Errorf("%s: %w", key("action"), err)
+	}
+	slowInterval := viper.GetDuration(key("slow_interval"))
+	if action == gasbudget.ActionSlow && slowInterval <= 0 {
+		return gasbudget.Config{}, fmt.Errorf("%s must be a positive duration, got %q", key("slow_interval"), viper.GetString(key("slow_interval")))
+	}
+	return gasbudget.Config{Hourly: hourly, Daily: daily, Action: action, SlowInterval: slowInterval}, nil
+}
+
+// initGasBudget 创建链的结算Gas支出预算，未设置预算时返回 nil
+// 超出预算与恢复时向管理员主题 admin.alerts 推送告警
+func initGasBudget(chainID uint64, wsHub *websocket.Hub, logger *logrus.Logger) *gasbudget.Tracker {
+	config, err := gasBudgetConfig(chainID)
+	if err != nil {
+		logger.WithError(err).Fatal("Invalid settlement gas budget config")
+	}
+	if !config.Enabled() {
+		return nil
+	}
+
+	tracker := gasbudget.NewTracker(chainID, config)
+	tracker.SetChangeHandler(func(status gasbudget.Status) {
+		alert := &types.AdminAlert{
+			Type:      types.AdminAlertGasBudgetRestored,
+			ChainID:   chainID,
+			Message:   fmt.Sprintf("Settlement gas spend on chain %d is back within budget", chainID),
+			Details:   status,
+			Timestamp: time.Now(),
+		}
+		fields := logrus.Fields{
+			"chain_id":      chainID,
+			"hourly_spent":  status.HourlySpent,
+			"hourly_budget": status.HourlyBudget,
+			"daily_spent":   status.DailySpent,
+			"daily_budget":  status.DailyBudget,
+		}
+		if status.Exceeded {
+			alert.Type = types.AdminAlertGasBudgetExceeded
+			alert.Message = fmt.Sprintf("Settlement gas spend on chain %d exceeded the %s budget, settlement action: %s", chainID, status.Window, status.Action)
+			logger.WithFields(fields).Warn("Settlement gas budget exceeded")
+		} else {
+			logger.WithFields(fields).Info("Settlement gas budget restored")
+		}
+		wsHub.PublishAdminAlert(alert)
+	})
+
+	logger.WithFields(logrus.Fields{
+		"chain_id":   chainID,
+		"hourly_eth": config.Hourly,
+		"daily_eth":  config.Daily,
+		"action":     config.Action,
+	}).Info("Settlement gas budget enabled")
+	return tracker
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/spf13/viper"
+
+	"orderbook-engine/internal/chains"
+	"orderbook-engine/internal/health"
+	"orderbook-engine/internal/matching"
+	"orderbook-engine/internal/storage"
+	"orderbook-engine/internal/websocket"
+)
+
+// initHealthChecker 注册各依赖组件的健康检查
+// 存储与撮合事件通道为关键组件；链上RPC、结算队列与WebSocket异常时只降级
+func initHealthChecker(store storage.Storage, chainRegistry *chains.Registry, engine *matching.MatchingEngine, wsHub *websocket.Hub) *health.Checker {
+	checker := health.NewChecker(viper.GetDuration("health.timeout"), viper.GetDuration("health.cache_ttl"))
+
+	checker.Add("storage", true, func(ctx context.Context) health.Result {
+		if err := store.HealthCheck(); err != nil {
+			return health.Error(err, nil)
+		}
+		return health.Result{Status: health.StatusHealthy}
+	})
+
+	maxBacklog := viper.GetInt64("health.max_event_backlog")
+	checker.Add("matching_events", true, func(ctx context.Context) health.Result {
+		backlog := int64(engine.EventQueueDepth())
+		details := map[string]interface{}{"backlog": backlog, "max_backlog": maxBacklog}
+		// 积压超过阈值两倍时事件消费已基本停滞
+		if maxBacklog > 0 && backlog > 2*maxBacklog {
+			return health.Result{Status: health.StatusUnhealthy, Error: "event backlog exceeds twice the limit", Details: details}
+		}
+		return health.Threshold(backlog, maxBacklog, details)
+	})
+
+	maxBlockAge := viper.GetDuration("health.max_block_age")
+	maxSettlementQueue := viper.GetInt64("health.max_settlement_queue")
+	for _, chain := range chainRegistry.Chains() {
+		chain := chain
+		if chain.Client != nil {
+			checker.Add(fmt.Sprintf("blockchain.%d", chain.ChainID), false, func(ctx context.Context) health.Result {
+				header, err := chain.Client.Backend().HeaderByNumber(ctx, nil)
+				if err != nil {
+					return health.Error(err, nil)
+				}
+				// 以最新区块时间衡量节点同步延迟
+				age := time.Since(time.Unix(int64(header.Time), 0)).Truncate(time.Second)
+				details := map[string]interface{}{
+					"head_block":     header.Number.Uint64(),
+					"block_age":      age.String(),
+					"pending_events": chain.Client.PendingEvents(),
+				}
+				if maxBlockAge > 0 && age > maxBlockAge {
+					return health.Result{Status: health.StatusDegraded, Error: "node is lagging behind the chain", Details: details}
+				}
+				return health.Result{Status: health.StatusHealthy, Details: details}
+			})
+		}
+		if chain.Settlement != nil {
+			checker.Add(fmt.Sprintf("settlement.%d", chain.ChainID), false, func(ctx context.Context) health.Result {
+				pending := int64(chain.Settlement.PendingCount())
+				return health.Threshold(pending, maxSettlementQueue, map[string]interface{}{
+					"pending":   pending,
+					"max_queue": maxSettlementQueue,
+					"paused":    chain.Settlement.Paused(),
+				})
+			})
+		}
+	}
+
+	checker.Add("websocket", false, func(ctx context.Context) health.Result {
+		details := map[string]interface{}{
+			"clients":                   wsHub.GetConnectedClients(),
+			"slow_consumer_disconnects": wsHub.SlowConsumerDisconnects(),
+		}
+		if !wsHub.Running() {
+			return health.Result{Status: health.StatusUnhealthy, Error: "hub is not running", Details: details}
+		}
+		return health.Result{Status: health.StatusHealthy, Details: details}
+	})
+
+	return checker
+}
+
+// 启动就绪条件
+const readyOrderBook = "orderbook_recovery"
+
+// settlementReadyName 结算管理器启动的就绪条件名
+func settlementReadyName(chainID uint64) string {
+	return fmt.Sprintf("settlement.%d", chainID)
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/viper"
+
+	"orderbook-engine/internal/lifecycle"
+)
+
+// initColdStore 按 lifecycle.driver 创建冷存储
+func initColdStore() (lifecycle.ColdStore, error) {
+	switch driver := viper.GetString("lifecycle.driver"); driver {
+	case "file":
+		return lifecycle.NewFileStore(viper.GetString("lifecycle.file.dir"))
+	case "s3":
+		return lifecycle.NewS3Store(lifecycle.S3Config{
+			Endpoint:  viper.GetString("lifecycle.s3.endpoint"),
+			Region:    viper.GetString("lifecycle.s3.region"),
+			Bucket:    viper.GetString("lifecycle.s3.bucket"),
+			Prefix:    viper.GetString("lifecycle.s3.prefix"),
+			AccessKey: viper.GetString("lifecycle.s3.access_key"),
+			SecretKey: viper.GetString("lifecycle.s3.secret_key"),
+		})
+	default:
+		return nil, fmt.Errorf("unsupported lifecycle driver %q", driver)
+	}
+}
+
+// lifecycleConfig 由配置生成数据生命周期配置
+func lifecycleConfig() lifecycle.Config {
+	return lifecycle.Config{
+		Retention: time.Duration(viper.GetInt("lifecycle.retention_days")) * 24 * time.Hour,
+		Interval:  viper.GetDuration("lifecycle.interval"),
+		BatchSize: viper.GetInt("lifecycle.batch_size"),
+	}
+}
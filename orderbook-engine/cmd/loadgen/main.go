@@ -0,0 +1,325 @@
+// loadgen 向运行中的实例持续提交签名随机订单，校验响应并统计延迟分位数
+// 用于撮合引擎与API的性能回归测试；配合 simulation.enabled 的实例可用 -seed 注入测试余额
+//
+//	go run ./cmd/loadgen -target http://localhost:8084 -pairs WETH-USDC -rate 200 -duration 1m -accounts 20 -seed 1000000
+package main
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"math/big"
+	"math/rand"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+
+	"orderbook-engine/internal/types"
+	"orderbook-engine/pkg/crypto"
+)
+
+// pair 压测交易对：PAIR:BASE_TOKEN:QUOTE_TOKEN
+type pair struct {
+	Name       string
+	BaseToken  string
+	QuoteToken string
+}
+
+// account 压测账户，nonce 在本地递增保证订单哈希唯一
+type account struct {
+	mu      sync.Mutex
+	key     *ecdsa.PrivateKey
+	address string
+	nonce   uint64
+}
+
+func (a *account) nextNonce() uint64 {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.nonce++
+	return a.nonce
+}
+
+// generator 随机订单生成参数，价格围绕中间价分布使部分订单立即成交
+type generator struct {
+	pairs       []pair
+	accounts    []*account
+	signer      *crypto.OrderSigner
+	mid         int64
+	spread      int64
+	minAmount   int64
+	maxAmount   int64
+	marketRatio float64
+	mu          sync.Mutex
+	rnd         *rand.Rand
+}
+
+func main() {
+	target := flag.String("target", "http://localhost:8084", "base URL of the instance under test")
+	pairsFlag := flag.String("pairs", "WETH-USDC:0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2:0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48", "comma separated PAIR:BASE_TOKEN:QUOTE_TOKEN")
+	rate := flag.Float64("rate", 100, "orders per second across all pairs")
+	duration := flag.Duration("duration", 30*time.Second, "test duration")
+	concurrency := flag.Int("concurrency", 32, "concurrent HTTP requests")
+	accounts := flag.Int("accounts", 10, "number of random signing accounts")
+	chainID := flag.Uint64("chain-id", 31337, "EIP-712 domain chain id")
+	contract := flag.String("contract", "0xf4B146FbA71F41E0592668ffbF264F1D186b2Ca8", "EIP-712 verifying contract")
+	mid := flag.Int64("mid", 2000, "mid price (integer price units)")
+	spread := flag.Int64("spread", 20, "max distance from mid price; orders on both sides cross within this band")
+	minAmount := flag.Int64("min-amount", 1, "minimum order amount (integer units)")
+	maxAmount := flag.Int64("max-amount", 10, "maximum order amount (integer units)")
+	marketRatio := flag.Float64("market-ratio", 0, "fraction of market orders")
+	seedAmount := flag.Float64("seed", 0, "seed each account with this balance of every token via /api/v1/simulation/balances, 0 disables")
+	timeout := flag.Duration("timeout", 5*time.Second, "HTTP request timeout")
+	maxErrorRate := flag.Float64("max-error-rate", 0, "exit 1 when the failed fraction exceeds this value, 0 disables")
+	maxP99 := flag.Duration("max-p99", 0, "exit 1 when p99 latency exceeds this value, 0 disables")
+	randSeed := flag.Int64("rand-seed", time.Now().UnixNano(), "random seed for reproducible order flow")
+	logLevel := flag.String("log-level", "info", "log level")
+	flag.Parse()
+
+	logger := logrus.New()
+	logger.SetOutput(os.Stderr)
+	if level, err := logrus.ParseLevel(*logLevel); err == nil {
+		logger.SetLevel(level)
+	}
+
+	pairs, err := parsePairs(*pairsFlag)
+	if err != nil {
+		logger.WithError(err).Fatal("Invalid -pairs")
+	}
+	if *rate <= 0 || *concurrency <= 0 || *accounts <= 0 {
+		logger.Fatal("-rate, -concurrency and -accounts must be positive")
+	}
+	if *minAmount <= 0 || *maxAmount < *minAmount || *spread < 0 || *mid <= *spread {
+		logger.Fatal("invalid price or amount range")
+	}
+
+	gen := &generator{
+		pairs:       pairs,
+		signer:      crypto.NewOrderSigner(new(big.Int).SetUint64(*chainID), common.HexToAddress(*contract)),
+		mid:         *mid,
+		spread:      *spread,
+		minAmount:   *minAmount,
+		maxAmount:   *maxAmount,
+		marketRatio: *marketRatio,
+		rnd:         rand.New(rand.NewSource(*randSeed)),
+	}
+	for i := 0; i < *accounts; i++ {
+		key, err := ethcrypto.GenerateKey()
+		if err != nil {
+			logger.WithError(err).Fatal("Failed to generate account key")
+		}
+		gen.accounts = append(gen.accounts, &account{key: key, address: ethcrypto.PubkeyToAddress(key.PublicKey).Hex()})
+	}
+
+	client := &http.Client{Timeout: *timeout}
+	baseURL := strings.TrimRight(*target, "/")
+
+	if *seedAmount > 0 {
+		if err := seedBalances(client, baseURL, gen.accounts, pairs, decimal.NewFromFloat(*seedAmount)); err != nil {
+			logger.WithError(err).Fatal("Failed to seed balances")
+		}
+		logger.WithField("accounts", len(gen.accounts)).Info("Balances seeded")
+	}
+
+	logger.WithFields(logrus.Fields{
+		"target":   baseURL,
+		"pairs":    len(pairs),
+		"rate":     *rate,
+		"duration": duration.String(),
+	}).Info("Starting load generation")
+
+	stats := newStats()
+	jobs := make(chan *types.SignedOrder, *concurrency)
+	var wg sync.WaitGroup
+	for i := 0; i < *concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for order := range jobs {
+				stats.record(submitOrder(client, baseURL, order))
+			}
+		}()
+	}
+
+	// 按固定节拍生成订单，工作协程全部忙碌时记为丢弃，避免发送端积压掩盖服务端延迟
+	started := time.Now()
+	ticker := time.NewTicker(time.Duration(float64(time.Second) / *rate))
+	deadline := time.After(*duration)
+generate:
+	for {
+		select {
+		case <-deadline:
+			break generate
+		case <-ticker.C:
+			order, err := gen.next()
+			if err != nil {
+				logger.WithError(err).Fatal("Failed to sign order")
+			}
+			select {
+			case jobs <- order:
+			default:
+				stats.drop()
+			}
+		}
+	}
+	ticker.Stop()
+	close(jobs)
+	wg.Wait()
+
+	report := stats.report(time.Since(started))
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		logger.WithError(err).Error("Failed to write load report")
+	}
+
+	failed := false
+	if *maxErrorRate > 0 && report.ErrorRate > *maxErrorRate {
+		logger.WithField("error_rate", report.ErrorRate).Error("Error rate above threshold")
+		failed = true
+	}
+	if *maxP99 > 0 && report.Latency.P99 > float64(*maxP99)/float64(time.Millisecond) {
+		logger.WithField("p99_ms", report.Latency.P99).Error("p99 latency above threshold")
+		failed = true
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// parsePairs 解析 PAIR:BASE_TOKEN:QUOTE_TOKEN 列表
+func parsePairs(value string) ([]pair, error) {
+	var pairs []pair
+	for _, item := range strings.Split(value, ",") {
+		parts := strings.Split(strings.TrimSpace(item), ":")
+		if len(parts) != 3 || parts[0] == "" || !common.IsHexAddress(parts[1]) || !common.IsHexAddress(parts[2]) {
+			return nil, fmt.Errorf("invalid pair %q, expected PAIR:BASE_TOKEN:QUOTE_TOKEN", item)
+		}
+		pairs = append(pairs, pair{Name: parts[0], BaseToken: parts[1], QuoteToken: parts[2]})
+	}
+	return pairs, nil
+}
+
+// next 生成并签名一笔随机订单
+func (g *generator) next() (*types.SignedOrder, error) {
+	g.mu.Lock()
+	p := g.pairs[g.rnd.Intn(len(g.pairs))]
+	acct := g.accounts[g.rnd.Intn(len(g.accounts))]
+	side := types.OrderSideBuy
+	if g.rnd.Intn(2) == 1 {
+		side = types.OrderSideSell
+	}
+	orderType := types.OrderTypeLimit
+	if g.rnd.Float64() < g.marketRatio {
+		orderType = types.OrderTypeMarket
+	}
+	price := g.mid + g.rnd.Int63n(2*g.spread+1) - g.spread
+	amount := g.minAmount + g.rnd.Int63n(g.maxAmount-g.minAmount+1)
+	g.mu.Unlock()
+
+	order := &types.SignedOrder{
+		UserAddress: acct.address,
+		TradingPair: p.Name,
+		BaseToken:   p.BaseToken,
+		QuoteToken:  p.QuoteToken,
+		Side:        side,
+		Type:        orderType,
+		Amount:      decimal.NewFromInt(amount),
+		Nonce:       acct.nextNonce(),
+	}
+	if orderType == types.OrderTypeLimit {
+		order.Price = decimal.NewFromInt(price)
+	}
+	if err := crypto.SignOrder(order, acct.key, g.signer); err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// result 单笔下单结果
+type result struct {
+	latency    time.Duration
+	statusCode int    // 0 表示请求未完成（连接失败、超时）
+	errorCode  string // 失败时响应中的 code
+	fills      int
+	invalid    bool // 成功响应缺少订单ID或状态
+}
+
+// placeOrderResponse 下单成功响应中校验的字段
+type placeOrderResponse struct {
+	OrderID string            `json:"order_id"`
+	Status  types.OrderStatus `json:"status"`
+	Fills   []json.RawMessage `json:"fills"`
+	Code    string            `json:"code"`
+	Error   string            `json:"error"`
+}
+
+// submitOrder 提交订单并校验响应
+func submitOrder(client *http.Client, baseURL string, order *types.SignedOrder) result {
+	body, _ := json.Marshal(order)
+	started := time.Now()
+	resp, err := client.Post(baseURL+"/api/v1/orders", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return result{latency: time.Since(started), errorCode: "REQUEST_FAILED"}
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(resp.Body)
+	res := result{latency: time.Since(started), statusCode: resp.StatusCode}
+
+	var decoded placeOrderResponse
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		res.invalid = true
+		return res
+	}
+	if resp.StatusCode != http.StatusCreated {
+		res.errorCode = decoded.Code
+		if res.errorCode == "" {
+			res.errorCode = decoded.Error
+		}
+		return res
+	}
+	if _, err := uuid.Parse(decoded.OrderID); err != nil || decoded.Status == "" {
+		res.invalid = true
+	}
+	res.fills = len(decoded.Fills)
+	return res
+}
+
+// seedBalances 通过模拟盘接口为每个账户注入全部代币的测试余额
+func seedBalances(client *http.Client, baseURL string, accounts []*account, pairs []pair, amount decimal.Decimal) error {
+	tokens := make(map[string]bool)
+	for _, p := range pairs {
+		tokens[p.BaseToken] = true
+		tokens[p.QuoteToken] = true
+	}
+	for _, acct := range accounts {
+		for token := range tokens {
+			body, _ := json.Marshal(map[string]interface{}{
+				"user_address": acct.address,
+				"token":        token,
+				"amount":       amount,
+			})
+			resp, err := client.Post(baseURL+"/api/v1/simulation/balances", "application/json", bytes.NewReader(body))
+			if err != nil {
+				return err
+			}
+			data, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				return fmt.Errorf("seed %s %s: status %d: %s", acct.address, token, resp.StatusCode, strings.TrimSpace(string(data)))
+			}
+		}
+	}
+	return nil
+}
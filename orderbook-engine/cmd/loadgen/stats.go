@@ -0,0 +1,139 @@
+package main
+
+import (
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// stats 压测结果统计
+type stats struct {
+	mu          sync.Mutex
+	latencies   []time.Duration
+	succeeded   int
+	failed      int
+	invalid     int
+	dropped     int
+	fills       int
+	statusCodes map[string]int
+	errorCodes  map[string]int
+}
+
+// Report 压测报告，延迟单位为毫秒
+type Report struct {
+	Duration    string         `json:"duration"`
+	Sent        int            `json:"sent"`
+	Succeeded   int            `json:"succeeded"`
+	Failed      int            `json:"failed"`
+	Invalid     int            `json:"invalid_responses"` // 无法解析或缺少订单ID的响应
+	Dropped     int            `json:"dropped"`           // 并发已满未能按节拍发出的订单
+	Fills       int            `json:"fills"`
+	Throughput  float64        `json:"throughput_per_sec"`
+	ErrorRate   float64        `json:"error_rate"`
+	StatusCodes map[string]int `json:"status_codes"`
+	ErrorCodes  map[string]int `json:"error_codes,omitempty"`
+	Latency     LatencySummary `json:"latency_ms"`
+}
+
+// LatencySummary 延迟分位数（毫秒）
+type LatencySummary struct {
+	Mean float64 `json:"mean"`
+	P50  float64 `json:"p50"`
+	P90  float64 `json:"p90"`
+	P99  float64 `json:"p99"`
+	Max  float64 `json:"max"`
+}
+
+func newStats() *stats {
+	return &stats{
+		statusCodes: make(map[string]int),
+		errorCodes:  make(map[string]int),
+	}
+}
+
+func (s *stats) record(res result) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.latencies = append(s.latencies, res.latency)
+	s.statusCodes[strconv.Itoa(res.statusCode)]++
+	switch {
+	case res.invalid:
+		s.invalid++
+		s.failed++
+	case res.statusCode == 201:
+		s.succeeded++
+		s.fills += res.fills
+	default:
+		s.failed++
+		s.errorCodes[res.errorCode]++
+	}
+}
+
+func (s *stats) drop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.dropped++
+}
+
+func (s *stats) report(elapsed time.Duration) *Report {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sent := len(s.latencies)
+	report := &Report{
+		Duration:    elapsed.Round(time.Millisecond).String(),
+		Sent:        sent,
+		Succeeded:   s.succeeded,
+		Failed:      s.failed,
+		Invalid:     s.invalid,
+		Dropped:     s.dropped,
+		Fills:       s.fills,
+		StatusCodes: s.statusCodes,
+		ErrorCodes:  s.errorCodes,
+		Latency:     summarize(s.latencies),
+	}
+	if elapsed > 0 {
+		report.Throughput = float64(sent) / elapsed.Seconds()
+	}
+	if sent > 0 {
+		report.ErrorRate = float64(s.failed) / float64(sent)
+	}
+	return report
+}
+
+// summarize 计算延迟分位数（最近秩法）
+func summarize(latencies []time.Duration) LatencySummary {
+	if len(latencies) == 0 {
+		return LatencySummary{}
+	}
+	sorted := append([]time.Duration(nil), latencies...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var total time.Duration
+	for _, latency := range sorted {
+		total += latency
+	}
+	percentile := func(p float64) float64 {
+		index := int(p*float64(len(sorted))+0.5) - 1
+		if index < 0 {
+			index = 0
+		}
+		if index >= len(sorted) {
+			index = len(sorted) - 1
+		}
+		return millis(sorted[index])
+	}
+	return LatencySummary{
+		Mean: millis(total / time.Duration(len(sorted))),
+		P50:  percentile(0.50),
+		P90:  percentile(0.90),
+		P99:  percentile(0.99),
+		Max:  millis(sorted[len(sorted)-1]),
+	}
+}
+
+func millis(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
@@ -7,6 +7,8 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"sort"
+	"strings"
 	"sync"
 	"syscall"
 	"time"
@@ -18,18 +20,58 @@ import (
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
 
+	"orderbook-engine/internal/analytics"
 	"orderbook-engine/internal/api"
+	"orderbook-engine/internal/audit"
 	"orderbook-engine/internal/blockchain"
+	"orderbook-engine/internal/booksnapshot"
+	"orderbook-engine/internal/chains"
+	"orderbook-engine/internal/circuitbreaker"
+	"orderbook-engine/internal/drain"
+	"orderbook-engine/internal/eventbus"
+	"orderbook-engine/internal/eventlog"
+	"orderbook-engine/internal/execution"
+	"orderbook-engine/internal/halt"
+	"orderbook-engine/internal/health"
+	"orderbook-engine/internal/history"
+	"orderbook-engine/internal/importer"
+	"orderbook-engine/internal/intake"
+	"orderbook-engine/internal/integrity"
+	"orderbook-engine/internal/leader"
+	"orderbook-engine/internal/lifecycle"
+	"orderbook-engine/internal/listing"
+	"orderbook-engine/internal/loadshed"
+	"orderbook-engine/internal/marketmaker"
 	"orderbook-engine/internal/matching"
+	"orderbook-engine/internal/merkle"
+	"orderbook-engine/internal/nonce"
+	"orderbook-engine/internal/obligations"
+	"orderbook-engine/internal/oracle"
+	"orderbook-engine/internal/orderflow"
+	"orderbook-engine/internal/pairs"
+	"orderbook-engine/internal/positions"
+	"orderbook-engine/internal/reduceonly"
+	"orderbook-engine/internal/referral"
+	"orderbook-engine/internal/rewards"
+	"orderbook-engine/internal/riskcontrol"
+	"orderbook-engine/internal/session"
+	"orderbook-engine/internal/settlement"
+	"orderbook-engine/internal/stats"
 	"orderbook-engine/internal/storage"
+	"orderbook-engine/internal/surveillance"
+	"orderbook-engine/internal/tokens"
 	"orderbook-engine/internal/types"
+	"orderbook-engine/internal/wallet"
 	"orderbook-engine/internal/websocket"
-	"orderbook-engine/pkg/crypto"
 )
 
 func main() {
 	// 初始化配置
 	initConfig()
+	applySimulationMode()
+	if err := validateConfig(); err != nil {
+		logrus.WithError(err).Fatal("Invalid configuration")
+	}
 
 	// 初始化日志
 	logger := initLogger()
@@ -41,51 +83,754 @@ func main() {
 	}
 	defer store.Close()
 
-	// 初始化签名器
-	chainID := big.NewInt(viper.GetInt64("blockchain.chain_id"))
-	contractAddress := common.HexToAddress(viper.GetString("blockchain.contract_address"))
-	signer := crypto.NewOrderSigner(chainID, contractAddress)
-
-	// 初始化区块链客户端
-	var blockchainClient *blockchain.Client
-	if viper.GetString("blockchain.rpc_url") != "" {
-		var err error
-		blockchainClient, err = blockchain.NewClient(
-			viper.GetString("blockchain.rpc_url"),
-			big.NewInt(viper.GetInt64("blockchain.chain_id")),
-			viper.GetString("blockchain.private_key"),
-			viper.GetString("blockchain.contract_address"),
-			viper.GetString("blockchain.settlement_address"),
-			logger,
-		)
-		if err != nil {
-			logger.WithError(err).Fatal("Failed to initialize blockchain client")
+	// 初始化链（每条链独立的签名域、区块链客户端）
+	chainRegistry := initChains(logger)
+	defer func() {
+		for _, chain := range chainRegistry.Chains() {
+			if chain.Client != nil {
+				chain.Client.Close()
+			}
 		}
-		logger.Info("Blockchain client initialized")
-	} else {
-		logger.Warn("Blockchain integration disabled - no RPC URL provided")
-	}
+	}()
+
+	// 默认链的签名器和客户端用于单链场景（预言机、余额、nonce同步）
+	signer := chainRegistry.Default().Signer
+	blockchainClient := chainRegistry.Default().Client
+
+	// 代币精度注册表，链上整数数量与引擎数量之间的换算
+	tokenRegistry := initTokens(chainRegistry, logger)
 
 	// 初始化撮合引擎
 	engine := matching.NewMatchingEngine(logger)
+	eventOverflow, err := matching.ParseOverflowPolicy(viper.GetString("matching.event_overflow"))
+	if err != nil {
+		logger.WithError(err).Fatal("Invalid matching event configuration")
+	}
+	if err := engine.SetEventBackpressure(matching.EventBackpressure{
+		BufferSize: viper.GetInt("matching.event_buffer"),
+		Overflow:   eventOverflow,
+		SpillDir:   viper.GetString("matching.event_spill_dir"),
+	}); err != nil {
+		logger.WithError(err).Fatal("Failed to configure matching event backpressure")
+	}
+
+	// 主备模式下以备用实例启动，当选后才接单
+	if viper.GetBool("leader.enabled") {
+		engine.SetStandby(true)
+	}
 
 	// 初始化WebSocket Hub
+	wsPolicy, err := websocket.ParseSlowConsumerPolicy(viper.GetString("websocket.slow_consumer_policy"))
+	if err != nil {
+		logger.WithError(err).Fatal("Invalid WebSocket configuration")
+	}
 	wsHub := websocket.NewHub(logger)
+	wsHub.SetConfig(websocket.Config{
+		SendBuffer:   viper.GetInt("websocket.send_buffer"),
+		PingInterval: viper.GetDuration("websocket.ping_interval"),
+		PongTimeout:  viper.GetDuration("websocket.pong_timeout"),
+		WriteTimeout: viper.GetDuration("websocket.write_timeout"),
+		Policy:       wsPolicy,
+		// 订单簿推送按周期合并，被动订阅者在突发行情下每周期每个交易对只收到一次最新状态
+		BookConflation: viper.GetDuration("websocket.book_conflation"),
+	})
+	// 多实例部署：主题消息经 NATS 在实例间转发，连接到任一实例的客户端都能收到其他实例产生的事件
+	if viper.GetBool("websocket.bridge.enabled") {
+		bridge, err := websocket.NewNATSBridge(viper.GetString("websocket.bridge.nats_url"), viper.GetString("websocket.bridge.subject_prefix"))
+		if err != nil {
+			logger.WithError(err).Fatal("Failed to connect WebSocket bridge")
+		}
+		defer bridge.Close()
+		wsHub.SetBridge(bridge)
+		logger.Info("🔗 WebSocket cross-instance bridge enabled")
+	}
 	go wsHub.Run()
+	watchChainConnectivity(wsHub, chainRegistry)
+
+	// 交易对上线登记：未开盘与已下架的交易对拒绝新订单，价格与数量须符合最小变动单位；
+	// 熔断与集合竞价的状态变化同步到登记表后再推送
+	pairListings, err := listing.NewRegistry(viper.GetString("listing.file"), logger)
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to load pair listings")
+	}
+	pairListings.SetStatusHandler(wsHub.PublishPairStatus)
+	publishPairStatus := func(update *types.PairStatusUpdate) {
+		pairListings.Observe(update)
+		wsHub.PublishPairStatus(update)
+	}
+	for _, pair := range pairListings.Pairs() {
+		for _, token := range []tokens.Token{pair.BaseToken, pair.QuoteToken} {
+			if err := tokenRegistry.Register(token); err != nil {
+				logger.WithError(err).WithField("trading_pair", pair.TradingPair).Warn("Failed to register listed token")
+			}
+		}
+	}
+	engine.AddOrderGate(pairListings)
+	engine.SetRestingPolicy(pairListings)
+	// 交易对规范命名：REST 与链上订单的同一代币对映射到同一个订单簿
+	pairNames := pairs.NewCanonicalizer(tokenRegistry, pairListings, viper.GetStringSlice("listing.quote_priority"))
+
+	// 初始化价格熔断
+	var breaker *circuitbreaker.CircuitBreaker
+	if viper.GetBool("circuit_breaker.enabled") {
+		breaker = circuitbreaker.NewCircuitBreaker(&circuitbreaker.Config{
+			MaxMovePercent: decimal.NewFromFloat(viper.GetFloat64("circuit_breaker.max_move_percent")),
+			Window:         viper.GetDuration("circuit_breaker.window"),
+			HaltDuration:   viper.GetDuration("circuit_breaker.halt_duration"),
+		}, logger)
+		publishBreakerStatus := func(update *types.PairStatusUpdate) {
+			publishPairStatus(update)
+			publishBreakerEvent(wsHub, update)
+		}
+		breaker.SetStatusHandler(publishBreakerStatus)
+		// 熔断恢复时先进入集合竞价，避免恢复瞬间按失衡的订单簿连续成交
+		if resume := viper.GetDuration("auction.resume_duration"); resume > 0 {
+			breaker.SetStatusHandler(func(update *types.PairStatusUpdate) {
+				publishBreakerStatus(update)
+				if update.Status == types.PairStatusTrading {
+					if err := engine.StartAuction(update.TradingPair, "halt_resume", resume); err != nil {
+						logger.WithError(err).WithField("trading_pair", update.TradingPair).Error("Failed to start resume auction")
+					}
+				}
+			})
+		}
+		breaker.StartResumeTicker(time.Second)
+		engine.AddTradingGate(breaker)
+		go handleCircuitBreakerEvents(engine.Subscribe(matching.SubscriptionOptions{
+			Name:       "circuit_breaker",
+			EventTypes: []string{matching.EventOrderAdded, matching.EventAuctionUncrossed},
+		}), breaker)
+		logger.Info("Circuit breaker enabled")
+	}
+
+	// 签名有效期：超期挂单自动撤销并清除存储中的签名
+	if ttl := viper.GetDuration("trading.signature_ttl"); ttl > 0 {
+		engine.SetSignatureTTL(ttl)
+		go runSignatureTTLSweeper(engine, store, viper.GetDuration("trading.signature_ttl_sweep_interval"), logger)
+		logger.WithField("ttl", ttl.String()).Info("Signature TTL enabled")
+	}
+
+	// 过期订单：到期时刻移出订单簿（定时全量清理兜底）并更新存储中的订单状态
+	go handleExpiredOrders(engine.Subscribe(matching.SubscriptionOptions{
+		Name:       "expiry",
+		EventTypes: []string{matching.EventOrderExpired},
+	}), store, logger)
+	engine.StartExpirySweeper(viper.GetDuration("trading.expiry_sweep_interval"))
+
+	// 市价单要求对手方的最小挂单名义价值
+	engine.SetMinMarketLiquidity(decimal.NewFromFloat(viper.GetFloat64("trading.market_min_liquidity")))
+	engine.SetFeeSchedule(feeSchedule())
+	if err := engine.SetPriceBands(priceBands()); err != nil {
+		logger.WithError(err).Fatal("Invalid price band configuration")
+	}
+
+	// 集合竞价：竞价结束统一成交的成交记录与订单状态写回存储
+	engine.SetPairStatusHandler(publishPairStatus)
+	pairListings.SetAuctionStarter(func(tradingPair string, duration time.Duration) error {
+		return engine.StartAuction(tradingPair, "opening", duration)
+	})
+	go handleAuctionFills(engine.Subscribe(matching.SubscriptionOptions{
+		Name:       "auction",
+		EventTypes: []string{matching.EventAuctionUncrossed},
+	}), store, logger)
+
+	// 订单入口去重：同一意图经 REST 与链上 OrderPlaced 两条入口进入时只保留先到的一笔
+	var deduper *intake.Deduper
+	if viper.GetBool("intake.dedup.enabled") {
+		deduper, err = intake.NewDeduper(viper.GetDuration("intake.dedup.window"), logger)
+		if err != nil {
+			logger.WithError(err).Fatal("Failed to initialize order intake dedup")
+		}
+	}
+
+	// 启动就绪门控：订单簿恢复、链上订阅与结算管理器启动完成后才接收流量
+	readiness := health.NewReadiness()
+	readiness.Require(readyOrderBook)
 
 	// 启动区块链事件监听
-	if blockchainClient != nil && viper.GetBool("trading.auto_matching") {
-		go handleBlockchainEvents(blockchainClient, engine, logger)
+	if viper.GetBool("trading.auto_matching") {
+		for _, chain := range chainRegistry.Chains() {
+			if chain.Client != nil {
+				name := fmt.Sprintf("blockchain.%d", chain.ChainID)
+				readiness.Require(name)
+				go func(client *blockchain.Client) {
+					<-client.Live()
+					readiness.Done(name)
+				}(chain.Client)
+				go handleBlockchainEvents(chain.Client, tokenRegistry, pairNames, engine, store, deduper, logger)
+			}
+		}
+	}
+
+	// 撮合事件日志：按顺序记录全部事件，供 cmd/replay 重放与回测
+	var eventLog *eventlog.Writer
+	if path := viper.GetString("eventlog.path"); path != "" {
+		eventLog, err = eventlog.NewWriter(path, logger)
+		if err != nil {
+			logger.WithError(err).Fatal("Failed to open event log")
+		}
+		defer eventLog.Close()
+		go eventLog.Run(engine.Subscribe(matching.SubscriptionOptions{
+			Name: "eventlog",
+		}))
+		logger.WithField("path", path).Info("Matching event log enabled")
+	}
+
+	// 外部事件总线：成交、订单状态与订单簿增量发布到 Kafka / NATS（至少一次投递，按交易对分区）
+	if viper.GetBool("eventbus.enabled") {
+		var publisher eventbus.Publisher
+		switch driver := viper.GetString("eventbus.driver"); driver {
+		case "kafka":
+			publisher = eventbus.NewKafkaPublisher(viper.GetStringSlice("eventbus.kafka.brokers"))
+		case "nats":
+			natsPublisher, err := eventbus.NewNATSPublisher(viper.GetString("eventbus.nats.url"), viper.GetString("eventbus.nats.stream"), viper.GetString("eventbus.topic_prefix"))
+			if err != nil {
+				logger.WithError(err).Fatal("Failed to connect event bus")
+			}
+			publisher = natsPublisher
+		default:
+			logger.WithField("driver", driver).Fatal("Unknown event bus driver")
+		}
+		defer publisher.Close()
+
+		forwarder := eventbus.NewForwarder(publisher, engine, eventbus.Config{
+			TopicPrefix:    viper.GetString("eventbus.topic_prefix"),
+			BookDepth:      viper.GetInt("eventbus.book_depth"),
+			PublishTimeout: viper.GetDuration("eventbus.publish_timeout"),
+			RetryBackoff:   viper.GetDuration("eventbus.retry_backoff"),
+			MaxBackoff:     viper.GetDuration("eventbus.max_backoff"),
+		}, logger)
+		go forwarder.Run(engine.Subscribe(matching.SubscriptionOptions{
+			Name:       "eventbus",
+			BufferSize: viper.GetInt("eventbus.buffer_size"),
+		}))
+		logger.WithField("driver", viper.GetString("eventbus.driver")).Info("📡 Event bus publisher enabled")
 	}
 
 	// 启动撮合引擎事件处理器
-	go handleMatchingEvents(engine, wsHub, blockchainClient, logger)
+	// 行情推送可丢弃，避免慢连接拖慢撮合
+	go handleMatchingEvents(engine.Subscribe(matching.SubscriptionOptions{
+		Name:       "websocket",
+		DropOnFull: true,
+	}), engine, wsHub, logger)
+	// 成交回报需读取存储，不与行情推送共用订阅，也不可丢弃
+	go handleExecutionReports(engine.Subscribe(matching.SubscriptionOptions{
+		Name:       "executions",
+		EventTypes: []string{matching.EventOrderAdded, matching.EventAuctionUncrossed},
+	}), execution.NewReporter(store), wsHub, logger)
 
 	// 初始化API处理器
 	handler := api.NewHandler(engine, store, signer, logger)
+	if deduper != nil {
+		handler.SetIntakeDeduper(deduper)
+	}
+	handler.SetChains(chainRegistry)
+	handler.SetPairListings(pairListings, tokenRegistry)
+	handler.SetPairCanonicalizer(pairNames)
+	handler.SetCircuitBreaker(breaker)
+	handler.SetRequireSignedCancel(viper.GetBool("trading.require_signed_cancel"))
+	handler.SetVerifyOrderSignatures(viper.GetBool("auth.verify_order_signatures"))
+	handler.SetSignatureDiagnostics(viper.GetBool("auth.signature_diagnostics"))
+	handler.SetSimulation(viper.GetBool("simulation.enabled"), decimal.NewFromFloat(viper.GetFloat64("simulation.max_seed_amount")))
+	handler.SetAPIKeyAuth(viper.GetBool("auth.require_api_key"), viper.GetDuration("auth.signature_window"))
+
+	// 审计日志：下单、撤单、管理操作、黑名单、结算提交与余额调整，配置了 PostgreSQL 时持久化
+	var auditor *audit.Recorder
+	if viper.GetBool("audit.enabled") {
+		var auditStore audit.Store = audit.NewMemoryStore()
+		if dsn := viper.GetString("audit.postgres_dsn"); dsn != "" {
+			postgresStore, err := audit.NewPostgresStore(dsn)
+			if err != nil {
+				logger.WithError(err).Fatal("Failed to initialize audit store")
+			}
+			defer postgresStore.Close()
+			auditStore = postgresStore
+		} else {
+			logger.Warn("Audit store not configured - audit log kept in memory only")
+		}
+		auditor = audit.NewRecorder(auditStore, logger)
+		handler.SetAuditRecorder(auditor)
+	}
+
+	// 初始化nonce管理（拒绝重放和已作废的nonce），链上nonce从默认链同步
+	if viper.GetBool("nonce.enabled") {
+		var chainNonces nonce.ChainSource
+		if blockchainClient != nil {
+			chainNonces = blockchainClient
+		}
+		handler.SetNonceTracker(nonce.NewTracker(chainNonces, store, viper.GetDuration("nonce.chain_sync_interval"), logger))
+	}
+
+	// 初始化会话管理
+	sessions := session.NewRegistry(logger)
+	handler.SetSessionRegistry(sessions)
+	wsHub.SetSessionRegistry(sessions)
+
+	// 初始化WebSocket主题访问控制
+	topicACL, err := websocket.NewTopicACL(viper.GetString("websocket.acl_file"))
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to load WebSocket ACL")
+	}
+	wsHub.SetTopicACL(topicACL)
+	handler.SetTopicACL(topicACL)
+	handler.SetWebSocketHub(wsHub)
+
+	// 紧急停机开关：暂停撮合与链上结算，行情与订单查询照常提供
+	haltSwitch := halt.NewSwitch(logger)
+	haltSwitch.SetStatusHandler(wsHub.PublishSystemStatus)
+	handler.SetHaltSwitch(haltSwitch)
+
+	// 初始化过载降级
+	if viper.GetBool("load_shedding.enabled") {
+		shedder := loadshed.NewShedder(&loadshed.Config{
+			MaxQueueDepth:  viper.GetInt("load_shedding.max_queue_depth"),
+			MaxLatency:     viper.GetDuration("load_shedding.max_latency"),
+			RecoverPeriod:  viper.GetDuration("load_shedding.recover_period"),
+			UserOrderRate:  viper.GetFloat64("load_shedding.user_order_rate"),
+			UserOrderBurst: viper.GetInt("load_shedding.user_order_burst"),
+		}, engine.EventQueueDepth, logger)
+		// 紧急停机期间 system.status 只推送停机状态
+		shedder.SetStatusHandler(func(update *types.SystemStatusUpdate) {
+			if !haltSwitch.Halted() {
+				wsHub.PublishSystemStatus(update)
+			}
+		})
+		shedder.Start(viper.GetDuration("load_shedding.check_interval"))
+		handler.SetLoadShedder(shedder)
+		logger.Info("Load shedding enabled")
+	}
+
+	// 参考价格来源（风控、异常监控共用）
+	priceOracle := initPriceOracle(engine, blockchainClient, logger)
+
+	// 初始化成交统计聚合
+	aggregator := stats.NewAggregator(&stats.Config{
+		LargeTradeMinNotional: decimal.NewFromFloat(viper.GetFloat64("stats.large_trade_min_notional")),
+		LargeTradeRetention:   viper.GetDuration("stats.large_trade_retention"),
+		MaxLargeTrades:        viper.GetInt("stats.max_large_trades"),
+	}, logger)
+	go handleStatsEvents(engine.Subscribe(matching.SubscriptionOptions{
+		Name:       "stats",
+		EventTypes: []string{matching.EventOrderAdded, matching.EventAuctionUncrossed},
+	}), aggregator)
+	handler.SetStatsAggregator(aggregator)
+
+	// 默克尔批次证明存储，关闭默克尔批次前已上链批次的证明仍可查询
+	var merkleProofs merkle.Store
+	if viper.GetBool("settlement.merkle.enabled") {
+		proofStore, err := merkle.NewFileStore(viper.GetString("settlement.merkle.store_path"))
+		if err != nil {
+			logger.WithError(err).Fatal("Failed to open merkle proof store")
+		}
+		merkleProofs = proofStore
+		handler.SetMerkleProofStore(merkleProofs)
+	}
+
+	// 初始化链上结算流水线
+	var pipeline *settlement.Pipeline
+	if viper.GetBool("settlement.enabled") {
+		pipeline = initSettlement(chainRegistry, engine, store, merkleProofs, wsHub, auditor, logger)
+		for _, chain := range chainRegistry.Chains() {
+			if chain.Settlement != nil {
+				defer chain.Settlement.Stop()
+				handler.AddSettlementManager(chain.Settlement)
+			}
+		}
+		handler.SetSettlementPipeline(pipeline)
+		handler.SetKeyRotation(settlementSigner, viper.GetDuration("settlement.key_rotation_timeout"))
+		logger.Info("On-chain settlement enabled")
+	}
+
+	// 主备模式下结算管理器在当选后启动
+	for _, chain := range chainRegistry.Chains() {
+		if chain.Settlement != nil {
+			readiness.Require(settlementReadyName(chain.ChainID))
+			if !viper.GetBool("leader.enabled") {
+				readiness.Done(settlementReadyName(chain.ChainID))
+			}
+		}
+	}
+
+	haltSwitch.AddAction(engine.SetHalted)
+	for _, chain := range chainRegistry.Chains() {
+		if chain.Settlement != nil {
+			haltSwitch.AddAction(chain.Settlement.SetPaused)
+		}
+	}
+	if viper.GetBool("system.halted") {
+		haltSwitch.Halt(viper.GetString("system.halt_reason"), "config")
+	}
+
+	handler.SetHealthChecker(initHealthChecker(store, chainRegistry, engine, wsHub))
+
+	// 初始化成交异常监控
+	if viper.GetBool("surveillance.enabled") {
+		detector := initSurveillance(priceOracle, logger)
+		if breaker != nil {
+			detector.SetHalter(breaker)
+		}
+		go handleSurveillanceEvents(engine.Subscribe(matching.SubscriptionOptions{
+			Name:       "surveillance",
+			EventTypes: []string{matching.EventOrderAdded, matching.EventAuctionUncrossed},
+		}), detector)
+		handler.SetSurveillance(detector)
+		logger.Info("Trade surveillance enabled")
+	}
+
+	// 初始化做市机器人
+	if viper.GetBool("liquidity.enabled") {
+		quoter := initLiquidityBot(engine, priceOracle, store, logger)
+		quoter.Start(viper.GetDuration("liquidity.refresh_interval"))
+		handler.SetQuoter(quoter)
+		logger.Info("Liquidity bot enabled")
+	}
+
+	// 初始化余额管理器
+	balanceManager := initBalanceManager(blockchainClient, logger)
+	handler.SetBalanceManager(balanceManager)
+
+	// 链上资金预检：拒绝托管余额或 ERC-20 授权不足、撮合后必然结算失败的订单
+	if checker := initPreflight(chainRegistry, logger); checker != nil {
+		handler.SetPreflightChecker(checker)
+	}
+
+	// 地址合规筛查：下单与提现前拒绝制裁名单等地址
+	if viper.GetBool("compliance.enabled") {
+		complianceChecker := initCompliance(logger)
+		handler.SetComplianceChecker(complianceChecker)
+		balanceManager.AddWithdrawalGate(complianceChecker.AllowWithdrawal)
+	}
+
+	// 只减仓订单：下单时按持仓缩减数量，持仓减少时缩减或撤销挂单
+	reduceOnlyGuard := reduceonly.NewGuard(engine, balanceManager, store, logger)
+	handler.SetReduceOnlyGuard(reduceOnlyGuard)
+	balanceManager.SetChangeHandler(func(update *types.BalanceUpdate) {
+		wsHub.PublishBalanceUpdate(update)
+		reduceOnlyGuard.OnBalanceChange(update)
+	})
+
+	// 余额持久化：启动时恢复余额与充值记录，之后每次变更写穿
+	if dsn := viper.GetString("wallet.postgres_dsn"); dsn != "" {
+		balanceStore, err := wallet.NewPostgresStore(dsn)
+		if err != nil {
+			logger.WithError(err).Fatal("Failed to initialize balance store")
+		}
+		defer balanceStore.Close()
+		if err := balanceManager.SetBalanceStore(balanceStore); err != nil {
+			logger.WithError(err).Fatal("Failed to restore balances")
+		}
+	} else {
+		logger.Warn("Balance store not configured - balances kept in memory only")
+	}
+
+	// 内部余额约束：下单锁定资金，成交转移余额，撤单释放锁定
+	var ledger *wallet.Ledger
+	if viper.GetBool("wallet.enforce_balances") {
+		// 订单锁定不落盘，由存储中的活跃订单重新推导
+		activeOrders, err := store.GetActiveOrders("")
+		if err != nil {
+			logger.WithError(err).Fatal("Failed to load active orders for lock recovery")
+		}
+		logger.WithField("locks", balanceManager.RestoreOrderLocks(activeOrders)).Info("Order locks restored")
+
+		handler.SetEnforceBalances(true)
+		ledger = wallet.NewLedger(balanceManager, store, logger)
+		go ledger.Run(engine.Subscribe(matching.SubscriptionOptions{
+			Name:       "ledger",
+			EventTypes: []string{matching.EventOrderAdded, matching.EventOrderCancelled, matching.EventOrderExpired, matching.EventOrderReduced, matching.EventAuctionUncrossed},
+		}))
+		logger.Info("Internal balance enforcement enabled")
+	}
+
+	// 提交前模拟执行失败、不再上链的成交：回滚内部余额并通知双方
+	if pipeline != nil {
+		pipeline.SetRejectHandler(func(fill *types.Fill, err error) {
+			if ledger != nil {
+				if revertErr := ledger.RevertFill(fill); revertErr != nil {
+					logger.WithError(revertErr).WithField("fill_id", fill.ID.String()).Error("Failed to roll back balances of rejected settlement")
+				}
+			}
+			publishSettlementRejection(wsHub, fill, err)
+		})
+	}
+
+	// 一致性检查：订单簿、存储与余额锁定交叉比对，由管理接口或 cmd/integrity 触发
+	var integrityLocks integrity.Locks
+	if viper.GetBool("wallet.enforce_balances") {
+		integrityLocks = balanceManager
+	}
+	integrityChecker := integrity.NewChecker(engine, store, integrityLocks, integrity.Config{
+		Grace: viper.GetDuration("integrity.grace"),
+	}, logger)
+	integrityChecker.SetReportHandler(func(report *integrity.Report) {
+		publishIntegrityReport(wsHub, report)
+	})
+	handler.SetIntegrityChecker(integrityChecker)
+
+	// 推荐返佣：taker 成交手续费按比例记为推荐人返佣，提取时入账到推荐人的托管余额
+	if viper.GetBool("referral.enabled") {
+		var referralStore referral.Store = referral.NewMemoryStore()
+		if dsn := viper.GetString("referral.postgres_dsn"); dsn != "" {
+			postgresStore, err := referral.NewPostgresStore(dsn)
+			if err != nil {
+				logger.WithError(err).Fatal("Failed to initialize referral store")
+			}
+			defer postgresStore.Close()
+			referralStore = postgresStore
+		} else {
+			logger.Warn("Referral store not configured - referrals kept in memory only")
+		}
+		program, err := referral.NewProgram(referralConfig(), referralStore, logger)
+		if err != nil {
+			logger.WithError(err).Fatal("Failed to initialize referral program")
+		}
+		program.SetPayer(&rebatePayer{balances: balanceManager})
+		go program.Run(engine.Subscribe(matching.SubscriptionOptions{
+			Name:       "referral",
+			EventTypes: []string{matching.EventOrderAdded, matching.EventAuctionUncrossed},
+		}))
+		handler.SetReferralProgram(program)
+		logger.Info("Referral program enabled")
+	}
+
+	// 流动性挖矿积分：按纪元统计 maker 成交额、报价紧密度与在线率
+	if viper.GetBool("rewards.enabled") {
+		rewardsEngine, err := rewards.NewEngine(engine, rewardsConfig(), logger)
+		if err != nil {
+			logger.WithError(err).Fatal("Failed to initialize liquidity rewards")
+		}
+		go rewardsEngine.Run(engine.Subscribe(matching.SubscriptionOptions{
+			Name:       "rewards",
+			EventTypes: []string{matching.EventOrderAdded, matching.EventAuctionUncrossed},
+		}))
+		rewardsEngine.Start()
+		handler.SetRewardsEngine(rewardsEngine)
+		logger.Info("Liquidity rewards enabled")
+	}
+
+	// 做市商报价义务：周期采样双边报价，考核周期结束时生成合规报告，不合规时告警并可提高费率
+	if viper.GetBool("mm_obligations.enabled") {
+		monitor, err := obligations.NewMonitor(engine, obligationConfig(), logger)
+		if err != nil {
+			logger.WithError(err).Fatal("Failed to initialize market maker obligations")
+		}
+		list, err := obligationList()
+		if err != nil {
+			logger.WithError(err).Fatal("Invalid market maker obligations")
+		}
+		for _, obligation := range list {
+			monitor.SetObligation(obligation)
+		}
+		monitor.SetNotifier(func(report *obligations.Report) {
+			notifyObligationReport(wsHub, auditor, report)
+		})
+		if viper.GetUint64("mm_obligations.penalty_fee_bps") > 0 {
+			monitor.SetFeeSetter(&chainFeeSetter{registry: chainRegistry})
+		}
+		monitor.Start()
+		handler.SetObligationMonitor(monitor)
+		logger.WithField("obligations", len(list)).Info("Market maker obligations monitor enabled")
+	}
+
+	// 持仓跟踪：按成交轧差用户各代币净持仓，风控据此限制风险暴露
+	positionTracker := positions.NewTracker(priceOracle)
+	go handlePositionEvents(engine.Subscribe(matching.SubscriptionOptions{
+		Name:       "positions",
+		EventTypes: []string{matching.EventOrderAdded, matching.EventAuctionUncrossed},
+	}), positionTracker)
+	positionTracker.SetQuoteSource(engine)
+	handler.SetPositionTracker(positionTracker)
+
+	// 订单流统计：用户下单、撤单、改单频率与成交比例，供管理接口与风控撤单率检查
+	flowConfig, err := orderFlowConfig()
+	if err != nil {
+		logger.WithError(err).Fatal("Invalid order flow config")
+	}
+	orderFlow := orderflow.NewTracker(flowConfig)
+	go handleOrderFlowEvents(engine.Subscribe(matching.SubscriptionOptions{
+		Name: "orderflow",
+		EventTypes: []string{
+			matching.EventOrderAdded, matching.EventOrderCancelled, matching.EventOrderExpired,
+			matching.EventOrderRejected, matching.EventOrderReduced, matching.EventAuctionUncrossed,
+		},
+	}), orderFlow)
+	handler.SetOrderFlowTracker(orderFlow)
+	if dir := viper.GetString("pnl.snapshot_dir"); dir != "" {
+		pnlStore, err := positions.NewFileSnapshotStore(dir)
+		if err != nil {
+			logger.WithError(err).Fatal("Failed to initialize PnL snapshot store")
+		}
+		positionTracker.RunDailySnapshots(pnlStore, logger)
+		handler.SetPnLSnapshotStore(pnlStore)
+	}
+
+	// 初始化风控
+	var riskController *riskcontrol.RiskController
+	if viper.GetBool("risk.enabled") {
+		riskController = initRiskController(engine, priceOracle, logger)
+		riskController.SetExposureSource(positionTracker)
+		riskController.SetOrderFlowSource(orderFlow)
+		riskController.StartCleanupTicker()
+		// 黑名单与账户冻结变化推送给用户，审计由管理接口记录
+		riskController.SetAlertHandler(wsHub.PublishRiskAlert)
+		riskController.SetViolationHandler(func(order *types.Order, result *riskcontrol.RiskCheckResult) {
+			publishRiskViolation(wsHub, order, result)
+		})
+		// 账户冻结对所有下单入口生效，并拦截提现
+		engine.AddAccountGate(riskController)
+		balanceManager.AddWithdrawalGate(riskController.AllowWithdrawal)
+		handler.SetRiskController(riskController)
+		logger.Info("Risk control enabled")
+	}
+
+	// 配置热更新：风控限额、提现手续费、交易对暂停状态与日志级别无需重启
+	newConfigReloader(riskController, balanceManager, breaker, logger).Start()
+
+	// 初始化历史数据导入
+	handler.SetImporter(importer.NewImporter(store, logger), viper.GetInt64("import.max_body_bytes"))
+
+	// 历史查询：配置了 PostgreSQL 时走索引查询，否则使用存储自身的实现
+	if dsn := viper.GetString("history.postgres_dsn"); dsn != "" {
+		historyStore, err := history.NewPostgresStore(dsn)
+		if err != nil {
+			logger.WithError(err).Fatal("Failed to initialize history store")
+		}
+		defer historyStore.Close()
+		handler.SetHistoryStore(historyStore)
+	} else if historyStore, ok := store.(history.Store); ok {
+		handler.SetHistoryStore(historyStore)
+	}
+
+	// 订单簿历史快照：配置了 PostgreSQL 时持久化，否则保存在内存中
+	var bookRecorder *booksnapshot.Recorder
+	if viper.GetBool("orderbook_history.enabled") {
+		var snapshotStore booksnapshot.Store = booksnapshot.NewMemoryStore()
+		if dsn := viper.GetString("orderbook_history.postgres_dsn"); dsn != "" {
+			postgresStore, err := booksnapshot.NewPostgresStore(dsn)
+			if err != nil {
+				logger.WithError(err).Fatal("Failed to initialize order book snapshot store")
+			}
+			defer postgresStore.Close()
+			snapshotStore = postgresStore
+		}
+		bookRecorder = booksnapshot.NewRecorder(engine, snapshotStore, booksnapshot.Config{
+			Interval:  viper.GetDuration("orderbook_history.interval"),
+			Depth:     viper.GetInt("orderbook_history.depth"),
+			Retention: viper.GetDuration("orderbook_history.retention"),
+		}, logger)
+		bookRecorder.Start()
+		handler.SetBookSnapshotStore(snapshotStore)
+		logger.WithField("interval", viper.GetDuration("orderbook_history.interval")).Info("📸 Order book snapshots enabled")
+	}
+
+	// 成交分析归档：逐笔成交批量写入 TimescaleDB / ClickHouse，聚合查询不经过交易存储
+	if viper.GetBool("analytics.enabled") {
+		sink, err := initAnalyticsSink()
+		if err != nil {
+			logger.WithError(err).Fatal("Failed to initialize analytics archive")
+		}
+		defer sink.Close()
+		archiver := analytics.NewArchiver(sink, analyticsConfig(), logger)
+		go archiver.Run(engine.Subscribe(matching.SubscriptionOptions{
+			Name:       "analytics",
+			EventTypes: []string{matching.EventOrderAdded, matching.EventAuctionUncrossed},
+			BufferSize: viper.GetInt("analytics.buffer_size"),
+		}))
+		handler.SetAnalytics(sink, archiver)
+		logger.WithField("driver", viper.GetString("analytics.driver")).Info("Analytics fill archive enabled")
+	}
+
+	// 数据生命周期：超过保留期的终态订单与已结算成交压缩导出到冷存储后从热存储删除
+	if viper.GetBool("lifecycle.enabled") {
+		cold, err := initColdStore()
+		if err != nil {
+			logger.WithError(err).Fatal("Failed to initialize cold storage")
+		}
+		orderPurger, _ := orderBackend(store).(lifecycle.OrderPurger)
+		fillPurger, _ := fillBackend(store).(lifecycle.FillPurger)
+		if orderPurger == nil && fillPurger == nil {
+			logger.Warn("Storage does not support purging orders or fills, data lifecycle archival disabled")
+		} else {
+			coldArchive, err := lifecycle.NewArchiver(orderPurger, fillPurger, cold, lifecycleConfig(), logger)
+			if err != nil {
+				logger.WithError(err).Fatal("Failed to initialize data lifecycle archival")
+			}
+			if err := coldArchive.Load(context.Background()); err != nil {
+				logger.WithError(err).Fatal("Failed to load archive manifests")
+			}
+			coldArchive.Start()
+			handler.SetColdArchive(coldArchive)
+			logger.WithFields(logrus.Fields{
+				"driver":         viper.GetString("lifecycle.driver"),
+				"retention_days": viper.GetInt("lifecycle.retention_days"),
+			}).Info("Data lifecycle archival enabled")
+		}
+	}
+
+	// 主备选举：只有持有租约的主实例撮合与结算，备用实例同步订单簿副本提供只读行情
+	var elector *leader.Elector
+	if viper.GetBool("leader.enabled") {
+		lease, err := leader.NewPostgresLease(viper.GetString("leader.postgres_dsn"), viper.GetString("leader.lease_name"))
+		if err != nil {
+			logger.WithError(err).Fatal("Failed to initialize leader lease")
+		}
+		defer lease.Close()
+		elector = leader.NewElector(lease, leader.Config{
+			ID:            instanceID(),
+			TTL:           viper.GetDuration("leader.lease_ttl"),
+			RenewInterval: viper.GetDuration("leader.renew_interval"),
+		}, logger)
+	}
+
+	// 排空停机：SIGTERM 或管理接口触发，完成后才关闭HTTP服务并退出
+	drainer := initDrainer(engine, chainRegistry, eventLog, bookRecorder, elector, logger)
+	handler.SetDrainer(drainer)
+
+	if elector != nil {
+		handoff := leader.NewHandoff(engine, viper.GetString("drain.snapshot_path"), viper.GetString("eventlog.path"), logger)
+		elector.OnElected(func() {
+			// 原主实例可能在本实例启动后继续追加事件日志
+			if eventLog != nil {
+				if err := eventLog.Resync(); err != nil {
+					logger.WithError(err).Error("Failed to resync event log")
+				}
+			}
+			// 接管订单簿成功后才开始结算，接管失败的实例不提交任何成交
+			if _, _, err := handoff.TakeOver(); err != nil {
+				logger.WithError(err).Error("Failed to take over order book")
+				drainer.Start("takeover_failed")
+				return
+			}
+			// 启动时推导的订单锁定不含主实例此后的下单与撤单，按接管后的挂单重建
+			if ledger != nil {
+				logger.WithField("locks", balanceManager.ReplaceOrderLocks(engine.OpenOrders())).Info("Order locks rebuilt after takeover")
+			}
+			for _, chain := range chainRegistry.Chains() {
+				if chain.Settlement != nil {
+					chain.Settlement.Start()
+					readiness.Done(settlementReadyName(chain.ChainID))
+				}
+			}
+			readiness.Done(readyOrderBook)
+		})
+		// 失去租约后不再撮合：排空退出，由进程管理器以备用实例重新启动
+		elector.OnDemoted(func() {
+			drainer.Start("lost_leadership")
+		})
+		handoff.Start(viper.GetDuration("leader.sync_interval"), elector.IsLeader)
+		elector.Start()
+		handler.SetElector(elector)
+		logger.WithField("id", elector.Status().ID).Info("Leader election enabled, starting as standby")
+	}
+
+	// 单实例模式没有需要接管的订单簿，余额锁定已在上文由存储中的活跃订单恢复；主备模式在接管时重建
+	if elector == nil {
+		readiness.Done(readyOrderBook)
+	}
+	handler.SetReadiness(readiness)
 
 	// 设置路由
-	router := setupRoutes(handler, wsHub)
+	router := setupRoutes(handler)
 
 	// 启动HTTP服务器
 	server := &http.Server{
@@ -103,10 +848,15 @@ func main() {
 		}
 	}()
 
-	// 等待中断信号
+	// 等待中断信号或管理接口触发的排空完成
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
+	select {
+	case sig := <-quit:
+		status := drainer.Drain("signal")
+		logger.WithFields(logrus.Fields{"signal": sig.String(), "steps": status.Steps}).Info("Drain finished")
+	case <-drainer.Done():
+	}
 
 	logger.Info("Shutting down server...")
 
@@ -131,10 +881,187 @@ func initConfig() {
 	viper.SetDefault("server.address", ":8084")
 	viper.SetDefault("server.read_timeout", "15s")
 	viper.SetDefault("server.write_timeout", "15s")
+	viper.SetDefault("server.docs_enabled", true) // 提供 OpenAPI 文档与 Swagger UI
 	viper.SetDefault("log.level", "info")
 	viper.SetDefault("log.format", "json")
 	viper.SetDefault("blockchain.chain_id", 31337)
 	viper.SetDefault("blockchain.contract_address", "0xf4B146FbA71F41E0592668ffbF264F1D186b2Ca8")
+	viper.SetDefault("blockchain.event_checkpoint_file", "data/event_checkpoints.json")
+	viper.SetDefault("blockchain.reconnect_backoff", "1s")
+	viper.SetDefault("blockchain.reconnect_max_backoff", "1m")
+	viper.SetDefault("trading.require_signed_cancel", false)
+	viper.SetDefault("auth.require_api_key", false)
+	viper.SetDefault("auth.signature_window", "30s")
+	viper.SetDefault("auth.verify_order_signatures", false) // 下单校验 EIP-712 订单签名
+	viper.SetDefault("auth.signature_diagnostics", false)   // 签名校验失败时返回摘要与恢复地址，便于对接排查
+	viper.SetDefault("trading.signature_ttl", "0s")
+	viper.SetDefault("trading.signature_ttl_sweep_interval", "1m")
+	viper.SetDefault("trading.expiry_sweep_interval", "30s")
+	viper.SetDefault("trading.market_min_liquidity", 0)
+	viper.SetDefault("trading.maker_fee_bps", 25) // 与结算合约 protocolFeeRate 一致，用于订单的 fee_paid 汇总
+	viper.SetDefault("trading.taker_fee_bps", 25)
+	viper.SetDefault("trading.price_band.max_deviation_percent", 50) // 限价偏离最新成交价的上限，0表示不限制
+	viper.SetDefault("trading.price_band.dynamic.trigger_percent", 10) // 窗口内急涨急跌超过该幅度时收紧价格带，0表示不启用
+	viper.SetDefault("trading.price_band.dynamic.window", "5m")
+	viper.SetDefault("trading.price_band.dynamic.tight_percent", 5)
+	viper.SetDefault("trading.price_band.dynamic.relax_duration", "15m")
+	viper.SetDefault("matching.event_buffer", 10000)
+	viper.SetDefault("matching.event_overflow", "expand")
+	viper.SetDefault("matching.event_spill_dir", "data/event_spill")
+	viper.SetDefault("trading.halted_pairs", []string{})
+	viper.SetDefault("auction.resume_duration", "0s")
+	viper.SetDefault("eventlog.path", "")
+	viper.SetDefault("eventbus.enabled", false)
+	viper.SetDefault("eventbus.driver", "kafka")
+	viper.SetDefault("eventbus.kafka.brokers", []string{"localhost:9092"})
+	viper.SetDefault("eventbus.nats.url", "nats://localhost:4222")
+	viper.SetDefault("eventbus.nats.stream", "ORDERBOOK")
+	viper.SetDefault("eventbus.topic_prefix", "orderbook")
+	viper.SetDefault("eventbus.book_depth", 50)
+	viper.SetDefault("eventbus.buffer_size", 10000)
+	viper.SetDefault("eventbus.publish_timeout", "10s")
+	viper.SetDefault("eventbus.retry_backoff", "500ms")
+	viper.SetDefault("eventbus.max_backoff", "30s")
+	viper.SetDefault("referral.enabled", false)
+	viper.SetDefault("referral.taker_fee_bps", 25)
+	viper.SetDefault("referral.rebate_share", 0.2)
+	viper.SetDefault("referral.postgres_dsn", "")
+	viper.SetDefault("rewards.enabled", false)
+	viper.SetDefault("rewards.epoch_length", "24h")
+	viper.SetDefault("rewards.sample_interval", "1m")
+	viper.SetDefault("rewards.max_spread", 0.02)
+	viper.SetDefault("rewards.volume_weight", 0.5)
+	viper.SetDefault("rewards.spread_weight", 0.3)
+	viper.SetDefault("rewards.uptime_weight", 0.2)
+	viper.SetDefault("rewards.retain_epochs", 30)
+	viper.SetDefault("mm_obligations.enabled", false)
+	viper.SetDefault("mm_obligations.sample_interval", "10s")
+	viper.SetDefault("mm_obligations.report_period", "24h")
+	viper.SetDefault("mm_obligations.penalty_fee_bps", 0)
+	viper.SetDefault("mm_obligations.retain_reports", 1000)
+	viper.SetDefault("simulation.enabled", false)
+	viper.SetDefault("simulation.max_seed_amount", 1000000)
+	viper.SetDefault("settlement.enabled", false)
+	viper.SetDefault("settlement.max_attempts", 5)
+	viper.SetDefault("settlement.retry_base_backoff", "5s")
+	viper.SetDefault("settlement.retry_max_backoff", "5m")
+	viper.SetDefault("settlement.simulate_fills", true) // 提交前逐笔 eth_call 模拟，剔除会回滚的成交
+	viper.SetDefault("settlement.indexed_batches", false) // 按订单去重提交（batchSettleTradesIndexed），需已升级的结算合约
+	viper.SetDefault("settlement.merkle.enabled", false)    // 只提交成交默克尔根（submitSettlementRoot），需已升级的结算合约
+	viper.SetDefault("settlement.merkle.pairs", []string{}) // 使用默克尔批次的交易对，为空时全部交易对
+	viper.SetDefault("settlement.merkle.store_path", "data/merkle_proofs.jsonl")
+	viper.SetDefault("settlement.signer.backend", "local") // local（链配置 private_key）、kms 或 vault
+	viper.SetDefault("settlement.signer.timeout", "10s")
+	viper.SetDefault("settlement.signer.vault.mount", "secret")
+	viper.SetDefault("settlement.signer.vault.field", "private_key")
+	viper.SetDefault("settlement.key_rotation_timeout", "10m") // 轮换时等待旧账户在途交易上链的最长时间
+	viper.SetDefault("settlement.gas_budget.hourly_eth", "0")  // 滚动一小时内结算交易的Gas支出上限（ETH），0 为不限制
+	viper.SetDefault("settlement.gas_budget.daily_eth", "0")   // 滚动一天内的上限，可用 settlement.gas_budget.chains.<chain_id>.* 按链覆盖
+	viper.SetDefault("settlement.gas_budget.action", "pause")  // 超出预算时 pause（暂停提交）或 slow（按 slow_interval 降低提交频率）
+	viper.SetDefault("settlement.gas_budget.slow_interval", "1m")
+	viper.SetDefault("import.max_body_bytes", 64<<20)
+	viper.SetDefault("history.postgres_dsn", "")
+	viper.SetDefault("storage.driver", "memory")
+	viper.SetDefault("audit.enabled", true)
+	viper.SetDefault("audit.postgres_dsn", "")
+	viper.SetDefault("drain.timeout", "2m")
+	viper.SetDefault("drain.snapshot_path", "data/engine_snapshot.json")
+	viper.SetDefault("leader.enabled", false)
+	viper.SetDefault("leader.driver", "postgres")
+	viper.SetDefault("leader.postgres_dsn", "")
+	viper.SetDefault("leader.lease_name", "matcher")
+	viper.SetDefault("leader.id", "")
+	viper.SetDefault("leader.lease_ttl", "15s")
+	viper.SetDefault("leader.renew_interval", "5s")
+	viper.SetDefault("leader.sync_interval", "5s")
+	viper.SetDefault("orderbook_history.enabled", true)
+	viper.SetDefault("orderbook_history.interval", "1m")
+	viper.SetDefault("orderbook_history.depth", 20)
+	viper.SetDefault("orderbook_history.retention", "24h")
+	viper.SetDefault("orderbook_history.postgres_dsn", "")
+	viper.SetDefault("analytics.enabled", false)
+	viper.SetDefault("analytics.driver", "timescale")
+	viper.SetDefault("analytics.timescale.dsn", "")
+	viper.SetDefault("analytics.clickhouse.url", "http://localhost:8123")
+	viper.SetDefault("analytics.clickhouse.database", "default")
+	viper.SetDefault("analytics.batch_size", 1000)
+	viper.SetDefault("analytics.flush_interval", "1s")
+	viper.SetDefault("analytics.insert_timeout", "10s")
+	viper.SetDefault("analytics.retry_backoff", "500ms")
+	viper.SetDefault("analytics.max_backoff", "30s")
+	viper.SetDefault("analytics.buffer_size", 10000)
+	viper.SetDefault("integrity.grace", "1m")
+	viper.SetDefault("health.timeout", "2s")
+	viper.SetDefault("health.cache_ttl", "1s")
+	viper.SetDefault("health.max_event_backlog", 5000)
+	viper.SetDefault("health.max_block_age", "2m")
+	viper.SetDefault("health.max_settlement_queue", 500)
+	viper.SetDefault("system.halted", false)
+	viper.SetDefault("system.halt_reason", "")
+	viper.SetDefault("lifecycle.enabled", false)
+	viper.SetDefault("lifecycle.retention_days", 90)
+	viper.SetDefault("lifecycle.interval", "1h")
+	viper.SetDefault("lifecycle.batch_size", 5000)
+	viper.SetDefault("lifecycle.driver", "file")
+	viper.SetDefault("lifecycle.file.dir", "data/archive")
+	viper.SetDefault("lifecycle.s3.region", "us-east-1")
+	viper.SetDefault("lifecycle.s3.prefix", "orderbook")
+	viper.SetDefault("wallet.enforce_balances", false)
+	viper.SetDefault("wallet.postgres_dsn", "")
+	viper.SetDefault("intake.dedup.enabled", true)
+	viper.SetDefault("intake.dedup.window", "10m")
+	viper.SetDefault("nonce.enabled", true)
+	viper.SetDefault("nonce.chain_sync_interval", "30s")
+	viper.SetDefault("preflight.mode", "off") // off、deposit（结算合约托管余额）或 allowance（钱包余额与授权），preflight.pairs.<pair> 按交易对覆盖
+	viper.SetDefault("preflight.timeout", "2s")
+	viper.SetDefault("preflight.fail_open", true) // 链上查询失败时放行订单
+	viper.SetDefault("compliance.enabled", false)
+	viper.SetDefault("compliance.denylist_file", "") // 每行一个地址，地址后可跟原因
+	viper.SetDefault("compliance.api.url", "")       // Chainalysis Sanctions API 兼容接口
+	viper.SetDefault("compliance.cache_ttl", "1h")
+	viper.SetDefault("compliance.timeout", "2s")
+	viper.SetDefault("compliance.fail_open", false) // 筛查接口不可用时拒绝下单与提现
+	viper.SetDefault("risk.enabled", false)
+	viper.SetDefault("risk.enable_balance_check", false)
+	viper.SetDefault("risk.max_price_deviation", 10)
+	viper.SetDefault("risk.max_exposure", 0)                           // 持仓名义金额上限（报价代币计），0 表示不限制
+	viper.SetDefault("risk.blacklist_file", "blacklist.json")          // 黑名单持久化，为空时仅保存在内存
+	viper.SetDefault("pnl.snapshot_dir", "data/pnl")                   // 每日盈亏快照目录，为空时不记录
+	viper.SetDefault("orderflow.windows", []string{"1m", "15m", "1h"}) // 最长窗口需覆盖风控撤单率窗口（1h）
+	viper.SetDefault("orderflow.bucket_size", "10s")
+	viper.SetDefault("orderflow.replace_window", "1s") // 撤单后该时间内同交易对同方向的新订单计为改单
+	viper.SetDefault("oracle.max_trade_age", "5m")
+	viper.SetDefault("oracle.max_age", "10m")
+	viper.SetDefault("oracle.cex.cache_ttl", "5s") // CEX 行情后台刷新间隔
+	viper.SetDefault("circuit_breaker.enabled", false)
+	viper.SetDefault("circuit_breaker.max_move_percent", 10)
+	viper.SetDefault("circuit_breaker.window", "5m")
+	viper.SetDefault("circuit_breaker.halt_duration", "5m")
+	viper.SetDefault("websocket.acl_file", "ws_acl.json")
+	viper.SetDefault("listing.file", "pair_listings.json")                 // 交易对上线登记，为空时仅保存在内存
+	viper.SetDefault("listing.quote_priority", pairs.DefaultQuotePriority) // 未上线代币对的计价代币优先级，靠前者为计价代币
+	viper.SetDefault("websocket.send_buffer", 256)
+	viper.SetDefault("websocket.slow_consumer_policy", "conflate")
+	viper.SetDefault("websocket.ping_interval", "30s")
+	viper.SetDefault("websocket.pong_timeout", "40s")
+	viper.SetDefault("websocket.write_timeout", "10s")
+	viper.SetDefault("websocket.book_conflation", "100ms")
+	viper.SetDefault("websocket.bridge.enabled", false)
+	viper.SetDefault("websocket.bridge.nats_url", "nats://localhost:4222")
+	viper.SetDefault("websocket.bridge.subject_prefix", "wsbridge") // 不要落在 eventbus.topic_prefix 下，否则会被 JetStream stream 持久化
+	viper.SetDefault("stats.large_trade_min_notional", 100000)
+	viper.SetDefault("stats.large_trade_retention", "24h")
+	viper.SetDefault("stats.max_large_trades", 1000)
+	viper.SetDefault("surveillance.enabled", false)
+	viper.SetDefault("liquidity.enabled", false)
+	viper.SetDefault("liquidity.refresh_interval", "5s")
+	viper.SetDefault("load_shedding.enabled", false)
+	viper.SetDefault("load_shedding.max_queue_depth", 8000)
+	viper.SetDefault("load_shedding.max_latency", "500ms")
+	viper.SetDefault("load_shedding.recover_period", "30s")
+	viper.SetDefault("load_shedding.user_order_rate", 1)
+	viper.SetDefault("load_shedding.user_order_burst", 5)
+	viper.SetDefault("load_shedding.check_interval", "1s")
 
 	if err := viper.ReadInConfig(); err != nil {
 		if _, ok := err.(viper.ConfigFileNotFoundError); ok {
@@ -167,17 +1094,532 @@ func initLogger() *logrus.Logger {
 		})
 	}
 
-	return logger
-}
+	return logger
+}
+
+// initStorage 初始化存储
+// 订单、成交、统计可分别配置后端（storage.<orders|fills|stats>.driver，未配置时使用 storage.driver），
+// 同名驱动共享同一实例；三者使用同一后端时直接返回该后端，保留其可选能力
+func initStorage() (storage.Storage, error) {
+	backends := make(map[string]storage.Storage)
+	open := func(concern string) (storage.Storage, error) {
+		driver := storageDriver(concern)
+		if backend, exists := backends[driver]; exists {
+			return backend, nil
+		}
+		backend, err := newStorageBackend(driver)
+		if err != nil {
+			return nil, fmt.Errorf("storage.%s: %w", concern, err)
+		}
+		backends[driver] = backend
+		return backend, nil
+	}
+
+	orders, err := open("orders")
+	if err != nil {
+		return nil, err
+	}
+	fills, err := open("fills")
+	if err != nil {
+		return nil, err
+	}
+	stats, err := open("stats")
+	if err != nil {
+		return nil, err
+	}
+	if len(backends) == 1 {
+		return orders, nil
+	}
+	return storage.NewComposite(orders, fills, stats), nil
+}
+
+// initRiskController 初始化风控控制器
+func initRiskController(engine *matching.MatchingEngine, priceOracle oracle.PriceOracle, logger *logrus.Logger) *riskcontrol.RiskController {
+	// 未配置 Redis：下单与撤单限率在进程内计数，黑名单由 risk.blacklist_file 持久化
+	riskController := riskcontrol.NewRiskController(nil, riskConfig(), logger)
+	riskController.SetPriceOracle(priceOracle)
+	riskController.SetOrderCounter(engine)
+	if path := viper.GetString("risk.blacklist_file"); path != "" {
+		store, err := riskcontrol.NewFileBlacklistStore(path)
+		if err == nil {
+			err = riskController.SetBlacklistStore(store)
+		}
+		if err != nil {
+			logger.WithError(err).Fatal("Failed to load blacklist")
+		}
+	}
+	for pair, pairConfig := range riskPairConfigs() {
+		if err := riskController.SetPairConfig(pair, pairConfig); err != nil {
+			logger.WithError(err).WithField("trading_pair", pair).Fatal("Invalid pair risk config")
+		}
+	}
+
+	return riskController
+}
+
+// feeSchedule 由配置生成订单成交汇总使用的手续费率
+func feeSchedule() matching.FeeSchedule {
+	return matching.FeeSchedule{
+		MakerBps: decimal.NewFromFloat(viper.GetFloat64("trading.maker_fee_bps")),
+		TakerBps: decimal.NewFromFloat(viper.GetFloat64("trading.taker_fee_bps")),
+	}
+}
+
+// priceBands 由配置生成引擎价格带：trading.price_band.max_deviation_percent 为默认值，
+// trading.price_band.pairs.<交易对>.max_deviation_percent 按交易对覆盖，trading.price_band.dynamic.* 为急涨急跌后的动态收紧
+func priceBands() matching.PriceBands {
+	bands := matching.PriceBands{
+		Default: matching.PriceBand{MaxDeviationPercent: decimal.NewFromFloat(viper.GetFloat64("trading.price_band.max_deviation_percent"))},
+		Pairs:   make(map[string]matching.PriceBand),
+		Dynamic: matching.DynamicBand{
+			TriggerPercent: decimal.NewFromFloat(viper.GetFloat64("trading.price_band.dynamic.trigger_percent")),
+			Window:         viper.GetDuration("trading.price_band.dynamic.window"),
+			TightPercent:   decimal.NewFromFloat(viper.GetFloat64("trading.price_band.dynamic.tight_percent")),
+			RelaxDuration:  viper.GetDuration("trading.price_band.dynamic.relax_duration"),
+		},
+	}
+	for pair := range viper.GetStringMap("trading.price_band.pairs") {
+		key := fmt.Sprintf("trading.price_band.pairs.%s.max_deviation_percent", pair)
+		bands.Pairs[strings.ToUpper(pair)] = matching.PriceBand{MaxDeviationPercent: decimal.NewFromFloat(viper.GetFloat64(key))}
+	}
+	return bands
+}
+
+// riskConfig 由配置生成全局风控配置
+func riskConfig() *riskcontrol.RiskConfig {
+	config := riskcontrol.DefaultRiskConfig()
+	config.EnableBalanceCheck = viper.GetBool("risk.enable_balance_check")
+	config.MaxPriceDeviation = decimal.NewFromFloat(viper.GetFloat64("risk.max_price_deviation"))
+	config.MaxExposure = decimal.NewFromFloat(viper.GetFloat64("risk.max_exposure"))
+	return config
+}
+
+// riskPairConfigs 交易对覆盖配置：risk.pairs.<pair>.{min_order_amount,max_order_amount,max_price_deviation}
+func riskPairConfigs() map[string]*riskcontrol.PairRiskConfig {
+	configs := make(map[string]*riskcontrol.PairRiskConfig)
+	for pair := range viper.GetStringMap("risk.pairs") {
+		key := "risk.pairs." + pair
+		configs[strings.ToUpper(pair)] = &riskcontrol.PairRiskConfig{
+			MinOrderAmount:    optionalDecimal(key + ".min_order_amount"),
+			MaxOrderAmount:    optionalDecimal(key + ".max_order_amount"),
+			MaxPriceDeviation: optionalDecimal(key + ".max_price_deviation"),
+		}
+	}
+	return configs
+}
+
+// initChains 初始化链注册表
+// 优先使用 chains: 数组配置，未配置时由单链 blockchain: 配置生成
+func initChains(logger *logrus.Logger) *chains.Registry {
+	configs, err := chainConfigs()
+	if err != nil {
+		logger.WithError(err).Fatal("Invalid chains config")
+	}
+
+	registry, err := chains.NewRegistry(configs)
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to initialize chains")
+	}
+
+	// 链上事件处理进度，重启或断线重连后从上次处理的位置补齐事件
+	var checkpoint blockchain.EventCheckpoint
+	if path := viper.GetString("blockchain.event_checkpoint_file"); path != "" {
+		fileCheckpoint, err := blockchain.NewFileCheckpoint(path)
+		if err != nil {
+			logger.WithError(err).Fatal("Failed to load event checkpoint")
+		}
+		checkpoint = fileCheckpoint
+	}
+
+	for _, chain := range registry.Chains() {
+		fields := logrus.Fields{"chain_id": chain.ChainID, "name": chain.Name}
+		if chain.RPCURL == "" {
+			logger.WithFields(fields).Warn("Blockchain integration disabled for chain - no RPC URL provided")
+			continue
+		}
+		if viper.GetBool("simulation.enabled") {
+			logger.WithFields(fields).Info("Blockchain integration disabled for chain - simulation mode")
+			continue
+		}
+
+		chain.Client, err = blockchain.NewClient(
+			chain.RPCURL,
+			new(big.Int).SetUint64(chain.ChainID),
+			chain.PrivateKey,
+			chain.ContractAddress,
+			chain.SettlementAddress,
+			logger,
+		)
+		if err != nil {
+			logger.WithError(err).WithFields(fields).Fatal("Failed to initialize blockchain client")
+		}
+		if checkpoint != nil {
+			chain.Client.SetEventCheckpoint(checkpoint)
+		}
+		chain.Client.SetReconnectBackoff(viper.GetDuration("blockchain.reconnect_backoff"), viper.GetDuration("blockchain.reconnect_max_backoff"))
+		logger.WithFields(fields).Info("Blockchain client initialized")
+	}
+	return registry
+}
+
+// chainConfigs 读取链配置
+func chainConfigs() ([]chains.Config, error) {
+	var configs []chains.Config
+	if err := viper.UnmarshalKey("chains", &configs); err != nil {
+		return nil, err
+	}
+	if len(configs) == 0 {
+		configs = []chains.Config{{
+			ChainID:           viper.GetUint64("blockchain.chain_id"),
+			Name:              "default",
+			RPCURL:            viper.GetString("blockchain.rpc_url"),
+			PrivateKey:        viper.GetString("blockchain.private_key"),
+			ContractAddress:   viper.GetString("blockchain.contract_address"),
+			SettlementAddress: viper.GetString("blockchain.settlement_address"),
+		}}
+	}
+	return configs, nil
+}
+
+// initTokens 初始化代币注册表
+// 配置项：tokens: [{chain_id, address, symbol, decimals}]，chain_id 为空时归属默认链；未配置的代币通过 ERC-20 decimals() 查询
+func initTokens(registry *chains.Registry, logger *logrus.Logger) *tokens.Registry {
+	tokenRegistry := tokens.NewRegistry()
+
+	var configs []tokens.Token
+	if err := viper.UnmarshalKey("tokens", &configs); err != nil {
+		logger.WithError(err).Fatal("Invalid tokens config")
+	}
+	for _, token := range configs {
+		if token.ChainID == 0 {
+			token.ChainID = registry.Default().ChainID
+		}
+		if err := tokenRegistry.Register(token); err != nil {
+			logger.WithError(err).Fatal("Invalid token config")
+		}
+	}
+
+	for _, chain := range registry.Chains() {
+		if chain.Client != nil {
+			tokenRegistry.SetContractCaller(chain.ChainID, chain.Client.Backend())
+		}
+	}
+
+	logger.WithField("tokens", len(configs)).Info("Token registry initialized")
+	return tokenRegistry
+}
+
+// initSettlement 初始化各链的批量结算并接入撮合成交
+func initSettlement(registry *chains.Registry, engine *matching.MatchingEngine, store storage.Storage, merkleProofs merkle.Store, wsHub *websocket.Hub, auditor *audit.Recorder, logger *logrus.Logger) *settlement.Pipeline {
+	router := settlement.NewChainRouter()
+	pipeline := settlement.NewPipeline(router, store, logger)
+	if fillStore, ok := fillBackend(store).(settlement.FillStore); ok {
+		pipeline.SetFillStore(fillStore)
+	} else {
+		logger.Warn("Storage does not support fill updates - settlement status kept in memory only")
+	}
+
+	for _, chain := range registry.Chains() {
+		fields := logrus.Fields{"chain_id": chain.ChainID, "name": chain.Name}
+		if chain.RPCURL == "" {
+			logger.WithFields(fields).Warn("Settlement disabled for chain - no RPC URL provided")
+			continue
+		}
+
+		signer, err := settlementSigner(chain.ChainID)
+		if err != nil {
+			logger.WithError(err).WithFields(fields).Fatal("Failed to initialize settlement signer")
+		}
+		manager, err := blockchain.NewSettlementManager(
+			chain.RPCURL,
+			common.HexToAddress(chain.SettlementAddress),
+			signer,
+			new(big.Int).SetUint64(chain.ChainID),
+		)
+		if err != nil {
+			logger.WithError(err).WithFields(fields).Fatal("Failed to initialize settlement manager")
+		}
+
+		chainID := chain.ChainID
+		manager.SetStatusHandler(func(fillIDs []uuid.UUID, status types.SettlementStatus, txHash string, err error) {
+			pipeline.HandleStatus(fillIDs, status, txHash, err)
+			auditSettlement(auditor, chainID, fillIDs, status, txHash, err)
+			publishSettlementEvent(wsHub, chainID, fillIDs, status, txHash, err)
+		})
+		manager.SetSimulation(viper.GetBool("settlement.simulate_fills"))
+		manager.SetIndexedBatches(viper.GetBool("settlement.indexed_batches"))
+		if merkleProofs != nil {
+			manager.SetMerkleSettlement(merkleProofs, viper.GetStringSlice("settlement.merkle.pairs"))
+		}
+		manager.SetRetryPolicy(blockchain.RetryPolicy{
+			MaxAttempts: viper.GetInt("settlement.max_attempts"),
+			BaseBackoff: viper.GetDuration("settlement.retry_base_backoff"),
+			MaxBackoff:  viper.GetDuration("settlement.retry_max_backoff"),
+		})
+		if budget := initGasBudget(chain.ChainID, wsHub, logger); budget != nil {
+			manager.SetGasBudget(budget)
+		}
+		// 主备模式下由当选回调启动，备用实例不提交结算
+		if !viper.GetBool("leader.enabled") {
+			manager.Start()
+		}
+
+		chain.Settlement = manager
+		router.Add(chain.ChainID, manager)
+	}
+
+	go pipeline.Run(engine.Subscribe(matching.SubscriptionOptions{
+		Name:       "settlement",
+		EventTypes: []string{matching.EventOrderAdded, matching.EventAuctionUncrossed},
+	}))
+	return pipeline
+}
+
+// auditSettlement 结算批次提交、失败及模拟执行剔除写入审计日志（逐笔排队状态不记录）
+func auditSettlement(auditor *audit.Recorder, chainID uint64, fillIDs []uuid.UUID, status types.SettlementStatus, txHash string, err error) {
+	action := audit.ActionSettlementSubmit
+	switch status {
+	case types.SettlementStatusSubmitted, types.SettlementStatusFailed:
+	case types.SettlementStatusRejected:
+		action = audit.ActionSettlementReject
+	default:
+		return
+	}
+	if auditor == nil {
+		return
+	}
+
+	entry := &audit.Entry{
+		ActorType: audit.ActorSystem,
+		Actor:     "settlement",
+		Action:    action,
+		Resource:  txHash,
+		Details: map[string]interface{}{
+			"chain_id": chainID,
+			"status":   status,
+			"fill_ids": fillIDs,
+		},
+	}
+	if status != types.SettlementStatusSubmitted {
+		entry.Outcome = audit.OutcomeFailure
+	}
+	if err != nil {
+		entry.Details["error"] = err.Error()
+	}
+	auditor.Record(entry)
+}
+
+// initDrainer 初始化排空步骤：停止接单 → 等待事件消费 → 提交结算批次 → 刷新事件日志 → 保存订单簿快照 → 释放主实例租约
+func initDrainer(engine *matching.MatchingEngine, registry *chains.Registry, eventLog *eventlog.Writer, bookRecorder *booksnapshot.Recorder, elector *leader.Elector, logger *logrus.Logger) *drain.Drainer {
+	drainer := drain.NewDrainer(viper.GetDuration("drain.timeout"), logger)
+
+	drainer.AddStep("stop_accepting", func(ctx context.Context) error {
+		engine.StopAccepting()
+		return nil
+	})
+	drainer.AddStep("flush_events", func(ctx context.Context) error {
+		return drain.WaitFor(ctx, 100*time.Millisecond, func() bool {
+			return engine.EventQueueDepth() == 0
+		})
+	})
+	drainer.AddStep("flush_settlement", func(ctx context.Context) error {
+		pending := 0
+		err := drain.WaitFor(ctx, time.Second, func() bool {
+			pending = 0
+			for _, chain := range registry.Chains() {
+				if chain.Settlement != nil {
+					pending += chain.Settlement.Flush()
+				}
+			}
+			return pending == 0
+		})
+		if err != nil {
+			return fmt.Errorf("%d settlements still pending: %w", pending, err)
+		}
+		return nil
+	})
+	if eventLog != nil {
+		drainer.AddStep("flush_event_log", func(ctx context.Context) error {
+			return eventLog.Flush()
+		})
+	}
+	if bookRecorder != nil {
+		drainer.AddStep("record_book_history", func(ctx context.Context) error {
+			bookRecorder.Capture(time.Now())
+			return nil
+		})
+	}
+	drainer.AddStep("snapshot_orders", func(ctx context.Context) error {
+		// 备用实例的订单簿只是副本，不覆盖主实例的快照
+		if elector != nil && !elector.IsLeader() {
+			return nil
+		}
+		return drain.WriteSnapshot(viper.GetString("drain.snapshot_path"), engine.OpenOrders())
+	})
+	if elector != nil {
+		drainer.AddStep("release_leadership", func(ctx context.Context) error {
+			elector.Stop()
+			return nil
+		})
+	}
+	return drainer
+}
+
+// instanceID 主备选举使用的实例标识，未配置时由主机名和进程号生成
+func instanceID() string {
+	if id := viper.GetString("leader.id"); id != "" {
+		return id
+	}
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	return fmt.Sprintf("%s-%d", hostname, os.Getpid())
+}
+
+// initBalanceManager 初始化余额管理器及提现手续费配置
+// 配置项：wallet.fee_account，wallet.withdrawals.<token>.{flat_fee,gas_limit,gas_to_token,min_amount}
+func initBalanceManager(blockchainClient *blockchain.Client, logger *logrus.Logger) *wallet.BalanceManager {
+	balanceManager := wallet.NewBalanceManager(logger)
+	balanceManager.SetFeeAccount(viper.GetString("wallet.fee_account"))
+	if blockchainClient != nil {
+		balanceManager.SetGasPriceSource(blockchainClient.Backend())
+	}
+
+	if err := applyWithdrawalFees(balanceManager); err != nil {
+		logger.WithError(err).Fatal("Invalid withdrawal fee config")
+	}
+
+	return balanceManager
+}
+
+// applyWithdrawalFees 应用配置中的提现手续费
+func applyWithdrawalFees(balanceManager *wallet.BalanceManager) error {
+	for token := range viper.GetStringMap("wallet.withdrawals") {
+		key := "wallet.withdrawals." + token
+		config := &wallet.WithdrawalFeeConfig{
+			Token:      token,
+			FlatFee:    decimal.NewFromFloat(viper.GetFloat64(key + ".flat_fee")),
+			GasLimit:   viper.GetUint64(key + ".gas_limit"),
+			GasToToken: decimal.NewFromFloat(viper.GetFloat64(key + ".gas_to_token")),
+			MinAmount:  decimal.NewFromFloat(viper.GetFloat64(key + ".min_amount")),
+		}
+		if err := balanceManager.SetWithdrawalFeeConfig(config); err != nil {
+			return fmt.Errorf("token %s: %w", token, err)
+		}
+	}
+	return nil
+}
+
+// initLiquidityBot 初始化做市机器人
+// 配置项：liquidity.account，liquidity.pairs.<pair>.{base_token,quote_token,size,spread_bps,max_inventory}
+func initLiquidityBot(engine *matching.MatchingEngine, priceOracle oracle.PriceOracle, store storage.Storage, logger *logrus.Logger) *marketmaker.Quoter {
+	account := viper.GetString("liquidity.account")
+	if account == "" {
+		logger.Fatal("Liquidity bot enabled but liquidity.account is not set")
+	}
+
+	var pairs []*marketmaker.PairConfig
+	for pair := range viper.GetStringMap("liquidity.pairs") {
+		key := "liquidity.pairs." + pair
+		pairs = append(pairs, &marketmaker.PairConfig{
+			TradingPair:  strings.ToUpper(pair),
+			BaseToken:    viper.GetString(key + ".base_token"),
+			QuoteToken:   viper.GetString(key + ".quote_token"),
+			Size:         decimal.NewFromFloat(viper.GetFloat64(key + ".size")),
+			SpreadBps:    decimal.NewFromFloat(viper.GetFloat64(key + ".spread_bps")),
+			MaxInventory: decimal.NewFromFloat(viper.GetFloat64(key + ".max_inventory")),
+		})
+	}
+
+	return marketmaker.NewQuoter(account, engine, priceOracle, store, pairs, logger)
+}
+
+// initSurveillance 初始化成交异常检测器
+// 配置项：surveillance.*，交易对阈值 surveillance.markets.<pair>.* 覆盖默认值
+func initSurveillance(priceOracle oracle.PriceOracle, logger *logrus.Logger) *surveillance.Detector {
+	defaults := surveillanceConfig("surveillance", surveillance.DefaultConfig())
+	detector := surveillance.NewDetector(defaults, priceOracle, logger)
+
+	for pair := range viper.GetStringMap("surveillance.markets") {
+		detector.SetMarketConfig(strings.ToUpper(pair), surveillanceConfig("surveillance.markets."+pair, defaults))
+	}
+
+	return detector
+}
+
+// surveillanceConfig 在基础配置上应用指定前缀下已设置的阈值
+func surveillanceConfig(prefix string, base *surveillance.Config) *surveillance.Config {
+	config := *base
+	if value := optionalDecimal(prefix + ".max_index_deviation"); value != nil {
+		config.MaxIndexDeviation = *value
+	}
+	if value := optionalDecimal(prefix + ".volume_spike_multiplier"); value != nil {
+		config.VolumeSpikeMultiplier = *value
+	}
+	if viper.IsSet(prefix + ".volume_min_samples") {
+		config.VolumeMinSamples = viper.GetInt(prefix + ".volume_min_samples")
+	}
+	if viper.IsSet(prefix + ".repeat_count") {
+		config.RepeatCount = viper.GetInt(prefix + ".repeat_count")
+	}
+	if viper.IsSet(prefix + ".repeat_window") {
+		config.RepeatWindow = viper.GetDuration(prefix + ".repeat_window")
+	}
+	if viper.IsSet(prefix + ".trip_circuit_breaker") {
+		config.TripCircuitBreaker = viper.GetBool(prefix + ".trip_circuit_breaker")
+	}
+	if viper.IsSet(prefix + ".halt_duration") {
+		config.HaltDuration = viper.GetDuration(prefix + ".halt_duration")
+	}
+	return &config
+}
+
+// optionalDecimal 读取可选的数值配置
+func optionalDecimal(key string) *decimal.Decimal {
+	if !viper.IsSet(key) {
+		return nil
+	}
+	value := decimal.NewFromFloat(viper.GetFloat64(key))
+	return &value
+}
+
+// initPriceOracle 初始化参考价格来源
+// 外部价格（Chainlink、CEX）优先，撮合引擎内部价格兜底
+func initPriceOracle(engine *matching.MatchingEngine, blockchainClient *blockchain.Client, logger *logrus.Logger) oracle.PriceOracle {
+	maxAge := viper.GetDuration("oracle.max_age")
+	var sources []oracle.PriceOracle
+
+	if feeds := viper.GetStringMapString("oracle.chainlink.feeds"); len(feeds) > 0 {
+		if blockchainClient == nil {
+			logger.Warn("Chainlink feeds configured but blockchain client is disabled")
+		} else if chainlinkOracle, err := oracle.NewChainlinkOracle(blockchainClient.Backend(), feeds, maxAge); err != nil {
+			logger.WithError(err).Error("Failed to initialize Chainlink oracle")
+		} else {
+			sources = append(sources, chainlinkOracle)
+		}
+	}
+
+	if baseURL := viper.GetString("oracle.cex.base_url"); baseURL != "" {
+		cexOracle := oracle.NewCEXOracle(
+			baseURL,
+			viper.GetStringMapString("oracle.cex.symbols"),
+			viper.GetDuration("oracle.cex.cache_ttl"),
+			maxAge,
+		)
+		cexOracle.Start()
+		sources = append(sources, cexOracle)
+	}
+
+	sources = append(sources, oracle.NewEngineOracle(engine, viper.GetDuration("oracle.max_trade_age")))
+	priceOracle := oracle.NewFallbackOracle(sources...)
 
-// initStorage 初始化存储
-func initStorage() (storage.Storage, error) {
-	// 返回功能完整的内存存储实现
-	return NewMemoryStorage(), nil
+	logger.WithField("sources", priceOracle.Name()).Info("Price oracle initialized")
+	return priceOracle
 }
 
 // setupRoutes 设置路由
-func setupRoutes(handler *api.Handler, wsHub *websocket.Hub) *gin.Engine {
+func setupRoutes(handler *api.Handler) *gin.Engine {
 	if viper.GetString("log.level") != "debug" {
 		gin.SetMode(gin.ReleaseMode)
 	}
@@ -185,33 +1627,167 @@ func setupRoutes(handler *api.Handler, wsHub *websocket.Hub) *gin.Engine {
 	router := gin.New()
 	router.Use(handler.CORSMiddleware())
 	router.Use(handler.LoggerMiddleware())
+	router.Use(handler.LoadShedMiddleware())
+	router.Use(handler.APIKeyMiddleware())
 	router.Use(gin.Recovery())
 
+	router.NoRoute(handler.NoRoute)
+
+	// 编排系统探针
+	router.GET("/healthz", handler.Liveness)
+	router.GET("/readyz", handler.Readiness)
+
 	// API路由
+	// 私有接口按API密钥权限鉴权；钱包签名的撤单和创建密钥接口自带身份证明
+	read := handler.RequirePermission(session.PermissionRead)
+	trade := handler.RequirePermission(session.PermissionTrade)
+	leaderOnly := handler.RequireLeader()
 	v1 := router.Group("/api/v1")
+	// 紧急停机期间只读：放行查询与撤单
+	v1.Use(handler.ReadOnlyMiddleware())
 	{
 		v1.GET("/health", handler.HealthCheck)
-		v1.POST("/orders", handler.PlaceOrder)
-		v1.DELETE("/orders/:order_id", handler.CancelOrder)
-		v1.GET("/orders", handler.GetOrders)
-		v1.GET("/orders/:order_id", handler.GetOrder)
+		v1.GET("/time", handler.GetServerTime)
+		v1.GET("/errors", handler.GetErrorCodes)
+		if viper.GetBool("server.docs_enabled") {
+			v1.GET("/openapi.json", handler.GetOpenAPISpec)
+			v1.GET("/docs", handler.SwaggerUI)
+		}
+		v1.GET("/chains", handler.GetChains)
+		v1.GET("/pairs", handler.GetPairs)
+		v1.GET("/pairs/resolve", handler.ResolvePair)
+		v1.GET("/signing-info", handler.GetSigningInfo)
+		v1.POST("/signing-info/digest", handler.GetOrderDigest)
+		v1.POST("/orders", trade, leaderOnly, handler.PlaceOrder)
+		v1.DELETE("/orders/:order_id", trade, leaderOnly, handler.CancelOrder)
+		v1.POST("/orders/cancel", leaderOnly, handler.CancelOrderSigned)
+		v1.POST("/orders/cancel-below-nonce", leaderOnly, handler.CancelOrdersBelowNonce)
+		v1.GET("/orders", read, handler.GetOrders)
+		v1.GET("/orders/:order_id", read, handler.GetOrder)
+		v1.GET("/orders/:order_id/queue-position", read, handler.GetQueuePosition)
+		v1.GET("/orders/:order_id/executions", read, handler.GetOrderExecutions)
 		v1.GET("/orderbook/:trading_pair", handler.GetOrderBook)
+		v1.GET("/orderbook/:trading_pair/l3", handler.GetOrderBookL3)
+		v1.GET("/orderbook/:trading_pair/history", handler.GetOrderBookHistory)
+		v1.GET("/bbo", handler.GetAllBBO)
+		v1.GET("/bbo/:trading_pair", handler.GetBBO)
+		v1.GET("/liquidity/:trading_pair", handler.GetLiquidityMetrics)
+		v1.GET("/engine/stats/:trading_pair", handler.GetEngineStats)
 		v1.GET("/trades", handler.GetTrades)
+		v1.GET("/trades/large", handler.GetLargeTrades)
+		v1.GET("/fills/:id/settlement", handler.GetFillSettlement)
+		v1.GET("/fills/:id/proof", handler.GetFillProof)
+		v1.GET("/candles/:trading_pair", handler.GetCandles)
 		v1.GET("/stats/:trading_pair", handler.GetStats)
+		v1.GET("/balances/:address", read, handler.GetBalances)
+		v1.GET("/balances/:address/:token", read, handler.GetTokenBalance)
+		v1.GET("/withdrawals/fees", handler.GetWithdrawalFees)
+		v1.GET("/withdrawals/quote", handler.QuoteWithdrawal)
+		v1.POST("/withdrawals", trade, leaderOnly, handler.RequestWithdrawal)
+		v1.GET("/account/sessions", read, handler.ListSessions)
+		v1.DELETE("/account/sessions/:session_id", trade, handler.RevokeSession)
+		v1.POST("/account/api-keys", handler.CreateAPIKey)
+		v1.GET("/account/:address", read, handler.GetAccountSummary)
+		v1.GET("/account/:address/nonce", read, handler.GetAccountNonce)
+		v1.GET("/account/:address/positions", read, handler.GetPositions)
+		v1.GET("/account/:address/pnl", read, handler.GetPnL)
+		v1.GET("/account/:address/pnl/history", read, handler.GetPnLHistory)
+		v1.GET("/account/:address/unsettled-fills", read, handler.GetUnsettledFills)
+		v1.GET("/account/:address/export", read, handler.ExportAccountHistory)
+		v1.POST("/simulation/balances", trade, handler.SeedBalance)
+		v1.POST("/referrals", trade, handler.RegisterReferral)
+		v1.POST("/referrals/claim", trade, handler.ClaimReferralRebate)
+		v1.GET("/referrals/:address", read, handler.GetReferralSummary)
+		v1.GET("/rewards/epochs", handler.GetRewardsEpochs)
+		v1.GET("/rewards/leaderboard", handler.GetRewardsLeaderboard)
+		v1.GET("/analytics/volume", handler.GetVolumeByPairDay)
+		v1.GET("/analytics/top-traders", handler.GetTopTraders)
+		v1.GET("/archive/orders/:order_id", read, handler.GetArchivedOrder)
+		v1.GET("/rewards/scores/:address", handler.GetRewardsScore)
 	}
 
-	// WebSocket路由
-	router.GET("/ws", func(c *gin.Context) {
-		wsHub.HandleWebSocket(c.Writer, c.Request)
-	})
+	// 管理路由
+	admin := router.Group("/admin/v1")
+	// 审计中间件在鉴权之前，未通过鉴权的管理请求同样留痕
+	admin.Use(handler.AuditMiddleware())
+	admin.Use(handler.AdminAuthMiddleware(viper.GetString("admin.token")))
+	{
+		admin.GET("/risk/pairs", handler.GetRiskPairConfigs)
+		admin.PUT("/risk/pairs/:trading_pair", handler.SetRiskPairConfig)
+		admin.DELETE("/risk/pairs/:trading_pair", handler.DeleteRiskPairConfig)
+		admin.GET("/blacklist", handler.GetBlacklist)
+		admin.POST("/blacklist", handler.AddToBlacklist)
+		admin.DELETE("/blacklist/:address", handler.RemoveFromBlacklist)
+		admin.GET("/users/frozen", handler.GetFrozenAccounts)
+		admin.POST("/users/:address/freeze", handler.FreezeAccount)
+		admin.DELETE("/users/:address/freeze", handler.UnfreezeAccount)
+		admin.GET("/circuit-breaker", handler.GetCircuitBreakerStates)
+		admin.POST("/circuit-breaker/:trading_pair/halt", handler.HaltTradingPair)
+		admin.POST("/circuit-breaker/:trading_pair/resume", handler.ResumeTradingPair)
+		admin.GET("/orderflow", handler.GetOrderFlowTop)
+		admin.GET("/orderflow/:address", handler.GetOrderFlow)
+		admin.POST("/pairs", handler.ListPair)
+		admin.POST("/pairs/:trading_pair/auction", handler.ScheduleOpeningAuction)
+		admin.PUT("/pairs/:trading_pair/min-resting", handler.SetPairMinResting)
+		admin.POST("/pairs/:trading_pair/halt", handler.HaltListedPair)
+		admin.POST("/pairs/:trading_pair/delist", handler.DelistPair)
+		admin.GET("/auctions", handler.GetAuctions)
+		admin.POST("/auctions/:trading_pair", handler.StartAuction)
+		admin.POST("/auctions/:trading_pair/uncross", handler.UncrossAuction)
+		admin.GET("/load-shedding", handler.GetLoadShedStatus)
+		admin.GET("/system/halt", handler.GetSystemHalt)
+		admin.POST("/system/halt", handler.HaltSystem)
+		admin.POST("/system/resume", handler.ResumeSystem)
+		admin.GET("/intake/dedup", handler.GetIntakeDedupStats)
+		admin.GET("/analytics/archive", handler.GetAnalyticsArchiveStats)
+		admin.GET("/archive", handler.GetColdArchiveStats)
+		admin.POST("/archive/run", handler.RunColdArchive)
+		admin.GET("/archive/fills/:id", handler.GetArchivedFill)
+		admin.POST("/integrity/check", handler.RunIntegrityCheck)
+		admin.GET("/engine/consumers", handler.GetEventConsumers)
+		admin.GET("/surveillance/alerts", handler.GetSurveillanceAlerts)
+		admin.GET("/surveillance/config", handler.GetSurveillanceConfig)
+		admin.PUT("/surveillance/config/:trading_pair", handler.SetSurveillanceConfig)
+		admin.GET("/ws/connections", handler.GetWebSocketConnections)
+		admin.GET("/ws/acl", handler.GetTopicGrants)
+		admin.GET("/liquidity", handler.GetLiquidityBotStatus)
+		admin.POST("/liquidity/kill", handler.KillLiquidityBot)
+		admin.POST("/liquidity/resume", handler.ResumeLiquidityBot)
+		admin.POST("/ws/acl/:address", handler.GrantTopic)
+		admin.POST("/deposits", handler.CreditDeposit)
+		admin.POST("/import/trades", handler.ImportTrades)
+		admin.POST("/import/candles", handler.ImportCandles)
+		admin.GET("/settlement/dead-letters", handler.GetSettlementDeadLetters)
+		admin.GET("/settlement/metrics", handler.GetSettlementMetrics)
+		admin.POST("/settlement/rotate-key", handler.RotateSettlementKey)
+		admin.POST("/settlement/dead-letters/:id/retry", handler.RetrySettlementDeadLetter)
+		admin.POST("/settlement/dead-letters/:id/void", handler.VoidSettlementDeadLetter)
+		admin.GET("/drain", handler.GetDrainStatus)
+		admin.POST("/drain", handler.StartDrain)
+		admin.GET("/leader", handler.GetLeaderStatus)
+		admin.GET("/audit", handler.GetAuditLog)
+		admin.GET("/mm-obligations", handler.GetMMObligations)
+		admin.GET("/mm-obligations/reports", handler.GetMMObligationReports)
+		admin.PUT("/mm-obligations/:address/:trading_pair", handler.SetMMObligation)
+		admin.DELETE("/mm-obligations/:address/:trading_pair", handler.DeleteMMObligation)
+		admin.DELETE("/ws/acl/:address", handler.RevokeTopic)
+	}
+
+	// WebSocket路由，连接身份与客户端IP沿用 REST 中间件的解析结果
+	router.GET("/ws", handler.WebSocketHandshake(viper.GetString("admin.token")))
+
+	// 接口文档按实际注册的路由生成
+	handler.SetRoutes(router.Routes())
 
 	return router
 }
 
 // handleBlockchainEvents 处理区块链事件
-func handleBlockchainEvents(client *blockchain.Client, engine *matching.MatchingEngine, logger *logrus.Logger) {
+// 链上下单进入撮合引擎并写入存储；链上撤单或完全成交时将对应挂单移出链下订单簿并更新存储，避免链下订单簿与链上意图不一致
+func handleBlockchainEvents(client *blockchain.Client, tokenRegistry *tokens.Registry, pairNames *pairs.Canonicalizer, engine *matching.MatchingEngine, store storage.Storage, deduper *intake.Deduper, logger *logrus.Logger) {
 	ctx := context.Background()
 	eventChan := make(chan *blockchain.OrderEvent, 1000)
+	chainID := client.ChainID()
 	
 	// 订阅订单事件
 	if err := client.SubscribeToOrderEvents(ctx, eventChan); err != nil {
@@ -222,64 +1798,210 @@ func handleBlockchainEvents(client *blockchain.Client, engine *matching.Matching
 	logger.Info("Started blockchain event listener")
 	
 	for event := range eventChan {
-		// 将区块链订单事件转换为引擎订单
-		order := &types.Order{
-			ID:          uuid.New(), // 生成新的UUID
-			UserAddress: event.Trader.Hex(),
-			TradingPair: fmt.Sprintf("%s-%s", event.TokenA.Hex(), event.TokenB.Hex()),
-			BaseToken:   event.TokenA.Hex(),
-			QuoteToken:  event.TokenB.Hex(),
-			Price:       decimal.NewFromBigInt(event.Price, -6), // 假设USDC是6位小数
-			Amount:      decimal.NewFromBigInt(event.Amount, -18), // 假设WETH是18位小数
-			CreatedAt:   time.Unix(int64(event.Timestamp), 0),
-		}
-		
-		if event.IsBuy {
-			order.Side = types.OrderSideBuy
-		} else {
-			order.Side = types.OrderSideSell
+		var err error
+		switch event.Kind {
+		case blockchain.OrderEventCancelled:
+			err = processBlockchainOrderClose(chainID, engine, store, event, types.StatusReasonChainCancel, logger)
+		case blockchain.OrderEventFilled:
+			err = processBlockchainOrderClose(chainID, engine, store, event, types.StatusReasonChainFilled, logger)
+		default:
+			// 价格以报价代币精度表示，数量以基础代币精度表示
+			baseToken, quoteToken := event.TokenA.Hex(), event.TokenB.Hex()
+			var price decimal.Decimal
+			price, err = tokenRegistry.ToDecimal(chainID, quoteToken, event.Price)
+			if err == nil {
+				var amount decimal.Decimal
+				if amount, err = tokenRegistry.ToDecimal(chainID, baseToken, event.Amount); err == nil {
+					err = processBlockchainOrder(client, tokenRegistry, pairNames, engine, store, deduper, event, price, amount, logger)
+				}
+			}
+		}
+		if err != nil {
+			logger.WithError(err).WithFields(logrus.Fields{
+				"order_id": event.OrderID.String(),
+				"event":    event.Kind,
+			}).Warn("Blockchain order event rejected")
 		}
-		
-		// 添加到撮合引擎
-		fills := engine.AddOrder(order)
-		
+		client.CommitOrderEvent(event)
+	}
+}
+
+// blockchainOrderID 链上订单对应的引擎订单ID，由链ID与合约订单ID确定性生成，撤单与成交事件据此找到订单
+func blockchainOrderID(chainID uint64, orderID *big.Int) uuid.UUID {
+	return uuid.NewSHA1(uuid.NameSpaceOID, []byte(fmt.Sprintf("orderbook:%d:%s", chainID, orderID.String())))
+}
+
+// processBlockchainOrderClose 链上撤单或完全成交：挂单移出订单簿（发布撤单事件），存储中的订单置为撤销或成交
+// 订单已在链下终止（成交、撤销、过期）时只记录日志，重复事件不会重复处理
+func processBlockchainOrderClose(chainID uint64, engine *matching.MatchingEngine, store storage.Storage, event *blockchain.OrderEvent, reason string, logger *logrus.Logger) error {
+	orderID := blockchainOrderID(chainID, event.OrderID)
+	order, err := store.GetOrder(orderID)
+	if err != nil {
+		return fmt.Errorf("order not found: %w", err)
+	}
+	if event.Kind == blockchain.OrderEventCancelled && !strings.EqualFold(order.UserAddress, event.Trader.Hex()) {
+		return fmt.Errorf("cancel trader %s does not own order", event.Trader.Hex())
+	}
+
+	snapshot, removed := engine.CancelOrderWithReason(orderID, order.TradingPair, reason)
+	if !removed {
 		logger.WithFields(logrus.Fields{
 			"order_id": event.OrderID.String(),
-			"trader":   event.Trader.Hex(),
-			"pair":     order.TradingPair,
-			"side":     order.Side,
-			"fills":    len(fills),
-		}).Info("Processed blockchain order")
-		
-		// 处理成交记录，更新区块链状态
-		for _, fill := range fills {
-			go func(f *types.Fill) {
-				// 执行区块链交易
-				buyer := common.HexToAddress(f.TakerOrderID.String()) // 简化处理
-				seller := common.HexToAddress(f.MakerOrderID.String())
-				tokenA := common.HexToAddress(order.BaseToken)
-				tokenB := common.HexToAddress(order.QuoteToken)
-				
-				tx, err := client.ExecuteTrade(
-					buyer, seller, tokenA, tokenB,
-					f.Amount.BigInt(), f.Price.BigInt(), false,
-				)
-				if err != nil {
-					logger.WithError(err).Error("Failed to execute blockchain trade")
-					return
-				}
-				
-				logger.WithField("tx_hash", tx.Hash().Hex()).Info("Blockchain trade executed")
-			}(fill)
+			"status":   order.Status,
+			"event":    event.Kind,
+		}).Info("Blockchain order already closed off-chain")
+		return nil
+	}
+
+	order.FilledAmount = snapshot.FilledAmount
+	order.AvgFillPrice = snapshot.AvgFillPrice
+	order.QuoteFilled = snapshot.QuoteFilled
+	order.FeePaid = snapshot.FeePaid
+	order.Status = types.OrderStatusCancelled
+	if event.Kind == blockchain.OrderEventFilled {
+		order.FilledAmount = order.Amount
+		order.Status = types.OrderStatusFilled
+	}
+	order.StatusReason = reason
+	order.UpdatedAt = snapshot.UpdatedAt
+	if err := store.UpdateOrder(order); err != nil {
+		return fmt.Errorf("failed to update order: %w", err)
+	}
+
+	logger.WithFields(logrus.Fields{
+		"order_id":  event.OrderID.String(),
+		"engine_id": orderID.String(),
+		"pair":      order.TradingPair,
+		"status":    order.Status,
+		"event":     event.Kind,
+	}).Info("Blockchain order removed from book")
+	return nil
+}
+
+// processBlockchainOrder 将区块链订单事件转换为引擎订单并撮合，订单与成交写入存储，成交回写区块链
+// 交易对名称取代币对的规范名称；代币顺序与规范交易对相反的订单记为被拒绝，不进入订单簿；
+// 已经由 REST 入口提交的相同意图记为被拒绝的重复订单，不进入订单簿
+func processBlockchainOrder(client *blockchain.Client, tokenRegistry *tokens.Registry, pairNames *pairs.Canonicalizer, engine *matching.MatchingEngine, store storage.Storage, deduper *intake.Deduper, event *blockchain.OrderEvent, price, amount decimal.Decimal, logger *logrus.Logger) error {
+	chainID := client.ChainID()
+	pair := pairNames.Resolve(chainID, event.TokenA.Hex(), event.TokenB.Hex())
+	order := &types.Order{
+		ID:          blockchainOrderID(chainID, event.OrderID),
+		UserAddress: event.Trader.Hex(),
+		TradingPair: pair.TradingPair,
+		ChainID:     chainID,
+		BaseToken:   event.TokenA.Hex(),
+		QuoteToken:  event.TokenB.Hex(),
+		Type:        types.OrderTypeLimit,
+		Price:       price,
+		Amount:      amount,
+		Status:      types.OrderStatusOpen,
+		Hash:        fmt.Sprintf("chain:%d:%s", chainID, event.OrderID.String()),
+		CreatedAt:   time.Unix(int64(event.Timestamp), 0),
+	}
+	
+	if event.IsBuy {
+		order.Side = types.OrderSideBuy
+	} else {
+		order.Side = types.OrderSideSell
+	}
+	// 合约 OrderType：0 限价，1 市价
+	if event.OrderType == 1 {
+		order.Type = types.OrderTypeMarket
+	}
+
+	// 补齐重放的事件已处理过，不重复入簿
+	if _, err := store.GetOrder(order.ID); err == nil {
+		return nil
+	}
+	if pair.Reversed {
+		err := fmt.Errorf("tokens are in reverse order for %s", pair.TradingPair)
+		engine.RejectOrder(order, types.StatusReasonPairReversed, err)
+		if saveErr := store.CreateOrder(order); saveErr != nil {
+			logger.WithError(saveErr).WithField("order_id", order.ID).Error("Failed to save reversed blockchain order")
+		}
+		return err
+	}
+	if deduper != nil {
+		if original, err := deduper.Admit(order, intake.SourceChain); err != nil {
+			engine.RejectOrder(order, types.StatusReasonDuplicateIntent, fmt.Errorf("%w: %s", err, original))
+			if saveErr := store.CreateOrder(order); saveErr != nil {
+				logger.WithError(saveErr).WithField("order_id", order.ID).Error("Failed to save duplicate blockchain order")
+			}
+			return err
+		}
+	}
+	if err := store.CreateOrder(order); err != nil {
+		if deduper != nil {
+			deduper.Release(order, intake.SourceChain)
+		}
+		return fmt.Errorf("failed to save order: %w", err)
+	}
+
+	// 添加到撮合引擎
+	fills, err := engine.AddOrder(order)
+	if updateErr := store.UpdateOrder(order); updateErr != nil {
+		logger.WithError(updateErr).WithField("order_id", order.ID).Error("Failed to update blockchain order")
+	}
+	if err != nil {
+		if deduper != nil {
+			deduper.Release(order, intake.SourceChain)
+		}
+		return fmt.Errorf("rejected by engine: %w", err)
+	}
+	for _, fill := range fills {
+		if err := store.CreateFill(fill); err != nil {
+			logger.WithError(err).WithField("fill_id", fill.ID).Error("Failed to save blockchain order fill")
 		}
 	}
+	
+	logger.WithFields(logrus.Fields{
+		"order_id": event.OrderID.String(),
+		"trader":   event.Trader.Hex(),
+		"pair":     order.TradingPair,
+		"side":     order.Side,
+		"fills":    len(fills),
+	}).Info("Processed blockchain order")
+	
+	// 处理成交记录，更新区块链状态
+	for _, fill := range fills {
+		go func(f *types.Fill) {
+			// 执行区块链交易
+			buyer := common.HexToAddress(f.TakerOrderID.String()) // 简化处理
+			seller := common.HexToAddress(f.MakerOrderID.String())
+			tokenA := common.HexToAddress(order.BaseToken)
+			tokenB := common.HexToAddress(order.QuoteToken)
+
+			amountUnits, err := tokenRegistry.ToBaseUnits(chainID, order.BaseToken, f.Amount)
+			if err != nil {
+				logger.WithError(err).Error("Failed to convert fill amount")
+				return
+			}
+			priceUnits, err := tokenRegistry.ToBaseUnits(chainID, order.QuoteToken, f.Price)
+			if err != nil {
+				logger.WithError(err).Error("Failed to convert fill price")
+				return
+			}
+			
+			tx, err := client.ExecuteTrade(
+				buyer, seller, tokenA, tokenB,
+				amountUnits, priceUnits, false,
+			)
+			if err != nil {
+				logger.WithError(err).Error("Failed to execute blockchain trade")
+				return
+			}
+			
+			logger.WithField("tx_hash", tx.Hash().Hex()).Info("Blockchain trade executed")
+		}(fill)
+	}
+	return nil
 }
 
 // handleMatchingEvents 处理撮合引擎事件
-func handleMatchingEvents(engine *matching.MatchingEngine, wsHub *websocket.Hub, blockchainClient *blockchain.Client, logger *logrus.Logger) {
-	for event := range engine.GetEventChannel() {
+func handleMatchingEvents(sub *matching.Subscription, engine *matching.MatchingEngine, wsHub *websocket.Hub, logger *logrus.Logger) {
+	for event := range sub.Events() {
 		switch event.Type {
-		case "order_added":
+		case matching.EventOrderAdded:
 			if event.Order != nil {
 				wsHub.PublishOrderUpdate(&types.OrderUpdate{
 					Order:     event.Order,
@@ -299,21 +2021,75 @@ func handleMatchingEvents(engine *matching.MatchingEngine, wsHub *websocket.Hub,
 			// 发布交易更新
 			for _, fill := range event.Fills {
 				trade := &types.Trade{
-					ID:          fill.ID,
-					TradingPair: fill.TradingPair,
-					Price:       fill.Price,
-					Amount:      fill.Amount,
-					Side:        fill.TakerSide,
-					Timestamp:   fill.CreatedAt,
+					ID:              fill.ID,
+					TradingPair:     fill.TradingPair,
+					Price:           fill.Price,
+					Amount:          fill.Amount,
+					Side:            fill.TakerSide,
+					Timestamp:       fill.CreatedAt,
+					EngineTimestamp: fill.EngineTimestamp,
 				}
 				wsHub.PublishTradeUpdate(&types.TradeUpdate{Trade: trade})
 			}
+			publishUserFills(wsHub, event)
 
-		case "order_cancelled":
+		case matching.EventAuctionUncrossed:
+			for _, fill := range event.Fills {
+				wsHub.PublishTradeUpdate(&types.TradeUpdate{Trade: &types.Trade{
+					ID:              fill.ID,
+					TradingPair:     fill.TradingPair,
+					Price:           fill.Price,
+					Amount:          fill.Amount,
+					Side:            fill.TakerSide,
+					Timestamp:       fill.CreatedAt,
+					EngineTimestamp: fill.EngineTimestamp,
+				}})
+			}
+			publishUserFills(wsHub, event)
+			if event.Order != nil {
+				wsHub.PublishOrderUpdate(&types.OrderUpdate{
+					Order:     event.Order,
+					EventType: "filled",
+				})
+			}
+			orderBook := engine.GetOrderBook(event.TradingPair, 20)
+			wsHub.PublishOrderBookUpdate(&types.OrderBookUpdate{
+				TradingPair: orderBook.TradingPair,
+				Bids:        orderBook.Bids,
+				Asks:        orderBook.Asks,
+				Timestamp:   time.Now(),
+			})
+
+		case matching.EventOrderRejected:
 			if event.Order != nil {
 				wsHub.PublishOrderUpdate(&types.OrderUpdate{
 					Order:     event.Order,
-					EventType: "cancelled",
+					EventType: "rejected",
+				})
+				orderID := event.Order.ID
+				wsHub.PublishRiskAlert(&types.RiskAlert{
+					UserAddress: event.Order.UserAddress,
+					Type:        types.RiskAlertOrderRejected,
+					Code:        event.Order.StatusReason,
+					Reason:      event.Order.RejectReason,
+					OrderID:     &orderID,
+					TradingPair: event.TradingPair,
+					Timestamp:   event.Timestamp,
+				})
+			}
+
+		case matching.EventOrderCancelled, matching.EventOrderExpired, matching.EventOrderReduced:
+			if event.Order != nil {
+				eventType := "cancelled"
+				switch event.Type {
+				case matching.EventOrderExpired:
+					eventType = "expired"
+				case matching.EventOrderReduced:
+					eventType = "reduced"
+				}
+				wsHub.PublishOrderUpdate(&types.OrderUpdate{
+					Order:     event.Order,
+					EventType: eventType,
 				})
 
 				// 发布订单簿更新
@@ -334,11 +2110,191 @@ func handleMatchingEvents(engine *matching.MatchingEngine, wsHub *websocket.Hub,
 	}
 }
 
+// publishUserFills 向成交双方的 fills.<address> 频道推送成交
+func publishUserFills(wsHub *websocket.Hub, event *matching.MatchEvent) {
+	for _, fill := range event.Fills {
+		makerSide := types.OrderSideBuy
+		if fill.TakerSide == types.OrderSideBuy {
+			makerSide = types.OrderSideSell
+		}
+		wsHub.PublishFillUpdate(fill.TakerUserAddress, &types.FillUpdate{
+			Fill:    fill,
+			OrderID: fill.TakerOrderID,
+			Side:    fill.TakerSide,
+			Role:    "taker",
+		})
+		wsHub.PublishFillUpdate(fill.MakerUserAddress, &types.FillUpdate{
+			Fill:    fill,
+			OrderID: fill.MakerOrderID,
+			Side:    makerSide,
+			Role:    "maker",
+		})
+	}
+}
+
+// handleExecutionReports 向成交双方的 executions.<address> 频道推送订单成交回报
+func handleExecutionReports(sub *matching.Subscription, reporter *execution.Reporter, wsHub *websocket.Hub, logger *logrus.Logger) {
+	for event := range sub.Events() {
+		for _, fill := range event.Fills {
+			reports, err := reporter.FillReports(fill, event.Fills)
+			if err != nil {
+				logger.WithError(err).WithField("fill_id", fill.ID).Warn("Failed to build execution report")
+			}
+			for _, report := range reports {
+				wsHub.PublishExecutionReport(report)
+			}
+		}
+	}
+}
+
+// publishSettlementRejection 向成交双方的 fills.<address> 频道推送结算被拒绝的成交
+func publishSettlementRejection(wsHub *websocket.Hub, fill *types.Fill, err error) {
+	reason := ""
+	if err != nil {
+		reason = err.Error()
+	}
+	makerSide := types.OrderSideBuy
+	if fill.TakerSide == types.OrderSideBuy {
+		makerSide = types.OrderSideSell
+	}
+	wsHub.PublishFillUpdate(fill.TakerUserAddress, &types.FillUpdate{
+		Fill:            fill,
+		OrderID:         fill.TakerOrderID,
+		Side:            fill.TakerSide,
+		Role:            "taker",
+		SettlementError: reason,
+	})
+	wsHub.PublishFillUpdate(fill.MakerUserAddress, &types.FillUpdate{
+		Fill:            fill,
+		OrderID:         fill.MakerOrderID,
+		Side:            makerSide,
+		Role:            "maker",
+		SettlementError: reason,
+	})
+}
+
+// handleCircuitBreakerEvents 将成交价喂给熔断器
+func handleCircuitBreakerEvents(sub *matching.Subscription, breaker *circuitbreaker.CircuitBreaker) {
+	for event := range sub.Events() {
+		for _, fill := range event.Fills {
+			breaker.RecordFill(fill)
+		}
+	}
+}
+
+// handleSurveillanceEvents 将成交流交给异常检测器
+func handleSurveillanceEvents(sub *matching.Subscription, detector *surveillance.Detector) {
+	for event := range sub.Events() {
+		for _, fill := range event.Fills {
+			detector.Inspect(fill)
+		}
+	}
+}
+
+// handlePositionEvents 将成交计入买卖双方的持仓
+func handlePositionEvents(sub *matching.Subscription, tracker *positions.Tracker) {
+	for event := range sub.Events() {
+		if event.Order == nil {
+			continue
+		}
+		for _, fill := range event.Fills {
+			tracker.ApplyFill(event.Order, fill)
+		}
+	}
+}
+
+// runSignatureTTLSweeper 定期撤销签名超期的挂单，并从存储中清除其签名防止被重放
+func runSignatureTTLSweeper(engine *matching.MatchingEngine, store storage.Storage, interval time.Duration, logger *logrus.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		for _, expired := range engine.ExpireStaleSignatures() {
+			order, err := store.GetOrder(expired.ID)
+			if err != nil {
+				logger.WithError(err).WithField("order_id", expired.ID).Error("Failed to load order with expired signature")
+				continue
+			}
+			order.Status = expired.Status
+			order.StatusReason = expired.StatusReason
+			order.UpdatedAt = expired.UpdatedAt
+			order.Signature = ""
+			if err := store.UpdateOrder(order); err != nil {
+				logger.WithError(err).WithField("order_id", order.ID).Error("Failed to purge expired order signature")
+			}
+		}
+	}
+}
+
+// handleExpiredOrders 将过期订单状态写回存储
+func handleExpiredOrders(sub *matching.Subscription, store storage.Storage, logger *logrus.Logger) {
+	for event := range sub.Events() {
+		if event.Order == nil {
+			continue
+		}
+		order, err := store.GetOrder(event.Order.ID)
+		if err != nil {
+			logger.WithError(err).WithField("order_id", event.Order.ID).Error("Failed to load expired order")
+			continue
+		}
+		order.Status = event.Order.Status
+		order.UpdatedAt = event.Order.UpdatedAt
+		if err := store.UpdateOrder(order); err != nil {
+			logger.WithError(err).WithField("order_id", order.ID).Error("Failed to update expired order")
+		}
+	}
+}
+
+// handleAuctionFills 将集合竞价成交及 taker 订单状态写回存储
+func handleAuctionFills(sub *matching.Subscription, store storage.Storage, logger *logrus.Logger) {
+	for event := range sub.Events() {
+		for _, fill := range event.Fills {
+			if err := store.CreateFill(fill); err != nil {
+				logger.WithError(err).WithField("fill_id", fill.ID).Error("Failed to save auction fill")
+			}
+		}
+		if event.Order == nil {
+			continue
+		}
+		order, err := store.GetOrder(event.Order.ID)
+		if err != nil {
+			logger.WithError(err).WithField("order_id", event.Order.ID).Error("Failed to load auction order")
+			continue
+		}
+		order.FilledAmount = event.Order.FilledAmount
+		order.AvgFillPrice = event.Order.AvgFillPrice
+		order.QuoteFilled = event.Order.QuoteFilled
+		order.FeePaid = event.Order.FeePaid
+		order.Status = event.Order.Status
+		order.UpdatedAt = event.Order.UpdatedAt
+		if err := store.UpdateOrder(order); err != nil {
+			logger.WithError(err).WithField("order_id", order.ID).Error("Failed to update auction order")
+		}
+	}
+}
+
+// handleStatsEvents 将成交写入统计聚合器
+func handleStatsEvents(sub *matching.Subscription, aggregator *stats.Aggregator) {
+	for event := range sub.Events() {
+		for _, fill := range event.Fills {
+			aggregator.Record(fill)
+		}
+	}
+}
+
+// handleOrderFlowEvents 将撮合事件计入用户订单流统计
+func handleOrderFlowEvents(sub *matching.Subscription, tracker *orderflow.Tracker) {
+	for event := range sub.Events() {
+		tracker.Observe(event)
+	}
+}
+
 // MemoryStorage 内存存储实现
 type MemoryStorage struct {
 	orders    map[uuid.UUID]*types.Order
 	ordersByHash map[string]*types.Order
 	fills     map[uuid.UUID]*types.Fill
+	candles   map[string]*types.Candle // pair|interval|openTime -> candle
 	mu        sync.RWMutex
 }
 
@@ -347,6 +2303,7 @@ func NewMemoryStorage() *MemoryStorage {
 		orders:    make(map[uuid.UUID]*types.Order),
 		ordersByHash: make(map[string]*types.Order),
 		fills:     make(map[uuid.UUID]*types.Fill),
+		candles:   make(map[string]*types.Candle),
 	}
 }
 
@@ -406,6 +2363,7 @@ func (m *MemoryStorage) GetUserOrders(userAddress, tradingPair, status string, l
 			result = append(result, order)
 		}
 	}
+	history.SortOrders(result)
 	
 	// 简单分页
 	start := offset
@@ -444,6 +2402,23 @@ func (m *MemoryStorage) CreateFill(fill *types.Fill) error {
 	return nil
 }
 
+func (m *MemoryStorage) GetFill(fillID uuid.UUID) (*types.Fill, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	fill, exists := m.fills[fillID]
+	if !exists {
+		return nil, fmt.Errorf("fill not found")
+	}
+	return fill, nil
+}
+
+func (m *MemoryStorage) UpdateFill(fill *types.Fill) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.fills[fill.ID] = fill
+	return nil
+}
+
 func (m *MemoryStorage) GetOrderFills(orderID uuid.UUID) ([]*types.Fill, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
@@ -471,6 +2446,7 @@ func (m *MemoryStorage) GetUserFills(userAddress string, limit, offset int) ([]*
 			result = append(result, fill)
 		}
 	}
+	history.SortFills(result)
 	
 	// 简单分页
 	start := offset
@@ -496,6 +2472,7 @@ func (m *MemoryStorage) GetRecentFills(tradingPair string, limit int) ([]*types.
 			result = append(result, fill)
 		}
 	}
+	history.SortFills(result)
 	
 	// 限制数量
 	if limit > 0 && len(result) > limit {
@@ -505,6 +2482,83 @@ func (m *MemoryStorage) GetRecentFills(tradingPair string, limit int) ([]*types.
 	return result, nil
 }
 
+func (m *MemoryStorage) QueryOrders(query history.OrderQuery) ([]*types.Order, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var result []*types.Order
+	for _, order := range m.orders {
+		if order.UserAddress != query.UserAddress {
+			continue
+		}
+		if query.TradingPair != "" && order.TradingPair != query.TradingPair {
+			continue
+		}
+		if query.Status != "" && string(order.Status) != query.Status {
+			continue
+		}
+		if !history.InRange(order.CreatedAt, query.From, query.To) || !query.After.Allows(order.CreatedAt, order.ID) {
+			continue
+		}
+		result = append(result, order)
+	}
+	history.SortOrders(result)
+
+	if len(result) > query.Limit {
+		result = result[:query.Limit]
+	}
+	return result, nil
+}
+
+func (m *MemoryStorage) QueryFills(query history.FillQuery) ([]*types.Fill, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var result []*types.Fill
+	for _, fill := range m.fills {
+		if query.TradingPair != "" && fill.TradingPair != query.TradingPair {
+			continue
+		}
+		if !history.InRange(fill.CreatedAt, query.From, query.To) || !query.After.Allows(fill.CreatedAt, fill.ID) {
+			continue
+		}
+		if query.UserAddress != "" {
+			if !m.fillMatchesUser(fill, query) {
+				continue
+			}
+		} else if query.Side != "" && fill.TakerSide != query.Side {
+			continue
+		}
+		result = append(result, fill)
+	}
+	history.SortFills(result)
+
+	if len(result) > query.Limit {
+		result = result[:query.Limit]
+	}
+	return result, nil
+}
+
+// fillMatchesUser 成交是否属于用户（taker或maker），并满足角色和用户订单方向条件
+func (m *MemoryStorage) fillMatchesUser(fill *types.Fill, query history.FillQuery) bool {
+	for _, party := range []struct {
+		role    history.Role
+		orderID uuid.UUID
+	}{{history.RoleTaker, fill.TakerOrderID}, {history.RoleMaker, fill.MakerOrderID}} {
+		if query.Role != "" && query.Role != party.role {
+			continue
+		}
+		order, exists := m.orders[party.orderID]
+		if !exists || order.UserAddress != query.UserAddress {
+			continue
+		}
+		if query.Side == "" || order.Side == query.Side {
+			return true
+		}
+	}
+	return false
+}
+
 func (m *MemoryStorage) GetTradingPairStats(tradingPair string, period time.Duration) (*storage.TradingPairStats, error) {
 	return &storage.TradingPairStats{
 		TradingPair: tradingPair,
@@ -528,5 +2582,114 @@ func (m *MemoryStorage) GetUserStats(userAddress string, period time.Duration) (
 	}, nil
 }
 
+func (m *MemoryStorage) SaveCandles(candles []*types.Candle) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, candle := range candles {
+		key := fmt.Sprintf("%s|%s|%d", candle.TradingPair, candle.Interval, candle.OpenTime.Unix())
+		m.candles[key] = candle
+	}
+	return nil
+}
+
+func (m *MemoryStorage) GetCandles(tradingPair, interval string, start, end time.Time, limit int) ([]*types.Candle, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var result []*types.Candle
+	for _, candle := range m.candles {
+		if candle.TradingPair != tradingPair || candle.Interval != interval {
+			continue
+		}
+		if !start.IsZero() && candle.OpenTime.Before(start) {
+			continue
+		}
+		if !end.IsZero() && candle.OpenTime.After(end) {
+			continue
+		}
+		result = append(result, candle)
+	}
+
+	// 按时间升序，超出数量时保留最近的K线
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].OpenTime.Before(result[j].OpenTime)
+	})
+	if limit > 0 && len(result) > limit {
+		result = result[len(result)-limit:]
+	}
+	return result, nil
+}
+
+// ArchivableOrders 终态且最后更新早于 before 的订单，按更新时间升序
+func (m *MemoryStorage) ArchivableOrders(before time.Time, limit int) ([]*types.Order, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var result []*types.Order
+	for _, order := range m.orders {
+		if order.Status == types.OrderStatusPending || order.IsActive() || !order.UpdatedAt.Before(before) {
+			continue
+		}
+		result = append(result, order)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].UpdatedAt.Before(result[j].UpdatedAt)
+	})
+	if limit > 0 && len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+// DeleteOrders 删除已归档的订单
+func (m *MemoryStorage) DeleteOrders(ids []uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, id := range ids {
+		if order, exists := m.orders[id]; exists {
+			if m.ordersByHash[order.Hash] == order {
+				delete(m.ordersByHash, order.Hash)
+			}
+			delete(m.orders, id)
+		}
+	}
+	return nil
+}
+
+// ArchivableFills 早于 before 且结算已终结（或未进入结算）的成交，按时间升序
+func (m *MemoryStorage) ArchivableFills(before time.Time, limit int) ([]*types.Fill, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var result []*types.Fill
+	for _, fill := range m.fills {
+		switch fill.SettlementStatus {
+		case "", types.SettlementStatusConfirmed, types.SettlementStatusVoided, types.SettlementStatusRejected:
+		default:
+			continue
+		}
+		if fill.CreatedAt.Before(before) {
+			result = append(result, fill)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CreatedAt.Before(result[j].CreatedAt)
+	})
+	if limit > 0 && len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+// DeleteFills 删除已归档的成交
+func (m *MemoryStorage) DeleteFills(ids []uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, id := range ids {
+		delete(m.fills, id)
+	}
+	return nil
+}
+
 func (m *MemoryStorage) HealthCheck() error { return nil }
 func (m *MemoryStorage) Close() error       { return nil }
\ No newline at end of file
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/shopspring/decimal"
+	"github.com/spf13/viper"
+
+	"orderbook-engine/internal/audit"
+	"orderbook-engine/internal/chains"
+	"orderbook-engine/internal/obligations"
+	"orderbook-engine/internal/types"
+	"orderbook-engine/internal/websocket"
+)
+
+// obligationEntry 配置文件中的报价义务：mm_obligations.market_makers[]
+type obligationEntry struct {
+	MarketMaker  string  `mapstructure:"market_maker"`
+	TradingPair  string  `mapstructure:"trading_pair"`
+	MaxSpreadBps float64 `mapstructure:"max_spread_bps"`
+	MinPresence  float64 `mapstructure:"min_presence"`
+	MinSize      float64 `mapstructure:"min_size"`
+}
+
+// obligationConfig 由配置生成报价义务监控配置
+func obligationConfig() obligations.Config {
+	return obligations.Config{
+		SampleInterval: viper.GetDuration("mm_obligations.sample_interval"),
+		ReportPeriod:   viper.GetDuration("mm_obligations.report_period"),
+		PenaltyFeeBps:  viper.GetUint64("mm_obligations.penalty_fee_bps"),
+		RetainReports:  viper.GetInt("mm_obligations.retain_reports"),
+	}
+}
+
+// obligationList 由配置生成做市商报价义务列表
+func obligationList() ([]*obligations.Obligation, error) {
+	var entries []obligationEntry
+	if err := viper.UnmarshalKey("mm_obligations.market_makers", &entries); err != nil {
+		return nil, err
+	}
+	list := make([]*obligations.Obligation, 0, len(entries))
+	for _, entry := range entries {
+		obligation := &obligations.Obligation{
+			MarketMaker:  entry.MarketMaker,
+			TradingPair:  strings.ToUpper(entry.TradingPair),
+			MaxSpreadBps: decimal.NewFromFloat(entry.MaxSpreadBps),
+			MinPresence:  decimal.NewFromFloat(entry.MinPresence),
+			MinSize:      decimal.NewFromFloat(entry.MinSize),
+		}
+		if err := obligation.Validate(); err != nil {
+			return nil, fmt.Errorf("%s %s: %w", entry.MarketMaker, entry.TradingPair, err)
+		}
+		list = append(list, obligation)
+	}
+	return list, nil
+}
+
+// chainFeeSetter 在全部已连接链的结算合约上设置用户费率
+type chainFeeSetter struct {
+	registry *chains.Registry
+}
+
+// SetUserFeeRates 设置用户费率，任一链失败时返回错误
+func (s *chainFeeSetter) SetUserFeeRates(userAddress string, makerBps, takerBps uint64) error {
+	sent := 0
+	for _, chain := range s.registry.Chains() {
+		if chain.Client == nil {
+			continue
+		}
+		if _, err := chain.Client.SetUserFeeRates(common.HexToAddress(userAddress),
+			new(big.Int).SetUint64(makerBps), new(big.Int).SetUint64(takerBps)); err != nil {
+			return fmt.Errorf("chain %d: %w", chain.ChainID, err)
+		}
+		sent++
+	}
+	if sent == 0 {
+		return fmt.Errorf("no blockchain client available")
+	}
+	return nil
+}
+
+// notifyObligationReport 不合规的报价义务报告推送给做市商并写入审计日志
+func notifyObligationReport(wsHub *websocket.Hub, auditor *audit.Recorder, report *obligations.Report) {
+	if report.Compliant {
+		return
+	}
+	reason := fmt.Sprintf("quote presence %s below required %s", report.Presence.StringFixed(4), report.Required.String())
+	wsHub.PublishRiskAlert(&types.RiskAlert{
+		UserAddress: report.MarketMaker,
+		Type:        types.RiskAlertQuotingObligation,
+		Code:        "QUOTING_OBLIGATION",
+		Reason:      reason,
+		TradingPair: report.TradingPair,
+		Timestamp:   report.PeriodEnd,
+	})
+	if auditor != nil {
+		auditor.Record(&audit.Entry{
+			ActorType: audit.ActorSystem,
+			Actor:     "mm_obligations",
+			Action:    audit.ActionMMObligationBreach,
+			Resource:  report.MarketMaker,
+			Details: map[string]interface{}{
+				"trading_pair": report.TradingPair,
+				"period_start": report.PeriodStart,
+				"period_end":   report.PeriodEnd,
+				"presence":     report.Presence.String(),
+				"required":     report.Required.String(),
+				"penalized":    report.Penalized,
+			},
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/viper"
+
+	"orderbook-engine/internal/orderflow"
+)
+
+// orderFlowConfig 由配置生成订单流统计配置：orderflow.windows 为统计窗口列表，orderflow.bucket_size 与 orderflow.replace_window
+func orderFlowConfig() (orderflow.Config, error) {
+	config := orderflow.Config{
+		BucketSize:    viper.GetDuration("orderflow.bucket_size"),
+		ReplaceWindow: viper.GetDuration("orderflow.replace_window"),
+	}
+	for _, value := range viper.GetStringSlice("orderflow.windows") {
+		window, err := time.ParseDuration(value)
+		if err != nil {
+			return orderflow.Config{}, fmt.Errorf("orderflow.windows: invalid duration %q", value)
+		}
+		config.Windows = append(config.Windows, window)
+	}
+	if err := config.Validate(); err != nil {
+		return orderflow.Config{}, fmt.Errorf("orderflow: %w", err)
+	}
+	return config, nil
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+
+	"orderbook-engine/internal/chains"
+	"orderbook-engine/internal/preflight"
+)
+
+// preflightConfig 由配置生成链上资金预检配置：preflight.mode 为默认模式，preflight.pairs.<pair> 按交易对覆盖
+func preflightConfig() (preflight.Config, error) {
+	mode, err := preflight.ParseMode(viper.GetString("preflight.mode"))
+	if err != nil {
+		return preflight.Config{}, fmt.Errorf("preflight.mode: %w", err)
+	}
+	pairs := make(map[string]preflight.Mode)
+	for pair := range viper.GetStringMap("preflight.pairs") {
+		pairMode, err := preflight.ParseMode(viper.GetString("preflight.pairs." + pair))
+		if err != nil {
+			return preflight.Config{}, fmt.Errorf("preflight.pairs.%s: %w", pair, err)
+		}
+		pairs[strings.ToUpper(pair)] = pairMode
+	}
+	return preflight.Config{
+		Mode:     mode,
+		Pairs:    pairs,
+		Timeout:  viper.GetDuration("preflight.timeout"),
+		FailOpen: viper.GetBool("preflight.fail_open"),
+	}, nil
+}
+
+// initPreflight 初始化下单前的链上资金预检，所有交易对均为 off 时返回 nil
+func initPreflight(registry *chains.Registry, logger *logrus.Logger) *preflight.Checker {
+	config, err := preflightConfig()
+	if err != nil {
+		logger.WithError(err).Fatal("Invalid preflight config")
+	}
+
+	checker := preflight.NewChecker(config, logger)
+	if !checker.Enabled() {
+		return nil
+	}
+	for _, chain := range registry.Chains() {
+		if chain.Client == nil {
+			logger.WithField("chain_id", chain.ChainID).Warn("Chain has no RPC client, on-chain funds check unavailable for its pairs")
+			continue
+		}
+		checker.AddChain(chain.ChainID, chain.Client)
+	}
+	logger.WithFields(logrus.Fields{
+		"mode":      config.Mode,
+		"pairs":     config.Pairs,
+		"fail_open": config.FailOpen,
+	}).Info("On-chain funds preflight check enabled")
+	return checker
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"github.com/shopspring/decimal"
+	"github.com/spf13/viper"
+
+	"orderbook-engine/internal/referral"
+	"orderbook-engine/internal/wallet"
+)
+
+// depositSourceReferral 返佣提取入账的充值来源
+const depositSourceReferral = "referral_rebate"
+
+// referralConfig 由配置生成推荐返佣配置
+func referralConfig() referral.Config {
+	return referral.Config{
+		TakerFeeBps: decimal.NewFromFloat(viper.GetFloat64("referral.taker_fee_bps")),
+		RebateShare: decimal.NewFromFloat(viper.GetFloat64("referral.rebate_share")),
+	}
+}
+
+// rebatePayer 返佣以充值记录入账到推荐人的托管余额，之后与其他余额一样经结算合约提现
+// 提取ID作为充值ID，重复发放只入账一次
+type rebatePayer struct {
+	balances *wallet.BalanceManager
+}
+
+// PayRebate 入账返佣
+func (p *rebatePayer) PayRebate(claimID, referrer, token string, amount decimal.Decimal) error {
+	_, _, err := p.balances.CreditDeposit(claimID, referrer, token, amount, depositSourceReferral)
+	return err
+}
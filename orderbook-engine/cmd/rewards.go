@@ -0,0 +1,21 @@
+package main
+
+import (
+	"github.com/shopspring/decimal"
+	"github.com/spf13/viper"
+
+	"orderbook-engine/internal/rewards"
+)
+
+// rewardsConfig 由配置生成流动性挖矿积分配置
+func rewardsConfig() rewards.Config {
+	return rewards.Config{
+		EpochLength:    viper.GetDuration("rewards.epoch_length"),
+		SampleInterval: viper.GetDuration("rewards.sample_interval"),
+		MaxSpread:      decimal.NewFromFloat(viper.GetFloat64("rewards.max_spread")),
+		VolumeWeight:   decimal.NewFromFloat(viper.GetFloat64("rewards.volume_weight")),
+		SpreadWeight:   decimal.NewFromFloat(viper.GetFloat64("rewards.spread_weight")),
+		UptimeWeight:   decimal.NewFromFloat(viper.GetFloat64("rewards.uptime_weight")),
+		RetainEpochs:   viper.GetInt("rewards.retain_epochs"),
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/viper"
+
+	"orderbook-engine/internal/txsigner"
+)
+
+// settlementSigner 按 settlement.signer.backend 创建链的结算签名者，密钥轮换时以当前配置重新创建
+// local 使用链配置中的 private_key；kms、vault 的私钥不出现在配置文件中，凭证为空时读取 AWS_* / VAULT_* 环境变量
+func settlementSigner(chainID uint64) (txsigner.Signer, error) {
+	switch backend := viper.GetString("settlement.signer.backend"); backend {
+	case txsigner.BackendLocal:
+		configs, err := chainConfigs()
+		if err != nil {
+			return nil, err
+		}
+		for _, chain := range configs {
+			if chain.ChainID == chainID {
+				return txsigner.NewLocalSigner(chain.PrivateKey)
+			}
+		}
+		return nil, fmt.Errorf("chain %d not configured", chainID)
+	case txsigner.BackendKMS:
+		return txsigner.NewKMSSigner(txsigner.KMSConfig{
+			KeyID:        viper.GetString("settlement.signer.kms.key_id"),
+			Region:       configOrEnv("settlement.signer.kms.region", "AWS_REGION"),
+			Endpoint:     viper.GetString("settlement.signer.kms.endpoint"),
+			AccessKey:    configOrEnv("settlement.signer.kms.access_key", "AWS_ACCESS_KEY_ID"),
+			SecretKey:    configOrEnv("settlement.signer.kms.secret_key", "AWS_SECRET_ACCESS_KEY"),
+			SessionToken: configOrEnv("settlement.signer.kms.session_token", "AWS_SESSION_TOKEN"),
+			Timeout:      viper.GetDuration("settlement.signer.timeout"),
+		})
+	case txsigner.BackendVault:
+		return txsigner.NewVaultSigner(txsigner.VaultConfig{
+			Address: configOrEnv("settlement.signer.vault.address", "VAULT_ADDR"),
+			Token:   configOrEnv("settlement.signer.vault.token", "VAULT_TOKEN"),
+			Mount:   viper.GetString("settlement.signer.vault.mount"),
+			Path:    viper.GetString("settlement.signer.vault.path"),
+			Field:   viper.GetString("settlement.signer.vault.field"),
+			Timeout: viper.GetDuration("settlement.signer.timeout"),
+		})
+	default:
+		return nil, fmt.Errorf("settlement.signer.backend: %w %q", txsigner.ErrUnknownBackend, backend)
+	}
+}
+
+// configOrEnv 读取配置项，为空时读取环境变量
+func configOrEnv(key, env string) string {
+	if value := viper.GetString(key); value != "" {
+		return value
+	}
+	return os.Getenv(env)
+}
@@ -0,0 +1,19 @@
+package main
+
+import (
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+// applySimulationMode 模拟盘模式：完整运行API、撮合、推送和余额，但不连接区块链、不提交结算
+// 余额只存在于内部虚拟账本，通过 POST /api/v1/simulation/balances 注入测试余额
+// 在配置校验前调用，覆盖与模拟盘冲突的配置项
+func applySimulationMode() {
+	if !viper.GetBool("simulation.enabled") {
+		return
+	}
+	viper.Set("settlement.enabled", false)
+	viper.Set("trading.auto_matching", false)
+	viper.Set("wallet.enforce_balances", true)
+	logrus.Warn("🧪 Simulation mode enabled - no blockchain client, no settlement, virtual balances only")
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/viper"
+
+	"orderbook-engine/internal/storage"
+)
+
+// storageConcerns 可单独配置后端的存储关注点
+var storageConcerns = []string{"orders", "fills", "stats"}
+
+// storageDriver 关注点使用的存储驱动，未单独配置时使用 storage.driver
+func storageDriver(concern string) string {
+	if driver := viper.GetString("storage." + concern + ".driver"); driver != "" {
+		return driver
+	}
+	return viper.GetString("storage.driver")
+}
+
+// storageDrivers 可用的存储驱动
+var storageDrivers = map[string]func() (storage.Storage, error){
+	"memory": func() (storage.Storage, error) { return NewMemoryStorage(), nil },
+}
+
+// newStorageBackend 按驱动创建存储后端
+func newStorageBackend(driver string) (storage.Storage, error) {
+	create, exists := storageDrivers[driver]
+	if !exists {
+		return nil, fmt.Errorf("unsupported storage driver %q", driver)
+	}
+	return create()
+}
+
+// fillBackend 成交所在的存储后端，用于检查成交相关的可选能力（如结算状态回写）
+func fillBackend(store storage.Storage) interface{} {
+	if composite, ok := store.(*storage.Composite); ok {
+		return composite.FillStore
+	}
+	return store
+}
+
+// orderBackend 订单所在的存储后端，用于检查订单相关的可选能力（如归档清理）
+func orderBackend(store storage.Storage) interface{} {
+	if composite, ok := store.(*storage.Composite); ok {
+		return composite.OrderStore
+	}
+	return store
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"orderbook-engine/internal/chains"
+	"orderbook-engine/internal/integrity"
+	"orderbook-engine/internal/riskcontrol"
+	"orderbook-engine/internal/types"
+	"orderbook-engine/internal/websocket"
+)
+
+// 运维事件（管理员主题 system.admin）：结算结果、风控拒单、价格熔断、链上订阅连接状态与一致性检查，
+// 供运维看板实时订阅，无需轮询管理接口
+
+// publishSettlementEvent 推送结算批次结果，仅推送确认、失败与提交前被拒绝
+func publishSettlementEvent(wsHub *websocket.Hub, chainID uint64, fillIDs []uuid.UUID, status types.SettlementStatus, txHash string, err error) {
+	event := &types.SystemEvent{
+		Type:      types.SystemEventSettlement,
+		Severity:  types.SystemEventWarning,
+		ChainID:   chainID,
+		Timestamp: time.Now(),
+	}
+	switch status {
+	case types.SettlementStatusConfirmed:
+		event.Severity = types.SystemEventInfo
+		event.Message = fmt.Sprintf("Settlement of %d fills confirmed on chain %d", len(fillIDs), chainID)
+	case types.SettlementStatusFailed:
+		event.Message = fmt.Sprintf("Settlement of %d fills failed on chain %d", len(fillIDs), chainID)
+	case types.SettlementStatusRejected:
+		event.Message = fmt.Sprintf("Settlement of %d fills rejected by simulation on chain %d", len(fillIDs), chainID)
+	default:
+		return
+	}
+
+	details := map[string]interface{}{
+		"status":   status,
+		"fill_ids": fillIDs,
+	}
+	if txHash != "" {
+		details["tx_hash"] = txHash
+	}
+	if err != nil {
+		details["error"] = err.Error()
+	}
+	event.Details = details
+	wsHub.PublishSystemEvent(event)
+}
+
+// publishRiskViolation 推送风控拒单
+func publishRiskViolation(wsHub *websocket.Hub, order *types.Order, result *riskcontrol.RiskCheckResult) {
+	wsHub.PublishSystemEvent(&types.SystemEvent{
+		Type:        types.SystemEventRiskViolation,
+		Severity:    types.SystemEventWarning,
+		TradingPair: order.TradingPair,
+		Message:     fmt.Sprintf("Order from %s rejected by risk control: %s", order.UserAddress, result.Code),
+		Details: map[string]interface{}{
+			"user_address": order.UserAddress,
+			"order_id":     order.ID,
+			"code":         result.Code,
+			"reason":       result.Reason,
+		},
+		Timestamp: time.Now(),
+	})
+}
+
+// publishBreakerEvent 推送价格熔断暂停与恢复
+func publishBreakerEvent(wsHub *websocket.Hub, update *types.PairStatusUpdate) {
+	event := &types.SystemEvent{
+		Type:        types.SystemEventCircuitBreaker,
+		Severity:    types.SystemEventCritical,
+		TradingPair: update.TradingPair,
+		Message:     fmt.Sprintf("Circuit breaker halted trading on %s", update.TradingPair),
+		Details:     update,
+		Timestamp:   update.Timestamp,
+	}
+	if update.Status != types.PairStatusHalted {
+		event.Severity = types.SystemEventInfo
+		event.Message = fmt.Sprintf("Circuit breaker resumed trading on %s", update.TradingPair)
+	}
+	wsHub.PublishSystemEvent(event)
+}
+
+// watchChainConnectivity 推送各链事件订阅的断开与恢复，需在开始订阅链上事件前调用
+func watchChainConnectivity(wsHub *websocket.Hub, registry *chains.Registry) {
+	for _, chain := range registry.Chains() {
+		if chain.Client == nil {
+			continue
+		}
+		chainID := chain.ChainID
+		chain.Client.SetConnectivityHandler(func(connected bool, err error) {
+			event := &types.SystemEvent{
+				Type:      types.SystemEventChainConnectivity,
+				Severity:  types.SystemEventInfo,
+				ChainID:   chainID,
+				Message:   fmt.Sprintf("Order event subscription on chain %d is live", chainID),
+				Timestamp: time.Now(),
+			}
+			if !connected {
+				event.Severity = types.SystemEventCritical
+				event.Message = fmt.Sprintf("Order event subscription on chain %d lost, reconnecting", chainID)
+				if err != nil {
+					event.Details = map[string]interface{}{"error": err.Error()}
+				}
+			}
+			wsHub.PublishSystemEvent(event)
+		})
+	}
+}
+
+// publishIntegrityReport 推送发现不一致的一致性检查报告
+func publishIntegrityReport(wsHub *websocket.Hub, report *integrity.Report) {
+	wsHub.PublishSystemEvent(&types.SystemEvent{
+		Type:      types.SystemEventIntegrity,
+		Severity:  types.SystemEventCritical,
+		Message:   fmt.Sprintf("Integrity check found %d discrepancies (%d repaired)", len(report.Discrepancies), report.Repaired),
+		Details:   report,
+		Timestamp: report.CheckedAt,
+	})
+}
@@ -7,11 +7,14 @@ go 1.21 // 需要 Go 1.21 或更高版本
 // 直接依赖包
 require (
 	github.com/ethereum/go-ethereum v1.13.10 // 以太坊 Go 客户端库
+	github.com/fsnotify/fsnotify v1.7.0 // 文件监听（配置热更新）
 	github.com/gin-gonic/gin v1.9.1 // HTTP Web 框架
 	github.com/go-redis/redis/v8 v8.11.5 // Redis 客户端
 	github.com/google/uuid v1.5.0 // UUID 生成器
 	github.com/gorilla/websocket v1.5.1 // WebSocket 实现
 	github.com/lib/pq v1.10.9 // PostgreSQL 驱动
+	github.com/nats-io/nats.go v1.31.0 // NATS 客户端（事件总线）
+	github.com/segmentio/kafka-go v0.4.47 // Kafka 客户端（事件总线）
 	github.com/shopspring/decimal v1.3.1 // 高精度十进制计算
 	github.com/sirupsen/logrus v1.9.3 // 结构化日志库
 	github.com/spf13/viper v1.18.2 // 配置管理库
@@ -35,7 +38,6 @@ require (
 	github.com/decred/dcrd/dcrec/secp256k1/v4 v4.0.1 // indirect
 	github.com/dgryski/go-rendezvous v0.0.0-20200823014737-9f7001d12a5f // indirect
 	github.com/ethereum/c-kzg-4844 v0.4.0 // indirect
-	github.com/fsnotify/fsnotify v1.7.0 // indirect
 	github.com/gabriel-vasile/mimetype v1.4.2 // indirect
 	github.com/gin-contrib/sse v0.1.0 // indirect
 	github.com/go-ole/go-ole v1.2.5 // indirect
@@ -46,6 +48,7 @@ require (
 	github.com/hashicorp/hcl v1.0.0 // indirect
 	github.com/holiman/uint256 v1.2.4 // indirect
 	github.com/json-iterator/go v1.1.12 // indirect
+	github.com/klauspost/compress v1.17.0 // indirect
 	github.com/klauspost/cpuid/v2 v2.2.4 // indirect
 	github.com/leodido/go-urn v1.2.4 // indirect
 	github.com/magiconair/properties v1.8.7 // indirect
@@ -54,7 +57,10 @@ require (
 	github.com/mmcloughlin/addchain v0.4.0 // indirect
 	github.com/modern-go/concurrent v0.0.0-20180306012644-bacd9c7ef1dd // indirect
 	github.com/modern-go/reflect2 v1.0.2 // indirect
+	github.com/nats-io/nkeys v0.4.6 // indirect
+	github.com/nats-io/nuid v1.0.1 // indirect
 	github.com/pelletier/go-toml/v2 v2.1.0 // indirect
+	github.com/pierrec/lz4/v4 v4.1.15 // indirect
 	github.com/pmezard/go-difflib v1.0.1-0.20181226105442-5d4384ee4fb2 // indirect
 	github.com/sagikazarmark/locafero v0.4.0 // indirect
 	github.com/sagikazarmark/slog-shim v0.1.0 // indirect
@@ -0,0 +1,173 @@
+// Package analytics 成交分析归档
+// 将撮合产生的每笔成交批量写入 TimescaleDB 或 ClickHouse（按交易对与时间分区），
+// 按交易对/日成交量、交易者排行等聚合查询直接走分析库，不给交易存储增加大范围扫描
+package analytics
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+
+	"orderbook-engine/internal/matching"
+	"orderbook-engine/internal/types"
+)
+
+// Sink 分析存储
+type Sink interface {
+	// InsertFills 批量写入成交，重复写入同一成交不产生重复统计
+	InsertFills(ctx context.Context, fills []*types.Fill) error
+	// VolumeByPairDay 按交易对与自然日（UTC）汇总成交量，按日期、交易对排序
+	VolumeByPairDay(ctx context.Context, query Query) ([]PairDayVolume, error)
+	// TopTraders 按成交额排序的交易者，maker 与 taker 双方各计一次
+	TopTraders(ctx context.Context, query Query, limit int) ([]TraderVolume, error)
+	Close() error
+}
+
+// Query 聚合查询条件
+type Query struct {
+	TradingPair string    // 为空表示全部交易对
+	From        time.Time // 含，零值表示不限
+	To          time.Time // 不含，零值表示不限
+}
+
+// PairDayVolume 交易对单日成交量
+type PairDayVolume struct {
+	TradingPair string          `json:"trading_pair"`
+	Day         time.Time       `json:"day"`
+	Trades      int64           `json:"trades"`
+	BaseVolume  decimal.Decimal `json:"base_volume"`  // 成交数量之和
+	QuoteVolume decimal.Decimal `json:"quote_volume"` // 成交额（价格×数量）之和
+}
+
+// TraderVolume 交易者成交汇总
+type TraderVolume struct {
+	UserAddress string          `json:"user_address"`
+	Trades      int64           `json:"trades"`
+	QuoteVolume decimal.Decimal `json:"quote_volume"`
+}
+
+// notional 成交额，保留 18 位小数与分析库的列精度一致
+func notional(fill *types.Fill) decimal.Decimal {
+	return fill.Price.Mul(fill.Amount).Round(18)
+}
+
+// Config 归档配置
+type Config struct {
+	BatchSize     int           // 单批写入的成交数上限，达到即写入
+	FlushInterval time.Duration // 未满批时的最长等待
+	InsertTimeout time.Duration // 单次写入超时
+	RetryBackoff  time.Duration // 写入失败后的初始重试间隔
+	MaxBackoff    time.Duration // 最大重试间隔
+}
+
+// Stats 归档统计
+type Stats struct {
+	Archived    uint64    `json:"archived"`      // 已写入的成交数
+	Batches     uint64    `json:"batches"`       // 已写入的批次数
+	Failures    uint64    `json:"failures"`      // 失败的写入尝试数
+	LastFlushAt time.Time `json:"last_flush_at"` // 最近一次成功写入时间
+	LastError   string    `json:"last_error,omitempty"`
+}
+
+// Archiver 消费撮合事件，将成交批量写入分析存储
+// 写入失败时按退避重试同一批成交，不丢弃；重试期间成交在订阅中积压
+type Archiver struct {
+	sink   Sink
+	config Config
+	logger *logrus.Logger
+
+	mu    sync.Mutex
+	stats Stats
+}
+
+// NewArchiver 创建成交归档器
+func NewArchiver(sink Sink, config Config, logger *logrus.Logger) *Archiver {
+	if config.BatchSize <= 0 {
+		config.BatchSize = 1000
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = time.Second
+	}
+	if config.InsertTimeout <= 0 {
+		config.InsertTimeout = 10 * time.Second
+	}
+	if config.RetryBackoff <= 0 {
+		config.RetryBackoff = 500 * time.Millisecond
+	}
+	if config.MaxBackoff < config.RetryBackoff {
+		config.MaxBackoff = 30 * time.Second
+	}
+	return &Archiver{sink: sink, config: config, logger: logger}
+}
+
+// Run 消费撮合事件直到订阅关闭，关闭前写入剩余成交
+func (a *Archiver) Run(sub *matching.Subscription) {
+	ticker := time.NewTicker(a.config.FlushInterval)
+	defer ticker.Stop()
+
+	var batch []*types.Fill
+	for {
+		select {
+		case event, ok := <-sub.Events():
+			if !ok {
+				a.flush(batch)
+				return
+			}
+			batch = append(batch, event.Fills...)
+			if len(batch) >= a.config.BatchSize {
+				a.flush(batch)
+				batch = nil
+			}
+		case <-ticker.C:
+			a.flush(batch)
+			batch = nil
+		}
+	}
+}
+
+// flush 写入一批成交，失败时退避重试直到成功
+func (a *Archiver) flush(batch []*types.Fill) {
+	if len(batch) == 0 {
+		return
+	}
+
+	backoff := a.config.RetryBackoff
+	for attempt := 1; ; attempt++ {
+		ctx, cancel := context.WithTimeout(context.Background(), a.config.InsertTimeout)
+		err := a.sink.InsertFills(ctx, batch)
+		cancel()
+
+		a.mu.Lock()
+		if err == nil {
+			a.stats.Archived += uint64(len(batch))
+			a.stats.Batches++
+			a.stats.LastFlushAt = time.Now()
+			a.stats.LastError = ""
+			a.mu.Unlock()
+			return
+		}
+		a.stats.Failures++
+		a.stats.LastError = err.Error()
+		a.mu.Unlock()
+
+		a.logger.WithError(err).WithFields(logrus.Fields{
+			"fills":   len(batch),
+			"attempt": attempt,
+			"backoff": backoff.String(),
+		}).Warn("Analytics fill archive insert failed, retrying")
+		time.Sleep(backoff)
+		if backoff *= 2; backoff > a.config.MaxBackoff {
+			backoff = a.config.MaxBackoff
+		}
+	}
+}
+
+// Stats 归档统计
+func (a *Archiver) Stats() Stats {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.stats
+}
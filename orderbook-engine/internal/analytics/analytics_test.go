@@ -0,0 +1,152 @@
+package analytics
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"orderbook-engine/internal/matching"
+	"orderbook-engine/internal/types"
+)
+
+func addOrder(t *testing.T, engine *matching.MatchingEngine, user string, side types.OrderSide, price, amount int64) {
+	_, err := engine.AddOrder(&types.Order{
+		ID:          uuid.New(),
+		UserAddress: user,
+		TradingPair: "WETH-USDC",
+		Side:        side,
+		Type:        types.OrderTypeLimit,
+		Price:       decimal.NewFromInt(price),
+		Amount:      decimal.NewFromInt(amount),
+		CreatedAt:   time.Now(),
+	})
+	require.NoError(t, err)
+}
+
+func testFill(pair string, price, amount int64, at time.Time) *types.Fill {
+	return &types.Fill{
+		ID:               uuid.New(),
+		TakerUserAddress: "0xTaker",
+		MakerUserAddress: "0xmaker",
+		TradingPair:      pair,
+		Price:            decimal.NewFromInt(price),
+		Amount:           decimal.NewFromInt(amount),
+		TakerSide:        types.OrderSideBuy,
+		CreatedAt:        at,
+	}
+}
+
+func TestArchiverWritesEngineFills(t *testing.T) {
+	engine := matching.NewMatchingEngine(logrus.New())
+	sink := NewMemorySink()
+	archiver := NewArchiver(sink, Config{BatchSize: 2, FlushInterval: time.Hour}, logrus.New())
+	sub := engine.Subscribe(matching.SubscriptionOptions{Name: "analytics"})
+	done := make(chan struct{})
+	go func() {
+		archiver.Run(sub)
+		close(done)
+	}()
+
+	addOrder(t, engine, "0xmaker", types.OrderSideSell, 2000, 3)
+	addOrder(t, engine, "0xtaker", types.OrderSideBuy, 2000, 1)
+	addOrder(t, engine, "0xtaker", types.OrderSideBuy, 2000, 2)
+	require.Eventually(t, func() bool { return archiver.Stats().Archived == 2 }, time.Second, 5*time.Millisecond, "满批即写入")
+
+	// 订阅关闭时写入剩余成交
+	addOrder(t, engine, "0xmaker", types.OrderSideSell, 2000, 1)
+	addOrder(t, engine, "0xother", types.OrderSideBuy, 2000, 1)
+	engine.Unsubscribe(sub)
+	<-done
+	assert.Equal(t, uint64(3), archiver.Stats().Archived)
+
+	traders, err := sink.TopTraders(context.Background(), Query{}, 2)
+	require.NoError(t, err)
+	require.Len(t, traders, 2)
+	assert.Equal(t, "0xmaker", traders[0].UserAddress)
+	assert.Equal(t, int64(3), traders[0].Trades)
+	assert.True(t, traders[0].QuoteVolume.Equal(decimal.NewFromInt(8000)))
+	assert.Equal(t, "0xtaker", traders[1].UserAddress)
+}
+
+func TestMemorySinkVolumeByPairDay(t *testing.T) {
+	sink := NewMemorySink()
+	day := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	fill := testFill("WETH-USDC", 2000, 1, day.Add(time.Hour))
+	require.NoError(t, sink.InsertFills(context.Background(), []*types.Fill{
+		fill,
+		testFill("WETH-USDC", 2100, 2, day.Add(23*time.Hour)),
+		testFill("WETH-USDC", 2200, 1, day.Add(25*time.Hour)),
+		testFill("WBTC-USDC", 60000, 1, day.Add(2*time.Hour)),
+	}))
+	// 重试写入同一成交不重复统计
+	require.NoError(t, sink.InsertFills(context.Background(), []*types.Fill{fill}))
+
+	volumes, err := sink.VolumeByPairDay(context.Background(), Query{TradingPair: "WETH-USDC"})
+	require.NoError(t, err)
+	require.Len(t, volumes, 2)
+	assert.Equal(t, day, volumes[0].Day)
+	assert.Equal(t, int64(2), volumes[0].Trades)
+	assert.True(t, volumes[0].BaseVolume.Equal(decimal.NewFromInt(3)))
+	assert.True(t, volumes[0].QuoteVolume.Equal(decimal.NewFromInt(6200)))
+
+	volumes, err = sink.VolumeByPairDay(context.Background(), Query{From: day, To: day.Add(24 * time.Hour)})
+	require.NoError(t, err)
+	require.Len(t, volumes, 2)
+	assert.Equal(t, "WBTC-USDC", volumes[0].TradingPair)
+}
+
+func TestClickHouseSink(t *testing.T) {
+	var inserted string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		query := r.URL.Query()
+		switch {
+		case strings.HasPrefix(query.Get("query"), "INSERT INTO analytics_fills"):
+			inserted = string(body)
+		case strings.Contains(string(body), "CREATE TABLE"):
+		case strings.Contains(string(body), "toStartOfDay"):
+			assert.Equal(t, "WETH-USDC", query.Get("param_pair"))
+			io.WriteString(w, `{"trading_pair":"WETH-USDC","day":"2024-05-01 00:00:00","trades":2,"base_volume":"3","quote_volume":"6200.5"}`+"\n")
+		case strings.Contains(string(body), "ARRAY JOIN"):
+			assert.Equal(t, "10", query.Get("param_limit"))
+			io.WriteString(w, `{"user_address":"0xmaker","trades":2,"quote_volume":"6200.5"}`+"\n")
+		default:
+			http.Error(w, "unexpected statement", http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	sink, err := NewClickHouseSink(ClickHouseConfig{URL: server.URL, Database: "analytics"})
+	require.NoError(t, err)
+	defer sink.Close()
+
+	at := time.Date(2024, 5, 1, 1, 2, 3, 456789000, time.UTC)
+	require.NoError(t, sink.InsertFills(context.Background(), []*types.Fill{testFill("WETH-USDC", 2000, 1, at)}))
+	assert.Contains(t, inserted, `"executed_at":"2024-05-01 01:02:03.456789"`)
+	assert.Contains(t, inserted, `"notional":"2000"`)
+	assert.Contains(t, inserted, `"taker_address":"0xtaker"`)
+
+	volumes, err := sink.VolumeByPairDay(context.Background(), Query{TradingPair: "WETH-USDC"})
+	require.NoError(t, err)
+	require.Len(t, volumes, 1)
+	assert.Equal(t, time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), volumes[0].Day)
+	assert.True(t, volumes[0].QuoteVolume.Equal(decimal.RequireFromString("6200.5")))
+
+	traders, err := sink.TopTraders(context.Background(), Query{}, 10)
+	require.NoError(t, err)
+	require.Len(t, traders, 1)
+	assert.Equal(t, int64(2), traders[0].Trades)
+
+	_, err = NewClickHouseSink(ClickHouseConfig{})
+	assert.Error(t, err)
+}
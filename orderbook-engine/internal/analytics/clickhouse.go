@@ -0,0 +1,269 @@
+package analytics
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"orderbook-engine/internal/types"
+)
+
+// clickhouseSchema 成交表：按交易对与月份分区、按交易对和时间排序；
+// ReplacingMergeTree 以成交ID合并重试写入的重复行，查询使用 FINAL 保证精确
+const clickhouseSchema = `CREATE TABLE IF NOT EXISTS analytics_fills (
+	id UUID,
+	trading_pair LowCardinality(String),
+	executed_at DateTime64(6, 'UTC'),
+	price Decimal128(18),
+	amount Decimal128(18),
+	notional Decimal128(18),
+	taker_side LowCardinality(String),
+	taker_address String,
+	maker_address String,
+	taker_order_id UUID,
+	maker_order_id UUID
+) ENGINE = ReplacingMergeTree
+PARTITION BY (trading_pair, toYYYYMM(executed_at))
+ORDER BY (trading_pair, executed_at, id)`
+
+// clickhouseTimeLayout DateTime64(6) 的文本格式
+const clickhouseTimeLayout = "2006-01-02 15:04:05.000000"
+
+// ClickHouseConfig ClickHouse 连接配置（HTTP 接口）
+type ClickHouseConfig struct {
+	URL      string // 如 http://localhost:8123
+	Database string
+	Username string
+	Password string
+	Timeout  time.Duration
+}
+
+// ClickHouseSink 基于 ClickHouse HTTP 接口的分析存储
+type ClickHouseSink struct {
+	config ClickHouseConfig
+	client *http.Client
+}
+
+// clickhouseFill 成交行（JSONEachRow），小数以字符串传递避免精度损失
+type clickhouseFill struct {
+	ID           string `json:"id"`
+	TradingPair  string `json:"trading_pair"`
+	ExecutedAt   string `json:"executed_at"`
+	Price        string `json:"price"`
+	Amount       string `json:"amount"`
+	Notional     string `json:"notional"`
+	TakerSide    string `json:"taker_side"`
+	TakerAddress string `json:"taker_address"`
+	MakerAddress string `json:"maker_address"`
+	TakerOrderID string `json:"taker_order_id"`
+	MakerOrderID string `json:"maker_order_id"`
+}
+
+// NewClickHouseSink 连接 ClickHouse 并确保成交表存在
+func NewClickHouseSink(config ClickHouseConfig) (*ClickHouseSink, error) {
+	if config.URL == "" {
+		return nil, fmt.Errorf("clickhouse url is required")
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 30 * time.Second
+	}
+	sink := &ClickHouseSink{config: config, client: &http.Client{Timeout: config.Timeout}}
+	if _, err := sink.exec(context.Background(), clickhouseSchema, nil, nil); err != nil {
+		return nil, fmt.Errorf("failed to create analytics schema: %w", err)
+	}
+	return sink, nil
+}
+
+// Close 无需释放连接
+func (s *ClickHouseSink) Close() error {
+	s.client.CloseIdleConnections()
+	return nil
+}
+
+// InsertFills 以 JSONEachRow 格式一次写入整批成交
+func (s *ClickHouseSink) InsertFills(ctx context.Context, fills []*types.Fill) error {
+	var body bytes.Buffer
+	encoder := json.NewEncoder(&body)
+	for _, fill := range fills {
+		row := clickhouseFill{
+			ID:           fill.ID.String(),
+			TradingPair:  fill.TradingPair,
+			ExecutedAt:   fill.CreatedAt.UTC().Format(clickhouseTimeLayout),
+			Price:        fill.Price.String(),
+			Amount:       fill.Amount.String(),
+			Notional:     notional(fill).String(),
+			TakerSide:    string(fill.TakerSide),
+			TakerAddress: strings.ToLower(fill.TakerUserAddress),
+			MakerAddress: strings.ToLower(fill.MakerUserAddress),
+			TakerOrderID: fill.TakerOrderID.String(),
+			MakerOrderID: fill.MakerOrderID.String(),
+		}
+		if err := encoder.Encode(&row); err != nil {
+			return fmt.Errorf("failed to encode analytics fill: %w", err)
+		}
+	}
+
+	if _, err := s.exec(ctx, "INSERT INTO analytics_fills FORMAT JSONEachRow", nil, &body); err != nil {
+		return fmt.Errorf("failed to insert analytics fills: %w", err)
+	}
+	return nil
+}
+
+// VolumeByPairDay 按交易对与自然日汇总成交量
+func (s *ClickHouseSink) VolumeByPairDay(ctx context.Context, query Query) ([]PairDayVolume, error) {
+	where, params := clickhouseWhere(query)
+	data, err := s.exec(ctx, `SELECT trading_pair, toString(toStartOfDay(executed_at, 'UTC')) AS day,
+		count() AS trades, toString(sum(amount)) AS base_volume, toString(sum(notional)) AS quote_volume
+		FROM analytics_fills FINAL`+where+`
+		GROUP BY trading_pair, day ORDER BY day, trading_pair FORMAT JSONEachRow`, params, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query analytics volume: %w", err)
+	}
+
+	result := []PairDayVolume{}
+	err = decodeRows(data, func(decode func(interface{}) error) error {
+		var row struct {
+			TradingPair string          `json:"trading_pair"`
+			Day         string          `json:"day"`
+			Trades      int64           `json:"trades"`
+			BaseVolume  decimal.Decimal `json:"base_volume"`
+			QuoteVolume decimal.Decimal `json:"quote_volume"`
+		}
+		if err := decode(&row); err != nil {
+			return err
+		}
+		day, err := time.ParseInLocation("2006-01-02 15:04:05", row.Day, time.UTC)
+		if err != nil {
+			return fmt.Errorf("invalid day %q: %w", row.Day, err)
+		}
+		result = append(result, PairDayVolume{
+			TradingPair: row.TradingPair,
+			Day:         day,
+			Trades:      row.Trades,
+			BaseVolume:  row.BaseVolume,
+			QuoteVolume: row.QuoteVolume,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode analytics volume: %w", err)
+	}
+	return result, nil
+}
+
+// TopTraders 按成交额排序的交易者
+func (s *ClickHouseSink) TopTraders(ctx context.Context, query Query, limit int) ([]TraderVolume, error) {
+	where, params := clickhouseWhere(query)
+	params.Set("param_limit", fmt.Sprint(limit))
+	data, err := s.exec(ctx, `SELECT user_address, count() AS trades, toString(sum(notional)) AS quote_volume
+		FROM analytics_fills FINAL
+		ARRAY JOIN [taker_address, maker_address] AS user_address`+where+`
+		GROUP BY user_address ORDER BY sum(notional) DESC, user_address
+		LIMIT {limit:UInt32} FORMAT JSONEachRow`, params, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query top traders: %w", err)
+	}
+
+	result := []TraderVolume{}
+	err = decodeRows(data, func(decode func(interface{}) error) error {
+		var trader TraderVolume
+		if err := decode(&trader); err != nil {
+			return err
+		}
+		result = append(result, trader)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode top traders: %w", err)
+	}
+	return result, nil
+}
+
+// exec 通过 HTTP 接口执行语句，body 非空时作为插入数据发送
+// 查询参数以 param_<name> 传递，语句中以 {name:Type} 引用；64 位整数以数字而非字符串输出
+func (s *ClickHouseSink) exec(ctx context.Context, statement string, params url.Values, body io.Reader) ([]byte, error) {
+	if params == nil {
+		params = url.Values{}
+	}
+	params.Set("output_format_json_quote_64bit_integers", "0")
+	if s.config.Database != "" {
+		params.Set("database", s.config.Database)
+	}
+
+	var request *http.Request
+	var err error
+	if body == nil {
+		request, err = http.NewRequestWithContext(ctx, http.MethodPost, s.config.URL+"/?"+params.Encode(), strings.NewReader(statement))
+	} else {
+		params.Set("query", statement)
+		request, err = http.NewRequestWithContext(ctx, http.MethodPost, s.config.URL+"/?"+params.Encode(), body)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if s.config.Username != "" {
+		request.Header.Set("X-ClickHouse-User", s.config.Username)
+		request.Header.Set("X-ClickHouse-Key", s.config.Password)
+	}
+
+	response, err := s.client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	data, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("clickhouse returned %d: %s", response.StatusCode, strings.TrimSpace(string(data)))
+	}
+	return data, nil
+}
+
+// clickhouseWhere 生成查询条件与参数
+func clickhouseWhere(query Query) (string, url.Values) {
+	params := url.Values{}
+	var conditions []string
+	if query.TradingPair != "" {
+		params.Set("param_pair", query.TradingPair)
+		conditions = append(conditions, "trading_pair = {pair:String}")
+	}
+	if !query.From.IsZero() {
+		params.Set("param_from", query.From.UTC().Format(clickhouseTimeLayout))
+		conditions = append(conditions, "executed_at >= {from:DateTime64(6, 'UTC')}")
+	}
+	if !query.To.IsZero() {
+		params.Set("param_to", query.To.UTC().Format(clickhouseTimeLayout))
+		conditions = append(conditions, "executed_at < {to:DateTime64(6, 'UTC')}")
+	}
+	if len(conditions) == 0 {
+		return "", params
+	}
+	return " WHERE " + strings.Join(conditions, " AND "), params
+}
+
+// decodeRows 逐行解析 JSONEachRow 结果
+func decodeRows(data []byte, row func(decode func(interface{}) error) error) error {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		if err := row(func(v interface{}) error { return json.Unmarshal(line, v) }); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,131 @@
+package analytics
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"orderbook-engine/internal/types"
+)
+
+// MemorySink 内存分析存储，用于开发与测试
+type MemorySink struct {
+	mu    sync.RWMutex
+	fills map[uuid.UUID]*types.Fill
+}
+
+// NewMemorySink 创建内存分析存储
+func NewMemorySink() *MemorySink {
+	return &MemorySink{fills: make(map[uuid.UUID]*types.Fill)}
+}
+
+// InsertFills 写入成交，按成交ID去重
+func (m *MemorySink) InsertFills(ctx context.Context, fills []*types.Fill) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, fill := range fills {
+		copied := *fill
+		m.fills[fill.ID] = &copied
+	}
+	return nil
+}
+
+// VolumeByPairDay 按交易对与自然日汇总成交量
+func (m *MemorySink) VolumeByPairDay(ctx context.Context, query Query) ([]PairDayVolume, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	type key struct {
+		pair string
+		day  time.Time
+	}
+	totals := make(map[key]*PairDayVolume)
+	for _, fill := range m.fills {
+		if !query.matches(fill) {
+			continue
+		}
+		k := key{pair: fill.TradingPair, day: fill.CreatedAt.UTC().Truncate(24 * time.Hour)}
+		total := totals[k]
+		if total == nil {
+			total = &PairDayVolume{TradingPair: k.pair, Day: k.day}
+			totals[k] = total
+		}
+		total.Trades++
+		total.BaseVolume = total.BaseVolume.Add(fill.Amount)
+		total.QuoteVolume = total.QuoteVolume.Add(notional(fill))
+	}
+
+	result := make([]PairDayVolume, 0, len(totals))
+	for _, total := range totals {
+		result = append(result, *total)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].Day.Equal(result[j].Day) {
+			return result[i].Day.Before(result[j].Day)
+		}
+		return result[i].TradingPair < result[j].TradingPair
+	})
+	return result, nil
+}
+
+// TopTraders 按成交额排序的交易者
+func (m *MemorySink) TopTraders(ctx context.Context, query Query, limit int) ([]TraderVolume, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	totals := make(map[string]*TraderVolume)
+	add := func(address string, value decimal.Decimal) {
+		address = strings.ToLower(address)
+		total := totals[address]
+		if total == nil {
+			total = &TraderVolume{UserAddress: address}
+			totals[address] = total
+		}
+		total.Trades++
+		total.QuoteVolume = total.QuoteVolume.Add(value)
+	}
+	for _, fill := range m.fills {
+		if !query.matches(fill) {
+			continue
+		}
+		value := notional(fill)
+		add(fill.TakerUserAddress, value)
+		add(fill.MakerUserAddress, value)
+	}
+
+	result := make([]TraderVolume, 0, len(totals))
+	for _, total := range totals {
+		result = append(result, *total)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if cmp := result[i].QuoteVolume.Cmp(result[j].QuoteVolume); cmp != 0 {
+			return cmp > 0
+		}
+		return result[i].UserAddress < result[j].UserAddress
+	})
+	if limit > 0 && len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+// Close 无需释放资源
+func (m *MemorySink) Close() error {
+	return nil
+}
+
+// matches 成交是否满足查询条件
+func (q Query) matches(fill *types.Fill) bool {
+	if q.TradingPair != "" && fill.TradingPair != q.TradingPair {
+		return false
+	}
+	if !q.From.IsZero() && fill.CreatedAt.Before(q.From) {
+		return false
+	}
+	return q.To.IsZero() || fill.CreatedAt.Before(q.To)
+}
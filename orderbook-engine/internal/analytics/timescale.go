@@ -0,0 +1,198 @@
+package analytics
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/shopspring/decimal"
+
+	_ "github.com/lib/pq"
+
+	"orderbook-engine/internal/types"
+)
+
+// timescaleSchema 成交超表：按成交时间分块（每块一天），再按交易对做空间分区
+var timescaleSchema = []string{
+	`CREATE EXTENSION IF NOT EXISTS timescaledb`,
+	`CREATE TABLE IF NOT EXISTS analytics_fills (
+		id UUID NOT NULL,
+		trading_pair TEXT NOT NULL,
+		executed_at TIMESTAMPTZ NOT NULL,
+		price NUMERIC(36, 18) NOT NULL,
+		amount NUMERIC(36, 18) NOT NULL,
+		notional NUMERIC(54, 18) NOT NULL,
+		taker_side TEXT NOT NULL,
+		taker_address TEXT NOT NULL,
+		maker_address TEXT NOT NULL,
+		taker_order_id UUID NOT NULL,
+		maker_order_id UUID NOT NULL,
+		PRIMARY KEY (id, trading_pair, executed_at)
+	)`,
+	`SELECT create_hypertable('analytics_fills', 'executed_at',
+		partitioning_column => 'trading_pair', number_partitions => 16,
+		chunk_time_interval => INTERVAL '1 day', if_not_exists => TRUE)`,
+	`CREATE INDEX IF NOT EXISTS idx_analytics_fills_pair_time ON analytics_fills (trading_pair, executed_at DESC)`,
+}
+
+// timescaleColumns 每行写入的参数个数
+const timescaleColumns = 11
+
+// timescaleRowsPerStatement 单条 INSERT 的行数上限（PostgreSQL 单条语句最多 65535 个参数）
+const timescaleRowsPerStatement = 1000
+
+// TimescaleSink 基于 TimescaleDB 的分析存储
+type TimescaleSink struct {
+	db *sql.DB
+}
+
+// NewTimescaleSink 连接 TimescaleDB 并确保超表存在
+func NewTimescaleSink(dsn string) (*TimescaleSink, error) {
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open analytics database: %w", err)
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to connect to analytics database: %w", err)
+	}
+
+	for _, stmt := range timescaleSchema {
+		if _, err := db.Exec(stmt); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("failed to create analytics schema: %w", err)
+		}
+	}
+	return &TimescaleSink{db: db}, nil
+}
+
+// Close 关闭数据库连接
+func (s *TimescaleSink) Close() error {
+	return s.db.Close()
+}
+
+// InsertFills 在一个事务内分段批量写入，已存在的成交忽略
+func (s *TimescaleSink) InsertFills(ctx context.Context, fills []*types.Fill) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin analytics insert: %w", err)
+	}
+	defer tx.Rollback()
+
+	for start := 0; start < len(fills); start += timescaleRowsPerStatement {
+		end := min(start+timescaleRowsPerStatement, len(fills))
+		rows := make([]string, 0, end-start)
+		args := make([]interface{}, 0, (end-start)*timescaleColumns)
+		for i, fill := range fills[start:end] {
+			placeholders := make([]string, timescaleColumns)
+			for j := range placeholders {
+				placeholders[j] = fmt.Sprintf("$%d", i*timescaleColumns+j+1)
+			}
+			rows = append(rows, "("+strings.Join(placeholders, ", ")+")")
+			args = append(args,
+				fill.ID, fill.TradingPair, fill.CreatedAt.UTC(), fill.Price, fill.Amount, notional(fill),
+				string(fill.TakerSide), strings.ToLower(fill.TakerUserAddress), strings.ToLower(fill.MakerUserAddress),
+				fill.TakerOrderID, fill.MakerOrderID)
+		}
+
+		stmt := `INSERT INTO analytics_fills (id, trading_pair, executed_at, price, amount, notional,
+			taker_side, taker_address, maker_address, taker_order_id, maker_order_id)
+			VALUES ` + strings.Join(rows, ", ") + ` ON CONFLICT DO NOTHING`
+		if _, err := tx.ExecContext(ctx, stmt, args...); err != nil {
+			return fmt.Errorf("failed to insert analytics fills: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit analytics fills: %w", err)
+	}
+	return nil
+}
+
+// VolumeByPairDay 按交易对与自然日汇总成交量
+func (s *TimescaleSink) VolumeByPairDay(ctx context.Context, query Query) ([]PairDayVolume, error) {
+	where, args := timescaleWhere(query)
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT trading_pair, time_bucket(INTERVAL '1 day', executed_at) AS day,
+			COUNT(*), SUM(amount)::TEXT, SUM(notional)::TEXT
+		FROM analytics_fills`+where+`
+		GROUP BY trading_pair, day ORDER BY day, trading_pair`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query analytics volume: %w", err)
+	}
+	defer rows.Close()
+
+	result := []PairDayVolume{}
+	for rows.Next() {
+		var volume PairDayVolume
+		var base, quote string
+		if err := rows.Scan(&volume.TradingPair, &volume.Day, &volume.Trades, &base, &quote); err != nil {
+			return nil, fmt.Errorf("failed to scan analytics volume: %w", err)
+		}
+		volume.Day = volume.Day.UTC()
+		if volume.BaseVolume, err = decimal.NewFromString(base); err != nil {
+			return nil, fmt.Errorf("invalid base volume %q: %w", base, err)
+		}
+		if volume.QuoteVolume, err = decimal.NewFromString(quote); err != nil {
+			return nil, fmt.Errorf("invalid quote volume %q: %w", quote, err)
+		}
+		result = append(result, volume)
+	}
+	return result, rows.Err()
+}
+
+// TopTraders 按成交额排序的交易者
+func (s *TimescaleSink) TopTraders(ctx context.Context, query Query, limit int) ([]TraderVolume, error) {
+	where, args := timescaleWhere(query)
+	args = append(args, limit)
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT user_address, COUNT(*), SUM(notional)::TEXT FROM (
+			SELECT taker_address AS user_address, notional FROM analytics_fills`+where+`
+			UNION ALL
+			SELECT maker_address AS user_address, notional FROM analytics_fills`+where+`
+		) sides
+		GROUP BY user_address ORDER BY SUM(notional) DESC, user_address
+		LIMIT $`+fmt.Sprint(len(args)), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query top traders: %w", err)
+	}
+	defer rows.Close()
+
+	result := []TraderVolume{}
+	for rows.Next() {
+		var trader TraderVolume
+		var quote string
+		if err := rows.Scan(&trader.UserAddress, &trader.Trades, &quote); err != nil {
+			return nil, fmt.Errorf("failed to scan top traders: %w", err)
+		}
+		if trader.QuoteVolume, err = decimal.NewFromString(quote); err != nil {
+			return nil, fmt.Errorf("invalid quote volume %q: %w", quote, err)
+		}
+		result = append(result, trader)
+	}
+	return result, rows.Err()
+}
+
+// timescaleWhere 生成查询条件，参数按出现顺序编号
+func timescaleWhere(query Query) (string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+	add := func(condition string, value interface{}) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+	if query.TradingPair != "" {
+		add("trading_pair = $%d", query.TradingPair)
+	}
+	if !query.From.IsZero() {
+		add("executed_at >= $%d", query.From.UTC())
+	}
+	if !query.To.IsZero() {
+		add("executed_at < $%d", query.To.UTC())
+	}
+	if len(conditions) == 0 {
+		return "", nil
+	}
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
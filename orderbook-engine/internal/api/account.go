@@ -0,0 +1,108 @@
+package api
+
+import (
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+
+	"orderbook-engine/internal/types"
+	"orderbook-engine/internal/wallet"
+)
+
+// pairExposure 单个交易对的挂单敞口
+type pairExposure struct {
+	TradingPair  string          `json:"trading_pair"`
+	OpenOrders   int             `json:"open_orders"`
+	BuyNotional  decimal.Decimal `json:"buy_notional"`  // 买单剩余数量 × 价格（报价代币）
+	SellNotional decimal.Decimal `json:"sell_notional"` // 卖单剩余数量 × 价格（报价代币）
+	SellAmount   decimal.Decimal `json:"sell_amount"`   // 卖单剩余数量（基础代币）
+}
+
+// accountRisk 账户风控状态
+type accountRisk struct {
+	Blacklisted       bool       `json:"blacklisted"`
+	BlacklistReason   string     `json:"blacklist_reason,omitempty"`
+	BlacklistUntil    *time.Time `json:"blacklist_until,omitempty"`
+	Frozen            bool       `json:"frozen"`
+	FrozenReason      string     `json:"frozen_reason,omitempty"`
+	WithdrawalsFrozen bool       `json:"withdrawals_frozen"`
+}
+
+// accountSummary 账户概览
+type accountSummary struct {
+	Address        string                        `json:"address"`
+	Balances       map[string]wallet.BalanceInfo `json:"balances,omitempty"`
+	OpenOrders     int                           `json:"open_orders"`
+	Exposure       []*pairExposure               `json:"exposure"`
+	UnsettledFills []*types.Fill                 `json:"unsettled_fills,omitempty"`
+	Risk           *accountRisk                  `json:"risk,omitempty"`
+	Timestamp      time.Time                     `json:"timestamp"`
+}
+
+// GetAccountSummary 获取账户概览：余额、各交易对挂单与敞口、未结算成交和风控状态
+// 未启用的模块（余额、结算、风控）对应字段省略
+func (h *Handler) GetAccountSummary(c *gin.Context) {
+	address := c.Param("address")
+	if !common.IsHexAddress(address) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid address", "code": CodeInvalidAddress})
+		return
+	}
+
+	summary := &accountSummary{
+		Address:   address,
+		Exposure:  make([]*pairExposure, 0),
+		Timestamp: time.Now(),
+	}
+
+	if h.balances != nil {
+		summary.Balances = h.balances.GetUserBalances(address)
+	}
+
+	exposures := make(map[string]*pairExposure)
+	for _, order := range h.engine.GetUserOrders(address) {
+		exposure, exists := exposures[order.TradingPair]
+		if !exists {
+			exposure = &pairExposure{TradingPair: order.TradingPair}
+			exposures[order.TradingPair] = exposure
+			summary.Exposure = append(summary.Exposure, exposure)
+		}
+
+		remaining := order.Amount.Sub(order.FilledAmount)
+		notional := remaining.Mul(order.Price)
+		if order.Side == types.OrderSideBuy {
+			exposure.BuyNotional = exposure.BuyNotional.Add(notional)
+		} else {
+			exposure.SellNotional = exposure.SellNotional.Add(notional)
+			exposure.SellAmount = exposure.SellAmount.Add(remaining)
+		}
+		exposure.OpenOrders++
+		summary.OpenOrders++
+	}
+	sort.Slice(summary.Exposure, func(i, j int) bool {
+		return summary.Exposure[i].TradingPair < summary.Exposure[j].TradingPair
+	})
+
+	if h.settlement != nil {
+		summary.UnsettledFills = h.settlement.UnsettledFills(address)
+	}
+
+	if h.risk != nil {
+		summary.Risk = &accountRisk{}
+		if entry, blacklisted := h.risk.GetBlacklistStatus(address); blacklisted {
+			summary.Risk.Blacklisted = true
+			summary.Risk.BlacklistReason = entry.Reason
+			summary.Risk.BlacklistUntil = &entry.ExpiresAt
+		}
+		if freeze, frozen := h.risk.GetAccountFreeze(address); frozen {
+			summary.Risk.Frozen = true
+			summary.Risk.FrozenReason = freeze.Reason
+			summary.Risk.WithdrawalsFrozen = freeze.FreezeWithdrawals
+		}
+	}
+
+	c.JSON(http.StatusOK, summary)
+}
@@ -0,0 +1,465 @@
+package api
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"orderbook-engine/internal/drain"
+	"orderbook-engine/internal/riskcontrol"
+	"orderbook-engine/internal/surveillance"
+)
+
+// AdminAuthMiddleware 管理接口鉴权中间件
+// 通过 X-Admin-Token 请求头校验，未配置令牌时拒绝所有管理请求
+func (h *Handler) AdminAuthMiddleware(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		provided := c.GetHeader("X-Admin-Token")
+		if token == "" || subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Admin authentication required", "code": CodeUnauthorized})
+			return
+		}
+		c.Next()
+	}
+}
+
+// GetRiskPairConfigs 获取交易对风控覆盖配置
+func (h *Handler) GetRiskPairConfigs(c *gin.Context) {
+	if h.risk == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Risk control disabled", "code": CodeFeatureDisabled})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"pairs": h.risk.GetPairConfigs(),
+	})
+}
+
+// SetRiskPairConfig 设置交易对风控覆盖配置（热更新）
+func (h *Handler) SetRiskPairConfig(c *gin.Context) {
+	if h.risk == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Risk control disabled", "code": CodeFeatureDisabled})
+		return
+	}
+
+	// 与配置文件 risk.pairs 相同按大写交易对登记
+	tradingPair := strings.ToUpper(strings.TrimSpace(c.Param("trading_pair")))
+	if !h.knownPair(tradingPair) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown trading pair", "code": CodeInvalidRequest, "details": tradingPair})
+		return
+	}
+	var pairConfig riskcontrol.PairRiskConfig
+	if err := c.ShouldBindJSON(&pairConfig); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid risk config", "code": CodeInvalidRequest, "details": err.Error()})
+		return
+	}
+
+	if err := h.risk.SetPairConfig(tradingPair, &pairConfig); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid risk config", "code": CodeInvalidRequest, "details": err.Error()})
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"trading_pair": tradingPair,
+		"client_ip":    c.ClientIP(),
+	}).Info("Admin updated pair risk config")
+
+	c.JSON(http.StatusOK, gin.H{
+		"trading_pair": tradingPair,
+		"effective":    h.risk.EffectiveConfig(tradingPair),
+	})
+}
+
+// DeleteRiskPairConfig 删除交易对风控覆盖配置
+func (h *Handler) DeleteRiskPairConfig(c *gin.Context) {
+	if h.risk == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Risk control disabled", "code": CodeFeatureDisabled})
+		return
+	}
+
+	// 已登记的覆盖配置总可以删除，交易对下架后也能清理
+	tradingPair := strings.ToUpper(strings.TrimSpace(c.Param("trading_pair")))
+	if !h.risk.RemovePairConfig(tradingPair) {
+		if !h.knownPair(tradingPair) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown trading pair", "code": CodeInvalidRequest, "details": tradingPair})
+			return
+		}
+		c.JSON(http.StatusNotFound, gin.H{"error": "Pair risk config not found", "code": CodeNotFound})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"trading_pair": tradingPair, "removed": true})
+}
+
+// knownPair 交易对是否存在：已登记上架、分配到某条链或已有订单簿
+func (h *Handler) knownPair(tradingPair string) bool {
+	if h.listings != nil {
+		if _, ok := h.listings.Get(tradingPair); ok {
+			return true
+		}
+	}
+	if h.chains != nil {
+		for _, chain := range h.chains.Chains() {
+			for _, pair := range chain.TradingPairs {
+				if strings.EqualFold(pair, tradingPair) {
+					return true
+				}
+			}
+		}
+	}
+	for _, pair := range h.engine.TradingPairs() {
+		if pair == tradingPair {
+			return true
+		}
+	}
+	return false
+}
+
+// GetCircuitBreakerStates 获取各交易对熔断状态
+func (h *Handler) GetCircuitBreakerStates(c *gin.Context) {
+	if h.breaker == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Circuit breaker disabled", "code": CodeFeatureDisabled})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"pairs": h.breaker.GetStates(),
+	})
+}
+
+// HaltTradingPair 人工暂停交易对
+func (h *Handler) HaltTradingPair(c *gin.Context) {
+	if h.breaker == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Circuit breaker disabled", "code": CodeFeatureDisabled})
+		return
+	}
+
+	var req struct {
+		Reason   string `json:"reason" binding:"required"`
+		Duration string `json:"duration"` // 为空表示需人工恢复
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid halt request", "code": CodeInvalidRequest, "details": err.Error()})
+		return
+	}
+
+	var duration time.Duration
+	if req.Duration != "" {
+		var err error
+		duration, err = time.ParseDuration(req.Duration)
+		if err != nil || duration < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid duration", "code": CodeInvalidRequest})
+			return
+		}
+	}
+
+	tradingPair := c.Param("trading_pair")
+	h.breaker.Halt(tradingPair, req.Reason, duration)
+
+	c.JSON(http.StatusOK, gin.H{"trading_pair": tradingPair, "halted": true})
+}
+
+// ResumeTradingPair 人工恢复交易对
+func (h *Handler) ResumeTradingPair(c *gin.Context) {
+	if h.breaker == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Circuit breaker disabled", "code": CodeFeatureDisabled})
+		return
+	}
+
+	tradingPair := c.Param("trading_pair")
+	if !h.breaker.Resume(tradingPair, "resumed by admin") {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Trading pair is not halted", "code": CodeNotFound})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"trading_pair": tradingPair, "halted": false})
+}
+
+// GetAuctions 获取进行中的集合竞价及参考撮合价
+func (h *Handler) GetAuctions(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"auctions": h.engine.GetAuctions(),
+	})
+}
+
+// StartAuction 交易对进入集合竞价（开盘、上新等），到期自动撮合并恢复连续交易
+func (h *Handler) StartAuction(c *gin.Context) {
+	var req struct {
+		Duration string `json:"duration" binding:"required"`
+		Reason   string `json:"reason"` // 默认 opening
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid auction request", "code": CodeInvalidRequest, "details": err.Error()})
+		return
+	}
+
+	duration, err := time.ParseDuration(req.Duration)
+	if err != nil || duration <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid duration", "code": CodeInvalidRequest})
+		return
+	}
+	if req.Reason == "" {
+		req.Reason = "opening"
+	}
+
+	tradingPair := c.Param("trading_pair")
+	if err := h.engine.StartAuction(tradingPair, req.Reason, duration); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to start auction", "code": CodeInvalidRequest, "details": err.Error()})
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"trading_pair": tradingPair,
+		"duration":     duration.String(),
+		"client_ip":    c.ClientIP(),
+	}).Info("Admin started call auction")
+
+	c.JSON(http.StatusOK, gin.H{"trading_pair": tradingPair, "auction": true, "ends_at": time.Now().Add(duration)})
+}
+
+// UncrossAuction 立即结束集合竞价并按统一价格撮合
+func (h *Handler) UncrossAuction(c *gin.Context) {
+	tradingPair := c.Param("trading_pair")
+	fills, ok := h.engine.EndAuction(tradingPair)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Trading pair is not in auction", "code": CodeNotFound})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"trading_pair": tradingPair, "auction": false, "fills": fills})
+}
+
+// GetLoadShedStatus 获取过载降级状态
+func (h *Handler) GetLoadShedStatus(c *gin.Context) {
+	if h.shedder == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Load shedding disabled", "code": CodeFeatureDisabled})
+		return
+	}
+
+	c.JSON(http.StatusOK, h.shedder.GetStatus())
+}
+
+// GetEventConsumers 获取撮合事件消费者积压与丢弃统计
+func (h *Handler) GetEventConsumers(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"consumers": h.engine.GetSubscriptionStats(),
+	})
+}
+
+// GetSurveillanceAlerts 获取成交异常告警
+func (h *Handler) GetSurveillanceAlerts(c *gin.Context) {
+	if h.detector == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Surveillance disabled", "code": CodeFeatureDisabled})
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if err != nil || limit <= 0 || limit > 1000 {
+		limit = 100
+	}
+
+	alerts := h.detector.GetAlerts(c.Query("trading_pair"), limit)
+	c.JSON(http.StatusOK, gin.H{
+		"alerts": alerts,
+		"total":  len(alerts),
+	})
+}
+
+// GetSurveillanceConfig 获取异常检测阈值
+func (h *Handler) GetSurveillanceConfig(c *gin.Context) {
+	if h.detector == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Surveillance disabled", "code": CodeFeatureDisabled})
+		return
+	}
+
+	defaults, markets := h.detector.GetMarketConfigs()
+	c.JSON(http.StatusOK, gin.H{
+		"defaults": defaults,
+		"markets":  markets,
+	})
+}
+
+// SetSurveillanceConfig 设置交易对异常检测阈值（热更新）
+func (h *Handler) SetSurveillanceConfig(c *gin.Context) {
+	if h.detector == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Surveillance disabled", "code": CodeFeatureDisabled})
+		return
+	}
+
+	var config surveillance.Config
+	if err := c.ShouldBindJSON(&config); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid surveillance config", "code": CodeInvalidRequest, "details": err.Error()})
+		return
+	}
+	if config.MaxIndexDeviation.IsNegative() || config.VolumeSpikeMultiplier.IsNegative() || config.RepeatCount < 0 || config.RepeatWindow < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid surveillance config", "code": CodeInvalidRequest, "details": "thresholds must not be negative"})
+		return
+	}
+
+	tradingPair := c.Param("trading_pair")
+	h.detector.SetMarketConfig(tradingPair, &config)
+
+	h.logger.WithFields(logrus.Fields{
+		"trading_pair": tradingPair,
+		"client_ip":    c.ClientIP(),
+	}).Info("Admin updated surveillance config")
+
+	c.JSON(http.StatusOK, gin.H{"trading_pair": tradingPair, "config": config})
+}
+
+// GetTopicGrants 获取WebSocket主题额外授权
+func (h *Handler) GetTopicGrants(c *gin.Context) {
+	if h.topicACL == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "WebSocket ACL disabled", "code": CodeFeatureDisabled})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"grants": h.topicACL.GetGrants(),
+	})
+}
+
+// GrantTopic 授予账号只读订阅主题的权限（审计、客服账号）
+func (h *Handler) GrantTopic(c *gin.Context) {
+	if h.topicACL == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "WebSocket ACL disabled", "code": CodeFeatureDisabled})
+		return
+	}
+
+	var req struct {
+		Topic string `json:"topic" binding:"required"` // 支持 "orders.*" 前缀通配
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid grant request", "code": CodeInvalidRequest, "details": err.Error()})
+		return
+	}
+
+	address := c.Param("address")
+	if err := h.topicACL.Grant(address, req.Topic); err != nil {
+		h.logger.WithError(err).Error("Failed to grant WebSocket topic")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to grant topic", "code": CodeInternal, "details": err.Error()})
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"address":   address,
+		"topic":     req.Topic,
+		"client_ip": c.ClientIP(),
+	}).Info("Admin granted WebSocket topic access")
+
+	c.JSON(http.StatusOK, gin.H{"address": address, "topic": req.Topic, "granted": true})
+}
+
+// RevokeTopic 撤销账号的主题授权
+func (h *Handler) RevokeTopic(c *gin.Context) {
+	if h.topicACL == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "WebSocket ACL disabled", "code": CodeFeatureDisabled})
+		return
+	}
+
+	address := c.Param("address")
+	topic := c.Query("topic")
+	if topic == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Topic required", "code": CodeInvalidRequest})
+		return
+	}
+
+	removed, err := h.topicACL.Revoke(address, topic)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to revoke WebSocket topic")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke topic", "code": CodeInternal, "details": err.Error()})
+		return
+	}
+	if !removed {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Grant not found", "code": CodeNotFound})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"address": address, "topic": topic, "granted": false})
+}
+
+// GetWebSocketConnections 列出WebSocket连接及其订阅、心跳和发送滞后指标
+func (h *Handler) GetWebSocketConnections(c *gin.Context) {
+	if h.wsHub == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "WebSocket hub unavailable", "code": CodeFeatureDisabled})
+		return
+	}
+
+	connections := h.wsHub.GetConnections()
+	config := h.wsHub.GetConfig()
+	c.JSON(http.StatusOK, gin.H{
+		"connections":               connections,
+		"total":                     len(connections),
+		"send_buffer":               config.SendBuffer,
+		"slow_consumer_policy":      config.Policy,
+		"slow_consumer_disconnects": h.wsHub.SlowConsumerDisconnects(),
+		"book_conflation":           h.wsHub.GetConflationStats(),
+	})
+}
+
+// GetLiquidityBotStatus 获取做市机器人状态
+func (h *Handler) GetLiquidityBotStatus(c *gin.Context) {
+	if h.quoter == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Liquidity bot disabled", "code": CodeFeatureDisabled})
+		return
+	}
+
+	c.JSON(http.StatusOK, h.quoter.GetStatus())
+}
+
+// KillLiquidityBot 紧急停止做市机器人并撤销全部报价
+func (h *Handler) KillLiquidityBot(c *gin.Context) {
+	if h.quoter == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Liquidity bot disabled", "code": CodeFeatureDisabled})
+		return
+	}
+
+	h.quoter.Kill()
+	h.logger.WithField("client_ip", c.ClientIP()).Warn("Admin killed liquidity bot")
+	c.JSON(http.StatusOK, gin.H{"killed": true})
+}
+
+// ResumeLiquidityBot 恢复做市机器人
+func (h *Handler) ResumeLiquidityBot(c *gin.Context) {
+	if h.quoter == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Liquidity bot disabled", "code": CodeFeatureDisabled})
+		return
+	}
+
+	h.quoter.Resume()
+	c.JSON(http.StatusOK, gin.H{"killed": false})
+}
+
+// SetDrainer 设置排空协调器
+func (h *Handler) SetDrainer(drainer *drain.Drainer) {
+	h.drainer = drainer
+}
+
+// StartDrain 开始排空停机：停止接单，等待撮合、事件和结算完成并保存快照后进程退出
+func (h *Handler) StartDrain(c *gin.Context) {
+	if h.drainer == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Drain not configured", "code": CodeFeatureDisabled})
+		return
+	}
+
+	if !h.drainer.Start("admin") {
+		c.JSON(http.StatusConflict, gin.H{"error": "Drain already in progress", "code": CodeConflict, "status": h.drainer.Status()})
+		return
+	}
+	h.logger.Warn("Drain requested via admin API")
+	c.JSON(http.StatusAccepted, h.drainer.Status())
+}
+
+// GetDrainStatus 获取排空进度
+func (h *Handler) GetDrainStatus(c *gin.Context) {
+	if h.drainer == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Drain not configured", "code": CodeFeatureDisabled})
+		return
+	}
+	c.JSON(http.StatusOK, h.drainer.Status())
+}
@@ -0,0 +1,56 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"orderbook-engine/internal/riskcontrol"
+)
+
+func TestRiskPairConfigNormalizesPair(t *testing.T) {
+	handler, store := newTestHandler(t)
+	risk := riskcontrol.NewRiskController(nil, riskcontrol.DefaultRiskConfig(), logrus.New())
+	handler.SetRiskController(risk)
+	router := gin.New()
+	router.PUT("/admin/risk/pairs/:trading_pair", handler.SetRiskPairConfig)
+	router.DELETE("/admin/risk/pairs/:trading_pair", handler.DeleteRiskPairConfig)
+
+	// 已有订单簿的交易对
+	restOrder(t, handler, store, newTestWallet(t).address)
+
+	request := func(method, pair, body string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		req := httptest.NewRequest(method, "/admin/risk/pairs/"+pair, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	// 大小写与空白不同的名称登记到同一交易对
+	recorder := request(http.MethodPut, "%20weth-usdc%20", `{"max_order_amount":"100"}`)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	configs := risk.GetPairConfigs()
+	require.Contains(t, configs, "WETH-USDC")
+	assert.Len(t, configs, 1)
+
+	// 未知交易对被拒绝
+	recorder = request(http.MethodPut, "WETH-USDT", `{"max_order_amount":"100"}`)
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.Equal(t, CodeInvalidRequest, errorCode(t, recorder))
+	assert.Len(t, risk.GetPairConfigs(), 1)
+	recorder = request(http.MethodDelete, "WETH-USDT", "")
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+
+	recorder = request(http.MethodDelete, "weth-usdc", "")
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Empty(t, risk.GetPairConfigs())
+	recorder = request(http.MethodDelete, "WETH-USDC", "")
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+}
@@ -0,0 +1,88 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"orderbook-engine/internal/analytics"
+)
+
+// SetAnalytics 设置成交分析存储与归档器
+func (h *Handler) SetAnalytics(sink analytics.Sink, archiver *analytics.Archiver) {
+	h.analytics = sink
+	h.archiver = archiver
+}
+
+// analyticsQuery 解析聚合查询参数 trading_pair、from、to（Unix秒或RFC3339）
+func analyticsQuery(c *gin.Context) (analytics.Query, bool) {
+	query := analytics.Query{TradingPair: c.Query("trading_pair")}
+	var err error
+	if query.From, err = parseTimeParam(c.Query("from")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from", "code": CodeInvalidRequest, "details": err.Error()})
+		return query, false
+	}
+	if query.To, err = parseTimeParam(c.Query("to")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to", "code": CodeInvalidRequest, "details": err.Error()})
+		return query, false
+	}
+	if !query.From.IsZero() && !query.To.IsZero() && !query.From.Before(query.To) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid time range", "code": CodeInvalidRequest, "details": "from must be before to"})
+		return query, false
+	}
+	return query, true
+}
+
+// GetVolumeByPairDay 按交易对与自然日（UTC）汇总的成交量
+func (h *Handler) GetVolumeByPairDay(c *gin.Context) {
+	if h.analytics == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Analytics archive not enabled", "code": CodeFeatureDisabled})
+		return
+	}
+	query, ok := analyticsQuery(c)
+	if !ok {
+		return
+	}
+
+	volumes, err := h.analytics.VolumeByPairDay(c.Request.Context(), query)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to query analytics volume")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query volume", "code": CodeInternal})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"volumes": volumes})
+}
+
+// GetTopTraders 按成交额排序的交易者，查询参数 limit（默认20，最大100）
+func (h *Handler) GetTopTraders(c *gin.Context) {
+	if h.analytics == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Analytics archive not enabled", "code": CodeFeatureDisabled})
+		return
+	}
+	query, ok := analyticsQuery(c)
+	if !ok {
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit <= 0 || limit > 100 {
+		limit = 20
+	}
+
+	traders, err := h.analytics.TopTraders(c.Request.Context(), query, limit)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to query top traders")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query top traders", "code": CodeInternal})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"traders": traders})
+}
+
+// GetAnalyticsArchiveStats 成交归档统计（管理接口）
+func (h *Handler) GetAnalyticsArchiveStats(c *gin.Context) {
+	if h.archiver == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Analytics archive not enabled", "code": CodeFeatureDisabled})
+		return
+	}
+	c.JSON(http.StatusOK, h.archiver.Stats())
+}
@@ -0,0 +1,128 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"orderbook-engine/internal/audit"
+	"orderbook-engine/internal/types"
+)
+
+// SetAuditRecorder 设置审计记录器
+func (h *Handler) SetAuditRecorder(recorder *audit.Recorder) {
+	h.audit = recorder
+}
+
+// recordAudit 写入审计记录，未启用审计时忽略
+func (h *Handler) recordAudit(entry *audit.Entry) {
+	if h.audit != nil {
+		h.audit.Record(entry)
+	}
+}
+
+// recordOrderAudit 记录用户对订单的操作，err 不为空时记录为失败
+func (h *Handler) recordOrderAudit(action string, order *types.Order, err error) {
+	entry := &audit.Entry{
+		ActorType: audit.ActorUser,
+		Actor:     order.UserAddress,
+		Action:    action,
+		Resource:  order.ID.String(),
+		Details: map[string]interface{}{
+			"trading_pair": order.TradingPair,
+			"side":         order.Side,
+			"type":         order.Type,
+			"price":        order.Price.String(),
+			"amount":       order.Amount.String(),
+			"status":       order.Status,
+		},
+	}
+	if order.StatusReason != "" {
+		entry.Details["status_reason"] = order.StatusReason
+	}
+	if err != nil {
+		entry.Outcome = audit.OutcomeFailure
+		entry.Details["error"] = err.Error()
+	}
+	h.recordAudit(entry)
+}
+
+// AuditMiddleware 管理接口审计中间件，记录所有修改类请求及其结果
+func (h *Handler) AuditMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead {
+			return
+		}
+		entry := &audit.Entry{
+			ActorType: audit.ActorAdmin,
+			Actor:     c.ClientIP(),
+			Action:    audit.ActionAdminRequest,
+			Resource:  c.FullPath(),
+			Details: map[string]interface{}{
+				"method": c.Request.Method,
+				"path":   c.Request.URL.Path,
+				"status": c.Writer.Status(),
+			},
+		}
+		if c.Writer.Status() >= http.StatusBadRequest {
+			entry.Outcome = audit.OutcomeFailure
+		}
+		h.recordAudit(entry)
+	}
+}
+
+// GetAuditLog 查询审计记录
+// 参数：actor_type、actor、action、resource、from、to（Unix秒或RFC3339）、limit（默认100，最大1000）
+func (h *Handler) GetAuditLog(c *gin.Context) {
+	if h.audit == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Audit log disabled", "code": CodeFeatureDisabled})
+		return
+	}
+
+	query := audit.Query{
+		ActorType: c.Query("actor_type"),
+		Actor:     c.Query("actor"),
+		Action:    c.Query("action"),
+		Resource:  c.Query("resource"),
+		Limit:     100,
+	}
+	if value := c.Query("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit <= 0 || limit > 1000 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit", "code": CodeInvalidRequest, "details": "limit must be between 1 and 1000"})
+			return
+		}
+		query.Limit = limit
+	}
+
+	from, err := parseTimeParam(c.Query("from"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid start time", "code": CodeInvalidRequest, "details": err.Error()})
+		return
+	}
+	to, err := parseTimeParam(c.Query("to"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid end time", "code": CodeInvalidRequest, "details": err.Error()})
+		return
+	}
+	if !from.IsZero() {
+		query.From = &from
+	}
+	if !to.IsZero() {
+		query.To = &to
+	}
+
+	entries, err := h.audit.Query(query)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to query audit log")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query audit log", "code": CodeInternal})
+		return
+	}
+	if entries == nil {
+		entries = []*audit.Entry{}
+	}
+	c.JSON(http.StatusOK, gin.H{"entries": entries})
+}
@@ -0,0 +1,104 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+
+	"orderbook-engine/internal/audit"
+)
+
+// GetBalances 获取用户所有代币余额
+// GET /api/v1/balances/:address
+func (h *Handler) GetBalances(c *gin.Context) {
+	if h.balances == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Balance manager disabled", "code": CodeFeatureDisabled})
+		return
+	}
+
+	address := c.Param("address")
+	c.JSON(http.StatusOK, gin.H{
+		"user_address": address,
+		"balances":     h.balances.GetUserBalances(address),
+	})
+}
+
+// GetTokenBalance 获取用户单个代币余额
+// GET /api/v1/balances/:address/:token
+func (h *Handler) GetTokenBalance(c *gin.Context) {
+	if h.balances == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Balance manager disabled", "code": CodeFeatureDisabled})
+		return
+	}
+
+	address := c.Param("address")
+	token := c.Param("token")
+	info := h.balances.GetTokenBalance(address, token)
+	c.JSON(http.StatusOK, gin.H{
+		"user_address": address,
+		"token":        token,
+		"total":        info.Total,
+		"locked":       info.Locked,
+		"available":    info.Available,
+	})
+}
+
+// depositRequest 充值入账请求
+type depositRequest struct {
+	DepositID   string          `json:"deposit_id" binding:"required"`
+	UserAddress string          `json:"user_address" binding:"required"`
+	Token       string          `json:"token" binding:"required"`
+	Amount      decimal.Decimal `json:"amount"`
+	Source      string          `json:"source"`
+}
+
+// CreditDeposit 充值入账（由充值监听服务调用）
+// POST /admin/v1/deposits，同一 deposit_id 重复提交不会重复入账
+func (h *Handler) CreditDeposit(c *gin.Context) {
+	if h.balances == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Balance manager disabled", "code": CodeFeatureDisabled})
+		return
+	}
+
+	var req depositRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid deposit", "code": CodeInvalidRequest, "details": err.Error()})
+		return
+	}
+
+	record, credited, err := h.balances.CreditDeposit(req.DepositID, req.UserAddress, req.Token, req.Amount, req.Source)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid deposit", "code": CodeInvalidRequest, "details": err.Error()})
+		return
+	}
+
+	if credited {
+		h.logger.WithFields(logrus.Fields{
+			"deposit_id": record.DepositID,
+			"user":       record.UserAddress,
+			"token":      record.Token,
+			"amount":     record.Amount.String(),
+			"client_ip":  c.ClientIP(),
+		}).Info("Admin credited deposit")
+		h.recordAudit(&audit.Entry{
+			ActorType: audit.ActorAdmin,
+			Actor:     c.ClientIP(),
+			Action:    audit.ActionBalanceDeposit,
+			Resource:  record.UserAddress,
+			Details: map[string]interface{}{
+				"deposit_id": record.DepositID,
+				"token":      record.Token,
+				"amount":     record.Amount.String(),
+				"source":     record.Source,
+			},
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"deposit":  record,
+		"credited": credited,
+		"balance":  h.balances.GetTokenBalance(record.UserAddress, record.Token),
+	})
+}
@@ -0,0 +1,111 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"orderbook-engine/internal/audit"
+)
+
+// GetBlacklist 获取全部未过期的黑名单条目（管理接口）
+func (h *Handler) GetBlacklist(c *gin.Context) {
+	if h.risk == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Risk control disabled", "code": CodeFeatureDisabled})
+		return
+	}
+	entries := h.risk.GetBlacklist()
+	c.JSON(http.StatusOK, gin.H{"entries": entries, "total": len(entries)})
+}
+
+// AddToBlacklist 将账户加入黑名单（管理接口），duration 为空时使用风控配置的黑名单时长
+// 黑名单拒绝新订单与撤单，已有挂单保留；需要撤销挂单时使用账户冻结
+func (h *Handler) AddToBlacklist(c *gin.Context) {
+	if h.risk == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Risk control disabled", "code": CodeFeatureDisabled})
+		return
+	}
+
+	var req struct {
+		UserAddress string `json:"user_address" binding:"required"`
+		Reason      string `json:"reason" binding:"required"`
+		Duration    string `json:"duration"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid blacklist request", "code": CodeInvalidRequest, "details": err.Error()})
+		return
+	}
+	if !common.IsHexAddress(req.UserAddress) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid address", "code": CodeInvalidAddress})
+		return
+	}
+	duration := h.risk.Config().BlacklistDuration
+	if req.Duration != "" {
+		var err error
+		duration, err = time.ParseDuration(req.Duration)
+		if err != nil || duration <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid duration", "code": CodeInvalidRequest})
+			return
+		}
+	}
+
+	// 持久化失败时条目已在内存中生效，仍记录审计后返回错误
+	entry, err := h.risk.AddToBlacklist(req.UserAddress, req.Reason, duration)
+
+	h.logger.WithFields(logrus.Fields{
+		"user_address": req.UserAddress,
+		"reason":       req.Reason,
+		"expires_at":   entry.ExpiresAt,
+		"client_ip":    c.ClientIP(),
+	}).Warn("Admin added account to blacklist")
+	h.recordAudit(&audit.Entry{
+		ActorType: audit.ActorAdmin,
+		Actor:     c.ClientIP(),
+		Action:    audit.ActionBlacklistAdd,
+		Resource:  req.UserAddress,
+		Details: map[string]interface{}{
+			"reason":     req.Reason,
+			"expires_at": entry.ExpiresAt,
+		},
+	})
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Blacklist entry applied but not persisted", "code": CodeInternal, "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"entry": entry})
+}
+
+// RemoveFromBlacklist 将账户移出黑名单（管理接口）
+func (h *Handler) RemoveFromBlacklist(c *gin.Context) {
+	if h.risk == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Risk control disabled", "code": CodeFeatureDisabled})
+		return
+	}
+	address := c.Param("address")
+	removed, err := h.risk.RemoveFromBlacklist(address)
+	if !removed {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Account not blacklisted", "code": CodeNotFound})
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"user_address": address,
+		"client_ip":    c.ClientIP(),
+	}).Warn("Admin removed account from blacklist")
+	h.recordAudit(&audit.Entry{
+		ActorType: audit.ActorAdmin,
+		Actor:     c.ClientIP(),
+		Action:    audit.ActionBlacklistRemove,
+		Resource:  address,
+	})
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Blacklist entry removed but not persisted", "code": CodeInternal, "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"user_address": address, "blacklisted": false})
+}
@@ -0,0 +1,48 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"orderbook-engine/internal/booksnapshot"
+)
+
+// SetBookSnapshotStore 设置订单簿历史快照存储
+func (h *Handler) SetBookSnapshotStore(store booksnapshot.Store) {
+	h.bookSnapshots = store
+}
+
+// GetOrderBookHistory 查询交易对在指定时刻的订单簿快照
+// 查询参数 at（Unix秒或RFC3339）必填，返回该时刻之前最近一次记录的快照
+func (h *Handler) GetOrderBookHistory(c *gin.Context) {
+	if h.bookSnapshots == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Order book history not enabled", "code": CodeFeatureDisabled})
+		return
+	}
+
+	tradingPair := c.Param("trading_pair")
+	at, err := parseTimeParam(c.Query("at"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid time", "code": CodeInvalidRequest, "details": err.Error()})
+		return
+	}
+	if at.IsZero() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Time required", "code": CodeInvalidRequest, "details": "query parameter at is required"})
+		return
+	}
+
+	snapshot, err := h.bookSnapshots.SnapshotAt(tradingPair, at)
+	if errors.Is(err, booksnapshot.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No order book snapshot at or before the requested time", "code": CodeNotFound})
+		return
+	}
+	if err != nil {
+		h.logger.WithError(err).WithField("trading_pair", tradingPair).Error("Failed to query order book snapshot")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query order book history", "code": CodeInternal})
+		return
+	}
+
+	c.JSON(http.StatusOK, snapshot)
+}
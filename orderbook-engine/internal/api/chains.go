@@ -0,0 +1,67 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"orderbook-engine/internal/chains"
+	"orderbook-engine/pkg/crypto"
+)
+
+// SetChains 设置多链注册表
+func (h *Handler) SetChains(registry *chains.Registry) {
+	h.chains = registry
+}
+
+// orderChain 确定订单所属的链及其签名域
+// 交易对归属唯一的链，请求中指定的链ID必须与之一致，避免同一交易对跨链撮合
+func (h *Handler) orderChain(tradingPair string, requested uint64) (uint64, *crypto.OrderSigner, error) {
+	if h.chains == nil {
+		return requested, h.signer, nil
+	}
+
+	chain := h.chains.ChainForPair(tradingPair)
+	if requested != 0 && requested != chain.ChainID {
+		return 0, nil, fmt.Errorf("trading pair %s settles on chain %d, not %d", tradingPair, chain.ChainID, requested)
+	}
+	return chain.ChainID, chain.Signer, nil
+}
+
+// chainSigner 获取请求指定链的签名域，0表示默认链
+func (h *Handler) chainSigner(requested uint64) (uint64, *crypto.OrderSigner, error) {
+	if h.chains == nil {
+		return requested, h.signer, nil
+	}
+
+	chain, err := h.chains.Resolve(requested)
+	if err != nil {
+		return 0, nil, err
+	}
+	return chain.ChainID, chain.Signer, nil
+}
+
+// GetChains 获取支持的链及其交易对（前端据此选择EIP-712签名域）
+func (h *Handler) GetChains(c *gin.Context) {
+	if h.chains == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Chain registry not configured", "code": CodeFeatureDisabled})
+		return
+	}
+
+	result := make([]gin.H, 0)
+	for _, chain := range h.chains.Chains() {
+		result = append(result, gin.H{
+			"chain_id":           chain.ChainID,
+			"name":               chain.Name,
+			"contract_address":   chain.ContractAddress,
+			"settlement_address": chain.SettlementAddress,
+			"trading_pairs":      chain.TradingPairs,
+			"domain_separator":   chain.Signer.DomainSeparator().Hex(),
+			"settlement_enabled": chain.Settlement != nil,
+			"default":            chain.ChainID == h.chains.Default().ChainID,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{"chains": result})
+}
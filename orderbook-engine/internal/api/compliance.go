@@ -0,0 +1,30 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"orderbook-engine/internal/compliance"
+)
+
+// SetComplianceChecker 设置下单与提现前的地址合规筛查，为空时不做筛查
+func (h *Handler) SetComplianceChecker(checker *compliance.Checker) {
+	h.compliance = checker
+}
+
+// screenAddress 筛查下单地址，返回命中的筛查结果
+func (h *Handler) screenAddress(ctx context.Context, address string) (*compliance.Result, error) {
+	if h.compliance == nil {
+		return nil, nil
+	}
+	return h.compliance.Check(ctx, address)
+}
+
+// complianceErrorResponse 合规筛查失败的HTTP状态码、错误码与说明
+func complianceErrorResponse(err error) (int, string, string) {
+	if errors.Is(err, compliance.ErrSanctioned) {
+		return http.StatusForbidden, CodeComplianceRejected, "Address failed compliance screening"
+	}
+	return http.StatusServiceUnavailable, CodeServiceUnavailable, "Compliance screening unavailable"
+}
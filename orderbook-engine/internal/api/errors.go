@@ -0,0 +1,213 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"orderbook-engine/internal/matching"
+	"orderbook-engine/internal/types"
+)
+
+// 错误响应统一为 {"error": 可读说明, "code": 错误码, "details": 可选细节}，可附加 order_id 等字段
+// error 与 details 的措辞可能调整，客户端应按 code 判断；code 一经发布保持不变，完整列表见 GET /api/v1/errors
+
+// 通用错误码
+const (
+	CodeInvalidRequest     = "INVALID_REQUEST"     // 请求格式或参数不合法
+	CodeInvalidAddress     = "INVALID_ADDRESS"     // 地址格式不合法
+	CodeUnauthorized       = "UNAUTHORIZED"        // 缺少或无效的身份凭证
+	CodePermissionDenied   = "PERMISSION_DENIED"   // 身份有效但无权执行该操作
+	CodeNotFound           = "NOT_FOUND"           // 请求的资源不存在
+	CodeConflict           = "CONFLICT"            // 与资源当前状态冲突
+	CodeRateLimited        = "RATE_LIMITED"        // 请求频率超过限制，稍后重试
+	CodeFeatureDisabled    = "FEATURE_DISABLED"    // 功能未启用或存储后端不支持
+	CodeServiceUnavailable = "SERVICE_UNAVAILABLE" // 服务暂时不可用
+	CodeInternal           = "INTERNAL_ERROR"      // 服务端内部错误
+)
+
+// 身份与签名错误码
+const (
+	CodeInvalidSignature  = "INVALID_SIGNATURE"  // 签名校验失败
+	CodeSignatureRequired = "SIGNATURE_REQUIRED" // 需要签名
+	CodeSignatureExpired  = "SIGNATURE_EXPIRED"  // 签名超过有效期
+	CodeSignatureReused   = "SIGNATURE_REUSED"   // 钱包签名已被使用
+	CodeStaleTimestamp    = "STALE_TIMESTAMP"    // 请求时间戳超出允许偏差
+	CodeInvalidAPIKey     = "INVALID_API_KEY"    // API密钥无效、已吊销或已过期
+	CodeAPIKeyRequired    = "API_KEY_REQUIRED"   // 私有接口需要API密钥
+)
+
+// 订单错误码，与订单 status_reason 相同的取值保持一致
+const (
+	CodeOrderNotFound        = "ORDER_NOT_FOUND"
+	CodeOrderExists          = "ORDER_EXISTS"
+	CodeOrderNotCancellable  = "ORDER_NOT_CANCELLABLE"
+	CodeOrderRejected        = "ORDER_REJECTED"
+	CodeOrderExpired         = "ORDER_EXPIRED"
+	CodeInvalidSlippage      = "INVALID_SLIPPAGE"
+	CodeNonceUsed            = "NONCE_USED"
+	CodeNonceInvalidated     = "NONCE_INVALIDATED"
+	CodeChainMismatch        = "CHAIN_MISMATCH"
+	CodePairMismatch         = "PAIR_MISMATCH"
+	CodeInsufficientBalance  = types.StatusReasonInsufficientBalance
+	CodeReduceOnly           = types.StatusReasonReduceOnly
+	CodeDuplicateIntent      = types.StatusReasonDuplicateIntent
+	CodePairHalted           = types.StatusReasonPairHalted
+	CodeAuctionInProgress    = "AUCTION_IN_PROGRESS"
+	CodeNoLiquidity          = types.StatusReasonNoLiquidity
+	CodeAccountFrozen        = types.StatusReasonAccountFrozen
+	CodePairNotTradable      = types.StatusReasonPairNotTradable
+	CodeInvalidIncrement     = types.StatusReasonInvalidIncrement
+	CodePriceOutOfBand       = types.StatusReasonPriceOutOfBand
+	CodeComplianceRejected   = types.StatusReasonComplianceRejected
+	CodeMinRestingTime       = "MIN_RESTING_TIME"
+	CodeWithdrawalNotAllowed = "WITHDRAWAL_NOT_ALLOWED"
+
+	CodeInsufficientOnchainFunds = types.StatusReasonInsufficientOnchainFunds
+	CodeInsufficientAllowance    = types.StatusReasonInsufficientAllowance
+)
+
+// 系统状态错误码
+const (
+	CodeEngineDraining     = "ENGINE_DRAINING"
+	CodeNotLeader          = types.StatusReasonNotLeader
+	CodeSystemHalted       = types.StatusReasonSystemHalted
+	CodeSimulationDisabled = "SIMULATION_DISABLED"
+)
+
+// 推荐返佣错误码
+const (
+	CodeReferralExists  = "REFERRAL_EXISTS"
+	CodeInvalidReferrer = "INVALID_REFERRER"
+	CodeNothingToClaim  = "NOTHING_TO_CLAIM"
+)
+
+// ErrorCodeInfo 错误码说明
+type ErrorCodeInfo struct {
+	Code        string `json:"code"`
+	Status      int    `json:"status"`
+	Description string `json:"description"`
+}
+
+// errorCatalog 对外公布的错误码，风控拒单另以风控规则代码作为 code（见 riskCodes）
+var errorCatalog = []ErrorCodeInfo{
+	{CodeInvalidRequest, http.StatusBadRequest, "Malformed request body or invalid parameter"},
+	{CodeInvalidAddress, http.StatusBadRequest, "Address is not a valid hex address"},
+	{CodeUnauthorized, http.StatusUnauthorized, "Missing or invalid credentials"},
+	{CodePermissionDenied, http.StatusForbidden, "Caller is not allowed to perform this operation"},
+	{CodeNotFound, http.StatusNotFound, "Requested resource does not exist"},
+	{CodeConflict, http.StatusConflict, "Request conflicts with the current state of the resource"},
+	{CodeRateLimited, http.StatusTooManyRequests, "Too many requests, retry after the Retry-After delay"},
+	{CodeFeatureDisabled, http.StatusServiceUnavailable, "Feature is not enabled on this instance"},
+	{CodeServiceUnavailable, http.StatusServiceUnavailable, "Service temporarily unavailable"},
+	{CodeInternal, http.StatusInternalServerError, "Internal server error"},
+	{CodeInvalidSignature, http.StatusBadRequest, "Signature verification failed, diagnostics are included when enabled"},
+	{CodeSignatureRequired, http.StatusUnauthorized, "Operation requires a signed request"},
+	{CodeSignatureExpired, http.StatusBadRequest, "Signature is past its validity window"},
+	{CodeSignatureReused, http.StatusBadRequest, "Wallet signature was already accepted, sign the request again with a new timestamp"},
+	{CodeStaleTimestamp, http.StatusBadRequest, "Request timestamp outside the allowed window"},
+	{CodeInvalidAPIKey, http.StatusUnauthorized, "API key is invalid, revoked or expired"},
+	{CodeAPIKeyRequired, http.StatusUnauthorized, "Private endpoints require an API key"},
+	{CodeOrderNotFound, http.StatusNotFound, "Order does not exist or is no longer resting"},
+	{CodeOrderExists, http.StatusConflict, "An order with the same hash already exists"},
+	{CodeOrderNotCancellable, http.StatusBadRequest, "Order is already filled, cancelled or rejected"},
+	{CodeOrderRejected, http.StatusBadRequest, "Order rejected by the matching engine"},
+	{CodeOrderExpired, http.StatusBadRequest, "Order expired before submission"},
+	{CodeInvalidSlippage, http.StatusBadRequest, "Invalid slippage protection parameters"},
+	{CodeNonceUsed, http.StatusBadRequest, "Order nonce already used"},
+	{CodeNonceInvalidated, http.StatusBadRequest, "Order nonce below the account's minimum valid nonce"},
+	{CodeChainMismatch, http.StatusBadRequest, "Order chain does not match the trading pair's chain"},
+	{CodePairMismatch, http.StatusBadRequest, "Trading pair does not match the order's tokens or their canonical base/quote order"},
+	{CodeInsufficientBalance, http.StatusBadRequest, "Available balance cannot cover the order"},
+	{CodeReduceOnly, http.StatusBadRequest, "Reduce-only order has no position to reduce"},
+	{CodeDuplicateIntent, http.StatusConflict, "Same order intent already submitted through another intake"},
+	{CodePairHalted, http.StatusServiceUnavailable, "Trading pair is halted"},
+	{CodeAuctionInProgress, http.StatusBadRequest, "Market orders are not accepted during a call auction"},
+	{CodeNoLiquidity, http.StatusBadRequest, "Not enough opposite-side liquidity for a market order"},
+	{CodeAccountFrozen, http.StatusForbidden, "Account frozen by an administrator"},
+	{CodePairNotTradable, http.StatusBadRequest, "Trading pair has not opened yet or is delisted"},
+	{CodeInvalidIncrement, http.StatusBadRequest, "Price or amount is not a multiple of the pair's tick or lot size"},
+	{CodePriceOutOfBand, http.StatusBadRequest, "Limit price too far from the last trade price, rejected by the engine price band"},
+	{CodeComplianceRejected, http.StatusForbidden, "Address failed sanctions or compliance screening"},
+	{CodeMinRestingTime, http.StatusBadRequest, "Order has not rested for the pair's minimum time, retry the cancel later"},
+	{CodeWithdrawalNotAllowed, http.StatusBadRequest, "Withdrawal rejected, see details and quote"},
+	{CodeInsufficientOnchainFunds, http.StatusBadRequest, "Deposited or wallet balance on-chain cannot settle the order"},
+	{CodeInsufficientAllowance, http.StatusBadRequest, "ERC-20 allowance for the settlement contract cannot settle the order"},
+	{CodeEngineDraining, http.StatusServiceUnavailable, "Instance is draining before shutdown"},
+	{CodeNotLeader, http.StatusServiceUnavailable, "Instance is a standby, send writes to the leader"},
+	{CodeSystemHalted, http.StatusServiceUnavailable, "Exchange is halted, only reads and cancellations are accepted"},
+	{CodeSimulationDisabled, http.StatusForbidden, "Endpoint only available in simulation mode"},
+	{CodeReferralExists, http.StatusConflict, "Referrer already registered"},
+	{CodeInvalidReferrer, http.StatusBadRequest, "Referrer cannot be used"},
+	{CodeNothingToClaim, http.StatusBadRequest, "No rebate available to claim"},
+}
+
+// riskCodes 风控拒单代码（HTTP 400，频率限制类为 429），余额不足与订单过期使用上文的通用订单错误码
+var riskCodes = []ErrorCodeInfo{
+	{"BLACKLISTED", http.StatusBadRequest, "Address or token is blacklisted"},
+	{"ORDER_TOO_SMALL", http.StatusBadRequest, "Order amount below the minimum"},
+	{"ORDER_TOO_LARGE", http.StatusBadRequest, "Order amount above the maximum"},
+	{"PRICE_DEVIATION_TOO_LARGE", http.StatusBadRequest, "Price too far from the reference price"},
+	{"ORDER_RATE_LIMIT_EXCEEDED", http.StatusTooManyRequests, "Too many orders in the rate limit window"},
+	{"CANCEL_RATE_LIMIT_EXCEEDED", http.StatusTooManyRequests, "Too many cancellations in the rate limit window"},
+	{"TOO_MANY_ORDERS", http.StatusBadRequest, "Too many open orders"},
+	{"EXPOSURE_LIMIT_EXCEEDED", http.StatusBadRequest, "Position exposure would exceed the account limit"},
+	{"ORDER_TOO_OLD", http.StatusBadRequest, "Order timestamp too old"},
+	{"CANCEL_RATIO_TOO_HIGH", http.StatusBadRequest, "Cancel-to-order ratio too high"},
+}
+
+// GetErrorCodes 获取错误码列表
+func (h *Handler) GetErrorCodes(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"codes": errorCatalog, "risk_codes": riskCodes})
+}
+
+// NoRoute 未匹配路由同样返回统一错误响应
+func (h *Handler) NoRoute(c *gin.Context) {
+	c.JSON(http.StatusNotFound, gin.H{"error": "Endpoint not found", "code": CodeNotFound, "details": c.Request.Method + " " + c.Request.URL.Path})
+}
+
+// riskStatus 风控拒单的HTTP状态码
+func riskStatus(code string) int {
+	if code == CodeAccountFrozen {
+		return http.StatusForbidden
+	}
+	for _, info := range riskCodes {
+		if info.Code == code {
+			return info.Status
+		}
+	}
+	return http.StatusBadRequest
+}
+
+// engineError 撮合引擎拒单错误与响应的对应关系
+type engineError struct {
+	err     error
+	status  int
+	code    string
+	message string
+}
+
+var engineErrors = []engineError{
+	{matching.ErrPairHalted, http.StatusServiceUnavailable, CodePairHalted, "Trading pair halted"},
+	{matching.ErrDraining, http.StatusServiceUnavailable, CodeEngineDraining, "Engine is draining"},
+	{matching.ErrNotLeader, http.StatusServiceUnavailable, CodeNotLeader, "Instance is not the leader"},
+	{matching.ErrSystemHalted, http.StatusServiceUnavailable, CodeSystemHalted, "Exchange is halted"},
+	{matching.ErrAccountFrozen, http.StatusForbidden, CodeAccountFrozen, "Account frozen"},
+	{matching.ErrPairNotTradable, http.StatusBadRequest, CodePairNotTradable, "Trading pair not open for orders"},
+	{matching.ErrInvalidIncrement, http.StatusBadRequest, CodeInvalidIncrement, "Price or amount violates tick or lot size"},
+	{matching.ErrPriceOutOfBand, http.StatusBadRequest, CodePriceOutOfBand, "Order price outside price band"},
+	{matching.ErrAuctionInProgress, http.StatusBadRequest, CodeAuctionInProgress, "Market orders not accepted during call auction"},
+	{matching.ErrNoLiquidity, http.StatusBadRequest, CodeNoLiquidity, "Insufficient liquidity"},
+	{matching.ErrSignatureExpired, http.StatusBadRequest, CodeSignatureExpired, "Order signature expired"},
+}
+
+// engineErrorResponse 将撮合引擎拒单错误映射为HTTP状态码与错误响应
+func engineErrorResponse(err error) (int, gin.H) {
+	for _, mapping := range engineErrors {
+		if errors.Is(err, mapping.err) {
+			return mapping.status, gin.H{"error": mapping.message, "code": mapping.code, "details": err.Error()}
+		}
+	}
+	return http.StatusBadRequest, gin.H{"error": "Order rejected", "code": CodeOrderRejected, "details": err.Error()}
+}
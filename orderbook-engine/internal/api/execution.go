@@ -0,0 +1,37 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"orderbook-engine/internal/execution"
+)
+
+// GetOrderExecutions 获取订单的成交回报（每笔成交一条，含累计成交数量、成交均价与剩余数量）
+func (h *Handler) GetOrderExecutions(c *gin.Context) {
+	orderID, err := uuid.Parse(c.Param("order_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid order ID", "code": CodeInvalidRequest})
+		return
+	}
+
+	order, err := h.storage.GetOrder(orderID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Order not found", "code": CodeOrderNotFound})
+		return
+	}
+	if !h.authorizeUser(c, order.UserAddress) {
+		return
+	}
+
+	fills, err := h.storage.GetOrderFills(orderID)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to get order fills")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get order fills", "code": CodeInternal})
+		return
+	}
+	reports := execution.Build(order, fills)
+	c.JSON(http.StatusOK, gin.H{"order_id": order.ID, "executions": reports, "total": len(reports)})
+}
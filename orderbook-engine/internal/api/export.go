@@ -0,0 +1,232 @@
+package api
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"orderbook-engine/internal/history"
+	"orderbook-engine/internal/types"
+)
+
+// exportChunkSize 导出时每次查询的记录数
+const exportChunkSize = 500
+
+// exportColumns 导出CSV列，订单和成交共用一张表，record_type 区分记录类型
+var exportColumns = []string{
+	"record_type", "id", "created_at", "trading_pair", "side", "order_type", "price", "amount",
+	"filled_amount", "status", "role", "order_id", "fee", "tx_hash", "settlement_status",
+}
+
+// exportRecord 导出记录
+type exportRecord struct {
+	RecordType       string `json:"record_type"` // order 或 fill
+	ID               string `json:"id"`
+	CreatedAt        string `json:"created_at"`
+	TradingPair      string `json:"trading_pair"`
+	Side             string `json:"side"` // 用户订单方向
+	OrderType        string `json:"order_type,omitempty"`
+	Price            string `json:"price"`
+	Amount           string `json:"amount"`
+	FilledAmount     string `json:"filled_amount,omitempty"`
+	Status           string `json:"status,omitempty"`
+	Role             string `json:"role,omitempty"`
+	OrderID          string `json:"order_id,omitempty"` // 成交对应的用户订单
+	Fee              string `json:"fee,omitempty"`
+	TxHash           string `json:"tx_hash,omitempty"`
+	SettlementStatus string `json:"settlement_status,omitempty"`
+}
+
+func (r *exportRecord) row() []string {
+	return []string{
+		r.RecordType, r.ID, r.CreatedAt, r.TradingPair, r.Side, r.OrderType, r.Price, r.Amount,
+		r.FilledAmount, r.Status, r.Role, r.OrderID, r.Fee, r.TxHash, r.SettlementStatus,
+	}
+}
+
+// exportWriter 按格式写出记录并逐块刷新到客户端
+type exportWriter interface {
+	Write(record *exportRecord) error
+	Flush() error
+}
+
+type csvExportWriter struct {
+	w *csv.Writer
+}
+
+func (e *csvExportWriter) Write(record *exportRecord) error {
+	return e.w.Write(record.row())
+}
+
+func (e *csvExportWriter) Flush() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+type jsonLinesExportWriter struct {
+	enc *json.Encoder
+}
+
+func (e *jsonLinesExportWriter) Write(record *exportRecord) error {
+	return e.enc.Encode(record)
+}
+
+func (e *jsonLinesExportWriter) Flush() error {
+	return nil
+}
+
+// ExportAccountHistory 导出账户订单和成交历史
+// GET /api/v1/account/:address/export?format=csv|jsonl&from=...&to=...
+// 分块查询并以分块传输编码流式写出，不在内存中保留完整历史
+func (h *Handler) ExportAccountHistory(c *gin.Context) {
+	if h.history == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "History export not supported by storage", "code": CodeFeatureDisabled})
+		return
+	}
+
+	address := c.Param("address")
+	format := strings.ToLower(c.DefaultQuery("format", "csv"))
+	if format == "json" {
+		format = "jsonl"
+	}
+	if format != "csv" && format != "jsonl" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid format", "code": CodeInvalidRequest, "details": "expected csv or jsonl"})
+		return
+	}
+
+	var from, to time.Time
+	var after *history.Cursor
+	if !bindHistoryPage(c, c.Query("from"), c.Query("to"), &from, &to, &after) {
+		return
+	}
+
+	filename := fmt.Sprintf("%s-history.%s", strings.ToLower(address), format)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	var writer exportWriter
+	if format == "csv" {
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+		csvWriter := csv.NewWriter(c.Writer)
+		csvWriter.Write(exportColumns)
+		writer = &csvExportWriter{w: csvWriter}
+	} else {
+		c.Header("Content-Type", "application/x-ndjson")
+		writer = &jsonLinesExportWriter{enc: json.NewEncoder(c.Writer)}
+	}
+	c.Status(http.StatusOK)
+
+	logger := h.logger.WithFields(logrus.Fields{"user": address, "format": format})
+	orders, err := h.exportOrders(c.Writer, writer, history.OrderQuery{UserAddress: address, From: from, To: to, Limit: exportChunkSize})
+	if err == nil {
+		var fills int
+		fills, err = h.exportFills(c.Writer, writer, history.FillQuery{UserAddress: address, From: from, To: to, Limit: exportChunkSize})
+		logger = logger.WithFields(logrus.Fields{"orders": orders, "fills": fills})
+	}
+	if err != nil {
+		// 响应头已发送，只能中断输出
+		logger.WithError(err).Error("Account history export aborted")
+		return
+	}
+	logger.Info("Account history exported")
+}
+
+// exportOrders 分页导出订单
+func (h *Handler) exportOrders(flusher http.Flusher, writer exportWriter, query history.OrderQuery) (int, error) {
+	count := 0
+	for {
+		orders, err := h.history.QueryOrders(query)
+		if err != nil {
+			return count, err
+		}
+		for _, order := range orders {
+			if err := writer.Write(orderExportRecord(order)); err != nil {
+				return count, err
+			}
+		}
+		count += len(orders)
+		if err := flushExport(flusher, writer); err != nil {
+			return count, err
+		}
+
+		if len(orders) < query.Limit {
+			return count, nil
+		}
+		last := orders[len(orders)-1]
+		query.After = &history.Cursor{CreatedAt: last.CreatedAt, ID: last.ID}
+	}
+}
+
+// exportFills 分页导出成交（用户视角）
+func (h *Handler) exportFills(flusher http.Flusher, writer exportWriter, query history.FillQuery) (int, error) {
+	count := 0
+	for {
+		fills, err := h.history.QueryFills(query)
+		if err != nil {
+			return count, err
+		}
+		for i, trade := range h.toUserTrades(fills, query.UserAddress) {
+			if err := writer.Write(fillExportRecord(fills[i], trade)); err != nil {
+				return count, err
+			}
+		}
+		count += len(fills)
+		if err := flushExport(flusher, writer); err != nil {
+			return count, err
+		}
+
+		if len(fills) < query.Limit {
+			return count, nil
+		}
+		last := fills[len(fills)-1]
+		query.After = &history.Cursor{CreatedAt: last.CreatedAt, ID: last.ID}
+	}
+}
+
+func flushExport(flusher http.Flusher, writer exportWriter) error {
+	if err := writer.Flush(); err != nil {
+		return err
+	}
+	flusher.Flush()
+	return nil
+}
+
+func orderExportRecord(order *types.Order) *exportRecord {
+	return &exportRecord{
+		RecordType:   "order",
+		ID:           order.ID.String(),
+		CreatedAt:    order.CreatedAt.UTC().Format(time.RFC3339Nano),
+		TradingPair:  order.TradingPair,
+		Side:         string(order.Side),
+		OrderType:    string(order.Type),
+		Price:        order.Price.String(),
+		Amount:       order.Amount.String(),
+		FilledAmount: order.FilledAmount.String(),
+		Status:       string(order.Status),
+	}
+}
+
+func fillExportRecord(fill *types.Fill, trade userTrade) *exportRecord {
+	orderID := fill.MakerOrderID
+	if trade.Role == history.RoleTaker {
+		orderID = fill.TakerOrderID
+	}
+	return &exportRecord{
+		RecordType:       "fill",
+		ID:               fill.ID.String(),
+		CreatedAt:        fill.CreatedAt.UTC().Format(time.RFC3339Nano),
+		TradingPair:      fill.TradingPair,
+		Side:             string(trade.UserSide),
+		Price:            fill.Price.String(),
+		Amount:           fill.Amount.String(),
+		Role:             string(trade.Role),
+		OrderID:          orderID.String(),
+		Fee:              "0", // 撮合暂不收取交易手续费
+		TxHash:           fill.TxHash,
+		SettlementStatus: string(fill.SettlementStatus),
+	}
+}
@@ -0,0 +1,111 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"orderbook-engine/internal/audit"
+	"orderbook-engine/internal/types"
+)
+
+// FreezeAccount 冻结账户（管理接口，事故响应）
+// 立即拒绝该账户在所有入口的新订单并撤销全部挂单，freeze_withdrawals 为 true 时同时冻结提现
+func (h *Handler) FreezeAccount(c *gin.Context) {
+	if h.risk == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Risk control disabled", "code": CodeFeatureDisabled})
+		return
+	}
+	address := c.Param("address")
+	if !common.IsHexAddress(address) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid address", "code": CodeInvalidAddress})
+		return
+	}
+
+	var req struct {
+		Reason            string `json:"reason" binding:"required"`
+		FreezeWithdrawals bool   `json:"freeze_withdrawals"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid freeze request", "code": CodeInvalidRequest, "details": err.Error()})
+		return
+	}
+
+	// 先冻结再撤单，撤单期间到达的新订单已被拒绝
+	freeze := h.risk.FreezeAccount(address, req.Reason, req.FreezeWithdrawals)
+	cancelled := h.cancelFrozenOrders(address)
+
+	h.logger.WithFields(logrus.Fields{
+		"user_address":       address,
+		"reason":             req.Reason,
+		"freeze_withdrawals": req.FreezeWithdrawals,
+		"cancelled":          cancelled,
+		"client_ip":          c.ClientIP(),
+	}).Warn("Admin froze account")
+	h.recordAudit(&audit.Entry{
+		ActorType: audit.ActorAdmin,
+		Actor:     c.ClientIP(),
+		Action:    audit.ActionAccountFreeze,
+		Resource:  address,
+		Details: map[string]interface{}{
+			"reason":             req.Reason,
+			"freeze_withdrawals": req.FreezeWithdrawals,
+			"cancelled_orders":   cancelled,
+		},
+	})
+
+	c.JSON(http.StatusOK, gin.H{"freeze": freeze, "cancelled_orders": cancelled})
+}
+
+// UnfreezeAccount 解除账户冻结（管理接口），已撤销的订单不会恢复
+func (h *Handler) UnfreezeAccount(c *gin.Context) {
+	if h.risk == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Risk control disabled", "code": CodeFeatureDisabled})
+		return
+	}
+	address := c.Param("address")
+	if !h.risk.UnfreezeAccount(address) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Account not frozen", "code": CodeNotFound})
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"user_address": address,
+		"client_ip":    c.ClientIP(),
+	}).Warn("Admin unfroze account")
+	h.recordAudit(&audit.Entry{
+		ActorType: audit.ActorAdmin,
+		Actor:     c.ClientIP(),
+		Action:    audit.ActionAccountUnfreeze,
+		Resource:  address,
+	})
+
+	c.JSON(http.StatusOK, gin.H{"user_address": address, "frozen": false})
+}
+
+// GetFrozenAccounts 获取全部冻结账户（管理接口）
+func (h *Handler) GetFrozenAccounts(c *gin.Context) {
+	if h.risk == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Risk control disabled", "code": CodeFeatureDisabled})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"accounts": h.risk.GetAccountFreezes()})
+}
+
+// cancelFrozenOrders 撤销账户在订单簿中的全部挂单并回写存储，返回撤销数量
+func (h *Handler) cancelFrozenOrders(address string) int {
+	cancelled := 0
+	for _, order := range h.engine.GetUserOrders(address) {
+		snapshot, ok := h.engine.CancelOrderWithReason(order.ID, order.TradingPair, types.StatusReasonAccountFrozen)
+		if !ok {
+			continue
+		}
+		if err := h.storage.UpdateOrder(snapshot); err != nil {
+			h.logger.WithError(err).WithField("order_id", order.ID).Error("Failed to update cancelled order")
+		}
+		cancelled++
+	}
+	return cancelled
+}
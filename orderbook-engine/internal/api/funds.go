@@ -0,0 +1,76 @@
+package api
+
+import (
+	"errors"
+	"math"
+
+	"github.com/shopspring/decimal"
+
+	"orderbook-engine/internal/types"
+)
+
+// errNoLockPrice 卖盘为空且未设置保护价，无法确定市价买单的锁定金额
+var errNoLockPrice = errors.New("cannot estimate market buy price without asks, set protection_price")
+
+// SetEnforceBalances 设置下单是否需要锁定内部余额
+func (h *Handler) SetEnforceBalances(enforce bool) {
+	h.enforceBalances = enforce
+}
+
+// lockOrderFunds 下单时锁定资金，未启用余额约束时不做处理
+// 市价买单以锁定价格作为保护价，撮合不会以高于锁定价格的价格成交，成交金额不超过锁定资金
+func (h *Handler) lockOrderFunds(order *types.Order) error {
+	if !h.enforceBalances || h.balances == nil {
+		return nil
+	}
+	price := h.lockPrice(order)
+	if order.Side == types.OrderSideBuy && order.Type == types.OrderTypeMarket {
+		if !price.IsPositive() {
+			return errNoLockPrice
+		}
+		order.ProtectionPrice = price
+	}
+	return h.balances.LockOrder(order, price)
+}
+
+// releaseOrderFunds 订单未被接受时释放已锁定的资金
+func (h *Handler) releaseOrderFunds(order *types.Order) {
+	if h.enforceBalances && h.balances != nil {
+		h.balances.ReleaseOrder(order.ID)
+	}
+}
+
+// availableBalances 用户各代币可用余额（供风控资金检查），未配置余额管理器时返回 nil
+func (h *Handler) availableBalances(userAddress string) map[string]decimal.Decimal {
+	if h.balances == nil {
+		return nil
+	}
+	result := make(map[string]decimal.Decimal)
+	for token, info := range h.balances.GetUserBalances(userAddress) {
+		result[token] = info.Available
+	}
+	return result
+}
+
+// lockPrice 买单锁定价格
+// 市价买单没有价格，按当前卖盘估算吃满订单数量的最差成交价；卖盘不足时剩余部分不会成交；
+// 用户设置了更低的保护价，或卖盘为空时使用保护价
+func (h *Handler) lockPrice(order *types.Order) decimal.Decimal {
+	if order.Side != types.OrderSideBuy || order.Type != types.OrderTypeMarket {
+		return order.Price
+	}
+
+	price := decimal.Zero
+	remaining := order.Amount
+	for _, level := range h.engine.GetOrderBook(order.TradingPair, math.MaxInt).Asks {
+		if !remaining.IsPositive() {
+			break
+		}
+		price = level.Price
+		remaining = remaining.Sub(level.Amount)
+	}
+	if order.ProtectionPrice.IsPositive() && (price.IsZero() || order.ProtectionPrice.LessThan(price)) {
+		price = order.ProtectionPrice
+	}
+	return price
+}
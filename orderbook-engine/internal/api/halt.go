@@ -0,0 +1,120 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"orderbook-engine/internal/audit"
+	"orderbook-engine/internal/halt"
+	"orderbook-engine/internal/types"
+)
+
+// readOnlyExempt 紧急停机期间仍放行的写接口：撤单与吊销会话只降低风险，签名摘要只做计算
+var readOnlyExempt = map[string]bool{
+	http.MethodDelete + " /api/v1/orders/:order_id":             true,
+	http.MethodPost + " /api/v1/orders/cancel":                  true,
+	http.MethodPost + " /api/v1/orders/cancel-below-nonce":      true,
+	http.MethodDelete + " /api/v1/account/sessions/:session_id": true,
+	http.MethodPost + " /api/v1/signing-info/digest":            true,
+}
+
+// SetHaltSwitch 设置紧急停机开关
+func (h *Handler) SetHaltSwitch(haltSwitch *halt.Switch) {
+	h.halt = haltSwitch
+}
+
+// systemStatus 当前系统运行状态：紧急停机优先于过载降级
+func (h *Handler) systemStatus() types.SystemStatus {
+	switch {
+	case h.halt != nil && h.halt.Halted():
+		return types.SystemStatusHalted
+	case h.shedder != nil && h.shedder.IsShedding():
+		return types.SystemStatusShedding
+	default:
+		return types.SystemStatusNormal
+	}
+}
+
+// ReadOnlyMiddleware 紧急停机期间只放行查询与撤单，其余写接口返回 503
+func (h *Handler) ReadOnlyMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if h.halt == nil || c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead ||
+			readOnlyExempt[c.Request.Method+" "+c.FullPath()] || !h.halt.Halted() {
+			c.Next()
+			return
+		}
+		c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{
+			"error":   "Exchange is halted, read-only mode",
+			"details": h.halt.GetStatus().Reason,
+			"code":    CodeSystemHalted,
+		})
+	}
+}
+
+// GetSystemHalt 获取紧急停机状态（管理接口）
+func (h *Handler) GetSystemHalt(c *gin.Context) {
+	if h.halt == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Emergency halt disabled", "code": CodeFeatureDisabled})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"system_status": h.systemStatus(), "halt": h.halt.GetStatus()})
+}
+
+// HaltSystem 全局紧急停机（管理接口）
+// 暂停全部撮合与链上结算，行情、订单查询与撤单照常提供
+func (h *Handler) HaltSystem(c *gin.Context) {
+	if h.halt == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Emergency halt disabled", "code": CodeFeatureDisabled})
+		return
+	}
+	var req struct {
+		Reason string `json:"reason" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid halt request", "code": CodeInvalidRequest, "details": err.Error()})
+		return
+	}
+
+	if !h.halt.Halt(req.Reason, c.ClientIP()) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Exchange already halted", "code": CodeConflict, "halt": h.halt.GetStatus()})
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"reason":    req.Reason,
+		"client_ip": c.ClientIP(),
+	}).Warn("Admin halted exchange")
+	h.recordAudit(&audit.Entry{
+		ActorType: audit.ActorAdmin,
+		Actor:     c.ClientIP(),
+		Action:    audit.ActionSystemHalt,
+		Resource:  "system",
+		Details:   map[string]interface{}{"reason": req.Reason},
+	})
+
+	c.JSON(http.StatusOK, gin.H{"system_status": h.systemStatus(), "halt": h.halt.GetStatus()})
+}
+
+// ResumeSystem 解除紧急停机（管理接口），暂停期间排队的结算随后提交
+func (h *Handler) ResumeSystem(c *gin.Context) {
+	if h.halt == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Emergency halt disabled", "code": CodeFeatureDisabled})
+		return
+	}
+	if !h.halt.Resume(c.ClientIP()) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Exchange not halted", "code": CodeConflict})
+		return
+	}
+
+	h.logger.WithField("client_ip", c.ClientIP()).Warn("Admin resumed exchange")
+	h.recordAudit(&audit.Entry{
+		ActorType: audit.ActorAdmin,
+		Actor:     c.ClientIP(),
+		Action:    audit.ActionSystemResume,
+		Resource:  "system",
+	})
+
+	c.JSON(http.StatusOK, gin.H{"system_status": h.systemStatus(), "halt": h.halt.GetStatus()})
+}
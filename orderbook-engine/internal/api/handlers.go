@@ -1,26 +1,124 @@
 package api
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 	"github.com/sirupsen/logrus"
 
+	"orderbook-engine/internal/analytics"
+	"orderbook-engine/internal/audit"
+	"orderbook-engine/internal/blockchain"
+	"orderbook-engine/internal/booksnapshot"
+	"orderbook-engine/internal/chains"
+	"orderbook-engine/internal/circuitbreaker"
+	"orderbook-engine/internal/compliance"
+	"orderbook-engine/internal/drain"
+	"orderbook-engine/internal/halt"
+	"orderbook-engine/internal/health"
+	"orderbook-engine/internal/history"
+	"orderbook-engine/internal/importer"
+	"orderbook-engine/internal/intake"
+	"orderbook-engine/internal/integrity"
+	"orderbook-engine/internal/leader"
+	"orderbook-engine/internal/lifecycle"
+	"orderbook-engine/internal/listing"
+	"orderbook-engine/internal/loadshed"
+	"orderbook-engine/internal/marketmaker"
 	"orderbook-engine/internal/matching"
+	"orderbook-engine/internal/merkle"
+	"orderbook-engine/internal/nonce"
+	"orderbook-engine/internal/obligations"
+	"orderbook-engine/internal/orderflow"
+	"orderbook-engine/internal/pairs"
+	"orderbook-engine/internal/positions"
+	"orderbook-engine/internal/preflight"
+	"orderbook-engine/internal/reduceonly"
+	"orderbook-engine/internal/referral"
+	"orderbook-engine/internal/rewards"
+	"orderbook-engine/internal/riskcontrol"
+	"orderbook-engine/internal/session"
+	"orderbook-engine/internal/settlement"
+	"orderbook-engine/internal/stats"
 	"orderbook-engine/internal/storage"
+	"orderbook-engine/internal/surveillance"
+	"orderbook-engine/internal/tokens"
 	"orderbook-engine/internal/types"
+	"orderbook-engine/internal/wallet"
+	"orderbook-engine/internal/websocket"
 	"orderbook-engine/pkg/crypto"
 )
 
 // Handler API处理器
 type Handler struct {
-	engine     *matching.MatchingEngine
-	storage    storage.Storage
-	signer     *crypto.OrderSigner
-	logger     *logrus.Logger
+	engine             *matching.MatchingEngine
+	storage            storage.Storage
+	signer             *crypto.OrderSigner
+	logger             *logrus.Logger
+	risk               *riskcontrol.RiskController // 可选，为空时不做风控检查
+	balances           *wallet.BalanceManager
+	breaker            *circuitbreaker.CircuitBreaker
+	shedder            *loadshed.Shedder
+	sessions           *session.Registry
+	detector           *surveillance.Detector
+	topicACL           *websocket.TopicACL
+	wsHub              *websocket.Hub
+	quoter             *marketmaker.Quoter
+	nonces             *nonce.Tracker
+	stats              *stats.Aggregator
+	settlement         *settlement.Pipeline
+	settlementManagers []*blockchain.SettlementManager
+	merkleProofs       merkle.Store      // 可选，为空时不提供默克尔批次证明查询
+	settlerSigner      SettlerSignerFunc // 可选，为空时不支持结算密钥轮换
+	keyRotationTimeout time.Duration
+	importer           *importer.Importer
+	drainer            *drain.Drainer
+	elector            *leader.Elector
+	audit              *audit.Recorder      // 可选，为空时不记录审计日志
+	chains             *chains.Registry     // 可选，为空时使用单链签名器
+	history            history.Store        // 可选，为空时订单和成交列表使用偏移分页
+	bookSnapshots      booksnapshot.Store   // 可选，为空时不提供历史订单簿查询
+	reduceOnly         *reduceonly.Guard    // 可选，为空时拒绝只减仓订单
+	referrals          *referral.Program    // 可选，为空时不提供推荐返佣
+	rewards            *rewards.Engine      // 可选，为空时不提供流动性挖矿积分
+	obligations        *obligations.Monitor // 可选，为空时不监控做市商报价义务
+	intake             *intake.Deduper      // 可选，为空时不做 REST 与链上入口的订单去重
+	analytics          analytics.Sink       // 可选，为空时不提供成交分析聚合查询
+	archiver           *analytics.Archiver
+	coldArchive        *lifecycle.Archiver     // 可选，为空时不归档冷数据
+	integrity          *integrity.Checker      // 可选，为空时不提供一致性检查
+	halt               *halt.Switch            // 可选，为空时不支持紧急停机
+	health             *health.Checker         // 可选，为空时健康检查不探测依赖
+	readiness          *health.Readiness       // 可选，为空时启动即就绪
+	preflight          *preflight.Checker      // 可选，为空时不做链上资金预检
+	compliance         *compliance.Checker     // 可选，为空时不做地址合规筛查
+	positions          *positions.Tracker      // 可选，为空时不提供持仓接口
+	pnlSnapshots       positions.SnapshotStore // 可选，为空时不提供盈亏历史接口
+	orderFlow          *orderflow.Tracker      // 可选，为空时不提供订单流统计接口
+	listings           *listing.Registry       // 可选，为空时不支持交易对上下架
+	pairNames          *pairs.Canonicalizer    // 可选，为空时不校验交易对名称与订单代币
+	tokenRegistry      *tokens.Registry
+	openAPI            openAPIState // 接口文档，按已注册路由生成
+
+	requireSignedCancel bool          // 为true时禁用仅凭 user_address 参数的撤单接口
+	importMaxBytes      int64         // 历史数据导入请求体上限
+	enforceBalances     bool          // 为true时下单需锁定内部余额
+	apiKeyRequired      bool          // 为true时私有接口必须携带签名的API密钥
+	apiKeyWindow        time.Duration // API请求签名时间戳允许的偏差
+	walletSignatures    *usedSignatures // 已接受的钱包签名，防止会话管理请求重放
+
+	verifyOrderSignatures bool // 为true时下单校验 EIP-712 订单签名
+	signatureDiagnostics  bool // 为true时签名校验失败返回摘要与恢复地址等诊断信息
+
+	simulation        bool            // 模拟盘模式，允许通过接口注入测试余额
+	simulationMaxSeed decimal.Decimal // 单次注入测试余额的上限，0表示不限
 }
 
 // NewHandler 创建API处理器
@@ -30,70 +128,150 @@ func NewHandler(engine *matching.MatchingEngine, storage storage.Storage, signer
 		storage: storage,
 		signer:  signer,
 		logger:  logger,
+
+		walletSignatures: newUsedSignatures(),
 	}
 }
 
+// SetRiskController 设置风控控制器
+func (h *Handler) SetRiskController(risk *riskcontrol.RiskController) {
+	h.risk = risk
+}
+
+// SetBalanceManager 设置余额管理器
+func (h *Handler) SetBalanceManager(balances *wallet.BalanceManager) {
+	h.balances = balances
+}
+
+// SetCircuitBreaker 设置价格熔断器
+func (h *Handler) SetCircuitBreaker(breaker *circuitbreaker.CircuitBreaker) {
+	h.breaker = breaker
+}
+
+// SetLoadShedder 设置过载降级控制器
+func (h *Handler) SetLoadShedder(shedder *loadshed.Shedder) {
+	h.shedder = shedder
+}
+
+// SetSurveillance 设置成交异常检测器
+func (h *Handler) SetSurveillance(detector *surveillance.Detector) {
+	h.detector = detector
+}
+
+// SetTopicACL 设置WebSocket主题访问控制
+func (h *Handler) SetTopicACL(acl *websocket.TopicACL) {
+	h.topicACL = acl
+}
+
+// SetWebSocketHub 设置WebSocket Hub，用于查询连接状态
+func (h *Handler) SetWebSocketHub(hub *websocket.Hub) {
+	h.wsHub = hub
+}
+
+// SetQuoter 设置做市机器人
+func (h *Handler) SetQuoter(quoter *marketmaker.Quoter) {
+	h.quoter = quoter
+}
+
+// SetStatsAggregator 设置成交统计聚合器
+func (h *Handler) SetStatsAggregator(aggregator *stats.Aggregator) {
+	h.stats = aggregator
+}
+
+// SetRequireSignedCancel 设置是否强制使用签名撤单
+func (h *Handler) SetRequireSignedCancel(require bool) {
+	h.requireSignedCancel = require
+}
+
+// SetVerifyOrderSignatures 设置下单时是否校验订单签名
+func (h *Handler) SetVerifyOrderSignatures(verify bool) {
+	h.verifyOrderSignatures = verify
+}
+
 // PlaceOrder 下单接口
 func (h *Handler) PlaceOrder(c *gin.Context) {
 	var signedOrder types.SignedOrder
 	if err := c.ShouldBindJSON(&signedOrder); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid order format", "details": err.Error()})
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid order format", "code": CodeInvalidRequest, "details": err.Error()})
+		return
+	}
+	if !h.authorizeUser(c, signedOrder.UserAddress) {
 		return
 	}
 
-	// 暂时跳过签名验证以测试撮合和结算流程
-	// TODO: 修复EIP-712签名验证问题
-	h.logger.WithFields(logrus.Fields{
-		"user_address": signedOrder.UserAddress,
-		"trading_pair": signedOrder.TradingPair,
-		"side": signedOrder.Side,
-		"price": signedOrder.Price.String(),
-		"amount": signedOrder.Amount.String(),
-		"signature": signedOrder.Signature,
-	}).Warn("⚠️  Signature verification temporarily disabled for testing")
-	
-	// 注释掉签名验证逻辑
-	/*
-	valid, err := h.signer.VerifyOrderSignature(&signedOrder)
+	// 排空停机期间不再接受新订单，在校验和锁定资金前直接拒绝
+	if h.engine.Draining() {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Engine is draining", "code": CodeEngineDraining})
+		return
+	}
+
+	// 过载降级期间按用户限流新订单（撤单不受影响）
+	if h.shedder != nil {
+		if err := h.shedder.AllowNewOrder(signedOrder.UserAddress); err != nil {
+			c.Header("Retry-After", "1")
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": "Order intake throttled", "code": CodeRateLimited, "details": err.Error()})
+			return
+		}
+	}
+
+	// 交易对名称规范写法（大写符号，地址写法转为符号），再确定订单所属的链（决定签名域和结算合约）
+	if h.pairNames != nil {
+		signedOrder.TradingPair = h.pairNames.Normalize(signedOrder.ChainID, signedOrder.TradingPair)
+	}
+	chainID, signer, err := h.orderChain(signedOrder.TradingPair, signedOrder.ChainID)
 	if err != nil {
-		h.logger.WithError(err).WithFields(logrus.Fields{
-			"user_address": signedOrder.UserAddress,
-			"signature": signedOrder.Signature,
-		}).Error("Failed to verify signature")
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Signature verification failed", "details": err.Error()})
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid chain", "code": CodeChainMismatch, "details": err.Error()})
 		return
 	}
-	if !valid {
-		h.logger.WithFields(logrus.Fields{
-			"user_address": signedOrder.UserAddress,
-			"signature": signedOrder.Signature,
-		}).Error("Invalid signature - signature verification returned false")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid signature"})
+	signedOrder.ChainID = chainID
+
+	// 交易对名称须与订单代币的规范交易对一致，避免与链上订单分裂成不同的订单簿
+	if signedOrder.TradingPair, err = h.checkOrderPair(chainID, signedOrder.TradingPair, signedOrder.BaseToken, signedOrder.QuoteToken); err != nil {
+		c.JSON(http.StatusBadRequest, pairMismatchResponse(err))
 		return
 	}
-	*/
 
-	// 检查订单是否过期
-	if signedOrder.ExpiresAt != nil && signedOrder.ExpiresAt.Before(time.Now()) {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Order expired"})
+	// 签名验证默认关闭以便测试撮合和结算流程，由 auth.verify_order_signatures 开启
+	if !h.verifyOrderSignatures {
+		h.logger.WithFields(logrus.Fields{
+			"user_address": signedOrder.UserAddress,
+			"trading_pair": signedOrder.TradingPair,
+			"side":         signedOrder.Side,
+			"price":        signedOrder.Price.String(),
+			"amount":       signedOrder.Amount.String(),
+			"signature":    signedOrder.Signature,
+		}).Warn("⚠️  Signature verification temporarily disabled for testing")
+	} else if valid, err := signer.VerifyOrderSignature(&signedOrder); err != nil || !valid {
+		h.logger.WithFields(logrus.Fields{
+			"user_address": signedOrder.UserAddress,
+			"signature":    signedOrder.Signature,
+		}).Warn("Invalid order signature")
+		c.JSON(http.StatusBadRequest, h.orderSignatureMismatch(signer, &signedOrder, err))
 		return
 	}
 
 	// 生成订单哈希
-	orderHash := crypto.GenerateOrderHash(&signedOrder)
+	orderHash := signer.GenerateOrderHash(&signedOrder)
 
-	// 检查订单是否已存在
+	// 检查订单是否已存在，被拒绝的订单允许重新提交（如充值后重试），沿用原订单记录
+	orderID := uuid.New()
+	resubmitted := false
 	existingOrder, err := h.storage.GetOrderByHash(orderHash)
 	if err == nil && existingOrder != nil {
-		c.JSON(http.StatusConflict, gin.H{"error": "Order already exists", "order_id": existingOrder.ID})
-		return
+		if existingOrder.Status != types.OrderStatusRejected {
+			c.JSON(http.StatusConflict, gin.H{"error": "Order already exists", "code": CodeOrderExists, "order_id": existingOrder.ID})
+			return
+		}
+		orderID = existingOrder.ID
+		resubmitted = true
 	}
 
 	// 创建订单
 	order := &types.Order{
-		ID:          uuid.New(),
+		ID:          orderID,
 		UserAddress: signedOrder.UserAddress,
 		TradingPair: signedOrder.TradingPair,
+		ChainID:     signedOrder.ChainID,
 		BaseToken:   signedOrder.BaseToken,
 		QuoteToken:  signedOrder.QuoteToken,
 		Side:        signedOrder.Side,
@@ -107,17 +285,139 @@ func (h *Handler) PlaceOrder(c *gin.Context) {
 		Status:      types.OrderStatusPending,
 		CreatedAt:   time.Now(),
 		UpdatedAt:   time.Now(),
+
+		MaxSlippage:     signedOrder.MaxSlippage,
+		ProtectionPrice: signedOrder.ProtectionPrice,
+		ReduceOnly:      signedOrder.ReduceOnly,
+	}
+
+	if signedOrder.MaxSlippage.IsNegative() || signedOrder.ProtectionPrice.IsNegative() {
+		h.rejectOrder(order, "INVALID_SLIPPAGE", "max_slippage and protection_price must not be negative", resubmitted)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid slippage protection", "code": CodeInvalidSlippage, "details": order.RejectReason, "order_id": order.ID})
+		return
+	}
+
+	// 检查订单是否过期
+	if signedOrder.ExpiresAt != nil && signedOrder.ExpiresAt.Before(time.Now()) {
+		h.rejectOrder(order, "ORDER_EXPIRED", "order expired before submission", resubmitted)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Order expired", "code": CodeOrderExpired, "order_id": order.ID})
+		return
+	}
+
+	// 拒绝已使用或已作废的nonce
+	if h.nonces != nil {
+		if err := h.nonces.Use(signedOrder.UserAddress, signedOrder.Nonce); err != nil {
+			code := CodeNonceUsed
+			if errors.Is(err, nonce.ErrNonceInvalidated) {
+				code = CodeNonceInvalidated
+			}
+			h.rejectOrder(order, code, err.Error(), resubmitted)
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid nonce", "code": code, "details": err.Error(), "order_id": order.ID})
+			return
+		}
+	}
+
+	// 只减仓订单按当前持仓缩减数量
+	if order.ReduceOnly {
+		if h.reduceOnly == nil {
+			h.releaseNonce(order)
+			h.rejectOrder(order, CodeFeatureDisabled, "reduce-only orders disabled", resubmitted)
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Reduce-only orders disabled", "code": CodeFeatureDisabled, "order_id": order.ID})
+			return
+		}
+		if err := h.reduceOnly.Clamp(order); err != nil {
+			h.releaseNonce(order)
+			h.rejectOrder(order, types.StatusReasonReduceOnly, err.Error(), resubmitted)
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Reduce-only order rejected", "code": CodeReduceOnly, "details": err.Error(), "order_id": order.ID})
+			return
+		}
+	}
+
+	// 合规筛查：未通过制裁名单等筛查的地址在风控与资金检查前拒绝
+	if result, err := h.screenAddress(c.Request.Context(), order.UserAddress); err != nil {
+		status, code, message := complianceErrorResponse(err)
+		h.releaseNonce(order)
+		h.rejectOrder(order, code, err.Error(), resubmitted)
+		c.JSON(status, gin.H{"error": message, "code": code, "details": err.Error(), "order_id": order.ID, "screening": result})
+		return
+	}
+
+	// 风控检查
+	if h.risk != nil {
+		if result := h.risk.CheckOrderRisk(order, h.availableBalances(order.UserAddress)); !result.Allowed {
+			h.logger.WithFields(logrus.Fields{
+				"user_address": order.UserAddress,
+				"trading_pair": order.TradingPair,
+				"code":         result.Code,
+				"reason":       result.Reason,
+			}).Warn("Order rejected by risk control")
+			h.releaseNonce(order)
+			h.rejectOrder(order, result.Code, result.Reason, resubmitted)
+			c.JSON(riskStatus(result.Code), gin.H{"error": "Order rejected by risk control", "code": result.Code, "details": result.Reason, "order_id": order.ID})
+			return
+		}
+	}
+
+	// 链上资金预检：托管余额或授权不足的订单撮合后必然结算失败
+	if err := h.preflightOrder(c.Request.Context(), order); err != nil {
+		status, code, message := preflightErrorResponse(err)
+		h.releaseNonce(order)
+		h.rejectOrder(order, code, err.Error(), resubmitted)
+		c.JSON(status, gin.H{"error": message, "code": code, "details": err.Error(), "order_id": order.ID})
+		return
+	}
+
+	// 锁定下单资金
+	if err := h.lockOrderFunds(order); errors.Is(err, errNoLockPrice) {
+		h.releaseNonce(order)
+		h.rejectOrder(order, types.StatusReasonNoLiquidity, err.Error(), resubmitted)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No liquidity for market order", "code": CodeNoLiquidity, "details": err.Error(), "order_id": order.ID})
+		return
+	} else if err != nil {
+		h.releaseNonce(order)
+		h.rejectOrder(order, types.StatusReasonInsufficientBalance, err.Error(), resubmitted)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Insufficient balance", "code": CodeInsufficientBalance, "details": err.Error(), "order_id": order.ID})
+		return
+	}
+
+	// 跨入口去重：同一意图已经由链上 OrderPlaced 事件进入订单簿
+	if h.intake != nil {
+		if original, err := h.intake.Admit(order, intake.SourceREST); err != nil {
+			h.releaseNonce(order)
+			h.releaseOrderFunds(order)
+			h.rejectOrder(order, types.StatusReasonDuplicateIntent, fmt.Sprintf("%s: %s", err.Error(), original), resubmitted)
+			c.JSON(http.StatusConflict, gin.H{"error": "Duplicate order intent", "code": CodeDuplicateIntent, "details": order.RejectReason, "order_id": order.ID, "original_order_id": original})
+			return
+		}
 	}
 
 	// 保存到数据库
-	if err := h.storage.CreateOrder(order); err != nil {
+	if err := h.saveOrder(order, resubmitted); err != nil {
 		h.logger.WithError(err).Error("Failed to create order")
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create order"})
+		h.releaseNonce(order)
+		h.releaseOrderFunds(order)
+		h.releaseIntake(order)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create order", "code": CodeInternal})
 		return
 	}
 
 	// 提交到撮合引擎
-	fills := h.engine.AddOrder(order)
+	fills, err := h.engine.AddOrder(order)
+	if err != nil {
+		h.releaseNonce(order)
+		h.releaseOrderFunds(order)
+		h.releaseIntake(order)
+		if updateErr := h.storage.UpdateOrder(order); updateErr != nil {
+			h.logger.WithError(updateErr).Error("Failed to update rejected order")
+		}
+
+		h.logger.WithError(err).WithField("order_id", order.ID).Warn("Order rejected by matching engine")
+		h.recordOrderAudit(audit.ActionOrderPlace, order, err)
+		status, response := engineErrorResponse(err)
+		response["order_id"] = order.ID
+		c.JSON(status, response)
+		return
+	}
 
 	// 保存成交记录
 	for _, fill := range fills {
@@ -140,26 +440,33 @@ func (h *Handler) PlaceOrder(c *gin.Context) {
 		"price":        order.Price.String(),
 		"fills":        len(fills),
 	}).Info("Order placed")
+	h.recordOrderAudit(audit.ActionOrderPlace, order, nil)
 
 	c.JSON(http.StatusCreated, gin.H{
-		"order_id": order.ID,
-		"status":   order.Status,
-		"fills":    fills,
+		"order_id":      order.ID,
+		"status":        order.Status,
+		"status_reason": order.StatusReason,
+		"fills":         fills,
 	})
 }
 
 // CancelOrder 取消订单接口
 func (h *Handler) CancelOrder(c *gin.Context) {
+	if h.requireSignedCancel {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Signed cancellation required", "code": CodeSignatureRequired, "details": "use POST /api/v1/orders/cancel with an EIP-712 CancelOrder signature"})
+		return
+	}
+
 	orderIDStr := c.Param("order_id")
 	orderID, err := uuid.Parse(orderIDStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid order ID"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid order ID", "code": CodeInvalidRequest})
 		return
 	}
 
 	userAddress := c.Query("user_address")
 	if userAddress == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "User address required"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": "User address required", "code": CodeInvalidRequest})
 		return
 	}
 
@@ -167,30 +474,119 @@ func (h *Handler) CancelOrder(c *gin.Context) {
 	order, err := h.storage.GetOrder(orderID)
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to get order")
-		c.JSON(http.StatusNotFound, gin.H{"error": "Order not found"})
+		c.JSON(http.StatusNotFound, gin.H{"error": "Order not found", "code": CodeOrderNotFound})
 		return
 	}
 
 	// 验证用户权限
 	if order.UserAddress != userAddress {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Not authorized to cancel this order"})
+		c.JSON(http.StatusForbidden, gin.H{"error": "Not authorized to cancel this order", "code": CodePermissionDenied})
 		return
 	}
 
-	// 检查订单状态
-	if !order.IsActive() {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Order cannot be cancelled", "status": order.Status})
+	h.cancelActiveOrder(c, order)
+}
+
+// CancelOrderSigned 签名撤单接口
+// 用户对 EIP-712 CancelOrder(orderHash, userAddress, nonce, expiresAt) 签名，无需信任请求中的地址参数
+func (h *Handler) CancelOrderSigned(c *gin.Context) {
+	var cancel types.SignedCancel
+	if err := c.ShouldBindJSON(&cancel); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cancel format", "code": CodeInvalidRequest, "details": err.Error()})
 		return
 	}
 
-	// 从撮合引擎中取消
-	success := h.engine.CancelOrder(orderID, order.TradingPair)
-	if !success {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel order in engine"})
+	if time.Now().Unix() > cancel.ExpiresAt {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Cancel request expired", "code": CodeSignatureExpired})
 		return
 	}
 
-	// 更新数据库
+	chainID, signer, err := h.chainSigner(cancel.ChainID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid chain", "code": CodeChainMismatch, "details": err.Error()})
+		return
+	}
+
+	valid, err := signer.VerifyCancelSignature(&cancel)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, h.cancelSignatureMismatch(signer, &cancel, err))
+		return
+	}
+	if !valid {
+		h.logger.WithFields(logrus.Fields{
+			"user_address": cancel.UserAddress,
+			"order_hash":   cancel.OrderHash,
+		}).Warn("Cancel signature does not match user address")
+		c.JSON(http.StatusUnauthorized, h.cancelSignatureMismatch(signer, &cancel, nil))
+		return
+	}
+
+	order, err := h.storage.GetOrderByHash(strings.TrimPrefix(strings.ToLower(cancel.OrderHash), "0x"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Order not found", "code": CodeOrderNotFound})
+		return
+	}
+
+	if !strings.EqualFold(order.UserAddress, cancel.UserAddress) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Not authorized to cancel this order", "code": CodePermissionDenied})
+		return
+	}
+
+	// 撤单签名必须与订单处于同一链的签名域
+	if h.chains != nil && order.ChainID != chainID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid chain", "code": CodeChainMismatch, "details": fmt.Sprintf("order is on chain %d", order.ChainID)})
+		return
+	}
+
+	// 撤单签名的nonce只能使用一次，防止截获的撤单在有效期内被重放；撤单未执行时释放，同一签名可以重试
+	if h.nonces != nil {
+		if err := h.nonces.UseCancel(cancel.UserAddress, cancel.Nonce, time.Unix(cancel.ExpiresAt, 0)); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid nonce", "code": CodeNonceUsed, "details": err.Error()})
+			return
+		}
+	}
+	if !h.cancelActiveOrder(c, order) && h.nonces != nil {
+		h.nonces.ReleaseCancel(cancel.UserAddress, cancel.Nonce)
+	}
+}
+
+// cancelActiveOrder 从撮合引擎撤销订单并返回结果，返回订单是否已撤销
+func (h *Handler) cancelActiveOrder(c *gin.Context, order *types.Order) bool {
+	// 检查订单状态
+	if !order.IsActive() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Order cannot be cancelled", "code": CodeOrderNotCancellable, "status": order.Status})
+		return false
+	}
+
+	// 从撮合引擎中取消，挂单未满交易对最短停留时间时拒绝
+	if err := h.cancelOrder(order); err != nil {
+		if errors.Is(err, matching.ErrMinRestingTime) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Order has not rested for the minimum time", "code": CodeMinRestingTime, "details": err.Error()})
+			return false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel order in engine", "code": CodeInternal})
+		return false
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"order_id": order.ID,
+		"status":   order.Status,
+	})
+	return true
+}
+
+// cancelOrder 从撮合引擎撤销挂单并持久化撤单状态
+// 挂单未满交易对最短停留时间时返回 matching.ErrMinRestingTime，已不在订单簿中时返回 matching.ErrOrderNotResting
+func (h *Handler) cancelOrder(order *types.Order) error {
+	cancelled, err := h.engine.CancelUserOrder(order.ID, order.TradingPair)
+	if err != nil {
+		return err
+	}
+
+	// 存储中读取的订单仍是撤单前的状态
+	order.Status = cancelled.Status
+	order.StatusReason = cancelled.StatusReason
+	order.UpdatedAt = cancelled.UpdatedAt
 	if err := h.storage.UpdateOrder(order); err != nil {
 		h.logger.WithError(err).Error("Failed to update cancelled order")
 	}
@@ -200,18 +596,15 @@ func (h *Handler) CancelOrder(c *gin.Context) {
 		"user_address": order.UserAddress,
 		"trading_pair": order.TradingPair,
 	}).Info("Order cancelled")
-
-	c.JSON(http.StatusOK, gin.H{
-		"order_id": order.ID,
-		"status":   order.Status,
-	})
+	h.recordOrderAudit(audit.ActionOrderCancel, order, nil)
+	return nil
 }
 
 // GetOrderBook 获取订单簿接口
 func (h *Handler) GetOrderBook(c *gin.Context) {
 	tradingPair := c.Param("trading_pair")
 	if tradingPair == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Trading pair required"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Trading pair required", "code": CodeInvalidRequest})
 		return
 	}
 
@@ -225,17 +618,105 @@ func (h *Handler) GetOrderBook(c *gin.Context) {
 	c.JSON(http.StatusOK, orderBook)
 }
 
+// GetBBO 获取交易对最优买卖价接口
+func (h *Handler) GetBBO(c *gin.Context) {
+	tradingPair := c.Param("trading_pair")
+	if tradingPair == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Trading pair required", "code": CodeInvalidRequest})
+		return
+	}
+
+	c.JSON(http.StatusOK, h.engine.GetBBO(tradingPair))
+}
+
+// GetAllBBO 获取全部交易对最优买卖价接口
+func (h *Handler) GetAllBBO(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"bbo": h.engine.GetAllBBO()})
+}
+
+// GetLiquidityMetrics 获取交易对流动性指标接口
+// 查询参数：bps（中间价上下的深度区间，基点，逗号分隔），impact（价格冲击百分比，逗号分隔）
+func (h *Handler) GetLiquidityMetrics(c *gin.Context) {
+	tradingPair := c.Param("trading_pair")
+	if tradingPair == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Trading pair required", "code": CodeInvalidRequest})
+		return
+	}
+
+	bands, err := parseDecimalList(c.DefaultQuery("bps", "10,50,100"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid bps", "code": CodeInvalidRequest, "details": err.Error()})
+		return
+	}
+	impact, err := parseDecimalList(c.DefaultQuery("impact", "0.5,1,2"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid impact", "code": CodeInvalidRequest, "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, h.engine.GetLiquidityMetrics(tradingPair, bands, impact))
+}
+
+// GetEngineStats 获取交易对撮合引擎内部统计接口（挂单数、价格层级数、成交速率、最优价排队深度、最新序号）
+func (h *Handler) GetEngineStats(c *gin.Context) {
+	tradingPair := c.Param("trading_pair")
+	if tradingPair == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Trading pair required", "code": CodeInvalidRequest})
+		return
+	}
+
+	stats, ok := h.engine.GetPairStats(tradingPair)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Order book not found", "code": CodeNotFound})
+		return
+	}
+	c.JSON(http.StatusOK, stats)
+}
+
+// parseDecimalList 解析逗号分隔的正数列表，最多10个
+func parseDecimalList(value string) ([]decimal.Decimal, error) {
+	parts := strings.Split(value, ",")
+	if len(parts) > 10 {
+		return nil, errors.New("at most 10 values allowed")
+	}
+	values := make([]decimal.Decimal, 0, len(parts))
+	for _, part := range parts {
+		parsed, err := decimal.NewFromString(strings.TrimSpace(part))
+		if err != nil || !parsed.IsPositive() {
+			return nil, fmt.Errorf("invalid value %q: expected positive number", part)
+		}
+		values = append(values, parsed)
+	}
+	return values, nil
+}
+
+// GetOrderBookL3 获取逐笔订单簿接口（L3），可按 user_address 只返回该用户的挂单
+func (h *Handler) GetOrderBookL3(c *gin.Context) {
+	tradingPair := c.Param("trading_pair")
+	if tradingPair == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Trading pair required", "code": CodeInvalidRequest})
+		return
+	}
+
+	depth, err := strconv.Atoi(c.DefaultQuery("depth", "20"))
+	if err != nil || depth <= 0 || depth > 100 {
+		depth = 20
+	}
+
+	c.JSON(http.StatusOK, h.engine.GetOrderBookL3(tradingPair, depth, c.Query("user_address")))
+}
+
 // GetOrders 获取用户订单列表
 func (h *Handler) GetOrders(c *gin.Context) {
 	userAddress := c.Query("user_address")
 	if userAddress == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "User address required"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": "User address required", "code": CodeInvalidRequest})
 		return
 	}
 
 	tradingPair := c.Query("trading_pair")
 	status := c.Query("status")
-	
+
 	limitStr := c.DefaultQuery("limit", "50")
 	limit, err := strconv.Atoi(limitStr)
 	if err != nil || limit <= 0 || limit > 100 {
@@ -248,10 +729,20 @@ func (h *Handler) GetOrders(c *gin.Context) {
 		offset = 0
 	}
 
+	if h.history != nil {
+		h.queryOrderHistory(c, history.OrderQuery{
+			UserAddress: userAddress,
+			TradingPair: tradingPair,
+			Status:      status,
+			Limit:       limit,
+		})
+		return
+	}
+
 	orders, err := h.storage.GetUserOrders(userAddress, tradingPair, status, limit, offset)
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to get user orders")
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get orders"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get orders", "code": CodeInternal})
 		return
 	}
 
@@ -266,48 +757,84 @@ func (h *Handler) GetOrder(c *gin.Context) {
 	orderIDStr := c.Param("order_id")
 	orderID, err := uuid.Parse(orderIDStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid order ID"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid order ID", "code": CodeInvalidRequest})
 		return
 	}
 
 	order, err := h.storage.GetOrder(orderID)
+	if err != nil && h.coldArchive != nil {
+		// 热存储中已清理的订单从冷存储取回
+		order, err = h.coldArchive.Order(c.Request.Context(), orderID)
+	}
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Order not found"})
+		c.JSON(http.StatusNotFound, gin.H{"error": "Order not found", "code": CodeOrderNotFound})
+		return
+	}
+	if !h.authorizeUser(c, order.UserAddress) {
 		return
 	}
 
 	c.JSON(http.StatusOK, order)
 }
 
+// GetQueuePosition 获取挂单的排队位置（同价位排在前面的订单数与数量）
+func (h *Handler) GetQueuePosition(c *gin.Context) {
+	orderID, err := uuid.Parse(c.Param("order_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid order ID", "code": CodeInvalidRequest})
+		return
+	}
+
+	position, ok := h.engine.GetQueuePosition(orderID)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Order not resting in order book", "code": CodeOrderNotFound})
+		return
+	}
+	if apiSession := h.apiSession(c); apiSession != nil {
+		if order, err := h.storage.GetOrder(orderID); err != nil || !strings.EqualFold(order.UserAddress, apiSession.UserAddress) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "API key does not belong to this user", "code": CodePermissionDenied})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, position)
+}
+
 // GetTrades 获取交易历史
+// 可按 user_address、side、role（maker/taker，需指定用户）、start_time、end_time 过滤
 func (h *Handler) GetTrades(c *gin.Context) {
 	tradingPair := c.Query("trading_pair")
-	
+
 	limitStr := c.DefaultQuery("limit", "50")
 	limit, err := strconv.Atoi(limitStr)
 	if err != nil || limit <= 0 || limit > 100 {
 		limit = 50
 	}
 
+	query := history.FillQuery{
+		UserAddress: c.Query("user_address"),
+		TradingPair: tradingPair,
+		Side:        types.OrderSide(strings.ToLower(c.Query("side"))),
+		Role:        history.Role(strings.ToLower(c.Query("role"))),
+		Limit:       limit,
+	}
+	if h.history != nil {
+		h.queryTradeHistory(c, query)
+		return
+	}
+	if query.UserAddress != "" || query.Side != "" || query.Role != "" || c.Query("start_time") != "" || c.Query("end_time") != "" {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Trade filters not supported by storage", "code": CodeFeatureDisabled})
+		return
+	}
+
 	fills, err := h.storage.GetRecentFills(tradingPair, limit)
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to get trades")
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get trades"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get trades", "code": CodeInternal})
 		return
 	}
 
-	// 转换为交易格式
-	trades := make([]types.Trade, len(fills))
-	for i, fill := range fills {
-		trades[i] = types.Trade{
-			ID:          fill.ID,
-			TradingPair: fill.TradingPair,
-			Price:       fill.Price,
-			Amount:      fill.Amount,
-			Side:        fill.TakerSide,
-			Timestamp:   fill.CreatedAt,
-		}
-	}
+	trades := toTrades(fills)
 
 	c.JSON(http.StatusOK, gin.H{
 		"trades": trades,
@@ -315,30 +842,116 @@ func (h *Handler) GetTrades(c *gin.Context) {
 	})
 }
 
-// GetStats 获取交易对统计信息
+// GetLargeTrades 获取跨市场的近期大额成交
+func (h *Handler) GetLargeTrades(c *gin.Context) {
+	if h.stats == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Trade statistics disabled", "code": CodeFeatureDisabled})
+		return
+	}
+
+	minNotional := h.stats.GetConfig().LargeTradeMinNotional
+	if value := c.Query("min_notional"); value != "" {
+		parsed, err := decimal.NewFromString(value)
+		if err != nil || parsed.IsNegative() {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid min_notional", "code": CodeInvalidRequest})
+			return
+		}
+		minNotional = parsed
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit <= 0 || limit > 100 {
+		limit = 50
+	}
+
+	trades := h.stats.LargeTrades(minNotional, c.Query("trading_pair"), limit)
+	c.JSON(http.StatusOK, gin.H{
+		"trades":       trades,
+		"total":        len(trades),
+		"min_notional": decimal.Max(minNotional, h.stats.GetConfig().LargeTradeMinNotional),
+	})
+}
+
+// GetStats 获取交易对统计信息及当前生效的价格带（含急涨急跌后的动态收紧状态）
 func (h *Handler) GetStats(c *gin.Context) {
 	tradingPair := c.Param("trading_pair")
 	if tradingPair == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Trading pair required"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Trading pair required", "code": CodeInvalidRequest})
 		return
 	}
 
 	stats, err := h.storage.GetTradingPairStats(tradingPair, 24*time.Hour)
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to get trading pair stats")
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get stats"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get stats", "code": CodeInternal})
 		return
 	}
 
-	c.JSON(http.StatusOK, stats)
+	c.JSON(http.StatusOK, pairTicker{TradingPairStats: stats, PriceBand: h.engine.GetPriceBand(tradingPair)})
+}
+
+// pairTicker 24小时统计及当前生效的价格带
+type pairTicker struct {
+	*storage.TradingPairStats
+	PriceBand *matching.BandState `json:"price_band,omitempty"` // 未设置价格带或没有参考价时省略
+}
+
+// SetHealthChecker 设置依赖健康检查器
+func (h *Handler) SetHealthChecker(checker *health.Checker) {
+	h.health = checker
 }
 
 // HealthCheck 健康检查接口
+// 返回各依赖组件状态与整体结论，整体为 unhealthy 时返回 503 供负载均衡摘除实例
 func (h *Handler) HealthCheck(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{
-		"status":    "healthy",
-		"timestamp": time.Now(),
-		"version":   "1.0.0",
+	response := gin.H{
+		"status":        health.StatusHealthy,
+		"timestamp":     time.Now(),
+		"version":       "1.0.0",
+		"simulation":    h.simulation,
+		"system_status": h.systemStatus(),
+	}
+	code := http.StatusOK
+	if h.health != nil {
+		report := h.health.Check(c.Request.Context())
+		response["status"] = report.Status
+		response["checked_at"] = report.CheckedAt
+		response["components"] = report.Components
+		if report.Status == health.StatusUnhealthy {
+			code = http.StatusServiceUnavailable
+		}
+	}
+	c.JSON(code, response)
+}
+
+// SetReadiness 设置启动就绪门控
+func (h *Handler) SetReadiness(readiness *health.Readiness) {
+	h.readiness = readiness
+}
+
+// Liveness 存活探针：进程能处理请求即存活，不探测依赖，避免依赖故障导致实例被反复重启
+func (h *Handler) Liveness(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "alive", "timestamp": time.Now()})
+}
+
+// Readiness 就绪探针：启动条件全部完成且未在排空时返回 200，否则返回 503，编排系统据此决定是否转发流量
+func (h *Handler) Readiness(c *gin.Context) {
+	status := health.ReadinessStatus{Ready: true, Pending: []string{}, Completed: []string{}}
+	if h.readiness != nil {
+		status = h.readiness.Status()
+	}
+	draining := h.engine.Draining()
+
+	code := http.StatusOK
+	if !status.Ready || draining {
+		code = http.StatusServiceUnavailable
+	}
+	c.JSON(code, gin.H{
+		"ready":     status.Ready && !draining,
+		"draining":  draining,
+		"pending":   status.Pending,
+		"completed": status.Completed,
+		"ready_at":  status.ReadyAt,
 	})
 }
 
@@ -347,7 +960,7 @@ func (h *Handler) CORSMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
 		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, X-API-Key, X-API-Timestamp, X-API-Signature, accept, origin, Cache-Control, X-Requested-With")
 		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
 
 		if c.Request.Method == "OPTIONS" {
@@ -359,6 +972,20 @@ func (h *Handler) CORSMiddleware() gin.HandlerFunc {
 	}
 }
 
+// LoadShedMiddleware 记录请求耗时，供过载检测使用
+func (h *Handler) LoadShedMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if h.shedder == nil {
+			c.Next()
+			return
+		}
+
+		start := time.Now()
+		c.Next()
+		h.shedder.ObserveLatency(time.Since(start))
+	}
+}
+
 // LoggerMiddleware 日志中间件
 func (h *Handler) LoggerMiddleware() gin.HandlerFunc {
 	return gin.LoggerWithFormatter(func(param gin.LogFormatterParams) string {
@@ -372,4 +999,4 @@ func (h *Handler) LoggerMiddleware() gin.HandlerFunc {
 		}).Info("HTTP Request")
 		return ""
 	})
-}
\ No newline at end of file
+}
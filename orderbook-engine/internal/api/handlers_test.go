@@ -0,0 +1,153 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"orderbook-engine/internal/nonce"
+	"orderbook-engine/internal/types"
+	"orderbook-engine/pkg/crypto"
+)
+
+// fixedResting 所有交易对相同的挂单最短停留时间
+type fixedResting time.Duration
+
+func (r fixedResting) MinRestingTime(tradingPair string) time.Duration {
+	return time.Duration(r)
+}
+
+// signCancel 用钱包私钥签名撤单请求
+func signCancel(t *testing.T, handler *Handler, w *testWallet, userAddress, orderHash string, cancelNonce uint64) []byte {
+	cancel := &types.SignedCancel{
+		OrderHash:   orderHash,
+		UserAddress: userAddress,
+		Nonce:       cancelNonce,
+		ExpiresAt:   time.Now().Add(time.Minute).Unix(),
+	}
+	require.NoError(t, crypto.SignCancel(cancel, w.key, handler.signer))
+	body, err := json.Marshal(cancel)
+	require.NoError(t, err)
+	return body
+}
+
+func postCancel(router *gin.Engine, body []byte) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/orders/cancel", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func TestCancelOrderSignedReleasesNonceAndRejectsReplay(t *testing.T) {
+	handler, store := newTestHandler(t)
+	handler.SetNonceTracker(nonce.NewTracker(nil, nil, time.Minute, logrus.New()))
+	router := gin.New()
+	router.POST("/orders/cancel", handler.CancelOrderSigned)
+
+	w := newTestWallet(t)
+	order := restOrder(t, handler, store, w.address)
+	body := signCancel(t, handler, w, w.address, order.Hash, 1)
+
+	// 未满最短停留时间的撤单被拒绝，nonce 被释放
+	handler.engine.SetRestingPolicy(fixedResting(time.Minute))
+	rejected := postCancel(router, body)
+	assert.Equal(t, http.StatusBadRequest, rejected.Code)
+	assert.Equal(t, CodeMinRestingTime, errorCode(t, rejected))
+
+	// 同一签名可以重试
+	handler.engine.SetRestingPolicy(nil)
+	accepted := postCancel(router, body)
+	require.Equal(t, http.StatusOK, accepted.Code, accepted.Body.String())
+	stored, err := store.GetOrder(order.ID)
+	require.NoError(t, err)
+	assert.Equal(t, types.OrderStatusCancelled, stored.Status)
+
+	// 已执行的撤单签名不能重放
+	replay := postCancel(router, body)
+	assert.Equal(t, http.StatusBadRequest, replay.Code)
+	assert.Equal(t, CodeNonceUsed, errorCode(t, replay))
+}
+
+func TestCancelOrderSignedRejectsOtherSigner(t *testing.T) {
+	handler, store := newTestHandler(t)
+	handler.SetNonceTracker(nonce.NewTracker(nil, nil, time.Minute, logrus.New()))
+	router := gin.New()
+	router.POST("/orders/cancel", handler.CancelOrderSigned)
+
+	owner := newTestWallet(t)
+	other := newTestWallet(t)
+	order := restOrder(t, handler, store, owner.address)
+
+	// 以订单所有者名义、由其他地址签名的撤单被拒绝
+	forged := postCancel(router, signCancel(t, handler, other, owner.address, order.Hash, 1))
+	assert.Equal(t, http.StatusUnauthorized, forged.Code)
+	assert.Equal(t, CodeInvalidSignature, errorCode(t, forged))
+
+	// 其他地址签名自己的撤单也不能撤销该订单
+	denied := postCancel(router, signCancel(t, handler, other, other.address, order.Hash, 1))
+	assert.Equal(t, http.StatusForbidden, denied.Code)
+	assert.Equal(t, CodePermissionDenied, errorCode(t, denied))
+
+	stored, err := store.GetOrder(order.ID)
+	require.NoError(t, err)
+	assert.True(t, stored.IsActive())
+	assert.Len(t, handler.engine.OpenOrders(), 1)
+}
+
+func TestReduceOnlyDisabledRejectsAndReleasesNonce(t *testing.T) {
+	handler, store := newTestHandler(t)
+	handler.SetNonceTracker(nonce.NewTracker(nil, nil, time.Minute, logrus.New()))
+	router := gin.New()
+	router.POST("/orders", handler.PlaceOrder)
+
+	w := newTestWallet(t)
+	body, err := json.Marshal(&types.SignedOrder{
+		UserAddress: w.address,
+		TradingPair: "WETH-USDC",
+		BaseToken:   "0x0000000000000000000000000000000000000001",
+		QuoteToken:  "0x0000000000000000000000000000000000000002",
+		Side:        types.OrderSideSell,
+		Type:        types.OrderTypeLimit,
+		Price:       decimal.NewFromInt(2000),
+		Amount:      decimal.NewFromInt(1),
+		Nonce:       7,
+		ReduceOnly:  true,
+	})
+	require.NoError(t, err)
+	place := func() *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/orders", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	// 未启用只减仓时拒绝并记录为被拒绝的订单
+	recorder := place()
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+	assert.Equal(t, CodeFeatureDisabled, errorCode(t, recorder))
+	var response struct {
+		OrderID uuid.UUID `json:"order_id"`
+	}
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+	stored, err := store.GetOrder(response.OrderID)
+	require.NoError(t, err)
+	assert.Equal(t, types.OrderStatusRejected, stored.Status)
+
+	// nonce 已释放，同一订单重新提交沿用原记录而不是报 nonce 已使用
+	recorder = place()
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+	assert.Equal(t, CodeFeatureDisabled, errorCode(t, recorder))
+	assert.NoError(t, handler.nonces.Use(w.address, 7))
+}
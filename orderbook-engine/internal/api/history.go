@@ -0,0 +1,193 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"orderbook-engine/internal/history"
+	"orderbook-engine/internal/types"
+)
+
+// SetHistoryStore 设置历史查询，订单和成交列表改为按时间倒序的游标分页
+func (h *Handler) SetHistoryStore(store history.Store) {
+	h.history = store
+}
+
+// queryOrderHistory 按时间范围和游标查询订单历史
+// 查询参数：from、to（Unix秒或RFC3339），cursor（上一页返回的 next_cursor）
+func (h *Handler) queryOrderHistory(c *gin.Context, query history.OrderQuery) {
+	if !bindHistoryPage(c, c.Query("from"), c.Query("to"), &query.From, &query.To, &query.After) {
+		return
+	}
+
+	limit := query.Limit
+	query.Limit = limit + 1
+	orders, err := h.history.QueryOrders(query)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to query order history")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get orders", "code": CodeInternal})
+		return
+	}
+
+	var nextCursor string
+	if len(orders) > limit {
+		orders = orders[:limit]
+		last := orders[limit-1]
+		nextCursor = history.Cursor{CreatedAt: last.CreatedAt, ID: last.ID}.Encode()
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"orders":      orders,
+		"total":       len(orders),
+		"next_cursor": nextCursor,
+	})
+}
+
+// queryTradeHistory 按条件、时间范围和游标查询成交历史
+// 时间范围参数为 start_time、end_time（兼容 from、to）
+func (h *Handler) queryTradeHistory(c *gin.Context, query history.FillQuery) {
+	if query.Side != "" && query.Side != types.OrderSideBuy && query.Side != types.OrderSideSell {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid side", "code": CodeInvalidRequest, "details": "expected buy or sell"})
+		return
+	}
+	if query.Role != "" && query.Role != history.RoleMaker && query.Role != history.RoleTaker {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid role", "code": CodeInvalidRequest, "details": "expected maker or taker"})
+		return
+	}
+	if query.Role != "" && query.UserAddress == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "User address required for role filter", "code": CodeInvalidRequest})
+		return
+	}
+	start := c.DefaultQuery("start_time", c.Query("from"))
+	end := c.DefaultQuery("end_time", c.Query("to"))
+	if !bindHistoryPage(c, start, end, &query.From, &query.To, &query.After) {
+		return
+	}
+
+	limit := query.Limit
+	query.Limit = limit + 1
+	fills, err := h.history.QueryFills(query)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to query trade history")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get trades", "code": CodeInternal})
+		return
+	}
+
+	var nextCursor string
+	if len(fills) > limit {
+		fills = fills[:limit]
+		last := fills[limit-1]
+		nextCursor = history.Cursor{CreatedAt: last.CreatedAt, ID: last.ID}.Encode()
+	}
+
+	var trades interface{} = toTrades(fills)
+	if query.UserAddress != "" {
+		trades = h.toUserTrades(fills, query.UserAddress)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"trades":      trades,
+		"total":       len(fills),
+		"next_cursor": nextCursor,
+	})
+}
+
+// userTrade 用户视角的成交
+type userTrade struct {
+	types.Trade
+	Role     history.Role    `json:"role"`
+	UserSide types.OrderSide `json:"user_side"`
+	TxHash   string          `json:"tx_hash,omitempty"`
+}
+
+// toUserTrades 成交记录转换为用户视角，taker 订单属于用户时为 taker，否则为 maker
+func (h *Handler) toUserTrades(fills []*types.Fill, userAddress string) []userTrade {
+	trades := make([]userTrade, len(fills))
+	for i, fill := range fills {
+		trade := userTrade{
+			Trade:    toTrades([]*types.Fill{fill})[0],
+			Role:     history.RoleMaker,
+			UserSide: oppositeSide(fill.TakerSide),
+			TxHash:   fill.TxHash,
+		}
+		takerAddress := fill.TakerUserAddress
+		if takerAddress == "" {
+			// 未记录用户地址的成交（早期或导入数据）从订单存储中查找
+			if order, err := h.storage.GetOrder(fill.TakerOrderID); err == nil {
+				takerAddress = order.UserAddress
+			}
+		}
+		if strings.EqualFold(takerAddress, userAddress) {
+			trade.Role = history.RoleTaker
+			trade.UserSide = fill.TakerSide
+		}
+		trades[i] = trade
+	}
+	return trades
+}
+
+func oppositeSide(side types.OrderSide) types.OrderSide {
+	if side == types.OrderSideBuy {
+		return types.OrderSideSell
+	}
+	return types.OrderSideBuy
+}
+
+// bindHistoryPage 解析时间范围和游标参数，失败时写入400响应
+func bindHistoryPage(c *gin.Context, fromValue, toValue string, from, to *time.Time, after **history.Cursor) bool {
+	var err error
+	if *from, err = parseTimeParam(fromValue); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid start time", "code": CodeInvalidRequest, "details": err.Error()})
+		return false
+	}
+	if *to, err = parseTimeParam(toValue); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid end time", "code": CodeInvalidRequest, "details": err.Error()})
+		return false
+	}
+	if !from.IsZero() && !to.IsZero() && !from.Before(*to) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid time range", "code": CodeInvalidRequest, "details": "start time must be before end time"})
+		return false
+	}
+	if *after, err = history.DecodeCursor(c.Query("cursor")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cursor", "code": CodeInvalidRequest})
+		return false
+	}
+	return true
+}
+
+// parseTimeParam 解析时间参数（Unix秒或RFC3339），空字符串返回零值
+func parseTimeParam(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if unix, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(unix, 0).UTC(), nil
+	}
+	parsed, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return time.Time{}, errors.New("expected unix timestamp in seconds or RFC3339")
+	}
+	return parsed.UTC(), nil
+}
+
+// toTrades 成交记录转换为公开交易格式
+func toTrades(fills []*types.Fill) []types.Trade {
+	trades := make([]types.Trade, len(fills))
+	for i, fill := range fills {
+		trades[i] = types.Trade{
+			ID:              fill.ID,
+			TradingPair:     fill.TradingPair,
+			Price:           fill.Price,
+			Amount:          fill.Amount,
+			Side:            fill.TakerSide,
+			Timestamp:       fill.CreatedAt,
+			EngineTimestamp: fill.EngineTimestamp,
+		}
+	}
+	return trades
+}
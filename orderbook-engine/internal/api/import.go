@@ -0,0 +1,156 @@
+package api
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"orderbook-engine/internal/importer"
+)
+
+// SetImporter 设置历史数据导入器
+func (h *Handler) SetImporter(imp *importer.Importer, maxBodyBytes int64) {
+	h.importer = imp
+	h.importMaxBytes = maxBodyBytes
+}
+
+// ImportTrades 导入历史成交（管理接口）
+// 请求体为 CSV 或 JSON 文件内容，也可使用 multipart 表单的 file 字段上传
+func (h *Handler) ImportTrades(c *gin.Context) {
+	h.runImport(c, h.importer.ImportTrades)
+}
+
+// ImportCandles 导入历史K线（管理接口）
+func (h *Handler) ImportCandles(c *gin.Context) {
+	h.runImport(c, h.importer.ImportCandles)
+}
+
+type importFunc func(r io.Reader, format importer.Format, opts importer.Options) (*importer.Result, error)
+
+func (h *Handler) runImport(c *gin.Context, run importFunc) {
+	if h.importer == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Historical import disabled", "code": CodeFeatureDisabled})
+		return
+	}
+
+	// 未指定格式时按 Content-Type 推断
+	formatValue := c.Query("format")
+	if formatValue == "" {
+		formatValue = string(importer.FormatCSV)
+		if strings.Contains(c.ContentType(), "json") {
+			formatValue = string(importer.FormatJSON)
+		}
+	}
+	format, err := importer.ParseFormat(formatValue)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid format", "code": CodeInvalidRequest, "details": err.Error()})
+		return
+	}
+
+	if h.importMaxBytes > 0 {
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.importMaxBytes)
+	}
+
+	var body io.Reader = c.Request.Body
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		file, err := c.FormFile("file")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Missing file", "code": CodeInvalidRequest, "details": err.Error()})
+			return
+		}
+		opened, err := file.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid file", "code": CodeInvalidRequest, "details": err.Error()})
+			return
+		}
+		defer opened.Close()
+		body = opened
+	}
+
+	dryRun, _ := strconv.ParseBool(c.DefaultQuery("dry_run", "false"))
+	result, err := run(body, format, importer.Options{
+		Source: c.Query("source"),
+		DryRun: dryRun,
+	})
+	if err != nil {
+		status, code := http.StatusBadRequest, CodeInvalidRequest
+		if result != nil {
+			// 校验通过但写入存储失败，部分记录可能已导入，可使用相同来源重试
+			status, code = http.StatusInternalServerError, CodeInternal
+		}
+		c.JSON(status, gin.H{"error": "Import failed", "code": code, "details": err.Error(), "result": result})
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"kind":      result.Kind,
+		"source":    result.Source,
+		"total":     result.Total,
+		"imported":  result.Imported,
+		"failed":    result.Failed,
+		"dry_run":   result.DryRun,
+		"client_ip": c.ClientIP(),
+	}).Info("Admin ran historical import")
+
+	if result.Failed > 0 {
+		c.JSON(http.StatusUnprocessableEntity, result)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// GetCandles 获取K线
+func (h *Handler) GetCandles(c *gin.Context) {
+	candleStore, ok := h.storage.(importer.CandleStore)
+	if !ok {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Candles not supported by storage", "code": CodeFeatureDisabled})
+		return
+	}
+
+	interval := c.DefaultQuery("interval", "1h")
+	if _, ok := importer.CandleIntervals[interval]; !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid interval", "code": CodeInvalidRequest})
+		return
+	}
+
+	var start, end time.Time
+	for _, param := range []struct {
+		name  string
+		value *time.Time
+	}{{"start", &start}, {"end", &end}} {
+		raw := c.Query(param.name)
+		if raw == "" {
+			continue
+		}
+		unix, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + param.name, "code": CodeInvalidRequest, "details": "expected unix timestamp in seconds"})
+			return
+		}
+		*param.value = time.Unix(unix, 0).UTC()
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "500"))
+	if err != nil || limit <= 0 || limit > 1000 {
+		limit = 500
+	}
+
+	tradingPair := strings.ToUpper(c.Param("trading_pair"))
+	candles, err := candleStore.GetCandles(tradingPair, interval, start, end, limit)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to get candles")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get candles", "code": CodeInternal})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"trading_pair": tradingPair,
+		"interval":     interval,
+		"candles":      candles,
+	})
+}
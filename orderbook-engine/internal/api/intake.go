@@ -0,0 +1,32 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"orderbook-engine/internal/intake"
+	"orderbook-engine/internal/types"
+)
+
+// SetIntakeDeduper 设置订单入口去重，为空时不去重
+func (h *Handler) SetIntakeDeduper(deduper *intake.Deduper) {
+	h.intake = deduper
+}
+
+// releaseIntake 放行后未进入订单簿的订单撤销去重登记
+func (h *Handler) releaseIntake(order *types.Order) {
+	if h.intake != nil {
+		h.intake.Release(order, intake.SourceREST)
+	}
+}
+
+// GetIntakeDedupStats 获取订单入口去重统计
+func (h *Handler) GetIntakeDedupStats(c *gin.Context) {
+	if h.intake == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Order intake dedup disabled", "code": CodeFeatureDisabled})
+		return
+	}
+
+	c.JSON(http.StatusOK, h.intake.Stats())
+}
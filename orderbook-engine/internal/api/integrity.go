@@ -0,0 +1,38 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"orderbook-engine/internal/integrity"
+)
+
+// SetIntegrityChecker 设置撮合引擎与存储的一致性检查器
+func (h *Handler) SetIntegrityChecker(checker *integrity.Checker) {
+	h.integrity = checker
+}
+
+// RunIntegrityCheck 交叉检查订单簿、存储与余额锁定（管理接口）
+// 默认只报告不一致，dry_run=false 时同时修复
+func (h *Handler) RunIntegrityCheck(c *gin.Context) {
+	if h.integrity == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Integrity check not available", "code": CodeFeatureDisabled})
+		return
+	}
+	dryRun, err := strconv.ParseBool(c.DefaultQuery("dry_run", "true"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid dry_run", "code": CodeInvalidRequest, "details": err.Error()})
+		return
+	}
+
+	report, err := h.integrity.Run(dryRun, time.Now())
+	if err != nil {
+		h.logger.WithError(err).Error("Integrity check failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Integrity check failed", "code": CodeInternal, "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
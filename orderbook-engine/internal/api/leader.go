@@ -0,0 +1,38 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"orderbook-engine/internal/leader"
+)
+
+// SetElector 设置主备选举器
+func (h *Handler) SetElector(elector *leader.Elector) {
+	h.elector = elector
+}
+
+// RequireLeader 写接口中间件：备用实例只提供只读行情，下单和撤单需发往主实例
+func (h *Handler) RequireLeader() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if h.engine.Standby() {
+			status := gin.H{"error": "Instance is not the leader", "code": CodeNotLeader}
+			if h.elector != nil {
+				status["leader"] = h.elector.Status().Holder
+			}
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, status)
+			return
+		}
+		c.Next()
+	}
+}
+
+// GetLeaderStatus 获取主备选举状态
+func (h *Handler) GetLeaderStatus(c *gin.Context) {
+	if h.elector == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Leader election disabled", "code": CodeFeatureDisabled})
+		return
+	}
+	c.JSON(http.StatusOK, h.elector.Status())
+}
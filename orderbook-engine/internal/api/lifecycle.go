@@ -0,0 +1,101 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"orderbook-engine/internal/lifecycle"
+)
+
+// SetColdArchive 设置订单与成交的冷数据归档任务
+func (h *Handler) SetColdArchive(archiver *lifecycle.Archiver) {
+	h.coldArchive = archiver
+}
+
+// GetArchivedOrder 从冷存储取回已归档的订单及其成交
+func (h *Handler) GetArchivedOrder(c *gin.Context) {
+	if h.coldArchive == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Data archive not enabled", "code": CodeFeatureDisabled})
+		return
+	}
+	orderID, err := uuid.Parse(c.Param("order_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid order ID", "code": CodeInvalidRequest})
+		return
+	}
+
+	order, err := h.coldArchive.Order(c.Request.Context(), orderID)
+	if errors.Is(err, lifecycle.ErrNotArchived) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Order not archived", "code": CodeNotFound})
+		return
+	}
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to read archived order")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read archived order", "code": CodeInternal})
+		return
+	}
+	if !h.authorizeUser(c, order.UserAddress) {
+		return
+	}
+
+	fills, err := h.coldArchive.OrderFills(c.Request.Context(), orderID)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to read archived fills")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read archived fills", "code": CodeInternal})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"order": order, "fills": fills})
+}
+
+// GetArchivedFill 从冷存储取回已归档的成交（管理接口）
+func (h *Handler) GetArchivedFill(c *gin.Context) {
+	if h.coldArchive == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Data archive not enabled", "code": CodeFeatureDisabled})
+		return
+	}
+	fillID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid fill ID", "code": CodeInvalidRequest})
+		return
+	}
+
+	fills, err := h.coldArchive.Fills(c.Request.Context(), []uuid.UUID{fillID})
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to read archived fill")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read archived fill", "code": CodeInternal})
+		return
+	}
+	if len(fills) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Fill not archived", "code": CodeNotFound})
+		return
+	}
+	c.JSON(http.StatusOK, fills[0])
+}
+
+// GetColdArchiveStats 冷数据归档统计（管理接口）
+func (h *Handler) GetColdArchiveStats(c *gin.Context) {
+	if h.coldArchive == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Data archive not enabled", "code": CodeFeatureDisabled})
+		return
+	}
+	c.JSON(http.StatusOK, h.coldArchive.Stats())
+}
+
+// RunColdArchive 立即执行一次归档（管理接口）
+func (h *Handler) RunColdArchive(c *gin.Context) {
+	if h.coldArchive == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Data archive not enabled", "code": CodeFeatureDisabled})
+		return
+	}
+	result, err := h.coldArchive.Run(c.Request.Context(), time.Now())
+	if err != nil {
+		h.logger.WithError(err).Error("Data archive run failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Archive run failed", "code": CodeInternal, "details": err.Error(), "result": result})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,333 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+
+	"orderbook-engine/internal/audit"
+	"orderbook-engine/internal/listing"
+	"orderbook-engine/internal/tokens"
+	"orderbook-engine/internal/types"
+)
+
+// SetPairListings 设置交易对登记表，上线时代币元数据登记到 tokenRegistry
+func (h *Handler) SetPairListings(registry *listing.Registry, tokenRegistry *tokens.Registry) {
+	h.listings = registry
+	h.tokenRegistry = tokenRegistry
+}
+
+// GetPairs 获取登记的交易对（状态、最小价格变动单位与数量单位）
+func (h *Handler) GetPairs(c *gin.Context) {
+	if h.listings == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Pair listing disabled", "code": CodeFeatureDisabled})
+		return
+	}
+
+	pairs := h.listings.Pairs()
+	c.JSON(http.StatusOK, gin.H{"pairs": pairs, "total": len(pairs)})
+}
+
+// ListPair 上线交易对（管理接口）
+// 初始状态默认为 pending，安排开盘竞价开始后才接受订单；trading 表示立即开放交易
+func (h *Handler) ListPair(c *gin.Context) {
+	if h.listings == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Pair listing disabled", "code": CodeFeatureDisabled})
+		return
+	}
+
+	var req struct {
+		TradingPair string           `json:"trading_pair"` // 为空时按代币符号生成
+		ChainID     uint64           `json:"chain_id"`
+		BaseToken   tokens.Token     `json:"base_token"`
+		QuoteToken  tokens.Token     `json:"quote_token"`
+		TickSize    decimal.Decimal  `json:"tick_size"`
+		LotSize     decimal.Decimal  `json:"lot_size"`
+		MinResting  int64            `json:"min_resting_ms"`
+		Status      types.PairStatus `json:"status"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid listing request", "code": CodeInvalidRequest, "details": err.Error()})
+		return
+	}
+
+	// 交易对的结算链由链配置决定，上线的链必须与之一致
+	if h.chains != nil {
+		chain := h.chains.ChainForPair(req.BaseToken.Symbol + "-" + req.QuoteToken.Symbol)
+		if req.ChainID == 0 {
+			req.ChainID = chain.ChainID
+		} else if req.ChainID != chain.ChainID {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Trading pair settles on another chain", "code": CodeChainMismatch, "details": chain.ChainID})
+			return
+		}
+	}
+
+	pair, err := h.listings.List(listing.Pair{
+		TradingPair:  req.TradingPair,
+		ChainID:      req.ChainID,
+		BaseToken:    req.BaseToken,
+		QuoteToken:   req.QuoteToken,
+		TickSize:     req.TickSize,
+		LotSize:      req.LotSize,
+		MinRestingMs: req.MinResting,
+		Status:       req.Status,
+	})
+	if errors.Is(err, listing.ErrPairExists) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Trading pair already listed", "code": CodeConflict, "details": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid listing request", "code": CodeInvalidRequest, "details": err.Error()})
+		return
+	}
+
+	// 登记代币精度，链上订单与结算按该元数据换算
+	if h.tokenRegistry != nil {
+		for _, token := range []tokens.Token{pair.BaseToken, pair.QuoteToken} {
+			if err := h.tokenRegistry.Register(token); err != nil {
+				h.logger.WithError(err).WithField("token", token.Address).Error("Failed to register listed token")
+			}
+		}
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"trading_pair": pair.TradingPair,
+		"status":       pair.Status,
+		"client_ip":    c.ClientIP(),
+	}).Info("Admin listed trading pair")
+	h.recordAudit(&audit.Entry{
+		ActorType: audit.ActorAdmin,
+		Actor:     c.ClientIP(),
+		Action:    audit.ActionPairList,
+		Resource:  pair.TradingPair,
+		Details: map[string]interface{}{
+			"chain_id":       pair.ChainID,
+			"tick_size":      pair.TickSize,
+			"lot_size":       pair.LotSize,
+			"min_resting_ms": pair.MinRestingMs,
+			"status":         pair.Status,
+		},
+	})
+
+	c.JSON(http.StatusCreated, gin.H{"pair": pair})
+}
+
+// ScheduleOpeningAuction 安排交易对的开盘集合竞价（管理接口），start_at 为空时立即开始
+func (h *Handler) ScheduleOpeningAuction(c *gin.Context) {
+	if h.listings == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Pair listing disabled", "code": CodeFeatureDisabled})
+		return
+	}
+
+	var req struct {
+		StartAt  time.Time `json:"start_at"`
+		Duration string    `json:"duration" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid auction request", "code": CodeInvalidRequest, "details": err.Error()})
+		return
+	}
+	duration, err := time.ParseDuration(req.Duration)
+	if err != nil || duration <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid duration", "code": CodeInvalidRequest})
+		return
+	}
+
+	tradingPair := c.Param("trading_pair")
+	pair, err := h.listings.ScheduleAuction(tradingPair, req.StartAt, duration)
+	if err != nil {
+		h.listingError(c, err)
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"trading_pair": tradingPair,
+		"start_at":     pair.OpeningAuction.StartAt,
+		"duration":     duration.String(),
+		"client_ip":    c.ClientIP(),
+	}).Info("Admin scheduled opening auction")
+	h.recordAudit(&audit.Entry{
+		ActorType: audit.ActorAdmin,
+		Actor:     c.ClientIP(),
+		Action:    audit.ActionPairAuction,
+		Resource:  tradingPair,
+		Details: map[string]interface{}{
+			"start_at": pair.OpeningAuction.StartAt,
+			"ends_at":  pair.OpeningAuction.EndsAt,
+		},
+	})
+
+	c.JSON(http.StatusOK, gin.H{"pair": pair})
+}
+
+// SetPairMinResting 设置交易对的挂单最短停留时间（管理接口），0 表示不限制
+// 挂单未满该时长时用户撤单被拒绝，用于抑制挂单后立即撤单的闪烁报价
+func (h *Handler) SetPairMinResting(c *gin.Context) {
+	if h.listings == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Pair listing disabled", "code": CodeFeatureDisabled})
+		return
+	}
+
+	var req struct {
+		MinRestingMs *int64 `json:"min_resting_ms" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid min resting request", "code": CodeInvalidRequest, "details": err.Error()})
+		return
+	}
+
+	tradingPair := c.Param("trading_pair")
+	pair, err := h.listings.SetMinResting(tradingPair, *req.MinRestingMs)
+	if err != nil {
+		h.listingError(c, err)
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"trading_pair":   tradingPair,
+		"min_resting_ms": pair.MinRestingMs,
+		"client_ip":      c.ClientIP(),
+	}).Info("Admin set minimum resting time")
+	h.recordAudit(&audit.Entry{
+		ActorType: audit.ActorAdmin,
+		Actor:     c.ClientIP(),
+		Action:    audit.ActionPairMinResting,
+		Resource:  tradingPair,
+		Details:   map[string]interface{}{"min_resting_ms": pair.MinRestingMs},
+	})
+
+	c.JSON(http.StatusOK, gin.H{"pair": pair})
+}
+
+// HaltListedPair 暂停登记的交易对（管理接口），与熔断暂停相同：拒绝会立即成交的订单，挂单保留
+func (h *Handler) HaltListedPair(c *gin.Context) {
+	if h.listings == nil || h.breaker == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Pair listing or circuit breaker disabled", "code": CodeFeatureDisabled})
+		return
+	}
+
+	var req struct {
+		Reason   string `json:"reason" binding:"required"`
+		Duration string `json:"duration"` // 为空表示需人工恢复
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid halt request", "code": CodeInvalidRequest, "details": err.Error()})
+		return
+	}
+	var duration time.Duration
+	if req.Duration != "" {
+		var err error
+		duration, err = time.ParseDuration(req.Duration)
+		if err != nil || duration < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid duration", "code": CodeInvalidRequest})
+			return
+		}
+	}
+
+	tradingPair := c.Param("trading_pair")
+	pair, ok := h.listings.Get(tradingPair)
+	if !ok {
+		h.listingError(c, listing.ErrPairNotFound)
+		return
+	}
+	if pair.Status == types.PairStatusDelisted {
+		h.listingError(c, listing.ErrPairDelisted)
+		return
+	}
+	// 熔断器发布 pair_status 事件，登记表随之同步为 halted
+	h.breaker.Halt(tradingPair, req.Reason, duration)
+
+	h.logger.WithFields(logrus.Fields{
+		"trading_pair": tradingPair,
+		"reason":       req.Reason,
+		"client_ip":    c.ClientIP(),
+	}).Warn("Admin halted listed trading pair")
+	h.recordAudit(&audit.Entry{
+		ActorType: audit.ActorAdmin,
+		Actor:     c.ClientIP(),
+		Action:    audit.ActionPairHalt,
+		Resource:  tradingPair,
+		Details:   map[string]interface{}{"reason": req.Reason, "duration": req.Duration},
+	})
+
+	c.JSON(http.StatusOK, gin.H{"trading_pair": tradingPair, "halted": true})
+}
+
+// DelistPair 下架交易对（管理接口）
+// 先下架再撤单，撤单期间到达的新订单已被拒绝；撤单通过订单推送通知用户，下架通过 status.<交易对> 主题推送
+func (h *Handler) DelistPair(c *gin.Context) {
+	if h.listings == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Pair listing disabled", "code": CodeFeatureDisabled})
+		return
+	}
+
+	var req struct {
+		Reason string `json:"reason" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid delist request", "code": CodeInvalidRequest, "details": err.Error()})
+		return
+	}
+
+	tradingPair := c.Param("trading_pair")
+	pair, err := h.listings.Delist(tradingPair, req.Reason)
+	if err != nil {
+		h.listingError(c, err)
+		return
+	}
+	cancelled := h.cancelDelistedOrders(tradingPair)
+
+	h.logger.WithFields(logrus.Fields{
+		"trading_pair": tradingPair,
+		"reason":       req.Reason,
+		"cancelled":    cancelled,
+		"client_ip":    c.ClientIP(),
+	}).Warn("Admin delisted trading pair")
+	h.recordAudit(&audit.Entry{
+		ActorType: audit.ActorAdmin,
+		Actor:     c.ClientIP(),
+		Action:    audit.ActionPairDelist,
+		Resource:  tradingPair,
+		Details: map[string]interface{}{
+			"reason":           req.Reason,
+			"cancelled_orders": cancelled,
+		},
+	})
+
+	c.JSON(http.StatusOK, gin.H{"pair": pair, "cancelled_orders": cancelled})
+}
+
+// cancelDelistedOrders 撤销交易对在订单簿中的全部挂单并回写存储，返回撤销数量
+func (h *Handler) cancelDelistedOrders(tradingPair string) int {
+	cancelled := 0
+	for _, order := range h.engine.OpenOrders() {
+		if order.TradingPair != tradingPair {
+			continue
+		}
+		snapshot, ok := h.engine.CancelOrderWithReason(order.ID, tradingPair, types.StatusReasonPairDelisted)
+		if !ok {
+			continue
+		}
+		if err := h.storage.UpdateOrder(snapshot); err != nil {
+			h.logger.WithError(err).WithField("order_id", order.ID).Error("Failed to update cancelled order")
+		}
+		cancelled++
+	}
+	return cancelled
+}
+
+// listingError 交易对登记表错误的响应
+func (h *Handler) listingError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, listing.ErrPairNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Trading pair not listed", "code": CodeNotFound, "details": err.Error()})
+	case errors.Is(err, listing.ErrPairDelisted):
+		c.JSON(http.StatusConflict, gin.H{"error": "Trading pair delisted", "code": CodeConflict, "details": err.Error()})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request", "code": CodeInvalidRequest, "details": err.Error()})
+	}
+}
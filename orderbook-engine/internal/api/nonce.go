@@ -0,0 +1,104 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"orderbook-engine/internal/audit"
+	"orderbook-engine/internal/nonce"
+	"orderbook-engine/internal/types"
+)
+
+// SetNonceTracker 设置nonce跟踪器
+func (h *Handler) SetNonceTracker(tracker *nonce.Tracker) {
+	h.nonces = tracker
+}
+
+// GetAccountNonce 获取用户nonce状态
+func (h *Handler) GetAccountNonce(c *gin.Context) {
+	if h.nonces == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Nonce tracking disabled", "code": CodeFeatureDisabled})
+		return
+	}
+
+	address := c.Param("address")
+	if !common.IsHexAddress(address) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid address", "code": CodeInvalidAddress})
+		return
+	}
+
+	c.JSON(http.StatusOK, h.nonces.GetStatus(address))
+}
+
+// CancelOrdersBelowNonce 签名批量撤单接口
+// 用户对 EIP-712 CancelUpTo(userAddress, minNonce, expiresAt) 签名，作废并撤销 nonce 小于 minNonce 的全部订单
+func (h *Handler) CancelOrdersBelowNonce(c *gin.Context) {
+	if h.nonces == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Nonce tracking disabled", "code": CodeFeatureDisabled})
+		return
+	}
+
+	var cancel types.SignedNonceCancel
+	if err := c.ShouldBindJSON(&cancel); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cancel format", "code": CodeInvalidRequest, "details": err.Error()})
+		return
+	}
+
+	if time.Now().Unix() > cancel.ExpiresAt {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Cancel request expired", "code": CodeSignatureExpired})
+		return
+	}
+
+	// nonce 作废对所有链生效（引擎内各链共享用户的nonce空间），签名使用请求指定链的签名域
+	_, signer, err := h.chainSigner(cancel.ChainID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid chain", "code": CodeChainMismatch, "details": err.Error()})
+		return
+	}
+
+	valid, err := signer.VerifyNonceCancelSignature(&cancel)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, h.nonceCancelSignatureMismatch(signer, &cancel, err))
+		return
+	}
+	if !valid {
+		h.logger.WithField("user_address", cancel.UserAddress).Warn("Nonce cancel signature does not match user address")
+		c.JSON(http.StatusUnauthorized, h.nonceCancelSignatureMismatch(signer, &cancel, nil))
+		return
+	}
+
+	// 先提高最小有效nonce，避免撤单期间有低nonce订单进入
+	h.nonces.InvalidateBelow(cancel.UserAddress, cancel.MinNonce)
+	cancelled := h.cancelUserOrders(cancel.UserAddress, func(order *types.Order) bool {
+		return order.Nonce < cancel.MinNonce
+	})
+
+	h.logger.WithFields(logrus.Fields{
+		"user_address": cancel.UserAddress,
+		"min_nonce":    cancel.MinNonce,
+		"cancelled":    cancelled,
+	}).Info("Orders below nonce cancelled")
+	h.recordAudit(&audit.Entry{
+		ActorType: audit.ActorUser,
+		Actor:     cancel.UserAddress,
+		Action:    audit.ActionOrderCancelBelowNonce,
+		Resource:  cancel.UserAddress,
+		Details:   map[string]interface{}{"min_nonce": cancel.MinNonce, "cancelled": cancelled},
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"cancelled": cancelled,
+		"nonce":     h.nonces.GetStatus(cancel.UserAddress),
+	})
+}
+
+// releaseNonce 订单未被接受时释放其nonce，允许用户重新提交
+func (h *Handler) releaseNonce(order *types.Order) {
+	if h.nonces != nil {
+		h.nonces.Release(order.UserAddress, order.Nonce)
+	}
+}
@@ -0,0 +1,103 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+
+	"orderbook-engine/internal/obligations"
+)
+
+// SetObligationMonitor 设置做市商报价义务监控
+func (h *Handler) SetObligationMonitor(monitor *obligations.Monitor) {
+	h.obligations = monitor
+}
+
+// GetMMObligations 获取做市商报价义务及当前考核周期的合规情况
+func (h *Handler) GetMMObligations(c *gin.Context) {
+	if h.obligations == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Market maker obligations disabled", "code": CodeFeatureDisabled})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"obligations": h.obligations.Obligations(),
+		"status":      h.obligations.Status(),
+	})
+}
+
+// SetMMObligation 新增或更新做市商在交易对上的报价义务（热更新）
+func (h *Handler) SetMMObligation(c *gin.Context) {
+	if h.obligations == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Market maker obligations disabled", "code": CodeFeatureDisabled})
+		return
+	}
+
+	var req struct {
+		MaxSpreadBps decimal.Decimal `json:"max_spread_bps"`
+		MinPresence  decimal.Decimal `json:"min_presence"`
+		MinSize      decimal.Decimal `json:"min_size"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid obligation", "code": CodeInvalidRequest, "details": err.Error()})
+		return
+	}
+
+	obligation := &obligations.Obligation{
+		MarketMaker:  c.Param("address"),
+		TradingPair:  c.Param("trading_pair"),
+		MaxSpreadBps: req.MaxSpreadBps,
+		MinPresence:  req.MinPresence,
+		MinSize:      req.MinSize,
+	}
+	if err := h.obligations.SetObligation(obligation); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid obligation", "code": CodeInvalidRequest, "details": err.Error()})
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"market_maker": obligation.MarketMaker,
+		"trading_pair": obligation.TradingPair,
+		"client_ip":    c.ClientIP(),
+	}).Info("Admin updated market maker obligation")
+
+	c.JSON(http.StatusOK, gin.H{"obligation": obligation})
+}
+
+// DeleteMMObligation 移除做市商在交易对上的报价义务
+func (h *Handler) DeleteMMObligation(c *gin.Context) {
+	if h.obligations == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Market maker obligations disabled", "code": CodeFeatureDisabled})
+		return
+	}
+
+	marketMaker, tradingPair := c.Param("address"), c.Param("trading_pair")
+	if !h.obligations.RemoveObligation(marketMaker, tradingPair) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Obligation not found", "code": CodeNotFound})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"market_maker": marketMaker, "trading_pair": tradingPair, "removed": true})
+}
+
+// GetMMObligationReports 获取已结束考核周期的合规报告，可按 market_maker、trading_pair 过滤
+func (h *Handler) GetMMObligationReports(c *gin.Context) {
+	if h.obligations == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Market maker obligations disabled", "code": CodeFeatureDisabled})
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if err != nil || limit <= 0 || limit > 1000 {
+		limit = 100
+	}
+
+	reports := h.obligations.Reports(c.Query("market_maker"), c.Query("trading_pair"), limit)
+	c.JSON(http.StatusOK, gin.H{
+		"reports": reports,
+		"total":   len(reports),
+	})
+}
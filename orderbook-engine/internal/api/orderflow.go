@@ -0,0 +1,53 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+
+	"orderbook-engine/internal/orderflow"
+)
+
+// SetOrderFlowTracker 设置用户订单流统计，为空时不提供订单流接口
+func (h *Handler) SetOrderFlowTracker(tracker *orderflow.Tracker) {
+	h.orderFlow = tracker
+}
+
+// GetOrderFlow 获取用户在各统计窗口内的消息频率、撤单与改单比例、成交比例和挂单存续时间（管理接口）
+func (h *Handler) GetOrderFlow(c *gin.Context) {
+	if h.orderFlow == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Order flow analytics disabled", "code": CodeFeatureDisabled})
+		return
+	}
+	address := c.Param("address")
+	if !common.IsHexAddress(address) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid address", "code": CodeInvalidAddress})
+		return
+	}
+
+	c.JSON(http.StatusOK, h.orderFlow.User(address))
+}
+
+// GetOrderFlowTop 获取窗口内消息数最多的用户（管理接口），window 默认 1h，limit 默认 50
+func (h *Handler) GetOrderFlowTop(c *gin.Context) {
+	if h.orderFlow == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Order flow analytics disabled", "code": CodeFeatureDisabled})
+		return
+	}
+	window, err := time.ParseDuration(c.DefaultQuery("window", "1h"))
+	if err != nil || window <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid window", "code": CodeInvalidRequest})
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit <= 0 || limit > 1000 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit", "code": CodeInvalidRequest})
+		return
+	}
+
+	users := h.orderFlow.Top(window, limit)
+	c.JSON(http.StatusOK, gin.H{"window": window.String(), "users": users, "total": len(users)})
+}
@@ -0,0 +1,66 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+
+	"orderbook-engine/internal/pairs"
+)
+
+// SetPairCanonicalizer 设置交易对规范命名，下单时按订单代币校验交易对名称与基础/计价顺序
+func (h *Handler) SetPairCanonicalizer(canonicalizer *pairs.Canonicalizer) {
+	h.pairNames = canonicalizer
+}
+
+// ResolvePair 获取代币对的规范交易对名称与基础/计价顺序
+// 查询参数：base_token、quote_token（必填），chain_id（为空时为默认链）
+func (h *Handler) ResolvePair(c *gin.Context) {
+	if h.pairNames == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Pair naming not available", "code": CodeFeatureDisabled})
+		return
+	}
+
+	baseToken, quoteToken := c.Query("base_token"), c.Query("quote_token")
+	if !common.IsHexAddress(baseToken) || !common.IsHexAddress(quoteToken) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "base_token and quote_token must be token addresses", "code": CodeInvalidAddress})
+		return
+	}
+	var requested uint64
+	if value := c.Query("chain_id"); value != "" {
+		parsed, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid chain_id", "code": CodeInvalidRequest})
+			return
+		}
+		requested = parsed
+	}
+	chainID, _, err := h.chainSigner(requested)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid chain", "code": CodeChainMismatch, "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, h.pairNames.Resolve(chainID, baseToken, quoteToken))
+}
+
+// checkOrderPair 按订单代币校验并规范交易对名称，未设置规范命名时原样返回
+func (h *Handler) checkOrderPair(chainID uint64, tradingPair, baseToken, quoteToken string) (string, error) {
+	if h.pairNames == nil {
+		return tradingPair, nil
+	}
+	return h.pairNames.Check(chainID, tradingPair, baseToken, quoteToken)
+}
+
+// pairMismatchResponse 交易对与订单代币不一致的响应
+func pairMismatchResponse(err error) gin.H {
+	response := gin.H{"error": "Trading pair does not match order tokens", "code": CodePairMismatch, "details": err.Error()}
+	var mismatch *pairs.MismatchError
+	if errors.As(err, &mismatch) {
+		response["expected"] = mismatch.Resolution
+	}
+	return response
+}
@@ -0,0 +1,117 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+
+	"orderbook-engine/internal/positions"
+)
+
+// accountPositions 账户持仓与风险暴露上限
+type accountPositions struct {
+	*positions.Summary
+	MaxExposure *decimal.Decimal `json:"max_exposure,omitempty"` // 风控启用且设置了上限时返回
+}
+
+// pnlHistory 账户每日盈亏快照
+type pnlHistory struct {
+	UserAddress string                `json:"user_address"`
+	Snapshots   []*positions.Snapshot `json:"snapshots"`
+	Total       int                   `json:"total"`
+}
+
+// SetPositionTracker 设置持仓跟踪，为空时不提供持仓接口
+func (h *Handler) SetPositionTracker(tracker *positions.Tracker) {
+	h.positions = tracker
+}
+
+// SetPnLSnapshotStore 设置每日盈亏快照存储，为空时不提供盈亏历史接口
+func (h *Handler) SetPnLSnapshotStore(store positions.SnapshotStore) {
+	h.pnlSnapshots = store
+}
+
+// GetPositions 获取账户各代币的净持仓与按标记价格计算的风险暴露
+func (h *Handler) GetPositions(c *gin.Context) {
+	if h.positions == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Position tracking disabled", "code": CodeFeatureDisabled})
+		return
+	}
+	address := c.Param("address")
+	if !common.IsHexAddress(address) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid address", "code": CodeInvalidAddress})
+		return
+	}
+
+	response := &accountPositions{Summary: h.positions.Positions(address)}
+	if h.risk != nil {
+		if maxExposure := h.risk.Config().MaxExposure; maxExposure.IsPositive() {
+			response.MaxExposure = &maxExposure
+		}
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// GetPnL 获取账户各交易对按平均成本计算的已实现与未实现盈亏
+func (h *Handler) GetPnL(c *gin.Context) {
+	if h.positions == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Position tracking disabled", "code": CodeFeatureDisabled})
+		return
+	}
+	address := c.Param("address")
+	if !common.IsHexAddress(address) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid address", "code": CodeInvalidAddress})
+		return
+	}
+
+	c.JSON(http.StatusOK, h.positions.PnL(address))
+}
+
+// GetPnLHistory 获取账户的每日盈亏快照，from、to 为 Unix 秒或 RFC3339，默认最近 30 天
+func (h *Handler) GetPnLHistory(c *gin.Context) {
+	if h.pnlSnapshots == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "PnL snapshots disabled", "code": CodeFeatureDisabled})
+		return
+	}
+	address := c.Param("address")
+	if !common.IsHexAddress(address) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid address", "code": CodeInvalidAddress})
+		return
+	}
+
+	from, err := parseTimeParam(c.Query("from"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from", "code": CodeInvalidRequest, "details": err.Error()})
+		return
+	}
+	to, err := parseTimeParam(c.Query("to"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to", "code": CodeInvalidRequest, "details": err.Error()})
+		return
+	}
+	if to.IsZero() {
+		to = time.Now().UTC()
+	}
+	if from.IsZero() {
+		from = to.AddDate(0, 0, -30)
+	}
+	if to.Before(from) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid time range", "code": CodeInvalidRequest, "details": "from must be before to"})
+		return
+	}
+	if to.Sub(from) > 366*24*time.Hour {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid time range", "code": CodeInvalidRequest, "details": "range must not exceed 366 days"})
+		return
+	}
+
+	snapshots, err := h.pnlSnapshots.History(address, from, to)
+	if err != nil {
+		h.logger.WithError(err).WithField("user", address).Error("Failed to load PnL snapshots")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load PnL history", "code": CodeInternal})
+		return
+	}
+	c.JSON(http.StatusOK, &pnlHistory{UserAddress: address, Snapshots: snapshots, Total: len(snapshots)})
+}
@@ -0,0 +1,37 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"orderbook-engine/internal/preflight"
+	"orderbook-engine/internal/types"
+)
+
+// SetPreflightChecker 设置下单前的链上资金预检，为空时不做预检
+func (h *Handler) SetPreflightChecker(checker *preflight.Checker) {
+	h.preflight = checker
+}
+
+// preflightOrder 检查用户的链上资金能否结算订单，买单按锁定价格折算报价代币
+func (h *Handler) preflightOrder(ctx context.Context, order *types.Order) error {
+	if h.preflight == nil {
+		return nil
+	}
+	return h.preflight.Check(ctx, order, h.lockPrice(order))
+}
+
+// preflightErrorResponse 链上资金预检失败的HTTP状态码、错误码与说明
+func preflightErrorResponse(err error) (int, string, string) {
+	switch {
+	case errors.Is(err, preflight.ErrInsufficientAllowance):
+		return http.StatusBadRequest, CodeInsufficientAllowance, "Insufficient allowance for settlement contract"
+	case errors.Is(err, preflight.ErrInsufficientDeposit), errors.Is(err, preflight.ErrInsufficientWalletBalance):
+		return http.StatusBadRequest, CodeInsufficientOnchainFunds, "Insufficient on-chain funds"
+	case errors.Is(err, preflight.ErrInvalidAddress):
+		return http.StatusBadRequest, CodeInvalidAddress, "Invalid address"
+	default:
+		return http.StatusServiceUnavailable, CodeServiceUnavailable, "On-chain funds check unavailable"
+	}
+}
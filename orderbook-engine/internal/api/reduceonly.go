@@ -0,0 +1,10 @@
+package api
+
+import (
+	"orderbook-engine/internal/reduceonly"
+)
+
+// SetReduceOnlyGuard 设置只减仓约束，为空时拒绝只减仓订单
+func (h *Handler) SetReduceOnlyGuard(guard *reduceonly.Guard) {
+	h.reduceOnly = guard
+}
@@ -0,0 +1,146 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+
+	"orderbook-engine/internal/audit"
+	"orderbook-engine/internal/referral"
+)
+
+// SetReferralProgram 设置推荐返佣
+func (h *Handler) SetReferralProgram(program *referral.Program) {
+	h.referrals = program
+}
+
+// registerReferralRequest 绑定推荐人请求
+type registerReferralRequest struct {
+	UserAddress string `json:"user_address" binding:"required"`
+	Referrer    string `json:"referrer" binding:"required"`
+}
+
+// RegisterReferral 绑定推荐人，每个地址只能绑定一次
+// POST /api/v1/referrals
+func (h *Handler) RegisterReferral(c *gin.Context) {
+	if h.referrals == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Referral program disabled", "code": CodeFeatureDisabled})
+		return
+	}
+
+	var req registerReferralRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid referral request", "code": CodeInvalidRequest, "details": err.Error()})
+		return
+	}
+	if !common.IsHexAddress(req.UserAddress) || !common.IsHexAddress(req.Referrer) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid referral request", "code": CodeInvalidRequest, "details": "user_address and referrer must be addresses"})
+		return
+	}
+	if !h.authorizeUser(c, req.UserAddress) {
+		return
+	}
+
+	registered, err := h.referrals.Register(req.UserAddress, req.Referrer)
+	if err != nil {
+		switch {
+		case errors.Is(err, referral.ErrAlreadyReferred):
+			c.JSON(http.StatusConflict, gin.H{"error": "Referrer already registered", "code": CodeReferralExists})
+		case errors.Is(err, referral.ErrSelfReferral), errors.Is(err, referral.ErrReferralCycle):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid referrer", "code": CodeInvalidReferrer, "details": err.Error()})
+		default:
+			h.logger.WithError(err).Error("Failed to register referral")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to register referral", "code": CodeInternal})
+		}
+		return
+	}
+
+	h.recordAudit(&audit.Entry{
+		ActorType: audit.ActorUser,
+		Actor:     registered.UserAddress,
+		Action:    audit.ActionReferralRegister,
+		Resource:  registered.UserAddress,
+		Details: map[string]interface{}{
+			"referrer": registered.Referrer,
+		},
+	})
+	c.JSON(http.StatusCreated, registered)
+}
+
+// GetReferralSummary 查询地址的推荐人、直接推荐人数与各代币返佣余额
+// GET /api/v1/referrals/:address
+func (h *Handler) GetReferralSummary(c *gin.Context) {
+	if h.referrals == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Referral program disabled", "code": CodeFeatureDisabled})
+		return
+	}
+
+	address := c.Param("address")
+	if !h.authorizeUser(c, address) {
+		return
+	}
+
+	config := h.referrals.Config()
+	c.JSON(http.StatusOK, gin.H{
+		"summary":       h.referrals.Summary(address),
+		"taker_fee_bps": config.TakerFeeBps,
+		"rebate_share":  config.RebateShare,
+	})
+}
+
+// claimRebateRequest 提取返佣请求
+type claimRebateRequest struct {
+	Referrer string `json:"referrer" binding:"required"`
+	Token    string `json:"token" binding:"required"`
+}
+
+// ClaimReferralRebate 提取某代币的全部可用返佣，入账到推荐人的托管余额后可正常提现
+// POST /api/v1/referrals/claim
+func (h *Handler) ClaimReferralRebate(c *gin.Context) {
+	if h.referrals == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Referral program disabled", "code": CodeFeatureDisabled})
+		return
+	}
+
+	var req claimRebateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid claim request", "code": CodeInvalidRequest, "details": err.Error()})
+		return
+	}
+	if !h.authorizeUser(c, req.Referrer) {
+		return
+	}
+
+	claim, err := h.referrals.Claim(req.Referrer, req.Token)
+	if err != nil {
+		switch {
+		case errors.Is(err, referral.ErrNothingToClaim):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Nothing to claim", "code": CodeNothingToClaim})
+		case errors.Is(err, referral.ErrPayoutDisabled):
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Rebate payout disabled", "code": CodeFeatureDisabled})
+		default:
+			h.logger.WithError(err).Error("Failed to claim referral rebate")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to claim rebate", "code": CodeInternal, "details": err.Error()})
+		}
+		return
+	}
+
+	h.recordAudit(&audit.Entry{
+		ActorType: audit.ActorUser,
+		Actor:     claim.Referrer,
+		Action:    audit.ActionReferralClaim,
+		Resource:  claim.Referrer,
+		Details: map[string]interface{}{
+			"claim_id": claim.ID,
+			"token":    claim.Token,
+			"amount":   claim.Amount.String(),
+		},
+	})
+	response := gin.H{"claim": claim}
+	if h.balances != nil {
+		response["balance"] = h.balances.GetTokenBalance(claim.Referrer, claim.Token)
+	}
+	c.JSON(http.StatusOK, response)
+}
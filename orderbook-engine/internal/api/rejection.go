@@ -0,0 +1,27 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+
+	"orderbook-engine/internal/audit"
+	"orderbook-engine/internal/types"
+)
+
+// rejectOrder 记录进入撮合前被拒绝的订单（参数校验、nonce、风控、余额）
+// 订单以 rejected 状态写入存储并发布 order_rejected 事件，用户和客服可通过订单查询接口（status=rejected）看到拒绝原因
+func (h *Handler) rejectOrder(order *types.Order, code, reason string, resubmitted bool) {
+	h.engine.RejectOrder(order, code, errors.New(reason))
+	if err := h.saveOrder(order, resubmitted); err != nil {
+		h.logger.WithError(err).WithField("order_id", order.ID).Error("Failed to save rejected order")
+	}
+	h.recordOrderAudit(audit.ActionOrderPlace, order, fmt.Errorf("%s: %s", code, reason))
+}
+
+// saveOrder 写入新订单，重新提交的被拒绝订单沿用原记录
+func (h *Handler) saveOrder(order *types.Order, resubmitted bool) error {
+	if resubmitted {
+		return h.storage.UpdateOrder(order)
+	}
+	return h.storage.CreateOrder(order)
+}
@@ -0,0 +1,86 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"orderbook-engine/internal/rewards"
+)
+
+// SetRewardsEngine 设置流动性挖矿积分引擎
+func (h *Handler) SetRewardsEngine(engine *rewards.Engine) {
+	h.rewards = engine
+}
+
+// rewardsEpoch 解析 epoch 查询参数，未指定时为当前纪元
+func (h *Handler) rewardsEpoch(c *gin.Context) (rewards.Epoch, bool) {
+	value := c.Query("epoch")
+	if value == "" {
+		return h.rewards.EpochAt(time.Now()), true
+	}
+	number, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || number < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid epoch", "code": CodeInvalidRequest})
+		return rewards.Epoch{}, false
+	}
+	return h.rewards.Epoch(number), true
+}
+
+// GetRewardsEpochs 当前纪元与有积分记录的纪元
+func (h *Handler) GetRewardsEpochs(c *gin.Context) {
+	if h.rewards == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Liquidity rewards disabled", "code": CodeFeatureDisabled})
+		return
+	}
+
+	epochs := make([]rewards.Epoch, 0)
+	for _, number := range h.rewards.Epochs() {
+		epochs = append(epochs, h.rewards.Epoch(number))
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"current": h.rewards.EpochAt(time.Now()),
+		"epochs":  epochs,
+	})
+}
+
+// GetRewardsLeaderboard 纪元积分排行，查询参数 epoch（默认当前纪元）、limit（默认50，最大500）
+func (h *Handler) GetRewardsLeaderboard(c *gin.Context) {
+	if h.rewards == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Liquidity rewards disabled", "code": CodeFeatureDisabled})
+		return
+	}
+
+	epoch, ok := h.rewardsEpoch(c)
+	if !ok {
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit <= 0 || limit > 500 {
+		limit = 50
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"epoch":       epoch,
+		"leaderboard": h.rewards.Leaderboard(epoch.Number, limit),
+	})
+}
+
+// GetRewardsScore 用户在纪元内的积分与排名，查询参数 epoch（默认当前纪元）
+func (h *Handler) GetRewardsScore(c *gin.Context) {
+	if h.rewards == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Liquidity rewards disabled", "code": CodeFeatureDisabled})
+		return
+	}
+
+	epoch, ok := h.rewardsEpoch(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"epoch": epoch,
+		"score": h.rewards.UserScore(c.Param("address"), epoch.Number),
+	})
+}
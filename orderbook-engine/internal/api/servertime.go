@@ -0,0 +1,29 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"orderbook-engine/internal/clock"
+)
+
+// serverTime 服务器时间，客户端据此校准本地时钟（签名时间戳与有效期按服务器时间计算）
+type serverTime struct {
+	ServerTime      time.Time `json:"server_time"`
+	ServerTimeMs    int64     `json:"server_time_ms"`
+	EngineTimestamp int64     `json:"engine_timestamp"`           // 引擎时间戳（单调递增 Unix 纳秒），与成交及推送消息中的同名字段可比
+	SignatureTTLMs  int64     `json:"signature_ttl_ms,omitempty"` // 挂单签名有效期，未启用时省略
+}
+
+// GetServerTime 获取服务器时间接口
+func (h *Handler) GetServerTime(c *gin.Context) {
+	now := time.Now()
+	c.JSON(http.StatusOK, serverTime{
+		ServerTime:      now.UTC(),
+		ServerTimeMs:    now.UnixMilli(),
+		EngineTimestamp: clock.Now(),
+		SignatureTTLMs:  h.engine.SignatureTTL().Milliseconds(),
+	})
+}
@@ -0,0 +1,399 @@
+package api
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"orderbook-engine/internal/matching"
+	"orderbook-engine/internal/session"
+	"orderbook-engine/internal/types"
+	"orderbook-engine/internal/websocket"
+	"orderbook-engine/pkg/crypto"
+)
+
+// SetSessionRegistry 设置会话注册表，吊销时按需撤销用户订单
+func (h *Handler) SetSessionRegistry(registry *session.Registry) {
+	h.sessions = registry
+	registry.OnRevoke(func(s *session.Session, cancelOrders bool) {
+		if cancelOrders {
+			cancelled := h.cancelUserOrders(s.UserAddress, nil)
+			h.logger.WithFields(logrus.Fields{
+				"user_address": s.UserAddress,
+				"cancelled":    cancelled,
+			}).Info("Cancelled orders on session revoke")
+		}
+	})
+}
+
+// SetAPIKeyAuth 设置API密钥鉴权
+// required 为true时私有接口必须携带签名的API密钥；window 为请求时间戳允许的偏差
+func (h *Handler) SetAPIKeyAuth(required bool, window time.Duration) {
+	h.apiKeyRequired = required
+	h.apiKeyWindow = window
+}
+
+// APIKeyMiddleware API密钥鉴权中间件
+// 携带 X-API-Key 时必须同时携带 X-API-Timestamp（毫秒）和 X-API-Signature（HMAC-SHA256），
+// 校验通过后记录会话供后续权限检查；未携带时放行，由 RequirePermission 决定是否拒绝
+func (h *Handler) APIKeyMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader("X-API-Key")
+		if key == "" || h.sessions == nil {
+			c.Next()
+			return
+		}
+
+		timestamp := c.GetHeader("X-API-Timestamp")
+		signature := c.GetHeader("X-API-Signature")
+		if timestamp == "" || signature == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Request signature required", "code": CodeSignatureRequired, "details": "X-API-Timestamp and X-API-Signature headers are required with X-API-Key"})
+			return
+		}
+
+		var body []byte
+		if c.Request.Body != nil {
+			var err error
+			if body, err = io.ReadAll(c.Request.Body); err != nil {
+				c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body", "code": CodeInvalidRequest})
+				return
+			}
+			c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		}
+
+		apiSession, err := h.sessions.AuthenticateRequest(key, timestamp, signature, c.Request.Method, c.Request.URL.RequestURI(), body, h.apiKeyWindow, c.ClientIP())
+		if err != nil {
+			h.logger.WithError(err).WithFields(logrus.Fields{
+				"client_ip": c.ClientIP(),
+				"path":      c.Request.URL.Path,
+			}).Warn("API key authentication failed")
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid API key", "code": CodeInvalidAPIKey, "details": err.Error()})
+			return
+		}
+
+		c.Set("api_session", apiSession)
+		c.Next()
+	}
+}
+
+// RequirePermission 私有接口权限中间件
+// 已鉴权时检查密钥权限及路径/查询参数中的用户地址归属；未鉴权时仅在强制鉴权模式下拒绝
+func (h *Handler) RequirePermission(permission session.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		apiSession := h.apiSession(c)
+		if apiSession == nil {
+			if h.apiKeyRequired {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "API key required", "code": CodeAPIKeyRequired})
+				return
+			}
+			c.Next()
+			return
+		}
+
+		if !apiSession.HasPermission(permission) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "API key lacks permission", "code": CodePermissionDenied, "details": string(permission) + " permission required"})
+			return
+		}
+		for _, address := range []string{c.Param("address"), c.Query("user_address")} {
+			if address != "" && !strings.EqualFold(address, apiSession.UserAddress) {
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "API key does not belong to this user", "code": CodePermissionDenied})
+				return
+			}
+		}
+		c.Next()
+	}
+}
+
+// WebSocketHandshake WebSocket握手接口
+// 连接身份取自 APIKeyMiddleware 已校验的API密钥与 X-Admin-Token，客户端IP与 REST 接口一致取 c.ClientIP()
+// 凭证只从请求头读取，查询串会出现在访问日志中
+func (h *Handler) WebSocketHandshake(adminToken string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if h.wsHub == nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "WebSocket hub unavailable", "code": CodeFeatureDisabled})
+			return
+		}
+
+		var identity websocket.Identity
+		if provided := c.GetHeader("X-Admin-Token"); provided != "" {
+			if adminToken == "" || subtle.ConstantTimeCompare([]byte(provided), []byte(adminToken)) != 1 {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Admin authentication required", "code": CodeUnauthorized})
+				return
+			}
+			identity.Admin = true
+		}
+		if apiSession := h.apiSession(c); apiSession != nil {
+			identity.Address = apiSession.UserAddress
+		}
+
+		h.wsHub.HandleWebSocket(c.Writer, c.Request, identity, c.ClientIP())
+	}
+}
+
+// ListSessions 列出用户的API密钥、会话密钥委托和WebSocket会话
+func (h *Handler) ListSessions(c *gin.Context) {
+	if h.sessions == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Session management disabled", "code": CodeFeatureDisabled})
+		return
+	}
+
+	userAddress, ok := h.requireSessionUser(c, session.ActionListSessions, "")
+	if !ok {
+		return
+	}
+
+	sessions := h.sessions.List(userAddress)
+	c.JSON(http.StatusOK, gin.H{
+		"sessions": sessions,
+		"total":    len(sessions),
+	})
+}
+
+// CreateAPIKey 创建API密钥
+func (h *Handler) CreateAPIKey(c *gin.Context) {
+	if h.sessions == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Session management disabled", "code": CodeFeatureDisabled})
+		return
+	}
+
+	var req struct {
+		UserAddress    string   `json:"user_address" binding:"required"`
+		Label          string   `json:"label"`
+		Permissions    []string `json:"permissions"` // read、trade，为空表示只读
+		TTL            string   `json:"ttl"`         // 为空表示不过期
+		CancelOnRevoke bool     `json:"cancel_on_revoke"`
+		Timestamp      int64    `json:"timestamp" binding:"required"` // 签名时间（Unix秒）
+		Signature      string   `json:"signature" binding:"required"` // 钱包对 session.APIKeyMessage 的 personal_sign 签名
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request", "code": CodeInvalidRequest, "details": err.Error()})
+		return
+	}
+
+	ttl, err := parseOptionalDuration(req.TTL)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ttl", "code": CodeInvalidRequest})
+		return
+	}
+
+	permissions, err := session.ParsePermissions(req.Permissions)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid permissions", "code": CodeInvalidRequest, "details": "allowed permissions: read, trade"})
+		return
+	}
+
+	// 密钥绑定的地址必须由钱包签名证明所有权
+	if skew := time.Since(time.Unix(req.Timestamp, 0)); skew > h.apiKeyWindow || skew < -h.apiKeyWindow {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Signature expired", "code": CodeStaleTimestamp})
+		return
+	}
+	message := session.APIKeyMessage(req.UserAddress, permissions, req.Timestamp)
+	valid, err := crypto.VerifyPersonalSignature(message, req.Signature, req.UserAddress)
+	if err != nil || !valid {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid signature", "code": CodeInvalidSignature})
+		return
+	}
+	if !h.walletSignatures.use(message, req.UserAddress, req.Timestamp, h.apiKeyWindow) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Signature already used", "code": CodeSignatureReused})
+		return
+	}
+
+	apiSession, key, secret, err := h.sessions.CreateAPIKey(req.UserAddress, req.Label, permissions, ttl, req.CancelOnRevoke)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to create API key")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create API key", "code": CodeInternal})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"session":    apiSession,
+		"api_key":    key,    // 仅返回一次
+		"api_secret": secret, // 请求签名密钥，仅返回一次
+	})
+}
+
+// RevokeSession 立即吊销会话
+// cancel_orders 可覆盖创建时的撤单设置
+func (h *Handler) RevokeSession(c *gin.Context) {
+	if h.sessions == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Session management disabled", "code": CodeFeatureDisabled})
+		return
+	}
+
+	sessionID, err := uuid.Parse(c.Param("session_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID", "code": CodeInvalidRequest})
+		return
+	}
+
+	userAddress, ok := h.requireSessionUser(c, session.ActionRevokeSession, sessionID.String())
+	if !ok {
+		return
+	}
+
+	var cancelOrders *bool
+	if value := c.Query("cancel_orders"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cancel_orders", "code": CodeInvalidRequest})
+			return
+		}
+		cancelOrders = &parsed
+	}
+
+	revoked, err := h.sessions.Revoke(userAddress, sessionID, cancelOrders)
+	if err != nil {
+		if errors.Is(err, session.ErrSessionRevoked) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Session already revoked", "code": CodeConflict})
+			return
+		}
+		c.JSON(http.StatusNotFound, gin.H{"error": "Session not found", "code": CodeNotFound})
+		return
+	}
+
+	c.JSON(http.StatusOK, revoked)
+}
+
+// requireSessionUser 获取请求用户地址并证明请求方控制该地址
+// 会话管理不受 auth.require_api_key 影响，始终需要认证：携带该用户的API密钥签名请求，
+// 或由用户钱包对 session.ManageMessage 签名（X-Wallet-Timestamp 为秒级时间戳，X-Wallet-Signature 为签名）
+func (h *Handler) requireSessionUser(c *gin.Context, action, target string) (string, bool) {
+	userAddress := c.Query("user_address")
+	if userAddress == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "User address required", "code": CodeInvalidRequest})
+		return "", false
+	}
+
+	if h.apiSession(c) != nil {
+		if !h.authorizeUser(c, userAddress) {
+			return "", false
+		}
+		return userAddress, true
+	}
+
+	timestamp, signature := c.GetHeader("X-Wallet-Timestamp"), c.GetHeader("X-Wallet-Signature")
+	if timestamp == "" || signature == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required", "code": CodeSignatureRequired, "details": "sign the request with an API key of this user, or provide X-Wallet-Timestamp and X-Wallet-Signature"})
+		return "", false
+	}
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid X-Wallet-Timestamp", "code": CodeInvalidRequest})
+		return "", false
+	}
+	if skew := time.Since(time.Unix(seconds, 0)); skew > h.apiKeyWindow || skew < -h.apiKeyWindow {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Signature expired", "code": CodeStaleTimestamp})
+		return "", false
+	}
+	message := session.ManageMessage(userAddress, action, target, seconds)
+	valid, err := crypto.VerifyPersonalSignature(message, signature, userAddress)
+	if err != nil || !valid {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid signature", "code": CodeInvalidSignature})
+		return "", false
+	}
+	// 同一签名只能使用一次，截获的请求不能在时间窗口内重放
+	if !h.walletSignatures.use(message, userAddress, seconds, h.apiKeyWindow) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Signature already used", "code": CodeSignatureReused})
+		return "", false
+	}
+	return userAddress, true
+}
+
+// usedSignatures 已接受的钱包签名消息，消息在其时间戳窗口结束前不能再次使用
+// 按签名消息而不是签名本身去重：同一消息可以构造出多个有效签名（ECDSA 签名的 s 值可取反）
+type usedSignatures struct {
+	mu   sync.Mutex
+	seen map[string]time.Time // lower(地址) + 消息 -> 过期时间
+}
+
+func newUsedSignatures() *usedSignatures {
+	return &usedSignatures{seen: make(map[string]time.Time)}
+}
+
+// use 记录签名消息，已使用过时返回 false；timestamp 为消息中的秒级时间戳，window 为允许的偏差
+func (u *usedSignatures) use(message, userAddress string, timestamp int64, window time.Duration) bool {
+	now := time.Now()
+	key := strings.ToLower(userAddress) + "\n" + message
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	for seenKey, expiresAt := range u.seen {
+		if now.After(expiresAt) {
+			delete(u.seen, seenKey)
+		}
+	}
+	if _, exists := u.seen[key]; exists {
+		return false
+	}
+	u.seen[key] = time.Unix(timestamp, 0).Add(window)
+	return true
+}
+
+// apiSession 获取请求鉴权的API密钥会话，未携带API密钥时返回nil
+func (h *Handler) apiSession(c *gin.Context) *session.Session {
+	if value, exists := c.Get("api_session"); exists {
+		return value.(*session.Session)
+	}
+	return nil
+}
+
+// authorizeUser 检查请求是否可以访问该用户的数据
+// 通过API密钥访问时只能访问密钥所属用户，不满足时写入403响应
+func (h *Handler) authorizeUser(c *gin.Context, userAddress string) bool {
+	if apiSession := h.apiSession(c); apiSession != nil && !strings.EqualFold(apiSession.UserAddress, userAddress) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "API key does not belong to this user", "code": CodePermissionDenied})
+		return false
+	}
+	return true
+}
+
+// cancelUserOrders 撤销用户的活跃订单，match 为空时撤销全部，返回撤销数量
+func (h *Handler) cancelUserOrders(userAddress string, match func(order *types.Order) bool) int {
+	orders, err := h.storage.GetActiveOrders("")
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to load active orders for cancel")
+		return 0
+	}
+
+	cancelled := 0
+	for _, order := range orders {
+		if !strings.EqualFold(order.UserAddress, userAddress) {
+			continue
+		}
+		if match != nil && !match(order) {
+			continue
+		}
+		// 与单笔撤单相同：未满最短停留时间的挂单保留
+		if err := h.cancelOrder(order); err != nil {
+			if !errors.Is(err, matching.ErrOrderNotResting) {
+				h.logger.WithError(err).WithField("order_id", order.ID.String()).Warn("Order not cancelled")
+			}
+			continue
+		}
+		cancelled++
+	}
+	return cancelled
+}
+
+// parseOptionalDuration 解析可选时长，空字符串返回0
+func parseOptionalDuration(value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration < 0 {
+		return 0, errors.New("invalid duration")
+	}
+	return duration, nil
+}
@@ -0,0 +1,267 @@
+package api
+
+import (
+	"crypto/ecdsa"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	gorilla "github.com/gorilla/websocket"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"orderbook-engine/internal/matching"
+	"orderbook-engine/internal/session"
+	"orderbook-engine/internal/storage"
+	"orderbook-engine/internal/types"
+	"orderbook-engine/internal/websocket"
+	"orderbook-engine/pkg/crypto"
+)
+
+var errOrderNotFound = errors.New("order not found")
+
+// memoryStorage 测试用订单存储，只实现处理器用到的方法
+type memoryStorage struct {
+	storage.Storage
+
+	mu     sync.Mutex
+	orders map[uuid.UUID]types.Order
+}
+
+func newMemoryStorage() *memoryStorage {
+	return &memoryStorage{orders: make(map[uuid.UUID]types.Order)}
+}
+
+func (s *memoryStorage) CreateOrder(order *types.Order) error {
+	return s.UpdateOrder(order)
+}
+
+func (s *memoryStorage) UpdateOrder(order *types.Order) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.orders[order.ID] = *order
+	return nil
+}
+
+func (s *memoryStorage) GetOrder(orderID uuid.UUID) (*types.Order, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	order, exists := s.orders[orderID]
+	if !exists {
+		return nil, errOrderNotFound
+	}
+	return &order, nil
+}
+
+func (s *memoryStorage) GetOrderByHash(hash string) (*types.Order, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, order := range s.orders {
+		if strings.EqualFold(order.Hash, hash) {
+			copied := order
+			return &copied, nil
+		}
+	}
+	return nil, errOrderNotFound
+}
+
+func (s *memoryStorage) GetActiveOrders(tradingPair string) ([]*types.Order, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var orders []*types.Order
+	for _, order := range s.orders {
+		if order.IsActive() && (tradingPair == "" || order.TradingPair == tradingPair) {
+			copied := order
+			orders = append(orders, &copied)
+		}
+	}
+	return orders, nil
+}
+
+// testWallet 测试钱包
+type testWallet struct {
+	key     *ecdsa.PrivateKey
+	address string
+}
+
+func newTestWallet(t *testing.T) *testWallet {
+	key, err := ethcrypto.GenerateKey()
+	require.NoError(t, err)
+	return &testWallet{key: key, address: ethcrypto.PubkeyToAddress(key.PublicKey).Hex()}
+}
+
+func newTestHandler(t *testing.T) (*Handler, *memoryStorage) {
+	gin.SetMode(gin.TestMode)
+	logger := logrus.New()
+	logger.SetLevel(logrus.WarnLevel)
+
+	store := newMemoryStorage()
+	handler := NewHandler(matching.NewMatchingEngine(logger), store, crypto.NewOrderSigner(big.NewInt(1), common.HexToAddress("0x1")), logger)
+	handler.SetAPIKeyAuth(false, time.Minute)
+	return handler, store
+}
+
+// restOrder 将订单挂入订单簿并写入存储
+func restOrder(t *testing.T, handler *Handler, store *memoryStorage, userAddress string) *types.Order {
+	order := &types.Order{
+		ID:          uuid.New(),
+		UserAddress: userAddress,
+		TradingPair: "WETH-USDC",
+		Side:        types.OrderSideBuy,
+		Type:        types.OrderTypeLimit,
+		Price:       decimal.NewFromInt(2000),
+		Amount:      decimal.NewFromInt(1),
+		Hash:        hex.EncodeToString(ethcrypto.Keccak256([]byte(uuid.NewString()))),
+		Status:      types.OrderStatusPending,
+		CreatedAt:   time.Now(),
+	}
+	_, err := handler.engine.AddOrder(order)
+	require.NoError(t, err)
+	require.NoError(t, store.CreateOrder(order))
+	return order
+}
+
+// signedManageRequest 钱包签名的会话管理请求
+func signedManageRequest(t *testing.T, w *testWallet, method, path, action, target string) *http.Request {
+	timestamp := time.Now().Unix()
+	signature, err := crypto.SignPersonalMessage(session.ManageMessage(w.address, action, target, timestamp), w.key)
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(method, path, nil)
+	req.Header.Set("X-Wallet-Timestamp", strconv.FormatInt(timestamp, 10))
+	req.Header.Set("X-Wallet-Signature", signature)
+	return req
+}
+
+func errorCode(t *testing.T, recorder *httptest.ResponseRecorder) string {
+	var body struct {
+		Code string `json:"code"`
+	}
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+	return body.Code
+}
+
+func TestWalletSignedManagementCannotBeReplayed(t *testing.T) {
+	handler, _ := newTestHandler(t)
+	handler.SetSessionRegistry(session.NewRegistry(logrus.New()))
+	router := gin.New()
+	router.GET("/account/sessions", handler.ListSessions)
+
+	w := newTestWallet(t)
+	req := signedManageRequest(t, w, http.MethodGet, "/account/sessions?user_address="+w.address, session.ActionListSessions, "")
+
+	first := httptest.NewRecorder()
+	router.ServeHTTP(first, req)
+	assert.Equal(t, http.StatusOK, first.Code)
+
+	// 同一签名在时间窗口内重放被拒绝
+	replay := httptest.NewRecorder()
+	router.ServeHTTP(replay, req.Clone(req.Context()))
+	assert.Equal(t, http.StatusBadRequest, replay.Code)
+	assert.Equal(t, CodeSignatureReused, errorCode(t, replay))
+
+	// 其他地址的签名不能管理该用户的会话
+	other := newTestWallet(t)
+	forged := signedManageRequest(t, other, http.MethodGet, "/account/sessions?user_address="+w.address, session.ActionListSessions, "")
+	rejected := httptest.NewRecorder()
+	router.ServeHTTP(rejected, forged)
+	assert.Equal(t, http.StatusUnauthorized, rejected.Code)
+	assert.Equal(t, CodeInvalidSignature, errorCode(t, rejected))
+}
+
+func TestRevokeCancelsAndPersistsOrders(t *testing.T) {
+	handler, store := newTestHandler(t)
+	registry := session.NewRegistry(logrus.New())
+	handler.SetSessionRegistry(registry)
+	router := gin.New()
+	router.DELETE("/account/sessions/:session_id", handler.RevokeSession)
+
+	w := newTestWallet(t)
+	apiSession, _, _, err := registry.CreateAPIKey(w.address, "bot", []session.Permission{session.PermissionTrade}, 0, true)
+	require.NoError(t, err)
+	order := restOrder(t, handler, store, w.address)
+
+	target := apiSession.ID.String()
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, signedManageRequest(t, w, http.MethodDelete, "/account/sessions/"+target+"?user_address="+w.address, session.ActionRevokeSession, target))
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+
+	// 撤单状态写入存储，不再是活跃订单
+	stored, err := store.GetOrder(order.ID)
+	require.NoError(t, err)
+	assert.Equal(t, types.OrderStatusCancelled, stored.Status)
+	assert.Empty(t, handler.engine.OpenOrders())
+	active, err := store.GetActiveOrders("")
+	require.NoError(t, err)
+	assert.Empty(t, active)
+}
+
+func TestUsedSignaturesExpire(t *testing.T) {
+	used := newUsedSignatures()
+	now := time.Now().Unix()
+
+	assert.True(t, used.use("message", "0xAbC", now, time.Minute))
+	assert.False(t, used.use("message", "0xabc", now, time.Minute))
+	assert.True(t, used.use("message", "0xdef", now, time.Minute))
+
+	// 窗口结束后的记录被清理
+	assert.True(t, used.use("old", "0xabc", now-120, time.Minute))
+	assert.True(t, used.use("old", "0xabc", now-120, time.Minute))
+}
+
+func TestWebSocketHandshakeUsesMiddlewareIdentity(t *testing.T) {
+	handler, _ := newTestHandler(t)
+	registry := session.NewRegistry(logrus.New())
+	handler.SetSessionRegistry(registry)
+	hub := websocket.NewHub(logrus.New())
+	hub.SetSessionRegistry(registry)
+	handler.SetWebSocketHub(hub)
+	go hub.Run()
+
+	router := gin.New()
+	router.Use(handler.APIKeyMiddleware())
+	router.GET("/ws", handler.WebSocketHandshake("admin-token"))
+	server := httptest.NewServer(router)
+	defer server.Close()
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/ws"
+
+	// 管理令牌错误时拒绝握手
+	_, resp, err := gorilla.DefaultDialer.Dial(url, http.Header{"X-Admin-Token": {"wrong"}})
+	require.Error(t, err)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	w := newTestWallet(t)
+	_, key, secret, err := registry.CreateAPIKey(w.address, "ws", []session.Permission{session.PermissionRead}, 0, false)
+	require.NoError(t, err)
+	timestamp := strconv.FormatInt(time.Now().UnixMilli(), 10)
+	conn, _, err := gorilla.DefaultDialer.Dial(url, http.Header{
+		"X-API-Key":       {key},
+		"X-API-Timestamp": {timestamp},
+		"X-API-Signature": {session.SignRequest(secret, timestamp, http.MethodGet, "/ws", nil)},
+		"X-Forwarded-For": {"203.0.113.7"},
+	})
+	require.NoError(t, err)
+	defer conn.Close()
+
+	// 连接身份与客户端IP取自中间件的解析结果
+	require.Eventually(t, func() bool { return len(hub.GetConnections()) == 1 }, time.Second, 10*time.Millisecond)
+	info := hub.GetConnections()[0]
+	assert.Equal(t, strings.ToLower(w.address), info.Identity.Address)
+	assert.False(t, info.Identity.Admin)
+	assert.Equal(t, "203.0.113.7", info.RemoteAddr)
+	assert.NotNil(t, info.SessionID)
+}
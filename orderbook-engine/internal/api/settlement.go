@@ -0,0 +1,189 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"orderbook-engine/internal/blockchain"
+	"orderbook-engine/internal/merkle"
+	"orderbook-engine/internal/settlement"
+	"orderbook-engine/internal/types"
+)
+
+// fillReader 按ID查询成交（存储实现可选支持）
+type fillReader interface {
+	GetFill(fillID uuid.UUID) (*types.Fill, error)
+}
+
+// SetSettlementPipeline 设置链上结算流水线
+func (h *Handler) SetSettlementPipeline(pipeline *settlement.Pipeline) {
+	h.settlement = pipeline
+}
+
+// AddSettlementManager 添加链上结算管理器（死信队列管理），每条启用结算的链一个
+func (h *Handler) AddSettlementManager(manager *blockchain.SettlementManager) {
+	h.settlementManagers = append(h.settlementManagers, manager)
+}
+
+// GetFillSettlement 查询单笔成交的链上结算状态
+// 在途成交返回流水线中的实时状态，其余从存储读取；未进入结算的成交状态为空
+func (h *Handler) GetFillSettlement(c *gin.Context) {
+	fillID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid fill ID", "code": CodeInvalidRequest})
+		return
+	}
+
+	var fill *types.Fill
+	if h.settlement != nil {
+		fill, _ = h.settlement.GetFill(fillID)
+	}
+	if fill == nil {
+		reader, ok := h.storage.(fillReader)
+		if !ok {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Fill lookup not supported by storage", "code": CodeFeatureDisabled})
+			return
+		}
+		if fill, err = reader.GetFill(fillID); err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Fill not found", "code": CodeNotFound})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"fill_id":           fill.ID,
+		"trading_pair":      fill.TradingPair,
+		"settlement_status": fill.SettlementStatus,
+		"tx_hash":           fill.TxHash,
+		"created_at":        fill.CreatedAt,
+	})
+}
+
+// SetMerkleProofStore 设置默克尔批次证明存储
+func (h *Handler) SetMerkleProofStore(store merkle.Store) {
+	h.merkleProofs = store
+}
+
+// GetFillProof 查询成交在已上链默克尔根中的包含证明，可用结算合约 verifyFillInclusion 核验
+func (h *Handler) GetFillProof(c *gin.Context) {
+	if h.merkleProofs == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Merkle settlement disabled", "code": CodeFeatureDisabled})
+		return
+	}
+
+	fillID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid fill ID", "code": CodeInvalidRequest})
+		return
+	}
+
+	proof, err := h.merkleProofs.FillProof(fillID)
+	if errors.Is(err, merkle.ErrProofNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Fill not settled in a merkle batch", "code": CodeNotFound})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load merkle proof", "code": CodeInternal, "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, proof)
+}
+
+// GetUnsettledFills 获取用户已成交但尚未上链确认的成交
+func (h *Handler) GetUnsettledFills(c *gin.Context) {
+	if h.settlement == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "On-chain settlement disabled", "code": CodeFeatureDisabled})
+		return
+	}
+
+	address := c.Param("address")
+	if !common.IsHexAddress(address) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid address", "code": CodeInvalidAddress})
+		return
+	}
+
+	fills := h.settlement.UnsettledFills(address)
+	c.JSON(http.StatusOK, gin.H{
+		"fills": fills,
+		"total": len(fills),
+	})
+}
+
+// GetSettlementDeadLetters 获取各链结算死信队列（管理接口）
+func (h *Handler) GetSettlementDeadLetters(c *gin.Context) {
+	if len(h.settlementManagers) == 0 {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "On-chain settlement disabled", "code": CodeFeatureDisabled})
+		return
+	}
+
+	deadLetters := make([]*blockchain.DeadLetter, 0)
+	for _, manager := range h.settlementManagers {
+		deadLetters = append(deadLetters, manager.DeadLetters()...)
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"dead_letters": deadLetters,
+		"total":        len(deadLetters),
+	})
+}
+
+// GetSettlementMetrics 获取各链已上链结算批次的Gas与calldata统计（管理接口）
+func (h *Handler) GetSettlementMetrics(c *gin.Context) {
+	if len(h.settlementManagers) == 0 {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "On-chain settlement disabled", "code": CodeFeatureDisabled})
+		return
+	}
+
+	metrics := make([]blockchain.BatchMetrics, 0, len(h.settlementManagers))
+	for _, manager := range h.settlementManagers {
+		metrics = append(metrics, manager.Metrics())
+	}
+	c.JSON(http.StatusOK, gin.H{"chains": metrics})
+}
+
+// RetrySettlementDeadLetter 重新提交死信结算项（管理接口）
+func (h *Handler) RetrySettlementDeadLetter(c *gin.Context) {
+	h.resolveDeadLetter(c, "retry", (*blockchain.SettlementManager).RetryDeadLetter)
+}
+
+// VoidSettlementDeadLetter 作废死信结算项（管理接口）
+func (h *Handler) VoidSettlementDeadLetter(c *gin.Context) {
+	h.resolveDeadLetter(c, "void", (*blockchain.SettlementManager).VoidDeadLetter)
+}
+
+func (h *Handler) resolveDeadLetter(c *gin.Context, action string, resolve func(manager *blockchain.SettlementManager, id uuid.UUID) error) {
+	if len(h.settlementManagers) == 0 {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "On-chain settlement disabled", "code": CodeFeatureDisabled})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid dead letter ID", "code": CodeInvalidRequest})
+		return
+	}
+
+	// 死信项只存在于其所属链的结算管理器中
+	err = blockchain.ErrDeadLetterNotFound
+	for _, manager := range h.settlementManagers {
+		if err = resolve(manager, id); !errors.Is(err, blockchain.ErrDeadLetterNotFound) {
+			break
+		}
+	}
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Dead letter not found", "code": CodeNotFound, "details": err.Error()})
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"dead_letter_id": id,
+		"action":         action,
+		"client_ip":      c.ClientIP(),
+	}).Info("Admin resolved settlement dead letter")
+
+	c.JSON(http.StatusOK, gin.H{"id": id, "action": action})
+}
@@ -0,0 +1,106 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"orderbook-engine/internal/audit"
+	"orderbook-engine/internal/blockchain"
+	"orderbook-engine/internal/txsigner"
+)
+
+// SettlerSignerFunc 由签名后端创建链的新结算签名者（读取当前配置的密钥）
+type SettlerSignerFunc func(chainID uint64) (txsigner.Signer, error)
+
+// SetKeyRotation 设置结算密钥轮换，timeout 为等待旧账户在途交易上链的最长时间
+func (h *Handler) SetKeyRotation(newSigner SettlerSignerFunc, timeout time.Duration) {
+	h.settlerSigner = newSigner
+	h.keyRotationTimeout = timeout
+}
+
+// RotateSettlementKey 轮换结算账户密钥（管理接口）
+// 先在签名后端准备好新密钥（更新配置中的 private_key、KMS key_id 或 Vault 中的新版本），再调用本接口切换
+func (h *Handler) RotateSettlementKey(c *gin.Context) {
+	if len(h.settlementManagers) == 0 || h.settlerSigner == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "On-chain settlement disabled", "code": CodeFeatureDisabled})
+		return
+	}
+
+	var req struct {
+		ChainID uint64 `json:"chain_id"` // 只有一条链启用结算时可省略
+	}
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid key rotation request", "code": CodeInvalidRequest, "details": err.Error()})
+		return
+	}
+
+	manager := h.settlementManagerFor(req.ChainID)
+	if manager == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "chain_id must name a chain with settlement enabled", "code": CodeInvalidRequest})
+		return
+	}
+
+	signer, err := h.settlerSigner(manager.ChainID())
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Failed to load new settlement key", "code": CodeServiceUnavailable, "details": err.Error()})
+		return
+	}
+
+	oldAddress := manager.SignerAddress()
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.keyRotationTimeout)
+	defer cancel()
+	rotation, err := manager.RotateSigner(ctx, signer)
+
+	entry := &audit.Entry{
+		ActorType: audit.ActorAdmin,
+		Actor:     c.ClientIP(),
+		Action:    audit.ActionSettlementKeyRotate,
+		Resource:  signer.Address().Hex(),
+		Details:   map[string]interface{}{"chain_id": manager.ChainID(), "old_address": oldAddress.Hex()},
+	}
+	if err != nil {
+		entry.Outcome = audit.OutcomeFailure
+		entry.Details["error"] = err.Error()
+		h.recordAudit(entry)
+
+		status, code := http.StatusServiceUnavailable, CodeServiceUnavailable
+		if errors.Is(err, blockchain.ErrSignerUnchanged) || errors.Is(err, blockchain.ErrSignerUnfunded) {
+			status, code = http.StatusConflict, CodeConflict
+		}
+		c.JSON(status, gin.H{"error": "Settlement key rotation failed", "code": code, "details": err.Error()})
+		return
+	}
+	h.recordAudit(entry)
+
+	h.logger.WithFields(logrus.Fields{
+		"chain_id":      rotation.ChainID,
+		"old_address":   rotation.OldAddress,
+		"new_address":   rotation.NewAddress,
+		"drained_nonce": rotation.DrainedNonce,
+		"client_ip":     c.ClientIP(),
+	}).Warn("Admin rotated settlement key")
+
+	c.JSON(http.StatusOK, rotation)
+}
+
+// settlementManagerFor 按链ID查找结算管理器，chainID 为 0 且只有一条链启用结算时返回该链
+func (h *Handler) settlementManagerFor(chainID uint64) *blockchain.SettlementManager {
+	if chainID == 0 {
+		if len(h.settlementManagers) == 1 {
+			return h.settlementManagers[0]
+		}
+		return nil
+	}
+	for _, manager := range h.settlementManagers {
+		if manager.ChainID() == chainID {
+			return manager
+		}
+	}
+	return nil
+}
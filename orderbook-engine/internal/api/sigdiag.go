@@ -0,0 +1,92 @@
+package api
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+
+	"orderbook-engine/internal/types"
+	"orderbook-engine/pkg/crypto"
+)
+
+// SetSignatureDiagnostics 设置签名校验失败时是否返回诊断信息
+// 诊断信息只包含可由请求公开计算的摘要与地址，但会暴露服务端的编码细节，生产环境建议关闭
+func (h *Handler) SetSignatureDiagnostics(enabled bool) {
+	h.signatureDiagnostics = enabled
+}
+
+// signatureMismatch 签名校验失败的错误响应，开启诊断时附带服务端计算的摘要、恢复出的地址与期望地址
+func (h *Handler) signatureMismatch(message string, digest common.Hash, signature, expected string, verifyErr error, extra gin.H) gin.H {
+	response := gin.H{"error": message, "code": CodeInvalidSignature}
+	if verifyErr != nil {
+		response["details"] = verifyErr.Error()
+	}
+	if !h.signatureDiagnostics {
+		return response
+	}
+
+	diagnostics := gin.H{
+		"digest":           digest.Hex(),
+		"expected_address": common.HexToAddress(expected).Hex(),
+	}
+	if recovered, err := crypto.RecoverAddress(digest, signature); err != nil {
+		diagnostics["recovery_error"] = err.Error()
+	} else {
+		diagnostics["recovered_address"] = recovered.Hex()
+	}
+	for key, value := range extra {
+		diagnostics[key] = value
+	}
+	response["diagnostics"] = diagnostics
+	return response
+}
+
+// orderSignatureMismatch 订单签名校验失败的错误响应
+// 诊断信息包含签名消息各字段的实际取值，并检查签名是否对应其他链的签名域（常见于钱包连错网络）
+func (h *Handler) orderSignatureMismatch(signer *crypto.OrderSigner, order *types.SignedOrder, verifyErr error) gin.H {
+	typed := crypto.TypedOrderFromSigned(order)
+	digest := signer.DigestTypedOrder(typed)
+
+	extra := gin.H{
+		"order_hash":       digest.Digest.Hex(),
+		"domain_separator": digest.DomainSeparator.Hex(),
+		"struct_hash":      digest.StructHash.Hex(),
+		"typed_data":       signer.OrderTypedData(typed),
+		"warnings":         orderSigningWarnings(order),
+	}
+	if h.signatureDiagnostics && h.chains != nil {
+		for _, chain := range h.chains.Chains() {
+			if chain.Signer == signer {
+				continue
+			}
+			if valid, err := chain.Signer.VerifyOrderSignature(order); err == nil && valid {
+				extra["signed_for_chain_id"] = chain.ChainID
+				break
+			}
+		}
+	}
+	return h.signatureMismatch("Invalid signature", digest.Digest, order.Signature, order.UserAddress, verifyErr, extra)
+}
+
+// cancelSignatureMismatch 签名撤单请求校验失败的错误响应
+func (h *Handler) cancelSignatureMismatch(signer *crypto.OrderSigner, cancel *types.SignedCancel, verifyErr error) gin.H {
+	digest, err := signer.HashCancel(cancel)
+	if err != nil {
+		return gin.H{"error": "Invalid cancel signature", "code": CodeInvalidSignature, "details": err.Error()}
+	}
+	return h.signatureMismatch("Invalid cancel signature", digest, cancel.Signature, cancel.UserAddress, verifyErr, gin.H{
+		"domain_separator": signer.DomainSeparator().Hex(),
+		"type_string":      crypto.CancelOrderTypeDef,
+	})
+}
+
+// nonceCancelSignatureMismatch 批量作废请求校验失败的错误响应
+func (h *Handler) nonceCancelSignatureMismatch(signer *crypto.OrderSigner, cancel *types.SignedNonceCancel, verifyErr error) gin.H {
+	digest, err := signer.HashNonceCancel(cancel)
+	if err != nil {
+		return gin.H{"error": "Invalid cancel signature", "code": CodeInvalidSignature, "details": err.Error()}
+	}
+	return h.signatureMismatch("Invalid cancel signature", digest, cancel.Signature, cancel.UserAddress, verifyErr, gin.H{
+		"domain_separator": signer.DomainSeparator().Hex(),
+		"type_string":      crypto.CancelUpToTypeDef,
+	})
+}
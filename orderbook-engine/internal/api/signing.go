@@ -0,0 +1,144 @@
+package api
+
+import (
+	"encoding/hex"
+	"net/http"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+
+	"orderbook-engine/internal/types"
+	"orderbook-engine/pkg/crypto"
+)
+
+// signingTypes 签名使用的 EIP-712 类型
+var signingTypes = map[string][]crypto.TypedDataField{
+	"EIP712Domain": crypto.DomainFields,
+	"Order":        crypto.OrderFields,
+	"CancelOrder":  crypto.CancelOrderFields,
+	"CancelUpTo":   crypto.CancelUpToFields,
+}
+
+// signingTypeStrings 规范类型字符串，keccak256 后即各类型的 TYPEHASH
+var signingTypeStrings = map[string]string{
+	"EIP712Domain": crypto.DomainTypeDef,
+	"Order":        crypto.OrderTypeDef,
+	"CancelOrder":  crypto.CancelOrderTypeDef,
+	"CancelUpTo":   crypto.CancelUpToTypeDef,
+}
+
+// GetSigningInfo 获取订单签名所需的 EIP-712 域、类型定义与字段编码规则
+// 可通过 chain_id 或 trading_pair 选择签名域，均为空时为默认链
+func (h *Handler) GetSigningInfo(c *gin.Context) {
+	var requested uint64
+	if value := c.Query("chain_id"); value != "" {
+		parsed, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid chain_id", "code": CodeInvalidRequest})
+			return
+		}
+		requested = parsed
+	}
+
+	var (
+		chainID uint64
+		signer  *crypto.OrderSigner
+		err     error
+	)
+	if tradingPair := c.Query("trading_pair"); tradingPair != "" {
+		chainID, signer, err = h.orderChain(tradingPair, requested)
+	} else {
+		chainID, signer, err = h.chainSigner(requested)
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid chain", "code": CodeChainMismatch, "details": err.Error()})
+		return
+	}
+
+	sides := make(map[types.OrderSide]uint8)
+	for _, side := range []types.OrderSide{types.OrderSideBuy, types.OrderSideSell} {
+		sides[side] = crypto.SideCode(side)
+	}
+	orderTypes := make(map[types.OrderType]uint8)
+	for _, orderType := range []types.OrderType{types.OrderTypeLimit, types.OrderTypeMarket, types.OrderTypeStopLoss, types.OrderTypeTakeProfit} {
+		orderTypes[orderType] = crypto.OrderTypeCode(orderType)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"chain_id":         chainID,
+		"domain":           signer.Domain(),
+		"domain_separator": signer.DomainSeparator().Hex(),
+		"primary_type":     "Order",
+		"types":            signingTypes,
+		"type_strings":     signingTypeStrings,
+		"encoding": gin.H{
+			"side":       sides,
+			"order_type": orderTypes,
+			"price":      "uint256 integer in quote token base units; fractional digits are truncated",
+			"amount":     "uint256 integer in base token base units; fractional digits are truncated",
+			"expires_at": "unix seconds, 0 when the order never expires",
+			"digest":     "keccak256(0x1901 || domain_separator || keccak256(abi.encode(ORDER_TYPEHASH, ...fields)))",
+			"signature":  "65-byte r || s || v hex, v may be 0/1 or 27/28",
+		},
+	})
+}
+
+// GetOrderDigest 计算未签名订单的签名摘要（调试接口）
+// 返回服务端实际校验的 typed data、结构体哈希与摘要，并提示会被静默改写的字段，便于前端排查签名不匹配
+func (h *Handler) GetOrderDigest(c *gin.Context) {
+	var order types.SignedOrder
+	if err := c.ShouldBindJSON(&order); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid order format", "code": CodeInvalidRequest, "details": err.Error()})
+		return
+	}
+
+	chainID, signer, err := h.orderChain(order.TradingPair, order.ChainID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid chain", "code": CodeChainMismatch, "details": err.Error()})
+		return
+	}
+
+	typed := crypto.TypedOrderFromSigned(&order)
+	digest := signer.DigestTypedOrder(typed)
+	c.JSON(http.StatusOK, gin.H{
+		"chain_id":         chainID,
+		"typed_data":       signer.OrderTypedData(typed),
+		"domain_separator": digest.DomainSeparator.Hex(),
+		"struct_hash":      digest.StructHash.Hex(),
+		"digest":           digest.Digest.Hex(),
+		"order_hash":       hex.EncodeToString(digest.Digest.Bytes()),
+		"warnings":         orderSigningWarnings(&order),
+	})
+}
+
+// orderSigningWarnings 检查签名编码时会被截断或按默认值处理的字段
+func orderSigningWarnings(order *types.SignedOrder) []string {
+	warnings := make([]string, 0)
+	for _, address := range []struct {
+		name  string
+		value string
+	}{{"user_address", order.UserAddress}, {"base_token", order.BaseToken}, {"quote_token", order.QuoteToken}} {
+		if !common.IsHexAddress(address.value) {
+			warnings = append(warnings, address.name+" is not a valid hex address")
+		}
+	}
+	if order.Side != types.OrderSideBuy && order.Side != types.OrderSideSell {
+		warnings = append(warnings, "side is neither buy nor sell and is encoded as 0 (buy)")
+	}
+	switch order.Type {
+	case types.OrderTypeLimit, types.OrderTypeMarket, types.OrderTypeStopLoss, types.OrderTypeTakeProfit:
+	default:
+		warnings = append(warnings, "type is not a known order type and is encoded as 0 (limit)")
+	}
+	if order.Price.IsNegative() || order.Amount.IsNegative() {
+		warnings = append(warnings, "negative price or amount is encoded by its absolute value")
+	}
+	if !order.Price.Equal(order.Price.Truncate(0)) {
+		warnings = append(warnings, "price has fractional digits that are truncated before hashing")
+	}
+	if !order.Amount.Equal(order.Amount.Truncate(0)) {
+		warnings = append(warnings, "amount has fractional digits that are truncated before hashing")
+	}
+	return warnings
+}
@@ -0,0 +1,83 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+
+	"orderbook-engine/internal/audit"
+)
+
+// depositSourceSimulation 模拟盘注入测试余额的充值来源
+const depositSourceSimulation = "simulation"
+
+// SetSimulation 设置模拟盘模式，maxSeed 为单次注入测试余额的上限（0表示不限）
+func (h *Handler) SetSimulation(enabled bool, maxSeed decimal.Decimal) {
+	h.simulation = enabled
+	h.simulationMaxSeed = maxSeed
+}
+
+// seedBalanceRequest 注入测试余额请求
+type seedBalanceRequest struct {
+	UserAddress string          `json:"user_address" binding:"required"`
+	Token       string          `json:"token" binding:"required"`
+	Amount      decimal.Decimal `json:"amount"`
+}
+
+// SeedBalance 模拟盘注入测试余额，以充值记录入账到虚拟账本
+// POST /api/v1/simulation/balances，仅在 simulation.enabled 时可用
+func (h *Handler) SeedBalance(c *gin.Context) {
+	if !h.simulation {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Simulation mode disabled", "code": CodeSimulationDisabled})
+		return
+	}
+	if h.balances == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Balance manager disabled", "code": CodeFeatureDisabled})
+		return
+	}
+
+	var req seedBalanceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid seed request", "code": CodeInvalidRequest, "details": err.Error()})
+		return
+	}
+	if !h.authorizeUser(c, req.UserAddress) {
+		return
+	}
+	if h.simulationMaxSeed.IsPositive() && req.Amount.GreaterThan(h.simulationMaxSeed) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Seed amount too large", "code": CodeInvalidRequest, "details": "amount exceeds simulation.max_seed_amount " + h.simulationMaxSeed.String()})
+		return
+	}
+
+	record, _, err := h.balances.CreditDeposit("sim-"+uuid.NewString(), req.UserAddress, req.Token, req.Amount, depositSourceSimulation)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid seed request", "code": CodeInvalidRequest, "details": err.Error()})
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"user":   record.UserAddress,
+		"token":  record.Token,
+		"amount": record.Amount.String(),
+	}).Info("Simulation balance seeded")
+	h.recordAudit(&audit.Entry{
+		ActorType: audit.ActorUser,
+		Actor:     record.UserAddress,
+		Action:    audit.ActionBalanceDeposit,
+		Resource:  record.UserAddress,
+		Details: map[string]interface{}{
+			"deposit_id": record.DepositID,
+			"token":      record.Token,
+			"amount":     record.Amount.String(),
+			"source":     record.Source,
+		},
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"deposit": record,
+		"balance": h.balances.GetTokenBalance(record.UserAddress, record.Token),
+	})
+}
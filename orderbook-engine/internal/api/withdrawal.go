@@ -0,0 +1,128 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"orderbook-engine/internal/compliance"
+	"orderbook-engine/internal/wallet"
+	"orderbook-engine/pkg/crypto"
+)
+
+// withdrawalRequest 用户签名的提现请求
+type withdrawalRequest struct {
+	WithdrawalID uuid.UUID       `json:"withdrawal_id" binding:"required"` // 客户端生成，重复提交同一ID只扣款一次
+	UserAddress  string          `json:"user_address" binding:"required"`
+	Token        string          `json:"token" binding:"required"`
+	Amount       decimal.Decimal `json:"amount"`
+	MaxFee       decimal.Decimal `json:"max_fee"`                      // 用户接受的最高手续费，取自报价
+	Timestamp    int64           `json:"timestamp" binding:"required"` // 签名时间（Unix秒）
+	Signature    string          `json:"signature" binding:"required"` // 钱包对 wallet.WithdrawalMessage 的 personal_sign 签名
+}
+
+// GetWithdrawalFees 获取各代币提现手续费与最小提现数量
+func (h *Handler) GetWithdrawalFees(c *gin.Context) {
+	if h.balances == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Balance manager disabled", "code": CodeFeatureDisabled})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"fees": h.balances.GetWithdrawalFeeConfigs(),
+	})
+}
+
+// QuoteWithdrawal 提现手续费报价（用户签名提现前调用）
+func (h *Handler) QuoteWithdrawal(c *gin.Context) {
+	if h.balances == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Balance manager disabled", "code": CodeFeatureDisabled})
+		return
+	}
+
+	userAddress := c.Query("user_address")
+	token := c.Query("token")
+	if userAddress == "" || token == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "User address and token required", "code": CodeInvalidRequest})
+		return
+	}
+
+	amount, err := decimal.NewFromString(c.Query("amount"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid amount", "code": CodeInvalidRequest})
+		return
+	}
+
+	quote, err := h.balances.QuoteWithdrawal(userAddress, token, amount)
+	if err != nil {
+		if quote == nil {
+			h.logger.WithError(err).Error("Failed to quote withdrawal")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to quote withdrawal", "code": CodeInternal, "details": err.Error()})
+			return
+		}
+		if errors.Is(err, compliance.ErrSanctioned) || errors.Is(err, compliance.ErrUnavailable) {
+			status, code, message := complianceErrorResponse(err)
+			c.JSON(status, gin.H{"error": message, "code": code, "details": err.Error(), "quote": quote})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Withdrawal not allowed", "code": CodeWithdrawalNotAllowed, "details": err.Error(), "quote": quote})
+		return
+	}
+
+	c.JSON(http.StatusOK, quote)
+}
+
+// RequestWithdrawal 扣减提现资金并收取手续费
+// 需要用户钱包签名确认数量与最高手续费，实时手续费超出 max_fee 时拒绝
+func (h *Handler) RequestWithdrawal(c *gin.Context) {
+	if h.balances == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Balance manager disabled", "code": CodeFeatureDisabled})
+		return
+	}
+
+	var req withdrawalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request", "code": CodeInvalidRequest, "details": err.Error()})
+		return
+	}
+	if !req.Amount.IsPositive() || req.MaxFee.IsNegative() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid amount", "code": CodeInvalidRequest})
+		return
+	}
+	if !h.authorizeUser(c, req.UserAddress) {
+		return
+	}
+
+	// API密钥不能代替钱包签名：提现金额与手续费上限必须由地址本人确认
+	if skew := time.Since(time.Unix(req.Timestamp, 0)); skew > h.apiKeyWindow || skew < -h.apiKeyWindow {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Signature expired", "code": CodeStaleTimestamp})
+		return
+	}
+	message := wallet.WithdrawalMessage(req.WithdrawalID, req.UserAddress, req.Token, req.Amount, req.MaxFee, req.Timestamp)
+	valid, err := crypto.VerifyPersonalSignature(message, req.Signature, req.UserAddress)
+	if err != nil || !valid {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid signature", "code": CodeInvalidSignature})
+		return
+	}
+
+	record, created, err := h.balances.Withdraw(req.WithdrawalID, req.UserAddress, req.Token, req.Amount, req.MaxFee)
+	if err != nil {
+		if errors.Is(err, compliance.ErrSanctioned) || errors.Is(err, compliance.ErrUnavailable) {
+			status, code, message := complianceErrorResponse(err)
+			c.JSON(status, gin.H{"error": message, "code": code, "details": err.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Withdrawal not allowed", "code": CodeWithdrawalNotAllowed, "details": err.Error()})
+		return
+	}
+
+	status := http.StatusCreated
+	if !created {
+		status = http.StatusOK
+	}
+	c.JSON(status, record)
+}
@@ -0,0 +1,141 @@
+// Package audit 审计日志
+// 记录每个改变系统状态的操作：谁（操作者）、做了什么（操作与对象）、何时、结果如何。
+// 记录只追加不修改，供合规审查按操作者、操作类型、对象和时间范围查询
+package audit
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// 操作者类型
+const (
+	ActorUser   = "user"   // 用户，Actor 为钱包地址
+	ActorAdmin  = "admin"  // 管理员，Actor 为请求来源IP
+	ActorSystem = "system" // 系统组件，Actor 为组件名
+)
+
+// 操作类型
+const (
+	ActionOrderPlace            = "order.place"
+	ActionOrderCancel           = "order.cancel"
+	ActionOrderCancelBelowNonce = "order.cancel_below_nonce"
+	ActionAdminRequest          = "admin.request"
+	ActionBlacklistAdd          = "blacklist.add"
+	ActionBlacklistRemove       = "blacklist.remove"
+	ActionSettlementSubmit      = "settlement.submit"
+	ActionSettlementReject      = "settlement.reject"
+	ActionSettlementKeyRotate   = "settlement.key_rotate"
+	ActionBalanceDeposit        = "balance.deposit"
+	ActionReferralRegister      = "referral.register"
+	ActionReferralClaim         = "referral.claim"
+	ActionMMObligationBreach    = "mm_obligation.breach"
+	ActionAccountFreeze         = "account.freeze"
+	ActionAccountUnfreeze       = "account.unfreeze"
+	ActionSystemHalt            = "system.halt"
+	ActionSystemResume          = "system.resume"
+	ActionPairList              = "pair.list"
+	ActionPairAuction           = "pair.auction"
+	ActionPairHalt              = "pair.halt"
+	ActionPairDelist            = "pair.delist"
+	ActionPairMinResting        = "pair.min_resting"
+)
+
+// 操作结果
+const (
+	OutcomeSuccess = "success"
+	OutcomeFailure = "failure"
+)
+
+// Entry 审计记录
+type Entry struct {
+	ID        uuid.UUID              `json:"id"`
+	Timestamp time.Time              `json:"timestamp"`
+	ActorType string                 `json:"actor_type"`
+	Actor     string                 `json:"actor"`
+	Action    string                 `json:"action"`
+	Resource  string                 `json:"resource,omitempty"` // 操作对象，如订单ID、用户地址、请求路径
+	Outcome   string                 `json:"outcome"`
+	Details   map[string]interface{} `json:"details,omitempty"`
+}
+
+// Query 查询条件，为空的条件不过滤
+type Query struct {
+	ActorType string
+	Actor     string
+	Action    string
+	Resource  string
+	From      *time.Time
+	To        *time.Time
+	Limit     int
+}
+
+// Store 审计记录存储，只支持追加
+type Store interface {
+	Append(entry *Entry) error
+	// Query 按时间倒序返回匹配的记录
+	Query(query Query) ([]*Entry, error)
+}
+
+// Recorder 审计记录器
+type Recorder struct {
+	store  Store
+	logger *logrus.Logger
+}
+
+// NewRecorder 创建审计记录器
+func NewRecorder(store Store, logger *logrus.Logger) *Recorder {
+	return &Recorder{
+		store:  store,
+		logger: logger,
+	}
+}
+
+// Record 补全记录ID、时间与结果后写入存储
+// 写入失败只记录错误日志，不影响已经完成的业务操作
+func (r *Recorder) Record(entry *Entry) {
+	if entry.ID == uuid.Nil {
+		entry.ID = uuid.New()
+	}
+	if entry.Timestamp.IsZero() {
+		entry.Timestamp = time.Now()
+	}
+	if entry.Outcome == "" {
+		entry.Outcome = OutcomeSuccess
+	}
+
+	if err := r.store.Append(entry); err != nil {
+		r.logger.WithError(err).WithFields(logrus.Fields{
+			"action":   entry.Action,
+			"actor":    entry.Actor,
+			"resource": entry.Resource,
+		}).Error("Failed to write audit entry")
+	}
+}
+
+// Query 查询审计记录
+func (r *Recorder) Query(query Query) ([]*Entry, error) {
+	return r.store.Query(query)
+}
+
+// matches 记录是否满足查询条件
+func (q *Query) matches(entry *Entry) bool {
+	switch {
+	case q.ActorType != "" && entry.ActorType != q.ActorType:
+		return false
+	case q.Actor != "" && !strings.EqualFold(entry.Actor, q.Actor):
+		return false
+	case q.Action != "" && entry.Action != q.Action:
+		return false
+	case q.Resource != "" && entry.Resource != q.Resource:
+		return false
+	case q.From != nil && entry.Timestamp.Before(*q.From):
+		return false
+	case q.To != nil && entry.Timestamp.After(*q.To):
+		return false
+	}
+	return true
+}
@@ -0,0 +1,53 @@
+package audit
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type failingStore struct {
+	MemoryStore
+}
+
+func (s *failingStore) Append(entry *Entry) error {
+	return errors.New("store unavailable")
+}
+
+func TestRecorderQuery(t *testing.T) {
+	recorder := NewRecorder(NewMemoryStore(), logrus.New())
+
+	recorder.Record(&Entry{ActorType: ActorUser, Actor: "0xAbC", Action: ActionOrderPlace, Resource: "order-1"})
+	recorder.Record(&Entry{ActorType: ActorUser, Actor: "0xabc", Action: ActionOrderCancel, Resource: "order-1"})
+	recorder.Record(&Entry{ActorType: ActorAdmin, Actor: "10.0.0.1", Action: ActionAdminRequest, Outcome: OutcomeFailure})
+
+	// 按时间倒序返回，操作者地址不区分大小写
+	entries, err := recorder.Query(Query{Actor: "0xABC"})
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, ActionOrderCancel, entries[0].Action)
+	assert.Equal(t, OutcomeSuccess, entries[0].Outcome)
+	assert.NotZero(t, entries[0].ID)
+
+	entries, err = recorder.Query(Query{Resource: "order-1", Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, ActionOrderCancel, entries[0].Action)
+
+	future := time.Now().Add(time.Hour)
+	entries, err = recorder.Query(Query{From: &future})
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	entries, err = recorder.Query(Query{ActorType: ActorAdmin})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, OutcomeFailure, entries[0].Outcome)
+
+	// 存储写入失败不影响调用方
+	NewRecorder(&failingStore{}, logrus.New()).Record(&Entry{Action: ActionOrderPlace})
+}
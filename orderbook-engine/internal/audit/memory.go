@@ -0,0 +1,44 @@
+package audit
+
+import (
+	"sync"
+)
+
+// MemoryStore 内存审计存储，进程重启后丢失，仅用于开发和测试
+type MemoryStore struct {
+	mu      sync.RWMutex
+	entries []*Entry
+}
+
+// NewMemoryStore 创建内存审计存储
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{}
+}
+
+// Append 追加记录
+func (s *MemoryStore) Append(entry *Entry) error {
+	copied := *entry
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = append(s.entries, &copied)
+	return nil
+}
+
+// Query 按时间倒序返回匹配的记录
+func (s *MemoryStore) Query(query Query) ([]*Entry, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var result []*Entry
+	for i := len(s.entries) - 1; i >= 0; i-- {
+		if query.Limit > 0 && len(result) >= query.Limit {
+			break
+		}
+		if query.matches(s.entries[i]) {
+			copied := *s.entries[i]
+			result = append(result, &copied)
+		}
+	}
+	return result, nil
+}
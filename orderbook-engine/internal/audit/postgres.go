@@ -0,0 +1,140 @@
+package audit
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	_ "github.com/lib/pq"
+)
+
+// auditSchema 审计表，规则禁止更新和删除，保证记录只追加
+var auditSchema = []string{
+	`CREATE TABLE IF NOT EXISTS audit_log (
+		id UUID PRIMARY KEY,
+		timestamp TIMESTAMPTZ NOT NULL,
+		actor_type TEXT NOT NULL,
+		actor TEXT NOT NULL,
+		action TEXT NOT NULL,
+		resource TEXT NOT NULL DEFAULT '',
+		outcome TEXT NOT NULL,
+		details JSONB
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_audit_log_timestamp ON audit_log (timestamp)`,
+	`CREATE INDEX IF NOT EXISTS idx_audit_log_actor ON audit_log (lower(actor), timestamp)`,
+	`CREATE INDEX IF NOT EXISTS idx_audit_log_action ON audit_log (action, timestamp)`,
+	`CREATE INDEX IF NOT EXISTS idx_audit_log_resource ON audit_log (resource, timestamp)`,
+	`CREATE OR REPLACE RULE audit_log_no_update AS ON UPDATE TO audit_log DO INSTEAD NOTHING`,
+	`CREATE OR REPLACE RULE audit_log_no_delete AS ON DELETE TO audit_log DO INSTEAD NOTHING`,
+}
+
+// PostgresStore 基于 PostgreSQL 的审计存储
+type PostgresStore struct {
+	db *sql.DB
+}
+
+// NewPostgresStore 创建 PostgreSQL 审计存储并确保表结构存在
+func NewPostgresStore(dsn string) (*PostgresStore, error) {
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit database: %w", err)
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to connect to audit database: %w", err)
+	}
+
+	for _, stmt := range auditSchema {
+		if _, err := db.Exec(stmt); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("failed to create audit schema: %w", err)
+		}
+	}
+	return &PostgresStore{db: db}, nil
+}
+
+// Close 关闭数据库连接
+func (s *PostgresStore) Close() error {
+	return s.db.Close()
+}
+
+// Append 追加记录
+func (s *PostgresStore) Append(entry *Entry) error {
+	var details []byte
+	if len(entry.Details) > 0 {
+		var err error
+		if details, err = json.Marshal(entry.Details); err != nil {
+			return fmt.Errorf("failed to encode audit details: %w", err)
+		}
+	}
+
+	_, err := s.db.Exec(
+		`INSERT INTO audit_log (id, timestamp, actor_type, actor, action, resource, outcome, details)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		entry.ID, entry.Timestamp, entry.ActorType, entry.Actor, entry.Action, entry.Resource, entry.Outcome, details)
+	if err != nil {
+		return fmt.Errorf("failed to append audit entry: %w", err)
+	}
+	return nil
+}
+
+// Query 按时间倒序返回匹配的记录
+func (s *PostgresStore) Query(query Query) ([]*Entry, error) {
+	var conditions []string
+	var args []interface{}
+	where := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+	if query.ActorType != "" {
+		where("actor_type = $%d", query.ActorType)
+	}
+	if query.Actor != "" {
+		where("lower(actor) = lower($%d)", query.Actor)
+	}
+	if query.Action != "" {
+		where("action = $%d", query.Action)
+	}
+	if query.Resource != "" {
+		where("resource = $%d", query.Resource)
+	}
+	if query.From != nil {
+		where("timestamp >= $%d", *query.From)
+	}
+	if query.To != nil {
+		where("timestamp <= $%d", *query.To)
+	}
+
+	stmt := `SELECT id, timestamp, actor_type, actor, action, resource, outcome, details FROM audit_log`
+	if len(conditions) > 0 {
+		stmt += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	stmt += " ORDER BY timestamp DESC"
+	if query.Limit > 0 {
+		args = append(args, query.Limit)
+		stmt += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+
+	rows, err := s.db.Query(stmt, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query audit log: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*Entry
+	for rows.Next() {
+		entry := &Entry{}
+		var details []byte
+		if err := rows.Scan(&entry.ID, &entry.Timestamp, &entry.ActorType, &entry.Actor, &entry.Action, &entry.Resource, &entry.Outcome, &details); err != nil {
+			return nil, fmt.Errorf("failed to scan audit entry: %w", err)
+		}
+		if len(details) > 0 {
+			if err := json.Unmarshal(details, &entry.Details); err != nil {
+				return nil, fmt.Errorf("failed to decode audit details: %w", err)
+			}
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
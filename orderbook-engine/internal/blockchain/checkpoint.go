@@ -0,0 +1,96 @@
+package blockchain
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// LogPosition 日志在链上的位置
+type LogPosition struct {
+	Block uint64 `json:"block"`
+	Index int    `json:"index"` // 区块内日志索引，-1 表示该区块尚未处理任何日志
+}
+
+// positionOf 获取日志位置
+func positionOf(vLog types.Log) LogPosition {
+	return LogPosition{Block: vLog.BlockNumber, Index: int(vLog.Index)}
+}
+
+// Before 是否位于另一位置之前
+func (p LogPosition) Before(other LogPosition) bool {
+	if p.Block != other.Block {
+		return p.Block < other.Block
+	}
+	return p.Index < other.Index
+}
+
+// EventCheckpoint 链上事件处理进度存储，记录最后一条已处理日志的位置
+type EventCheckpoint interface {
+	Load(chainID uint64, stream string) (LogPosition, bool, error)
+	Save(chainID uint64, stream string, position LogPosition) error
+}
+
+// FileCheckpoint 基于JSON文件的事件处理进度
+type FileCheckpoint struct {
+	mu        sync.Mutex
+	path      string
+	positions map[string]LogPosition
+}
+
+// NewFileCheckpoint 创建文件事件处理进度，文件不存在时从空进度开始
+func NewFileCheckpoint(path string) (*FileCheckpoint, error) {
+	cp := &FileCheckpoint{
+		path:      path,
+		positions: make(map[string]LogPosition),
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return cp, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read event checkpoint: %w", err)
+	}
+	if err := json.Unmarshal(data, &cp.positions); err != nil {
+		return nil, fmt.Errorf("failed to parse event checkpoint %s: %w", path, err)
+	}
+	return cp, nil
+}
+
+// Load 读取处理进度
+func (cp *FileCheckpoint) Load(chainID uint64, stream string) (LogPosition, bool, error) {
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	position, exists := cp.positions[checkpointKey(chainID, stream)]
+	return position, exists, nil
+}
+
+// Save 保存处理进度（先写临时文件再替换，避免写入中断损坏进度）
+func (cp *FileCheckpoint) Save(chainID uint64, stream string, position LogPosition) error {
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+
+	cp.positions[checkpointKey(chainID, stream)] = position
+	data, err := json.MarshalIndent(cp.positions, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(cp.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create checkpoint directory: %w", err)
+	}
+	tmp := cp.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write event checkpoint: %w", err)
+	}
+	return os.Rename(tmp, cp.path)
+}
+
+func checkpointKey(chainID uint64, stream string) string {
+	return fmt.Sprintf("%d:%s", chainID, stream)
+}
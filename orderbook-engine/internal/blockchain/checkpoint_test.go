@@ -0,0 +1,61 @@
+package blockchain
+
+import (
+	"context"
+	"math/big"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileCheckpointPersistsAndDeduplicates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoints.json")
+	cp, err := NewFileCheckpoint(path)
+	require.NoError(t, err)
+
+	_, exists, err := cp.Load(1, orderEventStream)
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	orderBookABI, err := parseOrderBookABI()
+	require.NoError(t, err)
+	client := &Client{chainID: big.NewInt(1), logger: logrus.New(), eventCheckpoint: cp, orderBookABI: orderBookABI}
+
+	// 提交旧位置不会回退进度
+	client.CommitOrderEvent(&OrderEvent{BlockNumber: 10, LogIndex: 2})
+	client.CommitOrderEvent(&OrderEvent{BlockNumber: 9, LogIndex: 5})
+
+	reloaded, err := NewFileCheckpoint(path)
+	require.NoError(t, err)
+	position, exists, err := reloaded.Load(1, orderEventStream)
+	require.NoError(t, err)
+	require.True(t, exists)
+	assert.Equal(t, LogPosition{Block: 10, Index: 2}, position)
+
+	// 补齐与订阅重叠的日志只投递一次
+	data, err := orderBookABI.Events["OrderPlaced"].Inputs.NonIndexed().Pack(
+		common.Address{1}, common.Address{2}, common.Address{3},
+		big.NewInt(2000), big.NewInt(1), true, uint8(0), big.NewInt(1700000000),
+	)
+	require.NoError(t, err)
+
+	events := make(chan *OrderEvent, 4)
+	last := position
+	for _, vLog := range []types.Log{
+		{BlockNumber: 10, Index: 2, Data: data},
+		{BlockNumber: 10, Index: 3, Data: data, Removed: true},
+		{BlockNumber: 11, Index: 0, Data: data},
+		{BlockNumber: 11, Index: 0, Data: data},
+	} {
+		require.NoError(t, client.deliverOrderEvent(context.Background(), vLog, events, &last))
+	}
+	require.Len(t, events, 1)
+	event := <-events
+	assert.Equal(t, uint64(11), event.BlockNumber)
+	assert.Equal(t, LogPosition{Block: 11, Index: 0}, last)
+}
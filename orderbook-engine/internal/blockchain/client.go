@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"math/big"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi"
@@ -21,7 +23,7 @@ import (
 type Client struct {
 	client           *ethclient.Client
 	chainID          *big.Int
-	privateKey       *ecdsa.PrivateKey
+	privateKey       *ecdsa.PrivateKey // 为空时客户端只读（结算签名由 KMS/Vault 等后端完成）
 	address          common.Address
 	orderBookAddress common.Address
 	settlementAddress common.Address
@@ -29,10 +31,33 @@ type Client struct {
 	
 	orderBookABI abi.ABI
 	settlementABI abi.ABI
+
+	rpcURL              string
+	eventCheckpoint     EventCheckpoint // 为空时进度仅保存在内存
+	reconnectBackoff    time.Duration
+	reconnectMaxBackoff time.Duration
+	eventMu             sync.Mutex
+	pendingEvents       int         // 已投递、尚未确认处理的事件数
+	committed           LogPosition // 最后一条已确认处理的日志
+	hasCommitted        bool
+	live                chan struct{} // 首次完成补齐并进入实时订阅后关闭
+	liveOnce            sync.Once
+	onConnectivity      func(connected bool, err error) // 可选，事件订阅进入实时或断开时回调
 }
 
+// OrderEventKind 订单事件类型
+type OrderEventKind string
+
+const (
+	OrderEventPlaced    OrderEventKind = "placed"    // OrderPlaced：链上下单
+	OrderEventCancelled OrderEventKind = "cancelled" // OrderCancelled：用户在链上撤单
+	OrderEventFilled    OrderEventKind = "filled"    // OrderFilled：订单在链上完全成交
+)
+
 // OrderEvent 订单事件
+// 撤单与成交事件只有 OrderID（撤单另有 Trader），其余字段为零值
 type OrderEvent struct {
+	Kind        OrderEventKind
 	OrderID     *big.Int
 	Trader      common.Address
 	TokenA      common.Address
@@ -42,6 +67,10 @@ type OrderEvent struct {
 	IsBuy       bool
 	OrderType   uint8
 	Timestamp   uint64
+
+	BlockNumber uint64
+	LogIndex    uint
+	TxHash      common.Hash
 }
 
 // TradeEvent 交易事件
@@ -64,19 +93,23 @@ func NewClient(rpcURL string, chainID *big.Int, privateKeyHex string, orderBookA
 		return nil, fmt.Errorf("failed to connect to ethereum node: %v", err)
 	}
 
-	// 解析私钥
-	privateKey, err := crypto.HexToECDSA(strings.TrimPrefix(privateKeyHex, "0x"))
-	if err != nil {
-		return nil, fmt.Errorf("invalid private key: %v", err)
-	}
+	// 解析私钥，未配置时客户端只读
+	var privateKey *ecdsa.PrivateKey
+	var address common.Address
+	if privateKeyHex != "" {
+		privateKey, err = crypto.HexToECDSA(strings.TrimPrefix(privateKeyHex, "0x"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid private key: %v", err)
+		}
 
-	// 获取地址
-	publicKey := privateKey.Public()
-	publicKeyECDSA, ok := publicKey.(*ecdsa.PublicKey)
-	if !ok {
-		return nil, fmt.Errorf("cannot assert type: publicKey is not of type *ecdsa.PublicKey")
+		// 获取地址
+		publicKey := privateKey.Public()
+		publicKeyECDSA, ok := publicKey.(*ecdsa.PublicKey)
+		if !ok {
+			return nil, fmt.Errorf("cannot assert type: publicKey is not of type *ecdsa.PublicKey")
+		}
+		address = crypto.PubkeyToAddress(*publicKeyECDSA)
 	}
-	address := crypto.PubkeyToAddress(*publicKeyECDSA)
 
 	// 解析ABI
 	orderBookABI, err := parseOrderBookABI()
@@ -99,6 +132,11 @@ func NewClient(rpcURL string, chainID *big.Int, privateKeyHex string, orderBookA
 		logger:            logger,
 		orderBookABI:      orderBookABI,
 		settlementABI:     settlementABI,
+
+		rpcURL:              rpcURL,
+		reconnectBackoff:    time.Second,
+		reconnectMaxBackoff: time.Minute,
+		live:                make(chan struct{}),
 	}, nil
 }
 
@@ -145,6 +183,70 @@ func (c *Client) ExecuteTrade(buyer, seller common.Address, tokenA, tokenB commo
 	return signedTx, nil
 }
 
+// GetUserNonce 读取Settlement合约中用户的最小有效nonce（userNonces）
+func (c *Client) GetUserNonce(ctx context.Context, userAddress string) (uint64, error) {
+	data, err := c.settlementABI.Pack("userNonces", common.HexToAddress(userAddress))
+	if err != nil {
+		return 0, fmt.Errorf("failed to pack call data: %v", err)
+	}
+
+	result, err := c.client.CallContract(ctx, ethereum.CallMsg{To: &c.settlementAddress, Data: data}, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to call userNonces: %v", err)
+	}
+
+	values, err := c.settlementABI.Unpack("userNonces", result)
+	if err != nil || len(values) != 1 {
+		return 0, fmt.Errorf("failed to unpack userNonces: %v", err)
+	}
+	nonce, ok := values[0].(*big.Int)
+	if !ok || !nonce.IsUint64() {
+		return 0, fmt.Errorf("unexpected userNonces value: %v", values[0])
+	}
+	return nonce.Uint64(), nil
+}
+
+// SetUserFeeRates 设置Settlement合约中用户的 maker/taker 费率（基点），0 表示使用合约默认费率
+func (c *Client) SetUserFeeRates(user common.Address, makerRate, takerRate *big.Int) (*types.Transaction, error) {
+	auth, err := c.getTransactOpts()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := c.settlementABI.Pack("setUserFeeRates", user, makerRate, takerRate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pack transaction data: %v", err)
+	}
+
+	tx := types.NewTransaction(
+		auth.Nonce.Uint64(),
+		c.settlementAddress,
+		big.NewInt(0),
+		auth.GasLimit,
+		auth.GasPrice,
+		data,
+	)
+
+	signedTx, err := types.SignTx(tx, types.NewEIP155Signer(c.chainID), c.privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign transaction: %v", err)
+	}
+
+	err = c.client.SendTransaction(context.Background(), signedTx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send transaction: %v", err)
+	}
+
+	c.logger.WithFields(logrus.Fields{
+		"tx_hash":    signedTx.Hash().Hex(),
+		"user":       user.Hex(),
+		"maker_rate": makerRate.String(),
+		"taker_rate": takerRate.String(),
+	}).Info("Fee rate transaction sent")
+
+	return signedTx, nil
+}
+
 // UpdateOrderStatus 更新订单状态
 func (c *Client) UpdateOrderStatus(orderID *big.Int, status uint8, filledAmount *big.Int) (*types.Transaction, error) {
 	auth, err := c.getTransactOpts()
@@ -180,50 +282,31 @@ func (c *Client) UpdateOrderStatus(orderID *big.Int, status uint8, filledAmount
 }
 
 // SubscribeToOrderEvents 监听订单事件
+// 订阅断开后按指数退避自动重连，重连时先用 eth_getLogs 补齐断线期间的事件，消费方处理完事件后需调用 CommitOrderEvent
 func (c *Client) SubscribeToOrderEvents(ctx context.Context, eventChan chan<- *OrderEvent) error {
-	query := ethereum.FilterQuery{
-		Addresses: []common.Address{c.orderBookAddress},
-		Topics: [][]common.Hash{
-			{crypto.Keccak256Hash([]byte("OrderPlaced(uint256,address,address,address,uint256,uint256,bool,uint8,uint256)"))},
-		},
-	}
-
-	logs := make(chan types.Log)
-	sub, err := c.client.SubscribeFilterLogs(ctx, query, logs)
-	if err != nil {
-		return fmt.Errorf("failed to subscribe to logs: %v", err)
-	}
-
-	go func() {
-		defer sub.Unsubscribe()
-		for {
-			select {
-			case err := <-sub.Err():
-				c.logger.WithError(err).Error("Subscription error")
-				return
-			case vLog := <-logs:
-				event, err := c.parseOrderEvent(vLog)
-				if err != nil {
-					c.logger.WithError(err).Error("Failed to parse order event")
-					continue
-				}
-				
-				select {
-				case eventChan <- event:
-				case <-ctx.Done():
-					return
-				}
-			case <-ctx.Done():
-				return
-			}
+	var last *LogPosition
+	if c.eventCheckpoint != nil {
+		position, exists, err := c.eventCheckpoint.Load(c.ChainID(), orderEventStream)
+		if err != nil {
+			return fmt.Errorf("failed to load event checkpoint: %v", err)
+		}
+		if exists {
+			last = &position
+			c.eventMu.Lock()
+			c.committed, c.hasCommitted = position, true
+			c.eventMu.Unlock()
 		}
-	}()
+	}
 
+	go c.runOrderEventStream(ctx, eventChan, last)
 	return nil
 }
 
 // getTransactOpts 获取交易选项
 func (c *Client) getTransactOpts() (*bind.TransactOpts, error) {
+	if c.privateKey == nil {
+		return nil, fmt.Errorf("blockchain client has no signing key")
+	}
 	nonce, err := c.client.PendingNonceAt(context.Background(), c.address)
 	if err != nil {
 		return nil, err
@@ -247,15 +330,58 @@ func (c *Client) getTransactOpts() (*bind.TransactOpts, error) {
 	return auth, nil
 }
 
-// parseOrderEvent 解析订单事件
+// parseOrderEvent 解析订单事件，按事件签名区分下单、撤单与成交
 func (c *Client) parseOrderEvent(vLog types.Log) (*OrderEvent, error) {
-	event := &OrderEvent{}
-	
-	err := c.orderBookABI.UnpackIntoInterface(event, "OrderPlaced", vLog.Data)
+	if len(vLog.Topics) > 0 {
+		switch vLog.Topics[0] {
+		case orderCancelledTopic:
+			if len(vLog.Topics) < 3 {
+				return nil, fmt.Errorf("OrderCancelled log missing indexed topics")
+			}
+			return &OrderEvent{
+				Kind:    OrderEventCancelled,
+				OrderID: new(big.Int).SetBytes(vLog.Topics[1].Bytes()),
+				Trader:  common.BytesToAddress(vLog.Topics[2].Bytes()),
+			}, nil
+		case orderFilledTopic:
+			if len(vLog.Topics) < 2 {
+				return nil, fmt.Errorf("OrderFilled log missing indexed topics")
+			}
+			return &OrderEvent{
+				Kind:    OrderEventFilled,
+				OrderID: new(big.Int).SetBytes(vLog.Topics[1].Bytes()),
+			}, nil
+		}
+	}
+
+	// 合约中 timestamp 为 uint256，先解码为 *big.Int 再转换
+	var raw struct {
+		Trader    common.Address
+		TokenA    common.Address
+		TokenB    common.Address
+		Price     *big.Int
+		Amount    *big.Int
+		IsBuy     bool
+		OrderType uint8
+		Timestamp *big.Int
+	}
+	err := c.orderBookABI.UnpackIntoInterface(&raw, "OrderPlaced", vLog.Data)
 	if err != nil {
 		return nil, err
 	}
 
+	event := &OrderEvent{
+		Kind:      OrderEventPlaced,
+		Trader:    raw.Trader,
+		TokenA:    raw.TokenA,
+		TokenB:    raw.TokenB,
+		Price:     raw.Price,
+		Amount:    raw.Amount,
+		IsBuy:     raw.IsBuy,
+		OrderType: raw.OrderType,
+		Timestamp: raw.Timestamp.Uint64(),
+	}
+
 	// 从topics中提取indexed参数
 	if len(vLog.Topics) > 1 {
 		event.OrderID = new(big.Int).SetBytes(vLog.Topics[1].Bytes())
@@ -282,6 +408,21 @@ func parseOrderBookABI() (abi.ABI, error) {
 			"name": "OrderPlaced",
 			"type": "event"
 		},
+		{
+			"inputs": [
+				{"indexed": true, "internalType": "uint256", "name": "orderId", "type": "uint256"},
+				{"indexed": true, "internalType": "address", "name": "trader", "type": "address"}
+			],
+			"name": "OrderCancelled",
+			"type": "event"
+		},
+		{
+			"inputs": [
+				{"indexed": true, "internalType": "uint256", "name": "orderId", "type": "uint256"}
+			],
+			"name": "OrderFilled",
+			"type": "event"
+		},
 		{
 			"inputs": [
 				{"internalType": "uint256", "name": "orderId", "type": "uint256"},
@@ -315,12 +456,40 @@ func parseSettlementABI() (abi.ABI, error) {
 			"outputs": [],
 			"stateMutability": "nonpayable",
 			"type": "function"
+		},
+		{
+			"inputs": [
+				{"internalType": "address", "name": "user", "type": "address"},
+				{"internalType": "uint256", "name": "makerRate", "type": "uint256"},
+				{"internalType": "uint256", "name": "takerRate", "type": "uint256"}
+			],
+			"name": "setUserFeeRates",
+			"outputs": [],
+			"stateMutability": "nonpayable",
+			"type": "function"
+		},
+		{
+			"inputs": [{"internalType": "address", "name": "", "type": "address"}],
+			"name": "userNonces",
+			"outputs": [{"internalType": "uint256", "name": "", "type": "uint256"}],
+			"stateMutability": "view",
+			"type": "function"
 		}
 	]`
 	
 	return abi.JSON(strings.NewReader(abiJSON))
 }
 
+// ChainID 获取链ID
+func (c *Client) ChainID() uint64 {
+	return c.chainID.Uint64()
+}
+
+// Backend 返回底层以太坊客户端（用于只读合约调用）
+func (c *Client) Backend() *ethclient.Client {
+	return c.client
+}
+
 // Close 关闭客户端
 func (c *Client) Close() {
 	if c.client != nil {
@@ -0,0 +1,49 @@
+package blockchain
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseOrderEventKinds(t *testing.T) {
+	orderBookABI, err := parseOrderBookABI()
+	require.NoError(t, err)
+	client := &Client{chainID: big.NewInt(1), logger: logrus.New(), orderBookABI: orderBookABI}
+
+	assert.Equal(t, orderBookABI.Events["OrderCancelled"].ID, orderCancelledTopic)
+	assert.Equal(t, orderBookABI.Events["OrderFilled"].ID, orderFilledTopic)
+	orderID := common.BigToHash(big.NewInt(42))
+	trader := common.HexToAddress("0x1234567890123456789012345678901234567890")
+
+	cancelled, err := client.parseOrderEvent(types.Log{
+		Topics: []common.Hash{orderCancelledTopic, orderID, common.BytesToHash(trader.Bytes())},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, OrderEventCancelled, cancelled.Kind)
+	assert.Equal(t, int64(42), cancelled.OrderID.Int64())
+	assert.Equal(t, trader, cancelled.Trader)
+
+	filled, err := client.parseOrderEvent(types.Log{Topics: []common.Hash{orderFilledTopic, orderID}})
+	require.NoError(t, err)
+	assert.Equal(t, OrderEventFilled, filled.Kind)
+	assert.Equal(t, int64(42), filled.OrderID.Int64())
+
+	_, err = client.parseOrderEvent(types.Log{Topics: []common.Hash{orderCancelledTopic, orderID}})
+	assert.Error(t, err, "缺少 trader topic")
+
+	data, err := orderBookABI.Events["OrderPlaced"].Inputs.NonIndexed().Pack(
+		trader, common.Address{2}, common.Address{3},
+		big.NewInt(2000), big.NewInt(1), true, uint8(0), big.NewInt(1700000000),
+	)
+	require.NoError(t, err)
+	placed, err := client.parseOrderEvent(types.Log{Topics: []common.Hash{orderPlacedTopic, orderID}, Data: data})
+	require.NoError(t, err)
+	assert.Equal(t, OrderEventPlaced, placed.Kind)
+	assert.Equal(t, trader, placed.Trader)
+}
@@ -0,0 +1,270 @@
+package blockchain
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// orderEventStream 订单事件流（OrderPlaced/OrderCancelled/OrderFilled）的进度标识，沿用最初只订阅 OrderPlaced 时的名称以兼容已有进度
+	orderEventStream = "order_placed"
+	// backfillChunkBlocks 每次 eth_getLogs 查询的最大区块跨度
+	backfillChunkBlocks = 2000
+)
+
+// 订单事件签名
+var (
+	orderPlacedTopic    = crypto.Keccak256Hash([]byte("OrderPlaced(uint256,address,address,address,uint256,uint256,bool,uint8,uint256)"))
+	orderCancelledTopic = crypto.Keccak256Hash([]byte("OrderCancelled(uint256,address)"))
+	orderFilledTopic    = crypto.Keccak256Hash([]byte("OrderFilled(uint256)"))
+)
+
+// SetEventCheckpoint 设置事件处理进度存储，重启后从上次处理的位置补齐事件
+func (c *Client) SetEventCheckpoint(checkpoint EventCheckpoint) {
+	c.eventCheckpoint = checkpoint
+}
+
+// SetConnectivityHandler 设置事件订阅连接状态回调：补齐完成进入实时订阅时 connected 为 true，
+// 实时订阅断开时为 false 并附带断开原因；重连失败期间不重复回调
+func (c *Client) SetConnectivityHandler(handler func(connected bool, err error)) {
+	c.onConnectivity = handler
+}
+
+// SetReconnectBackoff 设置订阅重连的初始和最大退避时间
+func (c *Client) SetReconnectBackoff(base, max time.Duration) {
+	if base > 0 {
+		c.reconnectBackoff = base
+	}
+	if max >= c.reconnectBackoff {
+		c.reconnectMaxBackoff = max
+	}
+}
+
+// CommitOrderEvent 确认事件已处理，推进持久化进度
+func (c *Client) CommitOrderEvent(event *OrderEvent) {
+	position := LogPosition{Block: event.BlockNumber, Index: int(event.LogIndex)}
+
+	c.eventMu.Lock()
+	defer c.eventMu.Unlock()
+	if c.pendingEvents > 0 {
+		c.pendingEvents--
+	}
+	c.saveCheckpointLocked(position)
+}
+
+// Live 首次完成历史事件补齐并进入实时订阅后关闭的通道
+func (c *Client) Live() <-chan struct{} {
+	return c.live
+}
+
+// PendingEvents 已投递、尚未确认处理的订单事件数
+func (c *Client) PendingEvents() int {
+	c.eventMu.Lock()
+	defer c.eventMu.Unlock()
+	return c.pendingEvents
+}
+
+// advanceCheckpoint 补齐完成且没有待处理事件时推进进度，避免空闲链每次重启都从旧区块扫描
+func (c *Client) advanceCheckpoint(position LogPosition) {
+	c.eventMu.Lock()
+	defer c.eventMu.Unlock()
+	if c.pendingEvents == 0 {
+		c.saveCheckpointLocked(position)
+	}
+}
+
+func (c *Client) saveCheckpointLocked(position LogPosition) {
+	if c.hasCommitted && !c.committed.Before(position) {
+		return
+	}
+	c.committed, c.hasCommitted = position, true
+
+	if c.eventCheckpoint == nil {
+		return
+	}
+	if err := c.eventCheckpoint.Save(c.ChainID(), orderEventStream, position); err != nil {
+		c.logger.WithError(err).WithField("block", position.Block).Error("Failed to save event checkpoint")
+	}
+}
+
+// runOrderEventStream 维持事件订阅，断开后按指数退避重连，直到 ctx 结束
+// last 为最后一条已投递日志的位置，为空时从当前区块开始
+func (c *Client) runOrderEventStream(ctx context.Context, eventChan chan<- *OrderEvent, last *LogPosition) {
+	backoff := c.reconnectBackoff
+	for {
+		connected, err := c.streamOrderEvents(ctx, eventChan, &last)
+		if ctx.Err() != nil {
+			return
+		}
+		if connected {
+			backoff = c.reconnectBackoff
+			if c.onConnectivity != nil {
+				c.onConnectivity(false, err)
+			}
+		}
+
+		c.logger.WithError(err).WithFields(logrus.Fields{
+			"chain_id": c.ChainID(),
+			"retry_in": backoff.String(),
+		}).Warn("Order event subscription lost, reconnecting")
+
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return
+		}
+
+		backoff *= 2
+		if backoff > c.reconnectMaxBackoff {
+			backoff = c.reconnectMaxBackoff
+		}
+	}
+}
+
+// streamOrderEvents 建立一次连接：先订阅再补齐历史日志，订阅与补齐重叠的部分按位置去重
+// 返回值表示是否已完成补齐并进入实时订阅
+func (c *Client) streamOrderEvents(ctx context.Context, eventChan chan<- *OrderEvent, last **LogPosition) (bool, error) {
+	// 独立连接，避免重连影响交易提交使用的客户端
+	client, err := ethclient.DialContext(ctx, c.rpcURL)
+	if err != nil {
+		return false, fmt.Errorf("failed to connect to ethereum node: %v", err)
+	}
+	defer client.Close()
+
+	logs := make(chan types.Log, 1024)
+	sub, err := client.SubscribeFilterLogs(ctx, c.orderEventsQuery(nil, nil), logs)
+	if err != nil {
+		return false, fmt.Errorf("failed to subscribe to logs: %v", err)
+	}
+	defer sub.Unsubscribe()
+
+	head, err := client.BlockNumber(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to get block number: %v", err)
+	}
+
+	if *last == nil {
+		// 首次启动且没有处理进度，从下一个区块开始
+		*last = &LogPosition{Block: head + 1, Index: -1}
+		c.logger.WithFields(logrus.Fields{
+			"chain_id":    c.ChainID(),
+			"start_block": head + 1,
+		}).Info("No event checkpoint found, starting from current block")
+	} else if err := c.backfillOrderEvents(ctx, client, head, eventChan, *last); err != nil {
+		return false, err
+	}
+	c.advanceCheckpoint(LogPosition{Block: head + 1, Index: -1})
+
+	c.logger.WithField("chain_id", c.ChainID()).Info("Subscribed to order events")
+	c.liveOnce.Do(func() { close(c.live) })
+	if c.onConnectivity != nil {
+		c.onConnectivity(true, nil)
+	}
+
+	for {
+		select {
+		case err := <-sub.Err():
+			if err == nil {
+				err = fmt.Errorf("subscription closed")
+			}
+			return true, err
+		case vLog := <-logs:
+			if err := c.deliverOrderEvent(ctx, vLog, eventChan, *last); err != nil {
+				return true, err
+			}
+		case <-ctx.Done():
+			return true, ctx.Err()
+		}
+	}
+}
+
+// backfillOrderEvents 通过 eth_getLogs 补齐 last 之后到 head 的事件
+func (c *Client) backfillOrderEvents(ctx context.Context, client *ethclient.Client, head uint64, eventChan chan<- *OrderEvent, last *LogPosition) error {
+	from := last.Block
+	if from > head {
+		return nil
+	}
+
+	c.logger.WithFields(logrus.Fields{
+		"chain_id":   c.ChainID(),
+		"from_block": from,
+		"to_block":   head,
+	}).Info("Backfilling order events")
+
+	for start := from; start <= head; start += backfillChunkBlocks {
+		end := start + backfillChunkBlocks - 1
+		if end > head {
+			end = head
+		}
+
+		vLogs, err := client.FilterLogs(ctx, c.orderEventsQuery(new(big.Int).SetUint64(start), new(big.Int).SetUint64(end)))
+		if err != nil {
+			return fmt.Errorf("failed to backfill logs %d-%d: %v", start, end, err)
+		}
+		for _, vLog := range vLogs {
+			if err := c.deliverOrderEvent(ctx, vLog, eventChan, last); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// deliverOrderEvent 投递位于 last 之后的日志并推进 last，已投递或被回滚的日志直接跳过
+func (c *Client) deliverOrderEvent(ctx context.Context, vLog types.Log, eventChan chan<- *OrderEvent, last *LogPosition) error {
+	position := positionOf(vLog)
+	if !last.Before(position) {
+		return nil
+	}
+	if vLog.Removed {
+		c.logger.WithFields(logrus.Fields{
+			"block":   vLog.BlockNumber,
+			"tx_hash": vLog.TxHash.Hex(),
+		}).Warn("Order event removed by chain reorg")
+		return nil
+	}
+
+	event, err := c.parseOrderEvent(vLog)
+	if err != nil {
+		c.logger.WithError(err).Error("Failed to parse order event")
+		*last = position
+		return nil
+	}
+	event.BlockNumber = vLog.BlockNumber
+	event.LogIndex = vLog.Index
+	event.TxHash = vLog.TxHash
+
+	c.eventMu.Lock()
+	c.pendingEvents++
+	c.eventMu.Unlock()
+
+	select {
+	case eventChan <- event:
+		*last = position
+		return nil
+	case <-ctx.Done():
+		c.eventMu.Lock()
+		c.pendingEvents--
+		c.eventMu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// orderEventsQuery 订单事件日志过滤条件，区块范围为空时用于实时订阅
+func (c *Client) orderEventsQuery(fromBlock, toBlock *big.Int) ethereum.FilterQuery {
+	return ethereum.FilterQuery{
+		FromBlock: fromBlock,
+		ToBlock:   toBlock,
+		Addresses: []common.Address{c.orderBookAddress},
+		Topics:    [][]common.Hash{{orderPlacedTopic, orderCancelledTopic, orderFilledTopic}},
+	}
+}
@@ -0,0 +1,87 @@
+package blockchain
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// fundsABIJSON 资金查询使用的只读方法：Settlement.userBalances 与 ERC-20 balanceOf/allowance
+const fundsABIJSON = `[
+	{
+		"inputs": [
+			{"internalType": "address", "name": "", "type": "address"},
+			{"internalType": "address", "name": "", "type": "address"}
+		],
+		"name": "userBalances",
+		"outputs": [{"internalType": "uint256", "name": "", "type": "uint256"}],
+		"stateMutability": "view",
+		"type": "function"
+	},
+	{
+		"inputs": [{"internalType": "address", "name": "account", "type": "address"}],
+		"name": "balanceOf",
+		"outputs": [{"internalType": "uint256", "name": "", "type": "uint256"}],
+		"stateMutability": "view",
+		"type": "function"
+	},
+	{
+		"inputs": [
+			{"internalType": "address", "name": "owner", "type": "address"},
+			{"internalType": "address", "name": "spender", "type": "address"}
+		],
+		"name": "allowance",
+		"outputs": [{"internalType": "uint256", "name": "", "type": "uint256"}],
+		"stateMutability": "view",
+		"type": "function"
+	}
+]`
+
+var fundsABI, fundsABIErr = abi.JSON(strings.NewReader(fundsABIJSON))
+
+// DepositedBalance 读取用户在Settlement合约中的托管余额（userBalances），结算时从该余额划转
+func (c *Client) DepositedBalance(ctx context.Context, user, token common.Address) (*big.Int, error) {
+	return c.callUint(ctx, c.settlementAddress, "userBalances", user, token)
+}
+
+// WalletFunds 读取用户的 ERC-20 钱包余额及对Settlement合约的授权额度
+func (c *Client) WalletFunds(ctx context.Context, user, token common.Address) (balance, allowance *big.Int, err error) {
+	if balance, err = c.callUint(ctx, token, "balanceOf", user); err != nil {
+		return nil, nil, err
+	}
+	if allowance, err = c.callUint(ctx, token, "allowance", user, c.settlementAddress); err != nil {
+		return nil, nil, err
+	}
+	return balance, allowance, nil
+}
+
+// callUint 调用返回单个 uint256 的只读方法
+func (c *Client) callUint(ctx context.Context, contract common.Address, method string, args ...interface{}) (*big.Int, error) {
+	if fundsABIErr != nil {
+		return nil, fmt.Errorf("failed to parse funds ABI: %v", fundsABIErr)
+	}
+	data, err := fundsABI.Pack(method, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pack call data: %v", err)
+	}
+
+	result, err := c.client.CallContract(ctx, ethereum.CallMsg{To: &contract, Data: data}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call %s: %v", method, err)
+	}
+
+	values, err := fundsABI.Unpack(method, result)
+	if err != nil || len(values) != 1 {
+		return nil, fmt.Errorf("failed to unpack %s: %v", method, err)
+	}
+	value, ok := values[0].(*big.Int)
+	if !ok {
+		return nil, fmt.Errorf("unexpected %s value: %v", method, values[0])
+	}
+	return value, nil
+}
@@ -11,6 +11,8 @@ import (
 	"github.com/shopspring/decimal"
 
 	"orderbook-engine/internal/matching"
+	"orderbook-engine/internal/pairs"
+	"orderbook-engine/internal/tokens"
 	"orderbook-engine/internal/types"
 )
 
@@ -18,21 +20,29 @@ import (
 type OrderPollingService struct {
 	client       *Client
 	engine       *matching.MatchingEngine
+	tokens       *tokens.Registry
+	pairNames    *pairs.Canonicalizer // 可选，为空时按代币符号命名交易对
 	logger       *logrus.Logger
 	lastBlock    uint64
 	pollInterval time.Duration
 }
 
 // NewOrderPollingService 创建轮询服务
-func NewOrderPollingService(client *Client, engine *matching.MatchingEngine, logger *logrus.Logger) *OrderPollingService {
+func NewOrderPollingService(client *Client, engine *matching.MatchingEngine, tokenRegistry *tokens.Registry, logger *logrus.Logger) *OrderPollingService {
 	return &OrderPollingService{
 		client:       client,
 		engine:       engine,
+		tokens:       tokenRegistry,
 		logger:       logger,
 		pollInterval: 5 * time.Second, // 每5秒轮询一次
 	}
 }
 
+// SetPairCanonicalizer 设置交易对规范命名，与其他下单入口使用同一交易对名称
+func (ops *OrderPollingService) SetPairCanonicalizer(canonicalizer *pairs.Canonicalizer) {
+	ops.pairNames = canonicalizer
+}
+
 // Start 启动轮询服务
 func (ops *OrderPollingService) Start(ctx context.Context) error {
 	// 获取当前区块高度
@@ -142,11 +152,21 @@ func (ops *OrderPollingService) ProcessOrderFromFrontend(orderData map[string]in
 		return fmt.Errorf("invalid amount format: %v", err)
 	}
 
+	// 交易对名称：设置了规范命名时取规范名称，代币顺序相反的订单不进入订单簿
+	tradingPair := fmt.Sprintf("%s-%s", ops.tokens.Symbol(ops.client.ChainID(), tokenA), ops.tokens.Symbol(ops.client.ChainID(), tokenB))
+	if ops.pairNames != nil {
+		pair := ops.pairNames.Resolve(ops.client.ChainID(), tokenA, tokenB)
+		if pair.Reversed {
+			return fmt.Errorf("tokens are in reverse order for %s", pair.TradingPair)
+		}
+		tradingPair = pair.TradingPair
+	}
+
 	// 创建订单对象
 	order := &types.Order{
 		ID:          uuid.New(),
 		UserAddress: userAddress,
-		TradingPair: fmt.Sprintf("%s-%s", tokenA, tokenB),
+		TradingPair: tradingPair,
 		BaseToken:   tokenA,
 		QuoteToken:  tokenB,
 		Price:       price,
@@ -161,7 +181,10 @@ func (ops *OrderPollingService) ProcessOrderFromFrontend(orderData map[string]in
 	}
 
 	// 添加到撮合引擎
-	fills := ops.engine.AddOrder(order)
+	fills, err := ops.engine.AddOrder(order)
+	if err != nil {
+		return fmt.Errorf("order rejected by engine: %w", err)
+	}
 
 	ops.logger.WithFields(logrus.Fields{
 		"user":   userAddress,
@@ -188,9 +211,18 @@ func (ops *OrderPollingService) executeFill(fill *types.Fill, order *types.Order
 	tokenA := common.HexToAddress(order.BaseToken)
 	tokenB := common.HexToAddress(order.QuoteToken)
 
-	// 转换精度：USDC 6位小数，WETH 18位小数
-	priceWei := fill.Price.Mul(decimal.New(1, 6)).BigInt()   // USDC精度
-	amountWei := fill.Amount.Mul(decimal.New(1, 18)).BigInt() // WETH精度
+	// 转换精度：价格按报价代币精度，数量按基础代币精度
+	chainID := ops.client.ChainID()
+	priceWei, err := ops.tokens.ToBaseUnits(chainID, order.QuoteToken, fill.Price)
+	if err != nil {
+		ops.logger.WithError(err).Error("Failed to convert fill price")
+		return
+	}
+	amountWei, err := ops.tokens.ToBaseUnits(chainID, order.BaseToken, fill.Amount)
+	if err != nil {
+		ops.logger.WithError(err).Error("Failed to convert fill amount")
+		return
+	}
 
 	tx, err := ops.client.ExecuteTrade(
 		buyer, seller, tokenA, tokenB,
@@ -2,40 +2,69 @@ package blockchain
 
 import (
 	"context"
-	"crypto/ecdsa"
 	"math/big"
 	"time"
 	"fmt"
 	"log"
+	"strings"
 	"sync"
 
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/google/uuid"
 
+	"orderbook-engine/internal/gasbudget"
+	"orderbook-engine/internal/merkle"
+	"orderbook-engine/internal/txsigner"
 	ordertypes "orderbook-engine/internal/types"
+	ordercrypto "orderbook-engine/pkg/crypto"
 )
 
 // SettlementManager 链上结算管理器
 type SettlementManager struct {
 	client               *ethclient.Client
 	settlementContract   common.Address
-	privateKey          *ecdsa.PrivateKey
+	signer              txsigner.Signer // 结算账户签名者，密钥轮换时替换
 	auth                *bind.TransactOpts
+	accounts            accountReader   // 查询结算账户nonce与余额
 	chainID             *big.Int
 	batchSize           int
 	settlementQueue     chan *PendingSettlement
 	batchTimer          *time.Timer
 	pendingSettlements  []*PendingSettlement
 	mu                  sync.RWMutex
+	submitMu            sync.Mutex // 串行化批次提交，密钥轮换期间持有以阻止新交易
 	running             bool
+	paused              bool // 紧急停机，成交继续排队但不提交上链
 	stopCh              chan struct{}
+	hasher              *ordercrypto.OrderSigner // EIP-712订单哈希（与合约域一致）
+	settlementABI       abi.ABI
+	statusHandler       SettlementStatusHandler
+	retryPolicy         RetryPolicy
+	deadLetters         map[uuid.UUID]*PendingSettlement // 超过重试次数的结算
+	simulator           ethereum.ContractCaller          // 提交前逐笔模拟执行，为空时不模拟
+	simulationRejected  int                              // 模拟执行失败被剔除的结算项数量
+	indexedBatches      bool                             // 使用按订单去重的 batchSettleTradesIndexed 提交
+	metrics             BatchMetrics                     // 已上链批次的Gas与calldata统计
+	merkleStore         merkle.Store                     // 默克尔批次证明存储，为空时不使用默克尔批次
+	merklePairs         map[string]bool                  // 使用默克尔批次的交易对，为空时全部交易对
+	lastRotation        *KeyRotation                     // 最近一次结算密钥轮换
+	gasBudget           *gasbudget.Tracker               // 结算Gas支出预算，为空时不限制
+	lastSubmitAt        time.Time                        // 最近一次提交批次的时间，由 submitMu 保护
 }
 
+// SettlementStatusHandler 批量结算状态回调，fillIDs 为批次中关联成交的ID
+type SettlementStatusHandler func(fillIDs []uuid.UUID, status ordertypes.SettlementStatus, txHash string, err error)
+
 // PendingSettlement 待结算交易
 type PendingSettlement struct {
+	ID              uuid.UUID // 结算项ID（关联成交时与FillID相同）
+	FillID          uuid.UUID // 关联的链下成交（可为空）
+	TradingPair     string
 	TakerOrderHash  [32]byte
 	MakerOrderHash  [32]byte
 	Price           *big.Int
@@ -46,6 +75,10 @@ type PendingSettlement struct {
 	TakerSignature  []byte
 	MakerSignature  []byte
 	Timestamp       time.Time
+	Attempts        int       // 已失败的提交次数
+	NextAttemptAt   time.Time // 退避结束时间
+	LastError       string
+	Isolated        bool      // 曾随失败批次提交，之后单独提交以定位问题交易
 }
 
 // CompactOrder 紧凑订单结构（匹配Solidity）
@@ -78,7 +111,7 @@ type BatchFill struct {
 func NewSettlementManager(
 	rpcURL string,
 	settlementAddress common.Address,
-	privateKeyHex string,
+	signer txsigner.Signer,
 	chainID *big.Int,
 ) (*SettlementManager, error) {
 	// 连接以太坊节点
@@ -87,16 +120,12 @@ func NewSettlementManager(
 		return nil, fmt.Errorf("failed to connect to Ethereum node: %w", err)
 	}
 
-	// 解析私钥
-	privateKey, err := crypto.HexToECDSA(privateKeyHex)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse private key: %w", err)
-	}
-
 	// 创建交易认证
-	auth, err := bind.NewKeyedTransactorWithChainID(privateKey, chainID)
+	auth := txsigner.NewTransactOpts(signer, chainID)
+
+	parsedABI, err := abi.JSON(strings.NewReader(batchSettleABI))
 	if err != nil {
-		return nil, fmt.Errorf("failed to create auth: %w", err)
+		return nil, fmt.Errorf("failed to parse settlement ABI: %w", err)
 	}
 
 	// 设置Gas参数
@@ -106,13 +135,18 @@ func NewSettlementManager(
 	sm := &SettlementManager{
 		client:              client,
 		settlementContract:  settlementAddress,
-		privateKey:          privateKey,
+		signer:              signer,
 		auth:                auth,
+		accounts:            client,
 		chainID:             chainID,
 		batchSize:           10, // 每批处理10笔交易
 		settlementQueue:     make(chan *PendingSettlement, 1000),
 		pendingSettlements:  make([]*PendingSettlement, 0),
 		stopCh:              make(chan struct{}),
+		hasher:              ordercrypto.NewOrderSigner(chainID, settlementAddress),
+		settlementABI:       parsedABI,
+		retryPolicy:         DefaultRetryPolicy(),
+		deadLetters:         make(map[uuid.UUID]*PendingSettlement),
 	}
 
 	return sm, nil
@@ -155,6 +189,21 @@ func (sm *SettlementManager) Stop() {
 	log.Println("⏹️  Settlement Manager stopped - 链上结算管理器已停止")
 }
 
+// ChainID 结算所在链的ID
+func (sm *SettlementManager) ChainID() uint64 {
+	if sm.chainID == nil {
+		return 0
+	}
+	return sm.chainID.Uint64()
+}
+
+// SetStatusHandler 设置批量结算状态回调
+func (sm *SettlementManager) SetStatusHandler(handler SettlementStatusHandler) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.statusHandler = handler
+}
+
 // SubmitTradeForSettlement 提交交易到结算队列
 func (sm *SettlementManager) SubmitTradeForSettlement(
 	takerOrder *ordertypes.SignedOrder,
@@ -162,48 +211,68 @@ func (sm *SettlementManager) SubmitTradeForSettlement(
 	fillPrice *big.Int,
 	fillAmount *big.Int,
 ) error {
-	// 转换订单格式
-	takerCompact, err := sm.convertToCompactOrder(takerOrder)
-	if err != nil {
-		return fmt.Errorf("failed to convert taker order: %w", err)
+	takerSide := uint8(0)
+	if takerOrder.Side == ordertypes.OrderSideSell {
+		takerSide = 1
 	}
+	return sm.enqueue(uuid.Nil, takerOrder.TradingPair,
+		ordercrypto.TypedOrderFromSigned(takerOrder), takerOrder.Signature,
+		ordercrypto.TypedOrderFromSigned(makerOrder), makerOrder.Signature,
+		fillPrice, fillAmount, takerSide)
+}
 
-	makerCompact, err := sm.convertToCompactOrder(makerOrder)
-	if err != nil {
-		return fmt.Errorf("failed to convert maker order: %w", err)
+// SubmitFill 提交链下成交到结算队列，taker/maker 需为带签名的订单
+func (sm *SettlementManager) SubmitFill(fill *ordertypes.Fill, takerOrder, makerOrder *ordertypes.Order) error {
+	takerSide := uint8(0)
+	if fill.TakerSide == ordertypes.OrderSideSell {
+		takerSide = 1
 	}
+	return sm.enqueue(fill.ID, fill.TradingPair,
+		ordercrypto.TypedOrderFromOrder(takerOrder), takerOrder.Signature,
+		ordercrypto.TypedOrderFromOrder(makerOrder), makerOrder.Signature,
+		fill.Price.BigInt(), fill.Amount.BigInt(), takerSide)
+}
 
+// enqueue 构造待结算交易并加入队列
+func (sm *SettlementManager) enqueue(
+	fillID uuid.UUID,
+	tradingPair string,
+	takerOrder *ordercrypto.TypedOrder, takerSignature string,
+	makerOrder *ordercrypto.TypedOrder, makerSignature string,
+	fillPrice, fillAmount *big.Int,
+	takerSide uint8,
+) error {
 	// 生成订单哈希
-	takerHash := sm.generateOrderHash(takerOrder)
-	makerHash := sm.generateOrderHash(makerOrder)
+	takerHash := sm.hasher.HashTypedOrder(takerOrder)
+	makerHash := sm.hasher.HashTypedOrder(makerOrder)
 
 	// 解析签名
-	takerSig, err := hexToBytes(takerOrder.Signature)
-	if err != nil {
-		return fmt.Errorf("invalid taker signature: %w", err)
+	takerSig, err := hexToBytes(takerSignature)
+	if err != nil || len(takerSig) != 65 {
+		return fmt.Errorf("invalid taker signature")
 	}
 
-	makerSig, err := hexToBytes(makerOrder.Signature)
-	if err != nil {
-		return fmt.Errorf("invalid maker signature: %w", err)
+	makerSig, err := hexToBytes(makerSignature)
+	if err != nil || len(makerSig) != 65 {
+		return fmt.Errorf("invalid maker signature")
 	}
 
-	// 确定taker方向
-	var takerSide uint8
-	if takerOrder.Side == ordertypes.OrderSideBuy {
-		takerSide = 0
-	} else {
-		takerSide = 1
+	id := fillID
+	if id == uuid.Nil {
+		id = uuid.New()
 	}
 
 	settlement := &PendingSettlement{
+		ID:              id,
+		FillID:          fillID,
+		TradingPair:     tradingPair,
 		TakerOrderHash:  takerHash,
 		MakerOrderHash:  makerHash,
 		Price:           fillPrice,
 		Amount:          fillAmount,
 		TakerSide:       takerSide,
-		TakerOrder:      takerCompact,
-		MakerOrder:      makerCompact,
+		TakerOrder:      toCompactOrder(takerOrder),
+		MakerOrder:      toCompactOrder(makerOrder),
 		TakerSignature:  takerSig,
 		MakerSignature:  makerSig,
 		Timestamp:       time.Now(),
@@ -245,75 +314,139 @@ func (sm *SettlementManager) timerProcessor() {
 		case <-sm.stopCh:
 			return
 		case <-sm.batchTimer.C:
-			sm.processBatch()
+			// 每个批次只含一个交易对，定时提交时处理完全部可提交的交易对
+			for sm.processBatch() {
+			}
 			sm.batchTimer.Reset(5 * time.Second)
 		}
 	}
 }
 
-// processBatch 处理批量结算
-func (sm *SettlementManager) processBatch() {
+// SetPaused 暂停或恢复提交上链（紧急停机），暂停期间结算项继续排队，恢复后由定时器提交
+func (sm *SettlementManager) SetPaused(paused bool) {
 	sm.mu.Lock()
-	if len(sm.pendingSettlements) == 0 {
-		sm.mu.Unlock()
-		return
+	defer sm.mu.Unlock()
+	sm.paused = paused
+}
+
+// Paused 是否暂停提交上链
+func (sm *SettlementManager) Paused() bool {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	return sm.paused
+}
+
+// processBatch 处理批量结算，没有可提交的结算项、已暂停或超出Gas预算时返回 false
+func (sm *SettlementManager) processBatch() bool {
+	if sm.Paused() {
+		return false
 	}
+	sm.submitMu.Lock()
+	defer sm.submitMu.Unlock()
 
-	// 复制待处理的结算
-	batch := make([]*PendingSettlement, len(sm.pendingSettlements))
-	copy(batch, sm.pendingSettlements)
-	sm.pendingSettlements = sm.pendingSettlements[:0] // 清空
-	sm.mu.Unlock()
+	now := time.Now()
+	if !sm.gasBudgetAllows(now) {
+		return false
+	}
+	batch := sm.takeBatch(now)
+	if len(batch) == 0 {
+		return false
+	}
+	sm.lastSubmitAt = now
+
+	// 逐笔模拟执行，剔除必然回滚的成交，避免单笔问题交易拖垮整批
+	batch = sm.excludeFailingFills(batch)
+	if len(batch) == 0 {
+		return true
+	}
 
 	log.Printf("🔗 Processing batch settlement with %d trades", len(batch))
+	sm.notify(batch, ordertypes.SettlementStatusBatched, "", nil)
 
-	if err := sm.executeBatchSettlement(batch); err != nil {
+	txHash, err := sm.executeBatchSettlement(batch)
+	if err != nil {
 		log.Printf("❌ Batch settlement failed: %v", err)
-		
-		// 重试逻辑：将失败的交易重新加入队列
-		sm.mu.Lock()
-		sm.pendingSettlements = append(batch, sm.pendingSettlements...)
-		sm.mu.Unlock()
+		sm.notify(batch, ordertypes.SettlementStatusFailed, txHash, err)
+		sm.handleBatchFailure(batch, err)
 	} else {
 		log.Printf("✅ Batch settlement completed successfully - %d trades settled", len(batch))
+		sm.notify(batch, ordertypes.SettlementStatusConfirmed, txHash, nil)
+	}
+	return true
+}
+
+// Flush 立即提交队列中全部可提交的结算项（排空停机时调用），返回仍在等待的结算项数量
+// 失败后处于退避等待中的结算项不会提前重试
+func (sm *SettlementManager) Flush() int {
+	for drained := false; !drained; {
+		select {
+		case settlement := <-sm.settlementQueue:
+			sm.mu.Lock()
+			sm.pendingSettlements = append(sm.pendingSettlements, settlement)
+			sm.mu.Unlock()
+		default:
+			drained = true
+		}
+	}
+
+	for sm.processBatch() {
+	}
+	return sm.PendingCount()
+}
+
+// PendingCount 等待提交的结算项数量（含队列中和退避重试中的）
+func (sm *SettlementManager) PendingCount() int {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	return len(sm.settlementQueue) + len(sm.pendingSettlements)
+}
+
+// notify 通知批次中关联成交的结算状态
+func (sm *SettlementManager) notify(batch []*PendingSettlement, status ordertypes.SettlementStatus, txHash string, err error) {
+	sm.mu.RLock()
+	handler := sm.statusHandler
+	sm.mu.RUnlock()
+	if handler == nil {
+		return
+	}
+
+	fillIDs := make([]uuid.UUID, 0, len(batch))
+	for _, settlement := range batch {
+		if settlement.FillID != uuid.Nil {
+			fillIDs = append(fillIDs, settlement.FillID)
+		}
+	}
+	if len(fillIDs) > 0 {
+		handler(fillIDs, status, txHash, err)
 	}
 }
 
 // executeBatchSettlement 执行批量链上结算
-func (sm *SettlementManager) executeBatchSettlement(settlements []*PendingSettlement) error {
+func (sm *SettlementManager) executeBatchSettlement(settlements []*PendingSettlement) (string, error) {
 	if len(settlements) == 0 {
-		return nil
+		return "", nil
 	}
 
-	// 构建BatchFill数据结构
-	batchFill := &BatchFill{
-		TakerOrderHashes: make([][32]byte, len(settlements)),
-		MakerOrderHashes: make([][32]byte, len(settlements)),
-		Prices:           make([]*big.Int, len(settlements)),
-		Amounts:          make([]*big.Int, len(settlements)),
-		TakerSides:       make([]uint8, len(settlements)),
-		TakerSignatures:  make([][]byte, len(settlements)),
-		MakerSignatures:  make([][]byte, len(settlements)),
-		TakerOrders:      make([]CompactOrder, len(settlements)),
-		MakerOrders:      make([]CompactOrder, len(settlements)),
+	// 默克尔批次只提交成交的默克尔根
+	method, batchFill := sm.batchCall(settlements)
+	args := []interface{}{batchFill}
+	var merkleBatch *merkle.Batch
+	if sm.merkleBatch(settlements) {
+		var err error
+		if merkleBatch, err = sm.buildMerkleBatch(settlements); err != nil {
+			return "", err
+		}
+		method, args = "submitSettlementRoot", []interface{}{common.HexToHash(merkleBatch.Root), big.NewInt(int64(merkleBatch.Fills))}
 	}
-
-	for i, settlement := range settlements {
-		batchFill.TakerOrderHashes[i] = settlement.TakerOrderHash
-		batchFill.MakerOrderHashes[i] = settlement.MakerOrderHash
-		batchFill.Prices[i] = settlement.Price
-		batchFill.Amounts[i] = settlement.Amount
-		batchFill.TakerSides[i] = settlement.TakerSide
-		batchFill.TakerSignatures[i] = settlement.TakerSignature
-		batchFill.MakerSignatures[i] = settlement.MakerSignature
-		batchFill.TakerOrders[i] = *settlement.TakerOrder
-		batchFill.MakerOrders[i] = *settlement.MakerOrder
+	calldata, err := sm.settlementABI.Pack(method, args...)
+	if err != nil {
+		return "", fmt.Errorf("failed to pack %s: %w", method, err)
 	}
 
 	// 获取最新的nonce
 	nonce, err := sm.client.PendingNonceAt(context.Background(), sm.auth.From)
 	if err != nil {
-		return fmt.Errorf("failed to get nonce: %w", err)
+		return "", fmt.Errorf("failed to get nonce: %w", err)
 	}
 	sm.auth.Nonce = big.NewInt(int64(nonce))
 
@@ -325,12 +458,13 @@ func (sm *SettlementManager) executeBatchSettlement(settlements []*PendingSettle
 	}
 	sm.auth.GasPrice = gasPrice
 
-	// 调用智能合约的batchSettleTrades函数
-	// 注意：这里需要生成合约ABI绑定，或者使用低级别的合约调用
-	tx, err := sm.callBatchSettleTrades(batchFill)
+	// 调用智能合约的批量结算函数
+	tx, err := sm.callBatchSettleTrades(method, args...)
 	if err != nil {
-		return fmt.Errorf("failed to call batchSettleTrades: %w", err)
+		return "", fmt.Errorf("failed to call %s: %w", method, err)
 	}
+	txHash := tx.Hash().Hex()
+	sm.notify(settlements, ordertypes.SettlementStatusSubmitted, txHash, nil)
 
 	// 等待交易确认
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
@@ -338,82 +472,75 @@ func (sm *SettlementManager) executeBatchSettlement(settlements []*PendingSettle
 
 	receipt, err := bind.WaitMined(ctx, sm.client, tx)
 	if err != nil {
-		return fmt.Errorf("transaction failed or timeout: %w", err)
+		return txHash, fmt.Errorf("transaction failed or timeout: %w", err)
 	}
+	sm.recordGasSpend(receipt, gasPrice)
 
 	if receipt.Status != types.ReceiptStatusSuccessful {
-		return fmt.Errorf("transaction reverted, hash: %s", tx.Hash().Hex())
+		return txHash, fmt.Errorf("transaction reverted, hash: %s", txHash)
 	}
 
-	log.Printf("🎉 Batch settlement successful! TX: %s, Gas used: %d", 
-		tx.Hash().Hex(), receipt.GasUsed)
+	sample := &BatchSample{
+		TxHash:        txHash,
+		Method:        method,
+		Fills:         len(settlements),
+		Orders:        uniqueOrders(settlements),
+		GasUsed:       receipt.GasUsed,
+		CalldataBytes: len(calldata),
+		SettledAt:     time.Now(),
+	}
+	sm.recordBatch(sample)
+	if merkleBatch != nil {
+		sm.saveMerkleBatch(merkleBatch, txHash)
+	}
+
+	log.Printf("🎉 Batch settlement successful! TX: %s, Gas used: %d (%d per fill, %d fills, %d orders, %d calldata bytes)",
+		txHash, receipt.GasUsed, sample.GasPerFill, sample.Fills, sample.Orders, sample.CalldataBytes)
 	
-	return nil
+	return txHash, nil
 }
 
-// 辅助函数
-func (sm *SettlementManager) convertToCompactOrder(order *ordertypes.SignedOrder) (*CompactOrder, error) {
-	// 转换地址
-	userAddr := common.HexToAddress(order.UserAddress)
-	baseToken := common.HexToAddress(order.BaseToken)
-	quoteToken := common.HexToAddress(order.QuoteToken)
-
-	// 转换价格和数量（使用BigInt）
-	price := order.Price.BigInt()
-	amount := order.Amount.BigInt()
-
-	// 转换过期时间
-	var expiresAt uint64
-	if order.ExpiresAt != nil {
-		expiresAt = uint64(order.ExpiresAt.Unix())
-	}
-
-	// 转换订单方向
-	var side uint8
-	if order.Side == ordertypes.OrderSideBuy {
-		side = 0
-	} else {
-		side = 1
+// buildBatchFill 构建BatchFill数据结构
+func buildBatchFill(settlements []*PendingSettlement) *BatchFill {
+	batchFill := &BatchFill{
+		TakerOrderHashes: make([][32]byte, len(settlements)),
+		MakerOrderHashes: make([][32]byte, len(settlements)),
+		Prices:           make([]*big.Int, len(settlements)),
+		Amounts:          make([]*big.Int, len(settlements)),
+		TakerSides:       make([]uint8, len(settlements)),
+		TakerSignatures:  make([][]byte, len(settlements)),
+		MakerSignatures:  make([][]byte, len(settlements)),
+		TakerOrders:      make([]CompactOrder, len(settlements)),
+		MakerOrders:      make([]CompactOrder, len(settlements)),
 	}
 
-	// 转换订单类型
-	var orderType uint8
-	switch order.Type {
-	case ordertypes.OrderTypeLimit:
-		orderType = 0
-	case ordertypes.OrderTypeMarket:
-		orderType = 1
-	case ordertypes.OrderTypeStopLoss:
-		orderType = 2
-	case ordertypes.OrderTypeTakeProfit:
-		orderType = 3
+	for i, settlement := range settlements {
+		batchFill.TakerOrderHashes[i] = settlement.TakerOrderHash
+		batchFill.MakerOrderHashes[i] = settlement.MakerOrderHash
+		batchFill.Prices[i] = settlement.Price
+		batchFill.Amounts[i] = settlement.Amount
+		batchFill.TakerSides[i] = settlement.TakerSide
+		batchFill.TakerSignatures[i] = settlement.TakerSignature
+		batchFill.MakerSignatures[i] = settlement.MakerSignature
+		batchFill.TakerOrders[i] = *settlement.TakerOrder
+		batchFill.MakerOrders[i] = *settlement.MakerOrder
 	}
-
-	return &CompactOrder{
-		UserAddress: userAddr,
-		BaseToken:   baseToken,
-		QuoteToken:  quoteToken,
-		Price:       price,
-		Amount:      amount,
-		ExpiresAt:   expiresAt,
-		Nonce:       order.Nonce,
-		Side:        side,
-		OrderType:   orderType,
-	}, nil
+	return batchFill
 }
 
-func (sm *SettlementManager) generateOrderHash(order *ordertypes.SignedOrder) [32]byte {
-	// 简单哈希生成，实际应该匹配链上的哈希计算
-	data := fmt.Sprintf("%s%s%s%d%s%s%d",
-		order.UserAddress,
-		order.BaseToken,
-		order.QuoteToken,
-		order.Side,
-		order.Price.String(),
-		order.Amount.String(),
-		order.Nonce,
-	)
-	return crypto.Keccak256Hash([]byte(data))
+// 辅助函数
+func toCompactOrder(typed *ordercrypto.TypedOrder) *CompactOrder {
+	return &CompactOrder{
+		UserAddress: typed.UserAddress,
+		BaseToken:   typed.BaseToken,
+		QuoteToken:  typed.QuoteToken,
+		Price:       typed.Price,
+		Amount:      typed.Amount,
+		ExpiresAt:   typed.ExpiresAt,
+		Nonce:       typed.Nonce,
+		Side:        typed.Side,
+		OrderType:   typed.OrderType,
+	}
 }
 
 func hexToBytes(hexStr string) ([]byte, error) {
@@ -424,20 +551,9 @@ func hexToBytes(hexStr string) ([]byte, error) {
 }
 
 // callBatchSettleTrades 调用批量结算合约函数
-// 这是一个简化版本，实际需要生成合约ABI绑定
-func (sm *SettlementManager) callBatchSettleTrades(batchFill *BatchFill) (*types.Transaction, error) {
-	// 注意：这里需要实现实际的合约调用
-	// 可以使用abigen生成的绑定，或者使用低级别的合约调用
-	
-	// 示例：构建交易数据（需要根据实际ABI调整）
-	// 这里返回一个模拟的交易，实际实现需要：
-	// 1. 使用abigen生成合约绑定
-	// 2. 调用绑定的batchSettleTrades方法
-	
-	log.Printf("⚠️  TODO: Implement actual contract call for batchSettleTrades")
-	
-	// 临时返回一个空交易作为占位符
-	return &types.Transaction{}, fmt.Errorf("contract call not implemented yet - need to generate ABI bindings")
+func (sm *SettlementManager) callBatchSettleTrades(method string, args ...interface{}) (*types.Transaction, error) {
+	contract := bind.NewBoundContract(sm.settlementContract, sm.settlementABI, sm.client, sm.client, sm.client)
+	return contract.Transact(sm.auth, method, args...)
 }
 
 // GetSettlementStats 获取结算统计
@@ -447,9 +563,100 @@ func (sm *SettlementManager) GetSettlementStats() map[string]interface{} {
 
 	return map[string]interface{}{
 		"running":            sm.running,
+		"paused":             sm.paused,
 		"pending_settlements": len(sm.pendingSettlements),
+		"dead_letters":       len(sm.deadLetters),
+		"simulation_rejected": sm.simulationRejected,
 		"queue_length":       len(sm.settlementQueue),
 		"batch_size":         sm.batchSize,
 		"contract_address":   sm.settlementContract.Hex(),
+		"signer_address":     sm.signerAddressLocked().Hex(),
+	}
+}
+// batchSettleABI OptimizedSettlement.batchSettleTrades、batchSettleTradesIndexed 与 submitSettlementRoot 的ABI
+const batchSettleABI = `[
+	{
+		"inputs": [
+			{
+				"components": [
+					{"internalType": "bytes32[]", "name": "takerOrderHashes", "type": "bytes32[]"},
+					{"internalType": "bytes32[]", "name": "makerOrderHashes", "type": "bytes32[]"},
+					{"internalType": "uint128[]", "name": "prices", "type": "uint128[]"},
+					{"internalType": "uint128[]", "name": "amounts", "type": "uint128[]"},
+					{"internalType": "uint8[]", "name": "takerSides", "type": "uint8[]"},
+					{"internalType": "bytes[]", "name": "takerSignatures", "type": "bytes[]"},
+					{"internalType": "bytes[]", "name": "makerSignatures", "type": "bytes[]"},
+					{
+						"components": [` + compactOrderComponents + `],
+						"internalType": "struct OptimizedSettlement.CompactOrder[]",
+						"name": "takerOrders",
+						"type": "tuple[]"
+					},
+					{
+						"components": [` + compactOrderComponents + `],
+						"internalType": "struct OptimizedSettlement.CompactOrder[]",
+						"name": "makerOrders",
+						"type": "tuple[]"
+					}
+				],
+				"internalType": "struct OptimizedSettlement.BatchFill",
+				"name": "fills",
+				"type": "tuple"
+			}
+		],
+		"name": "batchSettleTrades",
+		"outputs": [],
+		"stateMutability": "nonpayable",
+		"type": "function"
+	},
+	{
+		"inputs": [
+			{
+				"components": [
+					{
+						"components": [` + compactOrderComponents + `],
+						"internalType": "struct OptimizedSettlement.CompactOrder[]",
+						"name": "orders",
+						"type": "tuple[]"
+					},
+					{"internalType": "bytes32[]", "name": "orderHashes", "type": "bytes32[]"},
+					{"internalType": "bytes[]", "name": "signatures", "type": "bytes[]"},
+					{"internalType": "uint16[]", "name": "takerIndexes", "type": "uint16[]"},
+					{"internalType": "uint16[]", "name": "makerIndexes", "type": "uint16[]"},
+					{"internalType": "uint128[]", "name": "prices", "type": "uint128[]"},
+					{"internalType": "uint128[]", "name": "amounts", "type": "uint128[]"},
+					{"internalType": "uint8[]", "name": "takerSides", "type": "uint8[]"}
+				],
+				"internalType": "struct OptimizedSettlement.IndexedBatchFill",
+				"name": "fills",
+				"type": "tuple"
+			}
+		],
+		"name": "batchSettleTradesIndexed",
+		"outputs": [],
+		"stateMutability": "nonpayable",
+		"type": "function"
+	},
+	{
+		"inputs": [
+			{"internalType": "bytes32", "name": "root", "type": "bytes32"},
+			{"internalType": "uint256", "name": "fillCount", "type": "uint256"}
+		],
+		"name": "submitSettlementRoot",
+		"outputs": [],
+		"stateMutability": "nonpayable",
+		"type": "function"
 	}
-}
\ No newline at end of file
+]`
+
+// compactOrderComponents CompactOrder 结构体字段
+const compactOrderComponents = `
+	{"internalType": "address", "name": "userAddress", "type": "address"},
+	{"internalType": "address", "name": "baseToken", "type": "address"},
+	{"internalType": "address", "name": "quoteToken", "type": "address"},
+	{"internalType": "uint128", "name": "price", "type": "uint128"},
+	{"internalType": "uint128", "name": "amount", "type": "uint128"},
+	{"internalType": "uint64", "name": "expiresAt", "type": "uint64"},
+	{"internalType": "uint64", "name": "nonce", "type": "uint64"},
+	{"internalType": "uint8", "name": "side", "type": "uint8"},
+	{"internalType": "uint8", "name": "orderType", "type": "uint8"}`
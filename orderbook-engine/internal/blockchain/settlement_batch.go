@@ -0,0 +1,166 @@
+package blockchain
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"orderbook-engine/internal/gasbudget"
+)
+
+// IndexedBatchFill 按订单去重的批量成交结构（匹配Solidity）
+// 同一订单（通常是被多笔吃单成交的maker挂单）只传一次订单结构和签名，成交以下标引用
+type IndexedBatchFill struct {
+	Orders       []CompactOrder
+	OrderHashes  [][32]byte
+	Signatures   [][]byte
+	TakerIndexes []uint16
+	MakerIndexes []uint16
+	Prices       []*big.Int
+	Amounts      []*big.Int
+	TakerSides   []uint8
+}
+
+// buildIndexedBatchFill 构建按订单去重的批量成交数据
+func buildIndexedBatchFill(settlements []*PendingSettlement) *IndexedBatchFill {
+	batchFill := &IndexedBatchFill{
+		TakerIndexes: make([]uint16, len(settlements)),
+		MakerIndexes: make([]uint16, len(settlements)),
+		Prices:       make([]*big.Int, len(settlements)),
+		Amounts:      make([]*big.Int, len(settlements)),
+		TakerSides:   make([]uint8, len(settlements)),
+	}
+
+	indexes := make(map[[32]byte]uint16)
+	indexOf := func(hash [32]byte, order *CompactOrder, signature []byte) uint16 {
+		if index, exists := indexes[hash]; exists {
+			return index
+		}
+		index := uint16(len(batchFill.Orders))
+		indexes[hash] = index
+		batchFill.Orders = append(batchFill.Orders, *order)
+		batchFill.OrderHashes = append(batchFill.OrderHashes, hash)
+		batchFill.Signatures = append(batchFill.Signatures, signature)
+		return index
+	}
+
+	for i, settlement := range settlements {
+		batchFill.TakerIndexes[i] = indexOf(settlement.TakerOrderHash, settlement.TakerOrder, settlement.TakerSignature)
+		batchFill.MakerIndexes[i] = indexOf(settlement.MakerOrderHash, settlement.MakerOrder, settlement.MakerSignature)
+		batchFill.Prices[i] = settlement.Price
+		batchFill.Amounts[i] = settlement.Amount
+		batchFill.TakerSides[i] = settlement.TakerSide
+	}
+	return batchFill
+}
+
+// SetIndexedBatches 设置是否使用按订单去重的 batchSettleTradesIndexed 提交
+// 需要结算合约已升级到包含该函数的版本，关闭时使用逐笔携带完整订单的 batchSettleTrades
+func (sm *SettlementManager) SetIndexedBatches(enabled bool) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.indexedBatches = enabled
+}
+
+// batchCall 批量结算调用的合约方法与参数
+func (sm *SettlementManager) batchCall(settlements []*PendingSettlement) (string, interface{}) {
+	sm.mu.RLock()
+	indexed := sm.indexedBatches
+	sm.mu.RUnlock()
+
+	if indexed {
+		return "batchSettleTradesIndexed", *buildIndexedBatchFill(settlements)
+	}
+	return "batchSettleTrades", *buildBatchFill(settlements)
+}
+
+// tokenPair 结算项的交易对（基础代币、报价代币）
+type tokenPair struct {
+	base  common.Address
+	quote common.Address
+}
+
+// settlementPair 结算项所属的交易对，同一批次只打包同一交易对的成交
+func settlementPair(settlement *PendingSettlement) tokenPair {
+	if settlement.TakerOrder == nil {
+		return tokenPair{}
+	}
+	return tokenPair{base: settlement.TakerOrder.BaseToken, quote: settlement.TakerOrder.QuoteToken}
+}
+
+// uniqueOrders 批次中不重复的订单数量
+func uniqueOrders(settlements []*PendingSettlement) int {
+	seen := make(map[[32]byte]struct{}, len(settlements)*2)
+	for _, settlement := range settlements {
+		seen[settlement.TakerOrderHash] = struct{}{}
+		seen[settlement.MakerOrderHash] = struct{}{}
+	}
+	return len(seen)
+}
+
+// BatchSample 单个已上链批次的Gas与calldata
+type BatchSample struct {
+	TxHash        string    `json:"tx_hash"`
+	Method        string    `json:"method"`
+	Fills         int       `json:"fills"`
+	Orders        int       `json:"orders"` // 去重后的订单数
+	GasUsed       uint64    `json:"gas_used"`
+	CalldataBytes int       `json:"calldata_bytes"`
+	GasPerFill    uint64    `json:"gas_per_fill"`
+	SettledAt     time.Time `json:"settled_at"`
+}
+
+// BatchMetrics 已上链批次的累计Gas与calldata统计
+type BatchMetrics struct {
+	ChainID              uint64            `json:"chain_id"`
+	Batches              int               `json:"batches"`
+	Fills                int               `json:"fills"`
+	Orders               int               `json:"orders"`
+	GasUsed              uint64            `json:"gas_used"`
+	CalldataBytes        int               `json:"calldata_bytes"`
+	GasPerFill           uint64            `json:"gas_per_fill"`            // 平均每笔成交消耗的Gas
+	CalldataBytesPerFill int               `json:"calldata_bytes_per_fill"` // 平均每笔成交的calldata字节数
+	LastBatch            *BatchSample      `json:"last_batch,omitempty"`
+	GasBudget            *gasbudget.Status `json:"gas_budget,omitempty"` // 结算Gas支出预算，未设置时为空
+}
+
+// recordBatch 记录已上链批次的Gas与calldata
+func (sm *SettlementManager) recordBatch(sample *BatchSample) {
+	if sample.Fills > 0 {
+		sample.GasPerFill = sample.GasUsed / uint64(sample.Fills)
+	}
+
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.metrics.Batches++
+	sm.metrics.Fills += sample.Fills
+	sm.metrics.Orders += sample.Orders
+	sm.metrics.GasUsed += sample.GasUsed
+	sm.metrics.CalldataBytes += sample.CalldataBytes
+	sm.metrics.LastBatch = sample
+}
+
+// Metrics 获取已上链批次的Gas与calldata统计
+func (sm *SettlementManager) Metrics() BatchMetrics {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	metrics := sm.metrics
+	if sm.chainID != nil {
+		metrics.ChainID = sm.chainID.Uint64()
+	}
+	if metrics.Fills > 0 {
+		metrics.GasPerFill = metrics.GasUsed / uint64(metrics.Fills)
+		metrics.CalldataBytesPerFill = metrics.CalldataBytes / metrics.Fills
+	}
+	if metrics.LastBatch != nil {
+		last := *metrics.LastBatch
+		metrics.LastBatch = &last
+	}
+	if sm.gasBudget != nil {
+		status := sm.gasBudget.Check(time.Now())
+		metrics.GasBudget = &status
+	}
+	return metrics
+}
@@ -0,0 +1,95 @@
+package blockchain
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	weth = common.HexToAddress("0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2")
+	usdc = common.HexToAddress("0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48")
+	wbtc = common.HexToAddress("0x2260FAC5E5542a773Aa44fBCfeDf7C193bc2C599")
+)
+
+func newPairSettlement(base common.Address, takerHash, makerHash byte) *PendingSettlement {
+	order := &CompactOrder{BaseToken: base, QuoteToken: usdc, Price: big.NewInt(2000), Amount: big.NewInt(10)}
+	return &PendingSettlement{
+		ID:             uuid.New(),
+		TakerOrderHash: [32]byte{takerHash},
+		MakerOrderHash: [32]byte{makerHash},
+		Price:          big.NewInt(2000),
+		Amount:         big.NewInt(1),
+		TakerOrder:     order,
+		MakerOrder:     order,
+		TakerSignature: make([]byte, 65),
+		MakerSignature: make([]byte, 65),
+	}
+}
+
+func TestIndexedBatchDeduplicatesOrders(t *testing.T) {
+	// 三笔吃单成交同一 maker 挂单
+	settlements := []*PendingSettlement{
+		newPairSettlement(weth, 1, 9),
+		newPairSettlement(weth, 2, 9),
+		newPairSettlement(weth, 3, 9),
+	}
+
+	batchFill := buildIndexedBatchFill(settlements)
+	require.Len(t, batchFill.Orders, 4)
+	assert.Equal(t, [][32]byte{{1}, {9}, {2}, {3}}, batchFill.OrderHashes)
+	assert.Equal(t, []uint16{0, 2, 3}, batchFill.TakerIndexes)
+	assert.Equal(t, []uint16{1, 1, 1}, batchFill.MakerIndexes)
+	assert.Equal(t, 4, uniqueOrders(settlements))
+
+	// 去重后的 calldata 小于逐笔携带完整订单的编码
+	parsedABI, err := abi.JSON(strings.NewReader(batchSettleABI))
+	require.NoError(t, err)
+	indexed, err := parsedABI.Pack("batchSettleTradesIndexed", *batchFill)
+	require.NoError(t, err)
+	legacy, err := parsedABI.Pack("batchSettleTrades", *buildBatchFill(settlements))
+	require.NoError(t, err)
+	assert.Less(t, len(indexed), len(legacy))
+}
+
+func TestTakeBatchGroupsByPair(t *testing.T) {
+	sm := &SettlementManager{batchSize: 10}
+	sm.pendingSettlements = []*PendingSettlement{
+		newPairSettlement(weth, 1, 9),
+		newPairSettlement(wbtc, 2, 8),
+		newPairSettlement(weth, 3, 9),
+	}
+
+	now := time.Now()
+	first := sm.takeBatch(now)
+	require.Len(t, first, 2)
+	assert.Equal(t, weth, first[0].TakerOrder.BaseToken)
+	assert.Equal(t, weth, first[1].TakerOrder.BaseToken)
+
+	second := sm.takeBatch(now)
+	require.Len(t, second, 1)
+	assert.Equal(t, wbtc, second[0].TakerOrder.BaseToken)
+	assert.Empty(t, sm.takeBatch(now))
+}
+
+func TestBatchMetrics(t *testing.T) {
+	sm := &SettlementManager{chainID: big.NewInt(31337)}
+	sm.recordBatch(&BatchSample{Fills: 4, Orders: 5, GasUsed: 400000, CalldataBytes: 2000})
+	sm.recordBatch(&BatchSample{Fills: 1, Orders: 2, GasUsed: 150000, CalldataBytes: 1000})
+
+	metrics := sm.Metrics()
+	assert.Equal(t, uint64(31337), metrics.ChainID)
+	assert.Equal(t, 2, metrics.Batches)
+	assert.Equal(t, 5, metrics.Fills)
+	assert.Equal(t, uint64(110000), metrics.GasPerFill)
+	assert.Equal(t, 600, metrics.CalldataBytesPerFill)
+	require.NotNil(t, metrics.LastBatch)
+	assert.Equal(t, uint64(150000), metrics.LastBatch.GasPerFill)
+}
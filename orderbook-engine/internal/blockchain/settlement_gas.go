@@ -0,0 +1,71 @@
+package blockchain
+
+import (
+	"log"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"orderbook-engine/internal/gasbudget"
+)
+
+// SetGasBudget 设置结算Gas支出预算，为空时不限制
+// 超出预算后按预算的处理方式暂停提交（成交继续排队）或按 SlowInterval 降低提交频率，窗口滚动回到预算内后自动恢复
+func (sm *SettlementManager) SetGasBudget(budget *gasbudget.Tracker) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.gasBudget = budget
+}
+
+// GasBudget 当前的Gas支出预算状态，未设置预算时为空
+func (sm *SettlementManager) GasBudget() *gasbudget.Status {
+	sm.mu.RLock()
+	budget := sm.gasBudget
+	sm.mu.RUnlock()
+	if budget == nil {
+		return nil
+	}
+
+	status := budget.Check(time.Now())
+	return &status
+}
+
+// gasBudgetAllows 预算是否允许现在提交批次，调用方需持有 submitMu
+func (sm *SettlementManager) gasBudgetAllows(now time.Time) bool {
+	sm.mu.RLock()
+	budget := sm.gasBudget
+	sm.mu.RUnlock()
+	if budget == nil {
+		return true
+	}
+
+	status := budget.Check(now)
+	if !status.Exceeded {
+		return true
+	}
+	if status.Action == gasbudget.ActionSlow {
+		return now.Sub(sm.lastSubmitAt) >= budget.SlowInterval()
+	}
+	return false
+}
+
+// recordGasSpend 记录结算交易的Gas支出，回滚的交易同样消耗Gas
+func (sm *SettlementManager) recordGasSpend(receipt *types.Receipt, gasPrice *big.Int) {
+	sm.mu.RLock()
+	budget := sm.gasBudget
+	sm.mu.RUnlock()
+	if budget == nil {
+		return
+	}
+
+	// 部分节点的回执不含 effectiveGasPrice，按提交时的Gas价格估算
+	if receipt.EffectiveGasPrice != nil && receipt.EffectiveGasPrice.Sign() > 0 {
+		gasPrice = receipt.EffectiveGasPrice
+	}
+	status := budget.Record(time.Now(), receipt.GasUsed, gasPrice)
+	if status.Exceeded {
+		log.Printf("⛽ Settlement gas budget exceeded (%s): hourly %s/%s ETH, daily %s/%s ETH, action: %s",
+			status.Window, status.HourlySpent, status.HourlyBudget, status.DailySpent, status.DailyBudget, status.Action)
+	}
+}
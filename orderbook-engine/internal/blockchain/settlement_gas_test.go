@@ -0,0 +1,55 @@
+package blockchain
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"orderbook-engine/internal/gasbudget"
+)
+
+func TestGasBudgetPausesSubmission(t *testing.T) {
+	sm := &SettlementManager{}
+	assert.True(t, sm.gasBudgetAllows(time.Now()))
+
+	sm.SetGasBudget(gasbudget.NewTracker(1, gasbudget.Config{Hourly: decimal.RequireFromString("0.01"), Action: gasbudget.ActionPause}))
+	assert.True(t, sm.gasBudgetAllows(time.Now()))
+
+	// 回执的 effectiveGasPrice 优先于提交时的Gas价格：500000 gas × 20 gwei = 0.01 ETH
+	receipt := &types.Receipt{GasUsed: 500000, EffectiveGasPrice: big.NewInt(20_000_000_000)}
+	sm.recordGasSpend(receipt, big.NewInt(1))
+	assert.False(t, sm.gasBudgetAllows(time.Now()))
+
+	status := sm.GasBudget()
+	require.NotNil(t, status)
+	assert.True(t, status.Exceeded)
+	assert.True(t, status.HourlySpent.Equal(decimal.RequireFromString("0.01")))
+	metrics := sm.Metrics()
+	require.NotNil(t, metrics.GasBudget)
+	assert.True(t, metrics.GasBudget.Exceeded)
+
+	// 一小时后窗口滚动，恢复提交
+	assert.True(t, sm.gasBudgetAllows(time.Now().Add(time.Hour+time.Minute)))
+}
+
+func TestGasBudgetSlowsSubmission(t *testing.T) {
+	sm := &SettlementManager{}
+	sm.SetGasBudget(gasbudget.NewTracker(1, gasbudget.Config{
+		Hourly:       decimal.RequireFromString("0.001"),
+		Action:       gasbudget.ActionSlow,
+		SlowInterval: time.Minute,
+	}))
+	sm.recordGasSpend(&types.Receipt{GasUsed: 100000}, big.NewInt(20_000_000_000))
+
+	now := time.Now()
+	sm.lastSubmitAt = now.Add(-30 * time.Second)
+	assert.False(t, sm.gasBudgetAllows(now))
+
+	sm.lastSubmitAt = now.Add(-time.Minute)
+	assert.True(t, sm.gasBudgetAllows(now))
+}
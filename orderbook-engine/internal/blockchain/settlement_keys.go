@@ -0,0 +1,136 @@
+package blockchain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"orderbook-engine/internal/txsigner"
+)
+
+// rotationPollInterval 密钥轮换时检查旧账户在途交易的间隔
+const rotationPollInterval = 2 * time.Second
+
+var (
+	// ErrSignerUnchanged 新签名者与当前结算账户地址相同
+	ErrSignerUnchanged = errors.New("new signer has the same address as the current settlement account")
+	// ErrSignerUnfunded 新结算账户没有支付Gas的余额
+	ErrSignerUnfunded = errors.New("new settlement account has no balance for gas")
+)
+
+// accountReader 查询账户nonce与余额
+type accountReader interface {
+	PendingNonceAt(ctx context.Context, account common.Address) (uint64, error)
+	NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error)
+	BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error)
+}
+
+// KeyRotation 结算密钥轮换记录
+type KeyRotation struct {
+	ChainID      uint64    `json:"chain_id"`
+	OldAddress   string    `json:"old_address"`
+	NewAddress   string    `json:"new_address"`
+	DrainedNonce uint64    `json:"drained_nonce"` // 旧账户全部交易上链后的nonce
+	RotatedAt    time.Time `json:"rotated_at"`
+}
+
+// RotateSigner 轮换结算账户签名者
+// 轮换期间暂停提交新批次，等待旧账户已发出的交易全部上链（pending nonce 与已确认 nonce 一致）后再切换，
+// 避免旧账户的在途交易被遗留在 nonce 空间中；ctx 超时则放弃轮换，继续使用旧账户
+// 新账户需要已有支付Gas的余额；提交默克尔根等仅限合约 owner 的调用还需事先把权限转给新账户
+func (sm *SettlementManager) RotateSigner(ctx context.Context, signer txsigner.Signer) (*KeyRotation, error) {
+	// 等待进行中的批次完成，之后不再用旧账户发出新交易
+	sm.submitMu.Lock()
+	defer sm.submitMu.Unlock()
+
+	oldAddress := sm.SignerAddress()
+	newAddress := signer.Address()
+	if newAddress == oldAddress {
+		return nil, ErrSignerUnchanged
+	}
+
+	balance, err := sm.accounts.BalanceAt(ctx, newAddress, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get balance of %s: %w", newAddress.Hex(), err)
+	}
+	if balance.Sign() == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrSignerUnfunded, newAddress.Hex())
+	}
+
+	log.Printf("🔑 Rotating settlement key %s -> %s, draining in-flight transactions", oldAddress.Hex(), newAddress.Hex())
+	nonce, err := sm.drainNonces(ctx, oldAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	rotation := &KeyRotation{
+		OldAddress:   oldAddress.Hex(),
+		NewAddress:   newAddress.Hex(),
+		DrainedNonce: nonce,
+		RotatedAt:    time.Now(),
+	}
+	if sm.chainID != nil {
+		rotation.ChainID = sm.chainID.Uint64()
+	}
+
+	sm.mu.Lock()
+	sm.signer = signer
+	sm.auth = txsigner.NewTransactOpts(signer, sm.chainID)
+	sm.lastRotation = rotation
+	sm.mu.Unlock()
+
+	log.Printf("✅ Settlement key rotated to %s (old account drained at nonce %d)", newAddress.Hex(), nonce)
+	return rotation, nil
+}
+
+// drainNonces 等待账户已发出的交易全部上链，返回最终的nonce
+func (sm *SettlementManager) drainNonces(ctx context.Context, account common.Address) (uint64, error) {
+	for {
+		pending, err := sm.accounts.PendingNonceAt(ctx, account)
+		if err != nil {
+			return 0, fmt.Errorf("failed to get pending nonce: %w", err)
+		}
+		confirmed, err := sm.accounts.NonceAt(ctx, account, nil)
+		if err != nil {
+			return 0, fmt.Errorf("failed to get confirmed nonce: %w", err)
+		}
+		if pending <= confirmed {
+			return confirmed, nil
+		}
+
+		log.Printf("⏳ Waiting for %d in-flight transactions from %s", pending-confirmed, account.Hex())
+		select {
+		case <-ctx.Done():
+			return 0, fmt.Errorf("in-flight transactions from %s not drained (pending nonce %d, confirmed %d): %w",
+				account.Hex(), pending, confirmed, ctx.Err())
+		case <-time.After(rotationPollInterval):
+		}
+	}
+}
+
+// LastRotation 最近一次结算密钥轮换，未轮换过时为空
+func (sm *SettlementManager) LastRotation() *KeyRotation {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	return sm.lastRotation
+}
+
+// SignerAddress 当前结算账户地址，未配置签名者时为零地址
+func (sm *SettlementManager) SignerAddress() common.Address {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	return sm.signerAddressLocked()
+}
+
+// signerAddressLocked 当前结算账户地址，调用方需持有读锁
+func (sm *SettlementManager) signerAddressLocked() common.Address {
+	if sm.auth == nil {
+		return common.Address{}
+	}
+	return sm.auth.From
+}
@@ -0,0 +1,95 @@
+package blockchain
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"orderbook-engine/internal/txsigner"
+)
+
+// fakeAccounts 固定的账户nonce与余额
+type fakeAccounts struct {
+	pending   uint64
+	confirmed uint64
+	balances  map[common.Address]*big.Int
+}
+
+func (a *fakeAccounts) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	return a.pending, nil
+}
+
+func (a *fakeAccounts) NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error) {
+	return a.confirmed, nil
+}
+
+func (a *fakeAccounts) BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error) {
+	if balance, ok := a.balances[account]; ok {
+		return balance, nil
+	}
+	return big.NewInt(0), nil
+}
+
+func newTestSigner(t *testing.T) *txsigner.LocalSigner {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	signer, err := txsigner.NewLocalSigner(common.Bytes2Hex(crypto.FromECDSA(key)))
+	require.NoError(t, err)
+	return signer
+}
+
+func newRotatableManager(t *testing.T, accounts *fakeAccounts) *SettlementManager {
+	chainID := big.NewInt(31337)
+	return &SettlementManager{
+		chainID:  chainID,
+		auth:     txsigner.NewTransactOpts(newTestSigner(t), chainID),
+		accounts: accounts,
+	}
+}
+
+func TestRotateSigner(t *testing.T) {
+	accounts := &fakeAccounts{pending: 7, confirmed: 7, balances: map[common.Address]*big.Int{}}
+	sm := newRotatableManager(t, accounts)
+	oldAddress := sm.SignerAddress()
+
+	next := newTestSigner(t)
+	_, err := sm.RotateSigner(context.Background(), next)
+	assert.ErrorIs(t, err, ErrSignerUnfunded)
+
+	accounts.balances[next.Address()] = big.NewInt(1e18)
+	rotation, err := sm.RotateSigner(context.Background(), next)
+	require.NoError(t, err)
+	assert.Equal(t, oldAddress.Hex(), rotation.OldAddress)
+	assert.Equal(t, next.Address().Hex(), rotation.NewAddress)
+	assert.Equal(t, uint64(7), rotation.DrainedNonce)
+	assert.Equal(t, next.Address(), sm.SignerAddress())
+	assert.Equal(t, rotation, sm.LastRotation())
+
+	_, err = sm.RotateSigner(context.Background(), next)
+	assert.ErrorIs(t, err, ErrSignerUnchanged)
+}
+
+func TestRotateSignerWaitsForInFlightTransactions(t *testing.T) {
+	// 旧账户有一笔交易尚未上链
+	accounts := &fakeAccounts{pending: 8, confirmed: 7, balances: map[common.Address]*big.Int{}}
+	sm := newRotatableManager(t, accounts)
+	oldAddress := sm.SignerAddress()
+
+	next := newTestSigner(t)
+	accounts.balances[next.Address()] = big.NewInt(1e18)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := sm.RotateSigner(ctx, next)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// 超时放弃轮换，继续使用旧账户
+	assert.Equal(t, oldAddress, sm.SignerAddress())
+	assert.Nil(t, sm.LastRotation())
+}
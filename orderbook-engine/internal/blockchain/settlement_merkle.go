@@ -0,0 +1,78 @@
+package blockchain
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"orderbook-engine/internal/merkle"
+)
+
+// SetMerkleSettlement 设置默克尔批次结算：批次只提交成交的默克尔根（submitSettlementRoot），
+// 逐笔证明写入 store 供用户和监督方核验。pairs 为使用默克尔批次的交易对，为空时全部交易对；store 为空时关闭
+// 默克尔批次不在链上逐笔转移资产，需结算合约已升级到包含 submitSettlementRoot 的版本
+func (sm *SettlementManager) SetMerkleSettlement(store merkle.Store, pairs []string) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.merkleStore = store
+	sm.merklePairs = make(map[string]bool, len(pairs))
+	for _, pair := range pairs {
+		sm.merklePairs[strings.ToUpper(pair)] = true
+	}
+}
+
+// merkleBatch 批次是否以默克尔根提交（同一批次只含同一交易对，按第一笔判断）
+func (sm *SettlementManager) merkleBatch(settlements []*PendingSettlement) bool {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	if sm.merkleStore == nil || len(settlements) == 0 {
+		return false
+	}
+	return len(sm.merklePairs) == 0 || sm.merklePairs[strings.ToUpper(settlements[0].TradingPair)]
+}
+
+// buildMerkleBatch 构建批次的默克尔树与逐笔证明
+func (sm *SettlementManager) buildMerkleBatch(settlements []*PendingSettlement) (*merkle.Batch, error) {
+	fills := make([]merkle.Fill, len(settlements))
+	for i, settlement := range settlements {
+		fills[i] = merkle.Fill{
+			FillID:         settlement.ID,
+			TakerOrderHash: settlement.TakerOrderHash,
+			MakerOrderHash: settlement.MakerOrderHash,
+			Price:          settlement.Price,
+			Amount:         settlement.Amount,
+			TakerSide:      settlement.TakerSide,
+		}
+	}
+
+	var chainID uint64
+	if sm.chainID != nil {
+		chainID = sm.chainID.Uint64()
+	}
+	batch, _, err := merkle.NewBatch(chainID, fills)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build merkle batch: %w", err)
+	}
+	batch.Contract = sm.settlementContract.Hex()
+	return batch, nil
+}
+
+// saveMerkleBatch 保存已上链的默克尔批次
+// 根已上链，保存失败不影响结算结果，只记录错误（证明可由成交明细重新计算）
+func (sm *SettlementManager) saveMerkleBatch(batch *merkle.Batch, txHash string) {
+	batch.TxHash = txHash
+	batch.SubmittedAt = time.Now()
+
+	sm.mu.RLock()
+	store := sm.merkleStore
+	sm.mu.RUnlock()
+	if store == nil {
+		return
+	}
+	if err := store.SaveBatch(batch); err != nil {
+		log.Printf("❌ Failed to save merkle proofs for root %s (tx %s): %v", batch.Root, txHash, err)
+		return
+	}
+	log.Printf("🌳 Merkle root %s submitted for %d fills", batch.Root, batch.Fills)
+}
@@ -0,0 +1,70 @@
+package blockchain
+
+import (
+	"math/big"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"orderbook-engine/internal/merkle"
+)
+
+func TestMerkleBatchPairSelection(t *testing.T) {
+	store, err := merkle.NewFileStore(filepath.Join(t.TempDir(), "proofs.jsonl"))
+	require.NoError(t, err)
+
+	ethFill := newPairSettlement(weth, 1, 9)
+	ethFill.TradingPair = "WETH-USDC"
+	btcFill := newPairSettlement(wbtc, 2, 8)
+	btcFill.TradingPair = "WBTC-USDC"
+
+	sm := &SettlementManager{}
+	assert.False(t, sm.merkleBatch([]*PendingSettlement{ethFill}))
+
+	sm.SetMerkleSettlement(store, []string{"weth-usdc"})
+	assert.True(t, sm.merkleBatch([]*PendingSettlement{ethFill}))
+	assert.False(t, sm.merkleBatch([]*PendingSettlement{btcFill}))
+
+	// 未指定交易对时全部交易对使用默克尔批次
+	sm.SetMerkleSettlement(store, nil)
+	assert.True(t, sm.merkleBatch([]*PendingSettlement{btcFill}))
+}
+
+func TestBuildMerkleBatch(t *testing.T) {
+	store, err := merkle.NewFileStore(filepath.Join(t.TempDir(), "proofs.jsonl"))
+	require.NoError(t, err)
+	sm := &SettlementManager{chainID: big.NewInt(31337), settlementContract: common.HexToAddress("0x01")}
+	sm.SetMerkleSettlement(store, nil)
+
+	settlements := []*PendingSettlement{
+		newPairSettlement(weth, 1, 9),
+		newPairSettlement(weth, 2, 9),
+		newPairSettlement(weth, 3, 9),
+	}
+	batch, err := sm.buildMerkleBatch(settlements)
+	require.NoError(t, err)
+	assert.Equal(t, 3, batch.Fills)
+
+	// 根以 submitSettlementRoot 提交
+	parsedABI, err := abi.JSON(strings.NewReader(batchSettleABI))
+	require.NoError(t, err)
+	_, err = parsedABI.Pack("submitSettlementRoot", common.HexToHash(batch.Root), big.NewInt(int64(batch.Fills)))
+	require.NoError(t, err)
+
+	sm.saveMerkleBatch(batch, "0xabc")
+	proof, err := store.FillProof(settlements[2].ID)
+	require.NoError(t, err)
+	assert.Equal(t, "0xabc", proof.TxHash)
+	assert.Equal(t, uint64(31337), proof.ChainID)
+
+	siblings := make([][32]byte, len(proof.Siblings))
+	for i, sibling := range proof.Siblings {
+		siblings[i] = common.HexToHash(sibling)
+	}
+	assert.True(t, merkle.Verify(common.HexToHash(proof.Root), common.HexToHash(proof.Leaf), siblings))
+}
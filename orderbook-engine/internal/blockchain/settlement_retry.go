@@ -0,0 +1,188 @@
+package blockchain
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+
+	ordertypes "orderbook-engine/internal/types"
+)
+
+// ErrDeadLetterNotFound 死信结算项不存在
+var ErrDeadLetterNotFound = errors.New("dead letter not found")
+
+// RetryPolicy 结算失败重试策略
+type RetryPolicy struct {
+	MaxAttempts int           // 单笔结算最多失败次数，超过后进入死信队列
+	BaseBackoff time.Duration // 首次重试等待时间，之后按指数增长
+	MaxBackoff  time.Duration // 最长等待时间
+}
+
+// DefaultRetryPolicy 默认重试策略
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 5,
+		BaseBackoff: 5 * time.Second,
+		MaxBackoff:  5 * time.Minute,
+	}
+}
+
+// backoff 第 attempts 次失败后的等待时间
+func (p RetryPolicy) backoff(attempts int) time.Duration {
+	delay := p.BaseBackoff
+	for i := 1; i < attempts && delay < p.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > p.MaxBackoff {
+		delay = p.MaxBackoff
+	}
+	return delay
+}
+
+// DeadLetter 死信队列中的结算项
+type DeadLetter struct {
+	ID             uuid.UUID `json:"id"`
+	ChainID        uint64    `json:"chain_id"`
+	FillID         uuid.UUID `json:"fill_id,omitempty"`
+	TakerOrderHash string    `json:"taker_order_hash"`
+	MakerOrderHash string    `json:"maker_order_hash"`
+	Price          string    `json:"price"`
+	Amount         string    `json:"amount"`
+	Attempts       int       `json:"attempts"`
+	LastError      string    `json:"last_error"`
+	QueuedAt       time.Time `json:"queued_at"`
+}
+
+// SetRetryPolicy 设置结算失败重试策略
+func (sm *SettlementManager) SetRetryPolicy(policy RetryPolicy) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.retryPolicy = policy
+}
+
+// takeBatch 取出可提交的结算项
+// 曾随失败批次提交的结算项单独提交，避免单笔问题交易反复拖垮整批；
+// 同一批次只打包最早的可提交结算项所属交易对的成交，同一挂单被多笔成交引用时可去重订单结构
+func (sm *SettlementManager) takeBatch(now time.Time) []*PendingSettlement {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	var batch []*PendingSettlement
+	remaining := sm.pendingSettlements[:0]
+	for _, settlement := range sm.pendingSettlements {
+		eligible := !settlement.NextAttemptAt.After(now) && len(batch) < sm.batchSize
+		if eligible && len(batch) > 0 && (settlement.Isolated || batch[0].Isolated) {
+			eligible = false
+		}
+		if eligible && len(batch) > 0 && settlementPair(settlement) != settlementPair(batch[0]) {
+			eligible = false
+		}
+		if eligible {
+			batch = append(batch, settlement)
+		} else {
+			remaining = append(remaining, settlement)
+		}
+	}
+	sm.pendingSettlements = remaining
+	return batch
+}
+
+// handleBatchFailure 记录失败次数并按退避时间重新入队，超过重试次数的进入死信队列
+func (sm *SettlementManager) handleBatchFailure(batch []*PendingSettlement, err error) {
+	now := time.Now()
+	var deadLettered []*PendingSettlement
+
+	sm.mu.Lock()
+	requeue := make([]*PendingSettlement, 0, len(batch))
+	for _, settlement := range batch {
+		settlement.Attempts++
+		settlement.LastError = err.Error()
+		settlement.Isolated = settlement.Isolated || len(batch) > 1
+
+		if settlement.Attempts >= sm.retryPolicy.MaxAttempts {
+			sm.deadLetters[settlement.ID] = settlement
+			deadLettered = append(deadLettered, settlement)
+			continue
+		}
+		settlement.NextAttemptAt = now.Add(sm.retryPolicy.backoff(settlement.Attempts))
+		requeue = append(requeue, settlement)
+	}
+	sm.pendingSettlements = append(requeue, sm.pendingSettlements...)
+	sm.mu.Unlock()
+
+	for _, settlement := range deadLettered {
+		log.Printf("☠️  Settlement %s moved to dead-letter queue after %d attempts: %s",
+			settlement.ID, settlement.Attempts, settlement.LastError)
+	}
+}
+
+// DeadLetters 获取死信队列（按入队时间排序）
+func (sm *SettlementManager) DeadLetters() []*DeadLetter {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	var chainID uint64
+	if sm.chainID != nil {
+		chainID = sm.chainID.Uint64()
+	}
+
+	result := make([]*DeadLetter, 0, len(sm.deadLetters))
+	for _, settlement := range sm.deadLetters {
+		result = append(result, &DeadLetter{
+			ID:             settlement.ID,
+			ChainID:        chainID,
+			FillID:         settlement.FillID,
+			TakerOrderHash: fmt.Sprintf("0x%x", settlement.TakerOrderHash),
+			MakerOrderHash: fmt.Sprintf("0x%x", settlement.MakerOrderHash),
+			Price:          settlement.Price.String(),
+			Amount:         settlement.Amount.String(),
+			Attempts:       settlement.Attempts,
+			LastError:      settlement.LastError,
+			QueuedAt:       settlement.Timestamp,
+		})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].QueuedAt.Before(result[j].QueuedAt)
+	})
+	return result
+}
+
+// RetryDeadLetter 将死信结算项重置后重新入队，单独提交
+func (sm *SettlementManager) RetryDeadLetter(id uuid.UUID) error {
+	sm.mu.Lock()
+	settlement, exists := sm.deadLetters[id]
+	if !exists {
+		sm.mu.Unlock()
+		return fmt.Errorf("%w: %s", ErrDeadLetterNotFound, id)
+	}
+	delete(sm.deadLetters, id)
+	settlement.Attempts = 0
+	settlement.NextAttemptAt = time.Time{}
+	settlement.Isolated = true
+	sm.pendingSettlements = append(sm.pendingSettlements, settlement)
+	sm.mu.Unlock()
+
+	sm.notify([]*PendingSettlement{settlement}, ordertypes.SettlementStatusPending, "", nil)
+	log.Printf("🔁 Dead-letter settlement %s requeued", id)
+	return nil
+}
+
+// VoidDeadLetter 作废死信结算项，不再提交上链
+func (sm *SettlementManager) VoidDeadLetter(id uuid.UUID) error {
+	sm.mu.Lock()
+	settlement, exists := sm.deadLetters[id]
+	if !exists {
+		sm.mu.Unlock()
+		return fmt.Errorf("%w: %s", ErrDeadLetterNotFound, id)
+	}
+	delete(sm.deadLetters, id)
+	sm.mu.Unlock()
+
+	sm.notify([]*PendingSettlement{settlement}, ordertypes.SettlementStatusVoided, "", nil)
+	log.Printf("🗑️  Dead-letter settlement %s voided", id)
+	return nil
+}
@@ -0,0 +1,61 @@
+package blockchain
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSettlementRetryIsolatesAndDeadLetters(t *testing.T) {
+	sm := &SettlementManager{
+		batchSize:   10,
+		retryPolicy: RetryPolicy{MaxAttempts: 2, BaseBackoff: time.Second, MaxBackoff: time.Minute},
+		deadLetters: make(map[uuid.UUID]*PendingSettlement),
+	}
+	for i := 0; i < 3; i++ {
+		sm.pendingSettlements = append(sm.pendingSettlements, &PendingSettlement{
+			ID:     uuid.New(),
+			Price:  big.NewInt(2000),
+			Amount: big.NewInt(1),
+		})
+	}
+
+	now := time.Now()
+	batch := sm.takeBatch(now)
+	require.Len(t, batch, 3)
+
+	// 整批失败后按退避重新入队，之后逐笔单独提交
+	sm.handleBatchFailure(batch, errors.New("execution reverted"))
+	assert.Empty(t, sm.takeBatch(now), "退避期间不应重新提交")
+
+	later := now.Add(2 * time.Second)
+	poisoned := sm.takeBatch(later)
+	require.Len(t, poisoned, 1)
+	assert.Equal(t, 1, poisoned[0].Attempts)
+	assert.True(t, poisoned[0].Isolated)
+
+	// 单笔再次失败达到上限，进入死信队列，不影响其他结算项
+	sm.handleBatchFailure(poisoned, errors.New("execution reverted"))
+	deadLetters := sm.DeadLetters()
+	require.Len(t, deadLetters, 1)
+	assert.Equal(t, poisoned[0].ID, deadLetters[0].ID)
+	assert.Len(t, sm.takeBatch(later), 1)
+
+	// 重新提交后重置次数
+	require.NoError(t, sm.RetryDeadLetter(poisoned[0].ID))
+	assert.Empty(t, sm.DeadLetters())
+	assert.Equal(t, 0, poisoned[0].Attempts)
+	assert.Error(t, sm.VoidDeadLetter(poisoned[0].ID))
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 10, BaseBackoff: time.Second, MaxBackoff: 10 * time.Second}
+	assert.Equal(t, time.Second, policy.backoff(1))
+	assert.Equal(t, 4*time.Second, policy.backoff(3))
+	assert.Equal(t, 10*time.Second, policy.backoff(8))
+}
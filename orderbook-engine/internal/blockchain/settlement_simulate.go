@@ -0,0 +1,106 @@
+package blockchain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	ordertypes "orderbook-engine/internal/types"
+)
+
+// simulationTimeout 单笔模拟执行的超时
+const simulationTimeout = 10 * time.Second
+
+// SetSimulation 设置提交前是否逐笔模拟执行（eth_call）
+// 开启后批次中模拟回滚的成交（订单过期、授权被撤销、余额不足等）被剔除并以 rejected 状态通知，其余成交照常提交
+func (sm *SettlementManager) SetSimulation(enabled bool) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.simulator = nil
+	if enabled {
+		sm.simulator = sm.client
+	}
+}
+
+// excludeFailingFills 逐笔模拟执行批次，返回可提交的结算项
+// 模拟回滚的结算项不再上链；节点不可用等非回滚错误无法判断结果，照常提交并由失败重试处理
+func (sm *SettlementManager) excludeFailingFills(batch []*PendingSettlement) []*PendingSettlement {
+	sm.mu.RLock()
+	caller := sm.simulator
+	sm.mu.RUnlock()
+	// 默克尔批次不在链上逐笔执行成交，逐笔模拟的结果不适用
+	if caller == nil || sm.merkleBatch(batch) {
+		return batch
+	}
+
+	valid := make([]*PendingSettlement, 0, len(batch))
+	for _, settlement := range batch {
+		err := sm.simulateFill(caller, settlement)
+		if err == nil {
+			valid = append(valid, settlement)
+			continue
+		}
+		if !isRevert(err) {
+			log.Printf("⚠️  Settlement simulation unavailable for %s, submitting without simulation: %v", settlement.ID, err)
+			valid = append(valid, settlement)
+			continue
+		}
+
+		reason := fmt.Errorf("settlement simulation reverted: %s", revertReason(err))
+		sm.mu.Lock()
+		sm.simulationRejected++
+		sm.mu.Unlock()
+		log.Printf("🚫 Settlement %s excluded from batch: %v", settlement.ID, reason)
+		sm.notify([]*PendingSettlement{settlement}, ordertypes.SettlementStatusRejected, "", reason)
+	}
+	return valid
+}
+
+// simulateFill 以结算账户身份对仅含该笔成交的批量结算调用执行 eth_call
+func (sm *SettlementManager) simulateFill(caller ethereum.ContractCaller, settlement *PendingSettlement) error {
+	method, batchFill := sm.batchCall([]*PendingSettlement{settlement})
+	data, err := sm.settlementABI.Pack(method, batchFill)
+	if err != nil {
+		return fmt.Errorf("failed to pack %s: %w", method, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), simulationTimeout)
+	defer cancel()
+
+	msg := ethereum.CallMsg{To: &sm.settlementContract, Data: data}
+	if sm.auth != nil {
+		msg.From = sm.auth.From
+	}
+	_, err = caller.CallContract(ctx, msg, nil)
+	return err
+}
+
+// isRevert 是否为合约执行回滚（而非节点或网络错误）
+func isRevert(err error) bool {
+	var dataErr rpc.DataError
+	if errors.As(err, &dataErr) && dataErr.ErrorData() != nil {
+		return true
+	}
+	return strings.Contains(strings.ToLower(err.Error()), "revert")
+}
+
+// revertReason 解析 Error(string) 回滚原因，无法解析时返回原始错误
+func revertReason(err error) string {
+	var dataErr rpc.DataError
+	if errors.As(err, &dataErr) {
+		if data, ok := dataErr.ErrorData().(string); ok {
+			if reason, unpackErr := abi.UnpackRevert(common.FromHex(data)); unpackErr == nil {
+				return reason
+			}
+		}
+	}
+	return err.Error()
+}
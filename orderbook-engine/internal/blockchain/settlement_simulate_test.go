@@ -0,0 +1,88 @@
+package blockchain
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	ordertypes "orderbook-engine/internal/types"
+)
+
+// fakeCaller 按成交数量决定模拟结果
+type fakeCaller struct {
+	settlementABI abi.ABI
+	results       map[int64]error
+}
+
+func (c *fakeCaller) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	args, err := c.settlementABI.Methods["batchSettleTrades"].Inputs.Unpack(msg.Data[4:])
+	if err != nil {
+		return nil, err
+	}
+	fills := abi.ConvertType(args[0], new(BatchFill)).(*BatchFill)
+	if len(fills.Amounts) != 1 {
+		return nil, errors.New("simulation must contain a single fill")
+	}
+	return nil, c.results[fills.Amounts[0].Int64()]
+}
+
+func newSimulatedSettlement(amount int64) *PendingSettlement {
+	order := &CompactOrder{Price: big.NewInt(2000), Amount: big.NewInt(amount)}
+	return &PendingSettlement{
+		ID:             uuid.New(),
+		FillID:         uuid.New(),
+		Price:          big.NewInt(2000),
+		Amount:         big.NewInt(amount),
+		TakerOrder:     order,
+		MakerOrder:     order,
+		TakerSignature: make([]byte, 65),
+		MakerSignature: make([]byte, 65),
+	}
+}
+
+func TestExcludeFailingFills(t *testing.T) {
+	parsedABI, err := abi.JSON(strings.NewReader(batchSettleABI))
+	require.NoError(t, err)
+
+	sm := &SettlementManager{
+		settlementABI: parsedABI,
+		simulator: &fakeCaller{settlementABI: parsedABI, results: map[int64]error{
+			2: errors.New("execution reverted: Order expired"),
+			3: errors.New("connection refused"),
+		}},
+	}
+
+	var rejected []uuid.UUID
+	var rejectErr error
+	sm.SetStatusHandler(func(fillIDs []uuid.UUID, status ordertypes.SettlementStatus, txHash string, err error) {
+		if status == ordertypes.SettlementStatusRejected {
+			rejected = append(rejected, fillIDs...)
+			rejectErr = err
+		}
+	})
+
+	batch := []*PendingSettlement{newSimulatedSettlement(1), newSimulatedSettlement(2), newSimulatedSettlement(3)}
+	valid := sm.excludeFailingFills(batch)
+
+	// 回滚的成交被剔除，节点错误无法判断结果，照常提交
+	require.Len(t, valid, 2)
+	assert.Equal(t, batch[0].ID, valid[0].ID)
+	assert.Equal(t, batch[2].ID, valid[1].ID)
+	assert.Equal(t, []uuid.UUID{batch[1].FillID}, rejected)
+	assert.Contains(t, rejectErr.Error(), "Order expired")
+	assert.Equal(t, 1, sm.GetSettlementStats()["simulation_rejected"])
+}
+
+func TestExcludeFailingFillsDisabled(t *testing.T) {
+	sm := &SettlementManager{}
+	batch := []*PendingSettlement{newSimulatedSettlement(1)}
+	assert.Equal(t, batch, sm.excludeFailingFills(batch))
+}
@@ -0,0 +1,94 @@
+// Package booksnapshot 订单簿历史快照
+// 按固定周期记录各交易对前 N 档订单簿，供研究回溯和成交争议核查时查询任意时刻的盘口
+package booksnapshot
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"orderbook-engine/internal/matching"
+	"orderbook-engine/internal/types"
+)
+
+// ErrNotFound 查询时刻之前没有快照
+var ErrNotFound = errors.New("order book snapshot not found")
+
+// Store 快照存储
+type Store interface {
+	SaveSnapshot(snapshot *types.OrderBookSnapshot) error
+	// SnapshotAt 返回交易对在 at 时刻（含）之前最近的一份快照
+	SnapshotAt(tradingPair string, at time.Time) (*types.OrderBookSnapshot, error)
+	// Prune 删除 before 之前的快照
+	Prune(before time.Time) error
+}
+
+// Config 快照配置
+type Config struct {
+	Interval  time.Duration // 快照周期
+	Depth     int           // 每侧记录的价格层级数
+	Retention time.Duration // 快照保留时长，0 表示不清理
+}
+
+// Recorder 订单簿快照记录器
+// 订单簿序号未变化的交易对不重复记录，按时刻查询时返回的上一份快照即为当时的盘口
+type Recorder struct {
+	mu      sync.Mutex
+	engine  *matching.MatchingEngine
+	store   Store
+	config  Config
+	lastSeq map[string]uint64 // 交易对最近一次记录的订单簿序号
+	logger  *logrus.Logger
+}
+
+// NewRecorder 创建订单簿快照记录器
+func NewRecorder(engine *matching.MatchingEngine, store Store, config Config, logger *logrus.Logger) *Recorder {
+	return &Recorder{
+		engine:  engine,
+		store:   store,
+		config:  config,
+		lastSeq: make(map[string]uint64),
+		logger:  logger,
+	}
+}
+
+// Start 启动周期快照
+func (r *Recorder) Start() {
+	go func() {
+		ticker := time.NewTicker(r.config.Interval)
+		defer ticker.Stop()
+
+		for now := range ticker.C {
+			r.Capture(now)
+		}
+	}()
+}
+
+// Capture 记录一轮快照并清理过期快照，返回新记录的快照数
+func (r *Recorder) Capture(now time.Time) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	saved := 0
+	for _, pair := range r.engine.TradingPairs() {
+		snapshot := r.engine.GetOrderBook(pair, r.config.Depth)
+		if last, ok := r.lastSeq[pair]; ok && last == snapshot.Sequence {
+			continue
+		}
+		if err := r.store.SaveSnapshot(snapshot); err != nil {
+			r.logger.WithError(err).WithField("trading_pair", pair).Error("Failed to save order book snapshot")
+			continue
+		}
+		r.lastSeq[pair] = snapshot.Sequence
+		saved++
+	}
+
+	if r.config.Retention > 0 {
+		if err := r.store.Prune(now.Add(-r.config.Retention)); err != nil {
+			r.logger.WithError(err).Error("Failed to prune order book snapshots")
+		}
+	}
+	return saved
+}
@@ -0,0 +1,63 @@
+package booksnapshot
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"orderbook-engine/internal/matching"
+	"orderbook-engine/internal/types"
+)
+
+func addOrder(t *testing.T, engine *matching.MatchingEngine, side types.OrderSide, price int64) {
+	_, err := engine.AddOrder(&types.Order{
+		ID:          uuid.New(),
+		UserAddress: "0x1234567890123456789012345678901234567890",
+		TradingPair: "WETH-USDC",
+		Side:        side,
+		Type:        types.OrderTypeLimit,
+		Price:       decimal.NewFromInt(price),
+		Amount:      decimal.NewFromInt(1),
+		CreatedAt:   time.Now(),
+	})
+	require.NoError(t, err)
+}
+
+func TestRecorderSnapshotAt(t *testing.T) {
+	engine := matching.NewMatchingEngine(logrus.New())
+	store := NewMemoryStore()
+	recorder := NewRecorder(engine, store, Config{Depth: 5, Retention: time.Hour}, logrus.New())
+
+	addOrder(t, engine, types.OrderSideBuy, 2000)
+	assert.Equal(t, 1, recorder.Capture(time.Now()))
+	first := time.Now()
+
+	// 订单簿没有变化时不重复记录
+	assert.Equal(t, 0, recorder.Capture(time.Now()))
+
+	time.Sleep(time.Millisecond)
+	addOrder(t, engine, types.OrderSideSell, 2010)
+	assert.Equal(t, 1, recorder.Capture(time.Now()))
+
+	_, err := store.SnapshotAt("WETH-USDC", first.Add(-time.Hour))
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	snapshot, err := store.SnapshotAt("WETH-USDC", first)
+	require.NoError(t, err)
+	assert.Len(t, snapshot.Bids, 1)
+	assert.Empty(t, snapshot.Asks)
+
+	snapshot, err = store.SnapshotAt("WETH-USDC", time.Now())
+	require.NoError(t, err)
+	assert.Len(t, snapshot.Asks, 1)
+
+	// 超过保留时长的快照被清理
+	recorder.Capture(time.Now().Add(2 * time.Hour))
+	_, err = store.SnapshotAt("WETH-USDC", time.Now())
+	assert.ErrorIs(t, err, ErrNotFound)
+}
@@ -0,0 +1,67 @@
+package booksnapshot
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"orderbook-engine/internal/types"
+)
+
+// MemoryStore 内存快照存储，每个交易对按时间顺序保存
+type MemoryStore struct {
+	mu        sync.RWMutex
+	snapshots map[string][]*types.OrderBookSnapshot
+}
+
+// NewMemoryStore 创建内存快照存储
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		snapshots: make(map[string][]*types.OrderBookSnapshot),
+	}
+}
+
+// SaveSnapshot 保存快照
+func (s *MemoryStore) SaveSnapshot(snapshot *types.OrderBookSnapshot) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.snapshots[snapshot.TradingPair] = append(s.snapshots[snapshot.TradingPair], snapshot)
+	return nil
+}
+
+// SnapshotAt 返回 at 时刻（含）之前最近的一份快照
+func (s *MemoryStore) SnapshotAt(tradingPair string, at time.Time) (*types.OrderBookSnapshot, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	snapshots := s.snapshots[tradingPair]
+	i := sort.Search(len(snapshots), func(i int) bool {
+		return snapshots[i].Timestamp.After(at)
+	})
+	if i == 0 {
+		return nil, ErrNotFound
+	}
+	return snapshots[i-1], nil
+}
+
+// Prune 删除 before 之前的快照
+func (s *MemoryStore) Prune(before time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for pair, snapshots := range s.snapshots {
+		i := sort.Search(len(snapshots), func(i int) bool {
+			return !snapshots[i].Timestamp.Before(before)
+		})
+		if i == 0 {
+			continue
+		}
+		if i == len(snapshots) {
+			delete(s.snapshots, pair)
+			continue
+		}
+		s.snapshots[pair] = append([]*types.OrderBookSnapshot(nil), snapshots[i:]...)
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package booksnapshot
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	_ "github.com/lib/pq"
+
+	"orderbook-engine/internal/types"
+)
+
+// snapshotSchema 快照表，价格层级以 JSONB 保存
+var snapshotSchema = []string{
+	`CREATE TABLE IF NOT EXISTS orderbook_snapshots (
+		trading_pair TEXT NOT NULL,
+		taken_at TIMESTAMPTZ NOT NULL,
+		sequence BIGINT NOT NULL,
+		bids JSONB NOT NULL,
+		asks JSONB NOT NULL,
+		PRIMARY KEY (trading_pair, taken_at)
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_orderbook_snapshots_taken ON orderbook_snapshots (taken_at)`,
+}
+
+// PostgresStore 基于 PostgreSQL 的快照存储
+type PostgresStore struct {
+	db *sql.DB
+}
+
+// NewPostgresStore 创建 PostgreSQL 快照存储并确保表结构存在
+func NewPostgresStore(dsn string) (*PostgresStore, error) {
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open snapshot database: %w", err)
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to connect to snapshot database: %w", err)
+	}
+
+	for _, stmt := range snapshotSchema {
+		if _, err := db.Exec(stmt); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("failed to create snapshot schema: %w", err)
+		}
+	}
+	return &PostgresStore{db: db}, nil
+}
+
+// Close 关闭数据库连接
+func (s *PostgresStore) Close() error {
+	return s.db.Close()
+}
+
+// SaveSnapshot 保存快照
+func (s *PostgresStore) SaveSnapshot(snapshot *types.OrderBookSnapshot) error {
+	bids, err := json.Marshal(snapshot.Bids)
+	if err != nil {
+		return fmt.Errorf("failed to encode bids: %w", err)
+	}
+	asks, err := json.Marshal(snapshot.Asks)
+	if err != nil {
+		return fmt.Errorf("failed to encode asks: %w", err)
+	}
+
+	_, err = s.db.Exec(
+		`INSERT INTO orderbook_snapshots (trading_pair, taken_at, sequence, bids, asks) VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (trading_pair, taken_at) DO NOTHING`,
+		snapshot.TradingPair, snapshot.Timestamp, snapshot.Sequence, bids, asks)
+	if err != nil {
+		return fmt.Errorf("failed to save order book snapshot: %w", err)
+	}
+	return nil
+}
+
+// SnapshotAt 返回 at 时刻（含）之前最近的一份快照
+func (s *PostgresStore) SnapshotAt(tradingPair string, at time.Time) (*types.OrderBookSnapshot, error) {
+	snapshot := &types.OrderBookSnapshot{TradingPair: tradingPair}
+	var bids, asks []byte
+	err := s.db.QueryRow(
+		`SELECT taken_at, sequence, bids, asks FROM orderbook_snapshots
+		WHERE trading_pair = $1 AND taken_at <= $2 ORDER BY taken_at DESC LIMIT 1`,
+		tradingPair, at).Scan(&snapshot.Timestamp, &snapshot.Sequence, &bids, &asks)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query order book snapshot: %w", err)
+	}
+
+	if err := json.Unmarshal(bids, &snapshot.Bids); err != nil {
+		return nil, fmt.Errorf("failed to decode bids: %w", err)
+	}
+	if err := json.Unmarshal(asks, &snapshot.Asks); err != nil {
+		return nil, fmt.Errorf("failed to decode asks: %w", err)
+	}
+	return snapshot, nil
+}
+
+// Prune 删除 before 之前的快照
+func (s *PostgresStore) Prune(before time.Time) error {
+	if _, err := s.db.Exec(`DELETE FROM orderbook_snapshots WHERE taken_at < $1`, before); err != nil {
+		return fmt.Errorf("failed to prune order book snapshots: %w", err)
+	}
+	return nil
+}
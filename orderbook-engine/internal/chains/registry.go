@@ -0,0 +1,127 @@
+// Package chains 多链配置与运行时实例
+// 每条链拥有独立的RPC客户端、EIP-712签名域和结算管理器，交易对归属于唯一的链
+package chains
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"orderbook-engine/internal/blockchain"
+	"orderbook-engine/pkg/crypto"
+)
+
+// Config 单条链配置（对应配置文件 chains: 数组中的一项）
+type Config struct {
+	ChainID           uint64   `mapstructure:"chain_id" json:"chain_id"`
+	Name              string   `mapstructure:"name" json:"name"`
+	RPCURL            string   `mapstructure:"rpc_url" json:"-"`
+	PrivateKey        string   `mapstructure:"private_key" json:"-"`
+	ContractAddress   string   `mapstructure:"contract_address" json:"contract_address"`
+	SettlementAddress string   `mapstructure:"settlement_address" json:"settlement_address"`
+	TradingPairs      []string `mapstructure:"trading_pairs" json:"trading_pairs"`
+}
+
+// Chain 链运行时实例
+type Chain struct {
+	Config
+	Signer     *crypto.OrderSigner           // 该链的EIP-712签名域
+	Client     *blockchain.Client            // 未配置RPC时为空
+	Settlement *blockchain.SettlementManager // 未启用结算时为空
+}
+
+// Registry 链注册表
+type Registry struct {
+	chains       map[uint64]*Chain
+	pairChains   map[string]uint64 // 交易对(大写) -> 链ID
+	defaultChain uint64            // 第一条链，承接未显式分配的交易对
+}
+
+// NewRegistry 创建链注册表，第一条链为默认链
+func NewRegistry(configs []Config) (*Registry, error) {
+	if len(configs) == 0 {
+		return nil, fmt.Errorf("at least one chain must be configured")
+	}
+
+	r := &Registry{
+		chains:       make(map[uint64]*Chain, len(configs)),
+		pairChains:   make(map[string]uint64),
+		defaultChain: configs[0].ChainID,
+	}
+
+	for _, cfg := range configs {
+		if cfg.ChainID == 0 {
+			return nil, fmt.Errorf("chain %q has no chain_id", cfg.Name)
+		}
+		if _, exists := r.chains[cfg.ChainID]; exists {
+			return nil, fmt.Errorf("duplicate chain_id %d", cfg.ChainID)
+		}
+		if cfg.ContractAddress != "" && !common.IsHexAddress(cfg.ContractAddress) {
+			return nil, fmt.Errorf("chain %d: invalid contract_address %q", cfg.ChainID, cfg.ContractAddress)
+		}
+		if cfg.SettlementAddress != "" && !common.IsHexAddress(cfg.SettlementAddress) {
+			return nil, fmt.Errorf("chain %d: invalid settlement_address %q", cfg.ChainID, cfg.SettlementAddress)
+		}
+
+		for _, pair := range cfg.TradingPairs {
+			pair = strings.ToUpper(pair)
+			if owner, exists := r.pairChains[pair]; exists {
+				return nil, fmt.Errorf("trading pair %s assigned to both chain %d and %d", pair, owner, cfg.ChainID)
+			}
+			r.pairChains[pair] = cfg.ChainID
+		}
+
+		r.chains[cfg.ChainID] = &Chain{
+			Config: cfg,
+			Signer: crypto.NewOrderSigner(new(big.Int).SetUint64(cfg.ChainID), common.HexToAddress(cfg.ContractAddress)),
+		}
+	}
+
+	return r, nil
+}
+
+// Get 获取链
+func (r *Registry) Get(chainID uint64) (*Chain, bool) {
+	chain, exists := r.chains[chainID]
+	return chain, exists
+}
+
+// Default 获取默认链
+func (r *Registry) Default() *Chain {
+	return r.chains[r.defaultChain]
+}
+
+// ChainForPair 获取交易对所属的链，未显式分配的交易对归属默认链
+func (r *Registry) ChainForPair(tradingPair string) *Chain {
+	if chainID, exists := r.pairChains[strings.ToUpper(tradingPair)]; exists {
+		return r.chains[chainID]
+	}
+	return r.Default()
+}
+
+// Resolve 解析请求中的链ID，0表示默认链
+func (r *Registry) Resolve(chainID uint64) (*Chain, error) {
+	if chainID == 0 {
+		return r.Default(), nil
+	}
+	chain, exists := r.chains[chainID]
+	if !exists {
+		return nil, fmt.Errorf("unsupported chain_id %d", chainID)
+	}
+	return chain, nil
+}
+
+// Chains 获取全部链（按链ID排序）
+func (r *Registry) Chains() []*Chain {
+	result := make([]*Chain, 0, len(r.chains))
+	for _, chain := range r.chains {
+		result = append(result, chain)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ChainID < result[j].ChainID
+	})
+	return result
+}
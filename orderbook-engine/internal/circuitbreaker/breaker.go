@@ -0,0 +1,283 @@
+// Package circuitbreaker 价格熔断
+// 监控各交易对成交价，在时间窗口内波动超过阈值时暂停吃单
+package circuitbreaker
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+
+	"orderbook-engine/internal/matching"
+	"orderbook-engine/internal/types"
+)
+
+// Config 熔断配置
+type Config struct {
+	MaxMovePercent decimal.Decimal `json:"max_move_percent"` // 窗口内最大波动(百分比)
+	Window         time.Duration   `json:"window"`           // 观察窗口
+	HaltDuration   time.Duration   `json:"halt_duration"`    // 自动恢复时长，0表示需人工恢复
+}
+
+// PairState 交易对熔断状态
+type PairState struct {
+	TradingPair string     `json:"trading_pair"`
+	Halted      bool       `json:"halted"`
+	Reason      string     `json:"reason,omitempty"`
+	HaltedAt    *time.Time `json:"halted_at,omitempty"`
+	ResumeAt    *time.Time `json:"resume_at,omitempty"`
+	TripCount   int        `json:"trip_count"`
+}
+
+// pricePoint 窗口内成交价
+type pricePoint struct {
+	price decimal.Decimal
+	at    time.Time
+}
+
+// pairMonitor 单个交易对的监控数据
+type pairMonitor struct {
+	state  PairState
+	prices []pricePoint
+}
+
+// CircuitBreaker 熔断器
+type CircuitBreaker struct {
+	mu       sync.Mutex
+	config   *Config
+	pairs    map[string]*pairMonitor
+	onChange func(update *types.PairStatusUpdate)
+	logger   *logrus.Logger
+}
+
+// NewCircuitBreaker 创建熔断器
+func NewCircuitBreaker(config *Config, logger *logrus.Logger) *CircuitBreaker {
+	return &CircuitBreaker{
+		config: config,
+		pairs:  make(map[string]*pairMonitor),
+		logger: logger,
+	}
+}
+
+// SetStatusHandler 设置交易对状态变化回调（用于WebSocket通知）
+func (cb *CircuitBreaker) SetStatusHandler(handler func(update *types.PairStatusUpdate)) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	cb.onChange = handler
+}
+
+// AllowTaker 实现 matching.TradingGate
+func (cb *CircuitBreaker) AllowTaker(tradingPair string) error {
+	cb.mu.Lock()
+	monitor := cb.pairs[tradingPair]
+	if monitor == nil || !monitor.state.Halted {
+		cb.mu.Unlock()
+		return nil
+	}
+
+	// 到期自动恢复
+	if monitor.state.ResumeAt != nil && time.Now().After(*monitor.state.ResumeAt) {
+		update := cb.resumeLocked(monitor, "halt period elapsed")
+		cb.mu.Unlock()
+		cb.notify(update)
+		return nil
+	}
+
+	reason := monitor.state.Reason
+	cb.mu.Unlock()
+	return fmt.Errorf("%w: %s", matching.ErrPairHalted, reason)
+}
+
+// RecordFill 记录成交价并检查是否需要熔断
+func (cb *CircuitBreaker) RecordFill(fill *types.Fill) {
+	cb.mu.Lock()
+
+	monitor := cb.getMonitorLocked(fill.TradingPair)
+	now := fill.CreatedAt
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	// 移除窗口外的价格
+	cutoff := now.Add(-cb.config.Window)
+	i := 0
+	for i < len(monitor.prices) && monitor.prices[i].at.Before(cutoff) {
+		i++
+	}
+	monitor.prices = append(monitor.prices[i:], pricePoint{price: fill.Price, at: now})
+
+	if monitor.state.Halted {
+		cb.mu.Unlock()
+		return
+	}
+
+	low, high := monitor.prices[0].price, monitor.prices[0].price
+	for _, p := range monitor.prices[1:] {
+		low = decimal.Min(low, p.price)
+		high = decimal.Max(high, p.price)
+	}
+	if !low.IsPositive() {
+		cb.mu.Unlock()
+		return
+	}
+
+	move := high.Sub(low).Div(low).Mul(decimal.NewFromInt(100))
+	if move.LessThanOrEqual(cb.config.MaxMovePercent) {
+		cb.mu.Unlock()
+		return
+	}
+
+	reason := fmt.Sprintf("price moved %s%% within %s (low %s, high %s)",
+		move.StringFixed(2), cb.config.Window, low.String(), high.String())
+	update := cb.haltLocked(monitor, reason, cb.config.HaltDuration)
+	cb.mu.Unlock()
+
+	cb.logger.WithFields(logrus.Fields{
+		"trading_pair": fill.TradingPair,
+		"move_percent": move.StringFixed(2),
+		"low":          low.String(),
+		"high":         high.String(),
+	}).Warn("⛔ Circuit breaker tripped")
+	cb.notify(update)
+}
+
+// Halt 人工暂停交易对（duration为0表示需人工恢复）
+func (cb *CircuitBreaker) Halt(tradingPair, reason string, duration time.Duration) {
+	cb.mu.Lock()
+	update := cb.haltLocked(cb.getMonitorLocked(tradingPair), reason, duration)
+	cb.mu.Unlock()
+
+	cb.logger.WithFields(logrus.Fields{
+		"trading_pair": tradingPair,
+		"reason":       reason,
+		"duration":     duration.String(),
+	}).Warn("Trading pair halted manually")
+	cb.notify(update)
+}
+
+// Resume 恢复交易对
+func (cb *CircuitBreaker) Resume(tradingPair, reason string) bool {
+	cb.mu.Lock()
+	monitor := cb.pairs[tradingPair]
+	if monitor == nil || !monitor.state.Halted {
+		cb.mu.Unlock()
+		return false
+	}
+	update := cb.resumeLocked(monitor, reason)
+	cb.mu.Unlock()
+
+	cb.notify(update)
+	return true
+}
+
+// GetStates 获取所有交易对熔断状态
+func (cb *CircuitBreaker) GetStates() []PairState {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	states := make([]PairState, 0, len(cb.pairs))
+	for _, monitor := range cb.pairs {
+		states = append(states, monitor.state)
+	}
+	return states
+}
+
+// StartResumeTicker 启动自动恢复定时器（无订单流时也能按时恢复）
+func (cb *CircuitBreaker) StartResumeTicker(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		for range ticker.C {
+			cb.resumeExpired()
+		}
+	}()
+}
+
+// resumeExpired 恢复到期的交易对
+func (cb *CircuitBreaker) resumeExpired() {
+	var updates []*types.PairStatusUpdate
+
+	cb.mu.Lock()
+	now := time.Now()
+	for _, monitor := range cb.pairs {
+		if monitor.state.Halted && monitor.state.ResumeAt != nil && now.After(*monitor.state.ResumeAt) {
+			updates = append(updates, cb.resumeLocked(monitor, "halt period elapsed"))
+		}
+	}
+	cb.mu.Unlock()
+
+	for _, update := range updates {
+		cb.notify(update)
+	}
+}
+
+func (cb *CircuitBreaker) getMonitorLocked(tradingPair string) *pairMonitor {
+	monitor, exists := cb.pairs[tradingPair]
+	if !exists {
+		monitor = &pairMonitor{state: PairState{TradingPair: tradingPair}}
+		cb.pairs[tradingPair] = monitor
+	}
+	return monitor
+}
+
+func (cb *CircuitBreaker) haltLocked(monitor *pairMonitor, reason string, duration time.Duration) *types.PairStatusUpdate {
+	now := time.Now()
+	monitor.state.Halted = true
+	monitor.state.Reason = reason
+	monitor.state.HaltedAt = &now
+	monitor.state.ResumeAt = nil
+	monitor.state.TripCount++
+	if duration > 0 {
+		resumeAt := now.Add(duration)
+		monitor.state.ResumeAt = &resumeAt
+	}
+
+	return &types.PairStatusUpdate{
+		TradingPair: monitor.state.TradingPair,
+		Status:      types.PairStatusHalted,
+		Reason:      reason,
+		ResumeAt:    monitor.state.ResumeAt,
+		Timestamp:   now,
+	}
+}
+
+func (cb *CircuitBreaker) resumeLocked(monitor *pairMonitor, reason string) *types.PairStatusUpdate {
+	monitor.state.Halted = false
+	monitor.state.Reason = ""
+	monitor.state.HaltedAt = nil
+	monitor.state.ResumeAt = nil
+	// 恢复后重新开始观察，避免立即再次触发
+	monitor.prices = monitor.prices[:0]
+
+	cb.logger.WithFields(logrus.Fields{
+		"trading_pair": monitor.state.TradingPair,
+		"reason":       reason,
+	}).Info("Trading pair resumed")
+
+	return &types.PairStatusUpdate{
+		TradingPair: monitor.state.TradingPair,
+		Status:      types.PairStatusTrading,
+		Reason:      reason,
+		Timestamp:   time.Now(),
+	}
+}
+
+func (cb *CircuitBreaker) notify(update *types.PairStatusUpdate) {
+	cb.mu.Lock()
+	handler := cb.onChange
+	cb.mu.Unlock()
+
+	if handler != nil && update != nil {
+		handler(update)
+	}
+}
+
+// DefaultConfig 默认熔断配置
+func DefaultConfig() *Config {
+	return &Config{
+		MaxMovePercent: decimal.NewFromInt(10), // 10%
+		Window:         5 * time.Minute,        // 5分钟窗口
+		HaltDuration:   5 * time.Minute,        // 暂停5分钟后自动恢复
+	}
+}
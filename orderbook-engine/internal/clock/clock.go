@@ -0,0 +1,35 @@
+// Package clock 交易所时间戳
+// 引擎时间戳为进程内严格递增的 Unix 纳秒：以进程启动时的墙上时间为起点加上单调时钟的流逝时间，
+// 不受系统时间回拨或 NTP 跳变影响，同一进程内可直接用于事件排序；
+// 与墙上时间（CreatedAt、成交时间）的差值反映启动以来系统时钟的调整量
+package clock
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+var (
+	start = time.Now() // 携带单调时钟读数
+	last  atomic.Int64
+)
+
+// Now 返回引擎时间戳（Unix 纳秒），每次调用严格大于上一次的返回值
+func Now() int64 {
+	candidate := start.UnixNano() + int64(time.Since(start))
+	for {
+		previous := last.Load()
+		next := candidate
+		if next <= previous {
+			next = previous + 1
+		}
+		if last.CompareAndSwap(previous, next) {
+			return next
+		}
+	}
+}
+
+// Time 将引擎时间戳转换为时间（UTC）
+func Time(timestamp int64) time.Time {
+	return time.Unix(0, timestamp).UTC()
+}
@@ -0,0 +1,46 @@
+package clock
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNowStrictlyIncreasing(t *testing.T) {
+	previous := Now()
+	for i := 0; i < 1000; i++ {
+		next := Now()
+		assert.Greater(t, next, previous)
+		previous = next
+	}
+
+	// 起点为墙上时间，未调整时钟时与当前时间接近
+	assert.WithinDuration(t, time.Now(), Time(previous), time.Second)
+}
+
+func TestNowUniqueAcrossGoroutines(t *testing.T) {
+	const workers, calls = 8, 500
+
+	var mu sync.Mutex
+	seen := make(map[int64]bool, workers*calls)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			local := make([]int64, calls)
+			for i := range local {
+				local[i] = Now()
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			for _, timestamp := range local {
+				seen[timestamp] = true
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Len(t, seen, workers*calls)
+}
@@ -0,0 +1,82 @@
+package compliance
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// APIScreener 外部地址筛查接口
+// 接口格式兼容 Chainalysis Sanctions API：GET {baseURL}/{address}，请求头 X-API-Key，
+// 响应 {"identifications":[{"category":"sanctions","name":"...","description":"..."}]}，列表非空表示命中
+type APIScreener struct {
+	baseURL string
+	apiKey  string
+	client  *http.Client
+}
+
+// identification 筛查接口返回的命中记录
+type identification struct {
+	Category    string `json:"category"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// NewAPIScreener 创建外部地址筛查
+func NewAPIScreener(baseURL, apiKey string, timeout time.Duration) *APIScreener {
+	return &APIScreener{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		apiKey:  apiKey,
+		client:  &http.Client{Timeout: timeout},
+	}
+}
+
+// Name 筛查来源名称
+func (s *APIScreener) Name() string {
+	return "api"
+}
+
+// Screen 请求筛查接口
+func (s *APIScreener) Screen(ctx context.Context, address string) (*Result, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.baseURL+"/"+url.PathEscape(address), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if s.apiKey != "" {
+		req.Header.Set("X-API-Key", s.apiKey)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("screening request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("screening request returned status %d", resp.StatusCode)
+	}
+
+	var body struct {
+		Identifications []identification `json:"identifications"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("failed to decode screening response: %w", err)
+	}
+
+	result := &Result{Address: address, Source: s.Name(), CheckedAt: time.Now()}
+	if len(body.Identifications) > 0 {
+		first := body.Identifications[0]
+		result.Flagged = true
+		result.Category = first.Category
+		result.Reason = first.Name
+		if result.Reason == "" {
+			result.Reason = first.Description
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,167 @@
+// Package compliance 地址合规筛查（制裁名单等）
+// 下单与提现前按用户地址调用筛查服务（Chainalysis、TRM 风格的地址筛查接口或本地拒绝名单文件），
+// 命中任一来源即拒绝；筛查结果按地址缓存，避免每笔订单都请求外部服务
+package compliance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	// ErrSanctioned 地址未通过合规筛查
+	ErrSanctioned = errors.New("address failed compliance screening")
+	// ErrUnavailable 筛查服务不可用
+	ErrUnavailable = errors.New("compliance screening unavailable")
+)
+
+// maxCacheEntries 缓存条目达到该数量时清理过期结果
+const maxCacheEntries = 10000
+
+// Result 地址筛查结果
+type Result struct {
+	Address   string    `json:"address"`
+	Flagged   bool      `json:"flagged"`
+	Category  string    `json:"category,omitempty"` // 命中类别，如 sanctions
+	Reason    string    `json:"reason,omitempty"`
+	Source    string    `json:"source"` // 筛查来源
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// Screener 地址筛查来源
+type Screener interface {
+	Name() string
+	Screen(ctx context.Context, address string) (*Result, error)
+}
+
+// Config 合规筛查配置
+type Config struct {
+	CacheTTL time.Duration // 筛查结果缓存时长
+	Timeout  time.Duration // 单个地址筛查超时
+	FailOpen bool          // 筛查服务不可用时放行（仅记录告警）
+}
+
+// cachedResult 缓存的筛查结果
+type cachedResult struct {
+	result    *Result
+	expiresAt time.Time
+}
+
+// Checker 合规筛查，依次调用各筛查来源，命中任一来源即拒绝
+type Checker struct {
+	mu        sync.Mutex
+	config    Config
+	screeners []Screener
+	cache     map[string]*cachedResult // 小写地址 -> 结果
+	logger    *logrus.Logger
+}
+
+// NewChecker 创建合规筛查
+func NewChecker(config Config, logger *logrus.Logger, screeners ...Screener) *Checker {
+	return &Checker{
+		config:    config,
+		screeners: screeners,
+		cache:     make(map[string]*cachedResult),
+		logger:    logger,
+	}
+}
+
+// Check 筛查地址，未通过时返回 ErrSanctioned；筛查服务不可用时按 FailOpen 放行或返回 ErrUnavailable
+func (c *Checker) Check(ctx context.Context, address string) (*Result, error) {
+	key := strings.ToLower(address)
+	now := time.Now()
+
+	c.mu.Lock()
+	cached := c.cache[key]
+	c.mu.Unlock()
+	if cached != nil && now.Before(cached.expiresAt) {
+		return cached.result, rejection(cached.result)
+	}
+
+	if c.config.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.config.Timeout)
+		defer cancel()
+	}
+
+	names := make([]string, 0, len(c.screeners))
+	result := &Result{Address: address, CheckedAt: now}
+	for _, screener := range c.screeners {
+		names = append(names, screener.Name())
+		screened, err := screener.Screen(ctx, address)
+		if err != nil {
+			// 不可用的结果不缓存，下次请求重新筛查
+			if c.config.FailOpen {
+				c.logger.WithError(err).WithFields(logrus.Fields{
+					"user_address": address,
+					"source":       screener.Name(),
+				}).Warn("Compliance screening failed, allowing address")
+				return nil, nil
+			}
+			return nil, fmt.Errorf("%w: %s: %v", ErrUnavailable, screener.Name(), err)
+		}
+		if screened.Flagged {
+			result = screened
+			break
+		}
+	}
+	if !result.Flagged {
+		result.Source = strings.Join(names, ",")
+	}
+
+	c.store(key, result, now)
+	if result.Flagged {
+		c.logger.WithFields(logrus.Fields{
+			"user_address": address,
+			"source":       result.Source,
+			"category":     result.Category,
+			"reason":       result.Reason,
+		}).Warn("Address flagged by compliance screening")
+	}
+	return result, rejection(result)
+}
+
+// AllowWithdrawal 提现前筛查，用作余额管理器的提现闸门
+func (c *Checker) AllowWithdrawal(userAddress string) error {
+	_, err := c.Check(context.Background(), userAddress)
+	return err
+}
+
+// Invalidate 清除地址的缓存结果（名单更新后重新筛查）
+func (c *Checker) Invalidate(address string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.cache, strings.ToLower(address))
+}
+
+// store 缓存筛查结果，条目过多时先清理过期结果
+func (c *Checker) store(key string, result *Result, now time.Time) {
+	if c.config.CacheTTL <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.cache) >= maxCacheEntries {
+		for cachedKey, cached := range c.cache {
+			if !now.Before(cached.expiresAt) {
+				delete(c.cache, cachedKey)
+			}
+		}
+	}
+	c.cache[key] = &cachedResult{result: result, expiresAt: now.Add(c.config.CacheTTL)}
+}
+
+// rejection 命中筛查时返回带来源与原因的错误
+func rejection(result *Result) error {
+	if !result.Flagged {
+		return nil
+	}
+	return fmt.Errorf("%w: %s (%s)", ErrSanctioned, result.Reason, result.Source)
+}
@@ -0,0 +1,103 @@
+package compliance
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	sanctioned = "0x8589427373D6D84E98730D7795D8f6f8731FDA16"
+	clean      = "0x1234567890123456789012345678901234567890"
+)
+
+type countingScreener struct {
+	calls int
+	err   error
+}
+
+func (s *countingScreener) Name() string { return "counting" }
+
+func (s *countingScreener) Screen(ctx context.Context, address string) (*Result, error) {
+	s.calls++
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &Result{Address: address, Source: s.Name(), CheckedAt: time.Now()}, nil
+}
+
+func TestDenylistRejectsAndCachesResults(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "denylist.txt")
+	require.NoError(t, os.WriteFile(path, []byte("# OFAC\n"+sanctioned+" OFAC SDN\n\n"), 0o644))
+	denylist, err := NewDenylist(path)
+	require.NoError(t, err)
+	assert.Equal(t, 1, denylist.Len())
+
+	counter := &countingScreener{}
+	checker := NewChecker(Config{CacheTTL: time.Hour}, logrus.New(), denylist, counter)
+
+	result, err := checker.Check(context.Background(), "0x8589427373d6d84e98730d7795d8f6f8731fda16")
+	assert.ErrorIs(t, err, ErrSanctioned)
+	assert.Equal(t, "OFAC SDN", result.Reason)
+	assert.Equal(t, "denylist", result.Source)
+	assert.Equal(t, 0, counter.calls)
+
+	_, err = checker.Check(context.Background(), clean)
+	require.NoError(t, err)
+	_, err = checker.Check(context.Background(), clean)
+	require.NoError(t, err)
+	assert.Equal(t, 1, counter.calls)
+
+	checker.Invalidate(clean)
+	_, err = checker.Check(context.Background(), clean)
+	require.NoError(t, err)
+	assert.Equal(t, 2, counter.calls)
+
+	require.NoError(t, os.WriteFile(path, []byte("not-an-address\n"), 0o644))
+	assert.Error(t, denylist.Reload())
+}
+
+func TestScreeningUnavailable(t *testing.T) {
+	failing := &countingScreener{err: errors.New("connection refused")}
+
+	closed := NewChecker(Config{CacheTTL: time.Hour}, logrus.New(), failing)
+	_, err := closed.Check(context.Background(), clean)
+	assert.ErrorIs(t, err, ErrUnavailable)
+	assert.ErrorIs(t, closed.AllowWithdrawal(clean), ErrUnavailable)
+	assert.Equal(t, 2, failing.calls)
+
+	open := NewChecker(Config{CacheTTL: time.Hour, FailOpen: true}, logrus.New(), failing)
+	assert.NoError(t, open.AllowWithdrawal(clean))
+}
+
+func TestAPIScreener(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "secret", r.Header.Get("X-API-Key"))
+		if r.URL.Path == "/"+sanctioned {
+			w.Write([]byte(`{"identifications":[{"category":"sanctions","name":"SANCTIONS: OFAC SDN Tornado Cash"}]}`))
+			return
+		}
+		w.Write([]byte(`{"identifications":[]}`))
+	}))
+	defer server.Close()
+
+	checker := NewChecker(Config{Timeout: time.Second}, logrus.New(), NewAPIScreener(server.URL+"/", "secret", time.Second))
+	result, err := checker.Check(context.Background(), sanctioned)
+	assert.ErrorIs(t, err, ErrSanctioned)
+	assert.Equal(t, "sanctions", result.Category)
+	assert.Contains(t, err.Error(), "Tornado Cash")
+
+	result, err = checker.Check(context.Background(), clean)
+	require.NoError(t, err)
+	assert.False(t, result.Flagged)
+	assert.Equal(t, "api", result.Source)
+}
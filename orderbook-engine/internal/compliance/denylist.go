@@ -0,0 +1,94 @@
+package compliance
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Denylist 本地拒绝名单文件
+// 每行一个地址，地址后可跟原因，# 开头的行为注释：
+//
+//	0x8589427373D6D84E98730D7795D8f6f8731FDA16 OFAC SDN
+type Denylist struct {
+	mu        sync.RWMutex
+	path      string
+	addresses map[string]string // 小写地址 -> 原因
+}
+
+// NewDenylist 加载本地拒绝名单
+func NewDenylist(path string) (*Denylist, error) {
+	d := &Denylist{path: path}
+	if err := d.Reload(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// Reload 重新读取名单文件
+func (d *Denylist) Reload() error {
+	file, err := os.Open(d.path)
+	if err != nil {
+		return fmt.Errorf("failed to open denylist: %w", err)
+	}
+	defer file.Close()
+
+	addresses := make(map[string]string)
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.Fields(text)
+		if !common.IsHexAddress(fields[0]) {
+			return fmt.Errorf("denylist line %d: invalid address %q", line, fields[0])
+		}
+		reason := strings.TrimSpace(strings.TrimPrefix(text, fields[0]))
+		if reason == "" {
+			reason = "listed in local denylist"
+		}
+		addresses[strings.ToLower(fields[0])] = reason
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read denylist: %w", err)
+	}
+
+	d.mu.Lock()
+	d.addresses = addresses
+	d.mu.Unlock()
+	return nil
+}
+
+// Name 筛查来源名称
+func (d *Denylist) Name() string {
+	return "denylist"
+}
+
+// Screen 检查地址是否在名单中
+func (d *Denylist) Screen(ctx context.Context, address string) (*Result, error) {
+	d.mu.RLock()
+	reason, listed := d.addresses[strings.ToLower(address)]
+	d.mu.RUnlock()
+
+	result := &Result{Address: address, Source: d.Name(), CheckedAt: time.Now()}
+	if listed {
+		result.Flagged = true
+		result.Category = "denylist"
+		result.Reason = reason
+	}
+	return result, nil
+}
+
+// Len 名单中的地址数量
+func (d *Denylist) Len() int {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return len(d.addresses)
+}
@@ -0,0 +1,202 @@
+// Package drain 引擎排空停机
+// 部署或下线实例时按顺序执行排空步骤：停止接单、等待在途撮合与事件消费、提交结算批次、保存订单簿快照，完成后才退出进程
+package drain
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"orderbook-engine/internal/types"
+)
+
+// 排空状态
+const (
+	StateRunning  = "running"
+	StateDraining = "draining"
+	StateDrained  = "drained"
+)
+
+// Step 排空步骤
+type Step struct {
+	Name string
+	Run  func(ctx context.Context) error
+}
+
+// StepStatus 步骤执行结果
+type StepStatus struct {
+	Name     string `json:"name"`
+	Done     bool   `json:"done"`
+	Error    string `json:"error,omitempty"`
+	Duration string `json:"duration,omitempty"`
+}
+
+// Status 排空进度
+type Status struct {
+	State      string       `json:"state"`
+	Reason     string       `json:"reason,omitempty"` // 触发方式：signal、admin
+	StartedAt  *time.Time   `json:"started_at,omitempty"`
+	FinishedAt *time.Time   `json:"finished_at,omitempty"`
+	Steps      []StepStatus `json:"steps"`
+}
+
+// Drainer 排空协调器，步骤按注册顺序执行，单个步骤失败或超时不影响后续步骤
+type Drainer struct {
+	mu      sync.RWMutex
+	steps   []Step
+	timeout time.Duration // 全部步骤的总时限
+	status  Status
+	done    chan struct{}
+	logger  *logrus.Logger
+}
+
+// NewDrainer 创建排空协调器
+func NewDrainer(timeout time.Duration, logger *logrus.Logger) *Drainer {
+	return &Drainer{
+		timeout: timeout,
+		status:  Status{State: StateRunning, Steps: []StepStatus{}},
+		done:    make(chan struct{}),
+		logger:  logger,
+	}
+}
+
+// AddStep 注册排空步骤，需在开始排空前注册
+func (d *Drainer) AddStep(name string, run func(ctx context.Context) error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.steps = append(d.steps, Step{Name: name, Run: run})
+}
+
+// Start 开始排空，已开始时返回 false
+func (d *Drainer) Start(reason string) bool {
+	d.mu.Lock()
+	if d.status.State != StateRunning {
+		d.mu.Unlock()
+		return false
+	}
+	now := time.Now()
+	d.status.State = StateDraining
+	d.status.Reason = reason
+	d.status.StartedAt = &now
+	steps := d.steps
+	d.mu.Unlock()
+
+	go d.run(steps)
+	return true
+}
+
+// Drain 开始排空（如尚未开始）并等待完成
+func (d *Drainer) Drain(reason string) Status {
+	d.Start(reason)
+	<-d.done
+	return d.Status()
+}
+
+// Done 排空完成后关闭的通道
+func (d *Drainer) Done() <-chan struct{} {
+	return d.done
+}
+
+// Status 获取排空进度
+func (d *Drainer) Status() Status {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	status := d.status
+	status.Steps = append([]StepStatus(nil), d.status.Steps...)
+	return status
+}
+
+// run 依次执行排空步骤
+func (d *Drainer) run(steps []Step) {
+	defer close(d.done)
+
+	ctx, cancel := context.WithTimeout(context.Background(), d.timeout)
+	defer cancel()
+
+	d.logger.WithField("reason", d.Status().Reason).Warn("🚰 Draining engine")
+	for _, step := range steps {
+		started := time.Now()
+		err := step.Run(ctx)
+
+		result := StepStatus{Name: step.Name, Done: err == nil, Duration: time.Since(started).String()}
+		fields := logrus.Fields{"step": step.Name, "duration": result.Duration}
+		if err != nil {
+			result.Error = err.Error()
+			d.logger.WithError(err).WithFields(fields).Error("Drain step failed")
+		} else {
+			d.logger.WithFields(fields).Info("Drain step completed")
+		}
+
+		d.mu.Lock()
+		d.status.Steps = append(d.status.Steps, result)
+		d.mu.Unlock()
+	}
+
+	now := time.Now()
+	d.mu.Lock()
+	d.status.State = StateDrained
+	d.status.FinishedAt = &now
+	d.mu.Unlock()
+	d.logger.Warn("🚰 Engine drained")
+}
+
+// WaitFor 轮询等待条件成立，超时返回 ctx 的错误
+func WaitFor(ctx context.Context, interval time.Duration, condition func() bool) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for !condition() {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+	return nil
+}
+
+// Snapshot 排空时保存的挂单快照，可用于新实例恢复订单簿
+type Snapshot struct {
+	TakenAt time.Time      `json:"taken_at"`
+	Orders  []*types.Order `json:"orders"`
+}
+
+// WriteSnapshot 写入挂单快照（先写临时文件再重命名，避免留下不完整的快照）
+func WriteSnapshot(path string, orders []*types.Order) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create snapshot directory: %w", err)
+	}
+	data, err := json.Marshal(&Snapshot{TakenAt: time.Now(), Orders: orders})
+	if err != nil {
+		return fmt.Errorf("failed to encode snapshot: %w", err)
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to replace snapshot: %w", err)
+	}
+	return nil
+}
+
+// ReadSnapshot 读取挂单快照，文件不存在时返回的错误满足 errors.Is(err, os.ErrNotExist)
+func ReadSnapshot(path string) (*Snapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var snapshot Snapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("failed to decode snapshot: %w", err)
+	}
+	return &snapshot, nil
+}
@@ -0,0 +1,70 @@
+package drain
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"orderbook-engine/internal/matching"
+	"orderbook-engine/internal/types"
+)
+
+func newOrder(side types.OrderSide) *types.Order {
+	return &types.Order{
+		ID:          uuid.New(),
+		UserAddress: "0x1234567890123456789012345678901234567890",
+		TradingPair: "WETH-USDC",
+		Side:        side,
+		Type:        types.OrderTypeLimit,
+		Price:       decimal.NewFromInt(2000),
+		Amount:      decimal.NewFromInt(1),
+		CreatedAt:   time.Now(),
+	}
+}
+
+func TestDrainStopsIntakeAndSnapshotsBook(t *testing.T) {
+	engine := matching.NewMatchingEngine(logrus.New())
+	resting := newOrder(types.OrderSideBuy)
+	_, err := engine.AddOrder(resting)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+	drainer := NewDrainer(time.Second, logrus.New())
+	drainer.AddStep("stop_accepting", func(ctx context.Context) error {
+		engine.StopAccepting()
+		return nil
+	})
+	drainer.AddStep("failing", func(ctx context.Context) error {
+		return errors.New("boom")
+	})
+	drainer.AddStep("snapshot_orders", func(ctx context.Context) error {
+		return WriteSnapshot(path, engine.OpenOrders())
+	})
+
+	status := drainer.Drain("test")
+	assert.Equal(t, StateDrained, status.State)
+	require.Len(t, status.Steps, 3)
+	// 失败的步骤不阻止后续步骤执行
+	assert.Equal(t, "boom", status.Steps[1].Error)
+	assert.True(t, status.Steps[2].Done)
+	assert.False(t, drainer.Start("again"))
+
+	// 排空后拒绝新订单，已有挂单保留在快照中
+	rejected := newOrder(types.OrderSideSell)
+	_, err = engine.AddOrder(rejected)
+	assert.ErrorIs(t, err, matching.ErrDraining)
+	assert.Equal(t, types.StatusReasonDraining, rejected.StatusReason)
+
+	orders := engine.OpenOrders()
+	require.Len(t, orders, 1)
+	assert.Equal(t, resting.ID, orders[0].ID)
+	assert.FileExists(t, path)
+}
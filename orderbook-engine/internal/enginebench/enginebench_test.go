@@ -0,0 +1,134 @@
+package enginebench
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"orderbook-engine/internal/matching"
+	"orderbook-engine/internal/types"
+)
+
+func newBenchEngine() *matching.MatchingEngine {
+	logger := logrus.New()
+	logger.SetLevel(logrus.WarnLevel)
+	return matching.NewMatchingEngine(logger)
+}
+
+func smallConfig() Config {
+	config := DefaultConfig()
+	config.Levels = 20
+	config.TopOrders = 10
+	config.Operations = 5000
+	return config
+}
+
+func TestBuildBookPowerLawDepth(t *testing.T) {
+	config := smallConfig()
+	workload, err := NewWorkload(newBenchEngine(), config)
+	require.NoError(t, err)
+
+	placed, err := workload.BuildBook()
+	require.NoError(t, err)
+	assert.Equal(t, placed, workload.Resting())
+
+	engine := workload.Engine()
+	bestBid, ok := engine.GetBestPrice(config.TradingPair, types.OrderSideBuy)
+	require.True(t, ok)
+	bestAsk, ok := engine.GetBestPrice(config.TradingPair, types.OrderSideSell)
+	require.True(t, ok)
+	assert.Equal(t, config.MidPrice-config.TickSize, bestBid.IntPart())
+	assert.Equal(t, config.MidPrice+config.TickSize, bestAsk.IntPart())
+
+	// 最优档挂单数为 TopOrders，越深越少，但每档至少 1 笔
+	top := engine.GetOrdersAtPrice(config.TradingPair, types.OrderSideBuy, bestBid)
+	deepest := engine.GetOrdersAtPrice(config.TradingPair, types.OrderSideBuy, decimal.NewFromInt(config.MidPrice-int64(config.Levels)*config.TickSize))
+	assert.Len(t, top, config.TopOrders)
+	assert.NotEmpty(t, deepest)
+	assert.Less(t, len(deepest), len(top))
+	assert.Len(t, engine.OpenOrders(), placed)
+}
+
+func TestRunReportsLatencies(t *testing.T) {
+	workload, err := NewWorkload(newBenchEngine(), smallConfig())
+	require.NoError(t, err)
+
+	report, err := workload.Run()
+	require.NoError(t, err)
+	assert.Equal(t, 5000, report.Operations)
+	assert.Positive(t, report.Add.Count)
+	assert.Positive(t, report.Cancel.Count)
+	assert.Positive(t, report.Match.Count)
+	assert.Positive(t, report.Fills)
+	assert.Equal(t, report.Operations, report.Add.Count+report.Cancel.Count+report.Match.Count+report.Rejected)
+	assert.LessOrEqual(t, report.Cancel.P50, report.Cancel.P99)
+	assert.LessOrEqual(t, report.Cancel.P99, report.Cancel.Max)
+	assert.Positive(t, report.Throughput)
+}
+
+func TestRunIsReproducible(t *testing.T) {
+	first, err := NewWorkload(newBenchEngine(), smallConfig())
+	require.NoError(t, err)
+	second, err := NewWorkload(newBenchEngine(), smallConfig())
+	require.NoError(t, err)
+
+	a, err := first.Run()
+	require.NoError(t, err)
+	b, err := second.Run()
+	require.NoError(t, err)
+	assert.Equal(t, a.Fills, b.Fills)
+	assert.Equal(t, a.RestingOrders, b.RestingOrders)
+	assert.Equal(t, a.Cancel.Count, b.Cancel.Count)
+}
+
+func TestViolations(t *testing.T) {
+	report := &Report{
+		Throughput: 50000,
+		Add:        LatencySummary{Count: 10, P99: 20},
+		Cancel:     LatencySummary{Count: 10, P99: 80},
+		Match:      LatencySummary{},
+	}
+
+	assert.Empty(t, report.Violations(Thresholds{}))
+	assert.Empty(t, report.Violations(Thresholds{AddP99: 50 * time.Microsecond, MatchP99: time.Microsecond}), "没有样本的操作不检查")
+
+	violations := report.Violations(Thresholds{
+		AddP99:        50 * time.Microsecond,
+		CancelP99:     50 * time.Microsecond,
+		MinThroughput: 100000,
+	})
+	require.Len(t, violations, 2)
+	assert.Contains(t, violations[0], "cancel p99")
+	assert.Contains(t, violations[1], "throughput")
+}
+
+func TestConfigValidate(t *testing.T) {
+	config := DefaultConfig()
+	require.NoError(t, config.Validate())
+
+	config.CancelRatio = 0.95
+	assert.Error(t, config.Validate())
+
+	config = DefaultConfig()
+	config.MidPrice = int64(config.Levels)
+	assert.Error(t, config.Validate())
+}
+
+// BenchmarkCancelHeavy 撤单密集的做市商式负载，报告各操作 p99
+func BenchmarkCancelHeavy(b *testing.B) {
+	workload, err := NewWorkload(newBenchEngine(), DefaultConfig())
+	require.NoError(b, err)
+	_, err = workload.BuildBook()
+	require.NoError(b, err)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	report := workload.Execute(b.N)
+	b.ReportMetric(report.Add.P99, "add-p99-us")
+	b.ReportMetric(report.Cancel.P99, "cancel-p99-us")
+	b.ReportMetric(report.Match.P99, "match-p99-us")
+}
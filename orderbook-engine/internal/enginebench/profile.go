@@ -0,0 +1,41 @@
+package enginebench
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/pprof"
+)
+
+// StartCPUProfile 开始写入 CPU 剖析，返回的函数停止剖析并关闭文件
+func StartCPUProfile(path string) (func() error, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := pprof.StartCPUProfile(file); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return func() error {
+		pprof.StopCPUProfile()
+		return file.Close()
+	}, nil
+}
+
+// WriteProfile 写入命名剖析（heap、allocs、mutex、block 等），写入前触发一次 GC 使 heap 数据为最新
+func WriteProfile(name, path string) error {
+	profile := pprof.Lookup(name)
+	if profile == nil {
+		return fmt.Errorf("unknown profile %q", name)
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	if name == "heap" || name == "allocs" {
+		runtime.GC()
+	}
+	return profile.WriteTo(file, 0)
+}
@@ -0,0 +1,170 @@
+package enginebench
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"orderbook-engine/internal/types"
+)
+
+// LatencySummary 延迟分位数（微秒）
+type LatencySummary struct {
+	Count int     `json:"count"`
+	Mean  float64 `json:"mean"`
+	P50   float64 `json:"p50"`
+	P90   float64 `json:"p90"`
+	P99   float64 `json:"p99"`
+	P999  float64 `json:"p999"`
+	Max   float64 `json:"max"`
+}
+
+// Report 压测报告，延迟单位为微秒
+// 未成交的限价单计入 add，产生成交的订单（穿价限价单、市价单及恰好成交的被动挂单）计入 match
+type Report struct {
+	Config        Config         `json:"config"`
+	BookOrders    int            `json:"book_orders"` // 建簿挂单数
+	Duration      string         `json:"duration"`
+	Operations    int            `json:"operations"`
+	Throughput    float64        `json:"throughput_per_sec"`
+	Fills         int            `json:"fills"`
+	Rejected      int            `json:"rejected"` // 被引擎拒绝的订单（如对手方无流动性的市价单）
+	RestingOrders int            `json:"resting_orders"`
+	Add           LatencySummary `json:"add_latency_us"`
+	Cancel        LatencySummary `json:"cancel_latency_us"`
+	Match         LatencySummary `json:"match_latency_us"`
+}
+
+// Run 建簿后执行 Operations 个操作并统计延迟
+func (w *Workload) Run() (*Report, error) {
+	booked, err := w.BuildBook()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build book: %w", err)
+	}
+	report := w.Execute(w.config.Operations)
+	report.BookOrders = booked
+	return report, nil
+}
+
+// Execute 在当前订单簿上执行 operations 个操作并统计延迟
+func (w *Workload) Execute(operations int) *Report {
+	report := &Report{Config: w.config}
+	var adds, cancels, matches []time.Duration
+
+	started := time.Now()
+	for i := 0; i < operations; i++ {
+		kind := w.next()
+		if kind == opCancel {
+			order := w.takeResting()
+			if order == nil {
+				continue
+			}
+			at := time.Now()
+			w.engine.CancelOrder(order.ID, order.TradingPair)
+			cancels = append(cancels, time.Since(at))
+			report.Operations++
+			continue
+		}
+
+		var order *types.Order
+		switch kind {
+		case opCross:
+			order = w.crossOrder()
+		case opMarket:
+			order = w.newOrder(w.randomSide(), types.OrderTypeMarket, 0)
+		default:
+			side := w.randomSide()
+			order = w.newOrder(side, types.OrderTypeLimit, w.levelPrice(side, w.sampleLevel()))
+		}
+		at := time.Now()
+		fills, err := w.engine.AddOrder(order)
+		elapsed := time.Since(at)
+		report.Operations++
+		if err != nil {
+			report.Rejected++
+			continue
+		}
+		report.Fills += len(fills)
+		if len(fills) > 0 {
+			matches = append(matches, elapsed)
+		} else {
+			adds = append(adds, elapsed)
+		}
+		if order.Type == types.OrderTypeLimit && order.GetRemainingAmount().IsPositive() {
+			w.resting = append(w.resting, order)
+		}
+	}
+	elapsed := time.Since(started)
+
+	report.Duration = elapsed.Round(time.Millisecond).String()
+	if elapsed > 0 {
+		report.Throughput = float64(report.Operations) / elapsed.Seconds()
+	}
+	report.RestingOrders = len(w.engine.OpenOrders())
+	report.Add = summarize(adds)
+	report.Cancel = summarize(cancels)
+	report.Match = summarize(matches)
+	return report
+}
+
+// Thresholds 回归阈值，零值表示不检查
+type Thresholds struct {
+	AddP99        time.Duration
+	CancelP99     time.Duration
+	MatchP99      time.Duration
+	MinThroughput float64 // 每秒最少操作数
+}
+
+// Violations 报告超出阈值的项，为空表示通过
+func (r *Report) Violations(t Thresholds) []string {
+	var violations []string
+	check := func(name string, summary LatencySummary, limit time.Duration) {
+		if limit > 0 && summary.Count > 0 && summary.P99 > micros(limit) {
+			violations = append(violations, fmt.Sprintf("%s p99 %.1fus exceeds %s", name, summary.P99, limit))
+		}
+	}
+	check("add", r.Add, t.AddP99)
+	check("cancel", r.Cancel, t.CancelP99)
+	check("match", r.Match, t.MatchP99)
+	if t.MinThroughput > 0 && r.Throughput < t.MinThroughput {
+		violations = append(violations, fmt.Sprintf("throughput %.0f/s below %.0f/s", r.Throughput, t.MinThroughput))
+	}
+	return violations
+}
+
+// summarize 计算延迟分位数（最近秩法）
+func summarize(latencies []time.Duration) LatencySummary {
+	if len(latencies) == 0 {
+		return LatencySummary{}
+	}
+	sorted := append([]time.Duration(nil), latencies...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var total time.Duration
+	for _, latency := range sorted {
+		total += latency
+	}
+	percentile := func(p float64) float64 {
+		index := int(p*float64(len(sorted))+0.5) - 1
+		if index < 0 {
+			index = 0
+		}
+		if index >= len(sorted) {
+			index = len(sorted) - 1
+		}
+		return micros(sorted[index])
+	}
+	return LatencySummary{
+		Count: len(sorted),
+		Mean:  micros(total / time.Duration(len(sorted))),
+		P50:   percentile(0.50),
+		P90:   percentile(0.90),
+		P99:   percentile(0.99),
+		P999:  percentile(0.999),
+		Max:   micros(sorted[len(sorted)-1]),
+	}
+}
+
+func micros(d time.Duration) float64 {
+	return float64(d) / float64(time.Microsecond)
+}
@@ -0,0 +1,254 @@
+// Package enginebench 撮合引擎基准与性能剖析
+// 按幂律深度构建贴近真实的订单簿（靠近中间价的档位挂单更多），再以做市商式的高撤单比例混合
+// 挂单、撤单、穿价限价单与市价单，分别统计挂单、撤单、撮合的延迟分位数，可导出 pprof 并按阈值判定回归
+package enginebench
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"orderbook-engine/internal/matching"
+	"orderbook-engine/internal/types"
+)
+
+const (
+	benchBaseToken  = "0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2"
+	benchQuoteToken = "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"
+)
+
+// Config 负载配置
+type Config struct {
+	TradingPair   string  `json:"trading_pair"`   // 压测交易对
+	MidPrice      int64   `json:"mid_price"`      // 初始中间价（价格档位单位）
+	TickSize      int64   `json:"tick_size"`      // 价格档位间距
+	Levels        int     `json:"levels"`         // 每侧初始档位数
+	TopOrders     int     `json:"top_orders"`     // 最优档位的挂单数
+	DepthExponent float64 `json:"depth_exponent"` // 幂律指数：第 i 档（从 0 起）的挂单数为 TopOrders / (i+1)^DepthExponent，至少 1 笔
+	MaxAmount     int64   `json:"max_amount"`     // 单笔数量上限，数量在 [1, MaxAmount] 均匀分布
+	Users         int     `json:"users"`          // 挂单用户数
+	Operations    int     `json:"operations"`     // 压测操作数（不含建簿）
+	CancelRatio   float64 `json:"cancel_ratio"`   // 撤单操作占比
+	CrossRatio    float64 `json:"cross_ratio"`    // 穿价限价单占比
+	MarketRatio   float64 `json:"market_ratio"`   // 市价单占比，其余为被动挂单
+	Seed          int64   `json:"seed"`           // 随机种子，相同配置与种子生成相同的操作序列
+}
+
+// DefaultConfig 默认负载：做市商式撤单密集，40% 撤单、50% 被动挂单、10% 吃单，挂单略多于撤单与吃单的消耗使订单簿深度保持稳定
+func DefaultConfig() Config {
+	return Config{
+		TradingPair:   "WETH-USDC",
+		MidPrice:      200000,
+		TickSize:      1,
+		Levels:        200,
+		TopOrders:     50,
+		DepthExponent: 1.2,
+		MaxAmount:     10,
+		Users:         100,
+		Operations:    200000,
+		CancelRatio:   0.40,
+		CrossRatio:    0.05,
+		MarketRatio:   0.05,
+		Seed:          1,
+	}
+}
+
+// Validate 校验配置
+func (c Config) Validate() error {
+	if c.TradingPair == "" {
+		return fmt.Errorf("trading_pair is required")
+	}
+	if c.TickSize <= 0 || c.Levels <= 0 || c.TopOrders <= 0 || c.MaxAmount <= 0 || c.Users <= 0 {
+		return fmt.Errorf("tick_size, levels, top_orders, max_amount and users must be positive")
+	}
+	if c.MidPrice <= int64(c.Levels+1)*c.TickSize {
+		return fmt.Errorf("mid_price must exceed levels * tick_size")
+	}
+	if c.DepthExponent < 0 {
+		return fmt.Errorf("depth_exponent must not be negative")
+	}
+	if c.Operations < 0 {
+		return fmt.Errorf("operations must not be negative")
+	}
+	if c.CancelRatio < 0 || c.CrossRatio < 0 || c.MarketRatio < 0 || c.CancelRatio+c.CrossRatio+c.MarketRatio > 1 {
+		return fmt.Errorf("cancel, cross and market ratios must be non-negative and sum to at most 1")
+	}
+	return nil
+}
+
+// Workload 压测负载：持有引擎与本地跟踪的挂单，按配置生成操作
+type Workload struct {
+	config  Config
+	engine  *matching.MatchingEngine
+	rnd     *rand.Rand
+	weights []float64      // 各档位的累积幂律权重，用于抽样被动挂单档位
+	resting []*types.Order // 由本负载挂出、可能仍在簿上的订单
+	users   []string
+}
+
+// NewWorkload 创建负载，engine 应为空引擎
+func NewWorkload(engine *matching.MatchingEngine, config Config) (*Workload, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	w := &Workload{
+		config: config,
+		engine: engine,
+		rnd:    rand.New(rand.NewSource(config.Seed)),
+	}
+	total := 0.0
+	for level := 0; level < config.Levels; level++ {
+		total += w.levelWeight(level)
+		w.weights = append(w.weights, total)
+	}
+	for i := 0; i < config.Users; i++ {
+		w.users = append(w.users, fmt.Sprintf("0x%040x", i+1))
+	}
+	return w, nil
+}
+
+// Engine 负载使用的撮合引擎
+func (w *Workload) Engine() *matching.MatchingEngine {
+	return w.engine
+}
+
+// BuildBook 按幂律深度在中间价两侧挂出初始订单，返回挂单数
+func (w *Workload) BuildBook() (int, error) {
+	placed := 0
+	for level := 0; level < w.config.Levels; level++ {
+		count := int(math.Max(1, math.Round(float64(w.config.TopOrders)*w.levelWeight(level))))
+		for i := 0; i < count; i++ {
+			for _, side := range []types.OrderSide{types.OrderSideBuy, types.OrderSideSell} {
+				order := w.newOrder(side, types.OrderTypeLimit, w.levelPrice(side, level))
+				if _, err := w.engine.AddOrder(order); err != nil {
+					return placed, err
+				}
+				w.resting = append(w.resting, order)
+				placed++
+			}
+		}
+	}
+	return placed, nil
+}
+
+// Resting 本负载跟踪的挂单数（含已被动成交但尚未清理的）
+func (w *Workload) Resting() int {
+	return len(w.resting)
+}
+
+// levelWeight 第 level 档的相对深度
+func (w *Workload) levelWeight(level int) float64 {
+	return 1 / math.Pow(float64(level+1), w.config.DepthExponent)
+}
+
+// levelPrice 第 level 档的价格，买单在中间价下方，卖单在上方
+func (w *Workload) levelPrice(side types.OrderSide, level int) int64 {
+	offset := int64(level+1) * w.config.TickSize
+	if side == types.OrderSideBuy {
+		return w.config.MidPrice - offset
+	}
+	return w.config.MidPrice + offset
+}
+
+// sampleLevel 按幂律权重抽样档位
+func (w *Workload) sampleLevel() int {
+	target := w.rnd.Float64() * w.weights[len(w.weights)-1]
+	low, high := 0, len(w.weights)-1
+	for low < high {
+		mid := (low + high) / 2
+		if w.weights[mid] < target {
+			low = mid + 1
+		} else {
+			high = mid
+		}
+	}
+	return low
+}
+
+// randomSide 随机买卖方向
+func (w *Workload) randomSide() types.OrderSide {
+	if w.rnd.Intn(2) == 0 {
+		return types.OrderSideBuy
+	}
+	return types.OrderSideSell
+}
+
+// newOrder 生成订单，市价单 price 忽略
+func (w *Workload) newOrder(side types.OrderSide, orderType types.OrderType, price int64) *types.Order {
+	now := time.Now()
+	order := &types.Order{
+		ID:          uuid.New(),
+		UserAddress: w.users[w.rnd.Intn(len(w.users))],
+		TradingPair: w.config.TradingPair,
+		BaseToken:   benchBaseToken,
+		QuoteToken:  benchQuoteToken,
+		Side:        side,
+		Type:        orderType,
+		Amount:      decimal.NewFromInt(1 + w.rnd.Int63n(w.config.MaxAmount)),
+		Status:      types.OrderStatusOpen,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if orderType == types.OrderTypeLimit {
+		order.Price = decimal.NewFromInt(price)
+	}
+	return order
+}
+
+// opKind 操作类型
+type opKind int
+
+const (
+	opAdd opKind = iota
+	opCancel
+	opCross
+	opMarket
+)
+
+// next 抽样下一个操作类型，没有可撤挂单时撤单改为挂单
+func (w *Workload) next() opKind {
+	r := w.rnd.Float64()
+	switch {
+	case r < w.config.CancelRatio:
+		if len(w.resting) == 0 {
+			return opAdd
+		}
+		return opCancel
+	case r < w.config.CancelRatio+w.config.CrossRatio:
+		return opCross
+	case r < w.config.CancelRatio+w.config.CrossRatio+w.config.MarketRatio:
+		return opMarket
+	default:
+		return opAdd
+	}
+}
+
+// takeResting 随机取出一笔仍在簿上的挂单，顺带清理已完全成交的
+func (w *Workload) takeResting() *types.Order {
+	for len(w.resting) > 0 {
+		i := w.rnd.Intn(len(w.resting))
+		order := w.resting[i]
+		last := len(w.resting) - 1
+		w.resting[i] = w.resting[last]
+		w.resting[last] = nil
+		w.resting = w.resting[:last]
+		if order.Status == types.OrderStatusOpen || order.Status == types.OrderStatusPartiallyFilled {
+			return order
+		}
+	}
+	return nil
+}
+
+// crossOrder 穿过对手方最优档的限价单，价格落在中间价对侧前三档内
+func (w *Workload) crossOrder() *types.Order {
+	side := w.randomSide()
+	opposite := types.OrderSideSell
+	if side == types.OrderSideSell {
+		opposite = types.OrderSideBuy
+	}
+	return w.newOrder(side, types.OrderTypeLimit, w.levelPrice(opposite, w.rnd.Intn(3)))
+}
@@ -0,0 +1,222 @@
+// Package eventbus 撮合事件外部总线
+// 将成交、订单状态和订单簿增量发布到 Kafka / NATS，供外部风控、分析与归档系统消费
+package eventbus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+
+	"orderbook-engine/internal/matching"
+	"orderbook-engine/internal/types"
+)
+
+// 消息主题（实际主题为 TopicPrefix + "." + 主题）
+const (
+	TopicFills  = "fills"
+	TopicOrders = "orders"
+	TopicBook   = "book"
+)
+
+// Message 发布到外部总线的消息
+// Key 为交易对，同一交易对的消息进入同一分区（Kafka）或同一主题（NATS），保证分区内有序
+type Message struct {
+	Topic string
+	Key   string
+	ID    string // 消息唯一ID，消费者据此去重（至少一次投递可能重复）
+	Value []byte
+}
+
+// Publisher 外部事件总线
+// Publish 返回 nil 表示全部消息已被 broker 确认；返回错误时调用方整批重试
+type Publisher interface {
+	Publish(ctx context.Context, msgs []Message) error
+	Close() error
+}
+
+// Config 转发配置
+type Config struct {
+	TopicPrefix    string        // 主题前缀，如 orderbook
+	BookDepth      int           // 订单簿增量比较的档位数
+	PublishTimeout time.Duration // 单次发布超时
+	RetryBackoff   time.Duration // 发布失败后的初始重试间隔
+	MaxBackoff     time.Duration // 最大重试间隔
+}
+
+// BookDelta 订单簿增量：相对上一次发布变化的价位，Amount 为 0 表示该价位已移除
+type BookDelta struct {
+	TradingPair string                 `json:"trading_pair"`
+	Bids        []types.OrderBookLevel `json:"bids"`
+	Asks        []types.OrderBookLevel `json:"asks"`
+	Timestamp   time.Time              `json:"timestamp"`
+}
+
+// orderMessage 订单状态消息
+type orderMessage struct {
+	EventType string       `json:"event_type"`
+	Order     *types.Order `json:"order"`
+	Timestamp time.Time    `json:"timestamp"`
+}
+
+// Forwarder 消费撮合事件并发布到外部总线
+// 发布失败时按退避无限重试同一批消息，不丢弃事件（至少一次投递）；
+// 订阅不可丢弃，broker 长时间不可用时积压会反压撮合，应配置足够的订阅缓冲
+type Forwarder struct {
+	publisher Publisher
+	engine    *matching.MatchingEngine
+	config    Config
+	books     map[string]*types.OrderBookSnapshot // 各交易对上一次发布的订单簿
+	logger    *logrus.Logger
+}
+
+// NewForwarder 创建事件转发器
+func NewForwarder(publisher Publisher, engine *matching.MatchingEngine, config Config, logger *logrus.Logger) *Forwarder {
+	if config.BookDepth <= 0 {
+		config.BookDepth = 50
+	}
+	if config.PublishTimeout <= 0 {
+		config.PublishTimeout = 10 * time.Second
+	}
+	if config.RetryBackoff <= 0 {
+		config.RetryBackoff = 500 * time.Millisecond
+	}
+	if config.MaxBackoff < config.RetryBackoff {
+		config.MaxBackoff = 30 * time.Second
+	}
+	return &Forwarder{
+		publisher: publisher,
+		engine:    engine,
+		config:    config,
+		books:     make(map[string]*types.OrderBookSnapshot),
+		logger:    logger,
+	}
+}
+
+// Run 消费撮合事件直到订阅关闭
+func (f *Forwarder) Run(sub *matching.Subscription) {
+	for event := range sub.Events() {
+		msgs, err := f.messages(event)
+		if err != nil {
+			f.logger.WithError(err).WithField("event_type", event.Type).Error("Failed to encode engine event for event bus")
+			continue
+		}
+		f.publish(msgs)
+	}
+}
+
+// publish 发布一批消息，失败时退避重试直到成功
+func (f *Forwarder) publish(msgs []Message) {
+	if len(msgs) == 0 {
+		return
+	}
+
+	backoff := f.config.RetryBackoff
+	for attempt := 1; ; attempt++ {
+		ctx, cancel := context.WithTimeout(context.Background(), f.config.PublishTimeout)
+		err := f.publisher.Publish(ctx, msgs)
+		cancel()
+		if err == nil {
+			return
+		}
+
+		f.logger.WithError(err).WithFields(logrus.Fields{
+			"messages": len(msgs),
+			"attempt":  attempt,
+			"backoff":  backoff.String(),
+		}).Warn("Event bus publish failed, retrying")
+		time.Sleep(backoff)
+		if backoff *= 2; backoff > f.config.MaxBackoff {
+			backoff = f.config.MaxBackoff
+		}
+	}
+}
+
+// messages 将撮合事件转换为总线消息：成交、订单状态及订单簿增量
+func (f *Forwarder) messages(event *matching.MatchEvent) ([]Message, error) {
+	var msgs []Message
+
+	for _, fill := range event.Fills {
+		value, err := json.Marshal(fill)
+		if err != nil {
+			return nil, err
+		}
+		msgs = append(msgs, Message{Topic: f.topic(TopicFills), Key: event.TradingPair, ID: fill.ID.String(), Value: value})
+	}
+
+	if event.Order != nil {
+		value, err := json.Marshal(&orderMessage{EventType: event.Type, Order: event.Order, Timestamp: event.Timestamp})
+		if err != nil {
+			return nil, err
+		}
+		id := fmt.Sprintf("%s:%s:%s:%d", event.Order.ID, event.Type, event.Order.Status, event.Order.UpdatedAt.UnixNano())
+		msgs = append(msgs, Message{Topic: f.topic(TopicOrders), Key: event.TradingPair, ID: id, Value: value})
+	}
+
+	if delta := f.bookDelta(event.TradingPair); delta != nil {
+		value, err := json.Marshal(delta)
+		if err != nil {
+			return nil, err
+		}
+		id := fmt.Sprintf("%s:%d", event.TradingPair, delta.Timestamp.UnixNano())
+		msgs = append(msgs, Message{Topic: f.topic(TopicBook), Key: event.TradingPair, ID: id, Value: value})
+	}
+	return msgs, nil
+}
+
+// bookDelta 计算交易对订单簿相对上一次发布的增量，没有变化时返回 nil
+func (f *Forwarder) bookDelta(tradingPair string) *BookDelta {
+	current := f.engine.GetOrderBook(tradingPair, f.config.BookDepth)
+	previous := f.books[tradingPair]
+	f.books[tradingPair] = current
+
+	var prevBids, prevAsks []types.OrderBookLevel
+	if previous != nil {
+		prevBids, prevAsks = previous.Bids, previous.Asks
+	}
+
+	delta := &BookDelta{
+		TradingPair: tradingPair,
+		Bids:        diffLevels(prevBids, current.Bids),
+		Asks:        diffLevels(prevAsks, current.Asks),
+		Timestamp:   current.Timestamp,
+	}
+	if len(delta.Bids) == 0 && len(delta.Asks) == 0 {
+		return nil
+	}
+	return delta
+}
+
+// topic 带前缀的主题名
+func (f *Forwarder) topic(name string) string {
+	if f.config.TopicPrefix == "" {
+		return name
+	}
+	return f.config.TopicPrefix + "." + name
+}
+
+// diffLevels 比较两次快照的价位，返回新增或数量变化的价位及数量置 0 的已移除价位
+func diffLevels(previous, current []types.OrderBookLevel) []types.OrderBookLevel {
+	prev := make(map[string]types.OrderBookLevel, len(previous))
+	for _, level := range previous {
+		prev[level.Price.String()] = level
+	}
+
+	changes := []types.OrderBookLevel{}
+	for _, level := range current {
+		key := level.Price.String()
+		if old, exists := prev[key]; !exists || !old.Amount.Equal(level.Amount) || old.Count != level.Count {
+			changes = append(changes, level)
+		}
+		delete(prev, key)
+	}
+	for _, level := range previous {
+		if _, removed := prev[level.Price.String()]; removed {
+			changes = append(changes, types.OrderBookLevel{Price: level.Price, Amount: decimal.Zero})
+		}
+	}
+	return changes
+}
@@ -0,0 +1,95 @@
+package eventbus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"orderbook-engine/internal/matching"
+	"orderbook-engine/internal/types"
+)
+
+// fakePublisher 前 failures 次发布失败，之后记录全部消息
+type fakePublisher struct {
+	failures int
+	calls    int
+	msgs     []Message
+}
+
+func (p *fakePublisher) Publish(ctx context.Context, msgs []Message) error {
+	if p.calls++; p.calls <= p.failures {
+		return errors.New("broker unavailable")
+	}
+	p.msgs = append(p.msgs, msgs...)
+	return nil
+}
+
+func (p *fakePublisher) Close() error { return nil }
+
+func TestForwarderPublishesFillsAndBookDeltas(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel)
+	engine := matching.NewMatchingEngine(logger)
+	sub := engine.Subscribe(matching.SubscriptionOptions{Name: "eventbus"})
+
+	newOrder := func(side types.OrderSide, price int64) *types.Order {
+		return &types.Order{
+			ID:          uuid.New(),
+			UserAddress: "0x1234567890123456789012345678901234567890",
+			TradingPair: "WETH-USDC",
+			Side:        side,
+			Type:        types.OrderTypeLimit,
+			Price:       decimal.NewFromInt(price),
+			Amount:      decimal.NewFromInt(1),
+			CreatedAt:   time.Now(),
+		}
+	}
+
+	publisher := &fakePublisher{failures: 1}
+	forwarder := NewForwarder(publisher, engine, Config{TopicPrefix: "orderbook", RetryBackoff: time.Millisecond}, logger)
+	forward := func() {
+		for len(sub.Events()) > 0 {
+			msgs, err := forwarder.messages(<-sub.Events())
+			require.NoError(t, err)
+			forwarder.publish(msgs)
+		}
+	}
+
+	_, err := engine.AddOrder(newOrder(types.OrderSideSell, 2000))
+	require.NoError(t, err)
+	forward()
+	fills, err := engine.AddOrder(newOrder(types.OrderSideBuy, 2000))
+	require.NoError(t, err)
+	require.Len(t, fills, 1)
+	forward()
+
+	// 首次失败后重试，消息不丢失
+	var fillMsgs, bookMsgs []Message
+	for _, msg := range publisher.msgs {
+		assert.Equal(t, "WETH-USDC", msg.Key)
+		switch msg.Topic {
+		case "orderbook.fills":
+			fillMsgs = append(fillMsgs, msg)
+		case "orderbook.book":
+			bookMsgs = append(bookMsgs, msg)
+		}
+	}
+	require.Len(t, fillMsgs, 1)
+	assert.Equal(t, fills[0].ID.String(), fillMsgs[0].ID)
+
+	// 成交后卖单被吃掉，订单簿增量以数量 0 表示该价位移除
+	require.NotEmpty(t, bookMsgs)
+	var delta BookDelta
+	require.NoError(t, json.Unmarshal(bookMsgs[len(bookMsgs)-1].Value, &delta))
+	require.Len(t, delta.Asks, 1)
+	assert.True(t, delta.Asks[0].Price.Equal(decimal.NewFromInt(2000)))
+	assert.True(t, delta.Asks[0].Amount.IsZero())
+}
@@ -0,0 +1,44 @@
+package eventbus
+
+import (
+	"context"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// KafkaPublisher Kafka 发布
+// 按交易对哈希分区，要求全部 ISR 副本确认后才视为发布成功
+type KafkaPublisher struct {
+	writer *kafka.Writer
+}
+
+// NewKafkaPublisher 创建 Kafka 发布
+func NewKafkaPublisher(brokers []string) *KafkaPublisher {
+	return &KafkaPublisher{
+		writer: &kafka.Writer{
+			Addr:                   kafka.TCP(brokers...),
+			Balancer:               &kafka.Hash{},
+			RequiredAcks:           kafka.RequireAll,
+			AllowAutoTopicCreation: true,
+		},
+	}
+}
+
+// Publish 同步写入一批消息，返回时已收到 broker 确认
+func (p *KafkaPublisher) Publish(ctx context.Context, msgs []Message) error {
+	records := make([]kafka.Message, 0, len(msgs))
+	for _, msg := range msgs {
+		records = append(records, kafka.Message{
+			Topic:   msg.Topic,
+			Key:     []byte(msg.Key),
+			Value:   msg.Value,
+			Headers: []kafka.Header{{Key: "message-id", Value: []byte(msg.ID)}},
+		})
+	}
+	return p.writer.WriteMessages(ctx, records...)
+}
+
+// Close 关闭连接
+func (p *KafkaPublisher) Close() error {
+	return p.writer.Close()
+}
@@ -0,0 +1,63 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/nats-io/nats.go"
+)
+
+// NATSPublisher NATS JetStream 发布
+// 主题为 <topic>.<交易对>，以消息ID作为 JetStream 去重ID，收到 PubAck 才视为发布成功
+type NATSPublisher struct {
+	conn *nats.Conn
+	js   nats.JetStreamContext
+}
+
+// NewNATSPublisher 连接 NATS 并确保 stream 存在（订阅 <prefix>.> 下的全部主题）
+func NewNATSPublisher(url, stream, topicPrefix string) (*NATSPublisher, error) {
+	conn, err := nats.Connect(url, nats.Name("orderbook-engine"), nats.MaxReconnects(-1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to NATS: %w", err)
+	}
+	js, err := conn.JetStream()
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to open JetStream context: %w", err)
+	}
+
+	if _, err := js.StreamInfo(stream); errors.Is(err, nats.ErrStreamNotFound) {
+		subjects := ">"
+		if topicPrefix != "" {
+			subjects = topicPrefix + ".>"
+		}
+		if _, err := js.AddStream(&nats.StreamConfig{Name: stream, Subjects: []string{subjects}}); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("failed to create stream %s: %w", stream, err)
+		}
+	} else if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to look up stream %s: %w", stream, err)
+	}
+
+	return &NATSPublisher{conn: conn, js: js}, nil
+}
+
+// Publish 逐条发布并等待 JetStream 确认
+func (p *NATSPublisher) Publish(ctx context.Context, msgs []Message) error {
+	for _, msg := range msgs {
+		if _, err := p.js.PublishMsg(&nats.Msg{
+			Subject: msg.Topic + "." + msg.Key,
+			Data:    msg.Value,
+		}, nats.MsgId(msg.ID), nats.Context(ctx)); err != nil {
+			return fmt.Errorf("publish %s: %w", msg.Topic, err)
+		}
+	}
+	return nil
+}
+
+// Close 断开连接
+func (p *NATSPublisher) Close() error {
+	return p.conn.Drain()
+}
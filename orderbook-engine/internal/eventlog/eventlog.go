@@ -0,0 +1,191 @@
+// Package eventlog 撮合事件日志
+// 按顺序把撮合引擎事件追加写入 JSON Lines 文件，用于事后审计、确定性重放与回测
+package eventlog
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"orderbook-engine/internal/matching"
+	"orderbook-engine/internal/types"
+)
+
+// maxEntrySize 单条日志的最大字节数
+const maxEntrySize = 16 << 20
+
+// Entry 事件日志条目，与撮合事件一一对应
+type Entry struct {
+	Seq         uint64        `json:"seq"`
+	Type        string        `json:"type"` // matching.Event*
+	TradingPair string        `json:"trading_pair"`
+	Order       *types.Order  `json:"order,omitempty"` // 事件发生后的订单快照
+	Fills       []*types.Fill `json:"fills,omitempty"`
+	Timestamp   time.Time     `json:"timestamp"`
+}
+
+// Writer 事件日志写入器
+type Writer struct {
+	mu     sync.Mutex
+	path   string
+	file   *os.File
+	buf    *bufio.Writer
+	seq    uint64
+	logger *logrus.Logger
+}
+
+// NewWriter 打开事件日志文件（不存在时创建），在已有记录之后继续追加
+func NewWriter(path string, logger *logrus.Logger) (*Writer, error) {
+	seq, err := lastSeq(path)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open event log: %w", err)
+	}
+
+	return &Writer{
+		path:   path,
+		file:   file,
+		buf:    bufio.NewWriter(file),
+		seq:    seq,
+		logger: logger,
+	}, nil
+}
+
+// Append 追加一条撮合事件
+func (w *Writer) Append(event *matching.MatchEvent) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.seq++
+	data, err := json.Marshal(&Entry{
+		Seq:         w.seq,
+		Type:        event.Type,
+		TradingPair: event.TradingPair,
+		Order:       event.Order,
+		Fills:       event.Fills,
+		Timestamp:   event.Timestamp,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode event %d: %w", w.seq, err)
+	}
+	if _, err := w.buf.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write event %d: %w", w.seq, err)
+	}
+	return nil
+}
+
+// Flush 将缓冲写入文件
+func (w *Writer) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.Flush()
+}
+
+// Resync 落盘后重新读取日志文件的最后序号
+// 多实例共享日志时，备用实例接管前原主实例可能已在其后追加记录
+func (w *Writer) Resync() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if err := w.buf.Flush(); err != nil {
+		return err
+	}
+	seq, err := lastSeq(w.path)
+	if err != nil {
+		return err
+	}
+	w.seq = seq
+	return nil
+}
+
+// Run 消费撮合事件直到订阅关闭，积压清空时落盘
+func (w *Writer) Run(sub *matching.Subscription) {
+	for event := range sub.Events() {
+		if err := w.Append(event); err != nil {
+			w.logger.WithError(err).Error("Failed to append event log entry")
+			continue
+		}
+		if len(sub.Events()) == 0 {
+			if err := w.Flush(); err != nil {
+				w.logger.WithError(err).Error("Failed to flush event log")
+			}
+		}
+	}
+}
+
+// Close 落盘并关闭文件
+func (w *Writer) Close() error {
+	if err := w.Flush(); err != nil {
+		w.file.Close()
+		return err
+	}
+	return w.file.Close()
+}
+
+// Reader 按顺序读取事件日志
+type Reader struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+// NewReader 创建事件日志读取器
+func NewReader(r io.Reader) *Reader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxEntrySize)
+	return &Reader{scanner: scanner}
+}
+
+// Next 读取下一条记录，读完时返回 io.EOF
+func (r *Reader) Next() (*Entry, error) {
+	for r.scanner.Scan() {
+		r.line++
+		if len(r.scanner.Bytes()) == 0 {
+			continue
+		}
+		var entry Entry
+		if err := json.Unmarshal(r.scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("line %d: %w", r.line, err)
+		}
+		return &entry, nil
+	}
+	if err := r.scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+// lastSeq 读取已有日志文件的最后序号，文件不存在时为 0
+func lastSeq(path string) (uint64, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to open event log: %w", err)
+	}
+	defer file.Close()
+
+	var seq uint64
+	reader := NewReader(file)
+	for {
+		entry, err := reader.Next()
+		if err == io.EOF {
+			return seq, nil
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to read event log: %w", err)
+		}
+		seq = entry.Seq
+	}
+}
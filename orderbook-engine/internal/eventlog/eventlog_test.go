@@ -0,0 +1,80 @@
+package eventlog
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"orderbook-engine/internal/matching"
+	"orderbook-engine/internal/types"
+)
+
+func newOrder(side types.OrderSide, price, amount int64) *types.Order {
+	return &types.Order{
+		ID:          uuid.New(),
+		UserAddress: "0x1234567890123456789012345678901234567890",
+		TradingPair: "WETH-USDC",
+		Side:        side,
+		Type:        types.OrderTypeLimit,
+		Price:       decimal.NewFromInt(price),
+		Amount:      decimal.NewFromInt(amount),
+		CreatedAt:   time.Now(),
+	}
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.WarnLevel)
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+
+	// 记录一段订单流
+	engine := matching.NewMatchingEngine(logger)
+	sub := engine.Subscribe(matching.SubscriptionOptions{Name: "eventlog"})
+	cancelled := newOrder(types.OrderSideSell, 2005, 1)
+	for _, order := range []*types.Order{
+		newOrder(types.OrderSideSell, 2000, 1),
+		newOrder(types.OrderSideSell, 2010, 2),
+		cancelled,
+		newOrder(types.OrderSideBuy, 2010, 2),
+	} {
+		_, err := engine.AddOrder(order)
+		require.NoError(t, err)
+		if order == cancelled {
+			require.True(t, engine.CancelOrder(order.ID, order.TradingPair))
+		}
+	}
+
+	writer, err := NewWriter(path, logger)
+	require.NoError(t, err)
+	for len(sub.Events()) > 0 {
+		require.NoError(t, writer.Append(<-sub.Events()))
+	}
+	require.NoError(t, writer.Close())
+
+	// 重放到新引擎，成交与订单簿一致
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	replayed := matching.NewMatchingEngine(logger)
+	result, err := Replay(NewReader(file), replayed, ReplayOptions{Compare: true}, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 5, result.Entries)
+	assert.Equal(t, 2, result.Fills)
+	assert.Equal(t, 1, result.Cancels)
+	assert.Empty(t, result.Mismatches)
+	assert.Equal(t, engine.GetOrderBook("WETH-USDC", 10).Asks, replayed.GetOrderBook("WETH-USDC", 10).Asks)
+
+	// 重新打开时在已有序号之后追加
+	writer, err = NewWriter(path, logger)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(5), writer.seq)
+	require.NoError(t, writer.Close())
+}
@@ -0,0 +1,162 @@
+package eventlog
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"orderbook-engine/internal/matching"
+	"orderbook-engine/internal/types"
+)
+
+// ReplayOptions 重放选项
+type ReplayOptions struct {
+	Speed   float64 // 重放速度倍数，按记录的事件间隔等待；0 表示不等待
+	Compare bool    // 逐笔比对重放成交与记录的成交
+}
+
+// Mismatch 重放成交与记录不一致
+type Mismatch struct {
+	Seq      uint64    `json:"seq"`
+	OrderID  uuid.UUID `json:"order_id"`
+	Expected []string  `json:"expected"`
+	Actual   []string  `json:"actual"`
+}
+
+// ReplayResult 重放结果
+type ReplayResult struct {
+	Entries    int        `json:"entries"`
+	Orders     int        `json:"orders"`
+	Cancels    int        `json:"cancels"`
+	Fills      int        `json:"fills"`
+	Skipped    int        `json:"skipped"` // 无法由订单流重现的事件（集合竞价撮合等）
+	Pairs      []string   `json:"pairs"`
+	Mismatches []Mismatch `json:"mismatches,omitempty"`
+}
+
+// Replay 将记录的订单流按顺序送入撮合引擎
+// 新订单按提交时的状态重新撮合；撤单、签名超期与过期按记录的时点撤出订单簿，
+// 重放时不再按墙钟判断过期，保证结果只取决于日志本身。
+// onFill 不为空时对每笔重放成交调用，onEntry 不为空时在每条记录处理后调用
+func Replay(reader *Reader, engine *matching.MatchingEngine, opts ReplayOptions, onEntry func(entry *Entry), onFill func(fill *types.Fill)) (*ReplayResult, error) {
+	result := &ReplayResult{}
+	seenPairs := make(map[string]bool)
+
+	var last time.Time
+	for {
+		entry, err := reader.Next()
+		if err == io.EOF {
+			return result, nil
+		}
+		if err != nil {
+			return result, err
+		}
+		result.Entries++
+
+		if opts.Speed > 0 && !last.IsZero() && entry.Timestamp.After(last) {
+			time.Sleep(time.Duration(float64(entry.Timestamp.Sub(last)) / opts.Speed))
+		}
+		last = entry.Timestamp
+
+		if !seenPairs[entry.TradingPair] {
+			seenPairs[entry.TradingPair] = true
+			result.Pairs = append(result.Pairs, entry.TradingPair)
+		}
+
+		switch entry.Type {
+		case matching.EventOrderAdded:
+			if entry.Order == nil {
+				return result, fmt.Errorf("seq %d: order_added without order", entry.Seq)
+			}
+			result.Orders++
+			fills, err := engine.AddOrder(submittedOrder(entry.Order))
+			if err != nil {
+				// 记录中已被接受的订单在重放时被拒绝，视为不一致
+				result.Mismatches = append(result.Mismatches, Mismatch{
+					Seq:      entry.Seq,
+					OrderID:  entry.Order.ID,
+					Expected: fillKeys(entry.Fills),
+					Actual:   []string{"rejected: " + err.Error()},
+				})
+				break
+			}
+			result.Fills += len(fills)
+			for _, fill := range fills {
+				if onFill != nil {
+					onFill(fill)
+				}
+			}
+			if opts.Compare {
+				if mismatch := compareFills(entry, fills); mismatch != nil {
+					result.Mismatches = append(result.Mismatches, *mismatch)
+				}
+			}
+
+		case matching.EventOrderCancelled, matching.EventOrderExpired:
+			if entry.Order != nil && engine.CancelOrder(entry.Order.ID, entry.TradingPair) {
+				result.Cancels++
+			}
+
+		case matching.EventOrderReduced:
+			if entry.Order != nil {
+				engine.ReduceOrder(entry.Order.ID, entry.TradingPair, entry.Order.GetRemainingAmount())
+			}
+
+		case matching.EventOrderRejected:
+			// 被拒绝的订单不影响订单簿
+
+		default:
+			result.Skipped++
+		}
+
+		if onEntry != nil {
+			onEntry(entry)
+		}
+	}
+}
+
+// submittedOrder 由事件中的订单快照还原提交时的订单
+// 过期时间不参与重放，过期以记录的 order_expired 事件为准
+func submittedOrder(snapshot *types.Order) *types.Order {
+	order := *snapshot
+	order.FilledAmount = decimal.Zero
+	order.AvgFillPrice = decimal.Zero
+	order.QuoteFilled = decimal.Zero
+	order.FeePaid = decimal.Zero
+	order.Status = types.OrderStatusPending
+	order.StatusReason = ""
+	order.ExpiresAt = nil
+	return &order
+}
+
+// compareFills 比对重放成交与记录成交（成交ID为随机值，按对手单、价格与数量比对）
+func compareFills(entry *Entry, fills []*types.Fill) *Mismatch {
+	expected := fillKeys(entry.Fills)
+	actual := fillKeys(fills)
+
+	equal := len(expected) == len(actual)
+	for i := 0; equal && i < len(expected); i++ {
+		equal = expected[i] == actual[i]
+	}
+	if equal {
+		return nil
+	}
+	return &Mismatch{
+		Seq:      entry.Seq,
+		OrderID:  entry.Order.ID,
+		Expected: expected,
+		Actual:   actual,
+	}
+}
+
+// fillKeys 成交的比对键：maker订单@价格x数量
+func fillKeys(fills []*types.Fill) []string {
+	keys := make([]string, 0, len(fills))
+	for _, fill := range fills {
+		keys = append(keys, fmt.Sprintf("%s@%sx%s", fill.MakerOrderID, fill.Price.String(), fill.Amount.String()))
+	}
+	return keys
+}
@@ -0,0 +1,117 @@
+// Package execution 订单成交回报
+// 由订单与其成交按时间累计生成逐笔回报（累计成交数量、成交均价、剩余数量与最近一笔成交），
+// 实时推送与 REST 查询使用同一套计算
+package execution
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"orderbook-engine/internal/types"
+)
+
+// Source 读取订单与其成交（maker、taker 两侧）
+type Source interface {
+	GetOrder(orderID uuid.UUID) (*types.Order, error)
+	GetOrderFills(orderID uuid.UUID) ([]*types.Fill, error)
+}
+
+// Build 按成交时间生成订单的逐笔成交回报，每笔成交一条；不属于该订单的成交被忽略
+func Build(order *types.Order, fills []*types.Fill) []*types.ExecutionReport {
+	own := make([]*types.Fill, 0, len(fills))
+	for _, fill := range fills {
+		if fill.TakerOrderID == order.ID || fill.MakerOrderID == order.ID {
+			own = append(own, fill)
+		}
+	}
+	sort.SliceStable(own, func(i, j int) bool { return own[i].CreatedAt.Before(own[j].CreatedAt) })
+
+	// 只减仓缩减的数量不再成交
+	target := order.Amount.Sub(order.ReducedAmount)
+	reports := make([]*types.ExecutionReport, 0, len(own))
+	cumulative, notional := decimal.Zero, decimal.Zero
+	for i, fill := range own {
+		cumulative = cumulative.Add(fill.Amount)
+		notional = notional.Add(fill.Amount.Mul(fill.Price))
+
+		report := &types.ExecutionReport{
+			OrderID:            order.ID,
+			UserAddress:        order.UserAddress,
+			TradingPair:        order.TradingPair,
+			Side:               order.Side,
+			OrderStatus:        types.OrderStatusPartiallyFilled,
+			OrderAmount:        order.Amount,
+			CumulativeQuantity: cumulative,
+			AveragePrice:       notional.Div(cumulative),
+			RemainingQuantity:  decimal.Max(target.Sub(cumulative), decimal.Zero),
+			FillCount:          i + 1,
+			LastFillID:         fill.ID,
+			LastPrice:          fill.Price,
+			LastQuantity:       fill.Amount,
+			LastRole:           "maker",
+			Timestamp:          fill.CreatedAt,
+		}
+		if fill.TakerOrderID == order.ID {
+			report.LastRole = "taker"
+		}
+		if !report.RemainingQuantity.IsPositive() {
+			report.OrderStatus = types.OrderStatusFilled
+		}
+		reports = append(reports, report)
+	}
+	return reports
+}
+
+// Reporter 从存储生成成交回报
+type Reporter struct {
+	source Source
+}
+
+// NewReporter 创建成交回报生成器
+func NewReporter(source Source) *Reporter {
+	return &Reporter{source: source}
+}
+
+// FillReports 一笔成交对 taker、maker 订单的回报
+// batch 为同一撮合事件中的成交：撮合事件与成交落库并发，存储中可能还没有这些成交
+func (r *Reporter) FillReports(fill *types.Fill, batch []*types.Fill) ([]*types.ExecutionReport, error) {
+	reports := make([]*types.ExecutionReport, 0, 2)
+	for _, orderID := range []uuid.UUID{fill.TakerOrderID, fill.MakerOrderID} {
+		order, err := r.source.GetOrder(orderID)
+		if err != nil {
+			return reports, fmt.Errorf("failed to get order %s: %w", orderID, err)
+		}
+		stored, err := r.source.GetOrderFills(orderID)
+		if err != nil {
+			return reports, fmt.Errorf("failed to get fills of order %s: %w", orderID, err)
+		}
+
+		for _, report := range Build(order, mergeFills(stored, batch)) {
+			if report.LastFillID == fill.ID {
+				reports = append(reports, report)
+				break
+			}
+		}
+	}
+	return reports, nil
+}
+
+// mergeFills 合并已落库的成交与撮合事件中的成交，按成交ID去重
+func mergeFills(stored, batch []*types.Fill) []*types.Fill {
+	seen := make(map[uuid.UUID]bool, len(stored))
+	merged := make([]*types.Fill, 0, len(stored)+len(batch))
+	for _, fill := range stored {
+		seen[fill.ID] = true
+		merged = append(merged, fill)
+	}
+	for _, fill := range batch {
+		if !seen[fill.ID] {
+			seen[fill.ID] = true
+			merged = append(merged, fill)
+		}
+	}
+	return merged
+}
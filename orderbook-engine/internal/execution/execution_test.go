@@ -0,0 +1,125 @@
+package execution
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"orderbook-engine/internal/types"
+)
+
+// memorySource 内存中的订单与成交
+type memorySource struct {
+	orders map[uuid.UUID]*types.Order
+	fills  []*types.Fill
+}
+
+func (s *memorySource) GetOrder(orderID uuid.UUID) (*types.Order, error) {
+	if order, ok := s.orders[orderID]; ok {
+		return order, nil
+	}
+	return nil, errors.New("order not found")
+}
+
+func (s *memorySource) GetOrderFills(orderID uuid.UUID) ([]*types.Fill, error) {
+	var result []*types.Fill
+	for _, fill := range s.fills {
+		if fill.TakerOrderID == orderID || fill.MakerOrderID == orderID {
+			result = append(result, fill)
+		}
+	}
+	return result, nil
+}
+
+func newOrder(side types.OrderSide, amount string) *types.Order {
+	return &types.Order{
+		ID:          uuid.New(),
+		UserAddress: "0xabc",
+		TradingPair: "ETH-USDC",
+		Side:        side,
+		Amount:      decimal.RequireFromString(amount),
+	}
+}
+
+func newFill(taker, maker *types.Order, price, amount string, at time.Time) *types.Fill {
+	return &types.Fill{
+		ID:           uuid.New(),
+		TakerOrderID: taker.ID,
+		MakerOrderID: maker.ID,
+		TradingPair:  taker.TradingPair,
+		Price:        decimal.RequireFromString(price),
+		Amount:       decimal.RequireFromString(amount),
+		TakerSide:    taker.Side,
+		CreatedAt:    at,
+	}
+}
+
+func TestBuildCumulativeReports(t *testing.T) {
+	maker := newOrder(types.OrderSideSell, "3")
+	taker1 := newOrder(types.OrderSideBuy, "1")
+	taker2 := newOrder(types.OrderSideBuy, "5")
+
+	start := time.Now()
+	// 乱序传入，按成交时间累计
+	fills := []*types.Fill{
+		newFill(taker2, maker, "2010", "2", start.Add(time.Second)),
+		newFill(taker1, maker, "2000", "1", start),
+	}
+
+	reports := Build(maker, fills)
+	require.Len(t, reports, 2)
+
+	assert.Equal(t, fills[1].ID, reports[0].LastFillID)
+	assert.True(t, reports[0].CumulativeQuantity.Equal(decimal.RequireFromString("1")))
+	assert.True(t, reports[0].RemainingQuantity.Equal(decimal.RequireFromString("2")))
+	assert.Equal(t, types.OrderStatusPartiallyFilled, reports[0].OrderStatus)
+	assert.Equal(t, "maker", reports[0].LastRole)
+
+	// (2000×1 + 2010×2) / 3
+	assert.True(t, reports[1].AveragePrice.Equal(decimal.RequireFromString("2006.6666666666666667")), reports[1].AveragePrice.String())
+	assert.True(t, reports[1].CumulativeQuantity.Equal(decimal.RequireFromString("3")))
+	assert.True(t, reports[1].RemainingQuantity.IsZero())
+	assert.Equal(t, types.OrderStatusFilled, reports[1].OrderStatus)
+	assert.Equal(t, 2, reports[1].FillCount)
+	assert.True(t, reports[1].LastPrice.Equal(decimal.RequireFromString("2010")))
+}
+
+func TestFillReportsIncludeUnstoredFills(t *testing.T) {
+	maker := newOrder(types.OrderSideSell, "3")
+	taker := newOrder(types.OrderSideBuy, "3")
+	earlier := newOrder(types.OrderSideBuy, "1")
+
+	start := time.Now()
+	stored := newFill(earlier, maker, "2000", "1", start)
+	source := &memorySource{
+		orders: map[uuid.UUID]*types.Order{maker.ID: maker, taker.ID: taker, earlier.ID: earlier},
+		fills:  []*types.Fill{stored},
+	}
+
+	// 撮合事件中的成交尚未落库
+	fill := newFill(taker, maker, "2020", "2", start.Add(time.Second))
+	reports, err := NewReporter(source).FillReports(fill, []*types.Fill{fill})
+	require.NoError(t, err)
+	require.Len(t, reports, 2)
+
+	assert.Equal(t, taker.ID, reports[0].OrderID)
+	assert.Equal(t, "taker", reports[0].LastRole)
+	assert.True(t, reports[0].CumulativeQuantity.Equal(decimal.RequireFromString("2")))
+	assert.True(t, reports[0].RemainingQuantity.Equal(decimal.RequireFromString("1")))
+
+	assert.Equal(t, maker.ID, reports[1].OrderID)
+	assert.Equal(t, 2, reports[1].FillCount)
+	assert.True(t, reports[1].CumulativeQuantity.Equal(decimal.RequireFromString("3")))
+	assert.Equal(t, types.OrderStatusFilled, reports[1].OrderStatus)
+
+	// 成交落库后不会重复计算
+	source.fills = append(source.fills, fill)
+	reports, err = NewReporter(source).FillReports(fill, []*types.Fill{fill})
+	require.NoError(t, err)
+	assert.Equal(t, 2, reports[1].FillCount)
+}
@@ -0,0 +1,191 @@
+// Package gasbudget 链上结算的Gas支出预算
+// 按滚动的一小时、一天窗口累计结算交易消耗的原生代币（ETH），超出预算时由结算管理器暂停提交或降低批次频率
+package gasbudget
+
+import (
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Action 超出预算后的处理方式
+type Action string
+
+const (
+	ActionPause Action = "pause" // 暂停提交，成交继续排队
+	ActionSlow  Action = "slow"  // 降低提交频率，让批次攒满以摊薄每笔成交的Gas
+)
+
+// ParseAction 解析超出预算后的处理方式
+func ParseAction(value string) (Action, error) {
+	switch action := Action(value); action {
+	case ActionPause, ActionSlow:
+		return action, nil
+	default:
+		return "", fmt.Errorf("invalid gas budget action %q, want pause or slow", value)
+	}
+}
+
+// 预算窗口
+const (
+	WindowHourly = "hourly"
+	WindowDaily  = "daily"
+)
+
+// Config 预算配置，预算为零表示该窗口不限制
+type Config struct {
+	Hourly       decimal.Decimal // 滚动一小时内的支出上限（ETH）
+	Daily        decimal.Decimal // 滚动一天内的支出上限（ETH）
+	Action       Action
+	SlowInterval time.Duration // slow 模式下两次提交的最小间隔
+}
+
+// Enabled 是否设置了任一预算
+func (c Config) Enabled() bool {
+	return c.Hourly.IsPositive() || c.Daily.IsPositive()
+}
+
+// Status 预算状态
+type Status struct {
+	ChainID      uint64          `json:"chain_id"`
+	HourlySpent  decimal.Decimal `json:"hourly_spent"`
+	HourlyBudget decimal.Decimal `json:"hourly_budget"`
+	DailySpent   decimal.Decimal `json:"daily_spent"`
+	DailyBudget  decimal.Decimal `json:"daily_budget"`
+	Transactions int             `json:"transactions"` // 一天内的结算交易数
+	Exceeded     bool            `json:"exceeded"`
+	Window       string          `json:"window,omitempty"` // 超出预算的窗口
+	Action       Action          `json:"action"`
+	ResumesAt    *time.Time      `json:"resumes_at,omitempty"` // 不再有新支出时回到预算内的时间
+	Timestamp    time.Time       `json:"timestamp"`
+}
+
+// spend 单笔结算交易的支出
+type spend struct {
+	at     time.Time
+	amount decimal.Decimal
+}
+
+// Tracker 单条链的Gas支出预算
+type Tracker struct {
+	mu       sync.Mutex
+	chainID  uint64
+	config   Config
+	spends   []spend // 一天内的支出，按时间排序
+	exceeded bool
+	onChange func(Status)
+}
+
+// NewTracker 创建Gas支出预算
+func NewTracker(chainID uint64, config Config) *Tracker {
+	return &Tracker{chainID: chainID, config: config}
+}
+
+// SetChangeHandler 设置超出预算、恢复到预算内时的回调
+func (t *Tracker) SetChangeHandler(handler func(Status)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.onChange = handler
+}
+
+// Record 记录一笔结算交易的支出：gasUsed × effectiveGasPrice（wei）
+func (t *Tracker) Record(now time.Time, gasUsed uint64, gasPrice *big.Int) Status {
+	wei := new(big.Int).Mul(new(big.Int).SetUint64(gasUsed), gasPrice)
+
+	t.mu.Lock()
+	t.spends = append(t.spends, spend{at: now, amount: decimal.NewFromBigInt(wei, -18)})
+	status, handler := t.evaluate(now)
+	t.mu.Unlock()
+
+	if handler != nil {
+		handler(status)
+	}
+	return status
+}
+
+// Check 重新评估预算（窗口滚动后可能恢复到预算内）
+func (t *Tracker) Check(now time.Time) Status {
+	t.mu.Lock()
+	status, handler := t.evaluate(now)
+	t.mu.Unlock()
+
+	if handler != nil {
+		handler(status)
+	}
+	return status
+}
+
+// Action 超出预算后的处理方式
+func (t *Tracker) Action() Action {
+	return t.config.Action
+}
+
+// SlowInterval slow 模式下两次提交的最小间隔
+func (t *Tracker) SlowInterval() time.Duration {
+	return t.config.SlowInterval
+}
+
+// evaluate 清理过期支出并计算状态，状态变化时返回需要调用的回调，调用方需持有锁
+func (t *Tracker) evaluate(now time.Time) (Status, func(Status)) {
+	dayStart := now.Add(-24 * time.Hour)
+	hourStart := now.Add(-time.Hour)
+
+	expired := 0
+	for expired < len(t.spends) && !t.spends[expired].at.After(dayStart) {
+		expired++
+	}
+	t.spends = t.spends[expired:]
+
+	status := Status{
+		ChainID:      t.chainID,
+		HourlyBudget: t.config.Hourly,
+		DailyBudget:  t.config.Daily,
+		Transactions: len(t.spends),
+		Action:       t.config.Action,
+		Timestamp:    now,
+	}
+	for _, s := range t.spends {
+		status.DailySpent = status.DailySpent.Add(s.amount)
+		if s.at.After(hourStart) {
+			status.HourlySpent = status.HourlySpent.Add(s.amount)
+		}
+	}
+
+	// 日预算优先报告，其恢复时间更晚
+	var resumesAt time.Time
+	if t.config.Daily.IsPositive() && status.DailySpent.GreaterThanOrEqual(t.config.Daily) {
+		status.Window = WindowDaily
+		resumesAt = t.resumeTime(status.DailySpent, t.config.Daily, dayStart, 24*time.Hour)
+	} else if t.config.Hourly.IsPositive() && status.HourlySpent.GreaterThanOrEqual(t.config.Hourly) {
+		status.Window = WindowHourly
+		resumesAt = t.resumeTime(status.HourlySpent, t.config.Hourly, hourStart, time.Hour)
+	}
+	status.Exceeded = status.Window != ""
+	if status.Exceeded {
+		status.ResumesAt = &resumesAt
+	}
+
+	var handler func(Status)
+	if status.Exceeded != t.exceeded {
+		t.exceeded = status.Exceeded
+		handler = t.onChange
+	}
+	return status, handler
+}
+
+// resumeTime 窗口内最早的支出依次滑出窗口，直到累计支出低于预算的时间
+func (t *Tracker) resumeTime(spent, budget decimal.Decimal, windowStart time.Time, window time.Duration) time.Time {
+	for _, s := range t.spends {
+		if !s.at.After(windowStart) {
+			continue
+		}
+		spent = spent.Sub(s.amount)
+		if spent.LessThan(budget) {
+			return s.at.Add(window)
+		}
+	}
+	return windowStart.Add(window)
+}
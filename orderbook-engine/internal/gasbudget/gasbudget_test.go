@@ -0,0 +1,74 @@
+package gasbudget
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// gwei20 20 gwei
+var gwei20 = big.NewInt(20_000_000_000)
+
+func TestTrackerHourlyBudget(t *testing.T) {
+	tracker := NewTracker(1, Config{Hourly: decimal.RequireFromString("0.01"), Action: ActionPause})
+
+	var changes []Status
+	tracker.SetChangeHandler(func(status Status) { changes = append(changes, status) })
+
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	// 300000 gas × 20 gwei = 0.006 ETH
+	status := tracker.Record(start, 300000, gwei20)
+	assert.False(t, status.Exceeded)
+	assert.True(t, status.HourlySpent.Equal(decimal.RequireFromString("0.006")))
+
+	status = tracker.Record(start.Add(10*time.Minute), 300000, gwei20)
+	require.True(t, status.Exceeded)
+	assert.Equal(t, WindowHourly, status.Window)
+	// 第一笔滑出窗口后回到预算内
+	assert.Equal(t, start.Add(time.Hour), *status.ResumesAt)
+	require.Len(t, changes, 1)
+	assert.True(t, changes[0].Exceeded)
+
+	// 重复检查不重复触发回调
+	tracker.Check(start.Add(30 * time.Minute))
+	assert.Len(t, changes, 1)
+
+	status = tracker.Check(start.Add(time.Hour + time.Second))
+	assert.False(t, status.Exceeded)
+	require.Len(t, changes, 2)
+	assert.False(t, changes[1].Exceeded)
+}
+
+func TestTrackerDailyBudget(t *testing.T) {
+	tracker := NewTracker(1, Config{Hourly: decimal.RequireFromString("1"), Daily: decimal.RequireFromString("0.01"), Action: ActionSlow})
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 4; i++ {
+		tracker.Record(start.Add(time.Duration(i)*2*time.Hour), 150000, gwei20) // 每笔 0.003 ETH
+	}
+
+	status := tracker.Check(start.Add(7 * time.Hour))
+	require.True(t, status.Exceeded)
+	assert.Equal(t, WindowDaily, status.Window)
+	assert.Equal(t, ActionSlow, status.Action)
+	assert.Equal(t, 4, status.Transactions)
+	assert.Equal(t, start.Add(24*time.Hour), *status.ResumesAt)
+
+	// 超过一天的支出被清理
+	status = tracker.Check(start.Add(24*time.Hour + time.Minute))
+	assert.False(t, status.Exceeded)
+	assert.Equal(t, 3, status.Transactions)
+}
+
+func TestParseAction(t *testing.T) {
+	action, err := ParseAction("slow")
+	require.NoError(t, err)
+	assert.Equal(t, ActionSlow, action)
+
+	_, err = ParseAction("stop")
+	assert.Error(t, err)
+}
@@ -0,0 +1,111 @@
+// Package halt 交易所紧急停机（只读模式）
+// 停机期间暂停全部撮合与链上结算，行情、订单查询与撤单照常提供；
+// 停机与恢复时依次执行注册的动作（撮合引擎、结算管理器等），并通过状态回调推送 system.status
+package halt
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"orderbook-engine/internal/types"
+)
+
+// ErrHalted 交易所紧急停机中，拒绝写操作
+var ErrHalted = errors.New("exchange is halted, read-only mode")
+
+// Status 停机状态
+type Status struct {
+	Halted bool       `json:"halted"`
+	Reason string     `json:"reason,omitempty"`
+	Actor  string     `json:"actor,omitempty"` // 触发方：config 或管理员地址
+	Since  *time.Time `json:"since,omitempty"`
+}
+
+// Switch 紧急停机开关
+type Switch struct {
+	mu       sync.Mutex
+	status   Status
+	actions  []func(halted bool)
+	onChange func(update *types.SystemStatusUpdate)
+	logger   *logrus.Logger
+}
+
+// NewSwitch 创建紧急停机开关，初始为正常运行
+func NewSwitch(logger *logrus.Logger) *Switch {
+	return &Switch{logger: logger}
+}
+
+// AddAction 注册停机与恢复时执行的动作，需在切换前注册
+func (s *Switch) AddAction(action func(halted bool)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.actions = append(s.actions, action)
+}
+
+// SetStatusHandler 设置系统状态变化回调（用于WebSocket通知）
+func (s *Switch) SetStatusHandler(handler func(update *types.SystemStatusUpdate)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onChange = handler
+}
+
+// Halt 进入紧急停机，已停机时返回 false
+func (s *Switch) Halt(reason, actor string) bool {
+	return s.set(true, reason, actor)
+}
+
+// Resume 恢复运行，未停机时返回 false
+func (s *Switch) Resume(actor string) bool {
+	return s.set(false, "", actor)
+}
+
+// Halted 是否处于紧急停机
+func (s *Switch) Halted() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.status.Halted
+}
+
+// GetStatus 获取停机状态
+func (s *Switch) GetStatus() Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.status
+}
+
+// set 切换停机状态，动作按注册顺序在锁内执行，保证并发切换时各组件状态一致
+func (s *Switch) set(halted bool, reason, actor string) bool {
+	s.mu.Lock()
+	if s.status.Halted == halted {
+		s.mu.Unlock()
+		return false
+	}
+
+	now := time.Now()
+	if halted {
+		s.status = Status{Halted: true, Reason: reason, Actor: actor, Since: &now}
+	} else {
+		s.status = Status{}
+	}
+	for _, action := range s.actions {
+		action(halted)
+	}
+	handler := s.onChange
+	s.mu.Unlock()
+
+	update := &types.SystemStatusUpdate{Status: types.SystemStatusNormal, Reason: "resumed by " + actor, Timestamp: now}
+	if halted {
+		s.logger.WithFields(logrus.Fields{"reason": reason, "actor": actor}).Warn("🛑 Exchange halted, read-only mode")
+		update = &types.SystemStatusUpdate{Status: types.SystemStatusHalted, Reason: reason, Timestamp: now}
+	} else {
+		s.logger.WithField("actor", actor).Info("Exchange resumed")
+	}
+
+	if handler != nil {
+		handler(update)
+	}
+	return true
+}
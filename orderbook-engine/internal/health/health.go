@@ -0,0 +1,148 @@
+// Package health 依赖健康检查
+// 并发探测各依赖组件（存储、链上RPC、结算队列、WebSocket、撮合事件积压），汇总为整体结论供负载均衡判断：
+// 关键组件不可用时整体为 unhealthy，其余组件异常或指标超过阈值时为 degraded
+package health
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Status 健康状态
+type Status string
+
+const (
+	StatusHealthy   Status = "healthy"
+	StatusDegraded  Status = "degraded"
+	StatusUnhealthy Status = "unhealthy"
+)
+
+// Result 单个组件的探测结果
+type Result struct {
+	Status  Status                 `json:"status"`
+	Error   string                 `json:"error,omitempty"`
+	Details map[string]interface{} `json:"details,omitempty"`
+}
+
+// CheckFunc 组件探测函数，需在 ctx 结束前返回
+type CheckFunc func(ctx context.Context) Result
+
+// Component 组件检查结果
+type Component struct {
+	Name     string `json:"name"`
+	Critical bool   `json:"critical"`
+	Result
+	Latency string `json:"latency"`
+}
+
+// Report 健康检查报告
+type Report struct {
+	Status     Status      `json:"status"`
+	CheckedAt  time.Time   `json:"checked_at"`
+	Components []Component `json:"components"`
+}
+
+// check 已注册的组件检查
+type check struct {
+	name     string
+	critical bool
+	run      CheckFunc
+}
+
+// Checker 健康检查器，在缓存有效期内复用上次报告，避免负载均衡频繁探测压垮依赖
+type Checker struct {
+	mu       sync.Mutex
+	checks   []check
+	timeout  time.Duration // 单次检查的总时限，超时的组件视为不可用
+	cacheTTL time.Duration
+	last     *Report
+}
+
+// NewChecker 创建健康检查器
+func NewChecker(timeout, cacheTTL time.Duration) *Checker {
+	if timeout <= 0 {
+		timeout = 2 * time.Second
+	}
+	return &Checker{timeout: timeout, cacheTTL: cacheTTL}
+}
+
+// Add 注册组件检查，critical 组件不可用时整体为 unhealthy
+func (c *Checker) Add(name string, critical bool, run CheckFunc) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.checks = append(c.checks, check{name: name, critical: critical, run: run})
+}
+
+// Check 执行全部组件检查（或返回缓存期内的上次报告）
+func (c *Checker) Check(ctx context.Context) *Report {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if c.last != nil && now.Sub(c.last.CheckedAt) < c.cacheTTL {
+		return c.last
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	components := make([]Component, len(c.checks))
+	var wg sync.WaitGroup
+	for i, chk := range c.checks {
+		wg.Add(1)
+		go func(i int, chk check) {
+			defer wg.Done()
+			components[i] = runCheck(ctx, chk)
+		}(i, chk)
+	}
+	wg.Wait()
+
+	sort.SliceStable(components, func(i, j int) bool { return components[i].Name < components[j].Name })
+	c.last = &Report{Status: Overall(components), CheckedAt: now, Components: components}
+	return c.last
+}
+
+// runCheck 执行单个检查，超时返回 unhealthy
+func runCheck(ctx context.Context, chk check) Component {
+	start := time.Now()
+	done := make(chan Result, 1)
+	go func() { done <- chk.run(ctx) }()
+
+	var result Result
+	select {
+	case result = <-done:
+	case <-ctx.Done():
+		result = Result{Status: StatusUnhealthy, Error: "check timed out"}
+	}
+	return Component{Name: chk.name, Critical: chk.critical, Result: result, Latency: time.Since(start).String()}
+}
+
+// Overall 汇总整体结论：关键组件不可用为 unhealthy，任一组件异常为 degraded
+func Overall(components []Component) Status {
+	status := StatusHealthy
+	for _, component := range components {
+		switch {
+		case component.Status == StatusHealthy:
+		case component.Critical && component.Status == StatusUnhealthy:
+			return StatusUnhealthy
+		default:
+			status = StatusDegraded
+		}
+	}
+	return status
+}
+
+// Error 探测出错的结果
+func Error(err error, details map[string]interface{}) Result {
+	return Result{Status: StatusUnhealthy, Error: err.Error(), Details: details}
+}
+
+// Threshold 按阈值判断指标，limit 不大于 0 表示不限
+func Threshold(value, limit int64, details map[string]interface{}) Result {
+	if limit > 0 && value > limit {
+		return Result{Status: StatusDegraded, Details: details}
+	}
+	return Result{Status: StatusHealthy, Details: details}
+}
@@ -0,0 +1,71 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func healthy(ctx context.Context) Result { return Result{Status: StatusHealthy} }
+
+func TestCheckerVerdict(t *testing.T) {
+	checker := NewChecker(50*time.Millisecond, 0)
+	checker.Add("storage", true, healthy)
+	checker.Add("settlement", false, func(ctx context.Context) Result {
+		return Threshold(900, 500, map[string]interface{}{"pending": 900})
+	})
+
+	report := checker.Check(context.Background())
+	assert.Equal(t, StatusDegraded, report.Status, "非关键组件超过阈值只降级")
+	require.Len(t, report.Components, 2)
+	assert.Equal(t, "settlement", report.Components[0].Name)
+	assert.Equal(t, StatusDegraded, report.Components[0].Status)
+
+	// 关键组件超时视为不可用
+	checker.Add("events", true, func(ctx context.Context) Result {
+		<-ctx.Done()
+		time.Sleep(10 * time.Millisecond)
+		return Error(errors.New("late"), nil)
+	})
+	report = checker.Check(context.Background())
+	assert.Equal(t, StatusUnhealthy, report.Status)
+	assert.Equal(t, "check timed out", report.Components[0].Error)
+}
+
+func TestCheckerCachesReport(t *testing.T) {
+	calls := 0
+	checker := NewChecker(time.Second, time.Minute)
+	checker.Add("storage", true, func(ctx context.Context) Result {
+		calls++
+		return Error(errors.New("connection refused"), nil)
+	})
+
+	first := checker.Check(context.Background())
+	second := checker.Check(context.Background())
+	assert.Equal(t, 1, calls)
+	assert.Same(t, first, second)
+	assert.Equal(t, StatusUnhealthy, first.Status)
+}
+
+func TestReadinessGatesStartup(t *testing.T) {
+	readiness := NewReadiness()
+	readiness.Require("orderbook_recovery")
+	readiness.Require("settlement.1")
+	readiness.Done("orderbook_recovery")
+
+	status := readiness.Status()
+	assert.False(t, status.Ready)
+	assert.Equal(t, []string{"settlement.1"}, status.Pending)
+
+	readiness.Done("settlement.1")
+	require.True(t, readiness.Ready())
+
+	// 就绪后不再回退
+	readiness.Require("blockchain.1")
+	assert.True(t, readiness.Ready())
+	assert.NotNil(t, readiness.Status().ReadyAt)
+}
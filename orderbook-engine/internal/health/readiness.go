@@ -0,0 +1,89 @@
+package health
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// ReadinessStatus 启动就绪状态
+type ReadinessStatus struct {
+	Ready     bool       `json:"ready"`
+	Pending   []string   `json:"pending"`
+	Completed []string   `json:"completed"`
+	ReadyAt   *time.Time `json:"ready_at,omitempty"`
+}
+
+// Readiness 启动就绪门控
+// 启动时登记需要完成的条件（订单簿恢复、链上订阅、结算管理器启动等），全部完成后才就绪；就绪后不再回退
+// 全部条件需在开始对外提供服务前登记
+type Readiness struct {
+	mu        sync.Mutex
+	pending   map[string]bool
+	completed map[string]bool
+	readyAt   *time.Time
+}
+
+// NewReadiness 创建就绪门控
+func NewReadiness() *Readiness {
+	return &Readiness{pending: make(map[string]bool), completed: make(map[string]bool)}
+}
+
+// Require 登记启动条件，需在条件完成前登记；已就绪后登记的条件被忽略
+func (r *Readiness) Require(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.readyAt != nil || r.completed[name] {
+		return
+	}
+	r.pending[name] = true
+}
+
+// Done 标记启动条件完成，最后一个条件完成时进入就绪
+func (r *Readiness) Done(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.pending[name] {
+		return
+	}
+	delete(r.pending, name)
+	r.completed[name] = true
+}
+
+// Ready 是否已就绪：全部已登记条件完成
+func (r *Readiness) Ready() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.checkLocked()
+}
+
+// Status 获取就绪状态
+func (r *Readiness) Status() ReadinessStatus {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return ReadinessStatus{
+		Ready:     r.checkLocked(),
+		Pending:   sortedKeys(r.pending),
+		Completed: sortedKeys(r.completed),
+		ReadyAt:   r.readyAt,
+	}
+}
+
+// checkLocked 判断并记录就绪时间（调用方持有锁）
+func (r *Readiness) checkLocked() bool {
+	if r.readyAt == nil && len(r.pending) == 0 {
+		now := time.Now()
+		r.readyAt = &now
+	}
+	return r.readyAt != nil
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
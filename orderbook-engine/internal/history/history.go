@@ -0,0 +1,138 @@
+// Package history 订单与成交历史查询
+// 按创建时间倒序、以 (created_at, id) 作为游标分页，数据持续写入时翻页结果仍然稳定
+package history
+
+import (
+	"encoding/base64"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"orderbook-engine/internal/types"
+)
+
+// MaxLimit 单页最大条数
+const MaxLimit = 500
+
+// ErrInvalidCursor 游标格式错误
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor 分页游标，指向上一页最后一条记录
+type Cursor struct {
+	CreatedAt time.Time
+	ID        uuid.UUID
+}
+
+// Encode 编码为不透明字符串
+func (c Cursor) Encode() string {
+	raw := strconv.FormatInt(c.CreatedAt.UnixNano(), 10) + ":" + c.ID.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodeCursor 解析游标，空字符串表示第一页
+func DecodeCursor(value string) (*Cursor, error) {
+	if value == "" {
+		return nil, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	parts := strings.SplitN(string(raw), ":", 2)
+	if len(parts) != 2 {
+		return nil, ErrInvalidCursor
+	}
+	nanos, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	id, err := uuid.Parse(parts[1])
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return &Cursor{CreatedAt: time.Unix(0, nanos).UTC(), ID: id}, nil
+}
+
+// OrderQuery 订单历史查询条件
+type OrderQuery struct {
+	UserAddress string
+	TradingPair string
+	Status      string
+	From        time.Time // 含，零值表示不限
+	To          time.Time // 不含，零值表示不限
+	After       *Cursor   // 上一页游标
+	Limit       int
+}
+
+// Role 用户在成交中的角色
+type Role string
+
+const (
+	RoleTaker Role = "taker"
+	RoleMaker Role = "maker"
+)
+
+// FillQuery 成交历史查询条件
+type FillQuery struct {
+	UserAddress string          // 为空时查询全部用户
+	TradingPair string
+	Side        types.OrderSide // 指定用户时为用户订单方向，否则为 taker 方向
+	Role        Role            // 需指定用户
+	From        time.Time
+	To          time.Time
+	After       *Cursor
+	Limit       int
+}
+
+// Store 历史查询（存储实现可选支持）
+type Store interface {
+	QueryOrders(query OrderQuery) ([]*types.Order, error)
+	QueryFills(query FillQuery) ([]*types.Fill, error)
+}
+
+// InRange 时间是否位于 [from, to) 内
+func InRange(t, from, to time.Time) bool {
+	if !from.IsZero() && t.Before(from) {
+		return false
+	}
+	if !to.IsZero() && !t.Before(to) {
+		return false
+	}
+	return true
+}
+
+// Allows 记录是否属于游标之后的页（时间倒序、同一时间按ID倒序）
+func (c *Cursor) Allows(createdAt time.Time, id uuid.UUID) bool {
+	if c == nil {
+		return true
+	}
+	if !createdAt.Equal(c.CreatedAt) {
+		return createdAt.Before(c.CreatedAt)
+	}
+	return strings.Compare(id.String(), c.ID.String()) < 0
+}
+
+// SortOrders 按 (created_at, id) 倒序排序
+func SortOrders(orders []*types.Order) {
+	sort.Slice(orders, func(i, j int) bool {
+		return newer(orders[i].CreatedAt, orders[i].ID, orders[j].CreatedAt, orders[j].ID)
+	})
+}
+
+// SortFills 按 (created_at, id) 倒序排序
+func SortFills(fills []*types.Fill) {
+	sort.Slice(fills, func(i, j int) bool {
+		return newer(fills[i].CreatedAt, fills[i].ID, fills[j].CreatedAt, fills[j].ID)
+	})
+}
+
+func newer(aTime time.Time, aID uuid.UUID, bTime time.Time, bID uuid.UUID) bool {
+	if !aTime.Equal(bTime) {
+		return aTime.After(bTime)
+	}
+	return strings.Compare(aID.String(), bID.String()) > 0
+}
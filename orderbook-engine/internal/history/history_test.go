@@ -0,0 +1,53 @@
+package history
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"orderbook-engine/internal/types"
+)
+
+func TestCursorPaginationIsStable(t *testing.T) {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	fills := make([]*types.Fill, 0, 5)
+	for i := 0; i < 5; i++ {
+		// 两两同一时间，同一时间内按ID排序
+		fills = append(fills, &types.Fill{ID: uuid.New(), CreatedAt: base.Add(time.Duration(i/2) * time.Second)})
+	}
+	SortFills(fills)
+
+	// 每页2条，逐页用游标翻页
+	var cursor *Cursor
+	var seen []uuid.UUID
+	for {
+		var page []*types.Fill
+		for _, fill := range fills {
+			if len(page) < 2 && cursor.Allows(fill.CreatedAt, fill.ID) {
+				page = append(page, fill)
+			}
+		}
+		if len(page) == 0 {
+			break
+		}
+		for _, fill := range page {
+			seen = append(seen, fill.ID)
+		}
+
+		last := page[len(page)-1]
+		decoded, err := DecodeCursor(Cursor{CreatedAt: last.CreatedAt, ID: last.ID}.Encode())
+		require.NoError(t, err)
+		cursor = decoded
+	}
+
+	require.Len(t, seen, len(fills))
+	for i, fill := range fills {
+		assert.Equal(t, fill.ID, seen[i])
+	}
+
+	_, err := DecodeCursor("not-a-cursor")
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+}
@@ -0,0 +1,190 @@
+package history
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	_ "github.com/lib/pq"
+
+	"orderbook-engine/internal/types"
+)
+
+// historyIndexes 时间倒序查询使用的复合索引（表结构与 types.Order / types.Fill 的 gorm 定义一致）
+var historyIndexes = []string{
+	`CREATE INDEX IF NOT EXISTS idx_orders_user_created ON orders (user_address, created_at DESC, id DESC)`,
+	`CREATE INDEX IF NOT EXISTS idx_orders_pair_created ON orders (trading_pair, created_at DESC, id DESC)`,
+	`CREATE INDEX IF NOT EXISTS idx_fills_created ON fills (created_at DESC, id DESC)`,
+	`CREATE INDEX IF NOT EXISTS idx_fills_pair_created ON fills (trading_pair, created_at DESC, id DESC)`,
+}
+
+const orderColumns = `id, user_address, trading_pair, chain_id, base_token, quote_token, side, type, price, amount,
+	filled_amount, avg_fill_price, quote_filled, fee_paid, status, expires_at, nonce, signature, hash, created_at, updated_at`
+
+const fillColumns = `id, taker_order_id, maker_order_id, taker_user_address, maker_user_address, trading_pair, price,
+	amount, taker_side, tx_hash, settlement_status, created_at`
+
+// PostgresStore 基于 PostgreSQL 的历史查询
+type PostgresStore struct {
+	db *sql.DB
+}
+
+// NewPostgresStore 创建 PostgreSQL 历史查询并确保索引存在
+func NewPostgresStore(dsn string) (*PostgresStore, error) {
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history database: %w", err)
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to connect to history database: %w", err)
+	}
+
+	for _, stmt := range historyIndexes {
+		if _, err := db.Exec(stmt); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("failed to create history index: %w", err)
+		}
+	}
+	return &PostgresStore{db: db}, nil
+}
+
+// Close 关闭数据库连接
+func (s *PostgresStore) Close() error {
+	return s.db.Close()
+}
+
+// QueryOrders 查询订单历史
+func (s *PostgresStore) QueryOrders(query OrderQuery) ([]*types.Order, error) {
+	where := newConditions()
+	where.add("user_address = ?", query.UserAddress)
+	if query.TradingPair != "" {
+		where.add("trading_pair = ?", query.TradingPair)
+	}
+	if query.Status != "" {
+		where.add("status = ?", query.Status)
+	}
+	where.addRange("created_at", query.From, query.To)
+	where.addCursor(query.After)
+
+	rows, err := s.db.Query(
+		"SELECT "+orderColumns+" FROM orders"+where.sql()+" ORDER BY created_at DESC, id DESC LIMIT "+where.next(),
+		append(where.args, query.Limit)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query orders: %w", err)
+	}
+	defer rows.Close()
+
+	orders := make([]*types.Order, 0, query.Limit)
+	for rows.Next() {
+		var order types.Order
+		var expiresAt sql.NullTime
+		if err := rows.Scan(&order.ID, &order.UserAddress, &order.TradingPair, &order.ChainID, &order.BaseToken,
+			&order.QuoteToken, &order.Side, &order.Type, &order.Price, &order.Amount, &order.FilledAmount,
+			&order.AvgFillPrice, &order.QuoteFilled, &order.FeePaid, &order.Status, &expiresAt, &order.Nonce, &order.Signature, &order.Hash, &order.CreatedAt, &order.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan order: %w", err)
+		}
+		if expiresAt.Valid {
+			order.ExpiresAt = &expiresAt.Time
+		}
+		orders = append(orders, &order)
+	}
+	return orders, rows.Err()
+}
+
+// QueryFills 查询成交历史，按用户查询时匹配其作为 taker 或 maker 的成交（可按角色和用户订单方向过滤）
+func (s *PostgresStore) QueryFills(query FillQuery) ([]*types.Fill, error) {
+	where := newConditions()
+	if query.UserAddress != "" {
+		// 用户订单：按角色限定为 taker 或 maker 订单，方向为用户订单的方向
+		orderIDs := "fills.taker_order_id, fills.maker_order_id"
+		switch query.Role {
+		case RoleTaker:
+			orderIDs = "fills.taker_order_id"
+		case RoleMaker:
+			orderIDs = "fills.maker_order_id"
+		}
+		if query.Side != "" {
+			where.add("EXISTS (SELECT 1 FROM orders o WHERE o.id IN ("+orderIDs+") AND o.user_address = ? AND o.side = ?)", query.UserAddress, query.Side)
+		} else {
+			where.add("EXISTS (SELECT 1 FROM orders o WHERE o.id IN ("+orderIDs+") AND o.user_address = ?)", query.UserAddress)
+		}
+	} else if query.Side != "" {
+		where.add("taker_side = ?", query.Side)
+	}
+	if query.TradingPair != "" {
+		where.add("trading_pair = ?", query.TradingPair)
+	}
+	where.addRange("created_at", query.From, query.To)
+	where.addCursor(query.After)
+
+	rows, err := s.db.Query(
+		"SELECT "+fillColumns+" FROM fills"+where.sql()+" ORDER BY created_at DESC, id DESC LIMIT "+where.next(),
+		append(where.args, query.Limit)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query fills: %w", err)
+	}
+	defer rows.Close()
+
+	fills := make([]*types.Fill, 0, query.Limit)
+	for rows.Next() {
+		var fill types.Fill
+		var takerAddress, makerAddress, txHash, settlementStatus sql.NullString
+		if err := rows.Scan(&fill.ID, &fill.TakerOrderID, &fill.MakerOrderID, &takerAddress, &makerAddress,
+			&fill.TradingPair, &fill.Price, &fill.Amount, &fill.TakerSide, &txHash, &settlementStatus, &fill.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan fill: %w", err)
+		}
+		fill.TakerUserAddress = takerAddress.String
+		fill.MakerUserAddress = makerAddress.String
+		fill.TxHash = txHash.String
+		fill.SettlementStatus = types.SettlementStatus(settlementStatus.String)
+		fills = append(fills, &fill)
+	}
+	return fills, rows.Err()
+}
+
+// conditions WHERE 子句构造，? 按顺序替换为 $n 占位符
+type conditions struct {
+	clauses []string
+	args    []interface{}
+}
+
+func newConditions() *conditions {
+	return &conditions{}
+}
+
+func (c *conditions) add(clause string, args ...interface{}) {
+	for _, arg := range args {
+		c.args = append(c.args, arg)
+		clause = strings.Replace(clause, "?", fmt.Sprintf("$%d", len(c.args)), 1)
+	}
+	c.clauses = append(c.clauses, clause)
+}
+
+func (c *conditions) addRange(column string, from, to time.Time) {
+	if !from.IsZero() {
+		c.add(column+" >= ?", from)
+	}
+	if !to.IsZero() {
+		c.add(column+" < ?", to)
+	}
+}
+
+func (c *conditions) addCursor(cursor *Cursor) {
+	if cursor != nil {
+		c.add("(created_at, id) < (?, ?)", cursor.CreatedAt, cursor.ID)
+	}
+}
+
+// next 下一个参数的占位符
+func (c *conditions) next() string {
+	return fmt.Sprintf("$%d", len(c.args)+1)
+}
+
+func (c *conditions) sql() string {
+	if len(c.clauses) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(c.clauses, " AND ")
+}
@@ -0,0 +1,367 @@
+// Package importer 历史数据导入
+// 从其他交易场所导出的 CSV/JSON 中导入历史成交和K线，校验并规范化后写入存储，便于迁移时保留图表和用户成交记录
+package importer
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+
+	"orderbook-engine/internal/storage"
+	"orderbook-engine/internal/types"
+)
+
+// maxReportedErrors 结果中最多返回的行错误数
+const maxReportedErrors = 100
+
+// importNamespace 导入记录ID的命名空间，同一来源重复导入得到相同ID
+var importNamespace = uuid.NewSHA1(uuid.NameSpaceOID, []byte("orderbook-engine/import"))
+
+// CandleIntervals 支持的K线周期
+var CandleIntervals = map[string]time.Duration{
+	"1m":  time.Minute,
+	"5m":  5 * time.Minute,
+	"15m": 15 * time.Minute,
+	"1h":  time.Hour,
+	"4h":  4 * time.Hour,
+	"1d":  24 * time.Hour,
+}
+
+// CandleStore K线存储（存储实现可选支持）
+type CandleStore interface {
+	SaveCandles(candles []*types.Candle) error
+	GetCandles(tradingPair, interval string, start, end time.Time, limit int) ([]*types.Candle, error)
+}
+
+// fillReader 按ID查询成交，用于重复导入检测（存储实现可选支持）
+type fillReader interface {
+	GetFill(fillID uuid.UUID) (*types.Fill, error)
+}
+
+// Options 导入选项
+type Options struct {
+	Source string // 数据来源标识，与原始ID一起生成稳定的记录ID
+	DryRun bool   // 仅校验不写入
+}
+
+// RowError 行校验错误
+type RowError struct {
+	Row   int    `json:"row"` // 从1开始的数据行号（不含CSV表头）
+	Error string `json:"error"`
+}
+
+// Result 导入结果
+type Result struct {
+	Kind     string     `json:"kind"`
+	Source   string     `json:"source"`
+	DryRun   bool       `json:"dry_run"`
+	Total    int        `json:"total"`
+	Imported int        `json:"imported"`
+	Skipped  int        `json:"skipped"` // 已导入过的重复记录
+	Failed   int        `json:"failed"`
+	Errors   []RowError `json:"errors,omitempty"`
+}
+
+func (r *Result) addError(row int, err error) {
+	r.Failed++
+	if len(r.Errors) < maxReportedErrors {
+		r.Errors = append(r.Errors, RowError{Row: row, Error: err.Error()})
+	}
+}
+
+// Importer 历史数据导入器
+type Importer struct {
+	store  storage.Storage
+	logger *logrus.Logger
+}
+
+// NewImporter 创建历史数据导入器
+func NewImporter(store storage.Storage, logger *logrus.Logger) *Importer {
+	return &Importer{
+		store:  store,
+		logger: logger,
+	}
+}
+
+// importedTrade 规范化后的历史成交
+type importedTrade struct {
+	fill   *types.Fill
+	orders []*types.Order // 有用户地址时生成的已成交订单，用于关联用户成交记录
+}
+
+// ImportTrades 导入历史成交
+// 字段：id, trading_pair, price, amount, side（taker方向）, timestamp，可选 taker_address, maker_address, tx_hash
+// 任一行校验失败时不写入任何数据
+func (i *Importer) ImportTrades(r io.Reader, format Format, opts Options) (*Result, error) {
+	if opts.Source == "" {
+		return nil, fmt.Errorf("source is required")
+	}
+
+	records, err := readRecords(r, format)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{Kind: "trades", Source: opts.Source, DryRun: opts.DryRun, Total: len(records)}
+	trades := make([]*importedTrade, 0, len(records))
+	seen := make(map[uuid.UUID]bool, len(records))
+	for idx, rec := range records {
+		trade, err := normalizeTrade(rec, opts.Source)
+		if err != nil {
+			result.addError(idx+1, err)
+			continue
+		}
+		if seen[trade.fill.ID] {
+			result.addError(idx+1, fmt.Errorf("duplicate id %q in file", rec["id"]))
+			continue
+		}
+		seen[trade.fill.ID] = true
+		trades = append(trades, trade)
+	}
+
+	if result.Failed > 0 || opts.DryRun {
+		return result, nil
+	}
+
+	reader, _ := i.store.(fillReader)
+	for _, trade := range trades {
+		if reader != nil {
+			if existing, err := reader.GetFill(trade.fill.ID); err == nil && existing != nil {
+				result.Skipped++
+				continue
+			}
+		}
+
+		for _, order := range trade.orders {
+			if err := i.store.CreateOrder(order); err != nil {
+				return result, fmt.Errorf("failed to save order for trade %s: %w", trade.fill.ID, err)
+			}
+		}
+		if err := i.store.CreateFill(trade.fill); err != nil {
+			return result, fmt.Errorf("failed to save trade %s: %w", trade.fill.ID, err)
+		}
+		result.Imported++
+	}
+
+	i.logger.WithFields(logrus.Fields{
+		"source":   opts.Source,
+		"imported": result.Imported,
+		"skipped":  result.Skipped,
+	}).Info("📥 Historical trades imported")
+
+	return result, nil
+}
+
+// ImportCandles 导入历史K线，相同交易对、周期和开始时间的K线会被覆盖
+// 字段：trading_pair, interval, open_time, open, high, low, close, volume
+// 任一行校验失败时不写入任何数据
+func (i *Importer) ImportCandles(r io.Reader, format Format, opts Options) (*Result, error) {
+	if opts.Source == "" {
+		return nil, fmt.Errorf("source is required")
+	}
+
+	candleStore, ok := i.store.(CandleStore)
+	if !ok && !opts.DryRun {
+		return nil, fmt.Errorf("storage does not support candles")
+	}
+
+	records, err := readRecords(r, format)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{Kind: "candles", Source: opts.Source, DryRun: opts.DryRun, Total: len(records)}
+	candles := make([]*types.Candle, 0, len(records))
+	for idx, rec := range records {
+		candle, err := normalizeCandle(rec)
+		if err != nil {
+			result.addError(idx+1, err)
+			continue
+		}
+		candles = append(candles, candle)
+	}
+
+	if result.Failed > 0 || opts.DryRun {
+		return result, nil
+	}
+
+	if err := candleStore.SaveCandles(candles); err != nil {
+		return result, fmt.Errorf("failed to save candles: %w", err)
+	}
+	result.Imported = len(candles)
+
+	i.logger.WithFields(logrus.Fields{
+		"source":   opts.Source,
+		"imported": result.Imported,
+	}).Info("📥 Historical candles imported")
+
+	return result, nil
+}
+
+// normalizeTrade 校验并规范化一行成交记录
+func normalizeTrade(rec record, source string) (*importedTrade, error) {
+	externalID, err := rec.required("id")
+	if err != nil {
+		return nil, err
+	}
+	tradingPair, err := normalizePair(rec["trading_pair"])
+	if err != nil {
+		return nil, err
+	}
+	price, err := rec.positiveDecimal("price")
+	if err != nil {
+		return nil, err
+	}
+	amount, err := rec.positiveDecimal("amount")
+	if err != nil {
+		return nil, err
+	}
+	timestamp, err := rec.timestamp("timestamp")
+	if err != nil {
+		return nil, err
+	}
+	if timestamp.After(time.Now()) {
+		return nil, fmt.Errorf("timestamp is in the future")
+	}
+
+	var takerSide types.OrderSide
+	switch strings.ToLower(rec["side"]) {
+	case string(types.OrderSideBuy):
+		takerSide = types.OrderSideBuy
+	case string(types.OrderSideSell):
+		takerSide = types.OrderSideSell
+	default:
+		return nil, fmt.Errorf("invalid side %q", rec["side"])
+	}
+	makerSide := types.OrderSideSell
+	if takerSide == types.OrderSideSell {
+		makerSide = types.OrderSideBuy
+	}
+
+	fillID := uuid.NewSHA1(importNamespace, []byte(source+"|trade|"+externalID))
+	trade := &importedTrade{
+		fill: &types.Fill{
+			ID:           fillID,
+			TradingPair:  tradingPair,
+			Price:        price,
+			Amount:       amount,
+			TakerSide:    takerSide,
+			TxHash:       rec["tx_hash"],
+			TransactTime: timestamp,
+			CreatedAt:    timestamp,
+		},
+	}
+
+	// 有用户地址时生成对应的已成交订单，使用户成交查询能关联到导入的记录
+	for _, party := range []struct {
+		field   string
+		side    types.OrderSide
+		id      *uuid.UUID
+		address *string
+	}{
+		{"taker_address", takerSide, &trade.fill.TakerOrderID, &trade.fill.TakerUserAddress},
+		{"maker_address", makerSide, &trade.fill.MakerOrderID, &trade.fill.MakerUserAddress},
+	} {
+		address := rec[party.field]
+		if address == "" {
+			continue
+		}
+		if !common.IsHexAddress(address) {
+			return nil, fmt.Errorf("invalid %s %q", party.field, address)
+		}
+
+		orderID := uuid.NewSHA1(importNamespace, []byte(fmt.Sprintf("%s|%s|%s", source, party.field, externalID)))
+		*party.id = orderID
+		*party.address = common.HexToAddress(address).Hex()
+		trade.orders = append(trade.orders, &types.Order{
+			ID:           orderID,
+			UserAddress:  common.HexToAddress(address).Hex(),
+			TradingPair:  tradingPair,
+			Side:         party.side,
+			Type:         types.OrderTypeLimit,
+			Price:        price,
+			Amount:       amount,
+			FilledAmount: amount,
+			AvgFillPrice: price,
+			QuoteFilled:  price.Mul(amount),
+			Status:       types.OrderStatusFilled,
+			Hash:         "import:" + orderID.String(),
+			CreatedAt:    timestamp,
+			UpdatedAt:    timestamp,
+		})
+	}
+
+	return trade, nil
+}
+
+// normalizeCandle 校验并规范化一行K线记录
+func normalizeCandle(rec record) (*types.Candle, error) {
+	tradingPair, err := normalizePair(rec["trading_pair"])
+	if err != nil {
+		return nil, err
+	}
+
+	interval := strings.ToLower(rec["interval"])
+	period, ok := CandleIntervals[interval]
+	if !ok {
+		return nil, fmt.Errorf("unsupported interval %q", rec["interval"])
+	}
+
+	openTime, err := rec.timestamp("open_time")
+	if err != nil {
+		return nil, err
+	}
+	if !openTime.Truncate(period).Equal(openTime) {
+		return nil, fmt.Errorf("open_time %s is not aligned to %s", openTime.Format(time.RFC3339), interval)
+	}
+
+	candle := &types.Candle{
+		TradingPair: tradingPair,
+		Interval:    interval,
+		OpenTime:    openTime,
+	}
+	if candle.Open, err = rec.positiveDecimal("open"); err != nil {
+		return nil, err
+	}
+	if candle.High, err = rec.positiveDecimal("high"); err != nil {
+		return nil, err
+	}
+	if candle.Low, err = rec.positiveDecimal("low"); err != nil {
+		return nil, err
+	}
+	if candle.Close, err = rec.positiveDecimal("close"); err != nil {
+		return nil, err
+	}
+
+	volume, err := rec.required("volume")
+	if err != nil {
+		return nil, err
+	}
+	if candle.Volume, err = decimal.NewFromString(volume); err != nil || candle.Volume.IsNegative() {
+		return nil, fmt.Errorf("invalid volume %q", volume)
+	}
+
+	if candle.High.LessThan(candle.Low) ||
+		candle.High.LessThan(candle.Open) || candle.High.LessThan(candle.Close) ||
+		candle.Low.GreaterThan(candle.Open) || candle.Low.GreaterThan(candle.Close) {
+		return nil, fmt.Errorf("inconsistent OHLC values")
+	}
+
+	return candle, nil
+}
+
+// normalizePair 规范化交易对为大写 BASE-QUOTE
+func normalizePair(value string) (string, error) {
+	pair := strings.ToUpper(strings.TrimSpace(value))
+	parts := strings.Split(pair, "-")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", fmt.Errorf("invalid trading_pair %q (expected BASE-QUOTE)", value)
+	}
+	return pair, nil
+}
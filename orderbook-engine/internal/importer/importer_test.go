@@ -0,0 +1,75 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"orderbook-engine/internal/types"
+)
+
+func TestNormalizeTradesFromCSV(t *testing.T) {
+	data := `ID,Trading_Pair,Price,Amount,Side,Timestamp,Taker_Address,Maker_Address
+t-1, weth-usdc ,2000.5,1.25,SELL,2024-01-02T03:04:05+08:00,0x70997970c51812dc3a010c7d01b50e0d17dc79c8,
+t-2,WETH-USDC,2001,0.5,buy,1704164645000,,`
+
+	records, err := readRecords(strings.NewReader(data), FormatCSV)
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+
+	trade, err := normalizeTrade(records[0], "venue-a")
+	require.NoError(t, err)
+	assert.Equal(t, "WETH-USDC", trade.fill.TradingPair)
+	assert.Equal(t, types.OrderSideSell, trade.fill.TakerSide)
+	assert.True(t, trade.fill.Price.Equal(decimal.RequireFromString("2000.5")))
+	assert.Equal(t, time.Date(2024, 1, 1, 19, 4, 5, 0, time.UTC), trade.fill.CreatedAt)
+
+	// 仅 taker 有地址时只生成 taker 订单，地址规范化为校验和格式
+	require.Len(t, trade.orders, 1)
+	assert.Equal(t, "0x70997970C51812dc3A010C7d01b50e0d17dc79C8", trade.orders[0].UserAddress)
+	assert.Equal(t, trade.orders[0].ID, trade.fill.TakerOrderID)
+	assert.Equal(t, types.OrderStatusFilled, trade.orders[0].Status)
+
+	// 同一来源同一ID生成稳定的记录ID，不同来源互不冲突
+	again, err := normalizeTrade(records[0], "venue-a")
+	require.NoError(t, err)
+	assert.Equal(t, trade.fill.ID, again.fill.ID)
+	other, err := normalizeTrade(records[0], "venue-b")
+	require.NoError(t, err)
+	assert.NotEqual(t, trade.fill.ID, other.fill.ID)
+
+	// 毫秒时间戳
+	trade, err = normalizeTrade(records[1], "venue-a")
+	require.NoError(t, err)
+	assert.Equal(t, time.UnixMilli(1704164645000).UTC(), trade.fill.CreatedAt)
+	assert.Empty(t, trade.orders)
+}
+
+func TestNormalizeCandlesRejectsInvalidRows(t *testing.T) {
+	data := `[
+		{"trading_pair": "WETH-USDC", "interval": "1h", "open_time": 1704164400, "open": "2000", "high": 2010, "low": 1995, "close": "2005", "volume": "12.5"},
+		{"trading_pair": "WETH-USDC", "interval": "1h", "open_time": 1704164460, "open": "2000", "high": 2010, "low": 1995, "close": "2005", "volume": "1"},
+		{"trading_pair": "WETH-USDC", "interval": "1h", "open_time": 1704164400, "open": "2000", "high": 1999, "low": 1995, "close": "2005", "volume": "1"},
+		{"trading_pair": "WETH-USDC", "interval": "2h", "open_time": 1704164400, "open": "2000", "high": 2010, "low": 1995, "close": "2005", "volume": "1"}
+	]`
+
+	records, err := readRecords(strings.NewReader(data), FormatJSON)
+	require.NoError(t, err)
+	require.Len(t, records, 4)
+
+	candle, err := normalizeCandle(records[0])
+	require.NoError(t, err)
+	assert.Equal(t, time.Unix(1704164400, 0).UTC(), candle.OpenTime)
+	assert.True(t, candle.Volume.Equal(decimal.RequireFromString("12.5")))
+
+	_, err = normalizeCandle(records[1])
+	assert.ErrorContains(t, err, "not aligned")
+	_, err = normalizeCandle(records[2])
+	assert.ErrorContains(t, err, "inconsistent OHLC")
+	_, err = normalizeCandle(records[3])
+	assert.ErrorContains(t, err, "unsupported interval")
+}
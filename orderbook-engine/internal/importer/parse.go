@@ -0,0 +1,157 @@
+package importer
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Format 导入文件格式
+type Format string
+
+const (
+	FormatCSV  Format = "csv"
+	FormatJSON Format = "json"
+)
+
+// ParseFormat 解析导入格式
+func ParseFormat(value string) (Format, error) {
+	switch Format(strings.ToLower(value)) {
+	case FormatCSV:
+		return FormatCSV, nil
+	case FormatJSON:
+		return FormatJSON, nil
+	default:
+		return "", fmt.Errorf("unsupported format %q (expected csv or json)", value)
+	}
+}
+
+// record 一行原始数据，字段名统一为小写
+type record map[string]string
+
+// readRecords 读取CSV（首行为表头）或JSON（对象数组）记录
+func readRecords(r io.Reader, format Format) ([]record, error) {
+	switch format {
+	case FormatCSV:
+		return readCSV(r)
+	case FormatJSON:
+		return readJSON(r)
+	default:
+		return nil, fmt.Errorf("unsupported format %q", format)
+	}
+}
+
+func readCSV(r io.Reader) ([]record, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+	for i := range header {
+		header[i] = strings.ToLower(strings.TrimSpace(header[i]))
+	}
+
+	var records []record
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CSV row %d: %w", len(records)+1, err)
+		}
+
+		rec := make(record, len(header))
+		for i, name := range header {
+			if i < len(row) {
+				rec[name] = strings.TrimSpace(row[i])
+			}
+		}
+		records = append(records, rec)
+	}
+	return records, nil
+}
+
+func readJSON(r io.Reader) ([]record, error) {
+	decoder := json.NewDecoder(r)
+	decoder.UseNumber()
+
+	var rows []map[string]interface{}
+	if err := decoder.Decode(&rows); err != nil {
+		return nil, fmt.Errorf("failed to decode JSON array: %w", err)
+	}
+
+	records := make([]record, 0, len(rows))
+	for i, row := range rows {
+		rec := make(record, len(row))
+		for name, value := range row {
+			switch v := value.(type) {
+			case nil:
+				rec[strings.ToLower(name)] = ""
+			case string:
+				rec[strings.ToLower(name)] = strings.TrimSpace(v)
+			case json.Number:
+				rec[strings.ToLower(name)] = v.String()
+			default:
+				return nil, fmt.Errorf("row %d: field %q must be a string or number", i+1, name)
+			}
+		}
+		records = append(records, rec)
+	}
+	return records, nil
+}
+
+// required 读取必填字段
+func (rec record) required(name string) (string, error) {
+	value := rec[name]
+	if value == "" {
+		return "", fmt.Errorf("missing %s", name)
+	}
+	return value, nil
+}
+
+// positiveDecimal 读取正数字段
+func (rec record) positiveDecimal(name string) (decimal.Decimal, error) {
+	value, err := rec.required(name)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	parsed, err := decimal.NewFromString(value)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("invalid %s %q", name, value)
+	}
+	if !parsed.IsPositive() {
+		return decimal.Zero, fmt.Errorf("%s must be positive", name)
+	}
+	return parsed, nil
+}
+
+// timestamp 读取时间字段，支持RFC3339和Unix秒/毫秒，统一转换为UTC
+func (rec record) timestamp(name string) (time.Time, error) {
+	value, err := rec.required(name)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if parsed, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return parsed.UTC(), nil
+	}
+
+	unix, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid %s %q (expected RFC3339 or unix timestamp)", name, value)
+	}
+	// 13位及以上视为毫秒
+	if unix >= 1e12 {
+		return time.UnixMilli(unix).UTC(), nil
+	}
+	return time.Unix(unix, 0).UTC(), nil
+}
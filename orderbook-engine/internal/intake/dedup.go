@@ -0,0 +1,180 @@
+// Package intake 订单入口去重
+// 同一用户意图可能既经 REST 下单、又作为链上 OrderPlaced 事件进入撮合引擎。两条入口的订单哈希不同（链上订单没有 EIP-712 签名与 nonce），
+// 因此按规范哈希（链、用户、代币、方向、类型、价格、数量）识别同一意图：窗口内另一入口已登记的相同意图视为重复，一对一抵消，
+// 同一入口内的相同意图（如 REST 以不同 nonce 重复下单）视为不同订单
+package intake
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"orderbook-engine/internal/types"
+)
+
+// ErrDuplicate 另一入口已提交相同意图的订单
+var ErrDuplicate = errors.New("duplicate order intent from another intake path")
+
+// Source 订单入口
+type Source string
+
+const (
+	SourceREST  Source = "rest"  // POST /api/v1/orders
+	SourceChain Source = "chain" // 链上 OrderPlaced 事件
+)
+
+// Stats 去重统计
+type Stats struct {
+	Window     string            `json:"window"`
+	Tracked    int               `json:"tracked"`    // 窗口内登记的意图数
+	Admitted   map[Source]uint64 `json:"admitted"`   // 各入口放行的订单数
+	Duplicates map[Source]uint64 `json:"duplicates"` // 各入口被判定为重复而拒绝的订单数
+}
+
+// entry 一个规范哈希下某一入口登记的订单
+type entry struct {
+	source Source
+	orders []uuid.UUID // 尚未被另一入口抵消的订单，按登记顺序
+	at     time.Time   // 最近一次登记时间
+}
+
+// Deduper 订单入口去重
+type Deduper struct {
+	mu         sync.Mutex
+	window     time.Duration
+	entries    map[string]*entry
+	admitted   map[Source]uint64
+	duplicates map[Source]uint64
+	logger     *logrus.Logger
+}
+
+// NewDeduper 创建去重器，window 为跨入口判定重复的时间窗口
+func NewDeduper(window time.Duration, logger *logrus.Logger) (*Deduper, error) {
+	if window <= 0 {
+		return nil, fmt.Errorf("dedup window must be positive")
+	}
+	return &Deduper{
+		window:     window,
+		entries:    make(map[string]*entry),
+		admitted:   make(map[Source]uint64),
+		duplicates: make(map[Source]uint64),
+		logger:     logger,
+	}, nil
+}
+
+// CanonicalHash 订单意图的规范哈希，地址不区分大小写，价格与数量去掉多余的零；市价单不含价格
+func CanonicalHash(order *types.Order) string {
+	price := ""
+	if order.Type != types.OrderTypeMarket {
+		price = order.Price.String()
+	}
+	fields := []string{
+		fmt.Sprint(order.ChainID),
+		strings.ToLower(order.UserAddress),
+		strings.ToLower(order.BaseToken),
+		strings.ToLower(order.QuoteToken),
+		string(order.Side),
+		string(order.Type),
+		price,
+		order.Amount.String(),
+	}
+	sum := sha256.Sum256([]byte(strings.Join(fields, "|")))
+	return hex.EncodeToString(sum[:])
+}
+
+// Admit 登记订单意图
+// 窗口内另一入口已登记相同意图且尚未被抵消时，抵消其中最早的一笔并返回该订单ID与 ErrDuplicate
+func (d *Deduper) Admit(order *types.Order, source Source) (uuid.UUID, error) {
+	hash := CanonicalHash(order)
+	now := time.Now()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.pruneUnsafe(now)
+
+	current := d.entries[hash]
+	if current != nil && current.source != source {
+		original := current.orders[0]
+		current.orders = current.orders[1:]
+		if len(current.orders) == 0 {
+			delete(d.entries, hash)
+		}
+		d.duplicates[source]++
+		d.logger.WithFields(logrus.Fields{
+			"order_id":    order.ID.String(),
+			"original_id": original.String(),
+			"source":      source,
+			"original":    current.source,
+			"user":        order.UserAddress,
+		}).Warn("Duplicate order intent rejected")
+		return original, ErrDuplicate
+	}
+
+	if current == nil {
+		current = &entry{source: source}
+		d.entries[hash] = current
+	}
+	current.orders = append(current.orders, order.ID)
+	current.at = now
+	d.admitted[source]++
+	return order.ID, nil
+}
+
+// Release 撤销登记，用于放行后未能进入订单簿的订单（被撮合引擎拒绝或保存失败）
+func (d *Deduper) Release(order *types.Order, source Source) {
+	hash := CanonicalHash(order)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	current := d.entries[hash]
+	if current == nil || current.source != source {
+		return
+	}
+	for i, id := range current.orders {
+		if id == order.ID {
+			current.orders = append(current.orders[:i], current.orders[i+1:]...)
+			break
+		}
+	}
+	if len(current.orders) == 0 {
+		delete(d.entries, hash)
+	}
+}
+
+// Stats 去重统计
+func (d *Deduper) Stats() *Stats {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.pruneUnsafe(time.Now())
+
+	stats := &Stats{
+		Window:     d.window.String(),
+		Tracked:    len(d.entries),
+		Admitted:   make(map[Source]uint64),
+		Duplicates: make(map[Source]uint64),
+	}
+	for source, count := range d.admitted {
+		stats.Admitted[source] = count
+	}
+	for source, count := range d.duplicates {
+		stats.Duplicates[source] = count
+	}
+	return stats
+}
+
+// pruneUnsafe 清理超出窗口的登记（不加锁版本）
+func (d *Deduper) pruneUnsafe(now time.Time) {
+	for hash, current := range d.entries {
+		if now.Sub(current.at) > d.window {
+			delete(d.entries, hash)
+		}
+	}
+}
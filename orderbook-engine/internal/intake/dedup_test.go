@@ -0,0 +1,93 @@
+package intake
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"orderbook-engine/internal/types"
+)
+
+func testOrder() *types.Order {
+	return &types.Order{
+		ID:          uuid.New(),
+		UserAddress: "0x1234567890123456789012345678901234567890",
+		TradingPair: "WETH-USDC",
+		ChainID:     31337,
+		BaseToken:   "0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2",
+		QuoteToken:  "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48",
+		Side:        types.OrderSideBuy,
+		Type:        types.OrderTypeLimit,
+		Price:       decimal.NewFromInt(2000),
+		Amount:      decimal.NewFromInt(1),
+	}
+}
+
+func TestCanonicalHashNormalizes(t *testing.T) {
+	a := testOrder()
+	b := testOrder()
+	b.BaseToken = "0xc02aaa39b223fe8d0a0e5c4f27ead9083c756cc2"
+	b.Price = decimal.RequireFromString("2000.000")
+	b.Amount = decimal.RequireFromString("1.0")
+	assert.Equal(t, CanonicalHash(a), CanonicalHash(b))
+
+	b.Amount = decimal.NewFromInt(2)
+	assert.NotEqual(t, CanonicalHash(a), CanonicalHash(b))
+}
+
+func TestAdmitRejectsCrossPathDuplicate(t *testing.T) {
+	deduper, err := NewDeduper(time.Minute, logrus.New())
+	require.NoError(t, err)
+
+	rest := testOrder()
+	_, err = deduper.Admit(rest, SourceREST)
+	require.NoError(t, err)
+
+	// 同一入口的相同意图是不同订单
+	again := testOrder()
+	_, err = deduper.Admit(again, SourceREST)
+	require.NoError(t, err)
+
+	// 链上的相同意图按登记顺序逐笔抵消
+	original, err := deduper.Admit(testOrder(), SourceChain)
+	assert.ErrorIs(t, err, ErrDuplicate)
+	assert.Equal(t, rest.ID, original)
+	original, err = deduper.Admit(testOrder(), SourceChain)
+	assert.ErrorIs(t, err, ErrDuplicate)
+	assert.Equal(t, again.ID, original)
+
+	// 全部抵消后，链上订单正常放行
+	_, err = deduper.Admit(testOrder(), SourceChain)
+	assert.NoError(t, err)
+
+	stats := deduper.Stats()
+	assert.Equal(t, uint64(2), stats.Admitted[SourceREST])
+	assert.Equal(t, uint64(1), stats.Admitted[SourceChain])
+	assert.Equal(t, uint64(2), stats.Duplicates[SourceChain])
+	assert.Equal(t, 1, stats.Tracked)
+}
+
+func TestReleaseAndWindow(t *testing.T) {
+	deduper, err := NewDeduper(time.Minute, logrus.New())
+	require.NoError(t, err)
+
+	rejected := testOrder()
+	_, err = deduper.Admit(rejected, SourceREST)
+	require.NoError(t, err)
+	deduper.Release(rejected, SourceREST)
+	_, err = deduper.Admit(testOrder(), SourceChain)
+	assert.NoError(t, err, "被拒绝的订单撤销登记后不再抵消")
+
+	// 超出窗口的登记被清理
+	deduper.entries[CanonicalHash(testOrder())].at = time.Now().Add(-2 * time.Minute)
+	_, err = deduper.Admit(testOrder(), SourceREST)
+	assert.NoError(t, err)
+
+	_, err = NewDeduper(0, logrus.New())
+	assert.Error(t, err)
+}
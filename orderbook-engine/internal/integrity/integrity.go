@@ -0,0 +1,282 @@
+// Package integrity 撮合引擎与存储的一致性检查
+// 交叉比对订单簿挂单、存储中的活跃订单、订单成交累计与内部余额锁定，报告不一致并可选修复：
+//   - 订单簿中有挂单但存储中没有记录：按引擎快照补写存储
+//   - 订单簿中有挂单但存储状态已终结：按存储状态将订单撤出订单簿（撤单事件释放余额锁定）
+//   - 存储中为活跃订单但不在订单簿中：将存储状态关闭为已撤销
+//   - 存储中的已成交数量与成交记录累计不符：按成交累计修正
+//   - 余额锁定没有对应的挂单：释放锁定
+//
+// 最近 Grace 时间内更新的订单与锁定可能处于下单流程中间（已落库尚未进入订单簿），不参与检查
+package integrity
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+
+	"orderbook-engine/internal/types"
+	"orderbook-engine/internal/wallet"
+)
+
+// 不一致类型
+const (
+	KindMissingInStore  = "book_order_missing_in_store"  // 挂单在存储中没有记录
+	KindInactiveInStore = "book_order_inactive_in_store" // 挂单在存储中已是终态
+	KindNotInBook       = "active_order_not_in_book"     // 存储中的活跃订单不在订单簿中
+	KindFilledMismatch  = "filled_amount_mismatch"       // 已成交数量与成交记录累计不符
+	KindOrphanLock      = "lock_without_order"           // 余额锁定没有对应的挂单
+)
+
+// Engine 撮合引擎
+type Engine interface {
+	OpenOrders() []*types.Order
+	CancelOrderWithReason(orderID uuid.UUID, tradingPair, reason string) (*types.Order, bool)
+}
+
+// Store 订单与成交存储
+type Store interface {
+	CreateOrder(order *types.Order) error
+	GetOrder(orderID uuid.UUID) (*types.Order, error)
+	UpdateOrder(order *types.Order) error
+	GetActiveOrders(tradingPair string) ([]*types.Order, error)
+	GetOrderFills(orderID uuid.UUID) ([]*types.Fill, error)
+}
+
+// Locks 内部余额的订单锁定
+type Locks interface {
+	GetOrderLocks() map[string]*wallet.OrderLock
+	ReleaseOrder(orderID uuid.UUID) bool
+}
+
+// Config 检查配置
+type Config struct {
+	Grace time.Duration // 最近更新的订单与锁定不参与检查
+}
+
+// Discrepancy 一处不一致
+type Discrepancy struct {
+	Kind        string    `json:"kind"`
+	OrderID     uuid.UUID `json:"order_id"`
+	TradingPair string    `json:"trading_pair,omitempty"`
+	UserAddress string    `json:"user_address,omitempty"`
+	Details     string    `json:"details"`
+	Repaired    bool      `json:"repaired"`
+	RepairError string    `json:"repair_error,omitempty"`
+}
+
+// Report 检查报告
+type Report struct {
+	CheckedAt     time.Time     `json:"checked_at"`
+	DryRun        bool          `json:"dry_run"`
+	BookOrders    int           `json:"book_orders"`
+	StoreOrders   int           `json:"store_active_orders"`
+	Locks         int           `json:"locks"`
+	Discrepancies []Discrepancy `json:"discrepancies"`
+	Repaired      int           `json:"repaired"`
+}
+
+// Checker 一致性检查器
+type Checker struct {
+	engine Engine
+	store  Store
+	locks  Locks // 为空时不检查余额锁定
+	config Config
+	logger *logrus.Logger
+
+	onDiscrepancies func(report *Report) // 可选，发现不一致时通知运维
+}
+
+// NewChecker 创建一致性检查器，locks 可以为空（未启用内部余额约束）
+func NewChecker(engine Engine, store Store, locks Locks, config Config, logger *logrus.Logger) *Checker {
+	if config.Grace <= 0 {
+		config.Grace = time.Minute
+	}
+	return &Checker{engine: engine, store: store, locks: locks, config: config, logger: logger}
+}
+
+// SetReportHandler 设置发现不一致时的回调（推送运维事件），需在首次 Run 之前设置
+func (c *Checker) SetReportHandler(handler func(report *Report)) {
+	c.onDiscrepancies = handler
+}
+
+// Run 执行一次检查，dryRun 为 false 时修复发现的不一致
+func (c *Checker) Run(dryRun bool, now time.Time) (*Report, error) {
+	report := &Report{CheckedAt: now, DryRun: dryRun, Discrepancies: []Discrepancy{}}
+	settled := now.Add(-c.config.Grace)
+
+	bookOrders := c.engine.OpenOrders()
+	storeOrders, err := c.store.GetActiveOrders("")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load active orders: %w", err)
+	}
+	report.BookOrders, report.StoreOrders = len(bookOrders), len(storeOrders)
+
+	inBook := make(map[uuid.UUID]*types.Order, len(bookOrders))
+	for _, order := range bookOrders {
+		inBook[order.ID] = order
+	}
+
+	// 需要核对成交累计的存储订单
+	checked := make(map[uuid.UUID]*types.Order)
+
+	for _, order := range bookOrders {
+		stored, err := c.store.GetOrder(order.ID)
+		if err != nil || stored == nil {
+			if order.CreatedAt.After(settled) {
+				continue
+			}
+			c.record(report, dryRun, Discrepancy{
+				Kind:    KindMissingInStore,
+				Details: "order resting in book has no storage record",
+			}, order, func() error {
+				return c.store.CreateOrder(order)
+			})
+			continue
+		}
+
+		if stored.IsActive() || stored.Status == types.OrderStatusPending {
+			checked[stored.ID] = stored
+			continue
+		}
+		if stored.UpdatedAt.After(settled) {
+			continue
+		}
+		c.record(report, dryRun, Discrepancy{
+			Kind:    KindInactiveInStore,
+			Details: fmt.Sprintf("order resting in book is %s in storage", stored.Status),
+		}, order, func() error {
+			if _, ok := c.engine.CancelOrderWithReason(order.ID, order.TradingPair, types.StatusReasonIntegrityRepair); !ok {
+				return fmt.Errorf("order no longer in book")
+			}
+			return nil
+		})
+	}
+
+	for _, stored := range storeOrders {
+		if _, exists := inBook[stored.ID]; exists {
+			continue
+		}
+		checked[stored.ID] = stored
+		if stored.UpdatedAt.After(settled) || stored.CreatedAt.After(settled) {
+			continue
+		}
+		c.record(report, dryRun, Discrepancy{
+			Kind:    KindNotInBook,
+			Details: fmt.Sprintf("order is %s in storage but not resting in book", stored.Status),
+		}, stored, func() error {
+			stored.Status = types.OrderStatusCancelled
+			stored.StatusReason = types.StatusReasonIntegrityRepair
+			stored.UpdatedAt = now
+			return c.store.UpdateOrder(stored)
+		})
+	}
+
+	for _, stored := range sortedOrders(checked) {
+		if stored.UpdatedAt.After(settled) {
+			continue
+		}
+		fills, err := c.store.GetOrderFills(stored.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load fills for order %s: %w", stored.ID, err)
+		}
+		total, quote := decimal.Zero, decimal.Zero
+		for _, fill := range fills {
+			total = total.Add(fill.Amount)
+			quote = quote.Add(fill.Amount.Mul(fill.Price))
+		}
+		if total.Equal(stored.FilledAmount) {
+			continue
+		}
+		c.record(report, dryRun, Discrepancy{
+			Kind:    KindFilledMismatch,
+			Details: fmt.Sprintf("filled amount %s, fills total %s", stored.FilledAmount, total),
+		}, stored, func() error {
+			// 成交额与均价随成交数量一并按成交记录修复，手续费按撮合时的费率保留
+			stored.FilledAmount = total
+			stored.QuoteFilled = quote
+			stored.AvgFillPrice = decimal.Zero
+			if total.IsPositive() {
+				stored.AvgFillPrice = quote.Div(total)
+			}
+			stored.UpdatedAt = now
+			return c.store.UpdateOrder(stored)
+		})
+	}
+
+	if c.locks != nil {
+		locks := c.locks.GetOrderLocks()
+		report.Locks = len(locks)
+		keys := make([]string, 0, len(locks))
+		for key := range locks {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			lock := locks[key]
+			orderID, err := uuid.Parse(key)
+			if err != nil {
+				continue
+			}
+			if _, exists := inBook[orderID]; exists || lock.CreatedAt.After(settled) {
+				continue
+			}
+			c.record(report, dryRun, Discrepancy{
+				Kind:        KindOrphanLock,
+				OrderID:     orderID,
+				UserAddress: lock.UserAddress,
+				Details:     fmt.Sprintf("%s %s locked without a resting order", lock.Amount, lock.Token),
+			}, nil, func() error {
+				if !c.locks.ReleaseOrder(orderID) {
+					return fmt.Errorf("lock already released")
+				}
+				return nil
+			})
+		}
+	}
+
+	if len(report.Discrepancies) > 0 {
+		c.logger.WithFields(logrus.Fields{
+			"discrepancies": len(report.Discrepancies),
+			"repaired":      report.Repaired,
+			"dry_run":       dryRun,
+		}).Warn("Integrity check found discrepancies")
+		if c.onDiscrepancies != nil {
+			c.onDiscrepancies(report)
+		}
+	}
+	return report, nil
+}
+
+// record 记录不一致，非演练模式下执行修复
+func (c *Checker) record(report *Report, dryRun bool, discrepancy Discrepancy, order *types.Order, repair func() error) {
+	if order != nil {
+		discrepancy.OrderID = order.ID
+		discrepancy.TradingPair = order.TradingPair
+		discrepancy.UserAddress = order.UserAddress
+	}
+	if !dryRun {
+		if err := repair(); err != nil {
+			discrepancy.RepairError = err.Error()
+		} else {
+			discrepancy.Repaired = true
+			report.Repaired++
+		}
+	}
+	report.Discrepancies = append(report.Discrepancies, discrepancy)
+}
+
+// sortedOrders 按订单ID排序，保证报告顺序稳定
+func sortedOrders(orders map[uuid.UUID]*types.Order) []*types.Order {
+	result := make([]*types.Order, 0, len(orders))
+	for _, order := range orders {
+		result = append(result, order)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ID.String() < result[j].ID.String()
+	})
+	return result
+}
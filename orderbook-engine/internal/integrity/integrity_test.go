@@ -0,0 +1,151 @@
+package integrity
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"orderbook-engine/internal/matching"
+	"orderbook-engine/internal/types"
+	"orderbook-engine/internal/wallet"
+)
+
+// memoryStore 测试用存储
+type memoryStore struct {
+	orders map[uuid.UUID]*types.Order
+	fills  []*types.Fill
+}
+
+func (m *memoryStore) CreateOrder(order *types.Order) error {
+	copied := *order
+	m.orders[order.ID] = &copied
+	return nil
+}
+
+func (m *memoryStore) GetOrder(orderID uuid.UUID) (*types.Order, error) {
+	order, exists := m.orders[orderID]
+	if !exists {
+		return nil, fmt.Errorf("order not found")
+	}
+	return order, nil
+}
+
+func (m *memoryStore) UpdateOrder(order *types.Order) error {
+	m.orders[order.ID] = order
+	return nil
+}
+
+func (m *memoryStore) GetActiveOrders(tradingPair string) ([]*types.Order, error) {
+	var result []*types.Order
+	for _, order := range m.orders {
+		if order.IsActive() {
+			result = append(result, order)
+		}
+	}
+	return result, nil
+}
+
+func (m *memoryStore) GetOrderFills(orderID uuid.UUID) ([]*types.Fill, error) {
+	var result []*types.Fill
+	for _, fill := range m.fills {
+		if fill.TakerOrderID == orderID || fill.MakerOrderID == orderID {
+			result = append(result, fill)
+		}
+	}
+	return result, nil
+}
+
+func restingOrder(t *testing.T, engine *matching.MatchingEngine, price int64, at time.Time) *types.Order {
+	order := &types.Order{
+		ID:          uuid.New(),
+		UserAddress: "0xuser",
+		TradingPair: "WETH-USDC",
+		BaseToken:   "WETH",
+		QuoteToken:  "USDC",
+		Side:        types.OrderSideBuy,
+		Type:        types.OrderTypeLimit,
+		Price:       decimal.NewFromInt(price),
+		Amount:      decimal.NewFromInt(1),
+		CreatedAt:   at,
+		UpdatedAt:   at,
+	}
+	_, err := engine.AddOrder(order)
+	require.NoError(t, err)
+	return order
+}
+
+func TestCheckerReportsAndRepairs(t *testing.T) {
+	now := time.Now()
+	old := now.Add(-time.Hour)
+	engine := matching.NewMatchingEngine(logrus.New())
+	store := &memoryStore{orders: make(map[uuid.UUID]*types.Order)}
+
+	consistent := restingOrder(t, engine, 1000, old)
+	store.CreateOrder(consistent)
+
+	missing := restingOrder(t, engine, 1001, old)
+
+	closed := restingOrder(t, engine, 1002, old)
+	store.CreateOrder(closed)
+	store.orders[closed.ID].Status = types.OrderStatusCancelled
+
+	mismatched := restingOrder(t, engine, 1003, old)
+	store.CreateOrder(mismatched)
+	store.orders[mismatched.ID].FilledAmount = decimal.RequireFromString("0.5")
+
+	ghost := &types.Order{ID: uuid.New(), TradingPair: "WETH-USDC", Status: types.OrderStatusOpen, Amount: decimal.NewFromInt(1), CreatedAt: old, UpdatedAt: old}
+	store.orders[ghost.ID] = ghost
+
+	// 刚落库尚未进入订单簿的订单不参与检查
+	inflight := &types.Order{ID: uuid.New(), TradingPair: "WETH-USDC", Status: types.OrderStatusOpen, CreatedAt: now, UpdatedAt: now}
+	store.orders[inflight.ID] = inflight
+
+	balances := wallet.NewBalanceManager(logrus.New())
+	balances.SetBalance("0xuser", "USDC", decimal.NewFromInt(10000))
+	orphan := &types.Order{ID: uuid.New(), UserAddress: "0xuser", Side: types.OrderSideBuy, BaseToken: "WETH", QuoteToken: "USDC", Price: decimal.NewFromInt(100), Amount: decimal.NewFromInt(1)}
+	require.NoError(t, balances.LockOrder(orphan, orphan.Price))
+	balances.GetOrderLocks()[orphan.ID.String()].CreatedAt = old
+
+	checker := NewChecker(engine, store, balances, Config{Grace: time.Minute}, logrus.New())
+	kinds := func(report *Report) map[string]uuid.UUID {
+		result := make(map[string]uuid.UUID)
+		for _, d := range report.Discrepancies {
+			result[d.Kind] = d.OrderID
+		}
+		return result
+	}
+
+	// 演练不修改任何状态
+	report, err := checker.Run(true, now)
+	require.NoError(t, err)
+	assert.Equal(t, 4, report.BookOrders)
+	assert.Equal(t, map[string]uuid.UUID{
+		KindMissingInStore:  missing.ID,
+		KindInactiveInStore: closed.ID,
+		KindNotInBook:       ghost.ID,
+		KindFilledMismatch:  mismatched.ID,
+		KindOrphanLock:      orphan.ID,
+	}, kinds(report))
+	assert.Zero(t, report.Repaired)
+	assert.Equal(t, types.OrderStatusOpen, ghost.Status)
+
+	report, err = checker.Run(false, now)
+	require.NoError(t, err)
+	assert.Equal(t, 5, report.Repaired)
+	assert.Contains(t, store.orders, missing.ID)
+	assert.Equal(t, types.OrderStatusCancelled, ghost.Status)
+	assert.Equal(t, types.StatusReasonIntegrityRepair, ghost.StatusReason)
+	assert.True(t, store.orders[mismatched.ID].FilledAmount.IsZero())
+	assert.Empty(t, balances.GetOrderLocks())
+	assert.Len(t, engine.OpenOrders(), 3, "存储中已撤销的订单撤出订单簿")
+
+	report, err = checker.Run(true, now)
+	require.NoError(t, err)
+	assert.Empty(t, report.Discrepancies)
+}
@@ -0,0 +1,171 @@
+package leader
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"orderbook-engine/internal/drain"
+	"orderbook-engine/internal/eventlog"
+	"orderbook-engine/internal/matching"
+	"orderbook-engine/internal/types"
+)
+
+// 接管时订单簿的恢复来源
+const (
+	SourceEventLog = "event_log"
+	SourceSnapshot = "snapshot"
+	SourceEmpty    = "empty"
+)
+
+// errReplayIncomplete 事件日志包含重放无法重现的事件，重建的订单簿不可信
+var errReplayIncomplete = errors.New("event log cannot be fully replayed")
+
+// Handoff 主备状态交接
+// 主实例周期写入挂单快照，备用实例周期载入快照作为只读副本；
+// 接管时优先重放共享的事件日志重建订单簿（包含主实例失效前最后落盘的事件），
+// 未配置事件日志、或日志含有重放无法重现的事件（集合竞价撮合等）时使用最近的快照
+type Handoff struct {
+	mu           sync.Mutex
+	engine       *matching.MatchingEngine
+	snapshotPath string
+	eventLogPath string
+	logger       *logrus.Logger
+}
+
+// NewHandoff 创建主备状态交接，快照与事件日志需位于各实例共享的存储上
+func NewHandoff(engine *matching.MatchingEngine, snapshotPath, eventLogPath string, logger *logrus.Logger) *Handoff {
+	return &Handoff{
+		engine:       engine,
+		snapshotPath: snapshotPath,
+		eventLogPath: eventLogPath,
+		logger:       logger,
+	}
+}
+
+// Start 按周期同步：主实例写快照，备用实例载入快照
+func (h *Handoff) Start(interval time.Duration, isLeader func() bool) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			var err error
+			if isLeader() {
+				err = h.Publish()
+			} else {
+				err = h.Sync()
+			}
+			if err != nil {
+				h.logger.WithError(err).Error("Failed to sync order book snapshot")
+			}
+		}
+	}()
+}
+
+// Publish 写入当前挂单快照，尚未完成接管时不做任何操作
+func (h *Handoff) Publish() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.engine.Standby() {
+		return nil
+	}
+	return drain.WriteSnapshot(h.snapshotPath, h.engine.OpenOrders())
+}
+
+// Sync 备用实例载入主实例最近的快照，已接管时不做任何操作
+func (h *Handoff) Sync() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if !h.engine.Standby() {
+		return nil
+	}
+	snapshot, err := drain.ReadSnapshot(h.snapshotPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	h.engine.LoadOrders(snapshot.Orders)
+	return nil
+}
+
+// TakeOver 恢复主实例失效前的订单簿并退出备用模式，返回恢复来源与挂单数
+func (h *Handoff) TakeOver() (string, int, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	orders, source, err := h.recoverOrders()
+	if err != nil {
+		return "", 0, err
+	}
+	loaded := h.engine.LoadOrders(orders)
+	h.engine.SetStandby(false)
+
+	h.logger.WithFields(logrus.Fields{"source": source, "orders": loaded}).Warn("Order book recovered, accepting orders")
+	return source, loaded, nil
+}
+
+// recoverOrders 由事件日志或快照恢复挂单
+func (h *Handoff) recoverOrders() ([]*types.Order, string, error) {
+	if h.eventLogPath != "" {
+		orders, err := h.replayEventLog()
+		if err == nil {
+			return orders, SourceEventLog, nil
+		}
+		if errors.Is(err, errReplayIncomplete) {
+			h.logger.WithError(err).Warn("Recovering order book from snapshot instead of event log")
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return nil, "", err
+		}
+	}
+
+	snapshot, err := drain.ReadSnapshot(h.snapshotPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, SourceEmpty, nil
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	return snapshot.Orders, SourceSnapshot, nil
+}
+
+// replayEventLog 将事件日志重放到独立的引擎中，取重放后的挂单
+// 独立引擎没有集合竞价、价格带等状态，日志中有跳过的事件或重放成交与记录不一致时返回 errReplayIncomplete
+func (h *Handoff) replayEventLog() ([]*types.Order, error) {
+	file, err := os.Open(h.eventLogPath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	// 重放不还原订单过期时间，接管后按记录的过期时间继续过期
+	expiresAt := make(map[uuid.UUID]*time.Time)
+	replica := matching.NewMatchingEngine(h.logger)
+	result, err := eventlog.Replay(eventlog.NewReader(file), replica, eventlog.ReplayOptions{Compare: true}, func(entry *eventlog.Entry) {
+		if entry.Type == matching.EventOrderAdded && entry.Order != nil && entry.Order.ExpiresAt != nil {
+			expiresAt[entry.Order.ID] = entry.Order.ExpiresAt
+		}
+	}, nil)
+	if err != nil {
+		// 原主实例失效时最后一行可能不完整，使用此前完整的记录
+		h.logger.WithError(err).WithField("entries", result.Entries).Warn("Event log ends with an unreadable entry")
+	}
+	if result.Skipped > 0 || len(result.Mismatches) > 0 {
+		return nil, fmt.Errorf("%w: %d skipped events, %d fill mismatches", errReplayIncomplete, result.Skipped, len(result.Mismatches))
+	}
+
+	orders := replica.OpenOrders()
+	for _, order := range orders {
+		order.ExpiresAt = expiresAt[order.ID]
+	}
+	return orders, nil
+}
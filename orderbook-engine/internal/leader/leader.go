@@ -0,0 +1,204 @@
+// Package leader 多实例主备选举
+// 多个实例竞争同一租约，只有持有租约的主实例撮合与结算，备用实例同步主实例的订单簿副本提供只读行情，
+// 主实例失效（租约过期）后由备用实例接管
+package leader
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Lease 主实例租约
+type Lease interface {
+	// Acquire 租约空闲、已过期或已由 id 持有时获取（续约）租约，返回当前持有者
+	Acquire(ctx context.Context, id string, ttl time.Duration) (string, error)
+	// Release 由 id 持有时释放租约
+	Release(ctx context.Context, id string) error
+}
+
+// Config 选举配置
+type Config struct {
+	ID            string        // 实例标识
+	TTL           time.Duration // 租约有效期
+	RenewInterval time.Duration // 续约周期，应明显小于 TTL
+}
+
+// Status 选举状态
+type Status struct {
+	ID          string     `json:"id"`
+	Leader      bool       `json:"leader"`
+	Holder      string     `json:"holder,omitempty"` // 当前租约持有者
+	LeaderSince *time.Time `json:"leader_since,omitempty"`
+	LastRenewal *time.Time `json:"last_renewal,omitempty"`
+	LastError   string     `json:"last_error,omitempty"`
+}
+
+// Elector 主备选举器
+// 角色变化回调在选举协程中同步执行，接管耗时需小于租约有效期
+type Elector struct {
+	mu          sync.RWMutex
+	lease       Lease
+	config      Config
+	leader      bool
+	holder      string
+	leaderSince time.Time
+	lastRenewal time.Time
+	lastError   string
+	onElected   func()
+	onDemoted   func()
+	stopCh      chan struct{}
+	stopOnce    sync.Once
+	logger      *logrus.Logger
+}
+
+// NewElector 创建主备选举器
+func NewElector(lease Lease, config Config, logger *logrus.Logger) *Elector {
+	return &Elector{
+		lease:  lease,
+		config: config,
+		stopCh: make(chan struct{}),
+		logger: logger,
+	}
+}
+
+// OnElected 设置当选主实例回调
+func (e *Elector) OnElected(fn func()) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.onElected = fn
+}
+
+// OnDemoted 设置失去主实例身份回调
+func (e *Elector) OnDemoted(fn func()) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.onDemoted = fn
+}
+
+// Start 立即参选并按续约周期续约
+func (e *Elector) Start() {
+	go func() {
+		ticker := time.NewTicker(e.config.RenewInterval)
+		defer ticker.Stop()
+
+		for {
+			e.Campaign(time.Now())
+			select {
+			case <-e.stopCh:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop 停止参选，持有租约时主动释放以便备用实例立即接管
+func (e *Elector) Stop() {
+	e.stopOnce.Do(func() {
+		close(e.stopCh)
+	})
+
+	e.mu.Lock()
+	wasLeader := e.leader
+	e.leader = false
+	e.mu.Unlock()
+	if !wasLeader {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), e.config.RenewInterval)
+	defer cancel()
+	if err := e.lease.Release(ctx, e.config.ID); err != nil {
+		e.logger.WithError(err).Error("Failed to release leader lease")
+		return
+	}
+	e.logger.WithField("id", e.config.ID).Info("Leader lease released")
+}
+
+// Campaign 获取或续约租约并处理角色变化
+func (e *Elector) Campaign(now time.Time) {
+	ctx, cancel := context.WithTimeout(context.Background(), e.config.RenewInterval)
+	holder, err := e.lease.Acquire(ctx, e.config.ID, e.config.TTL)
+	cancel()
+
+	e.mu.Lock()
+	select {
+	case <-e.stopCh:
+		// 已停止参选，忽略停止前发出的续约结果
+		e.mu.Unlock()
+		return
+	default:
+	}
+
+	var callback func()
+	if err != nil {
+		e.lastError = err.Error()
+		// 无法续约时在租约可能被其他实例获取之前主动退位，避免同时存在两个主实例
+		if e.leader && now.Sub(e.lastRenewal) >= e.config.TTL-e.config.RenewInterval {
+			e.leader = false
+			callback = e.onDemoted
+		}
+	} else {
+		e.lastError = ""
+		e.holder = holder
+		switch {
+		case holder == e.config.ID:
+			e.lastRenewal = now
+			if !e.leader {
+				e.leader = true
+				e.leaderSince = now
+				callback = e.onElected
+			}
+		case e.leader:
+			e.leader = false
+			callback = e.onDemoted
+		}
+	}
+	leader := e.leader
+	e.mu.Unlock()
+
+	if err != nil {
+		e.logger.WithError(err).WithField("id", e.config.ID).Error("Failed to renew leader lease")
+	}
+	if callback == nil {
+		return
+	}
+	if leader {
+		e.logger.WithField("id", e.config.ID).Warn("👑 Elected as leader")
+	} else {
+		e.logger.WithFields(logrus.Fields{"id": e.config.ID, "holder": holder}).Warn("Lost leadership")
+	}
+	callback()
+}
+
+// IsLeader 当前实例是否为主实例
+func (e *Elector) IsLeader() bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.leader
+}
+
+// Status 获取选举状态
+func (e *Elector) Status() Status {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	status := Status{
+		ID:        e.config.ID,
+		Leader:    e.leader,
+		Holder:    e.holder,
+		LastError: e.lastError,
+	}
+	if e.leader {
+		since := e.leaderSince
+		status.LeaderSince = &since
+	}
+	if !e.lastRenewal.IsZero() {
+		renewal := e.lastRenewal
+		status.LastRenewal = &renewal
+	}
+	return status
+}
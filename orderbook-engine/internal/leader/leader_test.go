@@ -0,0 +1,152 @@
+package leader
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"orderbook-engine/internal/drain"
+	"orderbook-engine/internal/eventlog"
+	"orderbook-engine/internal/matching"
+	"orderbook-engine/internal/types"
+)
+
+func newOrder(side types.OrderSide) *types.Order {
+	return &types.Order{
+		ID:          uuid.New(),
+		UserAddress: "0x1234567890123456789012345678901234567890",
+		TradingPair: "WETH-USDC",
+		Side:        side,
+		Type:        types.OrderTypeLimit,
+		Price:       decimal.NewFromInt(2000),
+		Amount:      decimal.NewFromInt(1),
+		CreatedAt:   time.Now(),
+	}
+}
+
+func TestElectorFailover(t *testing.T) {
+	lease := NewMemoryLease()
+	config := Config{TTL: time.Minute, RenewInterval: time.Second}
+
+	config.ID = "a"
+	a := NewElector(lease, config, logrus.New())
+	config.ID = "b"
+	b := NewElector(lease, config, logrus.New())
+
+	elected := 0
+	b.OnElected(func() { elected++ })
+
+	a.Campaign(time.Now())
+	b.Campaign(time.Now())
+	assert.True(t, a.IsLeader())
+	assert.False(t, b.IsLeader())
+	assert.Equal(t, "a", b.Status().Holder)
+
+	// 主实例释放租约后备用实例在下一轮参选中接管
+	a.Stop()
+	b.Campaign(time.Now())
+	assert.True(t, b.IsLeader())
+	assert.Equal(t, 1, elected)
+}
+
+func TestHandoffTakeOver(t *testing.T) {
+	leaderEngine := matching.NewMatchingEngine(logrus.New())
+	resting := newOrder(types.OrderSideBuy)
+	_, err := leaderEngine.AddOrder(resting)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+	require.NoError(t, drain.WriteSnapshot(path, leaderEngine.OpenOrders()))
+
+	standby := matching.NewMatchingEngine(logrus.New())
+	standby.SetStandby(true)
+	handoff := NewHandoff(standby, path, "", logrus.New())
+
+	// 备用实例同步只读副本，拒绝新订单
+	require.NoError(t, handoff.Sync())
+	assert.Len(t, standby.OpenOrders(), 1)
+	_, err = standby.AddOrder(newOrder(types.OrderSideSell))
+	assert.ErrorIs(t, err, matching.ErrNotLeader)
+
+	source, loaded, err := handoff.TakeOver()
+	require.NoError(t, err)
+	assert.Equal(t, SourceSnapshot, source)
+	assert.Equal(t, 1, loaded)
+
+	// 接管后恢复的挂单可以继续撮合
+	fills, err := standby.AddOrder(newOrder(types.OrderSideSell))
+	require.NoError(t, err)
+	require.Len(t, fills, 1)
+	assert.Equal(t, resting.ID, fills[0].MakerOrderID)
+}
+
+// writeEventLog 将订阅到的全部事件写入事件日志
+func writeEventLog(t *testing.T, path string, sub *matching.Subscription) {
+	writer, err := eventlog.NewWriter(path, logrus.New())
+	require.NoError(t, err)
+	for len(sub.Events()) > 0 {
+		require.NoError(t, writer.Append(<-sub.Events()))
+	}
+	require.NoError(t, writer.Close())
+}
+
+func TestTakeOverReplaysEventLog(t *testing.T) {
+	leaderEngine := matching.NewMatchingEngine(logrus.New())
+	sub := leaderEngine.Subscribe(matching.SubscriptionOptions{Name: "eventlog"})
+	resting := newOrder(types.OrderSideBuy)
+	_, err := leaderEngine.AddOrder(resting)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "events.jsonl")
+	writeEventLog(t, logPath, sub)
+
+	standby := matching.NewMatchingEngine(logrus.New())
+	standby.SetStandby(true)
+	source, loaded, err := NewHandoff(standby, filepath.Join(dir, "snapshot.json"), logPath, logrus.New()).TakeOver()
+	require.NoError(t, err)
+	assert.Equal(t, SourceEventLog, source)
+	assert.Equal(t, 1, loaded)
+}
+
+func TestTakeOverAfterAuctionUsesSnapshot(t *testing.T) {
+	leaderEngine := matching.NewMatchingEngine(logrus.New())
+	sub := leaderEngine.Subscribe(matching.SubscriptionOptions{Name: "eventlog"})
+
+	// 集合竞价期间交叉的买卖单只挂单，竞价结束时统一成交
+	require.NoError(t, leaderEngine.StartAuction("WETH-USDC", "opening", time.Hour))
+	for _, side := range []types.OrderSide{types.OrderSideBuy, types.OrderSideSell} {
+		fills, err := leaderEngine.AddOrder(newOrder(side))
+		require.NoError(t, err)
+		assert.Empty(t, fills)
+	}
+	fills, ended := leaderEngine.EndAuction("WETH-USDC")
+	require.True(t, ended)
+	require.Len(t, fills, 1)
+
+	resting := newOrder(types.OrderSideBuy)
+	resting.Price = decimal.NewFromInt(1990)
+	_, err := leaderEngine.AddOrder(resting)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	logPath, snapshotPath := filepath.Join(dir, "events.jsonl"), filepath.Join(dir, "snapshot.json")
+	writeEventLog(t, logPath, sub)
+	require.NoError(t, drain.WriteSnapshot(snapshotPath, leaderEngine.OpenOrders()))
+
+	// 重放无法重现竞价撮合，改用快照，已成交的竞价订单不会重新挂出
+	standby := matching.NewMatchingEngine(logrus.New())
+	standby.SetStandby(true)
+	source, loaded, err := NewHandoff(standby, snapshotPath, logPath, logrus.New()).TakeOver()
+	require.NoError(t, err)
+	assert.Equal(t, SourceSnapshot, source)
+	assert.Equal(t, 1, loaded)
+	require.Len(t, standby.OpenOrders(), 1)
+	assert.Equal(t, resting.ID, standby.OpenOrders()[0].ID)
+}
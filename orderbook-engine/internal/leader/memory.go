@@ -0,0 +1,44 @@
+package leader
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryLease 进程内租约，只能在同一进程的选举者之间互斥，仅用于测试
+type MemoryLease struct {
+	mu        sync.Mutex
+	holder    string
+	expiresAt time.Time
+}
+
+// NewMemoryLease 创建进程内租约
+func NewMemoryLease() *MemoryLease {
+	return &MemoryLease{}
+}
+
+// Acquire 获取或续约租约
+func (l *MemoryLease) Acquire(ctx context.Context, id string, ttl time.Duration) (string, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if l.holder == "" || l.holder == id || now.After(l.expiresAt) {
+		l.holder = id
+		l.expiresAt = now.Add(ttl)
+	}
+	return l.holder, nil
+}
+
+// Release 释放租约
+func (l *MemoryLease) Release(ctx context.Context, id string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.holder == id {
+		l.holder = ""
+		l.expiresAt = time.Time{}
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package leader
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	_ "github.com/lib/pq"
+)
+
+// leaseSchema 租约表，每个租约名一行
+var leaseSchema = `CREATE TABLE IF NOT EXISTS leader_leases (
+	name TEXT PRIMARY KEY,
+	holder TEXT NOT NULL,
+	expires_at TIMESTAMPTZ NOT NULL
+)`
+
+// PostgresLease 基于 PostgreSQL 的租约
+// 过期判断使用数据库时钟，不依赖各实例之间的时钟同步
+type PostgresLease struct {
+	db   *sql.DB
+	name string
+}
+
+// NewPostgresLease 创建 PostgreSQL 租约并确保表结构存在
+func NewPostgresLease(dsn, name string) (*PostgresLease, error) {
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lease database: %w", err)
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to connect to lease database: %w", err)
+	}
+	if _, err := db.Exec(leaseSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create lease schema: %w", err)
+	}
+	return &PostgresLease{db: db, name: name}, nil
+}
+
+// Close 关闭数据库连接
+func (l *PostgresLease) Close() error {
+	return l.db.Close()
+}
+
+// Acquire 获取或续约租约
+func (l *PostgresLease) Acquire(ctx context.Context, id string, ttl time.Duration) (string, error) {
+	var holder string
+	err := l.db.QueryRowContext(ctx,
+		`INSERT INTO leader_leases (name, holder, expires_at) VALUES ($1, $2, now() + $3 * interval '1 millisecond')
+		ON CONFLICT (name) DO UPDATE SET holder = EXCLUDED.holder, expires_at = EXCLUDED.expires_at
+		WHERE leader_leases.holder = EXCLUDED.holder OR leader_leases.expires_at < now()
+		RETURNING holder`,
+		l.name, id, ttl.Milliseconds(),
+	).Scan(&holder)
+	if err == sql.ErrNoRows {
+		// 租约由其他实例持有且未过期
+		err = l.db.QueryRowContext(ctx, `SELECT holder FROM leader_leases WHERE name = $1`, l.name).Scan(&holder)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to acquire lease: %w", err)
+	}
+	return holder, nil
+}
+
+// Release 释放租约
+func (l *PostgresLease) Release(ctx context.Context, id string) error {
+	if _, err := l.db.ExecContext(ctx, `DELETE FROM leader_leases WHERE name = $1 AND holder = $2`, l.name, id); err != nil {
+		return fmt.Errorf("failed to release lease: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,438 @@
+// Package lifecycle 订单与成交数据生命周期
+// 定期将超过保留期的终态订单与结算已终结的成交压缩导出到冷存储（本地目录或 S3 兼容对象存储，gzip 压缩的 JSON Lines），
+// 导出成功后从热存储删除；每个归档对象附带一份清单，启动时载入清单建立索引，按需按ID取回归档记录
+package lifecycle
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"orderbook-engine/internal/types"
+)
+
+// ErrNotArchived 记录不在归档中
+var ErrNotArchived = errors.New("record not archived")
+
+// 归档记录类型
+const (
+	KindOrders = "orders"
+	KindFills  = "fills"
+)
+
+// manifestPrefix 清单对象的键前缀
+const manifestPrefix = "manifests/"
+
+// OrderPurger 热存储的订单清理能力
+type OrderPurger interface {
+	// ArchivableOrders 终态（成交、撤销、过期、拒绝）且最后更新早于 before 的订单，最多 limit 条
+	ArchivableOrders(before time.Time, limit int) ([]*types.Order, error)
+	DeleteOrders(ids []uuid.UUID) error
+}
+
+// FillPurger 热存储的成交清理能力
+type FillPurger interface {
+	// ArchivableFills 早于 before 且不再等待结算（未进入结算、已确认或已作废）的成交，最多 limit 条
+	ArchivableFills(before time.Time, limit int) ([]*types.Fill, error)
+	DeleteFills(ids []uuid.UUID) error
+}
+
+// ColdStore 冷存储（对象存储）
+type ColdStore interface {
+	Put(ctx context.Context, key string, data []byte) error
+	Get(ctx context.Context, key string) ([]byte, error)
+	// List 返回以 prefix 开头的全部键
+	List(ctx context.Context, prefix string) ([]string, error)
+}
+
+// Config 生命周期配置
+type Config struct {
+	Retention time.Duration // 热存储保留时长
+	Interval  time.Duration // 归档任务周期
+	BatchSize int           // 每个归档对象的最大记录数
+}
+
+// Manifest 归档对象清单
+type Manifest struct {
+	Key       string    `json:"key"` // 数据对象的键
+	Kind      string    `json:"kind"`
+	CreatedAt time.Time `json:"created_at"`
+	Entries   []Entry   `json:"entries"`
+}
+
+// Entry 清单中的一条记录
+type Entry struct {
+	ID       uuid.UUID   `json:"id"`
+	OrderIDs []uuid.UUID `json:"order_ids,omitempty"` // 成交关联的 taker 与 maker 订单
+}
+
+// RunResult 一次归档任务的结果
+type RunResult struct {
+	Cutoff         time.Time `json:"cutoff"`
+	OrdersArchived int       `json:"orders_archived"`
+	FillsArchived  int       `json:"fills_archived"`
+	Objects        []string  `json:"objects"`
+}
+
+// Stats 归档统计
+type Stats struct {
+	Enabled        map[string]bool `json:"enabled"` // 热存储支持清理的记录类型
+	Retention      string          `json:"retention"`
+	IndexedOrders  int             `json:"indexed_orders"`
+	IndexedFills   int             `json:"indexed_fills"`
+	Runs           uint64          `json:"runs"`
+	OrdersArchived uint64          `json:"orders_archived"`
+	FillsArchived  uint64          `json:"fills_archived"`
+	LastRun        *RunResult      `json:"last_run,omitempty"`
+	LastRunAt      time.Time       `json:"last_run_at"`
+	LastError      string          `json:"last_error,omitempty"`
+}
+
+// Archiver 数据生命周期任务
+type Archiver struct {
+	orders OrderPurger // 为空时不归档订单
+	fills  FillPurger  // 为空时不归档成交
+	cold   ColdStore
+	config Config
+	logger *logrus.Logger
+
+	runMu sync.Mutex // 同一时刻只运行一个归档任务
+
+	mu         sync.RWMutex
+	orderIndex map[uuid.UUID]string      // 订单ID -> 数据对象键
+	fillIndex  map[uuid.UUID]string      // 成交ID -> 数据对象键
+	orderFills map[uuid.UUID][]uuid.UUID // 订单ID -> 已归档的成交ID
+	stats      Stats
+}
+
+// NewArchiver 创建生命周期任务，orders 与 fills 可以为空（对应记录不归档）
+func NewArchiver(orders OrderPurger, fills FillPurger, cold ColdStore, config Config, logger *logrus.Logger) (*Archiver, error) {
+	if cold == nil {
+		return nil, fmt.Errorf("cold store is required")
+	}
+	if config.Retention <= 0 {
+		return nil, fmt.Errorf("retention must be positive")
+	}
+	if config.Interval <= 0 {
+		config.Interval = time.Hour
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = 5000
+	}
+	return &Archiver{
+		orders:     orders,
+		fills:      fills,
+		cold:       cold,
+		config:     config,
+		logger:     logger,
+		orderIndex: make(map[uuid.UUID]string),
+		fillIndex:  make(map[uuid.UUID]string),
+		orderFills: make(map[uuid.UUID][]uuid.UUID),
+		stats: Stats{
+			Enabled:   map[string]bool{KindOrders: orders != nil, KindFills: fills != nil},
+			Retention: config.Retention.String(),
+		},
+	}, nil
+}
+
+// Load 从冷存储载入全部清单，建立归档索引
+func (a *Archiver) Load(ctx context.Context) error {
+	keys, err := a.cold.List(ctx, manifestPrefix)
+	if err != nil {
+		return fmt.Errorf("failed to list archive manifests: %w", err)
+	}
+	for _, key := range keys {
+		data, err := a.cold.Get(ctx, key)
+		if err != nil {
+			return fmt.Errorf("failed to read archive manifest %s: %w", key, err)
+		}
+		var manifest Manifest
+		if err := json.Unmarshal(data, &manifest); err != nil {
+			return fmt.Errorf("invalid archive manifest %s: %w", key, err)
+		}
+		a.index(&manifest)
+	}
+	return nil
+}
+
+// Start 启动周期归档
+func (a *Archiver) Start() {
+	go func() {
+		ticker := time.NewTicker(a.config.Interval)
+		defer ticker.Stop()
+
+		for now := range ticker.C {
+			if _, err := a.Run(context.Background(), now); err != nil {
+				a.logger.WithError(err).Error("Data lifecycle archival failed")
+			}
+		}
+	}()
+}
+
+// Run 归档早于 now-Retention 的记录：逐批导出到冷存储并从热存储删除，直到没有可归档的记录
+func (a *Archiver) Run(ctx context.Context, now time.Time) (*RunResult, error) {
+	a.runMu.Lock()
+	defer a.runMu.Unlock()
+
+	result := &RunResult{Cutoff: now.Add(-a.config.Retention), Objects: []string{}}
+	err := a.run(ctx, now, result)
+
+	a.mu.Lock()
+	a.stats.Runs++
+	a.stats.OrdersArchived += uint64(result.OrdersArchived)
+	a.stats.FillsArchived += uint64(result.FillsArchived)
+	a.stats.LastRun = result
+	a.stats.LastRunAt = now
+	a.stats.LastError = ""
+	if err != nil {
+		a.stats.LastError = err.Error()
+	}
+	a.mu.Unlock()
+
+	if result.OrdersArchived > 0 || result.FillsArchived > 0 {
+		a.logger.WithFields(logrus.Fields{
+			"orders": result.OrdersArchived,
+			"fills":  result.FillsArchived,
+			"cutoff": result.Cutoff,
+		}).Info("Archived orders and fills to cold storage")
+	}
+	return result, err
+}
+
+func (a *Archiver) run(ctx context.Context, now time.Time, result *RunResult) error {
+	for a.orders != nil {
+		orders, err := a.orders.ArchivableOrders(result.Cutoff, a.config.BatchSize)
+		if err != nil {
+			return fmt.Errorf("failed to list archivable orders: %w", err)
+		}
+		if len(orders) == 0 {
+			break
+		}
+		records := make([]interface{}, len(orders))
+		ids := make([]uuid.UUID, len(orders))
+		entries := make([]Entry, len(orders))
+		for i, order := range orders {
+			records[i], ids[i], entries[i] = order, order.ID, Entry{ID: order.ID}
+		}
+		key, err := a.export(ctx, KindOrders, now, records, entries)
+		if err != nil {
+			return err
+		}
+		result.Objects = append(result.Objects, key)
+		if err := a.orders.DeleteOrders(ids); err != nil {
+			return fmt.Errorf("failed to delete archived orders: %w", err)
+		}
+		result.OrdersArchived += len(orders)
+		if len(orders) < a.config.BatchSize {
+			break
+		}
+	}
+
+	for a.fills != nil {
+		fills, err := a.fills.ArchivableFills(result.Cutoff, a.config.BatchSize)
+		if err != nil {
+			return fmt.Errorf("failed to list archivable fills: %w", err)
+		}
+		if len(fills) == 0 {
+			break
+		}
+		records := make([]interface{}, len(fills))
+		ids := make([]uuid.UUID, len(fills))
+		entries := make([]Entry, len(fills))
+		for i, fill := range fills {
+			records[i], ids[i] = fill, fill.ID
+			entries[i] = Entry{ID: fill.ID, OrderIDs: []uuid.UUID{fill.TakerOrderID, fill.MakerOrderID}}
+		}
+		key, err := a.export(ctx, KindFills, now, records, entries)
+		if err != nil {
+			return err
+		}
+		result.Objects = append(result.Objects, key)
+		if err := a.fills.DeleteFills(ids); err != nil {
+			return fmt.Errorf("failed to delete archived fills: %w", err)
+		}
+		result.FillsArchived += len(fills)
+		if len(fills) < a.config.BatchSize {
+			break
+		}
+	}
+	return nil
+}
+
+// export 将一批记录压缩写入冷存储，再写入清单并更新索引
+// 数据对象写入后、删除热存储前失败时，记录会在下次归档中重复导出，索引指向最新的对象
+func (a *Archiver) export(ctx context.Context, kind string, now time.Time, records []interface{}, entries []Entry) (string, error) {
+	var buffer bytes.Buffer
+	writer := gzip.NewWriter(&buffer)
+	encoder := json.NewEncoder(writer)
+	for _, record := range records {
+		if err := encoder.Encode(record); err != nil {
+			return "", fmt.Errorf("failed to encode archived %s: %w", kind, err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return "", fmt.Errorf("failed to compress archived %s: %w", kind, err)
+	}
+
+	name := fmt.Sprintf("%s/%s-%s", now.UTC().Format("2006/01/02"), now.UTC().Format("150405"), uuid.New())
+	manifest := &Manifest{
+		Key:       kind + "/" + name + ".jsonl.gz",
+		Kind:      kind,
+		CreatedAt: now,
+		Entries:   entries,
+	}
+	if err := a.cold.Put(ctx, manifest.Key, buffer.Bytes()); err != nil {
+		return "", fmt.Errorf("failed to upload archived %s: %w", kind, err)
+	}
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		return "", err
+	}
+	if err := a.cold.Put(ctx, manifestPrefix+kind+"/"+name+".json", data); err != nil {
+		return "", fmt.Errorf("failed to upload archive manifest: %w", err)
+	}
+	a.index(manifest)
+	return manifest.Key, nil
+}
+
+// index 将清单加入索引
+func (a *Archiver) index(manifest *Manifest) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for _, entry := range manifest.Entries {
+		switch manifest.Kind {
+		case KindOrders:
+			a.orderIndex[entry.ID] = manifest.Key
+		case KindFills:
+			if _, exists := a.fillIndex[entry.ID]; !exists {
+				for _, orderID := range entry.OrderIDs {
+					a.orderFills[orderID] = append(a.orderFills[orderID], entry.ID)
+				}
+			}
+			a.fillIndex[entry.ID] = manifest.Key
+		}
+	}
+}
+
+// Order 取回已归档的订单
+func (a *Archiver) Order(ctx context.Context, orderID uuid.UUID) (*types.Order, error) {
+	a.mu.RLock()
+	key, exists := a.orderIndex[orderID]
+	a.mu.RUnlock()
+	if !exists {
+		return nil, ErrNotArchived
+	}
+
+	var found *types.Order
+	err := a.scan(ctx, key, func(line []byte) (bool, error) {
+		var order types.Order
+		if err := json.Unmarshal(line, &order); err != nil {
+			return false, err
+		}
+		if order.ID == orderID {
+			found = &order
+			return true, nil
+		}
+		return false, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if found == nil {
+		return nil, ErrNotArchived
+	}
+	return found, nil
+}
+
+// Fills 取回已归档的成交，按对象分组读取
+func (a *Archiver) Fills(ctx context.Context, fillIDs []uuid.UUID) ([]*types.Fill, error) {
+	wanted := make(map[string]map[uuid.UUID]bool)
+	a.mu.RLock()
+	for _, id := range fillIDs {
+		if key, exists := a.fillIndex[id]; exists {
+			if wanted[key] == nil {
+				wanted[key] = make(map[uuid.UUID]bool)
+			}
+			wanted[key][id] = true
+		}
+	}
+	a.mu.RUnlock()
+
+	fills := []*types.Fill{}
+	for key, ids := range wanted {
+		err := a.scan(ctx, key, func(line []byte) (bool, error) {
+			var fill types.Fill
+			if err := json.Unmarshal(line, &fill); err != nil {
+				return false, err
+			}
+			if ids[fill.ID] {
+				delete(ids, fill.ID)
+				fills = append(fills, &fill)
+			}
+			return len(ids) == 0, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return fills, nil
+}
+
+// OrderFills 取回订单已归档的成交
+func (a *Archiver) OrderFills(ctx context.Context, orderID uuid.UUID) ([]*types.Fill, error) {
+	a.mu.RLock()
+	ids := append([]uuid.UUID(nil), a.orderFills[orderID]...)
+	a.mu.RUnlock()
+	return a.Fills(ctx, ids)
+}
+
+// scan 逐行读取归档对象，visit 返回 true 时停止
+func (a *Archiver) scan(ctx context.Context, key string, visit func(line []byte) (bool, error)) error {
+	data, err := a.cold.Get(ctx, key)
+	if err != nil {
+		return fmt.Errorf("failed to read archive object %s: %w", key, err)
+	}
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("invalid archive object %s: %w", key, err)
+	}
+	defer reader.Close()
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		done, err := visit(line)
+		if err != nil {
+			return fmt.Errorf("invalid record in archive object %s: %w", key, err)
+		}
+		if done {
+			return nil
+		}
+	}
+	return scanner.Err()
+}
+
+// Stats 归档统计
+func (a *Archiver) Stats() Stats {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	stats := a.stats
+	stats.IndexedOrders = len(a.orderIndex)
+	stats.IndexedFills = len(a.fillIndex)
+	return stats
+}
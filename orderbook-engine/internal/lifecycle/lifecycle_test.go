@@ -0,0 +1,182 @@
+package lifecycle
+
+import (
+	"context"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"orderbook-engine/internal/types"
+)
+
+// hotStore 测试用热存储
+type hotStore struct {
+	orders map[uuid.UUID]*types.Order
+	fills  map[uuid.UUID]*types.Fill
+}
+
+func (h *hotStore) ArchivableOrders(before time.Time, limit int) ([]*types.Order, error) {
+	var result []*types.Order
+	for _, order := range h.orders {
+		if !order.IsActive() && order.UpdatedAt.Before(before) && len(result) < limit {
+			result = append(result, order)
+		}
+	}
+	return result, nil
+}
+
+func (h *hotStore) DeleteOrders(ids []uuid.UUID) error {
+	for _, id := range ids {
+		delete(h.orders, id)
+	}
+	return nil
+}
+
+func (h *hotStore) ArchivableFills(before time.Time, limit int) ([]*types.Fill, error) {
+	var result []*types.Fill
+	for _, fill := range h.fills {
+		if fill.SettlementStatus != types.SettlementStatusPending && fill.CreatedAt.Before(before) && len(result) < limit {
+			result = append(result, fill)
+		}
+	}
+	return result, nil
+}
+
+func (h *hotStore) DeleteFills(ids []uuid.UUID) error {
+	for _, id := range ids {
+		delete(h.fills, id)
+	}
+	return nil
+}
+
+func TestArchiverMovesExpiredRecordsToColdStorage(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	old := now.Add(-100 * 24 * time.Hour)
+	filled := &types.Order{ID: uuid.New(), UserAddress: "0xuser", Status: types.OrderStatusFilled, Amount: decimal.NewFromInt(1), UpdatedAt: old}
+	cancelled := &types.Order{ID: uuid.New(), Status: types.OrderStatusCancelled, UpdatedAt: old}
+	open := &types.Order{ID: uuid.New(), Status: types.OrderStatusOpen, UpdatedAt: old}
+	recent := &types.Order{ID: uuid.New(), Status: types.OrderStatusFilled, UpdatedAt: now.Add(-time.Hour)}
+	fill := &types.Fill{ID: uuid.New(), TakerOrderID: filled.ID, MakerOrderID: uuid.New(), Price: decimal.NewFromInt(2000), CreatedAt: old}
+	unsettled := &types.Fill{ID: uuid.New(), SettlementStatus: types.SettlementStatusPending, CreatedAt: old}
+
+	hot := &hotStore{
+		orders: map[uuid.UUID]*types.Order{filled.ID: filled, cancelled.ID: cancelled, open.ID: open, recent.ID: recent},
+		fills:  map[uuid.UUID]*types.Fill{fill.ID: fill, unsettled.ID: unsettled},
+	}
+	cold, err := NewFileStore(t.TempDir())
+	require.NoError(t, err)
+	archiver, err := NewArchiver(hot, hot, cold, Config{Retention: 90 * 24 * time.Hour, BatchSize: 1}, logrus.New())
+	require.NoError(t, err)
+
+	result, err := archiver.Run(context.Background(), now)
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.OrdersArchived)
+	assert.Equal(t, 1, result.FillsArchived)
+	assert.Len(t, result.Objects, 3, "每批一个对象")
+	assert.Len(t, hot.orders, 2, "活跃与未到期的订单保留在热存储")
+	assert.Contains(t, hot.fills, unsettled.ID, "等待结算的成交不归档")
+
+	// 重启后从清单重建索引，按需取回
+	restarted, err := NewArchiver(hot, hot, cold, Config{Retention: 90 * 24 * time.Hour}, logrus.New())
+	require.NoError(t, err)
+	require.NoError(t, restarted.Load(context.Background()))
+	assert.Equal(t, 2, restarted.Stats().IndexedOrders)
+
+	order, err := restarted.Order(context.Background(), filled.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "0xuser", order.UserAddress)
+	assert.True(t, order.Amount.Equal(decimal.NewFromInt(1)))
+
+	fills, err := restarted.OrderFills(context.Background(), filled.ID)
+	require.NoError(t, err)
+	require.Len(t, fills, 1)
+	assert.Equal(t, fill.ID, fills[0].ID)
+
+	_, err = restarted.Order(context.Background(), open.ID)
+	assert.ErrorIs(t, err, ErrNotArchived)
+}
+
+func TestFileStoreRejectsEscapingKeys(t *testing.T) {
+	store, err := NewFileStore(t.TempDir())
+	require.NoError(t, err)
+	assert.Error(t, store.Put(context.Background(), "../outside", []byte("x")))
+	assert.Error(t, store.Put(context.Background(), "/etc/passwd", []byte("x")))
+}
+
+func TestS3Store(t *testing.T) {
+	var mu sync.Mutex
+	objects := make(map[string][]byte)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get("Authorization")
+		if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=access/20240601/us-east-1/s3/aws4_request") || r.Header.Get("x-amz-content-sha256") == "" {
+			http.Error(w, "unsigned request", http.StatusForbidden)
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+
+		key := strings.TrimPrefix(r.URL.Path, "/archive-bucket/")
+		switch {
+		case r.Method == http.MethodPut:
+			objects[key], _ = io.ReadAll(r.Body)
+		case r.URL.Query().Get("list-type") == "2":
+			type content struct {
+				Key string `xml:"Key"`
+			}
+			var result struct {
+				XMLName  xml.Name  `xml:"ListBucketResult"`
+				Contents []content `xml:"Contents"`
+			}
+			var keys []string
+			for key := range objects {
+				if strings.HasPrefix(key, r.URL.Query().Get("prefix")) {
+					keys = append(keys, key)
+				}
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				result.Contents = append(result.Contents, content{Key: key})
+			}
+			xml.NewEncoder(w).Encode(result)
+		default:
+			data, exists := objects[key]
+			if !exists {
+				http.Error(w, "NoSuchKey", http.StatusNotFound)
+				return
+			}
+			w.Write(data)
+		}
+	}))
+	defer server.Close()
+
+	store, err := NewS3Store(S3Config{Endpoint: server.URL, Bucket: "archive-bucket", Prefix: "orderbook", AccessKey: "access", SecretKey: "secret"})
+	require.NoError(t, err)
+	store.now = func() time.Time { return time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC) }
+
+	require.NoError(t, store.Put(context.Background(), "manifests/orders/a.json", []byte("a")))
+	require.NoError(t, store.Put(context.Background(), "orders/a.jsonl.gz", []byte("b")))
+	assert.Contains(t, objects, "orderbook/orders/a.jsonl.gz")
+
+	data, err := store.Get(context.Background(), "orders/a.jsonl.gz")
+	require.NoError(t, err)
+	assert.Equal(t, "b", string(data))
+
+	keys, err := store.List(context.Background(), "manifests/")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"manifests/orders/a.json"}, keys)
+
+	_, err = store.Get(context.Background(), "missing")
+	assert.Error(t, err)
+}
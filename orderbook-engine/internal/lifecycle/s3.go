@@ -0,0 +1,222 @@
+package lifecycle
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// S3Config S3 兼容对象存储配置（AWS S3、MinIO 等），请求以 SigV4 签名
+type S3Config struct {
+	Endpoint  string // 如 https://s3.us-east-1.amazonaws.com 或 http://localhost:9000
+	Region    string
+	Bucket    string
+	Prefix    string // 所有键的公共前缀
+	AccessKey string
+	SecretKey string
+	Timeout   time.Duration
+}
+
+// S3Store S3 兼容冷存储，使用路径风格寻址（endpoint/bucket/key）
+type S3Store struct {
+	config   S3Config
+	endpoint *url.URL
+	client   *http.Client
+	now      func() time.Time
+}
+
+// NewS3Store 创建 S3 冷存储
+func NewS3Store(config S3Config) (*S3Store, error) {
+	if config.Endpoint == "" || config.Bucket == "" {
+		return nil, fmt.Errorf("s3 endpoint and bucket are required")
+	}
+	if config.AccessKey == "" || config.SecretKey == "" {
+		return nil, fmt.Errorf("s3 credentials are required")
+	}
+	endpoint, err := url.Parse(strings.TrimRight(config.Endpoint, "/"))
+	if err != nil || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid s3 endpoint %q", config.Endpoint)
+	}
+	if config.Region == "" {
+		config.Region = "us-east-1"
+	}
+	if config.Prefix != "" && !strings.HasSuffix(config.Prefix, "/") {
+		config.Prefix += "/"
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 60 * time.Second
+	}
+	return &S3Store{
+		config:   config,
+		endpoint: endpoint,
+		client:   &http.Client{Timeout: config.Timeout},
+		now:      time.Now,
+	}, nil
+}
+
+// Put 上传对象
+func (s *S3Store) Put(ctx context.Context, key string, data []byte) error {
+	_, err := s.do(ctx, http.MethodPut, s.config.Prefix+key, nil, data)
+	return err
+}
+
+// Get 下载对象
+func (s *S3Store) Get(ctx context.Context, key string) ([]byte, error) {
+	return s.do(ctx, http.MethodGet, s.config.Prefix+key, nil, nil)
+}
+
+// List 使用 ListObjectsV2 分页列出以 prefix 开头的键
+func (s *S3Store) List(ctx context.Context, prefix string) ([]string, error) {
+	var keys []string
+	token := ""
+	for {
+		query := url.Values{"list-type": {"2"}, "prefix": {s.config.Prefix + prefix}}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+		data, err := s.do(ctx, http.MethodGet, "", query, nil)
+		if err != nil {
+			return nil, err
+		}
+		var page struct {
+			Contents []struct {
+				Key string `xml:"Key"`
+			} `xml:"Contents"`
+			IsTruncated           bool   `xml:"IsTruncated"`
+			NextContinuationToken string `xml:"NextContinuationToken"`
+		}
+		if err := xml.Unmarshal(data, &page); err != nil {
+			return nil, fmt.Errorf("invalid s3 list response: %w", err)
+		}
+		for _, object := range page.Contents {
+			keys = append(keys, strings.TrimPrefix(object.Key, s.config.Prefix))
+		}
+		if !page.IsTruncated || page.NextContinuationToken == "" {
+			return keys, nil
+		}
+		token = page.NextContinuationToken
+	}
+}
+
+// do 发送签名请求，key 为空时作用于存储桶本身
+func (s *S3Store) do(ctx context.Context, method, key string, query url.Values, body []byte) ([]byte, error) {
+	target := *s.endpoint
+	target.Path = s.endpoint.Path + "/" + s.config.Bucket
+	target.RawPath = s3Escape(s.endpoint.Path, true) + "/" + s3Escape(s.config.Bucket, false)
+	if key != "" {
+		target.Path += "/" + key
+		target.RawPath += "/" + s3Escape(key, true)
+	}
+	target.RawQuery = canonicalQuery(query)
+
+	request, err := http.NewRequestWithContext(ctx, method, target.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	s.sign(request, body, target.RawPath)
+
+	response, err := s.client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	data, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("s3 %s %s returned %d: %s", method, key, response.StatusCode, strings.TrimSpace(string(data)))
+	}
+	return data, nil
+}
+
+// sign 按 AWS Signature Version 4 签名请求
+func (s *S3Store) sign(request *http.Request, body []byte, canonicalURI string) {
+	now := s.now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := amzDate[:8]
+	payloadHash := sha256Hex(body)
+
+	request.Header.Set("x-amz-date", amzDate)
+	request.Header.Set("x-amz-content-sha256", payloadHash)
+
+	signedHeaders := "host;x-amz-content-sha256;x-amz-date"
+	canonicalHeaders := "host:" + request.URL.Host + "\n" +
+		"x-amz-content-sha256:" + payloadHash + "\n" +
+		"x-amz-date:" + amzDate + "\n"
+	canonicalRequest := strings.Join([]string{
+		request.Method,
+		canonicalURI,
+		request.URL.RawQuery,
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + s.config.Region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+	key := hmacSHA256([]byte("AWS4"+s.config.SecretKey), date)
+	key = hmacSHA256(key, s.config.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	request.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.config.AccessKey, scope, signedHeaders, signature))
+}
+
+// canonicalQuery 按键排序并以 SigV4 规则编码查询参数
+func canonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var parts []string
+	for _, key := range keys {
+		values := append([]string(nil), query[key]...)
+		sort.Strings(values)
+		for _, value := range values {
+			parts = append(parts, s3Escape(key, false)+"="+s3Escape(value, false))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// s3Escape 按 SigV4 规则编码：仅保留非保留字符，keepSlash 时保留路径分隔符
+func s3Escape(value string, keepSlash bool) string {
+	var builder strings.Builder
+	for _, b := range []byte(value) {
+		switch {
+		case 'A' <= b && b <= 'Z', 'a' <= b && b <= 'z', '0' <= b && b <= '9',
+			b == '-', b == '_', b == '.', b == '~', b == '/' && keepSlash:
+			builder.WriteByte(b)
+		default:
+			fmt.Fprintf(&builder, "%%%02X", b)
+		}
+	}
+	return builder.String()
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,509 @@
+// Package listing 交易对上线生命周期
+// 管理员上线交易对（代币元数据、最小价格变动单位与数量单位、挂单最短停留时间、初始状态），安排开盘集合竞价，暂停与下架；
+// 状态持久化到JSON文件，重启后恢复并重新安排尚未开始的开盘竞价。
+// 未登记的交易对不受限制，与上线功能引入之前的行为一致
+package listing
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+
+	"orderbook-engine/internal/matching"
+	"orderbook-engine/internal/tokens"
+	"orderbook-engine/internal/types"
+)
+
+var (
+	// ErrPairExists 交易对已上线
+	ErrPairExists = errors.New("trading pair already listed")
+	// ErrPairNotFound 交易对未登记
+	ErrPairNotFound = errors.New("trading pair not listed")
+	// ErrPairDelisted 交易对已下架
+	ErrPairDelisted = errors.New("trading pair delisted")
+)
+
+// Pair 登记的交易对
+type Pair struct {
+	TradingPair    string           `json:"trading_pair"`
+	ChainID        uint64           `json:"chain_id"`
+	BaseToken      tokens.Token     `json:"base_token"`
+	QuoteToken     tokens.Token     `json:"quote_token"`
+	TickSize       decimal.Decimal  `json:"tick_size"`                // 最小价格变动单位
+	LotSize        decimal.Decimal  `json:"lot_size"`                 // 最小数量单位
+	MinRestingMs   int64            `json:"min_resting_ms,omitempty"` // 挂单最短停留时间（毫秒），未满时拒绝用户撤单，0 表示不限制
+	Status         types.PairStatus `json:"status"`
+	Reason         string           `json:"reason,omitempty"`
+	OpeningAuction *AuctionSchedule `json:"opening_auction,omitempty"` // 尚未开始的开盘集合竞价
+	ListedAt       time.Time        `json:"listed_at"`
+	UpdatedAt      time.Time        `json:"updated_at"`
+	DelistedAt     *time.Time       `json:"delisted_at,omitempty"`
+}
+
+// AuctionSchedule 开盘集合竞价安排
+type AuctionSchedule struct {
+	StartAt time.Time `json:"start_at"`
+	EndsAt  time.Time `json:"ends_at"`
+}
+
+// AuctionStarter 开始集合竞价（撮合引擎 StartAuction），竞价结束后交易对恢复连续交易
+type AuctionStarter func(tradingPair string, duration time.Duration) error
+
+// Registry 交易对登记表，同时作为撮合引擎的订单闸门
+// 未开盘（pending）与已下架的交易对拒绝全部新订单，价格与数量须为最小变动单位的整数倍；
+// 暂停与集合竞价由熔断器和撮合引擎执行，登记表通过 Observe 同步其状态
+type Registry struct {
+	mu           sync.RWMutex
+	path         string
+	pairs        map[string]*Pair
+	timers       map[string]*time.Timer // 交易对 -> 开盘竞价定时器
+	startAuction AuctionStarter
+	onStatus     func(update *types.PairStatusUpdate)
+	logger       *logrus.Logger
+}
+
+// NewRegistry 创建交易对登记表，path为空时仅保存在内存
+func NewRegistry(path string, logger *logrus.Logger) (*Registry, error) {
+	r := &Registry{
+		path:   path,
+		pairs:  make(map[string]*Pair),
+		timers: make(map[string]*time.Timer),
+		logger: logger,
+	}
+
+	if path == "" {
+		return r, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return r, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pair listings: %w", err)
+	}
+	var pairs []*Pair
+	if err := json.Unmarshal(data, &pairs); err != nil {
+		return nil, fmt.Errorf("failed to parse pair listings: %w", err)
+	}
+	for _, pair := range pairs {
+		r.pairs[pair.TradingPair] = pair
+	}
+	return r, nil
+}
+
+// SetStatusHandler 设置上线、安排开盘竞价与下架时的状态通知回调（用于WebSocket推送）
+func (r *Registry) SetStatusHandler(handler func(update *types.PairStatusUpdate)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.onStatus = handler
+}
+
+// SetAuctionStarter 设置开盘竞价的执行方式，并安排已持久化的开盘竞价
+// 重启时已错过开始时间的竞价立即开始，持续原定时长
+func (r *Registry) SetAuctionStarter(starter AuctionStarter) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.startAuction = starter
+	for _, pair := range r.pairs {
+		if pair.OpeningAuction != nil && pair.Status != types.PairStatusDelisted {
+			r.armLocked(pair.TradingPair, pair.OpeningAuction)
+		}
+	}
+}
+
+// List 上线交易对
+// TradingPair 为空时按 基础代币符号-计价代币符号 生成；Status 为空时为 pending，需安排开盘竞价或恢复交易后才接受订单；
+// 已下架的交易对可以重新上线
+func (r *Registry) List(pair Pair) (*Pair, error) {
+	if err := normalize(&pair); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	if existing, ok := r.pairs[pair.TradingPair]; ok && existing.Status != types.PairStatusDelisted {
+		r.mu.Unlock()
+		return nil, fmt.Errorf("%w: %s", ErrPairExists, pair.TradingPair)
+	}
+	now := time.Now()
+	pair.Reason = "listed"
+	pair.OpeningAuction = nil
+	pair.ListedAt = now
+	pair.UpdatedAt = now
+	pair.DelistedAt = nil
+	r.pairs[pair.TradingPair] = &pair
+	if err := r.saveLocked(); err != nil {
+		delete(r.pairs, pair.TradingPair)
+		r.mu.Unlock()
+		return nil, err
+	}
+	listed := pair
+	handler := r.onStatus
+	r.mu.Unlock()
+
+	r.logger.WithFields(logrus.Fields{
+		"trading_pair": listed.TradingPair,
+		"chain_id":     listed.ChainID,
+		"tick_size":    listed.TickSize,
+		"lot_size":     listed.LotSize,
+		"status":       listed.Status,
+	}).Info("📋 Trading pair listed")
+
+	if handler != nil {
+		handler(&types.PairStatusUpdate{
+			TradingPair: listed.TradingPair,
+			Status:      listed.Status,
+			Reason:      listed.Reason,
+			Timestamp:   now,
+		})
+	}
+	return &listed, nil
+}
+
+// ScheduleAuction 安排开盘集合竞价，startAt 不晚于当前时间时立即开始；重复安排时覆盖之前的安排
+func (r *Registry) ScheduleAuction(tradingPair string, startAt time.Time, duration time.Duration) (*Pair, error) {
+	if duration <= 0 {
+		return nil, errors.New("auction duration must be positive")
+	}
+
+	r.mu.Lock()
+	pair, err := r.activeLocked(tradingPair)
+	if err != nil {
+		r.mu.Unlock()
+		return nil, err
+	}
+	now := time.Now()
+	if startAt.Before(now) {
+		startAt = now
+	}
+	previous := pair.OpeningAuction
+	schedule := &AuctionSchedule{StartAt: startAt, EndsAt: startAt.Add(duration)}
+	pair.OpeningAuction = schedule
+	pair.UpdatedAt = now
+	if err := r.saveLocked(); err != nil {
+		pair.OpeningAuction = previous
+		r.mu.Unlock()
+		return nil, err
+	}
+	r.armLocked(tradingPair, schedule)
+	scheduled := *pair
+	handler := r.onStatus
+	r.mu.Unlock()
+
+	r.logger.WithFields(logrus.Fields{
+		"trading_pair": tradingPair,
+		"start_at":     schedule.StartAt,
+		"ends_at":      schedule.EndsAt,
+	}).Info("Opening auction scheduled")
+
+	if handler != nil {
+		handler(&types.PairStatusUpdate{
+			TradingPair: tradingPair,
+			Status:      scheduled.Status,
+			Reason:      "opening_auction_scheduled",
+			ResumeAt:    &schedule.StartAt,
+			Timestamp:   now,
+		})
+	}
+	return &scheduled, nil
+}
+
+// SetMinResting 设置交易对的挂单最短停留时间（毫秒），0 表示不限制
+func (r *Registry) SetMinResting(tradingPair string, ms int64) (*Pair, error) {
+	if ms < 0 {
+		return nil, errors.New("min resting time must not be negative")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	pair, err := r.activeLocked(tradingPair)
+	if err != nil {
+		return nil, err
+	}
+	previous := *pair
+	pair.MinRestingMs = ms
+	pair.UpdatedAt = time.Now()
+	if err := r.saveLocked(); err != nil {
+		*pair = previous
+		return nil, err
+	}
+
+	r.logger.WithFields(logrus.Fields{
+		"trading_pair":   tradingPair,
+		"min_resting_ms": ms,
+	}).Info("Minimum resting time updated")
+	updated := *pair
+	return &updated, nil
+}
+
+// Delist 下架交易对，取消尚未开始的开盘竞价；挂单由调用方撤销
+func (r *Registry) Delist(tradingPair, reason string) (*Pair, error) {
+	r.mu.Lock()
+	pair, err := r.activeLocked(tradingPair)
+	if err != nil {
+		r.mu.Unlock()
+		return nil, err
+	}
+	previous := *pair
+	now := time.Now()
+	pair.Status = types.PairStatusDelisted
+	pair.Reason = reason
+	pair.OpeningAuction = nil
+	pair.UpdatedAt = now
+	pair.DelistedAt = &now
+	if err := r.saveLocked(); err != nil {
+		*pair = previous
+		r.mu.Unlock()
+		return nil, err
+	}
+	if timer, ok := r.timers[tradingPair]; ok {
+		timer.Stop()
+		delete(r.timers, tradingPair)
+	}
+	delisted := *pair
+	handler := r.onStatus
+	r.mu.Unlock()
+
+	r.logger.WithFields(logrus.Fields{
+		"trading_pair": tradingPair,
+		"reason":       reason,
+	}).Warn("Trading pair delisted")
+
+	if handler != nil {
+		handler(&types.PairStatusUpdate{
+			TradingPair: tradingPair,
+			Status:      types.PairStatusDelisted,
+			Reason:      reason,
+			Timestamp:   now,
+		})
+	}
+	return &delisted, nil
+}
+
+// Observe 同步熔断器与撮合引擎发布的交易对状态（暂停、恢复、集合竞价开始与结束）
+// 未登记与已下架的交易对忽略；未开盘的交易对暂停后恢复即开盘
+func (r *Registry) Observe(update *types.PairStatusUpdate) {
+	switch update.Status {
+	case types.PairStatusTrading, types.PairStatusHalted, types.PairStatusAuction:
+	default:
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	pair, ok := r.pairs[update.TradingPair]
+	if !ok || pair.Status == types.PairStatusDelisted || pair.Status == update.Status {
+		return
+	}
+	pair.Status = update.Status
+	pair.Reason = update.Reason
+	pair.UpdatedAt = update.Timestamp
+	if err := r.saveLocked(); err != nil {
+		r.logger.WithError(err).WithField("trading_pair", update.TradingPair).Error("Failed to persist pair status")
+	}
+}
+
+// Get 获取登记的交易对
+func (r *Registry) Get(tradingPair string) (*Pair, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	pair, ok := r.pairs[tradingPair]
+	if !ok {
+		return nil, false
+	}
+	copied := *pair
+	return &copied, true
+}
+
+// Pairs 获取全部登记的交易对（含已下架），按交易对排序
+func (r *Registry) Pairs() []*Pair {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.sortedLocked()
+}
+
+// AllowOrder 实现 matching.OrderGate
+func (r *Registry) AllowOrder(order *types.Order) error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	pair, ok := r.pairs[order.TradingPair]
+	if !ok {
+		return nil
+	}
+	switch pair.Status {
+	case types.PairStatusPending:
+		return fmt.Errorf("%w: %s has not opened yet", matching.ErrPairNotTradable, pair.TradingPair)
+	case types.PairStatusDelisted:
+		return fmt.Errorf("%w: %s is delisted", matching.ErrPairNotTradable, pair.TradingPair)
+	}
+	if order.Type != types.OrderTypeMarket && order.Price.IsPositive() && !order.Price.Mod(pair.TickSize).IsZero() {
+		return fmt.Errorf("%w: price %s is not a multiple of tick size %s", matching.ErrInvalidIncrement, order.Price, pair.TickSize)
+	}
+	if !order.Amount.Mod(pair.LotSize).IsZero() {
+		return fmt.Errorf("%w: amount %s is not a multiple of lot size %s", matching.ErrInvalidIncrement, order.Amount, pair.LotSize)
+	}
+	return nil
+}
+
+// MinRestingTime 实现 matching.RestingPolicy，未登记或已下架的交易对不限制
+func (r *Registry) MinRestingTime(tradingPair string) time.Duration {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	pair, ok := r.pairs[tradingPair]
+	if !ok || pair.Status == types.PairStatusDelisted {
+		return 0
+	}
+	return time.Duration(pair.MinRestingMs) * time.Millisecond
+}
+
+// activeLocked 获取未下架的交易对（调用方持有锁）
+func (r *Registry) activeLocked(tradingPair string) (*Pair, error) {
+	pair, ok := r.pairs[tradingPair]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrPairNotFound, tradingPair)
+	}
+	if pair.Status == types.PairStatusDelisted {
+		return nil, fmt.Errorf("%w: %s", ErrPairDelisted, tradingPair)
+	}
+	return pair, nil
+}
+
+// armLocked 安排开盘竞价定时器，替换之前的定时器（调用方持有锁）
+func (r *Registry) armLocked(tradingPair string, schedule *AuctionSchedule) {
+	if timer, ok := r.timers[tradingPair]; ok {
+		timer.Stop()
+	}
+	r.timers[tradingPair] = time.AfterFunc(time.Until(schedule.StartAt), func() {
+		r.openAuction(tradingPair, schedule)
+	})
+}
+
+// openAuction 开始开盘竞价，安排已被替换或交易对已下架时不做处理
+// 开始失败时安排被清除，需管理员重新安排
+func (r *Registry) openAuction(tradingPair string, schedule *AuctionSchedule) {
+	r.mu.Lock()
+	pair, ok := r.pairs[tradingPair]
+	if !ok || pair.OpeningAuction != schedule {
+		r.mu.Unlock()
+		return
+	}
+	delete(r.timers, tradingPair)
+	starter := r.startAuction
+	if starter == nil {
+		// 保留安排，SetAuctionStarter 时重新安排
+		r.mu.Unlock()
+		r.logger.WithField("trading_pair", tradingPair).Warn("Opening auction due but no auction starter configured")
+		return
+	}
+	pair.OpeningAuction = nil
+	r.mu.Unlock()
+
+	// 引擎同步回调状态处理（Observe），不能在持有登记表锁时调用
+	if err := starter(tradingPair, schedule.EndsAt.Sub(schedule.StartAt)); err != nil {
+		r.logger.WithError(err).WithField("trading_pair", tradingPair).Error("Failed to start opening auction")
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if pair.Status != types.PairStatusDelisted && pair.Status != types.PairStatusAuction {
+		pair.Status = types.PairStatusAuction
+		pair.Reason = "opening"
+	}
+	pair.UpdatedAt = time.Now()
+	if err := r.saveLocked(); err != nil {
+		r.logger.WithError(err).WithField("trading_pair", tradingPair).Error("Failed to persist pair status")
+	}
+}
+
+// sortedLocked 按交易对排序的副本（调用方持有锁）
+func (r *Registry) sortedLocked() []*Pair {
+	result := make([]*Pair, 0, len(r.pairs))
+	for _, pair := range r.pairs {
+		copied := *pair
+		result = append(result, &copied)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].TradingPair < result[j].TradingPair })
+	return result
+}
+
+// saveLocked 写入登记文件（先写临时文件再重命名）
+func (r *Registry) saveLocked() error {
+	if r.path == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(r.sortedLocked(), "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(r.path), ".pairs-*")
+	if err != nil {
+		return fmt.Errorf("failed to write pair listings: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write pair listings: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write pair listings: %w", err)
+	}
+	return os.Rename(tmp.Name(), r.path)
+}
+
+// normalize 校验上线参数并补全交易对名称、链ID与初始状态
+func normalize(pair *Pair) error {
+	for _, token := range []*tokens.Token{&pair.BaseToken, &pair.QuoteToken} {
+		if !common.IsHexAddress(token.Address) {
+			return fmt.Errorf("invalid token address %q", token.Address)
+		}
+		if token.Symbol == "" {
+			return fmt.Errorf("token %s has no symbol", token.Address)
+		}
+		token.ChainID = pair.ChainID
+	}
+	if strings.EqualFold(pair.BaseToken.Address, pair.QuoteToken.Address) {
+		return errors.New("base and quote tokens must differ")
+	}
+
+	name := pair.BaseToken.Symbol + "-" + pair.QuoteToken.Symbol
+	if pair.TradingPair == "" {
+		pair.TradingPair = name
+	} else if pair.TradingPair != name {
+		// 链上订单按代币符号生成交易对名称，名称不一致时链上订单无法进入该交易对
+		return fmt.Errorf("trading pair %s does not match token symbols %s", pair.TradingPair, name)
+	}
+
+	if !pair.TickSize.IsPositive() || !pair.LotSize.IsPositive() {
+		return errors.New("tick size and lot size must be positive")
+	}
+	if pair.MinRestingMs < 0 {
+		return errors.New("min resting time must not be negative")
+	}
+
+	switch pair.Status {
+	case "":
+		pair.Status = types.PairStatusPending
+	case types.PairStatusPending, types.PairStatusTrading:
+	default:
+		return fmt.Errorf("initial status must be %s or %s, got %q", types.PairStatusPending, types.PairStatusTrading, pair.Status)
+	}
+	return nil
+}
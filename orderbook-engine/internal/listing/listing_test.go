@@ -0,0 +1,185 @@
+package listing
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"orderbook-engine/internal/matching"
+	"orderbook-engine/internal/tokens"
+	"orderbook-engine/internal/types"
+)
+
+func newPair() Pair {
+	return Pair{
+		ChainID:    1,
+		BaseToken:  tokens.Token{Address: "0x1111111111111111111111111111111111111111", Symbol: "ARB", Decimals: 18},
+		QuoteToken: tokens.Token{Address: "0x2222222222222222222222222222222222222222", Symbol: "USDC", Decimals: 6},
+		TickSize:   decimal.RequireFromString("0.001"),
+		LotSize:    decimal.RequireFromString("0.1"),
+	}
+}
+
+func newOrder(price, amount string) *types.Order {
+	return &types.Order{
+		TradingPair: "ARB-USDC",
+		Type:        types.OrderTypeLimit,
+		Price:       decimal.RequireFromString(price),
+		Amount:      decimal.RequireFromString(amount),
+	}
+}
+
+func TestListValidatesAndGatesOrders(t *testing.T) {
+	registry, err := NewRegistry("", logrus.New())
+	require.NoError(t, err)
+
+	var updates []*types.PairStatusUpdate
+	registry.SetStatusHandler(func(update *types.PairStatusUpdate) { updates = append(updates, update) })
+
+	invalid := newPair()
+	invalid.TradingPair = "ARB-USDT"
+	_, err = registry.List(invalid)
+	assert.Error(t, err)
+
+	pair, err := registry.List(newPair())
+	require.NoError(t, err)
+	assert.Equal(t, "ARB-USDC", pair.TradingPair)
+	assert.Equal(t, types.PairStatusPending, pair.Status)
+	assert.Equal(t, uint64(1), pair.BaseToken.ChainID)
+	require.Len(t, updates, 1)
+	assert.Equal(t, types.PairStatusPending, updates[0].Status)
+
+	_, err = registry.List(newPair())
+	assert.ErrorIs(t, err, ErrPairExists)
+
+	// 未开盘拒绝全部订单，未登记的交易对不受限制
+	assert.ErrorIs(t, registry.AllowOrder(newOrder("1.5", "1")), matching.ErrPairNotTradable)
+	assert.NoError(t, registry.AllowOrder(&types.Order{TradingPair: "ETH-USDC", Amount: decimal.RequireFromString("0.123")}))
+
+	registry.Observe(&types.PairStatusUpdate{TradingPair: "ARB-USDC", Status: types.PairStatusTrading, Timestamp: time.Now()})
+	assert.NoError(t, registry.AllowOrder(newOrder("1.501", "2.3")))
+	assert.ErrorIs(t, registry.AllowOrder(newOrder("1.5005", "1")), matching.ErrInvalidIncrement)
+	assert.ErrorIs(t, registry.AllowOrder(newOrder("1.5", "0.25")), matching.ErrInvalidIncrement)
+
+	// 市价单不检查价格
+	market := newOrder("0", "1")
+	market.Type = types.OrderTypeMarket
+	assert.NoError(t, registry.AllowOrder(market))
+}
+
+func TestDelistPersistsAndRejectsOrders(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pairs.json")
+	registry, err := NewRegistry(path, logrus.New())
+	require.NoError(t, err)
+
+	initial := newPair()
+	initial.Status = types.PairStatusTrading
+	_, err = registry.List(initial)
+	require.NoError(t, err)
+
+	delisted, err := registry.Delist("ARB-USDC", "token migration")
+	require.NoError(t, err)
+	assert.Equal(t, types.PairStatusDelisted, delisted.Status)
+	require.NotNil(t, delisted.DelistedAt)
+
+	_, err = registry.Delist("ARB-USDC", "again")
+	assert.ErrorIs(t, err, ErrPairDelisted)
+	_, err = registry.ScheduleAuction("ARB-USDC", time.Now(), time.Minute)
+	assert.ErrorIs(t, err, ErrPairDelisted)
+
+	// 下架后状态更新被忽略
+	registry.Observe(&types.PairStatusUpdate{TradingPair: "ARB-USDC", Status: types.PairStatusTrading, Timestamp: time.Now()})
+
+	reloaded, err := NewRegistry(path, logrus.New())
+	require.NoError(t, err)
+	pair, ok := reloaded.Get("ARB-USDC")
+	require.True(t, ok)
+	assert.Equal(t, types.PairStatusDelisted, pair.Status)
+	assert.Equal(t, "token migration", pair.Reason)
+	assert.True(t, pair.TickSize.Equal(decimal.RequireFromString("0.001")))
+	assert.ErrorIs(t, reloaded.AllowOrder(newOrder("1.5", "1")), matching.ErrPairNotTradable)
+
+	// 下架后可以重新上线
+	relisted, err := reloaded.List(newPair())
+	require.NoError(t, err)
+	assert.Equal(t, types.PairStatusPending, relisted.Status)
+	assert.Nil(t, relisted.DelistedAt)
+}
+
+func TestScheduledOpeningAuction(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pairs.json")
+	registry, err := NewRegistry(path, logrus.New())
+	require.NoError(t, err)
+	_, err = registry.List(newPair())
+	require.NoError(t, err)
+
+	_, err = registry.ScheduleAuction("BTC-USDC", time.Now(), time.Minute)
+	assert.ErrorIs(t, err, ErrPairNotFound)
+
+	// 尚未设置竞价执行方式时安排保留，重启后由新的登记表执行，错过的开始时间立即开始
+	pair, err := registry.ScheduleAuction("ARB-USDC", time.Now().Add(-time.Second), 2*time.Minute)
+	require.NoError(t, err)
+	require.NotNil(t, pair.OpeningAuction)
+	assert.Equal(t, 2*time.Minute, pair.OpeningAuction.EndsAt.Sub(pair.OpeningAuction.StartAt))
+	assert.ErrorIs(t, registry.AllowOrder(newOrder("1.5", "1")), matching.ErrPairNotTradable)
+
+	reloaded, err := NewRegistry(path, logrus.New())
+	require.NoError(t, err)
+
+	started := make(chan time.Duration, 1)
+	reloaded.SetAuctionStarter(func(tradingPair string, duration time.Duration) error {
+		started <- duration
+		return nil
+	})
+
+	select {
+	case duration := <-started:
+		assert.Equal(t, 2*time.Minute, duration)
+	case <-time.After(time.Second):
+		t.Fatal("opening auction not started")
+	}
+
+	assert.Eventually(t, func() bool {
+		pair, _ := reloaded.Get("ARB-USDC")
+		return pair.Status == types.PairStatusAuction && pair.OpeningAuction == nil
+	}, time.Second, 10*time.Millisecond)
+	assert.NoError(t, reloaded.AllowOrder(newOrder("1.5", "1")))
+}
+
+func TestMinRestingTime(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pairs.json")
+	registry, err := NewRegistry(path, logrus.New())
+	require.NoError(t, err)
+
+	invalid := newPair()
+	invalid.MinRestingMs = -1
+	_, err = registry.List(invalid)
+	assert.Error(t, err)
+
+	initial := newPair()
+	initial.MinRestingMs = 250
+	_, err = registry.List(initial)
+	require.NoError(t, err)
+	assert.Equal(t, 250*time.Millisecond, registry.MinRestingTime("ARB-USDC"))
+	assert.Zero(t, registry.MinRestingTime("ETH-USDC"))
+
+	_, err = registry.SetMinResting("ETH-USDC", 100)
+	assert.ErrorIs(t, err, ErrPairNotFound)
+	pair, err := registry.SetMinResting("ARB-USDC", 500)
+	require.NoError(t, err)
+	assert.Equal(t, int64(500), pair.MinRestingMs)
+
+	reloaded, err := NewRegistry(path, logrus.New())
+	require.NoError(t, err)
+	assert.Equal(t, 500*time.Millisecond, reloaded.MinRestingTime("ARB-USDC"))
+
+	// 下架后不再限制
+	_, err = reloaded.Delist("ARB-USDC", "migration")
+	require.NoError(t, err)
+	assert.Zero(t, reloaded.MinRestingTime("ARB-USDC"))
+}
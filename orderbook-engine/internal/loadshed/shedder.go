@@ -0,0 +1,247 @@
+// Package loadshed 过载降级
+// 撮合事件积压或请求延迟超过阈值时进入降级模式：撤单照常处理，新订单按用户限流
+package loadshed
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"orderbook-engine/internal/types"
+)
+
+// ErrShedding 降级期间新订单被限流
+var ErrShedding = errors.New("system overloaded, new orders throttled")
+
+// Config 降级配置
+type Config struct {
+	MaxQueueDepth  int           `json:"max_queue_depth"`  // 撮合事件积压阈值
+	MaxLatency     time.Duration `json:"max_latency"`      // 请求平均延迟阈值
+	RecoverPeriod  time.Duration `json:"recover_period"`   // 指标持续低于阈值一半多久后退出降级
+	UserOrderRate  float64       `json:"user_order_rate"`  // 降级期间每用户每秒新订单数
+	UserOrderBurst int           `json:"user_order_burst"` // 降级期间每用户突发新订单数
+}
+
+// Status 降级状态
+type Status struct {
+	Shedding       bool          `json:"shedding"`
+	Reason         string        `json:"reason,omitempty"`
+	Since          *time.Time    `json:"since,omitempty"`
+	QueueDepth     int           `json:"queue_depth"`
+	AvgLatency     time.Duration `json:"avg_latency"`
+	ThrottledCount uint64        `json:"throttled_count"`
+}
+
+// latencyAlpha 请求延迟指数移动平均系数
+const latencyAlpha = 0.2
+
+// tokenBucket 单用户令牌桶
+type tokenBucket struct {
+	tokens   float64
+	updateAt time.Time
+}
+
+// Shedder 过载降级控制器
+type Shedder struct {
+	mu           sync.Mutex
+	config       *Config
+	queueDepth   func() int
+	avgLatency   time.Duration
+	shedding     bool
+	reason       string
+	since        time.Time
+	healthySince time.Time
+	buckets      map[string]*tokenBucket
+	throttled    uint64
+	onChange     func(update *types.SystemStatusUpdate)
+	logger       *logrus.Logger
+}
+
+// NewShedder 创建过载降级控制器
+// queueDepth 返回当前撮合事件积压数量
+func NewShedder(config *Config, queueDepth func() int, logger *logrus.Logger) *Shedder {
+	return &Shedder{
+		config:     config,
+		queueDepth: queueDepth,
+		buckets:    make(map[string]*tokenBucket),
+		logger:     logger,
+	}
+}
+
+// SetStatusHandler 设置系统状态变化回调（用于WebSocket通知）
+func (s *Shedder) SetStatusHandler(handler func(update *types.SystemStatusUpdate)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onChange = handler
+}
+
+// ObserveLatency 记录一次请求处理耗时
+func (s *Shedder) ObserveLatency(latency time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.avgLatency == 0 {
+		s.avgLatency = latency
+		return
+	}
+	s.avgLatency = time.Duration(latencyAlpha*float64(latency) + (1-latencyAlpha)*float64(s.avgLatency))
+}
+
+// AllowNewOrder 检查新订单是否放行
+// 正常模式全部放行；降级模式按用户令牌桶限流。撤单不经过此检查
+func (s *Shedder) AllowNewOrder(userAddress string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.shedding {
+		return nil
+	}
+
+	now := time.Now()
+	bucket, exists := s.buckets[userAddress]
+	if !exists {
+		bucket = &tokenBucket{tokens: float64(s.config.UserOrderBurst), updateAt: now}
+		s.buckets[userAddress] = bucket
+	}
+
+	bucket.tokens += now.Sub(bucket.updateAt).Seconds() * s.config.UserOrderRate
+	if bucket.tokens > float64(s.config.UserOrderBurst) {
+		bucket.tokens = float64(s.config.UserOrderBurst)
+	}
+	bucket.updateAt = now
+
+	if bucket.tokens < 1 {
+		s.throttled++
+		return ErrShedding
+	}
+	bucket.tokens--
+	return nil
+}
+
+// IsShedding 是否处于降级模式
+func (s *Shedder) IsShedding() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.shedding
+}
+
+// GetStatus 获取降级状态
+func (s *Shedder) GetStatus() *Status {
+	depth := s.queueDepth()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	status := &Status{
+		Shedding:       s.shedding,
+		Reason:         s.reason,
+		QueueDepth:     depth,
+		AvgLatency:     s.avgLatency,
+		ThrottledCount: s.throttled,
+	}
+	if s.shedding {
+		since := s.since
+		status.Since = &since
+	}
+	return status
+}
+
+// Start 启动过载检测
+func (s *Shedder) Start(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		for range ticker.C {
+			s.evaluate()
+		}
+	}()
+}
+
+// evaluate 根据当前指标切换降级模式
+func (s *Shedder) evaluate() {
+	depth := s.queueDepth()
+	now := time.Now()
+
+	s.mu.Lock()
+	latency := s.avgLatency
+
+	var update *types.SystemStatusUpdate
+	if !s.shedding {
+		reason := s.overloadReason(depth, latency)
+		if reason != "" {
+			s.shedding = true
+			s.reason = reason
+			s.since = now
+			s.healthySince = time.Time{}
+			update = &types.SystemStatusUpdate{Status: types.SystemStatusShedding, Reason: reason, Timestamp: now}
+		}
+	} else if s.isHealthy(depth, latency) {
+		// 指标需持续恢复一段时间才退出，避免频繁切换
+		if s.healthySince.IsZero() {
+			s.healthySince = now
+		} else if now.Sub(s.healthySince) >= s.config.RecoverPeriod {
+			s.shedding = false
+			s.reason = ""
+			s.buckets = make(map[string]*tokenBucket)
+			update = &types.SystemStatusUpdate{Status: types.SystemStatusNormal, Reason: "load recovered", Timestamp: now}
+		}
+	} else {
+		s.healthySince = time.Time{}
+	}
+
+	handler := s.onChange
+	s.mu.Unlock()
+
+	if update == nil {
+		return
+	}
+
+	fields := logrus.Fields{
+		"queue_depth": depth,
+		"avg_latency": latency.String(),
+	}
+	if update.Status == types.SystemStatusShedding {
+		s.logger.WithFields(fields).WithField("reason", update.Reason).Warn("🚨 Entering load shedding mode")
+	} else {
+		s.logger.WithFields(fields).Info("Load shedding mode cleared")
+	}
+
+	if handler != nil {
+		handler(update)
+	}
+}
+
+// overloadReason 返回过载原因，未过载返回空字符串
+func (s *Shedder) overloadReason(depth int, latency time.Duration) string {
+	if s.config.MaxQueueDepth > 0 && depth >= s.config.MaxQueueDepth {
+		return fmt.Sprintf("event queue depth %d exceeds %d", depth, s.config.MaxQueueDepth)
+	}
+	if s.config.MaxLatency > 0 && latency >= s.config.MaxLatency {
+		return fmt.Sprintf("average latency %s exceeds %s", latency, s.config.MaxLatency)
+	}
+	return ""
+}
+
+// isHealthy 指标低于阈值的一半视为恢复
+func (s *Shedder) isHealthy(depth int, latency time.Duration) bool {
+	if s.config.MaxQueueDepth > 0 && depth >= s.config.MaxQueueDepth/2 {
+		return false
+	}
+	if s.config.MaxLatency > 0 && latency >= s.config.MaxLatency/2 {
+		return false
+	}
+	return true
+}
+
+// DefaultConfig 默认降级配置
+func DefaultConfig() *Config {
+	return &Config{
+		MaxQueueDepth:  8000,                   // 事件通道容量的80%
+		MaxLatency:     500 * time.Millisecond, // 平均延迟500ms
+		RecoverPeriod:  30 * time.Second,
+		UserOrderRate:  1,
+		UserOrderBurst: 5,
+	}
+}
@@ -0,0 +1,119 @@
+package loadshed
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"orderbook-engine/internal/types"
+)
+
+func newTestShedder(depth *int) (*Shedder, *[]*types.SystemStatusUpdate) {
+	config := &Config{
+		MaxQueueDepth:  100,
+		MaxLatency:     100 * time.Millisecond,
+		RecoverPeriod:  time.Minute,
+		UserOrderRate:  0,
+		UserOrderBurst: 2,
+	}
+	shedder := NewShedder(config, func() int { return *depth }, logrus.New())
+	var updates []*types.SystemStatusUpdate
+	shedder.SetStatusHandler(func(update *types.SystemStatusUpdate) {
+		updates = append(updates, update)
+	})
+	return shedder, &updates
+}
+
+func TestQueueDepthThreshold(t *testing.T) {
+	depth := 99
+	shedder, updates := newTestShedder(&depth)
+
+	// 低于阈值不降级
+	shedder.evaluate()
+	assert.False(t, shedder.IsShedding())
+	assert.NoError(t, shedder.AllowNewOrder("alice"))
+
+	depth = 100
+	shedder.evaluate()
+	require.True(t, shedder.IsShedding())
+	require.Len(t, *updates, 1)
+	assert.Equal(t, types.SystemStatusShedding, (*updates)[0].Status)
+	status := shedder.GetStatus()
+	assert.Contains(t, status.Reason, "queue depth 100")
+	assert.NotNil(t, status.Since)
+
+	// 降到阈值一半以上仍未恢复
+	depth = 50
+	shedder.evaluate()
+	shedder.evaluate()
+	assert.True(t, shedder.IsShedding())
+	assert.True(t, shedder.healthySince.IsZero())
+
+	// 低于阈值一半并持续恢复期后退出降级
+	depth = 49
+	shedder.evaluate()
+	assert.True(t, shedder.IsShedding())
+	shedder.healthySince = shedder.healthySince.Add(-time.Minute)
+	shedder.evaluate()
+	assert.False(t, shedder.IsShedding())
+	require.Len(t, *updates, 2)
+	assert.Equal(t, types.SystemStatusNormal, (*updates)[1].Status)
+}
+
+func TestLatencyThresholdAndRecoveryReset(t *testing.T) {
+	depth := 0
+	shedder, updates := newTestShedder(&depth)
+
+	// 延迟按移动平均计算，单次慢请求不触发降级
+	shedder.ObserveLatency(10 * time.Millisecond)
+	shedder.ObserveLatency(200 * time.Millisecond)
+	shedder.evaluate()
+	assert.False(t, shedder.IsShedding())
+
+	for i := 0; i < 10; i++ {
+		shedder.ObserveLatency(200 * time.Millisecond)
+	}
+	shedder.evaluate()
+	require.True(t, shedder.IsShedding())
+	assert.Contains(t, shedder.GetStatus().Reason, "average latency")
+
+	// 恢复期内指标回升时重新计时
+	for i := 0; i < 20; i++ {
+		shedder.ObserveLatency(time.Millisecond)
+	}
+	shedder.evaluate()
+	assert.False(t, shedder.healthySince.IsZero())
+	for i := 0; i < 20; i++ {
+		shedder.ObserveLatency(200 * time.Millisecond)
+	}
+	shedder.evaluate()
+	assert.True(t, shedder.healthySince.IsZero())
+	assert.True(t, shedder.IsShedding())
+	assert.Len(t, *updates, 1)
+}
+
+func TestSheddingThrottlesNewOrdersPerUser(t *testing.T) {
+	depth := 100
+	shedder, _ := newTestShedder(&depth)
+	shedder.evaluate()
+	require.True(t, shedder.IsShedding())
+
+	// 每个用户按突发数放行，之后被限流
+	assert.NoError(t, shedder.AllowNewOrder("alice"))
+	assert.NoError(t, shedder.AllowNewOrder("alice"))
+	assert.ErrorIs(t, shedder.AllowNewOrder("alice"), ErrShedding)
+	assert.NoError(t, shedder.AllowNewOrder("bob"))
+	assert.Equal(t, uint64(1), shedder.GetStatus().ThrottledCount)
+
+	// 退出降级后全部放行，令牌桶重置
+	depth = 0
+	shedder.evaluate()
+	shedder.healthySince = shedder.healthySince.Add(-time.Minute)
+	shedder.evaluate()
+	require.False(t, shedder.IsShedding())
+	assert.NoError(t, shedder.AllowNewOrder("alice"))
+	assert.Empty(t, shedder.buckets)
+}
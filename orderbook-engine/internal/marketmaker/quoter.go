@@ -0,0 +1,296 @@
+// Package marketmaker 内置做市机器人
+// 使用专用内部账户围绕指数价格双边挂单，为新交易对提供初始流动性
+package marketmaker
+
+import (
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+
+	"orderbook-engine/internal/matching"
+	"orderbook-engine/internal/oracle"
+	"orderbook-engine/internal/types"
+)
+
+// PairConfig 单个交易对的报价配置
+type PairConfig struct {
+	TradingPair  string          `json:"trading_pair"`
+	BaseToken    string          `json:"base_token"`
+	QuoteToken   string          `json:"quote_token"`
+	Size         decimal.Decimal `json:"size"`          // 单边挂单数量
+	SpreadBps    decimal.Decimal `json:"spread_bps"`    // 买卖价差(基点)
+	MaxInventory decimal.Decimal `json:"max_inventory"` // 基础代币最大净持仓(绝对值)
+}
+
+// OrderRecorder 记录机器人订单（由存储层实现）
+type OrderRecorder interface {
+	CreateOrder(order *types.Order) error
+	UpdateOrder(order *types.Order) error
+}
+
+// PairStatus 交易对报价状态
+type PairStatus struct {
+	TradingPair string          `json:"trading_pair"`
+	Inventory   decimal.Decimal `json:"inventory"`
+	IndexPrice  decimal.Decimal `json:"index_price"`
+	BidOrderID  *uuid.UUID      `json:"bid_order_id,omitempty"`
+	AskOrderID  *uuid.UUID      `json:"ask_order_id,omitempty"`
+	LastQuoteAt *time.Time      `json:"last_quote_at,omitempty"`
+	LastError   string          `json:"last_error,omitempty"`
+}
+
+// Status 机器人状态
+type Status struct {
+	Account string        `json:"account"`
+	Killed  bool          `json:"killed"`
+	Pairs   []*PairStatus `json:"pairs"`
+}
+
+// pairQuoter 单个交易对的报价状态
+type pairQuoter struct {
+	config *PairConfig
+	status PairStatus
+	bid    *types.Order
+	ask    *types.Order
+}
+
+// Quoter 做市机器人
+type Quoter struct {
+	mu       sync.Mutex
+	account  string
+	engine   *matching.MatchingEngine
+	index    oracle.PriceOracle
+	recorder OrderRecorder
+	pairs    map[string]*pairQuoter
+	orders   map[uuid.UUID]*pairQuoter // 机器人挂单ID -> 交易对
+	killed   bool
+	logger   *logrus.Logger
+}
+
+// NewQuoter 创建做市机器人
+func NewQuoter(account string, engine *matching.MatchingEngine, index oracle.PriceOracle, recorder OrderRecorder, pairs []*PairConfig, logger *logrus.Logger) *Quoter {
+	q := &Quoter{
+		account:  account,
+		engine:   engine,
+		index:    index,
+		recorder: recorder,
+		pairs:    make(map[string]*pairQuoter),
+		orders:   make(map[uuid.UUID]*pairQuoter),
+		logger:   logger,
+	}
+	for _, config := range pairs {
+		q.pairs[config.TradingPair] = &pairQuoter{
+			config: config,
+			status: PairStatus{TradingPair: config.TradingPair},
+		}
+	}
+	return q
+}
+
+// Start 启动定时报价，并从撮合事件中跟踪机器人成交
+func (q *Quoter) Start(interval time.Duration) {
+	sub := q.engine.Subscribe(matching.SubscriptionOptions{
+		Name:       "liquidity_bot",
+		EventTypes: []string{matching.EventOrderAdded, matching.EventAuctionUncrossed},
+	})
+	go func() {
+		for event := range sub.Events() {
+			for _, fill := range event.Fills {
+				q.recordFill(fill)
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(interval)
+	go func() {
+		for range ticker.C {
+			q.Refresh()
+		}
+	}()
+}
+
+// Refresh 撤销旧报价并按最新指数价格重新报价
+func (q *Quoter) Refresh() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.killed {
+		return
+	}
+
+	for _, pq := range q.pairs {
+		q.cancelQuotesLocked(pq)
+
+		quote, err := q.index.GetPrice(pq.config.TradingPair)
+		if err != nil {
+			pq.status.LastError = err.Error()
+			continue
+		}
+		pq.status.IndexPrice = quote.Price
+		pq.status.LastError = ""
+
+		halfSpread := pq.config.SpreadBps.Div(decimal.NewFromInt(20000))
+		one := decimal.NewFromInt(1)
+
+		// 库存超限时只挂减仓方向
+		if pq.status.Inventory.LessThan(pq.config.MaxInventory) {
+			pq.bid = q.placeLocked(pq, types.OrderSideBuy, quote.Price.Mul(one.Sub(halfSpread)).Round(8))
+		}
+		if pq.status.Inventory.Neg().LessThan(pq.config.MaxInventory) {
+			pq.ask = q.placeLocked(pq, types.OrderSideSell, quote.Price.Mul(one.Add(halfSpread)).Round(8))
+		}
+
+		now := time.Now()
+		pq.status.LastQuoteAt = &now
+	}
+}
+
+// Kill 紧急停止：撤销全部报价并停止报价，需人工恢复
+func (q *Quoter) Kill() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.killed = true
+	for _, pq := range q.pairs {
+		q.cancelQuotesLocked(pq)
+	}
+
+	q.logger.WithField("account", q.account).Warn("🛑 Liquidity bot killed, all quotes cancelled")
+}
+
+// Resume 解除紧急停止，下一个周期恢复报价
+func (q *Quoter) Resume() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.killed = false
+	q.logger.WithField("account", q.account).Info("Liquidity bot resumed")
+}
+
+// GetStatus 获取机器人状态
+func (q *Quoter) GetStatus() *Status {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	status := &Status{Account: q.account, Killed: q.killed}
+	for _, pq := range q.pairs {
+		pairStatus := pq.status
+		if pq.bid != nil {
+			pairStatus.BidOrderID = &pq.bid.ID
+		}
+		if pq.ask != nil {
+			pairStatus.AskOrderID = &pq.ask.ID
+		}
+		status.Pairs = append(status.Pairs, &pairStatus)
+	}
+	return status
+}
+
+// placeLocked 提交机器人挂单
+func (q *Quoter) placeLocked(pq *pairQuoter, side types.OrderSide, price decimal.Decimal) *types.Order {
+	if !price.IsPositive() || !pq.config.Size.IsPositive() {
+		return nil
+	}
+
+	now := time.Now()
+	order := &types.Order{
+		ID:          uuid.New(),
+		UserAddress: q.account,
+		TradingPair: pq.config.TradingPair,
+		BaseToken:   pq.config.BaseToken,
+		QuoteToken:  pq.config.QuoteToken,
+		Side:        side,
+		Type:        types.OrderTypeLimit,
+		Price:       price,
+		Amount:      pq.config.Size,
+		Status:      types.OrderStatusPending,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	if q.recorder != nil {
+		if err := q.recorder.CreateOrder(order); err != nil {
+			q.logger.WithError(err).Error("Failed to record liquidity bot order")
+		}
+	}
+
+	// 先登记订单ID，成交事件可能在 AddOrder 返回前到达
+	q.orders[order.ID] = pq
+	fills, err := q.engine.AddOrder(order)
+	if err != nil {
+		delete(q.orders, order.ID)
+		pq.status.LastError = err.Error()
+		q.logger.WithError(err).WithField("trading_pair", pq.config.TradingPair).Warn("Liquidity bot order rejected")
+		return nil
+	}
+
+	// 作为taker立即成交的部分在此计入库存
+	for _, fill := range fills {
+		q.applyFillLocked(pq, side, fill.Amount)
+	}
+
+	if q.recorder != nil {
+		if err := q.recorder.UpdateOrder(order); err != nil {
+			q.logger.WithError(err).Error("Failed to update liquidity bot order")
+		}
+	}
+
+	if !order.IsActive() {
+		delete(q.orders, order.ID)
+		return nil
+	}
+	return order
+}
+
+// cancelQuotesLocked 撤销交易对的现有报价
+func (q *Quoter) cancelQuotesLocked(pq *pairQuoter) {
+	for _, order := range []*types.Order{pq.bid, pq.ask} {
+		if order == nil {
+			continue
+		}
+		if q.engine.CancelOrder(order.ID, order.TradingPair) && q.recorder != nil {
+			if err := q.recorder.UpdateOrder(order); err != nil {
+				q.logger.WithError(err).Error("Failed to update liquidity bot order")
+			}
+		}
+		delete(q.orders, order.ID)
+	}
+	pq.bid = nil
+	pq.ask = nil
+}
+
+// recordFill 机器人挂单作为maker被成交时更新库存
+func (q *Quoter) recordFill(fill *types.Fill) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	pq, exists := q.orders[fill.MakerOrderID]
+	if !exists {
+		return
+	}
+
+	// maker方向与taker相反
+	side := types.OrderSideBuy
+	if fill.TakerSide == types.OrderSideBuy {
+		side = types.OrderSideSell
+	}
+	q.applyFillLocked(pq, side, fill.Amount)
+}
+
+func (q *Quoter) applyFillLocked(pq *pairQuoter, side types.OrderSide, amount decimal.Decimal) {
+	if side == types.OrderSideBuy {
+		pq.status.Inventory = pq.status.Inventory.Add(amount)
+	} else {
+		pq.status.Inventory = pq.status.Inventory.Sub(amount)
+	}
+
+	q.logger.WithFields(logrus.Fields{
+		"trading_pair": pq.config.TradingPair,
+		"side":         side,
+		"amount":       amount.String(),
+		"inventory":    pq.status.Inventory.String(),
+	}).Debug("Liquidity bot filled")
+}
@@ -0,0 +1,145 @@
+package marketmaker
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"orderbook-engine/internal/matching"
+	"orderbook-engine/internal/oracle"
+	"orderbook-engine/internal/types"
+)
+
+const (
+	botAccount = "0x00000000000000000000000000000000000000b0"
+	pair       = "WETH-USDC"
+)
+
+// staticIndex 固定指数价格，err 不为空时返回错误
+type staticIndex struct {
+	price decimal.Decimal
+	err   error
+}
+
+func (i *staticIndex) GetPrice(tradingPair string) (*oracle.PriceQuote, error) {
+	if i.err != nil {
+		return nil, i.err
+	}
+	return &oracle.PriceQuote{TradingPair: tradingPair, Price: i.price, Source: "test", UpdatedAt: time.Now()}, nil
+}
+
+func (i *staticIndex) Name() string {
+	return "test"
+}
+
+// memoryRecorder 记录机器人订单的最新状态
+type memoryRecorder struct {
+	orders map[uuid.UUID]types.OrderStatus
+}
+
+func (r *memoryRecorder) CreateOrder(order *types.Order) error {
+	r.orders[order.ID] = order.Status
+	return nil
+}
+
+func (r *memoryRecorder) UpdateOrder(order *types.Order) error {
+	r.orders[order.ID] = order.Status
+	return nil
+}
+
+func newTestQuoter(index oracle.PriceOracle) (*Quoter, *matching.MatchingEngine, *memoryRecorder) {
+	engine := matching.NewMatchingEngine(logrus.New())
+	recorder := &memoryRecorder{orders: make(map[uuid.UUID]types.OrderStatus)}
+	quoter := NewQuoter(botAccount, engine, index, recorder, []*PairConfig{{
+		TradingPair:  pair,
+		BaseToken:    "WETH",
+		QuoteToken:   "USDC",
+		Size:         decimal.NewFromInt(1),
+		SpreadBps:    decimal.NewFromInt(100),
+		MaxInventory: decimal.NewFromInt(1),
+	}}, logrus.New())
+	return quoter, engine, recorder
+}
+
+func TestRefreshQuotesAroundIndex(t *testing.T) {
+	quoter, engine, recorder := newTestQuoter(&staticIndex{price: decimal.NewFromInt(2000)})
+
+	// 100 基点价差：买 1990，卖 2010
+	quoter.Refresh()
+	book := engine.GetOrderBook(pair, 10)
+	require.Len(t, book.Bids, 1)
+	require.Len(t, book.Asks, 1)
+	assert.True(t, book.Bids[0].Price.Equal(decimal.NewFromInt(1990)))
+	assert.True(t, book.Asks[0].Price.Equal(decimal.NewFromInt(2010)))
+
+	status := quoter.GetStatus().Pairs[0]
+	require.NotNil(t, status.BidOrderID)
+	require.NotNil(t, status.AskOrderID)
+	firstBid := *status.BidOrderID
+
+	// 再次报价前撤销旧报价，订单簿中每边只有一笔机器人挂单
+	quoter.Refresh()
+	book = engine.GetOrderBook(pair, 10)
+	assert.Len(t, book.Bids, 1)
+	assert.Len(t, book.Asks, 1)
+	assert.Equal(t, 1, book.Bids[0].Count)
+	assert.NotEqual(t, firstBid, *quoter.GetStatus().Pairs[0].BidOrderID)
+	assert.Equal(t, types.OrderStatusCancelled, recorder.orders[firstBid])
+}
+
+func TestInventoryLimitSkipsQuoteSide(t *testing.T) {
+	quoter, engine, _ := newTestQuoter(&staticIndex{price: decimal.NewFromInt(2000)})
+	quoter.Refresh()
+
+	// 外部买单吃掉机器人卖单，净持仓 -1 达到上限
+	taker := &types.Order{ID: uuid.New(), UserAddress: "taker", TradingPair: pair, Side: types.OrderSideBuy, Type: types.OrderTypeLimit,
+		Price: decimal.NewFromInt(2010), Amount: decimal.NewFromInt(1), Status: types.OrderStatusPending, CreatedAt: time.Now()}
+	fills, err := engine.AddOrder(taker)
+	require.NoError(t, err)
+	require.Len(t, fills, 1)
+	quoter.recordFill(fills[0])
+	assert.True(t, quoter.GetStatus().Pairs[0].Inventory.Equal(decimal.NewFromInt(-1)))
+
+	// 只挂减仓方向的买单
+	quoter.Refresh()
+	book := engine.GetOrderBook(pair, 10)
+	assert.Len(t, book.Bids, 1)
+	assert.Empty(t, book.Asks)
+	assert.Nil(t, quoter.GetStatus().Pairs[0].AskOrderID)
+
+	// 其他用户的成交不计入库存
+	quoter.recordFill(&types.Fill{MakerOrderID: uuid.New(), TakerSide: types.OrderSideSell, Amount: decimal.NewFromInt(5)})
+	assert.True(t, quoter.GetStatus().Pairs[0].Inventory.Equal(decimal.NewFromInt(-1)))
+}
+
+func TestKillCancelsQuotesUntilResumed(t *testing.T) {
+	index := &staticIndex{price: decimal.NewFromInt(2000)}
+	quoter, engine, _ := newTestQuoter(index)
+	quoter.Refresh()
+
+	quoter.Kill()
+	assert.True(t, quoter.GetStatus().Killed)
+	assert.Empty(t, engine.OpenOrders())
+
+	// 停止期间不报价
+	quoter.Refresh()
+	assert.Empty(t, engine.OpenOrders())
+
+	// 恢复后指数价格不可用时记录错误且不报价
+	quoter.Resume()
+	index.err = errors.New("index unavailable")
+	quoter.Refresh()
+	assert.Empty(t, engine.OpenOrders())
+	assert.Equal(t, "index unavailable", quoter.GetStatus().Pairs[0].LastError)
+
+	index.err = nil
+	quoter.Refresh()
+	assert.Len(t, engine.OpenOrders(), 2)
+	assert.Empty(t, quoter.GetStatus().Pairs[0].LastError)
+}
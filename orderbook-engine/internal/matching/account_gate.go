@@ -0,0 +1,34 @@
+package matching
+
+import (
+	"errors"
+	"fmt"
+
+	"orderbook-engine/internal/types"
+)
+
+// ErrAccountFrozen 账户已冻结
+var ErrAccountFrozen = errors.New("account frozen")
+
+// AccountGate 账户闸门（账户冻结等）
+// AllowAccount 返回错误时拒绝该账户的全部新订单，REST 与链上入口均经过该检查
+type AccountGate interface {
+	AllowAccount(userAddress string) error
+}
+
+// AddAccountGate 注册账户闸门
+func (me *MatchingEngine) AddAccountGate(gate AccountGate) {
+	me.mu.Lock()
+	defer me.mu.Unlock()
+	me.accountGates = append(me.accountGates, gate)
+}
+
+// rejectBlockedAccount 拒绝被账户闸门拦截的新订单
+func (me *MatchingEngine) rejectBlockedAccount(order *types.Order) error {
+	for _, gate := range me.accountGates {
+		if err := gate.AllowAccount(order.UserAddress); err != nil {
+			return me.rejectOrder(order, types.StatusReasonAccountFrozen, fmt.Errorf("%w: %v", ErrAccountFrozen, err))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,365 @@
+package matching
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+
+	"orderbook-engine/internal/clock"
+	"orderbook-engine/internal/types"
+)
+
+// ErrAuctionInProgress 交易对处于集合竞价阶段，不接受市价单
+var ErrAuctionInProgress = errors.New("trading pair in call auction")
+
+// AuctionState 集合竞价状态
+// 竞价期间限价单只进入订单簿不撮合，结束时按成交量最大的单一价格统一成交
+type AuctionState struct {
+	TradingPair     string          `json:"trading_pair"`
+	Reason          string          `json:"reason"` // opening、halt_resume 等
+	StartedAt       time.Time       `json:"started_at"`
+	EndsAt          time.Time       `json:"ends_at"`
+	IndicativePrice decimal.Decimal `json:"indicative_price"` // 当前订单簿的参考撮合价
+	MatchableVolume decimal.Decimal `json:"matchable_volume"` // 参考撮合价下可成交数量
+}
+
+// auction 进行中的集合竞价
+type auction struct {
+	reason    string
+	startedAt time.Time
+	endsAt    time.Time
+	timer     *time.Timer
+}
+
+// uncrossResult 竞价撮合价及可成交数量
+type uncrossResult struct {
+	price     decimal.Decimal
+	volume    decimal.Decimal
+	imbalance decimal.Decimal
+}
+
+// SetPairStatusHandler 设置交易对状态变化回调（进入/结束集合竞价，用于WebSocket通知）
+func (me *MatchingEngine) SetPairStatusHandler(handler func(update *types.PairStatusUpdate)) {
+	me.mu.Lock()
+	defer me.mu.Unlock()
+	me.onPairStatus = handler
+}
+
+// StartAuction 交易对进入集合竞价，duration 后自动撮合并恢复连续交易
+// 已在竞价中时延长到新的结束时间
+func (me *MatchingEngine) StartAuction(tradingPair, reason string, duration time.Duration) error {
+	if duration <= 0 {
+		return fmt.Errorf("auction duration must be positive")
+	}
+
+	me.mu.Lock()
+	now := time.Now()
+	state := me.auctions[tradingPair]
+	if state == nil {
+		state = &auction{startedAt: now}
+		me.auctions[tradingPair] = state
+	} else {
+		state.timer.Stop()
+	}
+	state.reason = reason
+	state.endsAt = now.Add(duration)
+	deadline := state.endsAt
+	state.timer = time.AfterFunc(duration, func() { me.endAuction(tradingPair, state, deadline) })
+	handler := me.onPairStatus
+	endsAt := state.endsAt
+	me.mu.Unlock()
+
+	me.logger.WithFields(logrus.Fields{
+		"trading_pair": tradingPair,
+		"reason":       reason,
+		"ends_at":      endsAt,
+	}).Info("🔔 Call auction started")
+
+	if handler != nil {
+		handler(&types.PairStatusUpdate{
+			TradingPair: tradingPair,
+			Status:      types.PairStatusAuction,
+			Reason:      reason,
+			ResumeAt:    &endsAt,
+			Timestamp:   now,
+		})
+	}
+	return nil
+}
+
+// EndAuction 立即结束集合竞价并撮合（紧急停机期间顺延到恢复之后），交易对不在竞价中时返回 false
+func (me *MatchingEngine) EndAuction(tradingPair string) ([]*types.Fill, bool) {
+	me.mu.RLock()
+	state := me.auctions[tradingPair]
+	me.mu.RUnlock()
+	if state == nil {
+		return nil, false
+	}
+	return me.endAuction(tradingPair, state, time.Time{}), true
+}
+
+// GetAuctions 获取进行中的集合竞价及参考撮合价
+func (me *MatchingEngine) GetAuctions() []*AuctionState {
+	me.mu.RLock()
+	defer me.mu.RUnlock()
+
+	result := make([]*AuctionState, 0, len(me.auctions))
+	for tradingPair, state := range me.auctions {
+		info := &AuctionState{
+			TradingPair: tradingPair,
+			Reason:      state.reason,
+			StartedAt:   state.startedAt,
+			EndsAt:      state.endsAt,
+		}
+		if orderBook, exists := me.orderBooks[tradingPair]; exists {
+			orderBook.mu.Lock()
+			if uncross, ok := computeUncross(orderBook); ok {
+				info.IndicativePrice = uncross.price
+				info.MatchableVolume = uncross.volume
+			}
+			orderBook.mu.Unlock()
+		}
+		result = append(result, info)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].TradingPair < result[j].TradingPair })
+	return result
+}
+
+// inAuction 交易对是否处于集合竞价（调用方持有锁）
+func (me *MatchingEngine) inAuction(tradingPair string) bool {
+	return me.auctions[tradingPair] != nil
+}
+
+// endAuction 撮合并恢复连续交易
+// state 已结束、或定时结束时竞价已被延长（deadline 不一致）时不做处理；deadline 为零值表示立即结束
+func (me *MatchingEngine) endAuction(tradingPair string, state *auction, deadline time.Time) []*types.Fill {
+	me.mu.Lock()
+	if me.auctions[tradingPair] != state || (!deadline.IsZero() && !state.endsAt.Equal(deadline)) {
+		me.mu.Unlock()
+		return nil
+	}
+	// 紧急停机期间不撮合，竞价顺延到恢复之后
+	if me.halted.Load() {
+		state.endsAt = time.Now().Add(haltedAuctionRetry)
+		next := state.endsAt
+		state.timer = time.AfterFunc(haltedAuctionRetry, func() { me.endAuction(tradingPair, state, next) })
+		me.mu.Unlock()
+		return nil
+	}
+	delete(me.auctions, tradingPair)
+	state.timer.Stop()
+
+	var fills []*types.Fill
+	var price decimal.Decimal
+	if orderBook, exists := me.orderBooks[tradingPair]; exists {
+		if uncross, ok := computeUncross(orderBook); ok {
+			price = uncross.price
+			fills = me.executeUncross(orderBook, uncross)
+		}
+		orderBook.publishSnapshot()
+	}
+	handler := me.onPairStatus
+	me.mu.Unlock()
+
+	me.logger.WithFields(logrus.Fields{
+		"trading_pair": tradingPair,
+		"price":        price.String(),
+		"fills":        len(fills),
+	}).Info("🔔 Call auction uncrossed")
+
+	if handler != nil {
+		handler(&types.PairStatusUpdate{
+			TradingPair: tradingPair,
+			Status:      types.PairStatusTrading,
+			Reason:      "auction_uncrossed",
+			Timestamp:   time.Now(),
+		})
+	}
+	return fills
+}
+
+// computeUncross 计算集合竞价撮合价
+// 选取可成交量最大的价格；相同时取买卖不平衡量最小者，再相同时取最接近最新成交价者（无成交价时取候选价格的中间值）
+func computeUncross(orderBook *OrderBook) (uncrossResult, bool) {
+	candidates := make(map[string]decimal.Decimal)
+	for _, queue := range orderBook.Bids.Levels() {
+		candidates[queue.Price.String()] = queue.Price
+	}
+	for _, queue := range orderBook.Asks.Levels() {
+		candidates[queue.Price.String()] = queue.Price
+	}
+
+	var best []uncrossResult
+	for _, price := range candidates {
+		demand := decimal.Zero
+		for _, queue := range orderBook.Bids.Levels() {
+			if queue.Price.GreaterThanOrEqual(price) {
+				demand = demand.Add(queue.Total)
+			}
+		}
+		supply := decimal.Zero
+		for _, queue := range orderBook.Asks.Levels() {
+			if queue.Price.LessThanOrEqual(price) {
+				supply = supply.Add(queue.Total)
+			}
+		}
+
+		result := uncrossResult{price: price, volume: decimal.Min(demand, supply), imbalance: demand.Sub(supply).Abs()}
+		if !result.volume.IsPositive() {
+			continue
+		}
+		switch {
+		case len(best) == 0 || result.volume.GreaterThan(best[0].volume):
+			best = []uncrossResult{result}
+		case result.volume.Equal(best[0].volume):
+			if result.imbalance.LessThan(best[0].imbalance) {
+				best = []uncrossResult{result}
+			} else if result.imbalance.Equal(best[0].imbalance) {
+				best = append(best, result)
+			}
+		}
+	}
+	if len(best) == 0 {
+		return uncrossResult{}, false
+	}
+
+	sort.Slice(best, func(i, j int) bool { return best[i].price.LessThan(best[j].price) })
+	if orderBook.LastPrice.IsPositive() {
+		closest := best[0]
+		for _, result := range best[1:] {
+			if result.price.Sub(orderBook.LastPrice).Abs().LessThan(closest.price.Sub(orderBook.LastPrice).Abs()) {
+				closest = result
+			}
+		}
+		return closest, true
+	}
+	return best[len(best)/2], true
+}
+
+// executeUncross 按竞价撮合价统一成交并发送事件（调用方持有写锁）
+// 买单按价格从高到低、卖单从低到高，同价按时间优先；后进入订单簿的一方视为 taker
+func (me *MatchingEngine) executeUncross(orderBook *OrderBook, uncross uncrossResult) []*types.Fill {
+	bids := auctionQueue(orderBook.Bids, func(price decimal.Decimal) bool { return price.GreaterThanOrEqual(uncross.price) })
+	asks := auctionQueue(orderBook.Asks, func(price decimal.Decimal) bool { return price.LessThanOrEqual(uncross.price) })
+
+	now := time.Now()
+	var fills []*types.Fill
+	takerFills := make(map[uuid.UUID][]*types.Fill)
+	var takers []*types.Order
+
+	remaining := uncross.volume
+	for len(bids) > 0 && len(asks) > 0 && remaining.IsPositive() {
+		bid, ask := bids[0], asks[0]
+		amount := decimal.Min(remaining, decimal.Min(bid.GetRemainingAmount(), ask.GetRemainingAmount()))
+
+		taker, maker := bid, ask
+		if ask.CreatedAt.After(bid.CreatedAt) {
+			taker, maker = ask, bid
+		}
+		fill := &types.Fill{
+			ID:              uuid.New(),
+			TakerOrderID:    taker.ID,
+			MakerOrderID:    maker.ID,
+			TradingPair:     orderBook.TradingPair,
+			Price:           uncross.price,
+			Amount:          amount,
+			TakerSide:       taker.Side,
+			TransactTime:    now,
+			EngineTimestamp: clock.Now(),
+			CreatedAt:       now,
+
+			TakerUserAddress: taker.UserAddress,
+			MakerUserAddress: maker.UserAddress,
+		}
+		fills = append(fills, fill)
+		if _, exists := takerFills[taker.ID]; !exists {
+			takers = append(takers, taker)
+		}
+		takerFills[taker.ID] = append(takerFills[taker.ID], fill)
+
+		me.fillRestingOrder(orderBook, bid, uncross.price, amount, bid == taker, now)
+		me.fillRestingOrder(orderBook, ask, uncross.price, amount, ask == taker, now)
+		remaining = remaining.Sub(amount)
+
+		if !bid.GetRemainingAmount().IsPositive() {
+			bids = bids[1:]
+		}
+		if !ask.GetRemainingAmount().IsPositive() {
+			asks = asks[1:]
+		}
+	}
+
+	if len(fills) > 0 {
+		orderBook.LastPrice = uncross.price
+		orderBook.LastTradeAt = now
+		orderBook.stats.recordMatches(len(fills), now)
+		me.recordBandTrade(orderBook, uncross.price, now)
+	}
+
+	// 每个 taker 订单一条事件，消费者可与连续撮合一样以事件订单作为 taker
+	for _, taker := range takers {
+		me.publish(&MatchEvent{
+			Type:        EventAuctionUncrossed,
+			TradingPair: orderBook.TradingPair,
+			Order:       snapshotOrder(taker),
+			Fills:       takerFills[taker.ID],
+			Timestamp:   now,
+		})
+	}
+	return fills
+}
+
+// auctionQueue 可在撮合价成交的挂单，按价格优先、时间优先排列
+func auctionQueue(side *PriceLevel, eligible func(price decimal.Decimal) bool) []*types.Order {
+	var orders []*types.Order
+	for _, queue := range side.Levels() {
+		if !eligible(queue.Price) {
+			break
+		}
+		orders = append(orders, queue.Orders...)
+	}
+	return orders
+}
+
+// fillRestingOrder 挂单成交指定数量，完全成交时移出订单簿（调用方持有写锁）
+func (me *MatchingEngine) fillRestingOrder(orderBook *OrderBook, order *types.Order, price, amount decimal.Decimal, taker bool, now time.Time) {
+	side := orderBook.Asks
+	if order.Side == types.OrderSideBuy {
+		side = orderBook.Bids
+	}
+
+	me.applyFill(order, price, amount, taker)
+	order.UpdatedAt = now
+	if queue := side.Get(order.Price); queue != nil {
+		queue.Total = queue.Total.Sub(amount)
+	}
+	orderBook.Sequence++
+
+	if order.GetRemainingAmount().IsZero() {
+		order.Status = types.OrderStatusFilled
+		me.removeOrderFromBook(orderBook, order)
+	} else {
+		order.Status = types.OrderStatusPartiallyFilled
+	}
+}
+
+// addAuctionOrder 竞价期间的订单进入订单簿，市价单被拒绝（调用方持有写锁）
+func (me *MatchingEngine) addAuctionOrder(orderBook *OrderBook, order *types.Order) error {
+	if order.Type == types.OrderTypeMarket {
+		return me.rejectOrder(order, types.StatusReasonAuction, ErrAuctionInProgress)
+	}
+
+	me.addOrderToBook(orderBook, order)
+	me.publish(&MatchEvent{
+		Type:        EventOrderAdded,
+		TradingPair: order.TradingPair,
+		Order:       snapshotOrder(order),
+		Timestamp:   time.Now(),
+	})
+	return nil
+}
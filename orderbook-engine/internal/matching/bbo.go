@@ -0,0 +1,77 @@
+package matching
+
+import (
+	"sort"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"orderbook-engine/internal/types"
+)
+
+// GetBBO 获取交易对的最优买卖价，买卖价与序号取自同一快照，保证一致
+func (me *MatchingEngine) GetBBO(tradingPair string) *types.BBO {
+	snapshot := me.loadSnapshot(tradingPair)
+	if snapshot == nil {
+		return &types.BBO{TradingPair: tradingPair, Timestamp: time.Now()}
+	}
+	return snapshot.bbo(tradingPair)
+}
+
+// GetMidPrice 获取买一卖一中间价及订单簿最近一次变更的时间，任一方为空时返回 false
+func (me *MatchingEngine) GetMidPrice(tradingPair string) (decimal.Decimal, time.Time, bool) {
+	snapshot := me.loadSnapshot(tradingPair)
+	if snapshot == nil || len(snapshot.bids) == 0 || len(snapshot.asks) == 0 {
+		return decimal.Zero, time.Time{}, false
+	}
+	mid := snapshot.bids[0].Price.Add(snapshot.asks[0].Price).Div(decimal.NewFromInt(2))
+	return mid, snapshot.updatedAt, true
+}
+
+// GetAllBBO 获取全部交易对的最优买卖价，按交易对排序
+func (me *MatchingEngine) GetAllBBO() []*types.BBO {
+	quotes := []*types.BBO{}
+	me.books.Range(func(key, value any) bool {
+		if snapshot := value.(*OrderBook).snapshot.Load(); snapshot != nil {
+			quotes = append(quotes, snapshot.bbo(key.(string)))
+		}
+		return true
+	})
+	sort.Slice(quotes, func(i, j int) bool {
+		return quotes[i].TradingPair < quotes[j].TradingPair
+	})
+	return quotes
+}
+
+// bbo 由快照计算最优买卖价、中间价和价差
+func (snapshot *bookSnapshot) bbo(tradingPair string) *types.BBO {
+	quote := &types.BBO{
+		TradingPair: tradingPair,
+		Sequence:    snapshot.sequence,
+		Timestamp:   time.Now(),
+	}
+
+	hasBid, hasAsk := len(snapshot.bids) > 0, len(snapshot.asks) > 0
+	if hasBid {
+		bid := snapshot.bids[0].Price
+		quote.BestBid = &bid
+	}
+	if hasAsk {
+		ask := snapshot.asks[0].Price
+		quote.BestAsk = &ask
+	}
+	if !hasBid || !hasAsk {
+		return quote
+	}
+
+	bid, ask := *quote.BestBid, *quote.BestAsk
+	mid := bid.Add(ask).Div(decimal.NewFromInt(2))
+	spread := ask.Sub(bid)
+	quote.MidPrice = &mid
+	quote.Spread = &spread
+	if mid.IsPositive() {
+		bps := spread.Div(mid).Mul(decimal.NewFromInt(10000)).Round(2)
+		quote.SpreadBps = &bps
+	}
+	return quote
+}
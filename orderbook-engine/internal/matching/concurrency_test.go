@@ -0,0 +1,212 @@
+package matching
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"orderbook-engine/internal/types"
+)
+
+// 并发下单/撤单/读快照压测，作为撮合引擎改动的发布门槛：
+//
+//	go test -race -run TestConcurrentOrderEntry ./internal/matching
+const (
+	concurrentPairs   = 4
+	concurrentWorkers = 8
+	ordersPerWorker   = 400
+)
+
+func TestConcurrentOrderEntry(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	engine := NewMatchingEngine(logger)
+
+	pairs := make([]string, concurrentPairs)
+	for i := range pairs {
+		pairs[i] = fmt.Sprintf("PAIR%d-USDC", i)
+	}
+
+	// 不可丢弃的消费者应收到与 AddOrder 返回值完全一致的成交
+	sub := engine.Subscribe(SubscriptionOptions{Name: "concurrency_test", EventTypes: []string{EventOrderAdded}})
+	eventFills := make(map[uuid.UUID]*types.Fill)
+	consumerDone := make(chan struct{})
+	go func() {
+		defer close(consumerDone)
+		for event := range sub.Events() {
+			for _, fill := range event.Fills {
+				eventFills[fill.ID] = fill
+			}
+		}
+	}()
+
+	var (
+		mu       sync.Mutex
+		orders   = make(map[uuid.UUID]*types.Order)
+		fills    []*types.Fill
+		wg       sync.WaitGroup
+		stop     = make(chan struct{})
+		readerWg sync.WaitGroup
+	)
+
+	// 快照读取方：撮合过程中任意时刻订单簿都不应出现交叉或非正数量
+	for _, pair := range pairs {
+		readerWg.Add(1)
+		go func(pair string) {
+			defer readerWg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				assertSnapshotConsistent(t, engine.GetOrderBook(pair, 50))
+				time.Sleep(100 * time.Microsecond)
+			}
+		}(pair)
+	}
+
+	for w := 0; w < concurrentWorkers; w++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			rng := rand.New(rand.NewSource(int64(worker)))
+			user := fmt.Sprintf("0x%040d", worker)
+			var placed []*types.Order
+
+			for i := 0; i < ordersPerWorker; i++ {
+				// 约五分之一的操作撤销自己较早的订单
+				if len(placed) > 0 && rng.Intn(5) == 0 {
+					target := placed[rng.Intn(len(placed))]
+					engine.CancelOrder(target.ID, target.TradingPair)
+					continue
+				}
+
+				order := randomOrder(rng, user, pairs[rng.Intn(len(pairs))])
+				orderFills, err := engine.AddOrder(order)
+				if errors.Is(err, ErrNoLiquidity) {
+					// 对手方为空时市价单被拒绝
+					continue
+				}
+				if !assert.NoError(t, err) {
+					return
+				}
+
+				mu.Lock()
+				orders[order.ID] = order
+				fills = append(fills, orderFills...)
+				mu.Unlock()
+				placed = append(placed, order)
+			}
+		}(w)
+	}
+
+	wg.Wait()
+	close(stop)
+	readerWg.Wait()
+
+	engine.Unsubscribe(sub)
+	<-consumerDone
+
+	// 无丢失成交：事件流与返回值一致
+	require.Len(t, eventFills, len(fills))
+	filled := make(map[uuid.UUID]decimal.Decimal)
+	for _, fill := range fills {
+		_, exists := eventFills[fill.ID]
+		assert.True(t, exists, "fill %s missing from event stream", fill.ID)
+		assert.True(t, fill.Amount.IsPositive())
+
+		maker, taker := orders[fill.MakerOrderID], orders[fill.TakerOrderID]
+		require.NotNil(t, maker)
+		require.NotNil(t, taker)
+		assert.Equal(t, maker.TradingPair, taker.TradingPair)
+		assert.NotEqual(t, maker.Side, taker.Side)
+		assert.True(t, fill.Price.Equal(maker.Price), "fill must execute at maker price")
+
+		filled[fill.MakerOrderID] = filled[fill.MakerOrderID].Add(fill.Amount)
+		filled[fill.TakerOrderID] = filled[fill.TakerOrderID].Add(fill.Amount)
+	}
+
+	// 订单成交量与成交记录一致，剩余量不为负
+	for id, order := range orders {
+		assert.True(t, order.FilledAmount.Equal(filled[id]), "order %s filled %s but fills sum to %s", id, order.FilledAmount, filled[id])
+		assert.False(t, order.GetRemainingAmount().IsNegative(), "order %s has negative remaining amount", id)
+	}
+
+	// 最终快照与簿内订单剩余量一致
+	for _, pair := range pairs {
+		snapshot := engine.GetOrderBook(pair, 1000)
+		assertSnapshotConsistent(t, snapshot)
+		for _, level := range snapshot.Bids {
+			assertLevelMatchesOrders(t, engine, pair, types.OrderSideBuy, level)
+		}
+		for _, level := range snapshot.Asks {
+			assertLevelMatchesOrders(t, engine, pair, types.OrderSideSell, level)
+		}
+	}
+}
+
+func randomOrder(rng *rand.Rand, user, pair string) *types.Order {
+	side := types.OrderSideBuy
+	if rng.Intn(2) == 0 {
+		side = types.OrderSideSell
+	}
+
+	order := &types.Order{
+		ID:          uuid.New(),
+		UserAddress: user,
+		TradingPair: pair,
+		Side:        side,
+		Type:        types.OrderTypeLimit,
+		Price:       decimal.NewFromInt(int64(1990 + rng.Intn(21))),
+		Amount:      decimal.NewFromInt(int64(1 + rng.Intn(10))).Div(decimal.NewFromInt(4)),
+		Status:      types.OrderStatusPending,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+	if rng.Intn(10) == 0 {
+		order.Type = types.OrderTypeMarket
+		order.Price = decimal.Zero
+	}
+	return order
+}
+
+func assertSnapshotConsistent(t *testing.T, snapshot *types.OrderBookSnapshot) {
+	for i, level := range snapshot.Bids {
+		assert.True(t, level.Amount.IsPositive(), "bid level %s has non-positive amount %s", level.Price, level.Amount)
+		if i > 0 {
+			assert.True(t, level.Price.LessThan(snapshot.Bids[i-1].Price), "bids must be sorted descending")
+		}
+	}
+	for i, level := range snapshot.Asks {
+		assert.True(t, level.Amount.IsPositive(), "ask level %s has non-positive amount %s", level.Price, level.Amount)
+		if i > 0 {
+			assert.True(t, level.Price.GreaterThan(snapshot.Asks[i-1].Price), "asks must be sorted ascending")
+		}
+	}
+	if len(snapshot.Bids) > 0 && len(snapshot.Asks) > 0 {
+		assert.True(t, snapshot.Bids[0].Price.LessThan(snapshot.Asks[0].Price), "book must not be crossed")
+	}
+}
+
+func assertLevelMatchesOrders(t *testing.T, engine *MatchingEngine, pair string, side types.OrderSide, level types.OrderBookLevel) {
+	orders := engine.GetOrdersAtPrice(pair, side, level.Price)
+	require.Len(t, orders, level.Count)
+
+	total := decimal.Zero
+	for _, order := range orders {
+		assert.True(t, order.IsActive())
+		total = total.Add(order.GetRemainingAmount())
+	}
+	assert.True(t, total.Equal(level.Amount), "level %s total %s != resting %s", level.Price, level.Amount, total)
+}
@@ -0,0 +1,53 @@
+package matching
+
+import (
+	"errors"
+
+	"orderbook-engine/internal/types"
+)
+
+// ErrDraining 引擎排空中，不再接受新订单
+var ErrDraining = errors.New("matching engine is draining")
+
+// StopAccepting 停止接受新订单（撤单、过期清理不受影响）
+// 返回时正在撮合的订单均已处理完毕，之后不会再产生新的成交
+func (me *MatchingEngine) StopAccepting() {
+	me.draining.Store(true)
+
+	// 撮合期间持有引擎读锁，获取一次写锁即可等待在途撮合结束
+	me.mu.Lock()
+	me.mu.Unlock()
+}
+
+// Draining 引擎是否处于排空状态
+func (me *MatchingEngine) Draining() bool {
+	return me.draining.Load()
+}
+
+// rejectWhileDraining 排空期间拒绝新订单（调用方持有订单簿锁）
+func (me *MatchingEngine) rejectWhileDraining(order *types.Order) error {
+	if !me.draining.Load() {
+		return nil
+	}
+	return me.rejectOrder(order, types.StatusReasonDraining, ErrDraining)
+}
+
+// OpenOrders 获取全部挂单的快照，按交易对、买卖方向和价格时间优先顺序排列
+func (me *MatchingEngine) OpenOrders() []*types.Order {
+	var orders []*types.Order
+	for _, pair := range me.TradingPairs() {
+		me.mu.RLock()
+		orderBook := me.orderBooks[pair]
+		orderBook.mu.Lock()
+		for _, side := range []*PriceLevel{orderBook.Bids, orderBook.Asks} {
+			for _, queue := range side.Levels() {
+				for _, order := range queue.Orders {
+					orders = append(orders, snapshotOrder(order))
+				}
+			}
+		}
+		orderBook.mu.Unlock()
+		me.mu.RUnlock()
+	}
+	return orders
+}
@@ -1,22 +1,56 @@
 package matching
 
 import (
-	"container/heap"
+	"errors"
+	"sort"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"orderbook-engine/internal/clock"
 	"orderbook-engine/internal/types"
 	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
 	"github.com/sirupsen/logrus"
 )
 
+// ErrPairHalted 交易对暂停撮合
+var ErrPairHalted = errors.New("trading pair halted")
+
+// TradingGate 交易闸门（熔断等）
+// AllowTaker 返回错误时拒绝会立即成交的订单，挂单不受影响
+type TradingGate interface {
+	AllowTaker(tradingPair string) error
+}
+
 // MatchingEngine 撮合引擎
+// 下单、撤单与单个订单簿的查询持有引擎读锁及该订单簿的锁，不同交易对可并行撮合；
+// 跨订单簿的清理、集合竞价及配置修改持有引擎写锁；
+// 订单簿快照与最优买卖价读取不可变快照，不获取任何锁
 type MatchingEngine struct {
-	mu          sync.RWMutex
-	orderBooks  map[string]*OrderBook
-	eventChan   chan *MatchEvent
-	logger      *logrus.Logger
+	mu           sync.RWMutex
+	orderBooks   map[string]*OrderBook
+	books        sync.Map // 交易对 -> *OrderBook，供免锁读路径查找（订单簿创建后不删除）
+	events       eventBus
+	gates        []TradingGate
+	accountGates []AccountGate
+	orderGates   []OrderGate
+	resting      RestingPolicy // 挂单最短停留时间，为空时不限制
+	usersMu      sync.Mutex
+	userOrders   map[string]int // 用户地址(小写) -> 挂单数量，由 usersMu 保护
+	signatureTTL time.Duration  // 签名最长有效期，0表示不限制
+	logger       *logrus.Logger
+
+	minMarketLiquidity decimal.Decimal     // 市价单要求的对手方最小挂单名义价值
+	fees               FeeSchedule         // 订单成交汇总使用的手续费率
+	priceBands         PriceBands          // 限价偏离参考价的上限
+	auctions           map[string]*auction // 处于集合竞价的交易对
+	expiries           *expiryScheduler    // 带 ExpiresAt 的挂单到期队列
+	onPairStatus       func(update *types.PairStatusUpdate)
+	draining           atomic.Bool // 排空中，不再接受新订单
+	standby            atomic.Bool // 备用实例，不接受新订单也不发布事件
+	halted             atomic.Bool // 紧急停机，不接受新订单也不撮合集合竞价
 }
 
 // MatchEvent 撮合事件
@@ -30,113 +64,177 @@ type MatchEvent struct {
 
 // OrderBook 单个交易对的订单簿
 type OrderBook struct {
+	mu          sync.Mutex // 单写者锁，持有引擎读锁时才可获取
 	TradingPair string
 	Bids        *PriceLevel // 买单队列（最高价优先）
 	Asks        *PriceLevel // 卖单队列（最低价优先）
 	Orders      map[uuid.UUID]*types.Order
-}
-
-// PriceLevel 价格层级（使用堆实现优先队列）
-type PriceLevel struct {
-	levels map[string]*PriceLevelQueue // price -> queue
-	heap   PriceHeap
-	isBuy  bool
-}
-
-// PriceLevelQueue 同一价格的订单队列（价格-时间优先）
-type PriceLevelQueue struct {
-	Price     decimal.Decimal
-	Orders    []*types.Order // 按时间顺序排列（FIFO）
-	Total     decimal.Decimal
-	OrdersMap map[uuid.UUID]*types.Order // 快速查找
-}
-
-// PriceHeap 价格堆（买单降序，卖单升序）
-type PriceHeap struct {
-	items []PriceLevelItem
-	isBuy bool
-}
+	LastPrice   decimal.Decimal // 最新成交价
+	LastTradeAt time.Time       // 最新成交时间
+	Sequence    uint64          // 订单簿变更序号，每次挂单、撤单或成交后递增
 
-type PriceLevelItem struct {
-	Price decimal.Decimal
-	Total decimal.Decimal
+	snapshot atomic.Pointer[bookSnapshot] // 最近一批变更后的不可变快照
+	band     bandTracker                  // 动态价格带状态
+	stats    bookStats                    // 成交速率与排队深度统计
 }
 
 // NewMatchingEngine 创建撮合引擎
 func NewMatchingEngine(logger *logrus.Logger) *MatchingEngine {
 	return &MatchingEngine{
 		orderBooks: make(map[string]*OrderBook),
-		eventChan:  make(chan *MatchEvent, 10000),
+		userOrders: make(map[string]int),
 		logger:     logger,
+
+		auctions: make(map[string]*auction),
+		expiries: newExpiryScheduler(),
 	}
 }
 
-// GetEventChannel 获取事件通道
-func (me *MatchingEngine) GetEventChannel() <-chan *MatchEvent {
-	return me.eventChan
+// EventQueueDepth 获取不可丢弃消费者中最大的事件积压（用于过载检测），含溢出队列中等待转发的事件
+func (me *MatchingEngine) EventQueueDepth() int {
+	me.events.mu.RLock()
+	defer me.events.mu.RUnlock()
+
+	depth := 0
+	for _, sub := range me.events.subs {
+		if sub.dropOnFull {
+			continue
+		}
+		if backlog := len(sub.ch) + sub.overflowDepth(); backlog > depth {
+			depth = backlog
+		}
+	}
+	return depth
 }
 
-// AddOrder 添加订单
-func (me *MatchingEngine) AddOrder(order *types.Order) []*types.Fill {
+// AddTradingGate 注册交易闸门
+func (me *MatchingEngine) AddTradingGate(gate TradingGate) {
 	me.mu.Lock()
 	defer me.mu.Unlock()
+	me.gates = append(me.gates, gate)
+}
+
+// AddOrder 添加订单
+// 引擎排空、备用或紧急停机中、订单被交易闸门、账户闸门或订单闸门拒绝、限价超出价格带、签名超过有效期或市价单没有对手方流动性时返回错误，订单状态置为 rejected
+func (me *MatchingEngine) AddOrder(order *types.Order) ([]*types.Fill, error) {
+	orderBook := me.lockBook(order.TradingPair)
+	defer me.unlockBook(orderBook)
+
+	if err := me.rejectWhileDraining(order); err != nil {
+		return nil, err
+	}
+	if err := me.rejectWhileStandby(order); err != nil {
+		return nil, err
+	}
+	if err := me.rejectWhileHalted(order); err != nil {
+		return nil, err
+	}
+	if err := me.rejectExpiredSignature(order); err != nil {
+		return nil, err
+	}
+	if err := me.rejectBlockedAccount(order); err != nil {
+		return nil, err
+	}
+	if err := me.rejectGatedOrder(order); err != nil {
+		return nil, err
+	}
+	if err := me.rejectOutOfBandOrder(orderBook, order); err != nil {
+		return nil, err
+	}
+
+	// 集合竞价期间限价单只挂单不撮合
+	if me.inAuction(order.TradingPair) {
+		return nil, me.addAuctionOrder(orderBook, order)
+	}
+
+	if err := me.rejectIlliquidMarketOrder(orderBook, order); err != nil {
+		return nil, err
+	}
+
+	if me.wouldCross(orderBook, order) {
+		for _, gate := range me.gates {
+			if err := gate.AllowTaker(order.TradingPair); err != nil {
+				return nil, me.rejectOrder(order, types.StatusReasonPairHalted, err)
+			}
+		}
+	}
 
-	orderBook := me.getOrCreateOrderBook(order.TradingPair)
 	fills := me.matchOrder(orderBook, order)
 
 	if order.GetRemainingAmount().GreaterThan(decimal.Zero) && order.Type == types.OrderTypeLimit {
 		me.addOrderToBook(orderBook, order)
 	}
 
+	// 市价单不挂单：对手方耗尽后剩余部分撤销
+	if order.Type == types.OrderTypeMarket && order.GetRemainingAmount().IsPositive() && order.Status != types.OrderStatusCancelled {
+		order.Status = types.OrderStatusCancelled
+		order.StatusReason = types.StatusReasonNoLiquidity
+		order.UpdatedAt = time.Now()
+	}
+
 	// 发送事件
-	me.eventChan <- &MatchEvent{
-		Type:        "order_added",
+	me.publish(&MatchEvent{
+		Type:        EventOrderAdded,
 		TradingPair: order.TradingPair,
-		Order:       order,
+		Order:       snapshotOrder(order),
 		Fills:       fills,
 		Timestamp:   time.Now(),
-	}
+	})
 
-	return fills
+	return fills, nil
 }
 
 // CancelOrder 取消订单
 func (me *MatchingEngine) CancelOrder(orderID uuid.UUID, tradingPair string) bool {
-	me.mu.Lock()
-	defer me.mu.Unlock()
+	_, ok := me.CancelOrderWithReason(orderID, tradingPair, "")
+	return ok
+}
+
+// CancelOrderWithReason 取消订单并记录原因代码，返回撤销后的订单快照，挂单不存在时返回 false
+func (me *MatchingEngine) CancelOrderWithReason(orderID uuid.UUID, tradingPair, reason string) (*types.Order, bool) {
+	me.mu.RLock()
+	defer me.mu.RUnlock()
 
 	orderBook, exists := me.orderBooks[tradingPair]
 	if !exists {
-		return false
+		return nil, false
 	}
+	orderBook.mu.Lock()
+	defer orderBook.unlock()
 
 	order, exists := orderBook.Orders[orderID]
 	if !exists {
-		return false
+		return nil, false
 	}
+	return me.cancelLocked(orderBook, order, reason), true
+}
 
+// cancelLocked 将挂单移出订单簿并发布撤单事件，返回撤销后的订单快照（调用方持有订单簿锁）
+func (me *MatchingEngine) cancelLocked(orderBook *OrderBook, order *types.Order, reason string) *types.Order {
 	me.removeOrderFromBook(orderBook, order)
 	order.Status = types.OrderStatusCancelled
+	if reason != "" {
+		order.StatusReason = reason
+	}
 	order.UpdatedAt = time.Now()
 
 	// 发送事件
-	me.eventChan <- &MatchEvent{
-		Type:        "order_cancelled",
-		TradingPair: tradingPair,
-		Order:       order,
+	snapshot := snapshotOrder(order)
+	me.publish(&MatchEvent{
+		Type:        EventOrderCancelled,
+		TradingPair: orderBook.TradingPair,
+		Order:       snapshot,
 		Timestamp:   time.Now(),
-	}
+	})
 
-	return true
+	return snapshot
 }
 
 // GetOrderBook 获取订单簿快照
+// 读取最近一批变更后发布的不可变快照，不获取引擎锁或订单簿锁，可供高频轮询与推送
 func (me *MatchingEngine) GetOrderBook(tradingPair string, depth int) *types.OrderBookSnapshot {
-	me.mu.RLock()
-	defer me.mu.RUnlock()
-
-	orderBook, exists := me.orderBooks[tradingPair]
-	if !exists {
+	snapshot := me.loadSnapshot(tradingPair)
+	if snapshot == nil {
 		return &types.OrderBookSnapshot{
 			TradingPair: tradingPair,
 			Bids:        []types.OrderBookLevel{},
@@ -144,13 +242,20 @@ func (me *MatchingEngine) GetOrderBook(tradingPair string, depth int) *types.Ord
 			Timestamp:   time.Now(),
 		}
 	}
+	return snapshot.orderBook(tradingPair, depth)
+}
 
-	return &types.OrderBookSnapshot{
-		TradingPair: tradingPair,
-		Bids:        me.getPriceLevels(orderBook.Bids, depth),
-		Asks:        me.getPriceLevels(orderBook.Asks, depth),
-		Timestamp:   time.Now(),
+// TradingPairs 获取已有订单簿的交易对，按名称排序
+func (me *MatchingEngine) TradingPairs() []string {
+	me.mu.RLock()
+	defer me.mu.RUnlock()
+
+	pairs := make([]string, 0, len(me.orderBooks))
+	for pair := range me.orderBooks {
+		pairs = append(pairs, pair)
 	}
+	sort.Strings(pairs)
+	return pairs
 }
 
 // matchOrder 撮合订单
@@ -164,39 +269,89 @@ func (me *MatchingEngine) matchOrder(orderBook *OrderBook, takerOrder *types.Ord
 		targetSide = orderBook.Bids
 	}
 
-	for takerOrder.GetRemainingAmount().GreaterThan(decimal.Zero) && targetSide.heap.Len() > 0 {
-		bestPrice := targetSide.heap.Peek()
-		if !me.canMatch(takerOrder, bestPrice.Price) {
+	// 市价单滑点保护
+	var limit decimal.Decimal
+	var protected bool
+	if best := targetSide.Best(); best != nil {
+		limit, protected = protectionPrice(takerOrder, best.Price)
+	}
+
+	for takerOrder.GetRemainingAmount().GreaterThan(decimal.Zero) && targetSide.Len() > 0 {
+		queue := targetSide.Best()
+		if !me.canMatch(takerOrder, queue.Price) {
+			break
+		}
+		if protected && !withinProtection(takerOrder.Side, queue.Price, limit) {
+			// 超出保护价，剩余部分撤销
+			takerOrder.Status = types.OrderStatusCancelled
+			takerOrder.StatusReason = types.StatusReasonSlippageLimit
+			takerOrder.UpdatedAt = time.Now()
+			me.logger.WithFields(logrus.Fields{
+				"order_id":         takerOrder.ID.String(),
+				"trading_pair":     takerOrder.TradingPair,
+				"protection_price": limit.String(),
+				"next_price":       queue.Price.String(),
+				"filled":           takerOrder.FilledAmount.String(),
+			}).Info("Market order stopped by slippage protection")
 			break
 		}
+		if takerOrder.Type == types.OrderTypeMarket {
+			// 市价单不越过当前价格带成交，剩余部分撤销
+			if band, ok := me.currentBand(orderBook, time.Now()); ok && !band.withinBand(queue.Price) {
+				takerOrder.Status = types.OrderStatusCancelled
+				takerOrder.StatusReason = types.StatusReasonPriceOutOfBand
+				takerOrder.UpdatedAt = time.Now()
+				me.logger.WithFields(logrus.Fields{
+					"order_id":     takerOrder.ID.String(),
+					"trading_pair": takerOrder.TradingPair,
+					"lower_price":  band.LowerPrice.String(),
+					"upper_price":  band.UpperPrice.String(),
+					"next_price":   queue.Price.String(),
+					"filled":       takerOrder.FilledAmount.String(),
+				}).Info("Market order stopped by price band")
+				break
+			}
+		}
 
-		queue := targetSide.levels[bestPrice.Price.String()]
-		if queue == nil || len(queue.Orders) == 0 {
-			heap.Pop(&targetSide.heap)
+		makerOrder := queue.Orders[0]
+		// 惰性检查：过期挂单不参与撮合
+		if makerOrder.IsExpired() {
+			me.expireOrder(orderBook, makerOrder, time.Now())
 			continue
 		}
 
-		makerOrder := queue.Orders[0]
 		matchPrice := makerOrder.Price
 		matchAmount := decimal.Min(takerOrder.GetRemainingAmount(), makerOrder.GetRemainingAmount())
 
-		// 创建成交记录
+		// 创建成交记录，同时记录撮合时刻的墙上时间与引擎时间戳
+		transactTime := time.Now()
 		fill := &types.Fill{
-			ID:           uuid.New(),
-			TakerOrderID: takerOrder.ID,
-			MakerOrderID: makerOrder.ID,
-			TradingPair:  takerOrder.TradingPair,
-			Price:        matchPrice,
-			Amount:       matchAmount,
-			TakerSide:    takerOrder.Side,
-			CreatedAt:    time.Now(),
+			ID:              uuid.New(),
+			TakerOrderID:    takerOrder.ID,
+			MakerOrderID:    makerOrder.ID,
+			TradingPair:     takerOrder.TradingPair,
+			Price:           matchPrice,
+			Amount:          matchAmount,
+			TakerSide:       takerOrder.Side,
+			TransactTime:    transactTime,
+			EngineTimestamp: clock.Now(),
+			CreatedAt:       transactTime,
+
+			TakerUserAddress: takerOrder.UserAddress,
+			MakerUserAddress: makerOrder.UserAddress,
 		}
 
 		fills = append(fills, fill)
+		orderBook.LastPrice = matchPrice
+		orderBook.LastTradeAt = fill.CreatedAt
+		orderBook.stats.recordMatches(1, fill.CreatedAt)
+		me.recordBandTrade(orderBook, matchPrice, fill.CreatedAt)
 
 		// 更新订单状态
-		takerOrder.FilledAmount = takerOrder.FilledAmount.Add(matchAmount)
-		makerOrder.FilledAmount = makerOrder.FilledAmount.Add(matchAmount)
+		me.applyFill(takerOrder, matchPrice, matchAmount, true)
+		me.applyFill(makerOrder, matchPrice, matchAmount, false)
+		queue.Total = queue.Total.Sub(matchAmount)
+		orderBook.Sequence++
 
 		if takerOrder.GetRemainingAmount().IsZero() {
 			takerOrder.Status = types.OrderStatusFilled
@@ -214,18 +369,33 @@ func (me *MatchingEngine) matchOrder(orderBook *OrderBook, takerOrder *types.Ord
 		takerOrder.UpdatedAt = time.Now()
 		makerOrder.UpdatedAt = time.Now()
 
-		me.logger.WithFields(logrus.Fields{
-			"trading_pair": takerOrder.TradingPair,
-			"price":        matchPrice.String(),
-			"amount":       matchAmount.String(),
-			"taker_id":     takerOrder.ID.String(),
-			"maker_id":     makerOrder.ID.String(),
-		}).Info("Order matched")
+		if me.logger.IsLevelEnabled(logrus.InfoLevel) {
+			me.logger.WithFields(logrus.Fields{
+				"trading_pair": takerOrder.TradingPair,
+				"price":        matchPrice.String(),
+				"amount":       matchAmount.String(),
+				"taker_id":     takerOrder.ID.String(),
+				"maker_id":     makerOrder.ID.String(),
+			}).Info("Order matched")
+		}
 	}
 
 	return fills
 }
 
+// wouldCross 检查订单是否会与对手盘立即成交（即作为taker）
+func (me *MatchingEngine) wouldCross(orderBook *OrderBook, order *types.Order) bool {
+	targetSide := orderBook.Asks
+	if order.Side == types.OrderSideSell {
+		targetSide = orderBook.Bids
+	}
+	best := targetSide.Best()
+	if best == nil {
+		return false
+	}
+	return me.canMatch(order, best.Price)
+}
+
 // canMatch 检查订单是否可以撮合
 func (me *MatchingEngine) canMatch(order *types.Order, price decimal.Decimal) bool {
 	if order.Type == types.OrderTypeMarket {
@@ -238,9 +408,41 @@ func (me *MatchingEngine) canMatch(order *types.Order, price decimal.Decimal) bo
 	return order.Price.LessThanOrEqual(price)
 }
 
+// ActiveOrderCount 获取用户在订单簿中的挂单数量
+func (me *MatchingEngine) ActiveOrderCount(userAddress string) int {
+	me.usersMu.Lock()
+	defer me.usersMu.Unlock()
+	return me.userOrders[strings.ToLower(userAddress)]
+}
+
+// GetUserOrders 获取用户在订单簿中的挂单快照
+func (me *MatchingEngine) GetUserOrders(userAddress string) []*types.Order {
+	me.mu.RLock()
+	defer me.mu.RUnlock()
+
+	if me.ActiveOrderCount(userAddress) == 0 {
+		return nil
+	}
+
+	var result []*types.Order
+	for _, orderBook := range me.orderBooks {
+		orderBook.mu.Lock()
+		for _, order := range orderBook.Orders {
+			if strings.EqualFold(order.UserAddress, userAddress) {
+				result = append(result, snapshotOrder(order))
+			}
+		}
+		orderBook.mu.Unlock()
+	}
+	return result
+}
+
 // addOrderToBook 将订单添加到订单簿（价格-时间优先）
 func (me *MatchingEngine) addOrderToBook(orderBook *OrderBook, order *types.Order) {
 	orderBook.Orders[order.ID] = order
+	me.usersMu.Lock()
+	me.userOrders[strings.ToLower(order.UserAddress)]++
+	me.usersMu.Unlock()
 
 	var targetSide *PriceLevel
 	if order.Side == types.OrderSideBuy {
@@ -249,40 +451,35 @@ func (me *MatchingEngine) addOrderToBook(orderBook *OrderBook, order *types.Orde
 		targetSide = orderBook.Asks
 	}
 
-	priceStr := order.Price.String()
-	queue, exists := targetSide.levels[priceStr]
-	if !exists {
-		queue = &PriceLevelQueue{
-			Price:     order.Price,
-			Orders:    []*types.Order{},
-			Total:     decimal.Zero,
-			OrdersMap: make(map[uuid.UUID]*types.Order),
-		}
-		targetSide.levels[priceStr] = queue
-		heap.Push(&targetSide.heap, PriceLevelItem{
-			Price: order.Price,
-			Total: decimal.Zero,
-		})
-	}
-
 	// 按时间顺序添加（FIFO）
-	queue.Orders = append(queue.Orders, order)
-	queue.OrdersMap[order.ID] = order
-	queue.Total = queue.Total.Add(order.GetRemainingAmount())
+	targetSide.addOrder(order)
 	order.Status = types.OrderStatusOpen
+	orderBook.Sequence++
+	me.expiries.schedule(order)
 
-	me.logger.WithFields(logrus.Fields{
-		"order_id":     order.ID.String(),
-		"price":        order.Price.String(),
-		"side":         order.Side,
-		"trading_pair": order.TradingPair,
-		"timestamp":    order.CreatedAt,
-	}).Debug("Added order to book with price-time priority")
+	if me.logger.IsLevelEnabled(logrus.DebugLevel) {
+		me.logger.WithFields(logrus.Fields{
+			"order_id":     order.ID.String(),
+			"price":        order.Price.String(),
+			"side":         order.Side,
+			"trading_pair": order.TradingPair,
+			"timestamp":    order.CreatedAt,
+		}).Debug("Added order to book with price-time priority")
+	}
 }
 
 // removeOrderFromBook 从订单簿移除订单
 func (me *MatchingEngine) removeOrderFromBook(orderBook *OrderBook, order *types.Order) {
+	if _, exists := orderBook.Orders[order.ID]; exists {
+		user := strings.ToLower(order.UserAddress)
+		me.usersMu.Lock()
+		if me.userOrders[user]--; me.userOrders[user] <= 0 {
+			delete(me.userOrders, user)
+		}
+		me.usersMu.Unlock()
+	}
 	delete(orderBook.Orders, order.ID)
+	me.expiries.unschedule(order.ID)
 
 	var targetSide *PriceLevel
 	if order.Side == types.OrderSideBuy {
@@ -291,26 +488,31 @@ func (me *MatchingEngine) removeOrderFromBook(orderBook *OrderBook, order *types
 		targetSide = orderBook.Asks
 	}
 
-	priceStr := order.Price.String()
-	queue, exists := targetSide.levels[priceStr]
-	if !exists {
-		return
-	}
+	// 从队列中移除订单，队列为空时移除价格层级
+	targetSide.removeOrder(order)
+	orderBook.Sequence++
+}
 
-	// 从队列中移除订单
-	for i, o := range queue.Orders {
-		if o.ID == order.ID {
-			queue.Orders = append(queue.Orders[:i], queue.Orders[i+1:]...)
-			queue.Total = queue.Total.Sub(o.GetRemainingAmount())
-			break
-		}
+// lockBook 获取引擎读锁及交易对订单簿的锁，订单簿不存在时先创建
+func (me *MatchingEngine) lockBook(tradingPair string) *OrderBook {
+	me.mu.RLock()
+	orderBook, exists := me.orderBooks[tradingPair]
+	if !exists {
+		me.mu.RUnlock()
+		me.mu.Lock()
+		orderBook = me.getOrCreateOrderBook(tradingPair)
+		me.mu.Unlock()
+		// 订单簿创建后不会被删除，重新获取读锁后指针仍然有效
+		me.mu.RLock()
 	}
+	orderBook.mu.Lock()
+	return orderBook
+}
 
-	// 如果队列为空，从堆中移除
-	if len(queue.Orders) == 0 {
-		delete(targetSide.levels, priceStr)
-		targetSide.heap.Remove(order.Price)
-	}
+// unlockBook 发布订单簿快照并释放 lockBook 获取的锁
+func (me *MatchingEngine) unlockBook(orderBook *OrderBook) {
+	orderBook.unlock()
+	me.mu.RUnlock()
 }
 
 // getOrCreateOrderBook 获取或创建订单簿
@@ -319,50 +521,17 @@ func (me *MatchingEngine) getOrCreateOrderBook(tradingPair string) *OrderBook {
 	if !exists {
 		orderBook = &OrderBook{
 			TradingPair: tradingPair,
-			Bids: &PriceLevel{
-				levels: make(map[string]*PriceLevelQueue),
-				heap:   PriceHeap{isBuy: true},
-				isBuy:  true,
-			},
-			Asks: &PriceLevel{
-				levels: make(map[string]*PriceLevelQueue),
-				heap:   PriceHeap{isBuy: false},
-				isBuy:  false,
-			},
-			Orders: make(map[uuid.UUID]*types.Order),
+			Bids:        newPriceLevel(true),
+			Asks:        newPriceLevel(false),
+			Orders:      make(map[uuid.UUID]*types.Order),
 		}
+		orderBook.publishSnapshot()
 		me.orderBooks[tradingPair] = orderBook
+		me.books.Store(tradingPair, orderBook)
 	}
 	return orderBook
 }
 
-// getPriceLevels 获取价格层级
-func (me *MatchingEngine) getPriceLevels(priceLevel *PriceLevel, depth int) []types.OrderBookLevel {
-	var levels []types.OrderBookLevel
-	
-	heap := make([]PriceLevelItem, len(priceLevel.heap.items))
-	copy(heap, priceLevel.heap.items)
-	
-	count := 0
-	for _, item := range heap {
-		if count >= depth {
-			break
-		}
-		
-		queue := priceLevel.levels[item.Price.String()]
-		if queue != nil && len(queue.Orders) > 0 {
-			levels = append(levels, types.OrderBookLevel{
-				Price:  item.Price,
-				Amount: queue.Total,
-				Count:  len(queue.Orders),
-			})
-			count++
-		}
-	}
-	
-	return levels
-}
-
 // GetBestPrice 获取最佳价格（价格优先）
 func (me *MatchingEngine) GetBestPrice(tradingPair string, side types.OrderSide) (decimal.Decimal, bool) {
 	me.mu.RLock()
@@ -372,20 +541,43 @@ func (me *MatchingEngine) GetBestPrice(tradingPair string, side types.OrderSide)
 	if !exists {
 		return decimal.Zero, false
 	}
+	orderBook.mu.Lock()
+	defer orderBook.mu.Unlock()
 
-	var targetSide *PriceLevel
+	return orderBook.bestPrice(side)
+}
+
+// bestPrice 本方最优价格（调用方持有订单簿锁）
+func (orderBook *OrderBook) bestPrice(side types.OrderSide) (decimal.Decimal, bool) {
+	targetSide := orderBook.Asks
 	if side == types.OrderSideBuy {
 		targetSide = orderBook.Bids
-	} else {
-		targetSide = orderBook.Asks
 	}
 
-	if targetSide.heap.Len() == 0 {
+	best := targetSide.Best()
+	if best == nil {
 		return decimal.Zero, false
 	}
+	return best.Price, true
+}
+
+// GetLastTradePrice 获取最新成交价及成交时间
+func (me *MatchingEngine) GetLastTradePrice(tradingPair string) (decimal.Decimal, time.Time, bool) {
+	me.mu.RLock()
+	defer me.mu.RUnlock()
+
+	orderBook, exists := me.orderBooks[tradingPair]
+	if !exists {
+		return decimal.Zero, time.Time{}, false
+	}
+	orderBook.mu.Lock()
+	defer orderBook.mu.Unlock()
+
+	if orderBook.LastTradeAt.IsZero() {
+		return decimal.Zero, time.Time{}, false
+	}
 
-	bestPrice := targetSide.heap.Peek()
-	return bestPrice.Price, true
+	return orderBook.LastPrice, orderBook.LastTradeAt, true
 }
 
 // GetOrdersAtPrice 获取指定价格的所有订单（按时间顺序）
@@ -397,6 +589,8 @@ func (me *MatchingEngine) GetOrdersAtPrice(tradingPair string, side types.OrderS
 	if !exists {
 		return nil
 	}
+	orderBook.mu.Lock()
+	defer orderBook.mu.Unlock()
 
 	var targetSide *PriceLevel
 	if side == types.OrderSideBuy {
@@ -405,9 +599,8 @@ func (me *MatchingEngine) GetOrdersAtPrice(tradingPair string, side types.OrderS
 		targetSide = orderBook.Asks
 	}
 
-	priceStr := price.String()
-	queue, exists := targetSide.levels[priceStr]
-	if !exists {
+	queue := targetSide.Get(price)
+	if queue == nil {
 		return nil
 	}
 
@@ -416,45 +609,3 @@ func (me *MatchingEngine) GetOrdersAtPrice(tradingPair string, side types.OrderS
 	copy(orders, queue.Orders)
 	return orders
 }
-
-// PriceHeap 实现 heap.Interface
-func (h PriceHeap) Len() int { return len(h.items) }
-
-func (h PriceHeap) Less(i, j int) bool {
-	if h.isBuy {
-		return h.items[i].Price.GreaterThan(h.items[j].Price) // 买单：价格降序
-	}
-	return h.items[i].Price.LessThan(h.items[j].Price) // 卖单：价格升序
-}
-
-func (h PriceHeap) Swap(i, j int) {
-	h.items[i], h.items[j] = h.items[j], h.items[i]
-}
-
-func (h *PriceHeap) Push(x interface{}) {
-	h.items = append(h.items, x.(PriceLevelItem))
-}
-
-func (h *PriceHeap) Pop() interface{} {
-	old := h.items
-	n := len(old)
-	item := old[n-1]
-	h.items = old[0 : n-1]
-	return item
-}
-
-func (h *PriceHeap) Peek() PriceLevelItem {
-	if len(h.items) == 0 {
-		return PriceLevelItem{}
-	}
-	return h.items[0]
-}
-
-func (h *PriceHeap) Remove(price decimal.Decimal) {
-	for i, item := range h.items {
-		if item.Price.Equal(price) {
-			heap.Remove(h, i)
-			break
-		}
-	}
-}
\ No newline at end of file
@@ -1,6 +1,10 @@
 package matching
 
 import (
+	"fmt"
+	"os"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -41,7 +45,8 @@ func TestAddOrder(t *testing.T) {
 
 	// 测试添加买单
 	buyOrder := createTestOrder(types.OrderSideBuy, 2000, 1)
-	fills := engine.AddOrder(buyOrder)
+	fills, err := engine.AddOrder(buyOrder)
+	require.NoError(t, err)
 	
 	assert.Empty(t, fills, "买单应该被添加到订单簿，没有成交")
 	assert.Equal(t, types.OrderStatusOpen, buyOrder.Status)
@@ -52,12 +57,15 @@ func TestOrderMatching(t *testing.T) {
 
 	// 添加买单
 	buyOrder := createTestOrder(types.OrderSideBuy, 2000, 1)
-	fills := engine.AddOrder(buyOrder)
+	fills, err := engine.AddOrder(buyOrder)
+	require.NoError(t, err)
 	assert.Empty(t, fills)
 
 	// 添加可以匹配的卖单
 	sellOrder := createTestOrder(types.OrderSideSell, 1999, 1)
-	fills = engine.AddOrder(sellOrder)
+	sellOrder.UserAddress = "0xtaker"
+	fills, err = engine.AddOrder(sellOrder)
+	require.NoError(t, err)
 	
 	require.NotEmpty(t, fills, "应该产生成交")
 	assert.Equal(t, 1, len(fills), "应该有一笔成交")
@@ -65,6 +73,8 @@ func TestOrderMatching(t *testing.T) {
 	fill := fills[0]
 	assert.Equal(t, buyOrder.ID, fill.MakerOrderID)
 	assert.Equal(t, sellOrder.ID, fill.TakerOrderID)
+	assert.Equal(t, buyOrder.UserAddress, fill.MakerUserAddress)
+	assert.Equal(t, "0xtaker", fill.TakerUserAddress)
 	assert.Equal(t, decimal.NewFromFloat(2000), fill.Price, "成交价格应该是maker价格")
 	assert.Equal(t, decimal.NewFromFloat(1), fill.Amount)
 }
@@ -74,12 +84,14 @@ func TestPartialFill(t *testing.T) {
 
 	// 添加大买单
 	buyOrder := createTestOrder(types.OrderSideBuy, 2000, 10)
-	fills := engine.AddOrder(buyOrder)
+	fills, err := engine.AddOrder(buyOrder)
+	require.NoError(t, err)
 	assert.Empty(t, fills)
 
 	// 添加小卖单
 	sellOrder := createTestOrder(types.OrderSideSell, 1999, 3)
-	fills = engine.AddOrder(sellOrder)
+	fills, err = engine.AddOrder(sellOrder)
+	require.NoError(t, err)
 	
 	require.NotEmpty(t, fills)
 	assert.Equal(t, 1, len(fills))
@@ -108,12 +120,67 @@ func TestMultipleOrderMatching(t *testing.T) {
 
 	// 添加大卖单
 	sellOrder := createTestOrder(types.OrderSideSell, 1998, 5)
-	fills := engine.AddOrder(sellOrder)
+	fills, err := engine.AddOrder(sellOrder)
+	require.NoError(t, err)
 	
-	// 应该先匹配最高价的买单
-	assert.Equal(t, 2, len(fills), "应该匹配前两个买单")
+	// 按价格从高到低依次匹配，第三个买单部分成交
+	require.Equal(t, 3, len(fills), "应该依次匹配三个买单")
 	assert.Equal(t, buyOrder1.ID, fills[0].MakerOrderID)
 	assert.Equal(t, buyOrder2.ID, fills[1].MakerOrderID)
+	assert.Equal(t, buyOrder3.ID, fills[2].MakerOrderID)
+	assert.True(t, fills[2].Amount.Equal(decimal.NewFromInt(2)))
+}
+
+func TestOrderFillSummary(t *testing.T) {
+	engine := setupTestEngine()
+	engine.SetFeeSchedule(FeeSchedule{MakerBps: decimal.NewFromInt(10), TakerBps: decimal.NewFromInt(25)})
+
+	buyOrder1 := createTestOrder(types.OrderSideBuy, 2000, 1)
+	buyOrder2 := createTestOrder(types.OrderSideBuy, 1990, 3)
+	engine.AddOrder(buyOrder1)
+	engine.AddOrder(buyOrder2)
+
+	sellOrder := createTestOrder(types.OrderSideSell, 1990, 3)
+	_, err := engine.AddOrder(sellOrder)
+	require.NoError(t, err)
+
+	// taker：2000×1 + 1990×2 = 5980，均价 5980/3，手续费 25bps
+	assert.True(t, sellOrder.QuoteFilled.Equal(decimal.NewFromInt(5980)))
+	assert.True(t, sellOrder.AvgFillPrice.Equal(decimal.NewFromInt(5980).Div(decimal.NewFromInt(3))))
+	assert.True(t, sellOrder.FeePaid.Equal(decimal.RequireFromString("14.95")))
+
+	// maker 按 maker 费率：3980 × 10bps
+	assert.True(t, buyOrder2.QuoteFilled.Equal(decimal.NewFromInt(3980)))
+	assert.True(t, buyOrder2.AvgFillPrice.Equal(decimal.NewFromInt(1990)))
+	assert.True(t, buyOrder2.FeePaid.Equal(decimal.RequireFromString("3.98")))
+}
+
+func TestOrderBookDepthOrdering(t *testing.T) {
+	engine := setupTestEngine()
+
+	// 乱序加入多个价格层级
+	for _, price := range []float64{2030, 2010, 2050, 2020, 2040} {
+		_, err := engine.AddOrder(createTestOrder(types.OrderSideSell, price, 1))
+		require.NoError(t, err)
+	}
+	cancelled := createTestOrder(types.OrderSideSell, 2020, 1)
+	_, err := engine.AddOrder(cancelled)
+	require.NoError(t, err)
+
+	// 部分成交与撤单后层级数量准确，清空的层级被移除
+	_, err = engine.AddOrder(createTestOrder(types.OrderSideBuy, 2010, 0.5))
+	require.NoError(t, err)
+	require.True(t, engine.CancelOrder(cancelled.ID, cancelled.TradingPair))
+	_, err = engine.AddOrder(createTestOrder(types.OrderSideBuy, 2010, 0.5))
+	require.NoError(t, err)
+
+	book := engine.GetOrderBook("WETH-USDC", 10)
+	require.Len(t, book.Asks, 4)
+	for i, price := range []int64{2020, 2030, 2040, 2050} {
+		assert.True(t, book.Asks[i].Price.Equal(decimal.NewFromInt(price)))
+		assert.True(t, book.Asks[i].Amount.Equal(decimal.NewFromInt(1)))
+		assert.Equal(t, 1, book.Asks[i].Count)
+	}
 }
 
 func TestCancelOrder(t *testing.T) {
@@ -133,6 +200,99 @@ func TestCancelOrder(t *testing.T) {
 	assert.False(t, success, "重复取消应该失败")
 }
 
+func TestCancelOrderWithReason(t *testing.T) {
+	engine := setupTestEngine()
+	sub := engine.Subscribe(SubscriptionOptions{Name: "test", EventTypes: []string{EventOrderCancelled}})
+	defer engine.Unsubscribe(sub)
+
+	order := createTestOrder(types.OrderSideSell, 2001, 1)
+	engine.AddOrder(order)
+
+	snapshot, ok := engine.CancelOrderWithReason(order.ID, order.TradingPair, types.StatusReasonChainCancel)
+	require.True(t, ok)
+	assert.Equal(t, types.OrderStatusCancelled, snapshot.Status)
+	assert.Equal(t, types.StatusReasonChainCancel, snapshot.StatusReason)
+	assert.Empty(t, engine.GetOrderBook(order.TradingPair, 10).Asks)
+
+	event := <-sub.Events()
+	assert.Equal(t, types.StatusReasonChainCancel, event.Order.StatusReason)
+
+	_, ok = engine.CancelOrderWithReason(order.ID, order.TradingPair, types.StatusReasonChainCancel)
+	assert.False(t, ok)
+}
+
+// fixedResting 所有交易对相同的挂单最短停留时间
+type fixedResting time.Duration
+
+func (r fixedResting) MinRestingTime(tradingPair string) time.Duration {
+	return time.Duration(r)
+}
+
+func TestCancelUserOrderMinRestingTime(t *testing.T) {
+	engine := setupTestEngine()
+	engine.SetRestingPolicy(fixedResting(time.Minute))
+
+	order := createTestOrder(types.OrderSideBuy, 2000, 1)
+	_, err := engine.AddOrder(order)
+	require.NoError(t, err)
+
+	// 未满停留时间的用户撤单被拒绝，挂单保留
+	_, err = engine.CancelUserOrder(order.ID, order.TradingPair)
+	assert.ErrorIs(t, err, ErrMinRestingTime)
+	assert.Equal(t, types.OrderStatusOpen, order.Status)
+	assert.Len(t, engine.GetOrderBook(order.TradingPair, 10).Bids, 1)
+
+	// 系统撤单不受限制
+	_, ok := engine.CancelOrderWithReason(order.ID, order.TradingPair, types.StatusReasonPairDelisted)
+	assert.True(t, ok)
+
+	rested := createTestOrder(types.OrderSideSell, 2100, 1)
+	rested.CreatedAt = time.Now().Add(-2 * time.Minute)
+	_, err = engine.AddOrder(rested)
+	require.NoError(t, err)
+	snapshot, err := engine.CancelUserOrder(rested.ID, rested.TradingPair)
+	require.NoError(t, err)
+	assert.Equal(t, types.OrderStatusCancelled, snapshot.Status)
+
+	_, err = engine.CancelUserOrder(rested.ID, rested.TradingPair)
+	assert.ErrorIs(t, err, ErrOrderNotResting)
+}
+
+func TestGetPairStats(t *testing.T) {
+	engine := setupTestEngine()
+
+	_, ok := engine.GetPairStats("WETH-USDC")
+	assert.False(t, ok)
+
+	for _, price := range []float64{2000, 2000, 1990} {
+		_, err := engine.AddOrder(createTestOrder(types.OrderSideBuy, price, 1))
+		require.NoError(t, err)
+	}
+	_, err := engine.AddOrder(createTestOrder(types.OrderSideSell, 2010, 1))
+	require.NoError(t, err)
+
+	stats, ok := engine.GetPairStats("WETH-USDC")
+	require.True(t, ok)
+	assert.Equal(t, 4, stats.RestingOrders)
+	assert.Equal(t, 2, stats.BidLevels)
+	assert.Equal(t, 1, stats.AskLevels)
+	assert.Equal(t, 2, stats.BestBidQueue)
+	assert.Equal(t, 1, stats.BestAskQueue)
+	assert.True(t, stats.AvgBestBidQueue.IsPositive())
+	assert.True(t, stats.MatchesPerSecond.IsZero())
+	assert.Nil(t, stats.LastTradeAt)
+
+	// 成交后速率为正，序号推进
+	sequence := stats.LastSequence
+	_, err = engine.AddOrder(createTestOrder(types.OrderSideSell, 2000, 1))
+	require.NoError(t, err)
+	stats, _ = engine.GetPairStats("WETH-USDC")
+	assert.True(t, stats.MatchesPerSecond.IsPositive())
+	assert.Equal(t, 1, stats.BestBidQueue)
+	assert.Greater(t, stats.LastSequence, sequence)
+	assert.NotNil(t, stats.LastTradeAt)
+}
+
 func TestGetOrderBook(t *testing.T) {
 	engine := setupTestEngine()
 
@@ -177,7 +337,8 @@ func TestMarketOrder(t *testing.T) {
 		UpdatedAt:   time.Now(),
 	}
 
-	fills := engine.AddOrder(marketOrder)
+	fills, err := engine.AddOrder(marketOrder)
+	require.NoError(t, err)
 	
 	assert.Equal(t, 2, len(fills), "市价单应该匹配两个卖单")
 	assert.Equal(t, types.OrderStatusFilled, marketOrder.Status)
@@ -205,6 +366,573 @@ func TestOrderExpiration(t *testing.T) {
 	assert.True(t, expiredOrder.IsExpired(), "订单应该已过期")
 }
 
+func TestActiveOrderCount(t *testing.T) {
+	engine := setupTestEngine()
+	user := "0x1234567890123456789012345678901234567890"
+
+	buy1 := createTestOrder(types.OrderSideBuy, 2000, 1)
+	buy2 := createTestOrder(types.OrderSideBuy, 1990, 1)
+	_, err := engine.AddOrder(buy1)
+	require.NoError(t, err)
+	_, err = engine.AddOrder(buy2)
+	require.NoError(t, err)
+	assert.Equal(t, 2, engine.ActiveOrderCount(user))
+
+	// 完全成交的挂单不再计数
+	_, err = engine.AddOrder(createTestOrder(types.OrderSideSell, 2000, 1))
+	require.NoError(t, err)
+	assert.Equal(t, 1, engine.ActiveOrderCount(user))
+
+	// 撤单后计数减少，地址大小写不敏感
+	assert.True(t, engine.CancelOrder(buy2.ID, buy2.TradingPair))
+	assert.Equal(t, 0, engine.ActiveOrderCount(strings.ToUpper(user)))
+}
+
+type haltedGate struct{}
+
+func (haltedGate) AllowTaker(tradingPair string) error {
+	return ErrPairHalted
+}
+
+func TestTradingGateRejectsTaker(t *testing.T) {
+	engine := setupTestEngine()
+	engine.AddTradingGate(haltedGate{})
+
+	// 挂单不会立即成交，不受闸门影响
+	buyOrder := createTestOrder(types.OrderSideBuy, 2000, 1)
+	_, err := engine.AddOrder(buyOrder)
+	require.NoError(t, err)
+	assert.Equal(t, types.OrderStatusOpen, buyOrder.Status)
+
+	// 会立即成交的订单被拒绝
+	sellOrder := createTestOrder(types.OrderSideSell, 1999, 1)
+	fills, err := engine.AddOrder(sellOrder)
+	assert.ErrorIs(t, err, ErrPairHalted)
+	assert.Empty(t, fills)
+	assert.Equal(t, types.OrderStatusRejected, sellOrder.Status)
+	assert.True(t, buyOrder.FilledAmount.IsZero(), "被拒绝的订单不应产生成交")
+}
+
+// frozenAccounts 按地址冻结的账户闸门
+type frozenAccounts map[string]bool
+
+func (f frozenAccounts) AllowAccount(userAddress string) error {
+	if f[strings.ToLower(userAddress)] {
+		return fmt.Errorf("compromised key")
+	}
+	return nil
+}
+
+func TestAccountGateRejectsFrozenAccount(t *testing.T) {
+	engine := setupTestEngine()
+	frozen := frozenAccounts{}
+	engine.AddAccountGate(frozen)
+
+	resting := createTestOrder(types.OrderSideBuy, 2000, 1)
+	_, err := engine.AddOrder(resting)
+	require.NoError(t, err)
+
+	// 冻结后该账户的挂单与吃单均被拒绝，其他账户不受影响
+	frozen[strings.ToLower(resting.UserAddress)] = true
+	order := createTestOrder(types.OrderSideBuy, 1990, 1)
+	order.UserAddress = resting.UserAddress
+	_, err = engine.AddOrder(order)
+	assert.ErrorIs(t, err, ErrAccountFrozen)
+	assert.Equal(t, types.StatusReasonAccountFrozen, order.StatusReason)
+	assert.Contains(t, order.RejectReason, "compromised key")
+
+	other := createTestOrder(types.OrderSideSell, 2000, 1)
+	other.UserAddress = "0xother"
+	fills, err := engine.AddOrder(other)
+	require.NoError(t, err)
+	assert.Len(t, fills, 1, "已有挂单仍可被动成交，由冻结接口负责撤单")
+}
+
+// lotGate 数量须为整数、交易对未开盘时拒绝的订单闸门
+type lotGate struct{ closed bool }
+
+func (g *lotGate) AllowOrder(order *types.Order) error {
+	if g.closed {
+		return fmt.Errorf("%w: %s is delisted", ErrPairNotTradable, order.TradingPair)
+	}
+	if !order.Amount.Equal(order.Amount.Truncate(0)) {
+		return fmt.Errorf("%w: amount %s", ErrInvalidIncrement, order.Amount)
+	}
+	return nil
+}
+
+func TestOrderGateRejectsOrder(t *testing.T) {
+	engine := setupTestEngine()
+	gate := &lotGate{}
+	engine.AddOrderGate(gate)
+
+	order := createTestOrder(types.OrderSideBuy, 2000, 1.5)
+	_, err := engine.AddOrder(order)
+	assert.ErrorIs(t, err, ErrInvalidIncrement)
+	assert.Equal(t, types.StatusReasonInvalidIncrement, order.StatusReason)
+
+	_, err = engine.AddOrder(createTestOrder(types.OrderSideBuy, 2000, 1))
+	require.NoError(t, err)
+
+	gate.closed = true
+	order = createTestOrder(types.OrderSideSell, 2000, 1)
+	_, err = engine.AddOrder(order)
+	assert.ErrorIs(t, err, ErrPairNotTradable)
+	assert.Equal(t, types.StatusReasonPairNotTradable, order.StatusReason)
+}
+
+func TestPriceBandRejectsDistantPrices(t *testing.T) {
+	engine := setupTestEngine()
+	require.NoError(t, engine.SetPriceBands(PriceBands{
+		Default: PriceBand{MaxDeviationPercent: decimal.NewFromInt(50)},
+		Pairs:   map[string]PriceBand{"wbtc-usdc": {}},
+	}))
+	assert.Error(t, engine.SetPriceBands(PriceBands{Default: PriceBand{MaxDeviationPercent: decimal.NewFromInt(-1)}}))
+
+	// 没有参考价时不限制
+	_, err := engine.AddOrder(createTestOrder(types.OrderSideBuy, 1000, 1))
+	require.NoError(t, err)
+	_, err = engine.AddOrder(createTestOrder(types.OrderSideSell, 3000, 1))
+	require.NoError(t, err)
+
+	// 尚无成交时以中间价 2000 为参考
+	order := createTestOrder(types.OrderSideSell, 3100, 1)
+	_, err = engine.AddOrder(order)
+	assert.ErrorIs(t, err, ErrPriceOutOfBand)
+	assert.Equal(t, types.StatusReasonPriceOutOfBand, order.StatusReason)
+
+	_, err = engine.AddOrder(createTestOrder(types.OrderSideBuy, 3000, 1))
+	require.NoError(t, err)
+
+	// 成交后以最新成交价 3000 为参考
+	_, err = engine.AddOrder(createTestOrder(types.OrderSideSell, 4500, 1))
+	require.NoError(t, err)
+	order = createTestOrder(types.OrderSideBuy, 1400, 1)
+	_, err = engine.AddOrder(order)
+	assert.ErrorIs(t, err, ErrPriceOutOfBand)
+
+	// 市价单不检查，按交易对关闭价格带后不检查
+	market := createTestOrder(types.OrderSideBuy, 0, 1)
+	market.Type = types.OrderTypeMarket
+	_, err = engine.AddOrder(market)
+	require.NoError(t, err)
+
+	other := createTestOrder(types.OrderSideBuy, 1, 1)
+	other.TradingPair = "WBTC-USDC"
+	_, err = engine.AddOrder(other)
+	require.NoError(t, err)
+}
+
+func TestDynamicPriceBandTightensAfterRapidMove(t *testing.T) {
+	engine := setupTestEngine()
+	require.NoError(t, engine.SetPriceBands(PriceBands{
+		Default: PriceBand{MaxDeviationPercent: decimal.NewFromInt(50)},
+		Dynamic: DynamicBand{
+			TriggerPercent: decimal.NewFromInt(10),
+			Window:         time.Minute,
+			TightPercent:   decimal.NewFromInt(5),
+			RelaxDuration:  time.Hour,
+		},
+	}))
+	assert.Nil(t, engine.GetPriceBand("WETH-USDC"))
+
+	trade := func(price float64) {
+		_, err := engine.AddOrder(createTestOrder(types.OrderSideSell, price, 1))
+		require.NoError(t, err)
+		fills, err := engine.AddOrder(createTestOrder(types.OrderSideBuy, price, 1))
+		require.NoError(t, err)
+		require.Len(t, fills, 1)
+	}
+	trade(2000)
+	// 触发前挂出的卖单
+	_, err := engine.AddOrder(createTestOrder(types.OrderSideSell, 2600, 1))
+	require.NoError(t, err)
+	band := engine.GetPriceBand("WETH-USDC")
+	require.NotNil(t, band)
+	assert.False(t, band.Dynamic)
+	assert.True(t, band.UpperPrice.Equal(decimal.NewFromInt(3000)))
+
+	// 一分钟内上涨 15% 触发收紧：以 2300 为参考，上下 5%
+	trade(2300)
+	band = engine.GetPriceBand("WETH-USDC")
+	require.NotNil(t, band)
+	assert.True(t, band.Dynamic)
+	require.NotNil(t, band.RelaxedAt)
+	assert.True(t, band.ReferencePrice.Equal(decimal.NewFromInt(2300)))
+	assert.True(t, band.MaxDeviationPercent.LessThan(decimal.NewFromFloat(5.01)))
+
+	_, err = engine.AddOrder(createTestOrder(types.OrderSideBuy, 2500, 1))
+	assert.ErrorIs(t, err, ErrPriceOutOfBand)
+
+	// 市价单不越过价格带成交
+	_, err = engine.AddOrder(createTestOrder(types.OrderSideSell, 2400, 1))
+	require.NoError(t, err)
+	market := createTestOrder(types.OrderSideBuy, 0, 2)
+	market.Type = types.OrderTypeMarket
+	fills, err := engine.AddOrder(market)
+	require.NoError(t, err)
+	assert.Len(t, fills, 1)
+	assert.Equal(t, types.OrderStatusCancelled, market.Status)
+	assert.Equal(t, types.StatusReasonPriceOutOfBand, market.StatusReason)
+
+	// 收紧期内偏离上限线性放宽到静态价格带
+	orderBook := engine.orderBooks["WETH-USDC"]
+	orderBook.band.triggeredAt = time.Now().Add(-30 * time.Minute)
+	band = engine.GetPriceBand("WETH-USDC")
+	assert.True(t, band.MaxDeviationPercent.Sub(decimal.NewFromFloat(27.5)).Abs().LessThan(decimal.NewFromFloat(0.1)), band.MaxDeviationPercent.String())
+
+	orderBook.band.triggeredAt = time.Now().Add(-2 * time.Hour)
+	band = engine.GetPriceBand("WETH-USDC")
+	assert.False(t, band.Dynamic)
+	assert.True(t, band.MaxDeviationPercent.Equal(decimal.NewFromInt(50)))
+}
+
+func TestSystemHaltStopsMatching(t *testing.T) {
+	engine := setupTestEngine()
+	require.NoError(t, engine.StartAuction("WETH-USDC", "open", time.Hour))
+	_, err := engine.AddOrder(createTestOrder(types.OrderSideBuy, 2000, 1))
+	require.NoError(t, err)
+	_, err = engine.AddOrder(createTestOrder(types.OrderSideSell, 2000, 1))
+	require.NoError(t, err)
+
+	// 停机期间拒绝新订单，集合竞价不撮合，撤单照常
+	engine.SetHalted(true)
+	order := createTestOrder(types.OrderSideBuy, 1990, 1)
+	_, err = engine.AddOrder(order)
+	assert.ErrorIs(t, err, ErrSystemHalted)
+	assert.Equal(t, types.StatusReasonSystemHalted, order.StatusReason)
+
+	fills, ok := engine.EndAuction("WETH-USDC")
+	assert.True(t, ok)
+	assert.Empty(t, fills)
+	assert.Len(t, engine.GetAuctions(), 1, "竞价顺延到恢复之后")
+
+	engine.SetHalted(false)
+	fills, ok = engine.EndAuction("WETH-USDC")
+	assert.True(t, ok)
+	assert.Len(t, fills, 1)
+}
+
+func TestRejectionEvents(t *testing.T) {
+	engine := setupTestEngine()
+	engine.AddTradingGate(haltedGate{})
+	sub := engine.Subscribe(SubscriptionOptions{Name: "test", EventTypes: []string{EventOrderRejected}})
+
+	// 撮合引擎拒绝的订单记录原因代码与说明并发布事件
+	_, err := engine.AddOrder(createTestOrder(types.OrderSideBuy, 2000, 1))
+	require.NoError(t, err)
+	sellOrder := createTestOrder(types.OrderSideSell, 1999, 1)
+	_, err = engine.AddOrder(sellOrder)
+	assert.ErrorIs(t, err, ErrPairHalted)
+	assert.Equal(t, types.StatusReasonPairHalted, sellOrder.StatusReason)
+	assert.NotEmpty(t, sellOrder.RejectReason)
+
+	// 进入撮合前被拒绝的订单同样发布事件，不进入订单簿
+	riskOrder := createTestOrder(types.OrderSideSell, 2100, 1)
+	engine.RejectOrder(riskOrder, "POSITION_LIMIT", fmt.Errorf("position limit exceeded"))
+	assert.Equal(t, types.OrderStatusRejected, riskOrder.Status)
+	assert.Equal(t, "position limit exceeded", riskOrder.RejectReason)
+	assert.Empty(t, engine.GetOrderBook("WETH-USDC", 10).Asks)
+
+	require.Len(t, sub.Events(), 2)
+	first := <-sub.Events()
+	assert.Equal(t, sellOrder.ID, first.Order.ID)
+	second := <-sub.Events()
+	assert.Equal(t, "POSITION_LIMIT", second.Order.StatusReason)
+}
+
+func TestSignatureTTL(t *testing.T) {
+	engine := setupTestEngine()
+	engine.SetSignatureTTL(time.Hour)
+
+	// 签名已超期的订单直接拒绝
+	staleOrder := createTestOrder(types.OrderSideSell, 2000, 1)
+	staleOrder.CreatedAt = time.Now().Add(-2 * time.Hour)
+	_, err := engine.AddOrder(staleOrder)
+	assert.ErrorIs(t, err, ErrSignatureExpired)
+	assert.Equal(t, types.OrderStatusRejected, staleOrder.Status)
+
+	// 挂单签名超期后被撤销，不再参与撮合
+	buyOrder := createTestOrder(types.OrderSideBuy, 2000, 1)
+	_, err = engine.AddOrder(buyOrder)
+	require.NoError(t, err)
+	assert.Empty(t, engine.ExpireStaleSignatures())
+
+	buyOrder.CreatedAt = time.Now().Add(-2 * time.Hour)
+	expired := engine.ExpireStaleSignatures()
+	require.Len(t, expired, 1)
+	assert.Equal(t, buyOrder.ID, expired[0].ID)
+	assert.Equal(t, types.OrderStatusCancelled, buyOrder.Status)
+	assert.Empty(t, engine.GetOrderBook("WETH-USDC", 10).Bids)
+}
+
+func TestExpiredOrdersRemoved(t *testing.T) {
+	engine := setupTestEngine()
+	sub := engine.Subscribe(SubscriptionOptions{Name: "test", EventTypes: []string{EventOrderExpired}})
+
+	// 撮合时惰性移除已过期的对手方挂单
+	soon := time.Now().Add(20 * time.Millisecond)
+	sellOrder := createTestOrder(types.OrderSideSell, 2000, 1)
+	sellOrder.ExpiresAt = &soon
+	_, err := engine.AddOrder(sellOrder)
+	require.NoError(t, err)
+	time.Sleep(30 * time.Millisecond)
+
+	buyOrder := createTestOrder(types.OrderSideBuy, 2000, 1)
+	fills, err := engine.AddOrder(buyOrder)
+	require.NoError(t, err)
+	assert.Empty(t, fills)
+	assert.Equal(t, types.OrderStatusExpired, sellOrder.Status)
+
+	// 定时清理移除过期挂单
+	assert.Empty(t, engine.ExpireOrders())
+	past := time.Now().Add(-time.Second)
+	buyOrder.ExpiresAt = &past
+	expired := engine.ExpireOrders()
+	require.Len(t, expired, 1)
+	assert.Equal(t, buyOrder.ID, expired[0].ID)
+	assert.Empty(t, engine.GetOrderBook("WETH-USDC", 10).Bids)
+
+	require.Len(t, sub.Events(), 2)
+	event := <-sub.Events()
+	assert.Equal(t, sellOrder.ID, event.Order.ID)
+	assert.Equal(t, types.OrderStatusExpired, event.Order.Status)
+}
+
+func TestExpirySchedulerExpiresAtDeadline(t *testing.T) {
+	engine := setupTestEngine()
+	sub := engine.Subscribe(SubscriptionOptions{Name: "test", EventTypes: []string{EventOrderExpired}})
+	// 全量清理间隔远大于订单有效期，到期只能由调度触发
+	engine.StartExpirySweeper(time.Hour)
+
+	later := time.Now().Add(time.Hour)
+	cancelled := createTestOrder(types.OrderSideBuy, 1990, 1)
+	cancelled.ExpiresAt = &later
+	_, err := engine.AddOrder(cancelled)
+	require.NoError(t, err)
+	require.True(t, engine.CancelOrder(cancelled.ID, "WETH-USDC"))
+
+	deadline := time.Now().Add(30 * time.Millisecond)
+	order := createTestOrder(types.OrderSideBuy, 2000, 1)
+	order.ExpiresAt = &deadline
+	_, err = engine.AddOrder(order)
+	require.NoError(t, err)
+
+	select {
+	case event := <-sub.Events():
+		assert.Equal(t, order.ID, event.Order.ID)
+		assert.WithinDuration(t, deadline, event.Timestamp, 100*time.Millisecond)
+	case <-time.After(time.Second):
+		t.Fatal("order not expired at its deadline")
+	}
+	assert.Empty(t, engine.GetOrderBook("WETH-USDC", 10).Bids)
+
+	// 撤销的挂单已移出到期队列
+	_, scheduled := engine.expiries.next()
+	assert.False(t, scheduled)
+}
+
+func TestMarketOrderSlippageProtection(t *testing.T) {
+	engine := setupTestEngine()
+	for _, price := range []float64{2000, 2010, 2100} {
+		_, err := engine.AddOrder(createTestOrder(types.OrderSideSell, price, 1))
+		require.NoError(t, err)
+	}
+
+	// 最大滑点 1%：可成交至 2020，2100 的卖单不再成交
+	marketOrder := createTestOrder(types.OrderSideBuy, 0, 3)
+	marketOrder.Type = types.OrderTypeMarket
+	marketOrder.MaxSlippage = decimal.NewFromInt(1)
+	fills, err := engine.AddOrder(marketOrder)
+	require.NoError(t, err)
+	assert.Len(t, fills, 2)
+	assert.Equal(t, types.OrderStatusCancelled, marketOrder.Status)
+	assert.Equal(t, types.StatusReasonSlippageLimit, marketOrder.StatusReason)
+	assert.True(t, marketOrder.FilledAmount.Equal(decimal.NewFromInt(2)))
+
+	// 保护价低于最优价时不成交
+	protected := createTestOrder(types.OrderSideBuy, 0, 1)
+	protected.Type = types.OrderTypeMarket
+	protected.ProtectionPrice = decimal.NewFromInt(2050)
+	fills, err = engine.AddOrder(protected)
+	require.NoError(t, err)
+	assert.Empty(t, fills)
+	assert.Equal(t, types.StatusReasonSlippageLimit, protected.StatusReason)
+}
+
+func TestMarketOrderWithoutLiquidity(t *testing.T) {
+	engine := setupTestEngine()
+	sub := engine.Subscribe(SubscriptionOptions{Name: "test", EventTypes: []string{EventOrderRejected}})
+
+	// 对手方为空时拒绝
+	marketOrder := createTestOrder(types.OrderSideBuy, 0, 1)
+	marketOrder.Type = types.OrderTypeMarket
+	_, err := engine.AddOrder(marketOrder)
+	assert.ErrorIs(t, err, ErrNoLiquidity)
+	assert.Equal(t, types.OrderStatusRejected, marketOrder.Status)
+	assert.Equal(t, types.StatusReasonNoLiquidity, marketOrder.StatusReason)
+	require.Len(t, sub.Events(), 1)
+
+	// 对手方名义价值低于下限时拒绝
+	engine.SetMinMarketLiquidity(decimal.NewFromInt(5000))
+	_, err = engine.AddOrder(createTestOrder(types.OrderSideSell, 2000, 1))
+	require.NoError(t, err)
+	smallBook := createTestOrder(types.OrderSideBuy, 0, 1)
+	smallBook.Type = types.OrderTypeMarket
+	_, err = engine.AddOrder(smallBook)
+	assert.ErrorIs(t, err, ErrNoLiquidity)
+
+	// 对手方耗尽后剩余部分撤销
+	engine.SetMinMarketLiquidity(decimal.Zero)
+	partial := createTestOrder(types.OrderSideBuy, 0, 2)
+	partial.Type = types.OrderTypeMarket
+	fills, err := engine.AddOrder(partial)
+	require.NoError(t, err)
+	assert.Len(t, fills, 1)
+	assert.Equal(t, types.OrderStatusCancelled, partial.Status)
+	assert.Equal(t, types.StatusReasonNoLiquidity, partial.StatusReason)
+}
+
+func TestCallAuctionUncross(t *testing.T) {
+	engine := setupTestEngine()
+	require.NoError(t, engine.StartAuction("WETH-USDC", "opening", time.Hour))
+
+	// 竞价期间交叉的限价单只挂单不撮合，市价单被拒绝
+	for _, order := range []*types.Order{
+		createTestOrder(types.OrderSideBuy, 2010, 2),
+		createTestOrder(types.OrderSideBuy, 2005, 1),
+		createTestOrder(types.OrderSideBuy, 2000, 1),
+		createTestOrder(types.OrderSideSell, 1990, 1),
+		createTestOrder(types.OrderSideSell, 2005, 2),
+	} {
+		fills, err := engine.AddOrder(order)
+		require.NoError(t, err)
+		assert.Empty(t, fills)
+	}
+	marketOrder := createTestOrder(types.OrderSideBuy, 0, 1)
+	marketOrder.Type = types.OrderTypeMarket
+	_, err := engine.AddOrder(marketOrder)
+	assert.ErrorIs(t, err, ErrAuctionInProgress)
+
+	auctions := engine.GetAuctions()
+	require.Len(t, auctions, 1)
+	assert.True(t, auctions[0].IndicativePrice.Equal(decimal.NewFromInt(2005)))
+
+	// 在成交量最大的单一价格统一成交
+	fills, ok := engine.EndAuction("WETH-USDC")
+	require.True(t, ok)
+	volume := decimal.Zero
+	for _, fill := range fills {
+		assert.True(t, fill.Price.Equal(decimal.NewFromInt(2005)))
+		volume = volume.Add(fill.Amount)
+	}
+	assert.True(t, volume.Equal(decimal.NewFromInt(3)))
+
+	// 恢复连续交易后剩余的买单不再与卖单交叉
+	orderBook := engine.GetOrderBook("WETH-USDC", 10)
+	assert.Len(t, orderBook.Asks, 0)
+	require.NotEmpty(t, orderBook.Bids)
+	assert.True(t, orderBook.Bids[0].Price.Equal(decimal.NewFromInt(2000)))
+}
+
+func TestOrderBookL3(t *testing.T) {
+	engine := setupTestEngine()
+
+	first := createTestOrder(types.OrderSideBuy, 2000, 1)
+	second := createTestOrder(types.OrderSideBuy, 2000, 2)
+	second.UserAddress = "0xother"
+	_, err := engine.AddOrder(first)
+	require.NoError(t, err)
+	_, err = engine.AddOrder(second)
+	require.NoError(t, err)
+	_, err = engine.AddOrder(createTestOrder(types.OrderSideBuy, 1990, 1))
+	require.NoError(t, err)
+
+	book := engine.GetOrderBookL3("WETH-USDC", 10, "")
+	require.Len(t, book.Bids, 2)
+	require.Len(t, book.Bids[0].Orders, 2)
+	assert.Equal(t, first.ID, book.Bids[0].Orders[0].OrderID)
+	assert.Equal(t, 1, book.Bids[0].Orders[1].Position)
+
+	// 按用户过滤时保留排队位置与层级汇总
+	filtered := engine.GetOrderBookL3("WETH-USDC", 10, "0xother")
+	require.Len(t, filtered.Bids, 1)
+	require.Len(t, filtered.Bids[0].Orders, 1)
+	assert.Equal(t, 1, filtered.Bids[0].Orders[0].Position)
+	assert.True(t, filtered.Bids[0].Amount.Equal(decimal.NewFromInt(3)))
+
+	position, ok := engine.GetQueuePosition(second.ID)
+	require.True(t, ok)
+	assert.Equal(t, 1, position.OrdersAhead)
+	assert.True(t, position.AmountAhead.Equal(decimal.NewFromInt(1)))
+	assert.True(t, position.IsBestPrice)
+}
+
+func TestGetBBO(t *testing.T) {
+	engine := setupTestEngine()
+
+	_, err := engine.AddOrder(createTestOrder(types.OrderSideBuy, 1990, 1))
+	require.NoError(t, err)
+	bbo := engine.GetBBO("WETH-USDC")
+	require.NotNil(t, bbo.BestBid)
+	assert.Nil(t, bbo.BestAsk)
+	assert.Nil(t, bbo.MidPrice)
+
+	_, err = engine.AddOrder(createTestOrder(types.OrderSideSell, 2010, 1))
+	require.NoError(t, err)
+	bbo = engine.GetBBO("WETH-USDC")
+	require.NotNil(t, bbo.MidPrice)
+	assert.True(t, bbo.MidPrice.Equal(decimal.NewFromInt(2000)))
+	assert.True(t, bbo.Spread.Equal(decimal.NewFromInt(20)))
+	assert.True(t, bbo.SpreadBps.Equal(decimal.NewFromInt(100)))
+
+	// 部分成交后序号递增，最优价不变
+	sequence := bbo.Sequence
+	_, err = engine.AddOrder(createTestOrder(types.OrderSideBuy, 2010, 0.5))
+	require.NoError(t, err)
+	bbo = engine.GetBBO("WETH-USDC")
+	assert.Greater(t, bbo.Sequence, sequence)
+	assert.True(t, bbo.BestAsk.Equal(decimal.NewFromInt(2010)))
+
+	all := engine.GetAllBBO()
+	require.Len(t, all, 1)
+	assert.Equal(t, "WETH-USDC", all[0].TradingPair)
+}
+
+func TestLiquidityMetrics(t *testing.T) {
+	engine := setupTestEngine()
+
+	// 中间价 2000：买 1990×1、1900×2，卖 2010×1、2100×1
+	for _, order := range []*types.Order{
+		createTestOrder(types.OrderSideBuy, 1990, 1),
+		createTestOrder(types.OrderSideBuy, 1900, 2),
+		createTestOrder(types.OrderSideSell, 2010, 1),
+		createTestOrder(types.OrderSideSell, 2100, 1),
+	} {
+		_, err := engine.AddOrder(order)
+		require.NoError(t, err)
+	}
+
+	metrics := engine.GetLiquidityMetrics("WETH-USDC",
+		[]decimal.Decimal{decimal.NewFromInt(100)}, []decimal.Decimal{decimal.NewFromInt(2), decimal.NewFromInt(10)})
+	require.NotNil(t, metrics.MidPrice)
+	assert.True(t, metrics.MidPrice.Equal(decimal.NewFromInt(2000)))
+	assert.True(t, metrics.Imbalance.Equal(decimal.NewFromFloat(0.2)))
+
+	// ±1% 区间内只有最优一档
+	require.Len(t, metrics.Bands, 1)
+	assert.True(t, metrics.Bands[0].BidAmount.Equal(decimal.NewFromInt(1)))
+	assert.True(t, metrics.Bands[0].AskNotional.Equal(decimal.NewFromInt(2010)))
+
+	// 推高 2% 需吃掉 2010 一档；推动 10% 时卖方挂单全部耗尽
+	require.Len(t, metrics.Impact, 2)
+	assert.True(t, metrics.Impact[0].BuyAmount.Equal(decimal.NewFromInt(1)))
+	assert.False(t, metrics.Impact[0].BuyExhausted)
+	assert.True(t, metrics.Impact[1].BuyAmount.Equal(decimal.NewFromInt(2)))
+	assert.True(t, metrics.Impact[1].BuyExhausted)
+	assert.True(t, metrics.Impact[1].SellAmount.Equal(decimal.NewFromInt(3)))
+}
+
 func BenchmarkAddOrder(b *testing.B) {
 	engine := setupTestEngine()
 	
@@ -228,4 +956,190 @@ func BenchmarkMatchOrder(b *testing.B) {
 		order := createTestOrder(types.OrderSideSell, 1999, 0.1)
 		engine.AddOrder(order)
 	}
-}
\ No newline at end of file
+}
+// setupBenchEngine 基准测试用引擎，关闭逐笔日志以测量撮合本身
+func setupBenchEngine() *MatchingEngine {
+	logger := logrus.New()
+	logger.SetLevel(logrus.WarnLevel)
+	return NewMatchingEngine(logger)
+}
+
+// BenchmarkAddOrderMixed 单交易对挂单与吃单混合，目标每交易对 ≥100k orders/sec
+func BenchmarkAddOrderMixed(b *testing.B) {
+	engine := setupBenchEngine()
+	orders := make([]*types.Order, b.N)
+	for i := range orders {
+		side := types.OrderSideBuy
+		if i%2 == 1 {
+			side = types.OrderSideSell
+		}
+		orders[i] = createTestOrder(side, 2000+float64(i%20-10), 1)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for _, order := range orders {
+		engine.AddOrder(order)
+	}
+	b.ReportMetric(float64(b.N)/b.Elapsed().Seconds(), "orders/sec")
+}
+
+// BenchmarkAddOrderParallelPairs 多交易对并行下单，各订单簿独立加锁
+func BenchmarkAddOrderParallelPairs(b *testing.B) {
+	engine := setupBenchEngine()
+	var pairSeq uint64
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		pair := fmt.Sprintf("PAIR%d-USDC", atomic.AddUint64(&pairSeq, 1))
+		i := 0
+		for pb.Next() {
+			side := types.OrderSideBuy
+			if i%2 == 1 {
+				side = types.OrderSideSell
+			}
+			order := createTestOrder(side, 2000+float64(i%20-10), 1)
+			order.TradingPair = pair
+			engine.AddOrder(order)
+			i++
+		}
+	})
+	b.ReportMetric(float64(b.N)/b.Elapsed().Seconds(), "orders/sec")
+}
+
+// collectEvents 读取 n 个事件，超时失败
+func collectEvents(t *testing.T, sub *Subscription, n int) []*MatchEvent {
+	events := make([]*MatchEvent, 0, n)
+	timeout := time.After(5 * time.Second)
+	for len(events) < n {
+		select {
+		case event := <-sub.Events():
+			events = append(events, event)
+		case <-timeout:
+			t.Fatalf("received %d of %d events", len(events), n)
+		}
+	}
+	return events
+}
+
+func TestEventOverflowPoliciesDoNotBlock(t *testing.T) {
+	for _, policy := range []OverflowPolicy{OverflowExpand, OverflowSpill} {
+		t.Run(string(policy), func(t *testing.T) {
+			engine := setupBenchEngine()
+			spillDir := t.TempDir()
+			require.NoError(t, engine.SetEventBackpressure(EventBackpressure{BufferSize: 2, Overflow: policy, SpillDir: spillDir}))
+			sub := engine.Subscribe(SubscriptionOptions{Name: "slow", EventTypes: []string{EventOrderAdded}})
+
+			// 消费者不读取时撮合不阻塞，溢出事件按序转发
+			orders := make([]*types.Order, 20)
+			for i := range orders {
+				orders[i] = createTestOrder(types.OrderSideBuy, 1000+float64(i), 1)
+				_, err := engine.AddOrder(orders[i])
+				require.NoError(t, err)
+			}
+			stats := engine.GetSubscriptionStats()
+			require.Len(t, stats, 1)
+			assert.Equal(t, policy, stats[0].Overflow)
+			assert.Positive(t, stats[0].Overflowed)
+			assert.Equal(t, 18, stats[0].OverflowDepth)
+			assert.Equal(t, 20, engine.EventQueueDepth())
+			if policy == OverflowSpill {
+				files, err := os.ReadDir(spillDir)
+				require.NoError(t, err)
+				assert.Len(t, files, 1)
+			}
+
+			for i, event := range collectEvents(t, sub, len(orders)) {
+				assert.Equal(t, orders[i].ID, event.Order.ID)
+				assert.True(t, orders[i].Price.Equal(event.Order.Price))
+			}
+
+			// 积压清空后新事件直接进入通道
+			_, err := engine.AddOrder(createTestOrder(types.OrderSideBuy, 900, 1))
+			require.NoError(t, err)
+			collectEvents(t, sub, 1)
+			assert.Equal(t, 0, engine.EventQueueDepth())
+
+			engine.Unsubscribe(sub)
+			files, err := os.ReadDir(spillDir)
+			require.NoError(t, err)
+			assert.Empty(t, files, "注销后删除溢出文件")
+		})
+	}
+}
+
+func TestEventBackpressureConfig(t *testing.T) {
+	engine := setupBenchEngine()
+	assert.Error(t, engine.SetEventBackpressure(EventBackpressure{Overflow: "unbounded"}))
+	assert.Error(t, engine.SetEventBackpressure(EventBackpressure{Overflow: OverflowSpill}))
+
+	require.NoError(t, engine.SetEventBackpressure(EventBackpressure{BufferSize: 1, Overflow: OverflowDrop}))
+	sub := engine.Subscribe(SubscriptionOptions{Name: "drop"})
+	engine.AddOrder(createTestOrder(types.OrderSideBuy, 1000, 1))
+	engine.AddOrder(createTestOrder(types.OrderSideBuy, 1001, 1))
+
+	stats := engine.GetSubscriptionStats()
+	require.Len(t, stats, 1)
+	assert.True(t, stats[0].DropOnFull)
+	assert.Equal(t, uint64(1), stats[0].Dropped)
+	assert.Equal(t, 0, engine.EventQueueDepth(), "可丢弃消费者不计入积压")
+	engine.Unsubscribe(sub)
+}
+
+func TestOrderBookSnapshotReadPath(t *testing.T) {
+	engine := setupTestEngine()
+
+	buy := createTestOrder(types.OrderSideBuy, 1990, 2)
+	_, err := engine.AddOrder(buy)
+	require.NoError(t, err)
+	_, err = engine.AddOrder(createTestOrder(types.OrderSideBuy, 1980, 1))
+	require.NoError(t, err)
+	_, err = engine.AddOrder(createTestOrder(types.OrderSideSell, 2010, 1))
+	require.NoError(t, err)
+
+	book := engine.GetOrderBook("WETH-USDC", 1)
+	require.Len(t, book.Bids, 1)
+	assert.True(t, book.Bids[0].Price.Equal(decimal.NewFromInt(1990)))
+	require.Len(t, book.Asks, 1)
+	sequence := book.Sequence
+
+	// 返回的层级是副本，修改不影响快照
+	book.Bids[0].Count = 99
+	assert.Equal(t, 1, engine.GetOrderBook("WETH-USDC", 10).Bids[0].Count)
+
+	// 改单与撤单后快照随之更新
+	_, ok := engine.ReduceOrder(buy.ID, "WETH-USDC", decimal.NewFromInt(1))
+	require.True(t, ok)
+	book = engine.GetOrderBook("WETH-USDC", 10)
+	assert.Greater(t, book.Sequence, sequence)
+	assert.True(t, book.Bids[0].Amount.Equal(decimal.NewFromInt(1)))
+	require.True(t, engine.CancelOrder(buy.ID, "WETH-USDC"))
+	book = engine.GetOrderBook("WETH-USDC", 10)
+	require.Len(t, book.Bids, 1)
+	assert.True(t, book.Bids[0].Price.Equal(decimal.NewFromInt(1980)))
+
+	// 撮合持有订单簿锁、清理持有引擎写锁时，行情读取不被阻塞
+	orderBook := engine.orderBooks["WETH-USDC"]
+	engine.mu.Lock()
+	orderBook.mu.Lock()
+	done := make(chan *types.BBO)
+	go func() {
+		engine.GetOrderBook("WETH-USDC", 10)
+		done <- engine.GetBBO("WETH-USDC")
+	}()
+	select {
+	case bbo := <-done:
+		assert.True(t, bbo.BestBid.Equal(decimal.NewFromInt(1980)))
+		assert.True(t, bbo.BestAsk.Equal(decimal.NewFromInt(2010)))
+		assert.Equal(t, book.Sequence, bbo.Sequence)
+	case <-time.After(time.Second):
+		t.Fatal("market data read blocked by engine locks")
+	}
+	orderBook.mu.Unlock()
+	engine.mu.Unlock()
+
+	empty := engine.GetOrderBook("WBTC-USDC", 10)
+	assert.Empty(t, empty.Bids)
+	assert.Equal(t, uint64(0), empty.Sequence)
+}
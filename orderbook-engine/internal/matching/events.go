@@ -0,0 +1,248 @@
+package matching
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/sirupsen/logrus"
+
+	"orderbook-engine/internal/types"
+)
+
+// 撮合事件类型
+const (
+	EventOrderAdded     = "order_added"
+	EventOrderCancelled = "order_cancelled"
+	EventOrderExpired   = "order_expired"
+	EventOrderRejected  = "order_rejected"
+	EventOrderReduced   = "order_reduced" // 挂单剩余数量被缩减（只减仓订单随持仓减少缩量）
+
+	EventAuctionUncrossed = "auction_uncrossed" // 集合竞价统一成交，每个 taker 订单一条事件
+)
+
+// defaultSubscriptionBuffer 默认订阅缓冲大小
+const defaultSubscriptionBuffer = 10000
+
+// SubscriptionOptions 事件订阅选项
+type SubscriptionOptions struct {
+	Name         string         // 消费者名称
+	BufferSize   int            // 独立缓冲大小，0使用引擎默认值
+	EventTypes   []string       // 关注的事件类型，为空表示全部
+	TradingPairs []string       // 关注的交易对，为空表示全部
+	DropOnFull   bool           // 缓冲满时丢弃事件而不是阻塞撮合（适用于行情推送等可丢失的消费者），等同 Overflow 为 drop
+	Overflow     OverflowPolicy // 缓冲满时的策略，为空使用引擎默认值（SetEventBackpressure）
+}
+
+// Subscription 撮合事件订阅
+type Subscription struct {
+	name         string
+	ch           chan *MatchEvent
+	eventTypes   map[string]bool
+	tradingPairs map[string]bool
+	dropOnFull   bool
+	dropped      uint64
+	overflow     OverflowPolicy
+	logger       *logrus.Logger
+
+	// expand / spill 策略的溢出队列与转发协程
+	mu          sync.Mutex
+	pending     overflowQueue
+	forwarding  bool // 转发协程已取出事件、尚未送达通道，期间新事件也进入溢出队列以保持顺序
+	wake        chan struct{}
+	done        chan struct{}
+	finished    chan struct{}
+	overflowed  uint64 // 进入溢出队列的事件数
+	spillErrors uint64 // 写盘或读盘失败次数
+}
+
+// SubscriptionStats 订阅统计
+type SubscriptionStats struct {
+	Name          string         `json:"name"`
+	QueueDepth    int            `json:"queue_depth"`
+	BufferSize    int            `json:"buffer_size"`
+	Dropped       uint64         `json:"dropped"`
+	DropOnFull    bool           `json:"drop_on_full"`
+	Overflow      OverflowPolicy `json:"overflow"`
+	OverflowDepth int            `json:"overflow_depth"` // 溢出队列中等待转发的事件数
+	Overflowed    uint64         `json:"overflowed"`     // 累计进入溢出队列的事件数
+	SpillErrors   uint64         `json:"spill_errors,omitempty"`
+}
+
+// eventBus 撮合事件总线，每个消费者独立缓冲，互不影响
+type eventBus struct {
+	mu       sync.RWMutex
+	subs     []*Subscription
+	defaults EventBackpressure
+}
+
+// Events 获取事件通道
+func (s *Subscription) Events() <-chan *MatchEvent {
+	return s.ch
+}
+
+// Name 获取消费者名称
+func (s *Subscription) Name() string {
+	return s.name
+}
+
+func (s *Subscription) matches(event *MatchEvent) bool {
+	if len(s.eventTypes) > 0 && !s.eventTypes[event.Type] {
+		return false
+	}
+	if len(s.tradingPairs) > 0 && !s.tradingPairs[event.TradingPair] {
+		return false
+	}
+	return true
+}
+
+// Subscribe 注册事件消费者
+func (me *MatchingEngine) Subscribe(opts SubscriptionOptions) *Subscription {
+	me.events.mu.Lock()
+	defer me.events.mu.Unlock()
+	defaults := me.events.defaults
+
+	bufferSize := opts.BufferSize
+	if bufferSize <= 0 {
+		bufferSize = defaults.BufferSize
+	}
+	if bufferSize <= 0 {
+		bufferSize = defaultSubscriptionBuffer
+	}
+	overflow := opts.Overflow
+	if opts.DropOnFull {
+		overflow = OverflowDrop
+	}
+	if overflow == "" {
+		overflow = defaults.Overflow
+	}
+	if overflow == "" || (overflow == OverflowSpill && defaults.SpillDir == "") {
+		overflow = OverflowBlock
+	}
+
+	sub := &Subscription{
+		name:         opts.Name,
+		ch:           make(chan *MatchEvent, bufferSize),
+		eventTypes:   toSet(opts.EventTypes),
+		tradingPairs: toSet(opts.TradingPairs),
+		dropOnFull:   overflow == OverflowDrop,
+		overflow:     overflow,
+		logger:       me.logger,
+	}
+	switch overflow {
+	case OverflowExpand:
+		sub.pending = &memoryQueue{}
+	case OverflowSpill:
+		sub.pending = newSpillQueue(defaults.SpillDir, opts.Name, &sub.spillErrors)
+	}
+	if sub.pending != nil {
+		sub.wake = make(chan struct{}, 1)
+		sub.done = make(chan struct{})
+		sub.finished = make(chan struct{})
+		go sub.forward()
+	}
+
+	me.events.subs = append(me.events.subs, sub)
+
+	me.logger.WithFields(logrus.Fields{
+		"consumer": opts.Name,
+		"overflow": overflow,
+	}).Info("Matching event consumer subscribed")
+	return sub
+}
+
+// Unsubscribe 注销事件消费者并关闭其通道
+func (me *MatchingEngine) Unsubscribe(sub *Subscription) {
+	// 持有撮合锁，确保没有正在进行的发布
+	me.mu.Lock()
+	defer me.mu.Unlock()
+
+	me.events.mu.Lock()
+	defer me.events.mu.Unlock()
+
+	for i, s := range me.events.subs {
+		if s == sub {
+			me.events.subs = append(me.events.subs[:i], me.events.subs[i+1:]...)
+			if sub.pending != nil {
+				close(sub.done)
+				<-sub.finished
+				sub.mu.Lock()
+				sub.pending.close()
+				sub.mu.Unlock()
+			}
+			close(sub.ch)
+			return
+		}
+	}
+}
+
+// GetSubscriptionStats 获取各消费者的积压和丢弃统计
+func (me *MatchingEngine) GetSubscriptionStats() []SubscriptionStats {
+	me.events.mu.RLock()
+	defer me.events.mu.RUnlock()
+
+	stats := make([]SubscriptionStats, 0, len(me.events.subs))
+	for _, sub := range me.events.subs {
+		stats = append(stats, SubscriptionStats{
+			Name:          sub.name,
+			QueueDepth:    len(sub.ch),
+			BufferSize:    cap(sub.ch),
+			Dropped:       atomic.LoadUint64(&sub.dropped),
+			DropOnFull:    sub.dropOnFull,
+			Overflow:      sub.overflow,
+			OverflowDepth: sub.overflowDepth(),
+			Overflowed:    atomic.LoadUint64(&sub.overflowed),
+			SpillErrors:   atomic.LoadUint64(&sub.spillErrors),
+		})
+	}
+	return stats
+}
+
+// publish 将事件分发给匹配的消费者（调用方需持有 me.mu），备用模式下不发布
+func (me *MatchingEngine) publish(event *MatchEvent) {
+	if me.standby.Load() {
+		return
+	}
+
+	me.events.mu.RLock()
+	defer me.events.mu.RUnlock()
+
+	for _, sub := range me.events.subs {
+		if !sub.matches(event) {
+			continue
+		}
+
+		if sub.pending != nil {
+			sub.enqueue(event)
+			continue
+		}
+		if !sub.dropOnFull {
+			sub.ch <- event
+			continue
+		}
+
+		select {
+		case sub.ch <- event:
+		default:
+			if atomic.AddUint64(&sub.dropped, 1)%1000 == 1 {
+				me.logger.WithField("consumer", sub.name).Warn("Matching event consumer lagging, dropping events")
+			}
+		}
+	}
+}
+
+// snapshotOrder 复制订单当前状态，消费者读取事件时不与后续撮合修改竞争
+func snapshotOrder(order *types.Order) *types.Order {
+	copied := *order
+	return &copied
+}
+
+func toSet(values []string) map[string]bool {
+	if len(values) == 0 {
+		return nil
+	}
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		set[v] = true
+	}
+	return set
+}
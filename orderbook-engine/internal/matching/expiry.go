@@ -0,0 +1,68 @@
+package matching
+
+import (
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"orderbook-engine/internal/types"
+)
+
+// StartExpirySweeper 启动过期订单清理
+// 到期调度在每个挂单的截止时刻将其移除；按固定间隔的全量清理作为兜底，撮合时也会惰性检查对手方挂单
+func (me *MatchingEngine) StartExpirySweeper(interval time.Duration) {
+	go me.runExpiryScheduler()
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			me.ExpireOrders()
+		}
+	}()
+}
+
+// ExpireOrders 移除已过期的挂单并发送 order_expired 事件，返回被移除订单的快照
+func (me *MatchingEngine) ExpireOrders() []*types.Order {
+	me.mu.Lock()
+	defer me.mu.Unlock()
+
+	now := time.Now()
+	var expired []*types.Order
+	for _, orderBook := range me.orderBooks {
+		for _, order := range orderBook.Orders {
+			if order.ExpiresAt == nil || now.Before(*order.ExpiresAt) {
+				continue
+			}
+			expired = append(expired, me.expireOrder(orderBook, order, now))
+		}
+		orderBook.publishSnapshot()
+	}
+
+	if len(expired) > 0 {
+		me.logger.WithField("count", len(expired)).Info("⏰ Expired orders removed from book")
+	}
+	return expired
+}
+
+// expireOrder 将挂单移出订单簿并置为 expired（调用方持有写锁，或读锁及该订单簿的锁）
+func (me *MatchingEngine) expireOrder(orderBook *OrderBook, order *types.Order, now time.Time) *types.Order {
+	me.removeOrderFromBook(orderBook, order)
+	order.Status = types.OrderStatusExpired
+	order.UpdatedAt = now
+
+	snapshot := snapshotOrder(order)
+	me.publish(&MatchEvent{
+		Type:        EventOrderExpired,
+		TradingPair: orderBook.TradingPair,
+		Order:       snapshot,
+		Timestamp:   now,
+	})
+
+	me.logger.WithFields(logrus.Fields{
+		"order_id":     order.ID.String(),
+		"trading_pair": orderBook.TradingPair,
+		"expires_at":   order.ExpiresAt,
+	}).Debug("Order expired")
+	return snapshot
+}
@@ -0,0 +1,165 @@
+package matching
+
+import (
+	"container/heap"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"orderbook-engine/internal/types"
+)
+
+// expiryIdleWait 没有待到期挂单时调度协程的等待时长，新挂单会提前唤醒
+const expiryIdleWait = time.Minute
+
+// expiryEntry 到期队列中的挂单
+type expiryEntry struct {
+	at          time.Time
+	orderID     uuid.UUID
+	tradingPair string
+	index       int
+}
+
+// expiryQueue 按到期时间排序的最小堆
+type expiryQueue []*expiryEntry
+
+func (q expiryQueue) Len() int           { return len(q) }
+func (q expiryQueue) Less(i, j int) bool { return q[i].at.Before(q[j].at) }
+func (q expiryQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *expiryQueue) Push(x interface{}) {
+	entry := x.(*expiryEntry)
+	entry.index = len(*q)
+	*q = append(*q, entry)
+}
+
+func (q *expiryQueue) Pop() interface{} {
+	old := *q
+	entry := old[len(old)-1]
+	old[len(old)-1] = nil
+	*q = old[:len(old)-1]
+	return entry
+}
+
+// expiryScheduler 挂单到期调度，挂单进出订单簿时同步维护，最早到期时刻变化时唤醒调度协程
+type expiryScheduler struct {
+	mu      sync.Mutex
+	queue   expiryQueue
+	entries map[uuid.UUID]*expiryEntry
+	wake    chan struct{}
+}
+
+func newExpiryScheduler() *expiryScheduler {
+	return &expiryScheduler{
+		entries: make(map[uuid.UUID]*expiryEntry),
+		wake:    make(chan struct{}, 1),
+	}
+}
+
+// schedule 登记带 ExpiresAt 的挂单
+func (s *expiryScheduler) schedule(order *types.Order) {
+	if order.ExpiresAt == nil {
+		return
+	}
+	s.mu.Lock()
+	entry, exists := s.entries[order.ID]
+	if exists {
+		entry.at = *order.ExpiresAt
+		heap.Fix(&s.queue, entry.index)
+	} else {
+		entry = &expiryEntry{at: *order.ExpiresAt, orderID: order.ID, tradingPair: order.TradingPair}
+		heap.Push(&s.queue, entry)
+		s.entries[order.ID] = entry
+	}
+	earliest := entry.index == 0
+	s.mu.Unlock()
+
+	if earliest {
+		select {
+		case s.wake <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// unschedule 挂单离开订单簿时移除
+func (s *expiryScheduler) unschedule(orderID uuid.UUID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if entry, exists := s.entries[orderID]; exists {
+		heap.Remove(&s.queue, entry.index)
+		delete(s.entries, orderID)
+	}
+}
+
+// reset 清空到期队列（订单簿整体替换时使用）
+func (s *expiryScheduler) reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.queue = nil
+	s.entries = make(map[uuid.UUID]*expiryEntry)
+}
+
+// next 最早的到期时刻
+func (s *expiryScheduler) next() (time.Time, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.queue) == 0 {
+		return time.Time{}, false
+	}
+	return s.queue[0].at, true
+}
+
+// popDue 取出 now 之前（含）到期的全部挂单
+func (s *expiryScheduler) popDue(now time.Time) []*expiryEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var due []*expiryEntry
+	for len(s.queue) > 0 && !now.Before(s.queue[0].at) {
+		entry := heap.Pop(&s.queue).(*expiryEntry)
+		delete(s.entries, entry.orderID)
+		due = append(due, entry)
+	}
+	return due
+}
+
+// runExpiryScheduler 在最早到期时刻唤醒并移除到期挂单，使订单在截止时间后毫秒级内过期
+func (me *MatchingEngine) runExpiryScheduler() {
+	for {
+		wait := expiryIdleWait
+		if at, ok := me.expiries.next(); ok {
+			wait = time.Until(at)
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-me.expiries.wake:
+		}
+		timer.Stop()
+
+		me.expireDue(time.Now())
+	}
+}
+
+// expireDue 移除已到期的挂单，返回被移除订单的快照
+func (me *MatchingEngine) expireDue(now time.Time) []*types.Order {
+	var expired []*types.Order
+	for _, entry := range me.expiries.popDue(now) {
+		me.mu.RLock()
+		if orderBook, exists := me.orderBooks[entry.tradingPair]; exists {
+			orderBook.mu.Lock()
+			if order, exists := orderBook.Orders[entry.orderID]; exists && order.ExpiresAt != nil && !now.Before(*order.ExpiresAt) {
+				expired = append(expired, me.expireOrder(orderBook, order, now))
+			}
+			orderBook.unlock()
+		}
+		me.mu.RUnlock()
+	}
+	return expired
+}
@@ -0,0 +1,51 @@
+package matching
+
+import (
+	"errors"
+
+	"github.com/shopspring/decimal"
+
+	"orderbook-engine/internal/types"
+)
+
+// bpsDenominator 基点分母，与结算合约手续费计算一致
+var bpsDenominator = decimal.NewFromInt(10000)
+
+// FeeSchedule 成交手续费率（基点），与结算合约的 maker/taker 费率一致，手续费以报价代币计
+type FeeSchedule struct {
+	MakerBps decimal.Decimal `json:"maker_bps"`
+	TakerBps decimal.Decimal `json:"taker_bps"`
+}
+
+// Validate 校验费率
+func (f FeeSchedule) Validate() error {
+	for _, bps := range []decimal.Decimal{f.MakerBps, f.TakerBps} {
+		if bps.IsNegative() || bps.GreaterThan(bpsDenominator) {
+			return errors.New("fee bps must be between 0 and 10000")
+		}
+	}
+	return nil
+}
+
+// SetFeeSchedule 设置订单成交汇总（FeePaid）使用的手续费率，未设置时手续费为零
+func (me *MatchingEngine) SetFeeSchedule(schedule FeeSchedule) {
+	me.mu.Lock()
+	defer me.mu.Unlock()
+	me.fees = schedule
+}
+
+// applyFill 订单成交指定数量，更新成交数量、成交额、成交均价与手续费（调用方持有引擎读锁与订单簿锁）
+func (me *MatchingEngine) applyFill(order *types.Order, price, amount decimal.Decimal, taker bool) {
+	quote := price.Mul(amount)
+	bps := me.fees.MakerBps
+	if taker {
+		bps = me.fees.TakerBps
+	}
+
+	order.FilledAmount = order.FilledAmount.Add(amount)
+	order.QuoteFilled = order.QuoteFilled.Add(quote)
+	order.FeePaid = order.FeePaid.Add(quote.Mul(bps).Div(bpsDenominator))
+	if order.FilledAmount.IsPositive() {
+		order.AvgFillPrice = order.QuoteFilled.Div(order.FilledAmount)
+	}
+}
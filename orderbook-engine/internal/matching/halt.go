@@ -0,0 +1,37 @@
+package matching
+
+import (
+	"errors"
+	"time"
+
+	"orderbook-engine/internal/types"
+)
+
+// ErrSystemHalted 交易所紧急停机中，不接受新订单
+var ErrSystemHalted = errors.New("exchange is halted")
+
+// haltedAuctionRetry 停机期间到期的集合竞价顺延间隔
+const haltedAuctionRetry = time.Second
+
+// SetHalted 切换紧急停机
+// 停机期间拒绝全部新订单、集合竞价延后撮合；撤单、过期清理与行情查询不受影响
+func (me *MatchingEngine) SetHalted(halted bool) {
+	me.halted.Store(halted)
+
+	// 与 StopAccepting 相同，获取一次写锁等待在途撮合结束
+	me.mu.Lock()
+	me.mu.Unlock()
+}
+
+// Halted 引擎是否处于紧急停机
+func (me *MatchingEngine) Halted() bool {
+	return me.halted.Load()
+}
+
+// rejectWhileHalted 紧急停机期间拒绝新订单（调用方持有订单簿锁）
+func (me *MatchingEngine) rejectWhileHalted(order *types.Order) error {
+	if !me.halted.Load() {
+		return nil
+	}
+	return me.rejectOrder(order, types.StatusReasonSystemHalted, ErrSystemHalted)
+}
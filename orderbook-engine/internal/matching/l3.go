@@ -0,0 +1,122 @@
+package matching
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"orderbook-engine/internal/types"
+)
+
+// GetOrderBookL3 获取逐笔订单簿（L3），按价格优先、同价按时间顺序列出挂单
+// userAddress 不为空时只返回该用户的挂单及其排队位置，层级汇总仍为整个层级
+func (me *MatchingEngine) GetOrderBookL3(tradingPair string, depth int, userAddress string) *types.OrderBookL3Snapshot {
+	me.mu.RLock()
+	defer me.mu.RUnlock()
+
+	snapshot := &types.OrderBookL3Snapshot{
+		TradingPair: tradingPair,
+		Bids:        []types.OrderBookL3Level{},
+		Asks:        []types.OrderBookL3Level{},
+		Timestamp:   time.Now(),
+	}
+
+	orderBook, exists := me.orderBooks[tradingPair]
+	if !exists {
+		return snapshot
+	}
+	orderBook.mu.Lock()
+	defer orderBook.mu.Unlock()
+
+	snapshot.Bids = getL3Levels(orderBook.Bids, depth, userAddress)
+	snapshot.Asks = getL3Levels(orderBook.Asks, depth, userAddress)
+	return snapshot
+}
+
+// getL3Levels 获取价格层级的挂单明细，按用户过滤时跳过没有该用户挂单的层级
+func getL3Levels(priceLevel *PriceLevel, depth int, userAddress string) []types.OrderBookL3Level {
+	levels := []types.OrderBookL3Level{}
+	for _, queue := range priceLevel.Levels() {
+		if len(levels) >= depth {
+			break
+		}
+
+		level := types.OrderBookL3Level{
+			Price:  queue.Price,
+			Amount: queue.Total,
+			Count:  len(queue.Orders),
+			Orders: []types.OrderBookL3Order{},
+		}
+		for position, order := range queue.Orders {
+			if userAddress != "" && order.UserAddress != userAddress {
+				continue
+			}
+			level.Orders = append(level.Orders, types.OrderBookL3Order{
+				OrderID:   order.ID,
+				Amount:    order.GetRemainingAmount(),
+				Position:  position,
+				Timestamp: order.CreatedAt,
+			})
+		}
+		if userAddress != "" && len(level.Orders) == 0 {
+			continue
+		}
+		levels = append(levels, level)
+	}
+	return levels
+}
+
+// GetQueuePosition 获取挂单在价格层级内的排队位置，订单不在订单簿中时返回 false
+func (me *MatchingEngine) GetQueuePosition(orderID uuid.UUID) (*types.QueuePosition, bool) {
+	me.mu.RLock()
+	defer me.mu.RUnlock()
+
+	for _, orderBook := range me.orderBooks {
+		if position, ok := orderBook.queuePosition(orderID); ok {
+			return position, true
+		}
+	}
+	return nil, false
+}
+
+// queuePosition 查找订单簿中挂单的排队位置
+func (orderBook *OrderBook) queuePosition(orderID uuid.UUID) (*types.QueuePosition, bool) {
+	orderBook.mu.Lock()
+	defer orderBook.mu.Unlock()
+
+	order, exists := orderBook.Orders[orderID]
+	if !exists {
+		return nil, false
+	}
+
+	side := orderBook.Asks
+	if order.Side == types.OrderSideBuy {
+		side = orderBook.Bids
+	}
+	queue := side.Get(order.Price)
+	if queue == nil {
+		return nil, false
+	}
+
+	position := &types.QueuePosition{
+		OrderID:     order.ID,
+		TradingPair: orderBook.TradingPair,
+		Side:        order.Side,
+		Price:       queue.Price,
+		AmountAhead: decimal.Zero,
+		Remaining:   order.GetRemainingAmount(),
+		LevelAmount: queue.Total,
+		LevelCount:  len(queue.Orders),
+		IsBestPrice: side.Best() == queue,
+	}
+	for _, ahead := range queue.Orders {
+		if ahead.ID == order.ID {
+			break
+		}
+		position.Position++
+		position.AmountAhead = position.AmountAhead.Add(ahead.GetRemainingAmount())
+	}
+	position.OrdersAhead = position.Position
+	return position, true
+}
@@ -0,0 +1,140 @@
+package matching
+
+import (
+	"errors"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+
+	"orderbook-engine/internal/types"
+)
+
+// ErrNoLiquidity 对手方没有足够流动性，市价单被拒绝
+var ErrNoLiquidity = errors.New("insufficient liquidity for market order")
+
+// SetMinMarketLiquidity 设置市价单要求的对手方最小挂单名义价值（价格×数量），0表示只要求对手方非空
+func (me *MatchingEngine) SetMinMarketLiquidity(notional decimal.Decimal) {
+	me.mu.Lock()
+	defer me.mu.Unlock()
+	me.minMarketLiquidity = notional
+}
+
+// rejectIlliquidMarketOrder 对手方为空或挂单名义价值不足时拒绝市价单，并发送 order_rejected 事件
+func (me *MatchingEngine) rejectIlliquidMarketOrder(orderBook *OrderBook, order *types.Order) error {
+	if order.Type != types.OrderTypeMarket {
+		return nil
+	}
+
+	targetSide := orderBook.Asks
+	if order.Side == types.OrderSideSell {
+		targetSide = orderBook.Bids
+	}
+
+	notional := decimal.Zero
+	for _, queue := range targetSide.Levels() {
+		notional = notional.Add(queue.Price.Mul(queue.Total))
+	}
+	if notional.IsPositive() && notional.GreaterThanOrEqual(me.minMarketLiquidity) {
+		return nil
+	}
+
+	me.logger.WithFields(logrus.Fields{
+		"order_id":     order.ID.String(),
+		"trading_pair": order.TradingPair,
+		"side":         order.Side,
+		"liquidity":    notional.String(),
+		"min_notional": me.minMarketLiquidity.String(),
+	}).Warn("Market order rejected - insufficient liquidity")
+	return me.rejectOrder(order, types.StatusReasonNoLiquidity, ErrNoLiquidity)
+}
+
+// GetLiquidityMetrics 计算交易对的流动性指标
+// bandsBps 为中间价上下的基点区间，impactPercents 为价格冲击的百分比
+func (me *MatchingEngine) GetLiquidityMetrics(tradingPair string, bandsBps, impactPercents []decimal.Decimal) *types.LiquidityMetrics {
+	me.mu.RLock()
+	defer me.mu.RUnlock()
+
+	metrics := &types.LiquidityMetrics{
+		TradingPair: tradingPair,
+		Bands:       []types.DepthBand{},
+		Impact:      []types.PriceImpact{},
+		Timestamp:   time.Now(),
+	}
+
+	orderBook, exists := me.orderBooks[tradingPair]
+	if !exists {
+		return metrics
+	}
+	orderBook.mu.Lock()
+	defer orderBook.mu.Unlock()
+
+	metrics.Sequence = orderBook.Sequence
+	bidTotal, askTotal := sideAmount(orderBook.Bids), sideAmount(orderBook.Asks)
+	metrics.Imbalance = imbalance(bidTotal, askTotal)
+
+	bid, hasBid := orderBook.bestPrice(types.OrderSideBuy)
+	ask, hasAsk := orderBook.bestPrice(types.OrderSideSell)
+	if !hasBid || !hasAsk {
+		return metrics
+	}
+	mid := bid.Add(ask).Div(decimal.NewFromInt(2))
+	metrics.MidPrice = &mid
+
+	for _, bps := range bandsBps {
+		offset := mid.Mul(bps).Div(decimal.NewFromInt(10000))
+		band := types.DepthBand{Bps: bps}
+		band.BidAmount, band.BidNotional = sideDepth(orderBook.Bids, func(price decimal.Decimal) bool {
+			return price.GreaterThanOrEqual(mid.Sub(offset))
+		})
+		band.AskAmount, band.AskNotional = sideDepth(orderBook.Asks, func(price decimal.Decimal) bool {
+			return price.LessThanOrEqual(mid.Add(offset))
+		})
+		band.Imbalance = imbalance(band.BidAmount, band.AskAmount)
+		metrics.Bands = append(metrics.Bands, band)
+	}
+
+	// 价格被推动到目标价，需要吃掉目标价以内（不含）的全部对手方挂单
+	for _, percent := range impactPercents {
+		offset := mid.Mul(percent).Div(decimal.NewFromInt(100))
+		upper, lower := mid.Add(offset), mid.Sub(offset)
+		impact := types.PriceImpact{Percent: percent}
+		impact.BuyAmount, impact.BuyNotional = sideDepth(orderBook.Asks, func(price decimal.Decimal) bool {
+			return price.LessThan(upper)
+		})
+		impact.SellAmount, impact.SellNotional = sideDepth(orderBook.Bids, func(price decimal.Decimal) bool {
+			return price.GreaterThan(lower)
+		})
+		impact.BuyExhausted = impact.BuyAmount.Equal(askTotal)
+		impact.SellExhausted = impact.SellAmount.Equal(bidTotal)
+		metrics.Impact = append(metrics.Impact, impact)
+	}
+	return metrics
+}
+
+// sideDepth 从最优价开始累计满足条件的价格层级，遇到第一个不满足的层级即停止
+func sideDepth(side *PriceLevel, within func(price decimal.Decimal) bool) (amount, notional decimal.Decimal) {
+	for _, queue := range side.Levels() {
+		if within != nil && !within(queue.Price) {
+			break
+		}
+		amount = amount.Add(queue.Total)
+		notional = notional.Add(queue.Price.Mul(queue.Total))
+	}
+	return amount, notional
+}
+
+// sideAmount 一方全部挂单数量
+func sideAmount(side *PriceLevel) decimal.Decimal {
+	amount, _ := sideDepth(side, nil)
+	return amount
+}
+
+// imbalance 买卖数量失衡度 (买-卖)/(买+卖)，双方都为空时为 0
+func imbalance(bid, ask decimal.Decimal) decimal.Decimal {
+	total := bid.Add(ask)
+	if total.IsZero() {
+		return decimal.Zero
+	}
+	return bid.Sub(ask).Div(total).Round(4)
+}
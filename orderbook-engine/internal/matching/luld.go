@@ -0,0 +1,166 @@
+package matching
+
+import (
+	"errors"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+)
+
+// DynamicBand 动态价格带（涨跌停，LULD）
+// Window 内成交价相对窗口内最高或最低成交价的变动超过 TriggerPercent 时，以触发成交价为新参考价，
+// 价格带收紧到 TightPercent，随后在 RelaxDuration 内线性放宽回静态价格带；TriggerPercent 为 0 表示不启用
+type DynamicBand struct {
+	TriggerPercent decimal.Decimal `json:"trigger_percent"`
+	Window         time.Duration   `json:"window"`
+	TightPercent   decimal.Decimal `json:"tight_percent"`
+	RelaxDuration  time.Duration   `json:"relax_duration"`
+}
+
+// Enabled 是否启用动态价格带
+func (d DynamicBand) Enabled() bool {
+	return d.TriggerPercent.IsPositive()
+}
+
+// Validate 校验动态价格带
+func (d DynamicBand) Validate() error {
+	if d.TriggerPercent.IsNegative() {
+		return errors.New("dynamic trigger percent must not be negative")
+	}
+	if !d.Enabled() {
+		return nil
+	}
+	if d.Window <= 0 || d.RelaxDuration <= 0 {
+		return errors.New("dynamic window and relax duration must be positive")
+	}
+	if !d.TightPercent.IsPositive() {
+		return errors.New("dynamic tight percent must be positive")
+	}
+	return nil
+}
+
+// BandState 交易对当前生效的价格带（行情接口展示）
+type BandState struct {
+	TradingPair         string          `json:"trading_pair"`
+	ReferencePrice      decimal.Decimal `json:"reference_price"`
+	MaxDeviationPercent decimal.Decimal `json:"max_deviation_percent"`
+	LowerPrice          decimal.Decimal `json:"lower_price"`
+	UpperPrice          decimal.Decimal `json:"upper_price"`
+	Dynamic             bool            `json:"dynamic"` // 处于急涨急跌后的收紧期
+	TriggeredAt         *time.Time      `json:"triggered_at,omitempty"`
+	RelaxedAt           *time.Time      `json:"relaxed_at,omitempty"` // 恢复静态价格带的时间
+}
+
+// bandPoint 窗口内的成交价
+type bandPoint struct {
+	at    time.Time
+	price decimal.Decimal
+}
+
+// bandTracker 订单簿的动态价格带状态，由订单簿锁保护
+type bandTracker struct {
+	trades      []bandPoint
+	reference   decimal.Decimal // 触发时的成交价
+	triggeredAt time.Time
+}
+
+// recordBandTrade 记录成交价，急涨急跌时触发动态价格带（调用方持有订单簿锁）
+func (me *MatchingEngine) recordBandTrade(orderBook *OrderBook, price decimal.Decimal, now time.Time) {
+	dynamic := me.priceBands.Dynamic
+	if !dynamic.Enabled() || !price.IsPositive() {
+		return
+	}
+
+	tracker := &orderBook.band
+	cutoff := now.Add(-dynamic.Window)
+	kept := tracker.trades[:0]
+	low, high := price, price
+	for _, point := range tracker.trades {
+		if point.at.Before(cutoff) {
+			continue
+		}
+		kept = append(kept, point)
+		low = decimal.Min(low, point.price)
+		high = decimal.Max(high, point.price)
+	}
+	tracker.trades = append(kept, bandPoint{at: now, price: price})
+
+	hundred := decimal.NewFromInt(100)
+	move := decimal.Max(price.Sub(low).Div(low), high.Sub(price).Div(high)).Mul(hundred)
+	if move.LessThanOrEqual(dynamic.TriggerPercent) {
+		return
+	}
+
+	tracker.reference = price
+	tracker.triggeredAt = now
+	tracker.trades = []bandPoint{{at: now, price: price}}
+	me.logger.WithFields(logrus.Fields{
+		"trading_pair": orderBook.TradingPair,
+		"price":        price.String(),
+		"move_percent": move.StringFixed(2),
+		"tight":        dynamic.TightPercent.String(),
+		"relax":        dynamic.RelaxDuration.String(),
+	}).Warn("⚠️ Rapid price move - price band tightened")
+}
+
+// currentBand 当前生效的参考价与偏离上限，没有参考价或未设置价格带时返回 false（调用方持有订单簿锁）
+// 收紧期内参考价为触发成交价，偏离上限从 TightPercent 线性放宽到静态价格带；静态价格带不大于 TightPercent 或未设置时收紧期内保持 TightPercent
+func (me *MatchingEngine) currentBand(orderBook *OrderBook, now time.Time) (*BandState, bool) {
+	static := me.priceBands.For(orderBook.TradingPair).MaxDeviationPercent
+	state := &BandState{TradingPair: orderBook.TradingPair, MaxDeviationPercent: static}
+
+	dynamic := me.priceBands.Dynamic
+	tracker := &orderBook.band
+	if dynamic.Enabled() && !tracker.triggeredAt.IsZero() && now.Before(tracker.triggeredAt.Add(dynamic.RelaxDuration)) {
+		triggeredAt := tracker.triggeredAt
+		relaxedAt := triggeredAt.Add(dynamic.RelaxDuration)
+		state.Dynamic = true
+		state.TriggeredAt = &triggeredAt
+		state.RelaxedAt = &relaxedAt
+		state.ReferencePrice = tracker.reference
+		state.MaxDeviationPercent = dynamic.TightPercent
+		if static.GreaterThan(dynamic.TightPercent) {
+			progress := decimal.NewFromInt(int64(now.Sub(triggeredAt))).Div(decimal.NewFromInt(int64(dynamic.RelaxDuration)))
+			state.MaxDeviationPercent = dynamic.TightPercent.Add(static.Sub(dynamic.TightPercent).Mul(progress))
+		}
+	} else {
+		if !static.IsPositive() {
+			return nil, false
+		}
+		reference, ok := referencePrice(orderBook)
+		if !ok {
+			return nil, false
+		}
+		state.ReferencePrice = reference
+	}
+
+	width := state.ReferencePrice.Mul(state.MaxDeviationPercent).Div(decimal.NewFromInt(100))
+	state.LowerPrice = decimal.Max(state.ReferencePrice.Sub(width), decimal.Zero)
+	state.UpperPrice = state.ReferencePrice.Add(width)
+	return state, true
+}
+
+// withinBand 价格是否在当前价格带内
+func (b *BandState) withinBand(price decimal.Decimal) bool {
+	return price.GreaterThanOrEqual(b.LowerPrice) && price.LessThanOrEqual(b.UpperPrice)
+}
+
+// GetPriceBand 获取交易对当前生效的价格带，未设置价格带或没有参考价时返回 nil
+func (me *MatchingEngine) GetPriceBand(tradingPair string) *BandState {
+	me.mu.RLock()
+	defer me.mu.RUnlock()
+
+	orderBook, exists := me.orderBooks[tradingPair]
+	if !exists {
+		return nil
+	}
+	orderBook.mu.Lock()
+	defer orderBook.mu.Unlock()
+
+	state, ok := me.currentBand(orderBook, time.Now())
+	if !ok {
+		return nil
+	}
+	return state
+}
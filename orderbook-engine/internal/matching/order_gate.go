@@ -0,0 +1,43 @@
+package matching
+
+import (
+	"errors"
+
+	"orderbook-engine/internal/types"
+)
+
+var (
+	// ErrPairNotTradable 交易对未开盘或已下架
+	ErrPairNotTradable = errors.New("trading pair not open for orders")
+	// ErrInvalidIncrement 价格或数量不是交易对最小变动单位的整数倍
+	ErrInvalidIncrement = errors.New("price or amount not a multiple of the tick or lot size")
+)
+
+// OrderGate 订单闸门（交易对上下架、最小变动单位等）
+// AllowOrder 返回包装 ErrPairNotTradable 或 ErrInvalidIncrement 的错误时拒绝新订单，REST 与链上入口均经过该检查
+type OrderGate interface {
+	AllowOrder(order *types.Order) error
+}
+
+// AddOrderGate 注册订单闸门
+func (me *MatchingEngine) AddOrderGate(gate OrderGate) {
+	me.mu.Lock()
+	defer me.mu.Unlock()
+	me.orderGates = append(me.orderGates, gate)
+}
+
+// rejectGatedOrder 拒绝被订单闸门拦截的新订单
+func (me *MatchingEngine) rejectGatedOrder(order *types.Order) error {
+	for _, gate := range me.orderGates {
+		err := gate.AllowOrder(order)
+		if err == nil {
+			continue
+		}
+		reason := types.StatusReasonPairNotTradable
+		if errors.Is(err, ErrInvalidIncrement) {
+			reason = types.StatusReasonInvalidIncrement
+		}
+		return me.rejectOrder(order, reason, err)
+	}
+	return nil
+}
@@ -0,0 +1,300 @@
+package matching
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync/atomic"
+)
+
+// OverflowPolicy 消费者缓冲满时的处理策略
+type OverflowPolicy string
+
+const (
+	OverflowBlock  OverflowPolicy = "block"  // 阻塞发布直到消费者腾出缓冲（撮合随之停顿）
+	OverflowDrop   OverflowPolicy = "drop"   // 丢弃事件并计数，适用于行情推送等可丢失的消费者
+	OverflowExpand OverflowPolicy = "expand" // 溢出部分暂存到内存队列，按序转发，内存随积压增长
+	OverflowSpill  OverflowPolicy = "spill"  // 溢出部分写入磁盘文件，按序转发，写盘失败时退化为内存暂存
+)
+
+// ParseOverflowPolicy 解析溢出策略
+func ParseOverflowPolicy(value string) (OverflowPolicy, error) {
+	switch policy := OverflowPolicy(value); policy {
+	case OverflowBlock, OverflowDrop, OverflowExpand, OverflowSpill:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("unknown event overflow policy %q, expected block, drop, expand or spill", value)
+	}
+}
+
+// EventBackpressure 事件订阅的默认缓冲与溢出策略，订阅选项未指定时使用
+type EventBackpressure struct {
+	BufferSize int            // 每个消费者的通道缓冲，0 使用默认值
+	Overflow   OverflowPolicy // 不可丢弃消费者缓冲满时的策略，为空表示 block
+	SpillDir   string         // spill 策略的溢出文件目录
+}
+
+// SetEventBackpressure 设置事件订阅的默认缓冲与溢出策略，只影响之后注册的消费者
+func (me *MatchingEngine) SetEventBackpressure(config EventBackpressure) error {
+	if config.Overflow == "" {
+		config.Overflow = OverflowBlock
+	}
+	if _, err := ParseOverflowPolicy(string(config.Overflow)); err != nil {
+		return err
+	}
+	if config.Overflow == OverflowSpill {
+		if config.SpillDir == "" {
+			return fmt.Errorf("spill overflow policy requires a spill directory")
+		}
+		if err := os.MkdirAll(config.SpillDir, 0o755); err != nil {
+			return fmt.Errorf("failed to create event spill directory: %w", err)
+		}
+	}
+
+	me.events.mu.Lock()
+	defer me.events.mu.Unlock()
+	me.events.defaults = config
+	return nil
+}
+
+// overflowQueue 消费者通道满时暂存事件的先进先出队列
+type overflowQueue interface {
+	push(event *MatchEvent)
+	pop() (*MatchEvent, bool, error)
+	len() int
+	close()
+}
+
+// memoryQueue 内存溢出队列
+type memoryQueue struct {
+	events []*MatchEvent
+	head   int
+}
+
+func (q *memoryQueue) push(event *MatchEvent) {
+	q.events = append(q.events, event)
+}
+
+func (q *memoryQueue) pop() (*MatchEvent, bool, error) {
+	if q.head >= len(q.events) {
+		return nil, false, nil
+	}
+	event := q.events[q.head]
+	q.events[q.head] = nil
+	q.head++
+	// 已消费部分过半时压缩，避免底层数组只增不减
+	if q.head == len(q.events) {
+		q.events, q.head = q.events[:0], 0
+	} else if q.head > len(q.events)/2 {
+		q.events, q.head = append([]*MatchEvent(nil), q.events[q.head:]...), 0
+	}
+	return event, true, nil
+}
+
+func (q *memoryQueue) len() int {
+	return len(q.events) - q.head
+}
+
+func (q *memoryQueue) close() {
+	q.events, q.head = nil, 0
+}
+
+// spillQueue 磁盘溢出队列，事件按 JSON 行追加写入文件，队列清空后截断文件
+// 写盘失败时后续事件改存内存，直到队列清空，保证转发顺序不变
+type spillQueue struct {
+	path     string
+	writer   *os.File
+	reader   *bufio.Reader
+	readFile *os.File
+	onDisk   int
+	memory   memoryQueue
+	degraded bool
+	errors   *uint64
+}
+
+// spillName 溢出文件名中只保留安全字符
+var spillName = regexp.MustCompile(`[^A-Za-z0-9_-]+`)
+
+// spillSeq 溢出文件序号，同名消费者重复订阅时互不覆盖
+var spillSeq uint64
+
+func newSpillQueue(dir, consumer string, errors *uint64) *spillQueue {
+	name := spillName.ReplaceAllString(consumer, "_")
+	return &spillQueue{
+		path:   filepath.Join(dir, fmt.Sprintf("%s-%d-%d.jsonl", name, os.Getpid(), atomic.AddUint64(&spillSeq, 1))),
+		errors: errors,
+	}
+}
+
+func (q *spillQueue) push(event *MatchEvent) {
+	if !q.degraded {
+		if err := q.write(event); err == nil {
+			q.onDisk++
+			return
+		}
+		atomic.AddUint64(q.errors, 1)
+		q.degraded = true
+	}
+	q.memory.push(event)
+}
+
+// write 追加一行事件，首次写入时创建文件
+func (q *spillQueue) write(event *MatchEvent) error {
+	if q.writer == nil {
+		writer, err := os.OpenFile(q.path, os.O_CREATE|os.O_RDWR|os.O_TRUNC, 0o644)
+		if err != nil {
+			return err
+		}
+		readFile, err := os.Open(q.path)
+		if err != nil {
+			writer.Close()
+			return err
+		}
+		q.writer, q.readFile, q.reader = writer, readFile, bufio.NewReader(readFile)
+	}
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = q.writer.Write(append(data, '\n'))
+	return err
+}
+
+func (q *spillQueue) pop() (*MatchEvent, bool, error) {
+	if q.onDisk > 0 {
+		q.onDisk--
+		line, err := q.reader.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			q.resetIfEmpty()
+			return nil, true, err
+		}
+		var event MatchEvent
+		if err := json.Unmarshal(line, &event); err != nil {
+			q.resetIfEmpty()
+			return nil, true, err
+		}
+		q.resetIfEmpty()
+		return &event, true, nil
+	}
+	event, ok, _ := q.memory.pop()
+	q.resetIfEmpty()
+	return event, ok, nil
+}
+
+// resetIfEmpty 队列清空后截断文件并恢复写盘
+func (q *spillQueue) resetIfEmpty() {
+	if q.onDisk > 0 || q.memory.len() > 0 {
+		return
+	}
+	q.degraded = false
+	if q.writer == nil {
+		return
+	}
+	if err := q.writer.Truncate(0); err == nil {
+		if _, err := q.writer.Seek(0, io.SeekStart); err == nil {
+			if _, err := q.readFile.Seek(0, io.SeekStart); err == nil {
+				q.reader.Reset(q.readFile)
+				return
+			}
+		}
+	}
+	// 无法复用文件时关闭，下次溢出重新创建
+	q.closeFiles()
+}
+
+func (q *spillQueue) len() int {
+	return q.onDisk + q.memory.len()
+}
+
+func (q *spillQueue) close() {
+	q.closeFiles()
+	q.onDisk = 0
+	q.memory.close()
+}
+
+func (q *spillQueue) closeFiles() {
+	if q.writer == nil {
+		return
+	}
+	q.writer.Close()
+	q.readFile.Close()
+	os.Remove(q.path)
+	q.writer, q.readFile, q.reader = nil, nil, nil
+}
+
+// enqueue 不阻塞地投递事件：没有积压时直接写入通道，通道满或已有积压时进入溢出队列，由转发协程按序送达
+func (s *Subscription) enqueue(event *MatchEvent) {
+	s.mu.Lock()
+	if s.pending.len() == 0 && !s.forwarding {
+		select {
+		case s.ch <- event:
+			s.mu.Unlock()
+			return
+		default:
+		}
+	}
+	s.pending.push(event)
+	overflowed := atomic.AddUint64(&s.overflowed, 1)
+	s.mu.Unlock()
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+	if overflowed%10000 == 1 {
+		s.logger.WithField("consumer", s.name).WithField("policy", s.overflow).Warn("Matching event consumer lagging, buffering overflow")
+	}
+}
+
+// forward 将溢出队列中的事件按序转发到消费者通道，直到订阅注销
+func (s *Subscription) forward() {
+	defer close(s.finished)
+	for {
+		select {
+		case <-s.wake:
+		case <-s.done:
+			return
+		}
+
+		for {
+			s.mu.Lock()
+			event, ok, err := s.pending.pop()
+			if !ok {
+				s.forwarding = false
+				s.mu.Unlock()
+				break
+			}
+			s.forwarding = true
+			s.mu.Unlock()
+
+			if err != nil {
+				atomic.AddUint64(&s.spillErrors, 1)
+				s.logger.WithError(err).WithField("consumer", s.name).Error("Failed to read spilled matching event")
+				continue
+			}
+			select {
+			case s.ch <- event:
+			case <-s.done:
+				return
+			}
+		}
+	}
+}
+
+// overflowDepth 溢出队列中的事件数，含转发协程已取出、尚未送达通道的一个
+func (s *Subscription) overflowDepth() int {
+	if s.pending == nil {
+		return 0
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	depth := s.pending.len()
+	if s.forwarding {
+		depth++
+	}
+	return depth
+}
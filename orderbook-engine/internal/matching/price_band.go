@@ -0,0 +1,102 @@
+package matching
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+
+	"orderbook-engine/internal/types"
+)
+
+// ErrPriceOutOfBand 订单价格超出交易对价格带
+var ErrPriceOutOfBand = errors.New("order price outside the price band")
+
+// PriceBand 价格带：限价偏离参考价超过 MaxDeviationPercent（百分比）的订单被拒绝，0表示不限制
+type PriceBand struct {
+	MaxDeviationPercent decimal.Decimal `json:"max_deviation_percent"`
+}
+
+// PriceBands 全局价格带及按交易对的覆盖，Dynamic 为急涨急跌后的动态收紧
+type PriceBands struct {
+	Default PriceBand            `json:"default"`
+	Pairs   map[string]PriceBand `json:"pairs,omitempty"` // 交易对(大写) -> 价格带
+	Dynamic DynamicBand          `json:"dynamic"`
+}
+
+// Validate 校验价格带
+func (b PriceBands) Validate() error {
+	if b.Default.MaxDeviationPercent.IsNegative() {
+		return errors.New("max deviation percent must not be negative")
+	}
+	for pair, band := range b.Pairs {
+		if band.MaxDeviationPercent.IsNegative() {
+			return fmt.Errorf("%s: max deviation percent must not be negative", pair)
+		}
+	}
+	return b.Dynamic.Validate()
+}
+
+// For 交易对适用的价格带
+func (b PriceBands) For(tradingPair string) PriceBand {
+	if band, ok := b.Pairs[strings.ToUpper(tradingPair)]; ok {
+		return band
+	}
+	return b.Default
+}
+
+// SetPriceBands 设置价格带
+// 价格带在引擎内执行，REST、链上、做市和管理入口的订单都经过检查，风控之外兜底防止离谱价位进入订单簿
+func (me *MatchingEngine) SetPriceBands(bands PriceBands) error {
+	if err := bands.Validate(); err != nil {
+		return err
+	}
+	pairs := make(map[string]PriceBand, len(bands.Pairs))
+	for pair, band := range bands.Pairs {
+		pairs[strings.ToUpper(pair)] = band
+	}
+	bands.Pairs = pairs
+
+	me.mu.Lock()
+	defer me.mu.Unlock()
+	me.priceBands = bands
+	return nil
+}
+
+// referencePrice 价格带的参考价：最新成交价，尚无成交时为买一卖一中间价（调用方持有订单簿锁）
+func referencePrice(orderBook *OrderBook) (decimal.Decimal, bool) {
+	if orderBook.LastPrice.IsPositive() {
+		return orderBook.LastPrice, true
+	}
+	bid, ask := orderBook.Bids.Best(), orderBook.Asks.Best()
+	if bid == nil || ask == nil {
+		return decimal.Zero, false
+	}
+	return bid.Price.Add(ask.Price).Div(decimal.NewFromInt(2)), true
+}
+
+// rejectOutOfBandOrder 拒绝限价超出当前价格带的订单；市价单由撮合时的价格带检查约束，没有参考价的交易对不检查
+func (me *MatchingEngine) rejectOutOfBandOrder(orderBook *OrderBook, order *types.Order) error {
+	if order.Type == types.OrderTypeMarket || !order.Price.IsPositive() {
+		return nil
+	}
+	band, ok := me.currentBand(orderBook, time.Now())
+	if !ok || band.withinBand(order.Price) {
+		return nil
+	}
+
+	me.logger.WithFields(logrus.Fields{
+		"order_id":      order.ID.String(),
+		"trading_pair":  order.TradingPair,
+		"price":         order.Price.String(),
+		"reference":     band.ReferencePrice.String(),
+		"max_deviation": band.MaxDeviationPercent.StringFixed(2),
+		"dynamic":       band.Dynamic,
+	}).Warn("Order rejected - price outside band")
+	return me.rejectOrder(order, types.StatusReasonPriceOutOfBand,
+		fmt.Errorf("%w: price %s outside %s-%s (reference %s, limit %s%%)", ErrPriceOutOfBand,
+			order.Price, band.LowerPrice, band.UpperPrice, band.ReferencePrice, band.MaxDeviationPercent.StringFixed(2)))
+}
@@ -0,0 +1,183 @@
+package matching
+
+import (
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"orderbook-engine/internal/types"
+)
+
+// PriceLevel 一侧订单簿的价格层级
+// 层级按价格有序保存（买单降序、卖单升序，最优价在前），map 用于按价格直接查找；
+// 两者只通过 addOrder/removeOrder 一起修改，层级清空时同时移除，不会残留空层级；
+// map 以规范化的整数价格为键，清空的队列回收复用
+type PriceLevel struct {
+	levels map[priceKey]*PriceLevelQueue
+	sorted []*PriceLevelQueue // 最优价在前
+	free   []*PriceLevelQueue // 已清空的队列，新建层级时复用
+	isBuy  bool
+}
+
+// priceKey 价格层级的整数键：规范化的系数与十进制指数（去掉末尾的零），
+// 2000 与 2000.00 得到相同的键；系数超出 int64 时退化为字符串
+type priceKey struct {
+	coef     int64
+	exp      int32
+	overflow string
+}
+
+// maxFreeQueues 每侧最多缓存的空队列数
+const maxFreeQueues = 64
+
+// newPriceKey 计算价格的整数键，避免撮合热路径上的 decimal.String() 分配
+func newPriceKey(price decimal.Decimal) priceKey {
+	coef := price.Coefficient()
+	if !coef.IsInt64() {
+		return priceKey{overflow: price.String()}
+	}
+
+	c, exp := coef.Int64(), price.Exponent()
+	if c == 0 {
+		return priceKey{}
+	}
+	for c%10 == 0 {
+		c /= 10
+		exp++
+	}
+	return priceKey{coef: c, exp: exp}
+}
+
+// PriceLevelQueue 同一价格的订单队列（价格-时间优先）
+// Total 为队列中订单剩余数量之和，成交时由撮合方同步扣减
+type PriceLevelQueue struct {
+	Price     decimal.Decimal
+	Orders    []*types.Order // 按时间顺序排列（FIFO）
+	Total     decimal.Decimal
+	OrdersMap map[uuid.UUID]*types.Order // 快速查找
+}
+
+// newPriceLevel 创建一侧订单簿
+func newPriceLevel(isBuy bool) *PriceLevel {
+	return &PriceLevel{
+		levels: make(map[priceKey]*PriceLevelQueue),
+		isBuy:  isBuy,
+	}
+}
+
+// Len 价格层级数
+func (pl *PriceLevel) Len() int {
+	return len(pl.sorted)
+}
+
+// Best 最优价格层级，没有挂单时返回 nil
+func (pl *PriceLevel) Best() *PriceLevelQueue {
+	if len(pl.sorted) == 0 {
+		return nil
+	}
+	return pl.sorted[0]
+}
+
+// Get 按价格查找层级
+func (pl *PriceLevel) Get(price decimal.Decimal) *PriceLevelQueue {
+	return pl.levels[newPriceKey(price)]
+}
+
+// Levels 按价格优先顺序返回全部层级，调用方不得修改返回的切片
+func (pl *PriceLevel) Levels() []*PriceLevelQueue {
+	return pl.sorted
+}
+
+// better price 是否优于 other（买单价高者优先，卖单价低者优先）
+func (pl *PriceLevel) better(price, other decimal.Decimal) bool {
+	if pl.isBuy {
+		return price.GreaterThan(other)
+	}
+	return price.LessThan(other)
+}
+
+// search 返回 price 在有序层级中的位置（第一个不优于 price 的层级）
+func (pl *PriceLevel) search(price decimal.Decimal) int {
+	return sort.Search(len(pl.sorted), func(i int) bool {
+		return !pl.better(pl.sorted[i].Price, price)
+	})
+}
+
+// addOrder 订单加入对应价格层级队尾，层级不存在时按价格插入
+func (pl *PriceLevel) addOrder(order *types.Order) *PriceLevelQueue {
+	key := newPriceKey(order.Price)
+	queue, exists := pl.levels[key]
+	if !exists {
+		queue = pl.newQueue(order.Price)
+		pl.levels[key] = queue
+
+		i := pl.search(order.Price)
+		pl.sorted = append(pl.sorted, nil)
+		copy(pl.sorted[i+1:], pl.sorted[i:])
+		pl.sorted[i] = queue
+	}
+
+	queue.Orders = append(queue.Orders, order)
+	queue.OrdersMap[order.ID] = order
+	queue.Total = queue.Total.Add(order.GetRemainingAmount())
+	return queue
+}
+
+// removeOrder 从价格层级移除订单并扣减其剩余数量，层级清空时一并移除；订单不在层级中时返回 false
+func (pl *PriceLevel) removeOrder(order *types.Order) bool {
+	key := newPriceKey(order.Price)
+	queue, exists := pl.levels[key]
+	if !exists || queue.OrdersMap[order.ID] == nil {
+		return false
+	}
+
+	for i, o := range queue.Orders {
+		if o.ID == order.ID {
+			queue.Orders = append(queue.Orders[:i], queue.Orders[i+1:]...)
+			queue.Total = queue.Total.Sub(o.GetRemainingAmount())
+			break
+		}
+	}
+	delete(queue.OrdersMap, order.ID)
+
+	if len(queue.Orders) == 0 {
+		delete(pl.levels, key)
+		i := pl.search(queue.Price)
+		if i < len(pl.sorted) && pl.sorted[i] == queue {
+			copy(pl.sorted[i:], pl.sorted[i+1:])
+			pl.sorted[len(pl.sorted)-1] = nil
+			pl.sorted = pl.sorted[:len(pl.sorted)-1]
+		}
+		pl.releaseQueue(queue)
+	}
+	return true
+}
+
+// newQueue 创建价格层级队列，优先复用已清空的队列
+func (pl *PriceLevel) newQueue(price decimal.Decimal) *PriceLevelQueue {
+	if n := len(pl.free); n > 0 {
+		queue := pl.free[n-1]
+		pl.free[n-1] = nil
+		pl.free = pl.free[:n-1]
+		queue.Price = price
+		queue.Total = decimal.Zero
+		return queue
+	}
+	return &PriceLevelQueue{
+		Price:     price,
+		Orders:    make([]*types.Order, 0, 8),
+		Total:     decimal.Zero,
+		OrdersMap: make(map[uuid.UUID]*types.Order),
+	}
+}
+
+// releaseQueue 回收已清空的队列
+// 对外返回的切片均为副本（见 GetOrdersAtPrice），队列回收后不会被外部继续引用
+func (pl *PriceLevel) releaseQueue(queue *PriceLevelQueue) {
+	if len(pl.free) >= maxFreeQueues {
+		return
+	}
+	queue.Orders = queue.Orders[:0]
+	pl.free = append(pl.free, queue)
+}
@@ -0,0 +1,65 @@
+package matching
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"orderbook-engine/internal/types"
+)
+
+// ReduceOrder 将挂单的剩余数量缩减到 remaining，保持其在价格层级中的排队位置
+// remaining 不小于当前剩余数量时不做修改；remaining 不大于0时撤销挂单（StatusReason 为 REDUCE_ONLY）
+// 返回修改后的订单快照，挂单不存在或未修改时返回 false
+func (me *MatchingEngine) ReduceOrder(orderID uuid.UUID, tradingPair string, remaining decimal.Decimal) (*types.Order, bool) {
+	me.mu.RLock()
+	defer me.mu.RUnlock()
+
+	orderBook, exists := me.orderBooks[tradingPair]
+	if !exists {
+		return nil, false
+	}
+	orderBook.mu.Lock()
+	defer orderBook.unlock()
+
+	order, exists := orderBook.Orders[orderID]
+	if !exists {
+		return nil, false
+	}
+	current := order.GetRemainingAmount()
+	if !remaining.LessThan(current) {
+		return nil, false
+	}
+
+	now := time.Now()
+	eventType := EventOrderReduced
+	if !remaining.IsPositive() {
+		me.removeOrderFromBook(orderBook, order)
+		order.ReducedAmount = order.ReducedAmount.Add(current)
+		order.Status = types.OrderStatusCancelled
+		order.StatusReason = types.StatusReasonReduceOnly
+		eventType = EventOrderCancelled
+	} else {
+		side := orderBook.Asks
+		if order.Side == types.OrderSideBuy {
+			side = orderBook.Bids
+		}
+		delta := current.Sub(remaining)
+		if queue := side.Get(order.Price); queue != nil {
+			queue.Total = queue.Total.Sub(delta)
+		}
+		order.ReducedAmount = order.ReducedAmount.Add(delta)
+		orderBook.Sequence++
+	}
+	order.UpdatedAt = now
+
+	snapshot := snapshotOrder(order)
+	me.publish(&MatchEvent{
+		Type:        eventType,
+		TradingPair: tradingPair,
+		Order:       snapshot,
+		Timestamp:   now,
+	})
+	return snapshot, true
+}
@@ -0,0 +1,33 @@
+package matching
+
+import (
+	"fmt"
+	"time"
+
+	"orderbook-engine/internal/types"
+)
+
+// rejectOrder 拒绝新订单并发布 order_rejected 事件（调用方持有订单簿锁）
+// reason 为原因代码，cause 的描述记录在 RejectReason 中，返回包装 cause 的错误
+func (me *MatchingEngine) rejectOrder(order *types.Order, reason string, cause error) error {
+	now := time.Now()
+	order.Status = types.OrderStatusRejected
+	order.StatusReason = reason
+	order.RejectReason = cause.Error()
+	order.UpdatedAt = now
+	me.publish(&MatchEvent{
+		Type:        EventOrderRejected,
+		TradingPair: order.TradingPair,
+		Order:       snapshotOrder(order),
+		Timestamp:   now,
+	})
+	return fmt.Errorf("order %s rejected: %w", order.ID, cause)
+}
+
+// RejectOrder 记录在进入撮合前被拒绝的订单（风控、余额、参数校验等），发布 order_rejected 事件
+// 订单不进入订单簿，事件供推送、事件日志和外部事件总线消费
+func (me *MatchingEngine) RejectOrder(order *types.Order, reason string, cause error) {
+	me.mu.RLock()
+	defer me.mu.RUnlock()
+	me.rejectOrder(order, reason, cause)
+}
@@ -0,0 +1,69 @@
+package matching
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"orderbook-engine/internal/types"
+)
+
+var (
+	// ErrMinRestingTime 挂单未满交易对最短停留时间，撤单被拒绝
+	ErrMinRestingTime = errors.New("order has not rested for the minimum time")
+	// ErrOrderNotResting 订单不在订单簿中（已成交、已撤销或不存在）
+	ErrOrderNotResting = errors.New("order not resting in the book")
+)
+
+// RestingPolicy 挂单最短停留时间（按交易对，交易对登记表提供），0 表示不限制
+type RestingPolicy interface {
+	MinRestingTime(tradingPair string) time.Duration
+}
+
+// SetRestingPolicy 设置挂单最短停留时间策略，为空时不限制
+// 限制挂单后立即撤单的闪烁报价（quote flicker），只作用于 CancelUserOrder；
+// 管理员、下架、冻结、到期、链上撤单及会话吊销等撤单不受限制
+func (me *MatchingEngine) SetRestingPolicy(policy RestingPolicy) {
+	me.mu.Lock()
+	defer me.mu.Unlock()
+	me.resting = policy
+}
+
+// CancelUserOrder 用户撤单，返回撤销后的订单快照
+// 挂单时间（CreatedAt）未满交易对最短停留时间时返回包装 ErrMinRestingTime 的错误，订单保留在订单簿中；
+// 挂单不存在时返回 ErrOrderNotResting
+func (me *MatchingEngine) CancelUserOrder(orderID uuid.UUID, tradingPair string) (*types.Order, error) {
+	me.mu.RLock()
+	defer me.mu.RUnlock()
+
+	orderBook, exists := me.orderBooks[tradingPair]
+	if !exists {
+		return nil, ErrOrderNotResting
+	}
+	orderBook.mu.Lock()
+	defer orderBook.unlock()
+
+	order, exists := orderBook.Orders[orderID]
+	if !exists {
+		return nil, ErrOrderNotResting
+	}
+	if wait := me.restingRemaining(order, time.Now()); wait > 0 {
+		retry := (wait + time.Millisecond - 1) / time.Millisecond
+		return nil, fmt.Errorf("%w: retry in %dms", ErrMinRestingTime, retry)
+	}
+	return me.cancelLocked(orderBook, order, ""), nil
+}
+
+// restingRemaining 订单距满足最短停留时间还需等待的时长（调用方持有引擎读锁）
+func (me *MatchingEngine) restingRemaining(order *types.Order, now time.Time) time.Duration {
+	if me.resting == nil || order.CreatedAt.IsZero() {
+		return 0
+	}
+	minimum := me.resting.MinRestingTime(order.TradingPair)
+	if minimum <= 0 {
+		return 0
+	}
+	return order.CreatedAt.Add(minimum).Sub(now)
+}
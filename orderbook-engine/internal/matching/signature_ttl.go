@@ -0,0 +1,88 @@
+package matching
+
+import (
+	"errors"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"orderbook-engine/internal/types"
+)
+
+// ErrSignatureExpired 订单签名超过最长有效期
+var ErrSignatureExpired = errors.New("order signature exceeded TTL")
+
+// SetSignatureTTL 设置订单签名最长有效期，0表示不限制
+// 超过有效期的签名无论 ExpiresAt 如何均不再参与撮合，以限制长期有效签名被重放的风险
+func (me *MatchingEngine) SetSignatureTTL(ttl time.Duration) {
+	me.mu.Lock()
+	defer me.mu.Unlock()
+	me.signatureTTL = ttl
+}
+
+// SignatureTTL 签名有效期，0 表示不限制
+func (me *MatchingEngine) SignatureTTL() time.Duration {
+	me.mu.RLock()
+	defer me.mu.RUnlock()
+	return me.signatureTTL
+}
+
+// signatureExpired 检查订单签名是否超过有效期（以订单被接收的时间计算签名年龄）
+func (me *MatchingEngine) signatureExpired(order *types.Order, now time.Time) bool {
+	if me.signatureTTL <= 0 || order.CreatedAt.IsZero() {
+		return false
+	}
+	return now.Sub(order.CreatedAt) > me.signatureTTL
+}
+
+// rejectExpiredSignature 拒绝签名已超过有效期的新订单
+func (me *MatchingEngine) rejectExpiredSignature(order *types.Order) error {
+	now := time.Now()
+	if !me.signatureExpired(order, now) {
+		return nil
+	}
+	return me.rejectOrder(order, types.StatusReasonSignatureExpired, ErrSignatureExpired)
+}
+
+// ExpireStaleSignatures 撤销签名超过有效期的挂单，返回被撤销订单的快照
+func (me *MatchingEngine) ExpireStaleSignatures() []*types.Order {
+	me.mu.Lock()
+	defer me.mu.Unlock()
+
+	if me.signatureTTL <= 0 {
+		return nil
+	}
+
+	now := time.Now()
+	var expired []*types.Order
+	for tradingPair, orderBook := range me.orderBooks {
+		for _, order := range orderBook.Orders {
+			if !me.signatureExpired(order, now) {
+				continue
+			}
+
+			me.removeOrderFromBook(orderBook, order)
+			order.Status = types.OrderStatusCancelled
+			order.StatusReason = types.StatusReasonSignatureExpired
+			order.UpdatedAt = now
+
+			snapshot := snapshotOrder(order)
+			expired = append(expired, snapshot)
+			me.publish(&MatchEvent{
+				Type:        EventOrderCancelled,
+				TradingPair: tradingPair,
+				Order:       snapshot,
+				Timestamp:   now,
+			})
+		}
+		orderBook.publishSnapshot()
+	}
+
+	if len(expired) > 0 {
+		me.logger.WithFields(logrus.Fields{
+			"count": len(expired),
+			"ttl":   me.signatureTTL.String(),
+		}).Info("⌛ Cancelled orders with expired signatures")
+	}
+	return expired
+}
@@ -0,0 +1,43 @@
+package matching
+
+import (
+	"github.com/shopspring/decimal"
+
+	"orderbook-engine/internal/types"
+)
+
+var hundred = decimal.NewFromInt(100)
+
+// protectionPrice 市价单可成交的最差价格（买单最高价、卖单最低价），ok 为 false 表示不限制
+// 同时设置最大滑点和保护价时取较严格者；滑点以撮合前对手方最优价为基准
+func protectionPrice(order *types.Order, bestPrice decimal.Decimal) (limit decimal.Decimal, ok bool) {
+	if order.Type != types.OrderTypeMarket {
+		return decimal.Zero, false
+	}
+
+	if order.MaxSlippage.IsPositive() {
+		offset := bestPrice.Mul(order.MaxSlippage).Div(hundred)
+		if order.Side == types.OrderSideBuy {
+			limit = bestPrice.Add(offset)
+		} else {
+			limit = bestPrice.Sub(offset)
+		}
+		ok = true
+	}
+
+	if order.ProtectionPrice.IsPositive() {
+		if !ok || withinProtection(order.Side, order.ProtectionPrice, limit) {
+			limit = order.ProtectionPrice
+		}
+		ok = true
+	}
+	return limit, ok
+}
+
+// withinProtection 成交价是否未超出保护价
+func withinProtection(side types.OrderSide, price, limit decimal.Decimal) bool {
+	if side == types.OrderSideBuy {
+		return price.LessThanOrEqual(limit)
+	}
+	return price.GreaterThanOrEqual(limit)
+}
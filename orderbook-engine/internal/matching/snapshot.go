@@ -0,0 +1,79 @@
+package matching
+
+import (
+	"time"
+
+	"orderbook-engine/internal/types"
+)
+
+// bookSnapshot 订单簿的不可变全深度快照
+// 每批变更（一次下单及其成交、撤单、改单、到期清理等）结束、释放订单簿锁之前原子替换，
+// 行情读取只加载快照，不获取引擎锁或订单簿锁，不与撮合争用
+type bookSnapshot struct {
+	bids      []types.OrderBookLevel // 最优价在前
+	asks      []types.OrderBookLevel
+	sequence  uint64
+	updatedAt time.Time // 快照发布时间，即订单簿最近一次变更的时间
+}
+
+// publishSnapshot 订单簿自上次快照后有变更时重建快照并采样排队深度（调用方持有该订单簿的锁或引擎写锁）
+func (orderBook *OrderBook) publishSnapshot() {
+	if current := orderBook.snapshot.Load(); current != nil && current.sequence == orderBook.Sequence {
+		return
+	}
+	orderBook.stats.sampleQueues(orderBook)
+	orderBook.snapshot.Store(&bookSnapshot{
+		bids:      snapshotLevels(orderBook.Bids),
+		asks:      snapshotLevels(orderBook.Asks),
+		sequence:  orderBook.Sequence,
+		updatedAt: time.Now(),
+	})
+}
+
+// unlock 发布快照后释放订单簿锁，变更订单簿的路径用它代替 mu.Unlock
+func (orderBook *OrderBook) unlock() {
+	orderBook.publishSnapshot()
+	orderBook.mu.Unlock()
+}
+
+// snapshotLevels 复制一侧的全部价格层级
+func snapshotLevels(priceLevel *PriceLevel) []types.OrderBookLevel {
+	queues := priceLevel.Levels()
+	levels := make([]types.OrderBookLevel, len(queues))
+	for i, queue := range queues {
+		levels[i] = types.OrderBookLevel{
+			Price:  queue.Price,
+			Amount: queue.Total,
+			Count:  len(queue.Orders),
+		}
+	}
+	return levels
+}
+
+// loadSnapshot 不加锁读取交易对的最新快照，订单簿不存在时返回 nil
+func (me *MatchingEngine) loadSnapshot(tradingPair string) *bookSnapshot {
+	value, exists := me.books.Load(tradingPair)
+	if !exists {
+		return nil
+	}
+	return value.(*OrderBook).snapshot.Load()
+}
+
+// orderBook 由快照生成前 depth 档的订单簿，层级切片为副本
+func (snapshot *bookSnapshot) orderBook(tradingPair string, depth int) *types.OrderBookSnapshot {
+	return &types.OrderBookSnapshot{
+		TradingPair: tradingPair,
+		Bids:        topLevels(snapshot.bids, depth),
+		Asks:        topLevels(snapshot.asks, depth),
+		Sequence:    snapshot.sequence,
+		Timestamp:   time.Now(),
+	}
+}
+
+// topLevels 复制前 depth 档
+func topLevels(levels []types.OrderBookLevel, depth int) []types.OrderBookLevel {
+	if depth < len(levels) {
+		levels = levels[:max(depth, 0)]
+	}
+	return append([]types.OrderBookLevel(nil), levels...)
+}
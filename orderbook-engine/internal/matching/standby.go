@@ -0,0 +1,68 @@
+package matching
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+
+	"orderbook-engine/internal/types"
+)
+
+// ErrNotLeader 当前实例不是主实例，不接受新订单
+var ErrNotLeader = errors.New("matching engine is in standby")
+
+// SetStandby 切换备用模式
+// 备用实例只保存主实例订单簿的只读副本：拒绝新订单且不发布撮合事件，避免重复结算、记录事件日志或推送外部总线
+func (me *MatchingEngine) SetStandby(standby bool) {
+	me.standby.Store(standby)
+
+	// 与 StopAccepting 相同，获取一次写锁等待在途撮合结束
+	me.mu.Lock()
+	me.mu.Unlock()
+}
+
+// Standby 引擎是否处于备用模式
+func (me *MatchingEngine) Standby() bool {
+	return me.standby.Load()
+}
+
+// rejectWhileStandby 备用模式下拒绝新订单（调用方持有订单簿锁）
+func (me *MatchingEngine) rejectWhileStandby(order *types.Order) error {
+	if !me.standby.Load() {
+		return nil
+	}
+	return me.rejectOrder(order, types.StatusReasonNotLeader, ErrNotLeader)
+}
+
+// LoadOrders 以给定挂单替换全部订单簿内容（不撮合、不发布事件），返回载入的挂单数
+// 用于备用实例同步主实例的订单簿以及接管时恢复状态
+func (me *MatchingEngine) LoadOrders(orders []*types.Order) int {
+	me.mu.Lock()
+	defer me.mu.Unlock()
+
+	for _, orderBook := range me.orderBooks {
+		orderBook.mu.Lock()
+		orderBook.Bids = newPriceLevel(true)
+		orderBook.Asks = newPriceLevel(false)
+		orderBook.Orders = make(map[uuid.UUID]*types.Order)
+		orderBook.Sequence++
+		orderBook.unlock()
+	}
+	me.usersMu.Lock()
+	me.userOrders = make(map[string]int)
+	me.usersMu.Unlock()
+	me.expiries.reset()
+
+	loaded := 0
+	for _, order := range orders {
+		if !order.GetRemainingAmount().IsPositive() {
+			continue
+		}
+		orderBook := me.getOrCreateOrderBook(order.TradingPair)
+		orderBook.mu.Lock()
+		me.addOrderToBook(orderBook, snapshotOrder(order))
+		orderBook.unlock()
+		loaded++
+	}
+	return loaded
+}
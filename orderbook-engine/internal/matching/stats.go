@@ -0,0 +1,108 @@
+package matching
+
+import (
+	"math"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	// matchRateWindow 成交速率指数加权的时间常数，约反映最近一分钟的成交速率
+	matchRateWindow = time.Minute
+	// queueDepthAlpha 最优价排队订单数指数加权系数，每批订单簿变更采样一次
+	queueDepthAlpha = 0.1
+)
+
+// PairStats 交易对撮合引擎内部统计（容量规划与排查用）
+type PairStats struct {
+	TradingPair      string          `json:"trading_pair"`
+	RestingOrders    int             `json:"resting_orders"`
+	BidLevels        int             `json:"bid_levels"`
+	AskLevels        int             `json:"ask_levels"`
+	MatchesPerSecond decimal.Decimal `json:"matches_per_second"` // 成交笔数速率的指数加权均值（时间常数一分钟）
+	BestBidQueue     int             `json:"best_bid_queue"`     // 当前最优买价排队订单数
+	BestAskQueue     int             `json:"best_ask_queue"`
+	AvgBestBidQueue  decimal.Decimal `json:"avg_best_bid_queue"` // 最优买价排队订单数的指数加权均值，按订单簿变更采样
+	AvgBestAskQueue  decimal.Decimal `json:"avg_best_ask_queue"`
+	LastSequence     uint64          `json:"last_sequence"`
+	LastTradeAt      *time.Time      `json:"last_trade_at,omitempty"`
+	Timestamp        time.Time       `json:"timestamp"`
+}
+
+// bookStats 订单簿统计状态，由订单簿锁保护
+type bookStats struct {
+	matchRate float64 // 截至 updatedAt 的成交速率（笔/秒）
+	updatedAt time.Time
+	bidQueue  float64 // 最优买价排队订单数的指数加权均值
+	askQueue  float64
+	sampled   bool
+}
+
+// recordMatches 记录 n 笔成交（调用方持有订单簿锁）
+func (s *bookStats) recordMatches(n int, now time.Time) {
+	s.matchRate = s.currentRate(now) + float64(n)/matchRateWindow.Seconds()
+	s.updatedAt = now
+}
+
+// currentRate 成交速率按时间衰减到 now
+func (s *bookStats) currentRate(now time.Time) float64 {
+	if s.updatedAt.IsZero() || !now.After(s.updatedAt) {
+		return s.matchRate
+	}
+	return s.matchRate * math.Exp(-now.Sub(s.updatedAt).Seconds()/matchRateWindow.Seconds())
+}
+
+// sampleQueues 采样最优价排队订单数，随快照发布每批变更采样一次（调用方持有订单簿锁）
+func (s *bookStats) sampleQueues(orderBook *OrderBook) {
+	bid, ask := float64(bestQueueLen(orderBook.Bids)), float64(bestQueueLen(orderBook.Asks))
+	if !s.sampled {
+		s.bidQueue, s.askQueue = bid, ask
+	} else {
+		s.bidQueue += queueDepthAlpha * (bid - s.bidQueue)
+		s.askQueue += queueDepthAlpha * (ask - s.askQueue)
+	}
+	s.sampled = true
+}
+
+// bestQueueLen 最优价层级的排队订单数，该侧为空时为 0
+func bestQueueLen(priceLevel *PriceLevel) int {
+	if best := priceLevel.Best(); best != nil {
+		return len(best.Orders)
+	}
+	return 0
+}
+
+// GetPairStats 获取交易对撮合引擎内部统计，交易对不存在时返回 false
+func (me *MatchingEngine) GetPairStats(tradingPair string) (*PairStats, bool) {
+	me.mu.RLock()
+	defer me.mu.RUnlock()
+
+	orderBook, exists := me.orderBooks[tradingPair]
+	if !exists {
+		return nil, false
+	}
+	orderBook.mu.Lock()
+	defer orderBook.mu.Unlock()
+
+	now := time.Now()
+	stats := &orderBook.stats
+	result := &PairStats{
+		TradingPair:      tradingPair,
+		RestingOrders:    len(orderBook.Orders),
+		BidLevels:        orderBook.Bids.Len(),
+		AskLevels:        orderBook.Asks.Len(),
+		MatchesPerSecond: decimal.NewFromFloat(stats.currentRate(now)).Round(4),
+		BestBidQueue:     bestQueueLen(orderBook.Bids),
+		BestAskQueue:     bestQueueLen(orderBook.Asks),
+		AvgBestBidQueue:  decimal.NewFromFloat(stats.bidQueue).Round(2),
+		AvgBestAskQueue:  decimal.NewFromFloat(stats.askQueue).Round(2),
+		LastSequence:     orderBook.Sequence,
+		Timestamp:        now,
+	}
+	if !orderBook.LastTradeAt.IsZero() {
+		lastTradeAt := orderBook.LastTradeAt
+		result.LastTradeAt = &lastTradeAt
+	}
+	return result, true
+}
@@ -0,0 +1,138 @@
+// Package merkle 成交默克尔批次
+// 一批成交只在链上提交默克尔根，逐笔成交的包含证明由链下保存并对外提供，
+// 叶子和节点的计算方式与结算合约 fillLeaf / verifyFillInclusion（OpenZeppelin MerkleProof）一致
+package merkle
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/google/uuid"
+)
+
+// ErrEmptyTree 没有叶子无法构建默克尔树
+var ErrEmptyTree = errors.New("merkle tree requires at least one leaf")
+
+// Fill 参与默克尔批次的成交（价格、数量为合约精度的整数）
+type Fill struct {
+	FillID         uuid.UUID
+	TakerOrderHash [32]byte
+	MakerOrderHash [32]byte
+	Price          *big.Int
+	Amount         *big.Int
+	TakerSide      uint8
+}
+
+// leafArguments 叶子的ABI编码：abi.encode(bytes16, bytes32, bytes32, uint128, uint128, uint8)
+var leafArguments = func() abi.Arguments {
+	mustType := func(name string) abi.Type {
+		t, err := abi.NewType(name, "", nil)
+		if err != nil {
+			panic(err)
+		}
+		return t
+	}
+	return abi.Arguments{
+		{Type: mustType("bytes16")},
+		{Type: mustType("bytes32")},
+		{Type: mustType("bytes32")},
+		{Type: mustType("uint128")},
+		{Type: mustType("uint128")},
+		{Type: mustType("uint8")},
+	}
+}()
+
+// Leaf 成交的叶子哈希：keccak256(keccak256(abi.encode(...)))，双重哈希避免叶子被当作内部节点伪造
+func Leaf(fill Fill) ([32]byte, error) {
+	encoded, err := leafArguments.Pack([16]byte(fill.FillID), fill.TakerOrderHash, fill.MakerOrderHash,
+		fill.Price, fill.Amount, fill.TakerSide)
+	if err != nil {
+		return [32]byte{}, fmt.Errorf("failed to encode fill %s: %w", fill.FillID, err)
+	}
+	var leaf [32]byte
+	copy(leaf[:], crypto.Keccak256(crypto.Keccak256(encoded)))
+	return leaf, nil
+}
+
+// Tree 默克尔树，levels[0] 为叶子层，最后一层为根
+// 节点为排序后的两个子节点拼接的哈希，奇数个节点时最后一个直接进入上一层
+type Tree struct {
+	levels [][][32]byte
+}
+
+// NewTree 按叶子顺序构建默克尔树
+func NewTree(leaves [][32]byte) (*Tree, error) {
+	if len(leaves) == 0 {
+		return nil, ErrEmptyTree
+	}
+
+	level := append([][32]byte(nil), leaves...)
+	levels := [][][32]byte{level}
+	for len(level) > 1 {
+		next := make([][32]byte, 0, (len(level)+1)/2)
+		for i := 0; i < len(level); i += 2 {
+			if i+1 == len(level) {
+				next = append(next, level[i])
+				continue
+			}
+			next = append(next, hashPair(level[i], level[i+1]))
+		}
+		levels = append(levels, next)
+		level = next
+	}
+	return &Tree{levels: levels}, nil
+}
+
+// Root 默克尔根
+func (t *Tree) Root() [32]byte {
+	return t.levels[len(t.levels)-1][0]
+}
+
+// Len 叶子数量
+func (t *Tree) Len() int {
+	return len(t.levels[0])
+}
+
+// Leaf 第 index 个叶子
+func (t *Tree) Leaf(index int) [32]byte {
+	return t.levels[0][index]
+}
+
+// Proof 第 index 个叶子的包含证明（自下而上的兄弟节点）
+func (t *Tree) Proof(index int) ([][32]byte, error) {
+	if index < 0 || index >= t.Len() {
+		return nil, fmt.Errorf("leaf index %d out of range [0, %d)", index, t.Len())
+	}
+
+	proof := make([][32]byte, 0, len(t.levels)-1)
+	for _, level := range t.levels[:len(t.levels)-1] {
+		if sibling := index ^ 1; sibling < len(level) {
+			proof = append(proof, level[sibling])
+		}
+		index /= 2
+	}
+	return proof, nil
+}
+
+// Verify 校验叶子是否包含在默克尔根中
+func Verify(root, leaf [32]byte, proof [][32]byte) bool {
+	computed := leaf
+	for _, sibling := range proof {
+		computed = hashPair(computed, sibling)
+	}
+	return computed == root
+}
+
+// hashPair 排序后拼接两个节点再哈希，与 OpenZeppelin MerkleProof 一致
+func hashPair(a, b [32]byte) [32]byte {
+	if bytes.Compare(a[:], b[:]) > 0 {
+		a, b = b, a
+	}
+	var node [32]byte
+	copy(node[:], crypto.Keccak256(a[:], b[:]))
+	return node
+}
@@ -0,0 +1,114 @@
+package merkle
+
+import (
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newFill(amount int64) Fill {
+	return Fill{
+		FillID:         uuid.New(),
+		TakerOrderHash: [32]byte{1},
+		MakerOrderHash: [32]byte{2},
+		Price:          big.NewInt(2000),
+		Amount:         big.NewInt(amount),
+		TakerSide:      1,
+	}
+}
+
+func TestTreeProofs(t *testing.T) {
+	for size := 1; size <= 7; size++ {
+		leaves := make([][32]byte, size)
+		for i := range leaves {
+			leaf, err := Leaf(newFill(int64(i + 1)))
+			require.NoError(t, err)
+			leaves[i] = leaf
+		}
+
+		tree, err := NewTree(leaves)
+		require.NoError(t, err)
+		for i := range leaves {
+			proof, err := tree.Proof(i)
+			require.NoError(t, err)
+			assert.True(t, Verify(tree.Root(), leaves[i], proof), "size %d leaf %d", size, i)
+		}
+
+		// 篡改的叶子无法通过校验
+		proof, err := tree.Proof(0)
+		require.NoError(t, err)
+		forged, err := Leaf(newFill(1000))
+		require.NoError(t, err)
+		assert.False(t, Verify(tree.Root(), forged, proof))
+	}
+
+	_, err := NewTree(nil)
+	assert.ErrorIs(t, err, ErrEmptyTree)
+}
+
+func TestTreeMatchesSortedPairHashing(t *testing.T) {
+	a, b := [32]byte{0xff}, [32]byte{0x01}
+	tree, err := NewTree([][32]byte{a, b})
+	require.NoError(t, err)
+
+	// 节点为排序后两个子节点拼接的哈希，与 OpenZeppelin MerkleProof 一致
+	var expected [32]byte
+	copy(expected[:], crypto.Keccak256(b[:], a[:]))
+	assert.Equal(t, expected, tree.Root())
+
+	// 单个叶子的树，根即为叶子
+	single, err := NewTree([][32]byte{a})
+	require.NoError(t, err)
+	assert.Equal(t, a, single.Root())
+}
+
+func TestFileStorePersistsProofs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "proofs.jsonl")
+	store, err := NewFileStore(path)
+	require.NoError(t, err)
+
+	fills := []Fill{newFill(1), newFill(2), newFill(3)}
+	batch, tree, err := NewBatch(31337, fills)
+	require.NoError(t, err)
+	batch.TxHash = "0xabc"
+	require.NoError(t, store.SaveBatch(batch))
+
+	// 模拟写入中断留下的半行
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o644)
+	require.NoError(t, err)
+	_, err = file.WriteString(`{"chain_id":1,"root":`)
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	reopened, err := NewFileStore(path)
+	require.NoError(t, err)
+	proof, err := reopened.FillProof(fills[1].FillID)
+	require.NoError(t, err)
+	assert.Equal(t, batch.Root, proof.Root)
+	assert.Equal(t, "0xabc", proof.TxHash)
+	assert.Equal(t, 3, proof.BatchFills)
+	assert.Equal(t, "2", proof.Amount)
+
+	siblings, err := tree.Proof(1)
+	require.NoError(t, err)
+	require.Len(t, proof.Siblings, len(siblings))
+	assert.True(t, Verify(tree.Root(), tree.Leaf(1), siblings))
+
+	_, err = reopened.FillProof(uuid.New())
+	assert.ErrorIs(t, err, ErrProofNotFound)
+
+	// 半行已被截掉，之后追加的批次可以正常载入
+	next, _, err := NewBatch(31337, []Fill{newFill(4)})
+	require.NoError(t, err)
+	require.NoError(t, reopened.SaveBatch(next))
+	reopened, err = NewFileStore(path)
+	require.NoError(t, err)
+	_, err = reopened.FillProof(next.Proofs[0].FillID)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,224 @@
+package merkle
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/google/uuid"
+)
+
+// ErrProofNotFound 成交不在任何已提交的默克尔批次中
+var ErrProofNotFound = errors.New("merkle proof not found")
+
+// Proof 单笔成交的包含证明，连同叶子的原始字段一起保存，用户和监督方可自行重算叶子
+type Proof struct {
+	FillID         uuid.UUID `json:"fill_id"`
+	Index          int       `json:"index"`
+	Leaf           string    `json:"leaf"`
+	Siblings       []string  `json:"proof"`
+	TakerOrderHash string    `json:"taker_order_hash"`
+	MakerOrderHash string    `json:"maker_order_hash"`
+	Price          string    `json:"price"`
+	Amount         string    `json:"amount"`
+	TakerSide      uint8     `json:"taker_side"`
+}
+
+// Batch 已上链的默克尔批次
+type Batch struct {
+	ChainID     uint64    `json:"chain_id"`
+	Root        string    `json:"root"`
+	TxHash      string    `json:"tx_hash"`
+	Contract    string    `json:"contract"`
+	Fills       int       `json:"fills"`
+	SubmittedAt time.Time `json:"submitted_at"`
+	Proofs      []Proof   `json:"proofs"`
+}
+
+// NewBatch 由成交构建默克尔树，返回批次（含各成交证明）和树
+func NewBatch(chainID uint64, fills []Fill) (*Batch, *Tree, error) {
+	leaves := make([][32]byte, len(fills))
+	for i, fill := range fills {
+		leaf, err := Leaf(fill)
+		if err != nil {
+			return nil, nil, err
+		}
+		leaves[i] = leaf
+	}
+	tree, err := NewTree(leaves)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	root := tree.Root()
+	batch := &Batch{
+		ChainID: chainID,
+		Root:    hexutil.Encode(root[:]),
+		Fills:   len(fills),
+		Proofs:  make([]Proof, len(fills)),
+	}
+	for i, fill := range fills {
+		siblings, err := tree.Proof(i)
+		if err != nil {
+			return nil, nil, err
+		}
+		encoded := make([]string, len(siblings))
+		for j, sibling := range siblings {
+			encoded[j] = hexutil.Encode(sibling[:])
+		}
+		batch.Proofs[i] = Proof{
+			FillID:         fill.FillID,
+			Index:          i,
+			Leaf:           hexutil.Encode(leaves[i][:]),
+			Siblings:       encoded,
+			TakerOrderHash: hexutil.Encode(fill.TakerOrderHash[:]),
+			MakerOrderHash: hexutil.Encode(fill.MakerOrderHash[:]),
+			Price:          fill.Price.String(),
+			Amount:         fill.Amount.String(),
+			TakerSide:      fill.TakerSide,
+		}
+	}
+	return batch, tree, nil
+}
+
+// FillProof 成交证明及其所属批次（不含批次内其他成交的证明）
+type FillProof struct {
+	Proof
+	ChainID     uint64    `json:"chain_id"`
+	Root        string    `json:"root"`
+	TxHash      string    `json:"tx_hash"`
+	Contract    string    `json:"contract"`
+	BatchFills  int       `json:"batch_fills"`
+	SubmittedAt time.Time `json:"submitted_at"`
+}
+
+// Store 默克尔批次与证明存储
+type Store interface {
+	SaveBatch(batch *Batch) error
+	// FillProof 查询成交的包含证明
+	FillProof(fillID uuid.UUID) (*FillProof, error)
+}
+
+// FileStore 基于JSON Lines文件的证明存储，每个批次追加一行，启动时全部载入内存索引
+type FileStore struct {
+	mu      sync.RWMutex
+	path    string
+	batches map[string]*Batch    // root -> 批次
+	fills   map[uuid.UUID]string // 成交ID -> root
+}
+
+// NewFileStore 创建文件证明存储，文件不存在时从空存储开始
+func NewFileStore(path string) (*FileStore, error) {
+	if path == "" {
+		return nil, fmt.Errorf("merkle proof store path is required")
+	}
+	store := &FileStore{
+		path:    path,
+		batches: make(map[string]*Batch),
+		fills:   make(map[uuid.UUID]string),
+	}
+
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open merkle proof store: %w", err)
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	var valid int64 // 最后一个完整行之后的偏移
+	for line := 1; ; line++ {
+		data, readErr := reader.ReadBytes('\n')
+		if readErr != nil {
+			// 最后一行没有换行符，是写入中断的半行，截掉以免之后追加的批次与其拼成损坏行
+			if len(data) > 0 {
+				if err := os.Truncate(path, valid); err != nil {
+					return nil, fmt.Errorf("failed to truncate partial merkle proof record: %w", err)
+				}
+			}
+			break
+		}
+		valid += int64(len(data))
+		if len(strings.TrimSpace(string(data))) == 0 {
+			continue
+		}
+		var batch Batch
+		if err := json.Unmarshal(data, &batch); err != nil {
+			return nil, fmt.Errorf("failed to parse merkle proof store %s line %d: %w", path, line, err)
+		}
+		store.index(&batch)
+	}
+	return store, nil
+}
+
+// SaveBatch 追加保存批次并同步落盘
+func (s *FileStore) SaveBatch(batch *Batch) error {
+	data, err := json.Marshal(batch)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create merkle proof store directory: %w", err)
+	}
+	file, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open merkle proof store: %w", err)
+	}
+	defer file.Close()
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write merkle proof store: %w", err)
+	}
+	if err := file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync merkle proof store: %w", err)
+	}
+
+	s.index(batch)
+	return nil
+}
+
+// FillProof 查询成交的包含证明
+func (s *FileStore) FillProof(fillID uuid.UUID) (*FillProof, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	root, exists := s.fills[fillID]
+	if !exists {
+		return nil, ErrProofNotFound
+	}
+	batch := s.batches[root]
+	for _, proof := range batch.Proofs {
+		if proof.FillID == fillID {
+			return &FillProof{
+				Proof:       proof,
+				ChainID:     batch.ChainID,
+				Root:        batch.Root,
+				TxHash:      batch.TxHash,
+				Contract:    batch.Contract,
+				BatchFills:  batch.Fills,
+				SubmittedAt: batch.SubmittedAt,
+			}, nil
+		}
+	}
+	return nil, ErrProofNotFound
+}
+
+// index 建立成交到批次的索引，调用方需持有写锁
+func (s *FileStore) index(batch *Batch) {
+	s.batches[batch.Root] = batch
+	for _, proof := range batch.Proofs {
+		s.fills[proof.FillID] = batch.Root
+	}
+}
@@ -0,0 +1,291 @@
+// Package nonce 用户订单nonce管理
+// 记录已使用的nonce并维护最小有效nonce，与结算合约 userNonces 语义一致：nonce 小于最小值的订单无效
+package nonce
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"orderbook-engine/internal/types"
+)
+
+var (
+	// ErrNonceUsed nonce已被使用
+	ErrNonceUsed = errors.New("nonce already used")
+	// ErrNonceInvalidated nonce低于最小有效nonce（已被链上或链下作废）
+	ErrNonceInvalidated = errors.New("nonce below minimum valid nonce")
+	// ErrCancelNonceUsed 撤单签名的nonce已被使用
+	ErrCancelNonceUsed = errors.New("cancel nonce already used")
+)
+
+// historyLimit 首次加载用户时从存储中读取的历史订单数
+const historyLimit = 1000
+
+// ChainSource 读取链上最小有效nonce（由区块链客户端实现）
+type ChainSource interface {
+	GetUserNonce(ctx context.Context, userAddress string) (uint64, error)
+}
+
+// OrderHistory 读取用户历史订单（由存储层实现）
+type OrderHistory interface {
+	GetUserOrders(userAddress, tradingPair, status string, limit, offset int) ([]*types.Order, error)
+}
+
+// Status 用户nonce状态
+type Status struct {
+	UserAddress string     `json:"user_address"`
+	NextNonce   uint64     `json:"next_nonce"`  // 建议下一笔订单使用的nonce
+	MinNonce    uint64     `json:"min_nonce"`   // 最小有效nonce
+	ChainNonce  uint64     `json:"chain_nonce"` // 最近一次同步的链上 userNonces
+	UsedCount   int        `json:"used_count"`
+	ChainSyncAt *time.Time `json:"chain_synced_at,omitempty"`
+}
+
+// userState 单个用户的nonce记录
+type userState struct {
+	loaded     chan struct{} // 历史订单恢复完成后关闭
+	used       map[uint64]struct{}
+	highest    uint64
+	hasUsed    bool
+	minNonce   uint64
+	chainNonce uint64
+	syncedAt   time.Time
+}
+
+// Tracker nonce跟踪器
+type Tracker struct {
+	mu           sync.Mutex
+	users        map[string]*userState
+	cancels      map[string]map[uint64]time.Time // 用户 -> 已使用的撤单nonce -> 撤单签名过期时间
+	chain        ChainSource
+	history      OrderHistory
+	syncInterval time.Duration
+	logger       *logrus.Logger
+}
+
+// NewTracker 创建nonce跟踪器
+// chain 为空时不同步链上nonce；history 为空时不从存储恢复已用nonce
+func NewTracker(chain ChainSource, history OrderHistory, syncInterval time.Duration, logger *logrus.Logger) *Tracker {
+	return &Tracker{
+		users:        make(map[string]*userState),
+		cancels:      make(map[string]map[uint64]time.Time),
+		chain:        chain,
+		history:      history,
+		syncInterval: syncInterval,
+		logger:       logger,
+	}
+}
+
+// Use 校验并占用nonce，校验失败时返回 ErrNonceUsed 或 ErrNonceInvalidated
+func (t *Tracker) Use(userAddress string, nonce uint64) error {
+	user := strings.ToLower(userAddress)
+	t.ensureLoaded(user)
+	t.syncIfStale(user)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	state := t.getUserLocked(user)
+	if nonce < state.minNonce {
+		return ErrNonceInvalidated
+	}
+	if _, exists := state.used[nonce]; exists {
+		return ErrNonceUsed
+	}
+	state.markUsed(nonce)
+	return nil
+}
+
+// Release 释放未被接受的订单占用的nonce
+func (t *Tracker) Release(userAddress string, nonce uint64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if state, exists := t.users[strings.ToLower(userAddress)]; exists {
+		delete(state.used, nonce)
+	}
+}
+
+// UseCancel 校验并占用撤单签名的nonce，撤单nonce与订单nonce相互独立
+// 记录保留到撤单签名过期，过期的撤单签名本身已被拒绝，无需继续记录
+func (t *Tracker) UseCancel(userAddress string, nonce uint64, expiresAt time.Time) error {
+	user := strings.ToLower(userAddress)
+	now := time.Now()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	used := t.cancels[user]
+	if used == nil {
+		used = make(map[uint64]time.Time)
+		t.cancels[user] = used
+	}
+	for usedNonce, expiry := range used {
+		if now.After(expiry) {
+			delete(used, usedNonce)
+		}
+	}
+	if _, exists := used[nonce]; exists {
+		return ErrCancelNonceUsed
+	}
+	used[nonce] = expiresAt
+	return nil
+}
+
+// ReleaseCancel 释放未执行的撤单占用的nonce，同一撤单签名可以重试
+func (t *Tracker) ReleaseCancel(userAddress string, nonce uint64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if used, exists := t.cancels[strings.ToLower(userAddress)]; exists {
+		delete(used, nonce)
+	}
+}
+
+// InvalidateBelow 作废小于 minNonce 的全部nonce，返回是否提高了最小有效nonce
+func (t *Tracker) InvalidateBelow(userAddress string, minNonce uint64) bool {
+	user := strings.ToLower(userAddress)
+	t.ensureLoaded(user)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	state := t.getUserLocked(user)
+	if minNonce <= state.minNonce {
+		return false
+	}
+	state.minNonce = minNonce
+	state.prune()
+	return true
+}
+
+// GetStatus 获取用户nonce状态（必要时先同步链上nonce）
+func (t *Tracker) GetStatus(userAddress string) *Status {
+	user := strings.ToLower(userAddress)
+	t.ensureLoaded(user)
+	t.syncIfStale(user)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	state := t.getUserLocked(user)
+	next := state.minNonce
+	if state.hasUsed && state.highest+1 > next {
+		next = state.highest + 1
+	}
+
+	status := &Status{
+		UserAddress: user,
+		NextNonce:   next,
+		MinNonce:    state.minNonce,
+		ChainNonce:  state.chainNonce,
+		UsedCount:   len(state.used),
+	}
+	if !state.syncedAt.IsZero() {
+		syncedAt := state.syncedAt
+		status.ChainSyncAt = &syncedAt
+	}
+	return status
+}
+
+// syncIfStale 链上nonce超过同步间隔未刷新时重新读取（不持有锁）
+func (t *Tracker) syncIfStale(user string) {
+	if t.chain == nil {
+		return
+	}
+
+	t.mu.Lock()
+	state := t.getUserLocked(user)
+	stale := time.Since(state.syncedAt) >= t.syncInterval
+	t.mu.Unlock()
+	if !stale {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	chainNonce, err := t.chain.GetUserNonce(ctx, user)
+	if err != nil {
+		t.logger.WithError(err).WithField("user_address", user).Warn("Failed to sync on-chain nonce")
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	state.chainNonce = chainNonce
+	state.syncedAt = time.Now()
+	if chainNonce > state.minNonce {
+		state.minNonce = chainNonce
+		state.prune()
+	}
+}
+
+// ensureLoaded 首次访问用户时从历史订单恢复已用nonce（不持有锁）
+// 存储读取在锁外进行，不阻塞其他用户的nonce校验；同一用户的并发调用等待恢复完成，避免恢复前放行已用nonce
+func (t *Tracker) ensureLoaded(user string) {
+	t.mu.Lock()
+	state, exists := t.users[user]
+	if !exists {
+		state = &userState{used: make(map[uint64]struct{}), loaded: make(chan struct{})}
+		t.users[user] = state
+	}
+	t.mu.Unlock()
+
+	if exists {
+		<-state.loaded
+		return
+	}
+	defer close(state.loaded)
+
+	if t.history == nil {
+		return
+	}
+	orders, err := t.history.GetUserOrders(user, "", "", historyLimit, 0)
+	if err != nil {
+		t.logger.WithError(err).WithField("user_address", user).Warn("Failed to load order nonces")
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, order := range orders {
+		// 被拒绝的订单未占用nonce，同一签名订单可以重新提交
+		if order.Status == types.OrderStatusRejected || order.Nonce < state.minNonce {
+			continue
+		}
+		state.markUsed(order.Nonce)
+	}
+}
+
+// getUserLocked 获取用户记录（调用方持有锁，且已调用 ensureLoaded）
+func (t *Tracker) getUserLocked(user string) *userState {
+	state, exists := t.users[user]
+	if !exists {
+		loaded := make(chan struct{})
+		close(loaded)
+		state = &userState{used: make(map[uint64]struct{}), loaded: loaded}
+		t.users[user] = state
+	}
+	return state
+}
+
+func (s *userState) markUsed(nonce uint64) {
+	s.used[nonce] = struct{}{}
+	if !s.hasUsed || nonce > s.highest {
+		s.highest = nonce
+		s.hasUsed = true
+	}
+}
+
+// prune 移除低于最小有效nonce的记录，这些nonce已无法再使用
+func (s *userState) prune() {
+	for nonce := range s.used {
+		if nonce < s.minNonce {
+			delete(s.used, nonce)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package nonce
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"orderbook-engine/internal/types"
+)
+
+const (
+	alice = "0xaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
+	bob   = "0xbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb"
+)
+
+// staticHistory 历史订单，block 不为空时读取 alice 的历史会阻塞到 block 关闭
+type staticHistory struct {
+	orders map[string][]*types.Order
+	block  chan struct{}
+}
+
+func (h *staticHistory) GetUserOrders(userAddress, tradingPair, status string, limit, offset int) ([]*types.Order, error) {
+	if h.block != nil && userAddress == alice {
+		<-h.block
+	}
+	return h.orders[userAddress], nil
+}
+
+func TestUseRejectsReplay(t *testing.T) {
+	tracker := NewTracker(nil, nil, time.Minute, logrus.New())
+
+	require.NoError(t, tracker.Use(alice, 5))
+	assert.ErrorIs(t, tracker.Use(alice, 5), ErrNonceUsed)
+	// 地址大小写不影响
+	assert.ErrorIs(t, tracker.Use("0xAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA", 5), ErrNonceUsed)
+	// 不同用户互不影响
+	assert.NoError(t, tracker.Use(bob, 5))
+
+	// 未被接受的订单释放nonce后可以重新提交
+	tracker.Release(alice, 5)
+	assert.NoError(t, tracker.Use(alice, 5))
+
+	// 作废低于最小有效nonce的全部nonce
+	assert.True(t, tracker.InvalidateBelow(alice, 10))
+	assert.ErrorIs(t, tracker.Use(alice, 7), ErrNonceInvalidated)
+	assert.NoError(t, tracker.Use(alice, 10))
+
+	status := tracker.GetStatus(alice)
+	assert.Equal(t, uint64(11), status.NextNonce)
+	assert.Equal(t, uint64(10), status.MinNonce)
+}
+
+func TestHistoryRestore(t *testing.T) {
+	history := &staticHistory{orders: map[string][]*types.Order{
+		alice: {
+			{Nonce: 1, Status: types.OrderStatusFilled},
+			{Nonce: 2, Status: types.OrderStatusRejected},
+		},
+	}}
+	tracker := NewTracker(nil, history, time.Minute, logrus.New())
+
+	assert.ErrorIs(t, tracker.Use(alice, 1), ErrNonceUsed)
+	// 被拒绝的订单未占用nonce
+	assert.NoError(t, tracker.Use(alice, 2))
+}
+
+func TestHistoryLoadDoesNotBlockOtherUsers(t *testing.T) {
+	history := &staticHistory{
+		orders: map[string][]*types.Order{alice: {{Nonce: 1, Status: types.OrderStatusOpen}}},
+		block:  make(chan struct{}),
+	}
+	tracker := NewTracker(nil, history, time.Minute, logrus.New())
+
+	aliceDone := make(chan error, 1)
+	go func() { aliceDone <- tracker.Use(alice, 1) }()
+
+	// alice 的历史读取阻塞期间，bob 的校验不受影响
+	bobDone := make(chan error, 1)
+	go func() { bobDone <- tracker.Use(bob, 1) }()
+	select {
+	case err := <-bobDone:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("nonce check blocked by another user's history load")
+	}
+
+	// alice 等待历史恢复完成后再校验，不会放行已用nonce
+	select {
+	case <-aliceDone:
+		t.Fatal("nonce check completed before history was loaded")
+	case <-time.After(20 * time.Millisecond):
+	}
+	close(history.block)
+	assert.ErrorIs(t, <-aliceDone, ErrNonceUsed)
+}
+
+func TestUseCancelRejectsReplay(t *testing.T) {
+	tracker := NewTracker(nil, nil, time.Minute, logrus.New())
+	expiresAt := time.Now().Add(time.Minute)
+
+	require.NoError(t, tracker.UseCancel(alice, 1, expiresAt))
+	assert.ErrorIs(t, tracker.UseCancel(alice, 1, expiresAt), ErrCancelNonceUsed)
+	// 撤单nonce与订单nonce相互独立
+	assert.NoError(t, tracker.Use(alice, 1))
+
+	// 撤单未执行时释放后可以重试
+	tracker.ReleaseCancel(alice, 1)
+	assert.NoError(t, tracker.UseCancel(alice, 1, expiresAt))
+
+	// 撤单签名过期后记录被清理
+	require.NoError(t, tracker.UseCancel(alice, 2, time.Now().Add(-time.Second)))
+	assert.NoError(t, tracker.UseCancel(alice, 2, expiresAt))
+}
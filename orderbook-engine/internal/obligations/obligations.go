@@ -0,0 +1,357 @@
+// Package obligations 做市商报价义务监控
+// 对指定做市商的每个交易对周期采样：双边各有一笔不小于最小数量、距中间价不超过约定基点的挂单记为在场；
+// 每个考核周期结束时生成合规报告，在场比例低于约定值时告警，并可通过结算合约的用户费率提高其手续费，恢复合规后还原
+package obligations
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+
+	"orderbook-engine/internal/matching"
+	"orderbook-engine/internal/types"
+)
+
+// bpsDenominator 基点分母
+var bpsDenominator = decimal.NewFromInt(10000)
+
+// Obligation 做市商在单个交易对上的报价义务
+type Obligation struct {
+	MarketMaker  string          `json:"market_maker"`
+	TradingPair  string          `json:"trading_pair"`
+	MaxSpreadBps decimal.Decimal `json:"max_spread_bps"` // 每侧报价距中间价的最大基点
+	MinPresence  decimal.Decimal `json:"min_presence"`   // 考核周期内要求的最低在场比例（0~1）
+	MinSize      decimal.Decimal `json:"min_size"`       // 每侧报价的最小剩余数量
+}
+
+// Validate 校验报价义务
+func (o *Obligation) Validate() error {
+	if o.MarketMaker == "" || o.TradingPair == "" {
+		return fmt.Errorf("market_maker and trading_pair are required")
+	}
+	if !o.MaxSpreadBps.IsPositive() {
+		return fmt.Errorf("max_spread_bps must be positive")
+	}
+	if o.MinPresence.IsNegative() || o.MinPresence.GreaterThan(decimal.NewFromInt(1)) {
+		return fmt.Errorf("min_presence must be between 0 and 1")
+	}
+	if o.MinSize.IsNegative() {
+		return fmt.Errorf("min_size must not be negative")
+	}
+	return nil
+}
+
+// Config 监控配置
+type Config struct {
+	SampleInterval time.Duration // 采样周期
+	ReportPeriod   time.Duration // 考核周期
+	PenaltyFeeBps  uint64        // 不合规时设置的 maker/taker 费率（基点），0 表示不处罚
+	RetainReports  int           // 保留的合规报告数，0 表示不清理
+}
+
+// Report 做市商在一个考核周期、一个交易对上的合规报告
+type Report struct {
+	MarketMaker string          `json:"market_maker"`
+	TradingPair string          `json:"trading_pair"`
+	PeriodStart time.Time       `json:"period_start"`
+	PeriodEnd   time.Time       `json:"period_end"`
+	Samples     int64           `json:"samples"`
+	Present     int64           `json:"present"`
+	Presence    decimal.Decimal `json:"presence"`
+	Required    decimal.Decimal `json:"required"`
+	Compliant   bool            `json:"compliant"`
+	Penalized   bool            `json:"penalized"` // 本周期结束时做市商处于费率处罚中
+}
+
+// Notifier 周期报告回调，每个考核周期结束时对每份报告调用一次
+type Notifier func(report *Report)
+
+// FeeSetter 设置用户手续费率（基点），0 表示恢复合约默认费率
+type FeeSetter interface {
+	SetUserFeeRates(userAddress string, makerBps, takerBps uint64) error
+}
+
+// tracker 单个报价义务在当前考核周期内的采样计数
+type tracker struct {
+	obligation *Obligation
+	samples    int64
+	present    int64
+}
+
+// Monitor 做市商报价义务监控
+type Monitor struct {
+	mu          sync.Mutex
+	engine      *matching.MatchingEngine
+	config      Config
+	trackers    map[string]*tracker // lower(market_maker)|trading_pair -> 采样计数
+	periodStart time.Time
+	reports     []*Report
+	penalized   map[string]bool // lower(market_maker) -> 处罚中
+	notifier    Notifier
+	fees        FeeSetter
+	logger      *logrus.Logger
+}
+
+// NewMonitor 创建报价义务监控
+func NewMonitor(engine *matching.MatchingEngine, config Config, logger *logrus.Logger) (*Monitor, error) {
+	if config.SampleInterval <= 0 || config.ReportPeriod <= 0 {
+		return nil, fmt.Errorf("sample_interval and report_period must be positive")
+	}
+	if config.PenaltyFeeBps > 1000 {
+		return nil, fmt.Errorf("penalty_fee_bps must not exceed 1000")
+	}
+	return &Monitor{
+		engine:      engine,
+		config:      config,
+		trackers:    make(map[string]*tracker),
+		periodStart: time.Now(),
+		penalized:   make(map[string]bool),
+		logger:      logger,
+	}, nil
+}
+
+// SetNotifier 设置周期报告回调
+func (m *Monitor) SetNotifier(notifier Notifier) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.notifier = notifier
+}
+
+// SetFeeSetter 设置费率处罚执行方，为空时只告警不处罚
+func (m *Monitor) SetFeeSetter(fees FeeSetter) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.fees = fees
+}
+
+// SetObligation 新增或更新报价义务，更新时保留当前周期的采样计数
+func (m *Monitor) SetObligation(obligation *Obligation) error {
+	if err := obligation.Validate(); err != nil {
+		return err
+	}
+	copied := *obligation
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key := trackerKey(copied.MarketMaker, copied.TradingPair)
+	if existing, exists := m.trackers[key]; exists {
+		existing.obligation = &copied
+		return nil
+	}
+	m.trackers[key] = &tracker{obligation: &copied}
+	return nil
+}
+
+// RemoveObligation 移除报价义务，不存在时返回 false
+func (m *Monitor) RemoveObligation(marketMaker, tradingPair string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key := trackerKey(marketMaker, tradingPair)
+	if _, exists := m.trackers[key]; !exists {
+		return false
+	}
+	delete(m.trackers, key)
+	return true
+}
+
+// Start 启动周期采样
+func (m *Monitor) Start() {
+	go func() {
+		ticker := time.NewTicker(m.config.SampleInterval)
+		defer ticker.Stop()
+
+		for now := range ticker.C {
+			m.Sample(now)
+		}
+	}()
+}
+
+// Sample 采样一次全部报价义务，考核周期已结束时先生成报告
+func (m *Monitor) Sample(now time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !now.Before(m.periodStart.Add(m.config.ReportPeriod)) {
+		m.closePeriodUnsafe(now)
+	}
+	for _, t := range m.trackers {
+		t.samples++
+		if m.quotingUnsafe(t.obligation) {
+			t.present++
+		}
+	}
+}
+
+// quotingUnsafe 做市商当前是否满足双边报价要求
+func (m *Monitor) quotingUnsafe(obligation *Obligation) bool {
+	bbo := m.engine.GetBBO(obligation.TradingPair)
+	if bbo.MidPrice == nil || !bbo.MidPrice.IsPositive() {
+		return false
+	}
+	band := bbo.MidPrice.Mul(obligation.MaxSpreadBps).Div(bpsDenominator)
+	minBid, maxAsk := bbo.MidPrice.Sub(band), bbo.MidPrice.Add(band)
+
+	hasBid, hasAsk := false, false
+	for _, order := range m.engine.GetUserOrders(obligation.MarketMaker) {
+		if order.TradingPair != obligation.TradingPair || order.GetRemainingAmount().LessThan(obligation.MinSize) {
+			continue
+		}
+		if order.Side == types.OrderSideBuy && !order.Price.LessThan(minBid) {
+			hasBid = true
+		}
+		if order.Side == types.OrderSideSell && !order.Price.GreaterThan(maxAsk) {
+			hasAsk = true
+		}
+	}
+	return hasBid && hasAsk
+}
+
+// closePeriodUnsafe 结束当前考核周期：生成报告、执行处罚或恢复费率、回调通知并重置计数
+func (m *Monitor) closePeriodUnsafe(now time.Time) {
+	reports := m.statusUnsafe(now)
+	m.periodStart = now
+
+	// 做市商在任一交易对不合规即处罚，全部合规后恢复
+	penalize := make(map[string]bool)
+	names := make(map[string]string)
+	for _, report := range reports {
+		key := strings.ToLower(report.MarketMaker)
+		penalize[key] = penalize[key] || !report.Compliant
+		names[key] = report.MarketMaker
+	}
+	for key, penalty := range penalize {
+		m.applyPenaltyUnsafe(names[key], penalty)
+	}
+	for _, report := range reports {
+		report.Penalized = m.penalized[strings.ToLower(report.MarketMaker)]
+	}
+
+	for _, report := range reports {
+		if !report.Compliant {
+			m.logger.WithFields(logrus.Fields{
+				"market_maker": report.MarketMaker,
+				"trading_pair": report.TradingPair,
+				"presence":     report.Presence.StringFixed(4),
+				"required":     report.Required.String(),
+			}).Warn("Market maker missed quoting obligation")
+		}
+		if m.notifier != nil {
+			m.notifier(report)
+		}
+	}
+
+	m.reports = append(m.reports, reports...)
+	if m.config.RetainReports > 0 && len(m.reports) > m.config.RetainReports {
+		m.reports = append([]*Report(nil), m.reports[len(m.reports)-m.config.RetainReports:]...)
+	}
+	for _, t := range m.trackers {
+		t.samples, t.present = 0, 0
+	}
+}
+
+// applyPenaltyUnsafe 按合规结果设置或恢复做市商费率，状态未变化时不发送交易
+func (m *Monitor) applyPenaltyUnsafe(marketMaker string, penalize bool) {
+	key := strings.ToLower(marketMaker)
+	if m.fees == nil || m.config.PenaltyFeeBps == 0 || m.penalized[key] == penalize {
+		return
+	}
+	rate := uint64(0)
+	if penalize {
+		rate = m.config.PenaltyFeeBps
+	}
+	if err := m.fees.SetUserFeeRates(marketMaker, rate, rate); err != nil {
+		m.logger.WithError(err).WithField("market_maker", marketMaker).Error("Failed to update market maker fee rates")
+		return
+	}
+	m.penalized[key] = penalize
+	m.logger.WithFields(logrus.Fields{
+		"market_maker": marketMaker,
+		"fee_bps":      rate,
+		"penalized":    penalize,
+	}).Info("Market maker fee rates updated")
+}
+
+// Status 当前考核周期截至目前的合规情况
+func (m *Monitor) Status() []*Report {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.statusUnsafe(time.Now())
+}
+
+// statusUnsafe 按当前计数生成报告，按做市商和交易对排序
+func (m *Monitor) statusUnsafe(now time.Time) []*Report {
+	reports := make([]*Report, 0, len(m.trackers))
+	for _, t := range m.trackers {
+		report := &Report{
+			MarketMaker: t.obligation.MarketMaker,
+			TradingPair: t.obligation.TradingPair,
+			PeriodStart: m.periodStart,
+			PeriodEnd:   now,
+			Samples:     t.samples,
+			Present:     t.present,
+			Presence:    decimal.Zero,
+			Required:    t.obligation.MinPresence,
+			Penalized:   m.penalized[strings.ToLower(t.obligation.MarketMaker)],
+		}
+		if t.samples > 0 {
+			report.Presence = decimal.NewFromInt(t.present).Div(decimal.NewFromInt(t.samples))
+		}
+		report.Compliant = !report.Presence.LessThan(report.Required)
+		reports = append(reports, report)
+	}
+	sort.Slice(reports, func(i, j int) bool {
+		if !strings.EqualFold(reports[i].MarketMaker, reports[j].MarketMaker) {
+			return strings.ToLower(reports[i].MarketMaker) < strings.ToLower(reports[j].MarketMaker)
+		}
+		return reports[i].TradingPair < reports[j].TradingPair
+	})
+	return reports
+}
+
+// Obligations 当前全部报价义务
+func (m *Monitor) Obligations() []*Obligation {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	obligations := make([]*Obligation, 0, len(m.trackers))
+	for _, t := range m.trackers {
+		copied := *t.obligation
+		obligations = append(obligations, &copied)
+	}
+	sort.Slice(obligations, func(i, j int) bool {
+		return trackerKey(obligations[i].MarketMaker, obligations[i].TradingPair) < trackerKey(obligations[j].MarketMaker, obligations[j].TradingPair)
+	})
+	return obligations
+}
+
+// Reports 已结束考核周期的合规报告，按时间倒序，marketMaker 或 tradingPair 为空时不过滤
+func (m *Monitor) Reports(marketMaker, tradingPair string, limit int) []*Report {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	result := make([]*Report, 0)
+	for i := len(m.reports) - 1; i >= 0; i-- {
+		if limit > 0 && len(result) >= limit {
+			break
+		}
+		report := m.reports[i]
+		if marketMaker != "" && !strings.EqualFold(report.MarketMaker, marketMaker) {
+			continue
+		}
+		if tradingPair != "" && report.TradingPair != tradingPair {
+			continue
+		}
+		copied := *report
+		result = append(result, &copied)
+	}
+	return result
+}
+
+func trackerKey(marketMaker, tradingPair string) string {
+	return strings.ToLower(marketMaker) + "|" + tradingPair
+}
@@ -0,0 +1,95 @@
+package obligations
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"orderbook-engine/internal/matching"
+	"orderbook-engine/internal/types"
+)
+
+const marketMaker = "0xMarketMaker"
+
+type fakeFees map[string]uint64
+
+func (f fakeFees) SetUserFeeRates(userAddress string, makerBps, takerBps uint64) error {
+	f[userAddress] = makerBps
+	return nil
+}
+
+func quote(side types.OrderSide, price int64) *types.Order {
+	return &types.Order{
+		ID:          uuid.New(),
+		UserAddress: marketMaker,
+		TradingPair: "WETH-USDC",
+		Side:        side,
+		Type:        types.OrderTypeLimit,
+		Price:       decimal.NewFromInt(price),
+		Amount:      decimal.NewFromInt(1),
+		CreatedAt:   time.Now(),
+	}
+}
+
+func TestMonitorReportsAndPenalizesMissedObligation(t *testing.T) {
+	engine := matching.NewMatchingEngine(logrus.New())
+	monitor, err := NewMonitor(engine, Config{
+		SampleInterval: time.Second,
+		ReportPeriod:   time.Minute,
+		PenaltyFeeBps:  50,
+	}, logrus.New())
+	require.NoError(t, err)
+	fees := fakeFees{}
+	monitor.SetFeeSetter(fees)
+	var notified []*Report
+	monitor.SetNotifier(func(report *Report) { notified = append(notified, report) })
+
+	require.NoError(t, monitor.SetObligation(&Obligation{
+		MarketMaker:  marketMaker,
+		TradingPair:  "WETH-USDC",
+		MaxSpreadBps: decimal.NewFromInt(10),
+		MinPresence:  decimal.NewFromFloat(0.75),
+		MinSize:      decimal.NewFromInt(1),
+	}))
+
+	bid := quote(types.OrderSideBuy, 1999)
+	_, err = engine.AddOrder(bid)
+	require.NoError(t, err)
+	_, err = engine.AddOrder(quote(types.OrderSideSell, 2001))
+	require.NoError(t, err)
+
+	// 4 次采样中只有 2 次双边报价：在场 50%，低于要求的 75%
+	start := time.Now()
+	monitor.Sample(start)
+	monitor.Sample(start.Add(time.Second))
+	engine.CancelOrder(bid.ID, bid.TradingPair)
+	monitor.Sample(start.Add(2 * time.Second))
+	monitor.Sample(start.Add(3 * time.Second))
+
+	status := monitor.Status()
+	require.Len(t, status, 1)
+	assert.True(t, status[0].Presence.Equal(decimal.NewFromFloat(0.5)))
+	assert.False(t, status[0].Compliant)
+
+	// 周期结束前恢复双边报价，结束时的采样计入下一周期
+	_, err = engine.AddOrder(quote(types.OrderSideBuy, 1999))
+	require.NoError(t, err)
+	monitor.Sample(start.Add(2 * time.Minute))
+	require.Len(t, notified, 1)
+	assert.False(t, notified[0].Compliant)
+	assert.True(t, notified[0].Penalized)
+	assert.Equal(t, uint64(50), fees[marketMaker])
+	assert.Len(t, monitor.Reports(marketMaker, "", 0), 1)
+
+	// 下一周期合规后还原费率
+	monitor.Sample(start.Add(3 * time.Minute))
+	require.Len(t, notified, 2)
+	assert.True(t, notified[1].Compliant)
+	assert.False(t, notified[1].Penalized)
+	assert.Equal(t, uint64(0), fees[marketMaker])
+}
@@ -0,0 +1,382 @@
+// Package openapi OpenAPI 3 文档生成
+// 请求与响应结构通过反射由 Go 类型生成 JSON Schema（遵循 json 标签），客户端 SDK 可据此自动生成并与服务端类型保持一致
+package openapi
+
+import (
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// Version 生成文档的 OpenAPI 版本
+const Version = "3.0.3"
+
+// Schema JSON Schema 对象
+type Schema map[string]interface{}
+
+// Parameter 查询参数
+type Parameter struct {
+	Name        string
+	Description string
+	Type        string // string、integer、number、boolean，为空时为 string
+	Required    bool
+}
+
+// Operation 接口说明
+type Operation struct {
+	Method   string
+	Path     string // gin 路由路径，:param 与 *param 转为 {param}
+	Summary  string
+	Tag      string
+	Query    []Parameter
+	Request  interface{} // 请求体示例值，按其类型生成 Schema，为空表示无请求体
+	Response interface{} // 成功响应示例值，为空时为任意对象
+	Status   int         // 成功状态码，为 0 时为 200
+	Security []string    // 可用的认证方式（任一即可），为空表示无需认证
+}
+
+// Document OpenAPI 文档构建器
+type Document struct {
+	mu         sync.Mutex
+	title      string
+	version    string
+	operations []Operation
+	security   map[string]Schema
+	errorType  interface{}
+	gen        *generator
+}
+
+// NewDocument 创建文档构建器
+func NewDocument(title, version string) *Document {
+	return &Document{
+		title:    title,
+		version:  version,
+		security: make(map[string]Schema),
+		gen:      newGenerator(),
+	}
+}
+
+// Add 添加接口
+func (d *Document) Add(operations ...Operation) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.operations = append(d.operations, operations...)
+}
+
+// Enum 声明字符串枚举类型的取值，生成为带 enum 的组件
+func (d *Document) Enum(value interface{}, values ...interface{}) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.gen.enums[reflect.TypeOf(value)] = values
+}
+
+// SecurityScheme 添加认证方式
+func (d *Document) SecurityScheme(name string, scheme Schema) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.security[name] = scheme
+}
+
+// ErrorResponse 设置错误响应结构，作为每个接口的 default 响应
+func (d *Document) ErrorResponse(value interface{}) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.errorType = value
+}
+
+// Build 生成 OpenAPI 文档
+func (d *Document) Build() map[string]interface{} {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	gen := d.gen.reset()
+	paths := make(map[string]map[string]interface{})
+	for _, op := range d.operations {
+		path, pathParams := convertPath(op.Path)
+		if paths[path] == nil {
+			paths[path] = make(map[string]interface{})
+		}
+		paths[path][strings.ToLower(op.Method)] = d.buildOperation(gen, op, pathParams)
+	}
+
+	components := map[string]interface{}{"schemas": gen.schemas}
+	if len(d.security) > 0 {
+		components["securitySchemes"] = d.security
+	}
+
+	return map[string]interface{}{
+		"openapi":    Version,
+		"info":       map[string]interface{}{"title": d.title, "version": d.version},
+		"paths":      paths,
+		"components": components,
+	}
+}
+
+func (d *Document) buildOperation(gen *generator, op Operation, pathParams []string) map[string]interface{} {
+	operation := map[string]interface{}{
+		"operationId": operationID(op),
+	}
+	if op.Summary != "" {
+		operation["summary"] = op.Summary
+	}
+	if op.Tag != "" {
+		operation["tags"] = []string{op.Tag}
+	}
+
+	parameters := make([]interface{}, 0, len(pathParams)+len(op.Query))
+	for _, name := range pathParams {
+		parameters = append(parameters, map[string]interface{}{
+			"name": name, "in": "path", "required": true, "schema": Schema{"type": "string"},
+		})
+	}
+	for _, param := range op.Query {
+		paramType := param.Type
+		if paramType == "" {
+			paramType = "string"
+		}
+		parameter := map[string]interface{}{
+			"name": param.Name, "in": "query", "required": param.Required, "schema": Schema{"type": paramType},
+		}
+		if param.Description != "" {
+			parameter["description"] = param.Description
+		}
+		parameters = append(parameters, parameter)
+	}
+	if len(parameters) > 0 {
+		operation["parameters"] = parameters
+	}
+
+	if op.Request != nil {
+		operation["requestBody"] = map[string]interface{}{
+			"required": true,
+			"content":  jsonContent(gen.schema(reflect.TypeOf(op.Request))),
+		}
+	}
+
+	status := op.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	response := Schema{"type": "object"}
+	if op.Response != nil {
+		response = gen.schema(reflect.TypeOf(op.Response))
+	}
+	responses := map[string]interface{}{
+		strconv.Itoa(status): map[string]interface{}{"description": http.StatusText(status), "content": jsonContent(response)},
+	}
+	if d.errorType != nil {
+		responses["default"] = map[string]interface{}{
+			"description": "Error",
+			"content":     jsonContent(gen.schema(reflect.TypeOf(d.errorType))),
+		}
+	}
+	operation["responses"] = responses
+
+	if len(op.Security) > 0 {
+		security := make([]interface{}, len(op.Security))
+		for i, name := range op.Security {
+			security[i] = map[string][]string{name: {}}
+		}
+		operation["security"] = security
+	}
+	return operation
+}
+
+func jsonContent(schema Schema) map[string]interface{} {
+	return map[string]interface{}{"application/json": map[string]interface{}{"schema": schema}}
+}
+
+// convertPath 将 gin 路由路径转为 OpenAPI 路径，返回路径参数名
+func convertPath(path string) (string, []string) {
+	segments := strings.Split(path, "/")
+	var params []string
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			params = append(params, segment[1:])
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/"), params
+}
+
+// operationID 由方法与路径生成唯一的 operationId，如 GET /orders/{order_id} 为 get_orders_order_id
+func operationID(op Operation) string {
+	replacer := strings.NewReplacer("/", "_", "-", "_", ":", "", "*", "", "{", "", "}", "")
+	return strings.ToLower(op.Method) + strings.TrimRight(replacer.Replace(strings.TrimPrefix(op.Path, "/api/v1")), "_")
+}
+
+// generator 由 Go 类型生成 JSON Schema，具名结构体与枚举注册为组件并以 $ref 引用
+type generator struct {
+	schemas map[string]Schema
+	names   map[reflect.Type]string
+	enums   map[reflect.Type][]interface{}
+}
+
+// 序列化为字符串等固定格式的类型
+var knownTypes = map[reflect.Type]Schema{
+	reflect.TypeOf(time.Time{}):       {"type": "string", "format": "date-time"},
+	reflect.TypeOf(uuid.UUID{}):       {"type": "string", "format": "uuid"},
+	reflect.TypeOf(decimal.Decimal{}): {"type": "string", "format": "decimal"},
+	reflect.TypeOf(time.Duration(0)):  {"type": "integer", "format": "int64", "description": "nanoseconds"},
+	reflect.TypeOf([]byte(nil)):       {"type": "string", "format": "byte"},
+}
+
+func newGenerator() *generator {
+	return &generator{enums: make(map[reflect.Type][]interface{})}
+}
+
+// reset 清空已生成的组件，保留枚举声明
+func (g *generator) reset() *generator {
+	g.schemas = make(map[string]Schema)
+	g.names = make(map[reflect.Type]string)
+	return g
+}
+
+func (g *generator) schema(t reflect.Type) Schema {
+	if t.Kind() == reflect.Ptr {
+		schema := g.schema(t.Elem())
+		if _, ref := schema["$ref"]; ref {
+			return Schema{"allOf": []interface{}{schema}, "nullable": true}
+		}
+		nullable := Schema{"nullable": true}
+		for key, value := range schema {
+			nullable[key] = value
+		}
+		return nullable
+	}
+	if known, ok := knownTypes[t]; ok {
+		return copySchema(known)
+	}
+	if values, ok := g.enums[t]; ok {
+		return g.component(t, func() Schema {
+			return Schema{"type": primitive(t.Kind()), "enum": values}
+		})
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.object(t)
+		}
+		return g.component(t, func() Schema { return g.object(t) })
+	case reflect.Slice, reflect.Array:
+		return Schema{"type": "array", "items": g.schema(elem(t))}
+	case reflect.Map:
+		return Schema{"type": "object", "additionalProperties": g.schema(elem(t))}
+	case reflect.Interface:
+		return Schema{}
+	case reflect.Int64, reflect.Uint64:
+		return Schema{"type": "integer", "format": "int64"}
+	case reflect.Int32, reflect.Uint32:
+		return Schema{"type": "integer", "format": "int32"}
+	case reflect.Float32, reflect.Float64:
+		return Schema{"type": "number", "format": "double"}
+	}
+	return Schema{"type": primitive(t.Kind())}
+}
+
+// component 将具名类型注册为组件，同名不同包的类型以包名前缀区分
+func (g *generator) component(t reflect.Type, build func() Schema) Schema {
+	if name, ok := g.names[t]; ok {
+		return ref(name)
+	}
+
+	name := t.Name()
+	if _, taken := g.schemas[name]; taken {
+		pkg := t.PkgPath()
+		name = pkg[strings.LastIndex(pkg, "/")+1:] + "." + name
+	}
+	g.names[t] = name
+	// 先占位，允许递归引用自身
+	g.schemas[name] = Schema{}
+	g.schemas[name] = build()
+	return ref(name)
+}
+
+// object 生成结构体的对象 Schema，匿名嵌入的结构体字段展开到外层
+func (g *generator) object(t reflect.Type) Schema {
+	properties := make(map[string]interface{})
+	var required []string
+	g.fields(t, properties, &required)
+
+	schema := Schema{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		sort.Strings(required)
+		schema["required"] = required
+	}
+	return schema
+}
+
+func (g *generator) fields(t reflect.Type, properties map[string]interface{}, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				g.fields(embedded, properties, required)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		properties[name] = g.schema(field.Type)
+		// 仅以 binding:"required" 标注的字段为必填，其余字段由服务端校验或取默认值
+		if strings.Contains(field.Tag.Get("binding"), "required") && !strings.Contains(options, "omitempty") {
+			*required = append(*required, name)
+		}
+	}
+}
+
+// elem 集合的元素类型，元素指针不会为 null，按指向的类型生成
+func elem(t reflect.Type) reflect.Type {
+	if t.Elem().Kind() == reflect.Ptr {
+		return t.Elem().Elem()
+	}
+	return t.Elem()
+}
+
+func primitive(kind reflect.Kind) string {
+	switch kind {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	}
+	return "string"
+}
+
+func ref(name string) Schema {
+	return Schema{"$ref": "#/components/schemas/" + name}
+}
+
+func copySchema(schema Schema) Schema {
+	copied := make(Schema, len(schema))
+	for key, value := range schema {
+		copied[key] = value
+	}
+	return copied
+}
@@ -0,0 +1,87 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type side string
+
+type audit struct {
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type order struct {
+	ID        uuid.UUID       `json:"id"`
+	Side      side            `json:"side" binding:"required"`
+	Price     decimal.Decimal `json:"price"`
+	ExpiresAt *time.Time      `json:"expires_at"`
+	Reason    string          `json:"reason,omitempty"`
+	Secret    string          `json:"-"`
+	Fills     []*fill         `json:"fills"`
+	audit
+}
+
+type fill struct {
+	Amount decimal.Decimal `json:"amount"`
+	Maker  *order          `json:"maker"`
+}
+
+type errorBody struct {
+	Error string `json:"error"`
+	Code  string `json:"code"`
+}
+
+func TestDocumentSchemasFollowJSONTags(t *testing.T) {
+	doc := NewDocument("Test API", "1.0.0")
+	doc.Enum(side(""), "buy", "sell")
+	doc.ErrorResponse(errorBody{})
+	doc.Add(Operation{
+		Method:  http.MethodPost,
+		Path:    "/api/v1/orders/:order_id",
+		Request: order{},
+		Response: struct {
+			Orders []order `json:"orders"`
+			Total  int     `json:"total"`
+		}{},
+		Status:   http.StatusCreated,
+		Security: []string{"apiKey"},
+	})
+
+	// 文档应可序列化为 JSON
+	raw, err := json.Marshal(doc.Build())
+	require.NoError(t, err)
+	var spec map[string]interface{}
+	require.NoError(t, json.Unmarshal(raw, &spec))
+
+	assert.Equal(t, Version, spec["openapi"])
+	schemas := spec["components"].(map[string]interface{})["schemas"].(map[string]interface{})
+	require.Contains(t, schemas, "order")
+	require.Contains(t, schemas, "fill")
+	assert.Equal(t, []interface{}{"buy", "sell"}, schemas["side"].(map[string]interface{})["enum"])
+
+	orderSchema := schemas["order"].(map[string]interface{})
+	properties := orderSchema["properties"].(map[string]interface{})
+	assert.Equal(t, "uuid", properties["id"].(map[string]interface{})["format"])
+	assert.Equal(t, "string", properties["price"].(map[string]interface{})["type"])
+	assert.Equal(t, true, properties["expires_at"].(map[string]interface{})["nullable"])
+	assert.Contains(t, properties, "created_at", "匿名嵌入字段展开")
+	assert.NotContains(t, properties, "Secret")
+	assert.Equal(t, []interface{}{"side"}, orderSchema["required"])
+	items := properties["fills"].(map[string]interface{})["items"].(map[string]interface{})
+	assert.Equal(t, "#/components/schemas/fill", items["$ref"])
+
+	operation := spec["paths"].(map[string]interface{})["/api/v1/orders/{order_id}"].(map[string]interface{})["post"].(map[string]interface{})
+	assert.Equal(t, "post_orders_order_id", operation["operationId"])
+	assert.Len(t, operation["parameters"], 1)
+	responses := operation["responses"].(map[string]interface{})
+	assert.Contains(t, responses, "201")
+	assert.Contains(t, responses, "default")
+}
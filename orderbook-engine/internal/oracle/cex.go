@@ -0,0 +1,130 @@
+package oracle
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// CEXOracle 基于中心化交易所REST行情的外部价格
+// 接口格式兼容 Binance /api/v3/ticker/price：{"symbol":"ETHUSDC","price":"2000.00"}
+// 行情由后台定时刷新，GetPrice 只读取缓存，不在风控等热路径上发起HTTP请求
+type CEXOracle struct {
+	mu       sync.RWMutex
+	baseURL  string
+	symbols  map[string]string // trading pair -> exchange symbol
+	client   *http.Client
+	interval time.Duration
+	maxAge   time.Duration
+	cache    map[string]*PriceQuote
+	errs     map[string]error // trading pair -> 最近一次刷新失败的原因
+}
+
+// NewCEXOracle 创建CEX预言机
+// interval 为后台刷新间隔，需调用 Start 开始刷新；maxAge 内刷新失败时继续使用上次的价格
+func NewCEXOracle(baseURL string, symbols map[string]string, interval, maxAge time.Duration) *CEXOracle {
+	return &CEXOracle{
+		baseURL:  baseURL,
+		symbols:  symbols,
+		client:   &http.Client{Timeout: 3 * time.Second},
+		interval: interval,
+		maxAge:   maxAge,
+		cache:    make(map[string]*PriceQuote),
+		errs:     make(map[string]error),
+	}
+}
+
+// Start 立即拉取一次行情，之后每个刷新间隔在后台刷新
+func (o *CEXOracle) Start() {
+	go func() {
+		o.Refresh()
+		ticker := time.NewTicker(o.interval)
+		for range ticker.C {
+			o.Refresh()
+		}
+	}()
+}
+
+// Refresh 拉取全部交易对的最新行情，失败的交易对保留上次的价格
+func (o *CEXOracle) Refresh() {
+	for tradingPair, symbol := range o.symbols {
+		quote, err := o.fetch(tradingPair, symbol)
+
+		o.mu.Lock()
+		if err != nil {
+			o.errs[tradingPair] = err
+		} else {
+			o.cache[tradingPair] = quote
+			delete(o.errs, tradingPair)
+		}
+		o.mu.Unlock()
+	}
+}
+
+// GetPrice 获取最近一次刷新的交易所价格
+func (o *CEXOracle) GetPrice(tradingPair string) (*PriceQuote, error) {
+	if _, exists := o.symbols[tradingPair]; !exists {
+		return nil, ErrPriceUnavailable
+	}
+
+	o.mu.RLock()
+	cached, lastErr := o.cache[tradingPair], o.errs[tradingPair]
+	o.mu.RUnlock()
+
+	if cached == nil {
+		if lastErr != nil {
+			return nil, fmt.Errorf("%w: %v", ErrPriceUnavailable, lastErr)
+		}
+		return nil, ErrPriceUnavailable
+	}
+	if err := checkStaleness(cached, o.maxAge); err != nil {
+		if lastErr != nil {
+			return nil, fmt.Errorf("%w (last refresh: %v)", err, lastErr)
+		}
+		return nil, err
+	}
+	return cached, nil
+}
+
+// fetch 请求交易所行情接口
+func (o *CEXOracle) fetch(tradingPair, symbol string) (*PriceQuote, error) {
+	resp, err := o.client.Get(o.baseURL + "/api/v3/ticker/price?symbol=" + url.QueryEscape(symbol))
+	if err != nil {
+		return nil, fmt.Errorf("ticker request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("ticker request returned status %d", resp.StatusCode)
+	}
+
+	var ticker struct {
+		Symbol string `json:"symbol"`
+		Price  string `json:"price"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&ticker); err != nil {
+		return nil, fmt.Errorf("failed to decode ticker: %w", err)
+	}
+
+	price, err := decimal.NewFromString(ticker.Price)
+	if err != nil || !price.IsPositive() {
+		return nil, fmt.Errorf("%w: invalid ticker price %q", ErrPriceUnavailable, ticker.Price)
+	}
+
+	return &PriceQuote{
+		TradingPair: tradingPair,
+		Price:       price,
+		Source:      "cex",
+		UpdatedAt:   time.Now(),
+	}, nil
+}
+
+// Name 价格来源名称
+func (o *CEXOracle) Name() string {
+	return "cex"
+}
@@ -0,0 +1,137 @@
+package oracle
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/shopspring/decimal"
+)
+
+// aggregatorV3ABI Chainlink AggregatorV3Interface（仅包含用到的方法）
+const aggregatorV3ABI = `[
+	{
+		"inputs": [],
+		"name": "decimals",
+		"outputs": [{"internalType": "uint8", "name": "", "type": "uint8"}],
+		"stateMutability": "view",
+		"type": "function"
+	},
+	{
+		"inputs": [],
+		"name": "latestRoundData",
+		"outputs": [
+			{"internalType": "uint80", "name": "roundId", "type": "uint80"},
+			{"internalType": "int256", "name": "answer", "type": "int256"},
+			{"internalType": "uint256", "name": "startedAt", "type": "uint256"},
+			{"internalType": "uint256", "name": "updatedAt", "type": "uint256"},
+			{"internalType": "uint80", "name": "answeredInRound", "type": "uint80"}
+		],
+		"stateMutability": "view",
+		"type": "function"
+	}
+]`
+
+// ChainlinkOracle 基于Chainlink喂价合约的外部价格
+type ChainlinkOracle struct {
+	mu       sync.Mutex
+	feeds    map[string]*bind.BoundContract // trading pair -> aggregator
+	decimals map[string]int32               // trading pair -> feed decimals (缓存)
+	maxAge   time.Duration
+}
+
+// NewChainlinkOracle 创建Chainlink预言机
+// feeds 为交易对到喂价合约地址的映射
+func NewChainlinkOracle(caller bind.ContractCaller, feeds map[string]string, maxAge time.Duration) (*ChainlinkOracle, error) {
+	parsed, err := abi.JSON(strings.NewReader(aggregatorV3ABI))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse aggregator ABI: %w", err)
+	}
+
+	contracts := make(map[string]*bind.BoundContract, len(feeds))
+	for pair, address := range feeds {
+		if !common.IsHexAddress(address) {
+			return nil, fmt.Errorf("invalid feed address for %s: %s", pair, address)
+		}
+		contracts[pair] = bind.NewBoundContract(common.HexToAddress(address), parsed, caller, nil, nil)
+	}
+
+	return &ChainlinkOracle{
+		feeds:    contracts,
+		decimals: make(map[string]int32),
+		maxAge:   maxAge,
+	}, nil
+}
+
+// GetPrice 读取喂价合约最新一轮价格
+func (o *ChainlinkOracle) GetPrice(tradingPair string) (*PriceQuote, error) {
+	feed, exists := o.feeds[tradingPair]
+	if !exists {
+		return nil, ErrPriceUnavailable
+	}
+
+	decimals, err := o.feedDecimals(tradingPair, feed)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []interface{}
+	if err := feed.Call(&bind.CallOpts{}, &out, "latestRoundData"); err != nil {
+		return nil, fmt.Errorf("latestRoundData call failed: %w", err)
+	}
+	if len(out) != 5 {
+		return nil, fmt.Errorf("unexpected latestRoundData output length: %d", len(out))
+	}
+
+	answer, ok := out[1].(*big.Int)
+	if !ok || answer.Sign() <= 0 {
+		return nil, fmt.Errorf("%w: invalid answer from feed", ErrPriceUnavailable)
+	}
+	updatedAt, ok := out[3].(*big.Int)
+	if !ok {
+		return nil, fmt.Errorf("unexpected updatedAt type %T", out[3])
+	}
+
+	quote := &PriceQuote{
+		TradingPair: tradingPair,
+		Price:       decimal.NewFromBigInt(answer, -decimals),
+		Source:      "chainlink",
+		UpdatedAt:   time.Unix(updatedAt.Int64(), 0),
+	}
+	if err := checkStaleness(quote, o.maxAge); err != nil {
+		return nil, err
+	}
+	return quote, nil
+}
+
+// feedDecimals 获取喂价精度（只查询一次）
+func (o *ChainlinkOracle) feedDecimals(tradingPair string, feed *bind.BoundContract) (int32, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if d, ok := o.decimals[tradingPair]; ok {
+		return d, nil
+	}
+
+	var out []interface{}
+	if err := feed.Call(&bind.CallOpts{}, &out, "decimals"); err != nil {
+		return 0, fmt.Errorf("decimals call failed: %w", err)
+	}
+	d, ok := out[0].(uint8)
+	if !ok {
+		return 0, fmt.Errorf("unexpected decimals type %T", out[0])
+	}
+
+	o.decimals[tradingPair] = int32(d)
+	return int32(d), nil
+}
+
+// Name 价格来源名称
+func (o *ChainlinkOracle) Name() string {
+	return "chainlink"
+}
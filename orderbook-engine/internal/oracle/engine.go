@@ -0,0 +1,60 @@
+package oracle
+
+import (
+	"time"
+
+	"orderbook-engine/internal/matching"
+)
+
+// EngineOracle 基于撮合引擎的内部价格
+// 优先使用买一卖一中间价，盘口单边时退化为最新成交价
+// 中间价的时间为订单簿最近一次变更的时间，长时间无变动的盘口与过期的成交价一样不作为参考
+type EngineOracle struct {
+	engine      *matching.MatchingEngine
+	maxTradeAge time.Duration // 中间价与最新成交价的最大有效期
+}
+
+// NewEngineOracle 创建内部价格预言机
+func NewEngineOracle(engine *matching.MatchingEngine, maxTradeAge time.Duration) *EngineOracle {
+	return &EngineOracle{
+		engine:      engine,
+		maxTradeAge: maxTradeAge,
+	}
+}
+
+// GetPrice 获取中间价或最新成交价
+func (o *EngineOracle) GetPrice(tradingPair string) (*PriceQuote, error) {
+	if mid, updatedAt, ok := o.engine.GetMidPrice(tradingPair); ok {
+		quote := &PriceQuote{
+			TradingPair: tradingPair,
+			Price:       mid,
+			Source:      "engine_mid",
+			UpdatedAt:   updatedAt,
+		}
+		if err := checkStaleness(quote, o.maxTradeAge); err != nil {
+			return nil, err
+		}
+		return quote, nil
+	}
+
+	price, tradedAt, ok := o.engine.GetLastTradePrice(tradingPair)
+	if !ok {
+		return nil, ErrPriceUnavailable
+	}
+
+	quote := &PriceQuote{
+		TradingPair: tradingPair,
+		Price:       price,
+		Source:      "engine_last_trade",
+		UpdatedAt:   tradedAt,
+	}
+	if err := checkStaleness(quote, o.maxTradeAge); err != nil {
+		return nil, err
+	}
+	return quote, nil
+}
+
+// Name 价格来源名称
+func (o *EngineOracle) Name() string {
+	return "engine"
+}
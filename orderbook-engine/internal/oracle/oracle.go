@@ -0,0 +1,80 @@
+// Package oracle 提供参考价格来源
+// 用于风控价格偏差检查等需要"市场价格"的场景
+package oracle
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+var (
+	// ErrPriceUnavailable 没有可用价格
+	ErrPriceUnavailable = errors.New("price unavailable")
+	// ErrPriceStale 价格已过期
+	ErrPriceStale = errors.New("price is stale")
+)
+
+// PriceQuote 参考价格
+type PriceQuote struct {
+	TradingPair string          `json:"trading_pair"`
+	Price       decimal.Decimal `json:"price"`
+	Source      string          `json:"source"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
+
+// PriceOracle 价格预言机接口
+type PriceOracle interface {
+	// GetPrice 获取交易对参考价格，价格不可用或过期时返回错误
+	GetPrice(tradingPair string) (*PriceQuote, error)
+	// Name 价格来源名称
+	Name() string
+}
+
+// checkStaleness 检查价格是否过期（maxAge<=0 表示不检查）
+func checkStaleness(quote *PriceQuote, maxAge time.Duration) error {
+	if maxAge <= 0 {
+		return nil
+	}
+	if age := time.Since(quote.UpdatedAt); age > maxAge {
+		return fmt.Errorf("%w: %s price for %s is %s old (max %s)",
+			ErrPriceStale, quote.Source, quote.TradingPair, age.Truncate(time.Second), maxAge)
+	}
+	return nil
+}
+
+// FallbackOracle 组合预言机，按顺序尝试各个价格来源
+type FallbackOracle struct {
+	oracles []PriceOracle
+}
+
+// NewFallbackOracle 创建组合预言机
+func NewFallbackOracle(oracles ...PriceOracle) *FallbackOracle {
+	return &FallbackOracle{oracles: oracles}
+}
+
+// GetPrice 返回第一个可用且未过期的价格
+func (f *FallbackOracle) GetPrice(tradingPair string) (*PriceQuote, error) {
+	var errs []string
+	for _, o := range f.oracles {
+		quote, err := o.GetPrice(tradingPair)
+		if err == nil {
+			return quote, nil
+		}
+		errs = append(errs, fmt.Sprintf("%s: %v", o.Name(), err))
+	}
+
+	return nil, fmt.Errorf("%w for %s (%s)", ErrPriceUnavailable, tradingPair, strings.Join(errs, "; "))
+}
+
+// Name 价格来源名称
+func (f *FallbackOracle) Name() string {
+	names := make([]string, len(f.oracles))
+	for i, o := range f.oracles {
+		names[i] = o.Name()
+	}
+	return "fallback(" + strings.Join(names, ",") + ")"
+}
@@ -0,0 +1,132 @@
+package oracle
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"orderbook-engine/internal/matching"
+	"orderbook-engine/internal/types"
+)
+
+// staticOracle 固定返回 quote 或 err 的价格来源
+type staticOracle struct {
+	name  string
+	quote *PriceQuote
+	err   error
+}
+
+func (o *staticOracle) GetPrice(tradingPair string) (*PriceQuote, error) {
+	return o.quote, o.err
+}
+
+func (o *staticOracle) Name() string {
+	return o.name
+}
+
+func TestCEXOracleReadsRefreshedPrice(t *testing.T) {
+	var requests, failing atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if failing.Load() == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		assert.Equal(t, "ETHUSDC", r.URL.Query().Get("symbol"))
+		w.Write([]byte(`{"symbol":"ETHUSDC","price":"2000.50"}`))
+	}))
+	defer server.Close()
+
+	o := NewCEXOracle(server.URL, map[string]string{"WETH-USDC": "ETHUSDC"}, time.Minute, time.Minute)
+
+	// 尚未刷新时没有价格，查询不会发起请求
+	_, err := o.GetPrice("WETH-USDC")
+	assert.ErrorIs(t, err, ErrPriceUnavailable)
+	_, err = o.GetPrice("WBTC-USDC")
+	assert.ErrorIs(t, err, ErrPriceUnavailable)
+	assert.Zero(t, requests.Load())
+
+	o.Refresh()
+	for i := 0; i < 3; i++ {
+		quote, err := o.GetPrice("WETH-USDC")
+		require.NoError(t, err)
+		assert.True(t, quote.Price.Equal(decimal.RequireFromString("2000.50")))
+		assert.Equal(t, "cex", quote.Source)
+	}
+	assert.Equal(t, int32(1), requests.Load())
+
+	// 刷新失败时继续使用未过期的价格
+	failing.Store(1)
+	o.Refresh()
+	quote, err := o.GetPrice("WETH-USDC")
+	require.NoError(t, err)
+	assert.True(t, quote.Price.Equal(decimal.RequireFromString("2000.50")))
+
+	// 超过最大有效期后拒绝使用
+	o.mu.Lock()
+	o.cache["WETH-USDC"].UpdatedAt = time.Now().Add(-2 * time.Minute)
+	o.mu.Unlock()
+	_, err = o.GetPrice("WETH-USDC")
+	assert.ErrorIs(t, err, ErrPriceStale)
+}
+
+func TestEngineOracleMidUsesBookUpdateTime(t *testing.T) {
+	engine := matching.NewMatchingEngine(logrus.New())
+	o := NewEngineOracle(engine, 50*time.Millisecond)
+
+	_, err := o.GetPrice("WETH-USDC")
+	assert.ErrorIs(t, err, ErrPriceUnavailable)
+
+	before := time.Now()
+	for _, order := range []*types.Order{
+		{ID: uuid.New(), UserAddress: "maker", TradingPair: "WETH-USDC", Side: types.OrderSideBuy, Type: types.OrderTypeLimit,
+			Price: decimal.NewFromInt(1990), Amount: decimal.NewFromInt(1), Status: types.OrderStatusOpen, CreatedAt: time.Now()},
+		{ID: uuid.New(), UserAddress: "maker", TradingPair: "WETH-USDC", Side: types.OrderSideSell, Type: types.OrderTypeLimit,
+			Price: decimal.NewFromInt(2010), Amount: decimal.NewFromInt(1), Status: types.OrderStatusOpen, CreatedAt: time.Now()},
+	} {
+		_, err := engine.AddOrder(order)
+		require.NoError(t, err)
+	}
+	after := time.Now()
+
+	quote, err := o.GetPrice("WETH-USDC")
+	require.NoError(t, err)
+	assert.Equal(t, "engine_mid", quote.Source)
+	assert.True(t, quote.Price.Equal(decimal.NewFromInt(2000)))
+	// 中间价的时间为订单簿变更时间，而不是查询时间
+	assert.False(t, quote.UpdatedAt.Before(before))
+	assert.False(t, quote.UpdatedAt.After(after))
+
+	// 盘口长时间未变动时不再作为参考价格
+	time.Sleep(60 * time.Millisecond)
+	_, err = o.GetPrice("WETH-USDC")
+	assert.ErrorIs(t, err, ErrPriceStale)
+}
+
+func TestFallbackOracleUsesFirstAvailable(t *testing.T) {
+	quote := &PriceQuote{TradingPair: "WETH-USDC", Price: decimal.NewFromInt(2000), Source: "b", UpdatedAt: time.Now()}
+	f := NewFallbackOracle(
+		&staticOracle{name: "a", err: ErrPriceStale},
+		&staticOracle{name: "b", quote: quote},
+	)
+	assert.Equal(t, "fallback(a,b)", f.Name())
+
+	got, err := f.GetPrice("WETH-USDC")
+	require.NoError(t, err)
+	assert.Equal(t, quote, got)
+
+	// 全部不可用时汇总各来源的错误
+	f = NewFallbackOracle(&staticOracle{name: "a", err: errors.New("down")})
+	_, err = f.GetPrice("WETH-USDC")
+	assert.ErrorIs(t, err, ErrPriceUnavailable)
+	assert.Contains(t, err.Error(), "a: down")
+}
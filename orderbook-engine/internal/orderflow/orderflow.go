@@ -0,0 +1,417 @@
+// Package orderflow 用户订单流统计
+// 按撮合引擎事件统计每个用户在滚动窗口内的下单、撤单、改单（撤单后立即同向重下）与被拒消息数，
+// 以及已终结订单的成交比例和挂单存续时间，供管理接口查看并为风控撤单率检查提供数据。
+// 只统计用户撤单（撤单事件没有原因代码），下架、冻结、到期等系统撤单不计入；统计保存在内存中，重启后重新累计
+package orderflow
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"orderbook-engine/internal/matching"
+	"orderbook-engine/internal/types"
+)
+
+// Config 订单流统计配置
+type Config struct {
+	Windows       []time.Duration `json:"windows"`        // 统计窗口，最长窗口决定保留的数据
+	BucketSize    time.Duration   `json:"bucket_size"`    // 统计桶粒度，窗口边界按桶对齐
+	ReplaceWindow time.Duration   `json:"replace_window"` // 撤单后该时间内同一交易对同方向的新订单计为改单
+}
+
+// DefaultConfig 默认配置：1分钟、15分钟与1小时窗口
+func DefaultConfig() Config {
+	return Config{
+		Windows:       []time.Duration{time.Minute, 15 * time.Minute, time.Hour},
+		BucketSize:    10 * time.Second,
+		ReplaceWindow: time.Second,
+	}
+}
+
+// Validate 校验配置
+func (c Config) Validate() error {
+	if len(c.Windows) == 0 {
+		return errors.New("at least one window is required")
+	}
+	if c.BucketSize <= 0 {
+		return errors.New("bucket size must be positive")
+	}
+	for _, window := range c.Windows {
+		if window < c.BucketSize {
+			return errors.New("windows must not be shorter than the bucket size")
+		}
+	}
+	if c.ReplaceWindow < 0 {
+		return errors.New("replace window must not be negative")
+	}
+	return nil
+}
+
+// WindowStats 用户在一个滚动窗口内的订单流统计
+type WindowStats struct {
+	Window        string          `json:"window"`
+	Orders        int             `json:"orders"`   // 被接受的新订单
+	Cancels       int             `json:"cancels"`  // 用户撤单
+	Replaces      int             `json:"replaces"` // 改单：撤单后 ReplaceWindow 内同交易对同方向的新订单，同时计入 Orders
+	Rejects       int             `json:"rejects"`  // 被拒绝的订单
+	Fills         int             `json:"fills"`    // 参与的成交笔数（maker 与 taker）
+	MessageRate   decimal.Decimal `json:"messages_per_second"`
+	CancelRatio   decimal.Decimal `json:"cancel_ratio"`    // 撤单数 / 下单数
+	ReplaceRatio  decimal.Decimal `json:"replace_ratio"`   // 改单数 / 下单数
+	ClosedOrders  int             `json:"closed_orders"`   // 窗口内终结（成交完、撤销或过期）的订单
+	FillRatio     decimal.Decimal `json:"fill_ratio"`      // 终结订单中有成交的比例
+	AvgLifetimeMs int64           `json:"avg_lifetime_ms"` // 终结挂单从下单到终结的平均时长，立即成交的订单不计入
+}
+
+// UserFlow 用户订单流统计
+type UserFlow struct {
+	UserAddress string         `json:"user_address"`
+	OpenOrders  int            `json:"open_orders"`
+	Windows     []*WindowStats `json:"windows"`
+	Timestamp   time.Time      `json:"timestamp"`
+}
+
+// bucket 一个统计桶的计数
+type bucket struct {
+	start    time.Time
+	orders   int
+	cancels  int
+	replaces int
+	rejects  int
+	fills    int
+	closed   int
+	filled   int           // 终结订单中有成交的
+	rested   int           // 终结订单中挂过单的
+	lifetime time.Duration // 挂过单的终结订单存续时间之和
+}
+
+// userFlow 用户的统计桶与最近撤单时间（由 Tracker 锁保护）
+type userFlow struct {
+	buckets     []*bucket            // 按时间顺序
+	lastCancels map[string]time.Time // 交易对|方向 -> 最近撤单时间
+	open        int
+}
+
+// openOrder 统计中的未终结订单
+type openOrder struct {
+	user      string
+	createdAt time.Time
+	remaining decimal.Decimal
+	filled    bool
+	rested    bool
+}
+
+// Tracker 订单流统计
+type Tracker struct {
+	mu        sync.Mutex
+	config    Config
+	retention time.Duration
+	users     map[string]*userFlow
+	orders    map[uuid.UUID]*openOrder
+	lastSweep time.Time
+}
+
+// NewTracker 创建订单流统计，配置不合法时使用默认配置
+func NewTracker(config Config) *Tracker {
+	if config.Validate() != nil {
+		config = DefaultConfig()
+	}
+	windows := append([]time.Duration(nil), config.Windows...)
+	sort.Slice(windows, func(i, j int) bool { return windows[i] < windows[j] })
+	config.Windows = windows
+
+	return &Tracker{
+		config:    config,
+		retention: windows[len(windows)-1],
+		users:     make(map[string]*userFlow),
+		orders:    make(map[uuid.UUID]*openOrder),
+	}
+}
+
+// Observe 计入撮合引擎事件
+func (t *Tracker) Observe(event *matching.MatchEvent) {
+	if event.Order == nil {
+		return
+	}
+	at := event.Timestamp
+	if at.IsZero() {
+		at = time.Now()
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	switch event.Type {
+	case matching.EventOrderAdded:
+		t.orderAddedLocked(event.Order, at)
+		t.fillsLocked(event.Order, event.Fills, at)
+	case matching.EventAuctionUncrossed:
+		t.fillsLocked(event.Order, event.Fills, at)
+	case matching.EventOrderCancelled:
+		if event.Order.StatusReason == "" {
+			user := t.userLocked(event.Order.UserAddress)
+			t.bucketLocked(user, at).cancels++
+			user.lastCancels[sideKey(event.Order)] = at
+		}
+		t.closeLocked(event.Order.ID, at)
+	case matching.EventOrderExpired:
+		t.closeLocked(event.Order.ID, at)
+	case matching.EventOrderRejected:
+		t.bucketLocked(t.userLocked(event.Order.UserAddress), at).rejects++
+	case matching.EventOrderReduced:
+		if open, ok := t.orders[event.Order.ID]; ok {
+			open.remaining = event.Order.GetRemainingAmount()
+		}
+	}
+
+	if at.Sub(t.lastSweep) > t.retention {
+		t.sweepLocked(at)
+	}
+}
+
+// orderAddedLocked 计入新订单，撤单后 ReplaceWindow 内同向的新订单计为改单；未完全成交的限价单开始计时
+func (t *Tracker) orderAddedLocked(order *types.Order, at time.Time) {
+	user := t.userLocked(order.UserAddress)
+	current := t.bucketLocked(user, at)
+	current.orders++
+
+	key := sideKey(order)
+	if cancelledAt, ok := user.lastCancels[key]; ok {
+		if at.Sub(cancelledAt) <= t.config.ReplaceWindow {
+			current.replaces++
+		}
+		delete(user.lastCancels, key)
+	}
+
+	createdAt := order.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = at
+	}
+	t.orders[order.ID] = &openOrder{
+		user:      strings.ToLower(order.UserAddress),
+		createdAt: createdAt,
+		remaining: order.Amount,
+		rested:    order.Status == types.OrderStatusOpen || order.Status == types.OrderStatusPartiallyFilled,
+	}
+	user.open++
+}
+
+// fillsLocked 计入 taker 订单的成交，成交完的 maker 与 taker 订单终结；taker 未挂单即终结（市价单剩余撤销）时同样终结
+func (t *Tracker) fillsLocked(taker *types.Order, fills []*types.Fill, at time.Time) {
+	for _, fill := range fills {
+		for _, side := range []struct {
+			user    string
+			orderID uuid.UUID
+		}{{fill.TakerUserAddress, fill.TakerOrderID}, {fill.MakerUserAddress, fill.MakerOrderID}} {
+			if side.user != "" {
+				t.bucketLocked(t.userLocked(side.user), at).fills++
+			}
+			open, ok := t.orders[side.orderID]
+			if !ok {
+				continue
+			}
+			open.filled = true
+			open.remaining = open.remaining.Sub(fill.Amount)
+			if !open.remaining.IsPositive() {
+				t.closeLocked(side.orderID, at)
+			}
+		}
+	}
+
+	switch taker.Status {
+	case types.OrderStatusFilled, types.OrderStatusCancelled, types.OrderStatusExpired:
+		t.closeLocked(taker.ID, at)
+	}
+}
+
+// closeLocked 订单终结，记录成交比例与挂单存续时间；统计开始前的订单忽略
+func (t *Tracker) closeLocked(orderID uuid.UUID, at time.Time) {
+	open, ok := t.orders[orderID]
+	if !ok {
+		return
+	}
+	delete(t.orders, orderID)
+
+	user := t.userLocked(open.user)
+	if user.open > 0 {
+		user.open--
+	}
+	current := t.bucketLocked(user, at)
+	current.closed++
+	if open.filled {
+		current.filled++
+	}
+	if open.rested {
+		current.rested++
+		current.lifetime += at.Sub(open.createdAt)
+	}
+}
+
+// userLocked 获取或创建用户统计
+func (t *Tracker) userLocked(userAddress string) *userFlow {
+	key := strings.ToLower(userAddress)
+	user, ok := t.users[key]
+	if !ok {
+		user = &userFlow{lastCancels: make(map[string]time.Time)}
+		t.users[key] = user
+	}
+	return user
+}
+
+// bucketLocked 时间所在的统计桶，乱序到达的旧事件计入最近的桶
+func (t *Tracker) bucketLocked(user *userFlow, at time.Time) *bucket {
+	start := at.Truncate(t.config.BucketSize)
+	if n := len(user.buckets); n > 0 && !user.buckets[n-1].start.Before(start) {
+		return user.buckets[n-1]
+	}
+	user.buckets = append(t.prune(user.buckets, at.Add(-t.retention)), &bucket{start: start})
+	return user.buckets[len(user.buckets)-1]
+}
+
+// sweepLocked 清理保留期外的统计桶，没有统计与未终结订单的用户移除
+func (t *Tracker) sweepLocked(now time.Time) {
+	t.lastSweep = now
+	cutoff := now.Add(-t.retention)
+	for key, user := range t.users {
+		user.buckets = t.prune(user.buckets, cutoff)
+		for side, cancelledAt := range user.lastCancels {
+			if now.Sub(cancelledAt) > t.config.ReplaceWindow {
+				delete(user.lastCancels, side)
+			}
+		}
+		if len(user.buckets) == 0 && user.open == 0 && len(user.lastCancels) == 0 {
+			delete(t.users, key)
+		}
+	}
+}
+
+// User 获取用户在各配置窗口内的统计
+func (t *Tracker) User(userAddress string) *UserFlow {
+	now := time.Now()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	flow := &UserFlow{UserAddress: userAddress, Windows: make([]*WindowStats, 0, len(t.config.Windows)), Timestamp: now}
+	user, ok := t.users[strings.ToLower(userAddress)]
+	if ok {
+		flow.OpenOrders = user.open
+	}
+	for _, window := range t.config.Windows {
+		var buckets []*bucket
+		if ok {
+			buckets = user.buckets
+		}
+		flow.Windows = append(flow.Windows, t.windowStats(buckets, window, now))
+	}
+	return flow
+}
+
+// Top 获取窗口内消息数最多的用户，window 超过保留期时按保留期统计
+func (t *Tracker) Top(window time.Duration, limit int) []*UserFlow {
+	now := time.Now()
+
+	t.mu.Lock()
+	result := make([]*UserFlow, 0, len(t.users))
+	for address, user := range t.users {
+		stats := t.windowStats(user.buckets, window, now)
+		if messageCount(stats) == 0 {
+			continue
+		}
+		result = append(result, &UserFlow{UserAddress: address, OpenOrders: user.open, Windows: []*WindowStats{stats}, Timestamp: now})
+	}
+	t.mu.Unlock()
+
+	sort.Slice(result, func(i, j int) bool {
+		left, right := messageCount(result[i].Windows[0]), messageCount(result[j].Windows[0])
+		if left != right {
+			return left > right
+		}
+		return result[i].UserAddress < result[j].UserAddress
+	})
+	if limit > 0 && len(result) > limit {
+		result = result[:limit]
+	}
+	return result
+}
+
+// CancelCounts 用户在窗口内的下单数与撤单数（实现 riskcontrol.OrderFlowSource）
+func (t *Tracker) CancelCounts(userAddress string, window time.Duration) (int, int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	user, ok := t.users[strings.ToLower(userAddress)]
+	if !ok {
+		return 0, 0
+	}
+	stats := t.windowStats(user.buckets, window, time.Now())
+	return stats.Orders, stats.Cancels
+}
+
+// windowStats 汇总窗口内的统计桶（调用方持有锁）
+func (t *Tracker) windowStats(buckets []*bucket, window time.Duration, now time.Time) *WindowStats {
+	if window > t.retention {
+		window = t.retention
+	}
+	stats := &WindowStats{Window: window.String()}
+	cutoff := now.Add(-window)
+	var filled, rested int
+	var lifetime time.Duration
+	for i := len(buckets) - 1; i >= 0; i-- {
+		b := buckets[i]
+		if !b.start.Add(t.config.BucketSize).After(cutoff) {
+			break
+		}
+		stats.Orders += b.orders
+		stats.Cancels += b.cancels
+		stats.Replaces += b.replaces
+		stats.Rejects += b.rejects
+		stats.Fills += b.fills
+		stats.ClosedOrders += b.closed
+		filled += b.filled
+		rested += b.rested
+		lifetime += b.lifetime
+	}
+
+	stats.MessageRate = decimal.NewFromInt(int64(messageCount(stats))).Div(decimal.NewFromFloat(window.Seconds())).Round(4)
+	stats.CancelRatio = ratio(stats.Cancels, stats.Orders)
+	stats.ReplaceRatio = ratio(stats.Replaces, stats.Orders)
+	stats.FillRatio = ratio(filled, stats.ClosedOrders)
+	if rested > 0 {
+		stats.AvgLifetimeMs = (lifetime / time.Duration(rested)).Milliseconds()
+	}
+	return stats
+}
+
+// prune 移除在 cutoff 之前结束的统计桶
+func (t *Tracker) prune(buckets []*bucket, cutoff time.Time) []*bucket {
+	i := 0
+	for i < len(buckets) && !buckets[i].start.Add(t.config.BucketSize).After(cutoff) {
+		i++
+	}
+	return buckets[i:]
+}
+
+// messageCount 窗口内的消息数（下单、撤单与被拒订单）
+func messageCount(stats *WindowStats) int {
+	return stats.Orders + stats.Cancels + stats.Rejects
+}
+
+// ratio 比例，分母为零时为零
+func ratio(numerator, denominator int) decimal.Decimal {
+	if denominator == 0 {
+		return decimal.Zero
+	}
+	return decimal.NewFromInt(int64(numerator)).Div(decimal.NewFromInt(int64(denominator))).Round(4)
+}
+
+// sideKey 改单识别的交易对与方向
+func sideKey(order *types.Order) string {
+	return order.TradingPair + "|" + string(order.Side)
+}
@@ -0,0 +1,137 @@
+package orderflow
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"orderbook-engine/internal/matching"
+	"orderbook-engine/internal/types"
+)
+
+const (
+	alice = "0xAAAAaaaaAAAAaaaaAAAAaaaaAAAAaaaaAAAAaaaa"
+	bob   = "0xBBBBbbbbBBBBbbbbBBBBbbbbBBBBbbbbBBBBbbbb"
+)
+
+func order(user string, side types.OrderSide, amount string, createdAt time.Time) *types.Order {
+	return &types.Order{
+		ID:          uuid.New(),
+		UserAddress: user,
+		TradingPair: "WETH-USDC",
+		Side:        side,
+		Type:        types.OrderTypeLimit,
+		Price:       decimal.NewFromInt(2000),
+		Amount:      decimal.RequireFromString(amount),
+		Status:      types.OrderStatusOpen,
+		CreatedAt:   createdAt,
+	}
+}
+
+func event(eventType string, o *types.Order, at time.Time, fills ...*types.Fill) *matching.MatchEvent {
+	return &matching.MatchEvent{Type: eventType, TradingPair: o.TradingPair, Order: o, Fills: fills, Timestamp: at}
+}
+
+func TestCancelReplaceAndRejectCounts(t *testing.T) {
+	tracker := NewTracker(DefaultConfig())
+	now := time.Now()
+
+	first := order(alice, types.OrderSideBuy, "1", now.Add(-3*time.Second))
+	tracker.Observe(event(matching.EventOrderAdded, first, first.CreatedAt))
+
+	// 撤单后立即同向重下计为改单
+	first.Status = types.OrderStatusCancelled
+	tracker.Observe(event(matching.EventOrderCancelled, first, now.Add(-2*time.Second)))
+	second := order(alice, types.OrderSideBuy, "1", now.Add(-2*time.Second+200*time.Millisecond))
+	tracker.Observe(event(matching.EventOrderAdded, second, second.CreatedAt))
+
+	// 系统撤单不计入撤单数，但订单终结
+	second.Status = types.OrderStatusCancelled
+	second.StatusReason = types.StatusReasonPairDelisted
+	tracker.Observe(event(matching.EventOrderCancelled, second, now.Add(-time.Second)))
+
+	rejected := order(alice, types.OrderSideSell, "1", now)
+	rejected.Status = types.OrderStatusRejected
+	tracker.Observe(event(matching.EventOrderRejected, rejected, now))
+
+	flow := tracker.User(alice)
+	require.Len(t, flow.Windows, 3)
+	stats := flow.Windows[0]
+	assert.Equal(t, "1m0s", stats.Window)
+	assert.Equal(t, 2, stats.Orders)
+	assert.Equal(t, 1, stats.Cancels)
+	assert.Equal(t, 1, stats.Replaces)
+	assert.Equal(t, 1, stats.Rejects)
+	assert.Equal(t, 2, stats.ClosedOrders)
+	assert.True(t, stats.CancelRatio.Equal(decimal.RequireFromString("0.5")))
+	assert.True(t, stats.FillRatio.IsZero())
+	assert.Equal(t, int64(900), stats.AvgLifetimeMs)
+	assert.Zero(t, flow.OpenOrders)
+
+	placed, cancelled := tracker.CancelCounts(alice, time.Hour)
+	assert.Equal(t, 2, placed)
+	assert.Equal(t, 1, cancelled)
+
+	top := tracker.Top(time.Hour, 10)
+	require.Len(t, top, 1)
+	assert.Equal(t, "0xaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa", top[0].UserAddress)
+}
+
+func TestFillRatioAndLifetime(t *testing.T) {
+	tracker := NewTracker(DefaultConfig())
+	now := time.Now()
+
+	maker := order(alice, types.OrderSideSell, "2", now.Add(-5*time.Second))
+	tracker.Observe(event(matching.EventOrderAdded, maker, maker.CreatedAt))
+
+	// 部分成交后 maker 仍在挂单
+	taker := order(bob, types.OrderSideBuy, "1", now.Add(-4*time.Second))
+	taker.Status = types.OrderStatusFilled
+	fill := &types.Fill{TakerOrderID: taker.ID, MakerOrderID: maker.ID, TakerUserAddress: bob, MakerUserAddress: alice, Amount: decimal.NewFromInt(1)}
+	tracker.Observe(event(matching.EventOrderAdded, taker, taker.CreatedAt, fill))
+	assert.Equal(t, 1, tracker.User(alice).OpenOrders)
+
+	// 剩余部分成交后 maker 终结
+	taker = order(bob, types.OrderSideBuy, "1", now.Add(-time.Second))
+	taker.Status = types.OrderStatusFilled
+	fill = &types.Fill{TakerOrderID: taker.ID, MakerOrderID: maker.ID, TakerUserAddress: bob, MakerUserAddress: alice, Amount: decimal.NewFromInt(1)}
+	tracker.Observe(event(matching.EventOrderAdded, taker, taker.CreatedAt, fill))
+
+	stats := tracker.User(alice).Windows[0]
+	assert.Equal(t, 2, stats.Fills)
+	assert.Equal(t, 1, stats.ClosedOrders)
+	assert.True(t, stats.FillRatio.Equal(decimal.NewFromInt(1)))
+	assert.Equal(t, int64(4000), stats.AvgLifetimeMs)
+
+	// 立即成交的 taker 计入成交比例，不计入挂单存续时间
+	stats = tracker.User(bob).Windows[0]
+	assert.Equal(t, 2, stats.Orders)
+	assert.Equal(t, 2, stats.ClosedOrders)
+	assert.True(t, stats.FillRatio.Equal(decimal.NewFromInt(1)))
+	assert.Zero(t, stats.AvgLifetimeMs)
+	assert.Zero(t, tracker.User(bob).OpenOrders)
+}
+
+func TestWindowsExcludeOldBuckets(t *testing.T) {
+	tracker := NewTracker(DefaultConfig())
+	now := time.Now()
+
+	old := order(alice, types.OrderSideBuy, "1", now.Add(-10*time.Minute))
+	tracker.Observe(event(matching.EventOrderAdded, old, old.CreatedAt))
+	recent := order(alice, types.OrderSideBuy, "1", now)
+	tracker.Observe(event(matching.EventOrderAdded, recent, now))
+
+	windows := tracker.User(alice).Windows
+	assert.Equal(t, 1, windows[0].Orders)
+	assert.Equal(t, 2, windows[1].Orders)
+
+	// 超过保留期的窗口按保留期统计
+	placed, _ := tracker.CancelCounts(alice, 24*time.Hour)
+	assert.Equal(t, 2, placed)
+
+	assert.Error(t, Config{Windows: []time.Duration{time.Second}, BucketSize: time.Minute}.Validate())
+}
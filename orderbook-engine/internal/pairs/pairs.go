@@ -0,0 +1,192 @@
+// Package pairs 交易对规范命名
+// 同一对代币在各下单入口（REST、链上事件、轮询）统一映射为一个交易对名称，避免同一市场分裂成多个订单簿：
+//   - 已上线的交易对按登记的基础、计价代币地址匹配，名称取登记名称
+//   - 未上线的代币对以代币符号命名（未知代币使用地址），基础/计价顺序按计价代币优先级决定：
+//     优先级列表中靠前的代币为计价代币，两者都不在列表中时地址较小者为基础代币
+//
+// 订单的代币顺序与规范顺序相反时标记为反向：价格与数量的含义随之反转，且签名覆盖代币顺序，
+// 无法在不改变订单哈希的情况下换算，由调用方拒绝
+package pairs
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"orderbook-engine/internal/listing"
+	"orderbook-engine/internal/tokens"
+)
+
+// DefaultQuotePriority 默认计价代币优先级
+var DefaultQuotePriority = []string{"USDC", "USDT", "DAI", "WETH", "WBTC"}
+
+// Listings 已上线交易对来源（交易对登记表）
+type Listings interface {
+	Pairs() []*listing.Pair
+}
+
+// Resolution 代币对的规范交易对
+type Resolution struct {
+	TradingPair string `json:"trading_pair"`
+	BaseToken   string `json:"base_token"`
+	QuoteToken  string `json:"quote_token"`
+	Reversed    bool   `json:"reversed"` // 输入的代币顺序与规范顺序相反
+	Listed      bool   `json:"listed"`   // 名称与顺序来自已上线的交易对
+	Known       bool   `json:"known"`    // 已上线或两个代币的符号均已知
+}
+
+// Canonicalizer 交易对规范命名
+type Canonicalizer struct {
+	tokens   *tokens.Registry
+	listings Listings       // 可以为空
+	quotes   map[string]int // 代币符号 -> 计价优先级（越小越优先）
+}
+
+// NewCanonicalizer 创建规范命名，listings 可以为空，quotePriority 为空时使用 DefaultQuotePriority
+func NewCanonicalizer(tokenRegistry *tokens.Registry, listings Listings, quotePriority []string) *Canonicalizer {
+	if len(quotePriority) == 0 {
+		quotePriority = DefaultQuotePriority
+	}
+	quotes := make(map[string]int, len(quotePriority))
+	for i, symbol := range quotePriority {
+		symbol = strings.ToUpper(symbol)
+		if _, exists := quotes[symbol]; !exists {
+			quotes[symbol] = i
+		}
+	}
+	return &Canonicalizer{tokens: tokenRegistry, listings: listings, quotes: quotes}
+}
+
+// Resolve 获取代币对的规范交易对，tokenA、tokenB 为订单中的基础、计价代币地址
+func (c *Canonicalizer) Resolve(chainID uint64, tokenA, tokenB string) Resolution {
+	if pair, reversed, ok := c.listed(chainID, tokenA, tokenB); ok {
+		return Resolution{
+			TradingPair: pair.TradingPair,
+			BaseToken:   pair.BaseToken.Address,
+			QuoteToken:  pair.QuoteToken.Address,
+			Reversed:    reversed,
+			Listed:      true,
+			Known:       true,
+		}
+	}
+
+	base, quote := c.token(chainID, tokenA), c.token(chainID, tokenB)
+	reversed := c.quoteFirst(base, quote)
+	if reversed {
+		base, quote = quote, base
+	}
+	return Resolution{
+		TradingPair: base.symbol + "-" + quote.symbol,
+		BaseToken:   base.address,
+		QuoteToken:  quote.address,
+		Reversed:    reversed,
+		Known:       base.known && quote.known,
+	}
+}
+
+// Normalize 规范交易对名称的写法：符号转为大写，地址形式的代币替换为符号（如 "0xc02a…-0xa0b8…" 转为 "WETH-USDC"）
+// 只改写法，不调整基础/计价顺序；无法解析的部分原样保留
+func (c *Canonicalizer) Normalize(chainID uint64, tradingPair string) string {
+	parts := strings.Split(strings.TrimSpace(tradingPair), "-")
+	if len(parts) != 2 {
+		return strings.ToUpper(tradingPair)
+	}
+	if common.IsHexAddress(parts[0]) && common.IsHexAddress(parts[1]) {
+		if pair, reversed, ok := c.listed(chainID, parts[0], parts[1]); ok && !reversed {
+			return pair.TradingPair
+		}
+	}
+	for i, part := range parts {
+		parts[i] = c.token(chainID, part).symbol
+	}
+	return parts[0] + "-" + parts[1]
+}
+
+// listed 按代币地址查找已上线（未下架）的交易对，reversed 表示输入顺序与登记顺序相反
+func (c *Canonicalizer) listed(chainID uint64, tokenA, tokenB string) (*listing.Pair, bool, bool) {
+	if c.listings == nil || !common.IsHexAddress(tokenA) || !common.IsHexAddress(tokenB) {
+		return nil, false, false
+	}
+	a, b := common.HexToAddress(tokenA), common.HexToAddress(tokenB)
+	for _, pair := range c.listings.Pairs() {
+		if pair.ChainID != chainID || pair.DelistedAt != nil {
+			continue
+		}
+		base, quote := common.HexToAddress(pair.BaseToken.Address), common.HexToAddress(pair.QuoteToken.Address)
+		if base == a && quote == b {
+			return pair, false, true
+		}
+		if base == b && quote == a {
+			return pair, true, true
+		}
+	}
+	return nil, false, false
+}
+
+// tokenName 代币的规范符号与地址
+type tokenName struct {
+	symbol  string
+	address string
+	known   bool
+}
+
+// token 解析代币符号：地址通过代币注册表查询（未知时使用校验和地址），符号转为大写
+func (c *Canonicalizer) token(chainID uint64, value string) tokenName {
+	value = strings.TrimSpace(value)
+	if !common.IsHexAddress(value) {
+		return tokenName{symbol: strings.ToUpper(value), known: value != ""}
+	}
+	address := common.HexToAddress(value).Hex()
+	if c.tokens != nil {
+		if token, err := c.tokens.Lookup(chainID, address); err == nil {
+			return tokenName{symbol: token.Symbol, address: address, known: true}
+		}
+	}
+	return tokenName{symbol: address, address: address}
+}
+
+// quoteFirst 第一个代币是否应作为计价代币（即输入顺序与规范顺序相反）
+func (c *Canonicalizer) quoteFirst(first, second tokenName) bool {
+	firstRank, firstQuote := c.quotes[first.symbol]
+	secondRank, secondQuote := c.quotes[second.symbol]
+	switch {
+	case firstQuote && secondQuote:
+		return firstRank < secondRank
+	case firstQuote != secondQuote:
+		return firstQuote
+	case first.address != "" && second.address != "":
+		return strings.ToLower(first.address) > strings.ToLower(second.address)
+	default:
+		return first.symbol > second.symbol
+	}
+}
+
+// MismatchError REST 订单声明的交易对与其代币的规范交易对不一致
+type MismatchError struct {
+	Requested  string
+	Resolution Resolution
+}
+
+func (e *MismatchError) Error() string {
+	if e.Resolution.Reversed {
+		return fmt.Sprintf("tokens are in reverse order for %s: base must be %s and quote %s",
+			e.Resolution.TradingPair, e.Resolution.BaseToken, e.Resolution.QuoteToken)
+	}
+	return fmt.Sprintf("trading pair %s does not match the order's tokens, expected %s", e.Requested, e.Resolution.TradingPair)
+}
+
+// Check 校验订单声明的交易对与代币：返回规范交易对名称
+// 代币顺序相反，或已知代币的规范名称与声明名称不一致时返回 *MismatchError；
+// 代币符号未知且未上线时无法判断，返回规范写法的声明名称
+func (c *Canonicalizer) Check(chainID uint64, tradingPair, baseToken, quoteToken string) (string, error) {
+	requested := c.Normalize(chainID, tradingPair)
+	resolution := c.Resolve(chainID, baseToken, quoteToken)
+	if !resolution.Known {
+		return requested, nil
+	}
+	if resolution.Reversed || resolution.TradingPair != requested {
+		return "", &MismatchError{Requested: tradingPair, Resolution: resolution}
+	}
+	return resolution.TradingPair, nil
+}
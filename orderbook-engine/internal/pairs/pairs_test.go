@@ -0,0 +1,109 @@
+package pairs
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"orderbook-engine/internal/listing"
+	"orderbook-engine/internal/tokens"
+)
+
+const (
+	weth = "0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2"
+	usdc = "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"
+	arb  = "0x1111111111111111111111111111111111111111"
+	gmx  = "0x2222222222222222222222222222222222222222"
+	xyz  = "0x3333333333333333333333333333333333333333" // 未登记代币
+)
+
+type staticListings []*listing.Pair
+
+func (l staticListings) Pairs() []*listing.Pair { return l }
+
+func newCanonicalizer(t *testing.T) *Canonicalizer {
+	registry := tokens.NewRegistry()
+	for _, token := range []tokens.Token{
+		{ChainID: 1, Address: weth, Symbol: "weth", Decimals: 18},
+		{ChainID: 1, Address: usdc, Symbol: "USDC", Decimals: 6},
+		{ChainID: 1, Address: arb, Symbol: "ARB", Decimals: 18},
+		{ChainID: 1, Address: gmx, Symbol: "GMX", Decimals: 18},
+	} {
+		require.NoError(t, registry.Register(token))
+	}
+
+	// 登记的交易对以 GMX 计价，覆盖默认的按地址排序
+	listings := staticListings{{
+		TradingPair: "ARB-GMX",
+		ChainID:     1,
+		BaseToken:   tokens.Token{Address: arb, Symbol: "ARB"},
+		QuoteToken:  tokens.Token{Address: gmx, Symbol: "GMX"},
+	}}
+	return NewCanonicalizer(registry, listings, nil)
+}
+
+func TestResolveOrdering(t *testing.T) {
+	c := newCanonicalizer(t)
+
+	// 计价优先级决定顺序，反向输入映射到同一名称
+	resolution := c.Resolve(1, weth, usdc)
+	assert.Equal(t, "WETH-USDC", resolution.TradingPair)
+	assert.False(t, resolution.Reversed)
+	assert.True(t, resolution.Known)
+	assert.False(t, resolution.Listed)
+
+	resolution = c.Resolve(1, usdc, weth)
+	assert.Equal(t, "WETH-USDC", resolution.TradingPair)
+	assert.True(t, resolution.Reversed)
+	assert.Equal(t, weth, resolution.BaseToken)
+
+	// 已上线交易对按登记顺序
+	resolution = c.Resolve(1, gmx, arb)
+	assert.Equal(t, "ARB-GMX", resolution.TradingPair)
+	assert.True(t, resolution.Reversed)
+	assert.True(t, resolution.Listed)
+
+	// 其他链上的同地址代币未登记，按地址命名并按地址排序
+	resolution = c.Resolve(2, gmx, arb)
+	assert.Equal(t, arb+"-"+gmx, resolution.TradingPair)
+	assert.True(t, resolution.Reversed)
+	assert.False(t, resolution.Known)
+
+	// 未知代币使用地址，与计价代币组合时计价代币仍在后
+	resolution = c.Resolve(1, usdc, xyz)
+	assert.Equal(t, xyz+"-USDC", resolution.TradingPair)
+	assert.True(t, resolution.Reversed)
+	assert.False(t, resolution.Known)
+}
+
+func TestNormalizeAndCheck(t *testing.T) {
+	c := newCanonicalizer(t)
+
+	assert.Equal(t, "WETH-USDC", c.Normalize(1, "weth-usdc"))
+	assert.Equal(t, "WETH-USDC", c.Normalize(1, weth+"-"+usdc))
+	assert.Equal(t, "ARB-GMX", c.Normalize(1, "0x1111111111111111111111111111111111111111-0x2222222222222222222222222222222222222222"))
+	// 只改写法，不调整顺序
+	assert.Equal(t, "USDC-WETH", c.Normalize(1, "usdc-weth"))
+
+	pair, err := c.Check(1, "weth-usdc", weth, usdc)
+	require.NoError(t, err)
+	assert.Equal(t, "WETH-USDC", pair)
+
+	// 名称与代币不一致
+	_, err = c.Check(1, "ARB-USDC", weth, usdc)
+	var mismatch *MismatchError
+	require.True(t, errors.As(err, &mismatch))
+	assert.Equal(t, "WETH-USDC", mismatch.Resolution.TradingPair)
+
+	// 代币顺序相反
+	_, err = c.Check(1, "USDC-WETH", usdc, weth)
+	require.True(t, errors.As(err, &mismatch))
+	assert.True(t, mismatch.Resolution.Reversed)
+
+	// 未知代币无法判断，保留规范写法的声明名称
+	pair, err = c.Check(1, "abc-usdc", xyz, usdc)
+	require.NoError(t, err)
+	assert.Equal(t, "ABC-USDC", pair)
+}
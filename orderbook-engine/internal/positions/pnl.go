@@ -0,0 +1,147 @@
+package positions
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"orderbook-engine/internal/types"
+)
+
+// QuoteSource 最优买卖价来源（由撮合引擎实现），用于按中间价计算浮动盈亏
+type QuoteSource interface {
+	GetBBO(tradingPair string) *types.BBO
+}
+
+// PairPnL 用户单一交易对的盈亏（报价代币计）
+// 持仓成本按加权平均计算：加仓更新均价，减仓按均价结转已实现盈亏，反向开仓后以成交价为新均价
+type PairPnL struct {
+	TradingPair   string          `json:"trading_pair"`
+	Position      decimal.Decimal `json:"position"`     // 基础代币净持仓，净卖出为负
+	AverageCost   decimal.Decimal `json:"average_cost"` // 持仓均价，无持仓时为零
+	MarkPrice     decimal.Decimal `json:"mark_price"`   // 中间价，单边盘口时为最近成交价
+	RealizedPnL   decimal.Decimal `json:"realized_pnl"`
+	UnrealizedPnL decimal.Decimal `json:"unrealized_pnl"` // (标记价格 - 均价) × 持仓
+	TotalPnL      decimal.Decimal `json:"total_pnl"`
+}
+
+// PnLSummary 用户全部交易对的盈亏，合计值按各交易对报价代币面值直接相加
+type PnLSummary struct {
+	UserAddress   string          `json:"user_address"`
+	Pairs         []*PairPnL      `json:"pairs"`
+	RealizedPnL   decimal.Decimal `json:"realized_pnl"`
+	UnrealizedPnL decimal.Decimal `json:"unrealized_pnl"`
+	TotalPnL      decimal.Decimal `json:"total_pnl"`
+	Timestamp     time.Time       `json:"timestamp"`
+}
+
+// costBasis 交易对持仓成本（由 Tracker 锁保护）
+type costBasis struct {
+	position    decimal.Decimal
+	averageCost decimal.Decimal
+	realized    decimal.Decimal
+}
+
+// SetQuoteSource 设置中间价来源，为空时按最近成交价计算浮动盈亏
+func (t *Tracker) SetQuoteSource(source QuoteSource) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.quotes = source
+}
+
+// applyCost 按成交更新用户交易对的持仓均价与已实现盈亏，delta 买入为正（调用方持有写锁）
+func (t *Tracker) applyCost(userAddress, tradingPair string, delta, price decimal.Decimal) {
+	if userAddress == "" || delta.IsZero() {
+		return
+	}
+	user := strings.ToLower(userAddress)
+	pairs, exists := t.costs[user]
+	if !exists {
+		pairs = make(map[string]*costBasis)
+		t.costs[user] = pairs
+	}
+	basis, exists := pairs[tradingPair]
+	if !exists {
+		basis = &costBasis{}
+		pairs[tradingPair] = basis
+	}
+
+	position := basis.position
+	if position.IsZero() || position.Sign() == delta.Sign() {
+		// 开仓或加仓：加权平均
+		total := position.Abs().Add(delta.Abs())
+		basis.averageCost = position.Abs().Mul(basis.averageCost).Add(delta.Abs().Mul(price)).Div(total)
+		basis.position = position.Add(delta)
+		return
+	}
+
+	// 减仓：平掉的部分按均价结转盈亏，多头盈亏为 (价格 - 均价)，空头相反
+	closed := decimal.Min(position.Abs(), delta.Abs())
+	pnl := price.Sub(basis.averageCost).Mul(closed)
+	if position.IsNegative() {
+		pnl = pnl.Neg()
+	}
+	basis.realized = basis.realized.Add(pnl)
+	basis.position = position.Add(delta)
+	switch {
+	case basis.position.IsZero():
+		basis.averageCost = decimal.Zero
+	case basis.position.Sign() != position.Sign():
+		// 反向开仓的部分以成交价为成本
+		basis.averageCost = price
+	}
+}
+
+// PnL 获取用户各交易对的已实现与浮动盈亏，按交易对排序
+func (t *Tracker) PnL(userAddress string) *PnLSummary {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.pnlLocked(userAddress, time.Now())
+}
+
+// pnlLocked 计算用户盈亏（调用方持有锁）
+func (t *Tracker) pnlLocked(userAddress string, now time.Time) *PnLSummary {
+	summary := &PnLSummary{
+		UserAddress:   userAddress,
+		Pairs:         make([]*PairPnL, 0),
+		RealizedPnL:   decimal.Zero,
+		UnrealizedPnL: decimal.Zero,
+		TotalPnL:      decimal.Zero,
+		Timestamp:     now,
+	}
+	for tradingPair, basis := range t.costs[strings.ToLower(userAddress)] {
+		pnl := &PairPnL{
+			TradingPair:   tradingPair,
+			Position:      basis.position,
+			AverageCost:   basis.averageCost,
+			MarkPrice:     t.midPrice(tradingPair),
+			RealizedPnL:   basis.realized,
+			UnrealizedPnL: decimal.Zero,
+		}
+		if !basis.position.IsZero() && pnl.MarkPrice.IsPositive() {
+			pnl.UnrealizedPnL = pnl.MarkPrice.Sub(basis.averageCost).Mul(basis.position)
+		}
+		pnl.TotalPnL = pnl.RealizedPnL.Add(pnl.UnrealizedPnL)
+
+		summary.Pairs = append(summary.Pairs, pnl)
+		summary.RealizedPnL = summary.RealizedPnL.Add(pnl.RealizedPnL)
+		summary.UnrealizedPnL = summary.UnrealizedPnL.Add(pnl.UnrealizedPnL)
+	}
+	summary.TotalPnL = summary.RealizedPnL.Add(summary.UnrealizedPnL)
+	sort.Slice(summary.Pairs, func(i, j int) bool {
+		return summary.Pairs[i].TradingPair < summary.Pairs[j].TradingPair
+	})
+	return summary
+}
+
+// midPrice 交易对中间价，没有双边盘口时使用最近成交价（调用方持有锁）
+func (t *Tracker) midPrice(tradingPair string) decimal.Decimal {
+	if t.quotes != nil {
+		if bbo := t.quotes.GetBBO(tradingPair); bbo != nil && bbo.MidPrice != nil && bbo.MidPrice.IsPositive() {
+			return *bbo.MidPrice
+		}
+	}
+	return t.lastPrices[tradingPair]
+}
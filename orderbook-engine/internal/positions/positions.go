@@ -0,0 +1,180 @@
+// Package positions 用户持仓与风险暴露
+// 按成交轧差得到每个用户每个代币的净持仓（买入基础代币为正、支付的报价代币为负），
+// 基础代币持仓按交易对标记价格折算为报价代币计价的名义金额，全部基础代币名义金额之和即账户风险暴露。
+// 持仓自引擎启动后的成交开始累计，报价代币视为现金不计入风险暴露
+package positions
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"orderbook-engine/internal/oracle"
+	"orderbook-engine/internal/types"
+)
+
+// Position 用户单一代币的净持仓
+type Position struct {
+	Token       string          `json:"token"`
+	TradingPair string          `json:"trading_pair,omitempty"` // 标记价格使用的交易对，报价代币为空
+	Net         decimal.Decimal `json:"net"`                    // 净买入为正，净卖出为负
+	MarkPrice   decimal.Decimal `json:"mark_price"`             // 标记价格（报价代币），报价代币为零
+	Notional    decimal.Decimal `json:"notional"`               // |Net| × 标记价格
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
+
+// Summary 用户持仓与风险暴露
+type Summary struct {
+	UserAddress string          `json:"user_address"`
+	Positions   []*Position     `json:"positions"`
+	Exposure    decimal.Decimal `json:"exposure"` // 基础代币持仓名义金额之和
+	Timestamp   time.Time       `json:"timestamp"`
+}
+
+// holding 净持仓（由 Tracker 锁保护）
+type holding struct {
+	token     string
+	base      bool // 作为基础代币成交过，计入风险暴露
+	net       decimal.Decimal
+	updatedAt time.Time
+}
+
+// Tracker 持仓跟踪
+type Tracker struct {
+	mu         sync.RWMutex
+	holdings   map[string]map[string]*holding   // 小写用户地址 -> 小写代币地址 -> 持仓
+	markPairs  map[string]string                // 小写基础代币地址 -> 最近成交的交易对
+	lastPrices map[string]decimal.Decimal       // 交易对 -> 最近成交价
+	oracle     oracle.PriceOracle               // 可选，标记价格来源，不可用时使用最近成交价
+	costs      map[string]map[string]*costBasis // 小写用户地址 -> 交易对 -> 持仓成本
+	quotes     QuoteSource                      // 可选，盈亏标记使用的中间价来源
+}
+
+// NewTracker 创建持仓跟踪，priceOracle 为空时按最近成交价标记
+func NewTracker(priceOracle oracle.PriceOracle) *Tracker {
+	return &Tracker{
+		holdings:   make(map[string]map[string]*holding),
+		markPairs:  make(map[string]string),
+		lastPrices: make(map[string]decimal.Decimal),
+		oracle:     priceOracle,
+		costs:      make(map[string]map[string]*costBasis),
+	}
+}
+
+// ApplyFill 按成交更新买卖双方的持仓，order 为成交所属的订单（提供交易对的代币地址）
+func (t *Tracker) ApplyFill(order *types.Order, fill *types.Fill) {
+	if order.BaseToken == "" || order.QuoteToken == "" {
+		return
+	}
+	base, quote := strings.ToLower(order.BaseToken), strings.ToLower(order.QuoteToken)
+	quoteAmount := fill.Price.Mul(fill.Amount)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.markPairs[base] = fill.TradingPair
+	t.lastPrices[fill.TradingPair] = fill.Price
+
+	buyer, seller := fill.TakerUserAddress, fill.MakerUserAddress
+	if fill.TakerSide == types.OrderSideSell {
+		buyer, seller = seller, buyer
+	}
+	t.adjust(buyer, base, true, fill.Amount, fill.CreatedAt)
+	t.adjust(buyer, quote, false, quoteAmount.Neg(), fill.CreatedAt)
+	t.adjust(seller, base, true, fill.Amount.Neg(), fill.CreatedAt)
+	t.adjust(seller, quote, false, quoteAmount, fill.CreatedAt)
+	t.applyCost(buyer, fill.TradingPair, fill.Amount, fill.Price)
+	t.applyCost(seller, fill.TradingPair, fill.Amount.Neg(), fill.Price)
+}
+
+// adjust 调整用户单一代币的净持仓（调用方持有写锁）
+func (t *Tracker) adjust(userAddress, token string, base bool, delta decimal.Decimal, at time.Time) {
+	if userAddress == "" {
+		return
+	}
+	user := strings.ToLower(userAddress)
+	tokens, exists := t.holdings[user]
+	if !exists {
+		tokens = make(map[string]*holding)
+		t.holdings[user] = tokens
+	}
+	h, exists := tokens[token]
+	if !exists {
+		h = &holding{token: token}
+		tokens[token] = h
+	}
+	h.base = h.base || base
+	h.net = h.net.Add(delta)
+	if at.IsZero() {
+		at = time.Now()
+	}
+	h.updatedAt = at
+}
+
+// Positions 获取用户持仓与风险暴露，持仓按代币地址排序
+func (t *Tracker) Positions(userAddress string) *Summary {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	summary := &Summary{
+		UserAddress: userAddress,
+		Positions:   make([]*Position, 0),
+		Exposure:    decimal.Zero,
+		Timestamp:   time.Now(),
+	}
+	for _, h := range t.holdings[strings.ToLower(userAddress)] {
+		position := &Position{Token: h.token, Net: h.net, UpdatedAt: h.updatedAt}
+		if h.base {
+			position.TradingPair = t.markPairs[h.token]
+			position.MarkPrice = t.markPrice(position.TradingPair)
+			position.Notional = h.net.Abs().Mul(position.MarkPrice)
+			summary.Exposure = summary.Exposure.Add(position.Notional)
+		}
+		summary.Positions = append(summary.Positions, position)
+	}
+	sort.Slice(summary.Positions, func(i, j int) bool {
+		return summary.Positions[i].Token < summary.Positions[j].Token
+	})
+	return summary
+}
+
+// Exposure 用户当前风险暴露
+func (t *Tracker) Exposure(userAddress string) decimal.Decimal {
+	return t.Positions(userAddress).Exposure
+}
+
+// ProjectedExposure 订单全部成交后的风险暴露，订单代币按 price 标记
+// 减少持仓的订单使风险暴露下降
+func (t *Tracker) ProjectedExposure(order *types.Order, price decimal.Decimal) decimal.Decimal {
+	summary := t.Positions(order.UserAddress)
+	base := strings.ToLower(order.BaseToken)
+
+	net := decimal.Zero
+	exposure := summary.Exposure
+	for _, position := range summary.Positions {
+		if position.Token == base {
+			net = position.Net
+			exposure = exposure.Sub(position.Notional)
+			break
+		}
+	}
+
+	amount := order.GetRemainingAmount()
+	if order.Side == types.OrderSideSell {
+		amount = amount.Neg()
+	}
+	return exposure.Add(net.Add(amount).Abs().Mul(price))
+}
+
+// markPrice 交易对标记价格：优先使用价格预言机，不可用时使用最近成交价（调用方持有锁）
+func (t *Tracker) markPrice(tradingPair string) decimal.Decimal {
+	if t.oracle != nil {
+		if quote, err := t.oracle.GetPrice(tradingPair); err == nil {
+			return quote.Price
+		}
+	}
+	return t.lastPrices[tradingPair]
+}
@@ -0,0 +1,155 @@
+package positions
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"orderbook-engine/internal/types"
+)
+
+const (
+	alice = "0x1111111111111111111111111111111111111111"
+	bob   = "0x2222222222222222222222222222222222222222"
+	weth  = "0xc02aaa39b223fe8d0a0e5c4f27ead9083c756cc2"
+	usdc  = "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48"
+)
+
+func order(user string, side types.OrderSide, amount string) *types.Order {
+	return &types.Order{
+		UserAddress: user,
+		TradingPair: "WETH-USDC",
+		BaseToken:   weth,
+		QuoteToken:  usdc,
+		Side:        side,
+		Amount:      decimal.RequireFromString(amount),
+	}
+}
+
+func fill(taker, maker string, takerSide types.OrderSide, price, amount string) *types.Fill {
+	return &types.Fill{
+		TakerUserAddress: taker,
+		MakerUserAddress: maker,
+		TradingPair:      "WETH-USDC",
+		TakerSide:        takerSide,
+		Price:            decimal.RequireFromString(price),
+		Amount:           decimal.RequireFromString(amount),
+		CreatedAt:        time.Now(),
+	}
+}
+
+func position(t *testing.T, summary *Summary, token string) *Position {
+	for _, p := range summary.Positions {
+		if p.Token == token {
+			return p
+		}
+	}
+	t.Fatalf("no position in %s", token)
+	return nil
+}
+
+func TestFillsNetIntoPositions(t *testing.T) {
+	tracker := NewTracker(nil)
+	tracker.ApplyFill(order(alice, types.OrderSideBuy, "3"), fill(alice, bob, types.OrderSideBuy, "2000", "2"))
+	tracker.ApplyFill(order(bob, types.OrderSideBuy, "1"), fill(bob, alice, types.OrderSideBuy, "2100", "0.5"))
+
+	summary := tracker.Positions(alice)
+	require.Len(t, summary.Positions, 2)
+	base := position(t, summary, weth)
+	assert.True(t, base.Net.Equal(decimal.RequireFromString("1.5")))
+	assert.Equal(t, "WETH-USDC", base.TradingPair)
+	assert.True(t, base.MarkPrice.Equal(decimal.NewFromInt(2100)))
+	assert.True(t, base.Notional.Equal(decimal.NewFromInt(3150)))
+	quote := position(t, summary, usdc)
+	assert.True(t, quote.Net.Equal(decimal.NewFromInt(-2950)))
+	assert.True(t, quote.Notional.IsZero())
+	assert.True(t, summary.Exposure.Equal(decimal.NewFromInt(3150)))
+
+	// 卖方持仓为负，风险暴露按绝对值计
+	assert.True(t, tracker.Exposure(bob).Equal(decimal.NewFromInt(3150)))
+	assert.Empty(t, tracker.Positions("0x3333333333333333333333333333333333333333").Positions)
+}
+
+func TestProjectedExposure(t *testing.T) {
+	tracker := NewTracker(nil)
+	tracker.ApplyFill(order(alice, types.OrderSideBuy, "2"), fill(alice, bob, types.OrderSideBuy, "2000", "2"))
+
+	price := decimal.NewFromInt(2000)
+	assert.True(t, tracker.ProjectedExposure(order(alice, types.OrderSideBuy, "1"), price).Equal(decimal.NewFromInt(6000)))
+	// 减仓订单降低风险暴露，反向超过持仓后按新的净持仓计
+	assert.True(t, tracker.ProjectedExposure(order(alice, types.OrderSideSell, "1.5"), price).Equal(decimal.NewFromInt(1000)))
+	assert.True(t, tracker.ProjectedExposure(order(alice, types.OrderSideSell, "5"), price).Equal(decimal.NewFromInt(6000)))
+	// 没有持仓时为订单本身的名义金额
+	assert.True(t, tracker.ProjectedExposure(order("0x3333333333333333333333333333333333333333", types.OrderSideSell, "1"), price).Equal(price))
+}
+
+type fixedQuotes map[string]decimal.Decimal
+
+func (q fixedQuotes) GetBBO(tradingPair string) *types.BBO {
+	bbo := &types.BBO{TradingPair: tradingPair}
+	if mid, ok := q[tradingPair]; ok {
+		bbo.MidPrice = &mid
+	}
+	return bbo
+}
+
+func TestAverageCostPnL(t *testing.T) {
+	tracker := NewTracker(nil)
+	tracker.SetQuoteSource(fixedQuotes{"WETH-USDC": decimal.NewFromInt(2300)})
+
+	// 2000 买 1、2200 买 1：均价 2100；2400 卖 1.5：已实现 450；剩余 0.5 按中间价 2300 浮盈 100
+	tracker.ApplyFill(order(alice, types.OrderSideBuy, "1"), fill(alice, bob, types.OrderSideBuy, "2000", "1"))
+	tracker.ApplyFill(order(alice, types.OrderSideBuy, "1"), fill(alice, bob, types.OrderSideBuy, "2200", "1"))
+	tracker.ApplyFill(order(bob, types.OrderSideBuy, "1.5"), fill(bob, alice, types.OrderSideBuy, "2400", "1.5"))
+
+	summary := tracker.PnL(alice)
+	require.Len(t, summary.Pairs, 1)
+	pair := summary.Pairs[0]
+	assert.True(t, pair.Position.Equal(decimal.RequireFromString("0.5")))
+	assert.True(t, pair.AverageCost.Equal(decimal.NewFromInt(2100)))
+	assert.True(t, pair.RealizedPnL.Equal(decimal.NewFromInt(450)))
+	assert.True(t, pair.UnrealizedPnL.Equal(decimal.NewFromInt(100)))
+	assert.True(t, summary.TotalPnL.Equal(decimal.NewFromInt(550)))
+
+	// 对手方空头：2000、2200 卖出均价 2100，2400 买回 1.5 亏 450，剩余 -0.5 按 2300 浮亏 100
+	short := tracker.PnL(bob).Pairs[0]
+	assert.True(t, short.RealizedPnL.Equal(decimal.NewFromInt(-450)))
+	assert.True(t, short.UnrealizedPnL.Equal(decimal.NewFromInt(-100)))
+
+	// 反向开仓：卖 1 平掉 0.5 后以成交价为新均价
+	tracker.ApplyFill(order(alice, types.OrderSideSell, "1"), fill(alice, bob, types.OrderSideSell, "2500", "1"))
+	pair = tracker.PnL(alice).Pairs[0]
+	assert.True(t, pair.Position.Equal(decimal.RequireFromString("-0.5")))
+	assert.True(t, pair.AverageCost.Equal(decimal.NewFromInt(2500)))
+	assert.True(t, pair.RealizedPnL.Equal(decimal.NewFromInt(650)))
+}
+
+func TestDailySnapshots(t *testing.T) {
+	store, err := NewFileSnapshotStore(t.TempDir())
+	require.NoError(t, err)
+
+	tracker := NewTracker(nil)
+	tracker.ApplyFill(order(alice, types.OrderSideBuy, "1"), fill(alice, bob, types.OrderSideBuy, "2000", "1"))
+
+	day1 := time.Date(2024, 3, 1, 23, 59, 59, 0, time.UTC)
+	count, err := tracker.TakeSnapshots(store, day1)
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	tracker.ApplyFill(order(bob, types.OrderSideBuy, "1"), fill(bob, alice, types.OrderSideBuy, "2100", "1"))
+	_, err = tracker.TakeSnapshots(store, day1.AddDate(0, 0, 2))
+	require.NoError(t, err)
+
+	history, err := store.History("0x1111111111111111111111111111111111111111", day1.Add(-time.Hour), day1.AddDate(0, 0, 5))
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	assert.Equal(t, "2024-03-01", history[0].Date)
+	assert.Equal(t, "2024-03-03", history[1].Date)
+	assert.True(t, history[0].RealizedPnL.IsZero())
+	assert.True(t, history[1].RealizedPnL.Equal(decimal.NewFromInt(100)))
+	require.Len(t, history[1].Pairs, 1)
+	assert.Equal(t, "WETH-USDC", history[1].Pairs[0].TradingPair)
+}
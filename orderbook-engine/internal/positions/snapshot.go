@@ -0,0 +1,128 @@
+package positions
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// dateLayout 快照日期格式（UTC）
+const dateLayout = "2006-01-02"
+
+// Snapshot 用户某日收盘时的盈亏快照
+type Snapshot struct {
+	Date string `json:"date"` // UTC 日期
+	*PnLSummary
+}
+
+// SnapshotStore 每日盈亏快照存储
+type SnapshotStore interface {
+	// SaveSnapshots 保存某日全部用户的快照，重复保存同一日期时覆盖
+	SaveSnapshots(date string, snapshots []*Snapshot) error
+	// History 返回用户在 [from, to] 日期范围内的快照，按日期排序
+	History(userAddress string, from, to time.Time) ([]*Snapshot, error)
+}
+
+// FileSnapshotStore 本地目录快照存储，每日一个 JSON 文件
+type FileSnapshotStore struct {
+	dir string
+}
+
+// NewFileSnapshotStore 创建本地目录快照存储
+func NewFileSnapshotStore(dir string) (*FileSnapshotStore, error) {
+	if dir == "" {
+		return nil, errors.New("pnl snapshot directory is required")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create pnl snapshot directory: %w", err)
+	}
+	return &FileSnapshotStore{dir: dir}, nil
+}
+
+// SaveSnapshots 写入当日快照文件（先写临时文件再重命名）
+func (s *FileSnapshotStore) SaveSnapshots(date string, snapshots []*Snapshot) error {
+	data, err := json.Marshal(snapshots)
+	if err != nil {
+		return err
+	}
+	path := s.path(date)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write pnl snapshots: %w", err)
+	}
+	return os.Rename(tmp, path)
+}
+
+// History 逐日读取快照文件，缺失的日期跳过
+func (s *FileSnapshotStore) History(userAddress string, from, to time.Time) ([]*Snapshot, error) {
+	user := strings.ToLower(userAddress)
+	result := make([]*Snapshot, 0)
+	for day := from.UTC().Truncate(24 * time.Hour); !day.After(to.UTC()); day = day.AddDate(0, 0, 1) {
+		data, err := os.ReadFile(s.path(day.Format(dateLayout)))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read pnl snapshots: %w", err)
+		}
+
+		var snapshots []*Snapshot
+		if err := json.Unmarshal(data, &snapshots); err != nil {
+			return nil, fmt.Errorf("failed to parse pnl snapshots for %s: %w", day.Format(dateLayout), err)
+		}
+		for _, snapshot := range snapshots {
+			if strings.ToLower(snapshot.UserAddress) == user {
+				result = append(result, snapshot)
+				break
+			}
+		}
+	}
+	return result, nil
+}
+
+// path 日期对应的快照文件
+func (s *FileSnapshotStore) path(date string) string {
+	return filepath.Join(s.dir, "pnl-"+date+".json")
+}
+
+// TakeSnapshots 记录全部有交易对持仓记录的用户在 at 时刻的盈亏，快照日期为 at 的 UTC 日期
+func (t *Tracker) TakeSnapshots(store SnapshotStore, at time.Time) (int, error) {
+	date := at.UTC().Format(dateLayout)
+
+	t.mu.RLock()
+	snapshots := make([]*Snapshot, 0, len(t.costs))
+	for user := range t.costs {
+		snapshots = append(snapshots, &Snapshot{Date: date, PnLSummary: t.pnlLocked(user, at)})
+	}
+	t.mu.RUnlock()
+
+	if err := store.SaveSnapshots(date, snapshots); err != nil {
+		return 0, err
+	}
+	return len(snapshots), nil
+}
+
+// RunDailySnapshots 每个 UTC 日结束时记录当日盈亏快照
+func (t *Tracker) RunDailySnapshots(store SnapshotStore, logger *logrus.Logger) {
+	go func() {
+		for {
+			now := time.Now().UTC()
+			next := now.Truncate(24 * time.Hour).Add(24 * time.Hour)
+			time.Sleep(next.Sub(now))
+
+			// 以前一日最后时刻记账，日期归属刚结束的一天
+			count, err := t.TakeSnapshots(store, next.Add(-time.Nanosecond))
+			if err != nil {
+				logger.WithError(err).Error("Failed to save daily PnL snapshots")
+				continue
+			}
+			logger.WithField("users", count).Info("Daily PnL snapshots saved")
+		}
+	}()
+}
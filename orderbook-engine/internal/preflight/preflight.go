@@ -0,0 +1,198 @@
+// Package preflight 下单前的链上资金预检
+// 订单在链下撮合后由结算合约从用户资金中划转，链上资金不足的订单会在撮合后才结算失败。
+// 预检在接受订单前查询用户在结算合约中的托管余额（deposit 模式），或钱包余额与对结算合约的 ERC-20 授权（allowance 模式），
+// 拒绝单笔即无法在链上结算的订单；多笔挂单合计占用的资金仍由链下余额锁定约束
+package preflight
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+
+	"orderbook-engine/internal/types"
+)
+
+// Mode 预检模式
+type Mode string
+
+const (
+	ModeOff       Mode = "off"       // 不做预检
+	ModeDeposit   Mode = "deposit"   // 检查结算合约中的托管余额（userBalances）
+	ModeAllowance Mode = "allowance" // 检查钱包余额及对结算合约的 ERC-20 授权
+)
+
+// ParseMode 解析预检模式，空字符串视为 off
+func ParseMode(value string) (Mode, error) {
+	switch mode := Mode(strings.ToLower(strings.TrimSpace(value))); mode {
+	case "", ModeOff:
+		return ModeOff, nil
+	case ModeDeposit, ModeAllowance:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unsupported preflight mode %q (off, deposit or allowance)", value)
+	}
+}
+
+// quoteScale 合约按 price * amount / 1e18 计算报价代币数量
+var quoteScale = decimal.New(1, 18)
+
+var (
+	// ErrInsufficientDeposit 结算合约中的托管余额不足
+	ErrInsufficientDeposit = errors.New("insufficient deposited balance in settlement contract")
+	// ErrInsufficientWalletBalance 钱包中的代币余额不足
+	ErrInsufficientWalletBalance = errors.New("insufficient wallet balance")
+	// ErrInsufficientAllowance 对结算合约的 ERC-20 授权额度不足
+	ErrInsufficientAllowance = errors.New("insufficient ERC-20 allowance for settlement contract")
+	// ErrInvalidAddress 用户或代币地址不合法，订单无法在链上结算
+	ErrInvalidAddress = errors.New("invalid address")
+	// ErrUnavailable 链上查询失败或订单所属链没有RPC客户端
+	ErrUnavailable = errors.New("on-chain funds check unavailable")
+)
+
+// Reader 链上资金查询，由 blockchain.Client 实现
+type Reader interface {
+	DepositedBalance(ctx context.Context, user, token common.Address) (*big.Int, error)
+	WalletFunds(ctx context.Context, user, token common.Address) (balance, allowance *big.Int, err error)
+}
+
+// Config 预检配置
+type Config struct {
+	Mode     Mode            // 默认模式
+	Pairs    map[string]Mode // 交易对(大写) -> 模式，覆盖默认模式
+	Timeout  time.Duration   // 单笔订单链上查询超时
+	FailOpen bool            // 链上查询失败时放行订单（仅记录告警）
+}
+
+// Checker 链上资金预检
+type Checker struct {
+	config  Config
+	readers map[uint64]Reader
+	logger  *logrus.Logger
+}
+
+// NewChecker 创建链上资金预检
+func NewChecker(config Config, logger *logrus.Logger) *Checker {
+	pairs := make(map[string]Mode, len(config.Pairs))
+	for pair, mode := range config.Pairs {
+		pairs[strings.ToUpper(pair)] = mode
+	}
+	config.Pairs = pairs
+	if config.Mode == "" {
+		config.Mode = ModeOff
+	}
+	return &Checker{
+		config:  config,
+		readers: make(map[uint64]Reader),
+		logger:  logger,
+	}
+}
+
+// AddChain 登记链的资金查询客户端
+func (c *Checker) AddChain(chainID uint64, reader Reader) {
+	c.readers[chainID] = reader
+}
+
+// Enabled 是否有交易对开启了预检
+func (c *Checker) Enabled() bool {
+	if c.config.Mode != ModeOff {
+		return true
+	}
+	for _, mode := range c.config.Pairs {
+		if mode != ModeOff {
+			return true
+		}
+	}
+	return false
+}
+
+// Mode 交易对的预检模式
+func (c *Checker) Mode(tradingPair string) Mode {
+	if mode, ok := c.config.Pairs[strings.ToUpper(tradingPair)]; ok {
+		return mode
+	}
+	return c.config.Mode
+}
+
+// Check 检查用户的链上资金能否结算该订单，price 为买单折算报价代币使用的价格（市价买单为估算价）
+// 资金不足时返回 ErrInsufficient* 错误；链上查询失败时按 FailOpen 放行或返回 ErrUnavailable
+func (c *Checker) Check(ctx context.Context, order *types.Order, price decimal.Decimal) error {
+	mode := c.Mode(order.TradingPair)
+	if mode == ModeOff {
+		return nil
+	}
+
+	token, required := Requirement(order, price)
+	if !required.IsPositive() {
+		return nil
+	}
+	if !common.IsHexAddress(order.UserAddress) || !common.IsHexAddress(token) {
+		return fmt.Errorf("%w: user %q, token %q", ErrInvalidAddress, order.UserAddress, token)
+	}
+	user, tokenAddress := common.HexToAddress(order.UserAddress), common.HexToAddress(token)
+
+	reader, ok := c.readers[order.ChainID]
+	if !ok {
+		return c.unavailable(order, fmt.Errorf("no RPC client for chain %d", order.ChainID))
+	}
+
+	if c.config.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.config.Timeout)
+		defer cancel()
+	}
+
+	if mode == ModeDeposit {
+		deposited, err := reader.DepositedBalance(ctx, user, tokenAddress)
+		if err != nil {
+			return c.unavailable(order, err)
+		}
+		return shortfall(ErrInsufficientDeposit, token, required, deposited)
+	}
+
+	balance, allowance, err := reader.WalletFunds(ctx, user, tokenAddress)
+	if err != nil {
+		return c.unavailable(order, err)
+	}
+	if err := shortfall(ErrInsufficientWalletBalance, token, required, balance); err != nil {
+		return err
+	}
+	return shortfall(ErrInsufficientAllowance, token, required, allowance)
+}
+
+// Requirement 订单结算需要的代币及数量（代币最小单位）
+// 卖单为基础代币的订单数量，买单为报价代币 price * amount / 1e18（与结算合约的取整一致）
+func Requirement(order *types.Order, price decimal.Decimal) (string, decimal.Decimal) {
+	amount := order.GetRemainingAmount()
+	if order.Side == types.OrderSideSell {
+		return order.BaseToken, amount.Truncate(0)
+	}
+	return order.QuoteToken, price.Truncate(0).Mul(amount.Truncate(0)).Div(quoteScale).Truncate(0)
+}
+
+// shortfall 可用数量不足时返回带明细的错误
+func shortfall(cause error, token string, required decimal.Decimal, available *big.Int) error {
+	if available != nil && !decimal.NewFromBigInt(available, 0).LessThan(required) {
+		return nil
+	}
+	return fmt.Errorf("%w: token %s requires %s, available %s", cause, token, required.String(), available)
+}
+
+// unavailable 链上查询失败，FailOpen 时放行
+func (c *Checker) unavailable(order *types.Order, err error) error {
+	if c.config.FailOpen {
+		c.logger.WithError(err).WithFields(logrus.Fields{
+			"order_id":     order.ID,
+			"user_address": order.UserAddress,
+			"chain_id":     order.ChainID,
+		}).Warn("On-chain funds check failed, accepting order")
+		return nil
+	}
+	return fmt.Errorf("%w: %v", ErrUnavailable, err)
+}
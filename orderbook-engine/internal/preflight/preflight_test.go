@@ -0,0 +1,179 @@
+package preflight
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"orderbook-engine/internal/types"
+)
+
+const (
+	user       = "0x1234567890123456789012345678901234567890"
+	baseToken  = "0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2"
+	quoteToken = "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"
+)
+
+type fakeReader struct {
+	deposits   map[common.Address]*big.Int
+	balances   map[common.Address]*big.Int
+	allowances map[common.Address]*big.Int
+	err        error
+}
+
+func (r *fakeReader) DepositedBalance(ctx context.Context, user, token common.Address) (*big.Int, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	return amountOf(r.deposits, token), nil
+}
+
+func (r *fakeReader) WalletFunds(ctx context.Context, user, token common.Address) (*big.Int, *big.Int, error) {
+	if r.err != nil {
+		return nil, nil, r.err
+	}
+	return amountOf(r.balances, token), amountOf(r.allowances, token), nil
+}
+
+func amountOf(amounts map[common.Address]*big.Int, token common.Address) *big.Int {
+	if amount, ok := amounts[token]; ok {
+		return amount
+	}
+	return big.NewInt(0)
+}
+
+func wei(value string) *big.Int {
+	amount, _ := new(big.Int).SetString(value, 10)
+	return amount
+}
+
+func newOrder(side types.OrderSide, price, amount string) *types.Order {
+	return &types.Order{
+		ID:          uuid.New(),
+		UserAddress: user,
+		TradingPair: "WETH-USDC",
+		ChainID:     1,
+		BaseToken:   baseToken,
+		QuoteToken:  quoteToken,
+		Side:        side,
+		Type:        types.OrderTypeLimit,
+		Price:       decimal.RequireFromString(price),
+		Amount:      decimal.RequireFromString(amount),
+	}
+}
+
+func TestRequirementMatchesSettlementRounding(t *testing.T) {
+	sell := newOrder(types.OrderSideSell, "2000000000", "1500000000000000000")
+	token, amount := Requirement(sell, sell.Price)
+	assert.Equal(t, baseToken, token)
+	assert.Equal(t, "1500000000000000000", amount.String())
+
+	// 2000 USDC(6位) × 1.5 WETH(18位) / 1e18 = 3000 USDC
+	buy := newOrder(types.OrderSideBuy, "2000000000", "1500000000000000000")
+	token, amount = Requirement(buy, buy.Price)
+	assert.Equal(t, quoteToken, token)
+	assert.Equal(t, "3000000000", amount.String())
+
+	// 不足一个最小单位的部分向下取整
+	dust := newOrder(types.OrderSideBuy, "3", "500000000000000000")
+	_, amount = Requirement(dust, dust.Price)
+	assert.Equal(t, "1", amount.String())
+}
+
+func TestDepositMode(t *testing.T) {
+	reader := &fakeReader{deposits: map[common.Address]*big.Int{
+		common.HexToAddress(quoteToken): wei("3000000000"),
+		common.HexToAddress(baseToken):  wei("1000000000000000000"),
+	}}
+	checker := NewChecker(Config{Mode: ModeDeposit}, logrus.New())
+	checker.AddChain(1, reader)
+
+	buy := newOrder(types.OrderSideBuy, "2000000000", "1500000000000000000")
+	require.NoError(t, checker.Check(context.Background(), buy, buy.Price))
+
+	sell := newOrder(types.OrderSideSell, "2000000000", "1500000000000000000")
+	err := checker.Check(context.Background(), sell, sell.Price)
+	assert.ErrorIs(t, err, ErrInsufficientDeposit)
+	assert.Contains(t, err.Error(), "requires 1500000000000000000, available 1000000000000000000")
+}
+
+func TestAllowanceMode(t *testing.T) {
+	reader := &fakeReader{
+		balances:   map[common.Address]*big.Int{common.HexToAddress(baseToken): wei("2000000000000000000")},
+		allowances: map[common.Address]*big.Int{common.HexToAddress(baseToken): wei("1000000000000000000")},
+	}
+	checker := NewChecker(Config{Mode: ModeAllowance}, logrus.New())
+	checker.AddChain(1, reader)
+
+	assert.NoError(t, checker.Check(context.Background(), newOrder(types.OrderSideSell, "2000", "1000000000000000000"), decimal.Zero))
+	assert.ErrorIs(t, checker.Check(context.Background(), newOrder(types.OrderSideSell, "2000", "1500000000000000000"), decimal.Zero), ErrInsufficientAllowance)
+	assert.ErrorIs(t, checker.Check(context.Background(), newOrder(types.OrderSideSell, "2000", "3000000000000000000"), decimal.Zero), ErrInsufficientWalletBalance)
+}
+
+func TestPairModeOverridesDefault(t *testing.T) {
+	checker := NewChecker(Config{Mode: ModeOff, Pairs: map[string]Mode{"weth-usdc": ModeDeposit}}, logrus.New())
+	checker.AddChain(1, &fakeReader{})
+	assert.True(t, checker.Enabled())
+	assert.Equal(t, ModeDeposit, checker.Mode("WETH-USDC"))
+	assert.Equal(t, ModeOff, checker.Mode("WBTC-USDC"))
+
+	order := newOrder(types.OrderSideSell, "2000", "1")
+	assert.ErrorIs(t, checker.Check(context.Background(), order, order.Price), ErrInsufficientDeposit)
+
+	order.TradingPair = "WBTC-USDC"
+	assert.NoError(t, checker.Check(context.Background(), order, order.Price))
+
+	assert.False(t, NewChecker(Config{}, logrus.New()).Enabled())
+}
+
+func TestUnavailableFailOpenAndClosed(t *testing.T) {
+	reader := &fakeReader{err: errors.New("connection refused")}
+	order := newOrder(types.OrderSideSell, "2000", "1")
+
+	open := NewChecker(Config{Mode: ModeDeposit, FailOpen: true}, logrus.New())
+	open.AddChain(1, reader)
+	assert.NoError(t, open.Check(context.Background(), order, order.Price))
+
+	closed := NewChecker(Config{Mode: ModeDeposit}, logrus.New())
+	closed.AddChain(1, reader)
+	assert.ErrorIs(t, closed.Check(context.Background(), order, order.Price), ErrUnavailable)
+
+	// 订单所属链没有RPC客户端
+	order.ChainID = 2
+	assert.ErrorIs(t, closed.Check(context.Background(), order, order.Price), ErrUnavailable)
+}
+
+func TestSkipsUnpricedAndInvalidOrders(t *testing.T) {
+	checker := NewChecker(Config{Mode: ModeDeposit}, logrus.New())
+	checker.AddChain(1, &fakeReader{})
+
+	// 卖盘为空的市价买单无法估算所需报价代币，交由撮合引擎处理
+	market := newOrder(types.OrderSideBuy, "0", "1000")
+	market.Type = types.OrderTypeMarket
+	assert.NoError(t, checker.Check(context.Background(), market, decimal.Zero))
+
+	invalid := newOrder(types.OrderSideSell, "2000", "1")
+	invalid.BaseToken = "WETH"
+	assert.ErrorIs(t, checker.Check(context.Background(), invalid, invalid.Price), ErrInvalidAddress)
+}
+
+func TestParseMode(t *testing.T) {
+	mode, err := ParseMode("")
+	require.NoError(t, err)
+	assert.Equal(t, ModeOff, mode)
+
+	mode, err = ParseMode("Allowance")
+	require.NoError(t, err)
+	assert.Equal(t, ModeAllowance, mode)
+
+	_, err = ParseMode("permit")
+	assert.Error(t, err)
+}
@@ -0,0 +1,200 @@
+// Package reduceonly 只减仓订单
+// 只减仓订单的剩余数量不超过用户当前持有的、该订单卖出的代币（卖单为基础代币，买单为按价格折算的报价代币），
+// 用于安全地处置库存：同一代币的多笔只减仓挂单按下单先后分配持仓，持仓减少时自动缩减靠后的挂单，持仓耗尽时撤销
+package reduceonly
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+
+	"orderbook-engine/internal/matching"
+	"orderbook-engine/internal/types"
+)
+
+// amountPrecision 买单按报价代币折算数量时保留的小数位
+const amountPrecision = 18
+
+var (
+	// ErrNoHoldings 没有可减的持仓（持仓为零或已被其他只减仓挂单占满）
+	ErrNoHoldings = errors.New("no holdings available to reduce")
+	// ErrPriceRequired 市价只减仓买单需要保护价才能折算持仓
+	ErrPriceRequired = errors.New("reduce-only market buy requires protection_price")
+)
+
+// Balances 持仓查询
+type Balances interface {
+	GetBalance(userAddress, token string) decimal.Decimal
+}
+
+// OrderStore 订单存储，挂单缩量后写回
+type OrderStore interface {
+	GetOrder(orderID uuid.UUID) (*types.Order, error)
+	UpdateOrder(order *types.Order) error
+}
+
+// Guard 只减仓约束
+type Guard struct {
+	mu       sync.Mutex
+	engine   *matching.MatchingEngine
+	balances Balances
+	store    OrderStore
+	logger   *logrus.Logger
+}
+
+// NewGuard 创建只减仓约束
+func NewGuard(engine *matching.MatchingEngine, balances Balances, store OrderStore, logger *logrus.Logger) *Guard {
+	return &Guard{
+		engine:   engine,
+		balances: balances,
+		store:    store,
+		logger:   logger,
+	}
+}
+
+// Clamp 下单时按未被其他只减仓挂单占用的持仓缩减订单数量，没有可用持仓时返回 ErrNoHoldings
+func (g *Guard) Clamp(order *types.Order) error {
+	price, err := exposurePrice(order)
+	if err != nil {
+		return err
+	}
+	token := exposureToken(order)
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	holdings := g.balances.GetBalance(order.UserAddress, token)
+	for _, resting := range g.restingOrders(order.UserAddress, token) {
+		if resting.ID == order.ID {
+			continue
+		}
+		restingPrice, _ := exposurePrice(resting)
+		holdings = holdings.Sub(exposure(resting.GetRemainingAmount(), restingPrice))
+	}
+
+	allowed := capacity(holdings, price)
+	if !allowed.IsPositive() {
+		return ErrNoHoldings
+	}
+	if remaining := order.GetRemainingAmount(); remaining.GreaterThan(allowed) {
+		order.ReducedAmount = order.ReducedAmount.Add(remaining.Sub(allowed))
+		g.logger.WithFields(logrus.Fields{
+			"order_id": order.ID.String(),
+			"user":     order.UserAddress,
+			"token":    token,
+			"amount":   allowed.String(),
+		}).Info("Reduce-only order clamped to holdings")
+	}
+	return nil
+}
+
+// OnBalanceChange 余额变化回调，重新按持仓分配该代币的只减仓挂单
+func (g *Guard) OnBalanceChange(update *types.BalanceUpdate) {
+	g.Rebalance(update.UserAddress, update.Token)
+}
+
+// Rebalance 按当前持仓重新分配用户卖出 token 的只减仓挂单，先下单的优先保留，返回被缩减或撤销的订单数
+func (g *Guard) Rebalance(userAddress, token string) int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	holdings := g.balances.GetBalance(userAddress, token)
+	reduced := 0
+	for _, order := range g.restingOrders(userAddress, token) {
+		price, _ := exposurePrice(order)
+		remaining := order.GetRemainingAmount()
+		needed := exposure(remaining, price)
+		if !needed.GreaterThan(holdings) {
+			holdings = holdings.Sub(needed)
+			continue
+		}
+
+		allowed := capacity(holdings, price)
+		holdings = holdings.Sub(exposure(allowed, price))
+		snapshot, ok := g.engine.ReduceOrder(order.ID, order.TradingPair, allowed)
+		if !ok {
+			continue
+		}
+		reduced++
+		g.persist(snapshot)
+		g.logger.WithFields(logrus.Fields{
+			"order_id":  order.ID.String(),
+			"user":      userAddress,
+			"token":     token,
+			"remaining": snapshot.GetRemainingAmount().String(),
+			"status":    snapshot.Status,
+		}).Warn("Reduce-only order shrunk after holdings decreased")
+	}
+	return reduced
+}
+
+// restingOrders 用户卖出 token 的只减仓挂单，按下单时间排序
+func (g *Guard) restingOrders(userAddress, token string) []*types.Order {
+	var orders []*types.Order
+	for _, order := range g.engine.GetUserOrders(userAddress) {
+		if order.ReduceOnly && strings.EqualFold(exposureToken(order), token) {
+			orders = append(orders, order)
+		}
+	}
+	sort.Slice(orders, func(i, j int) bool {
+		return orders[i].CreatedAt.Before(orders[j].CreatedAt)
+	})
+	return orders
+}
+
+// persist 挂单缩量后写回存储
+func (g *Guard) persist(snapshot *types.Order) {
+	order, err := g.store.GetOrder(snapshot.ID)
+	if err != nil {
+		g.logger.WithError(err).WithField("order_id", snapshot.ID).Error("Failed to load reduce-only order")
+		return
+	}
+	order.ReducedAmount = snapshot.ReducedAmount
+	order.Status = snapshot.Status
+	order.StatusReason = snapshot.StatusReason
+	order.UpdatedAt = snapshot.UpdatedAt
+	if err := g.store.UpdateOrder(order); err != nil {
+		g.logger.WithError(err).WithField("order_id", snapshot.ID).Error("Failed to update reduce-only order")
+	}
+}
+
+// exposureToken 订单卖出的代币
+func exposureToken(order *types.Order) string {
+	if order.Side == types.OrderSideBuy {
+		return order.QuoteToken
+	}
+	return order.BaseToken
+}
+
+// exposurePrice 买单折算持仓使用的价格（限价单为订单价格，市价单为保护价），卖单返回 1
+func exposurePrice(order *types.Order) (decimal.Decimal, error) {
+	if order.Side != types.OrderSideBuy {
+		return decimal.NewFromInt(1), nil
+	}
+	if order.Type == types.OrderTypeMarket {
+		if !order.ProtectionPrice.IsPositive() {
+			return decimal.Zero, ErrPriceRequired
+		}
+		return order.ProtectionPrice, nil
+	}
+	return order.Price, nil
+}
+
+// exposure 数量对应占用的持仓
+func exposure(amount, price decimal.Decimal) decimal.Decimal {
+	return amount.Mul(price)
+}
+
+// capacity 持仓可支持的最大数量（向下取整，避免超过持仓）
+func capacity(holdings, price decimal.Decimal) decimal.Decimal {
+	if !holdings.IsPositive() || !price.IsPositive() {
+		return decimal.Zero
+	}
+	amount, _ := holdings.QuoRem(price, amountPrecision)
+	return amount
+}
@@ -0,0 +1,108 @@
+package reduceonly
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"orderbook-engine/internal/matching"
+	"orderbook-engine/internal/types"
+)
+
+const (
+	user      = "0x1234567890123456789012345678901234567890"
+	baseToken = "0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2"
+)
+
+type fakeBalances map[string]decimal.Decimal
+
+func (b fakeBalances) GetBalance(userAddress, token string) decimal.Decimal {
+	return b[token]
+}
+
+type fakeStore map[uuid.UUID]*types.Order
+
+func (s fakeStore) GetOrder(orderID uuid.UUID) (*types.Order, error) {
+	order := *s[orderID]
+	return &order, nil
+}
+
+func (s fakeStore) UpdateOrder(order *types.Order) error {
+	s[order.ID] = order
+	return nil
+}
+
+func newSellOrder(amount int64, createdAt time.Time) *types.Order {
+	return &types.Order{
+		ID:          uuid.New(),
+		UserAddress: user,
+		TradingPair: "WETH-USDC",
+		BaseToken:   baseToken,
+		QuoteToken:  "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48",
+		Side:        types.OrderSideSell,
+		Type:        types.OrderTypeLimit,
+		Price:       decimal.NewFromInt(2000),
+		Amount:      decimal.NewFromInt(amount),
+		ReduceOnly:  true,
+		CreatedAt:   createdAt,
+	}
+}
+
+func restingOrder(engine *matching.MatchingEngine, orderID uuid.UUID) (*types.Order, bool) {
+	for _, order := range engine.GetUserOrders(user) {
+		if order.ID == orderID {
+			return order, true
+		}
+	}
+	return nil, false
+}
+
+func TestReduceOnlyClampAndRebalance(t *testing.T) {
+	engine := matching.NewMatchingEngine(logrus.New())
+	balances := fakeBalances{baseToken: decimal.NewFromInt(5)}
+	store := fakeStore{}
+	guard := NewGuard(engine, balances, store, logrus.New())
+
+	// 下单数量超过持仓时缩减到持仓
+	first := newSellOrder(3, time.Now().Add(-time.Minute))
+	require.NoError(t, guard.Clamp(first))
+	assert.True(t, first.GetRemainingAmount().Equal(decimal.NewFromInt(3)))
+	_, err := engine.AddOrder(first)
+	require.NoError(t, err)
+	store[first.ID] = first
+
+	second := newSellOrder(4, time.Now())
+	require.NoError(t, guard.Clamp(second))
+	assert.True(t, second.GetRemainingAmount().Equal(decimal.NewFromInt(2)), "剩余持仓只够2")
+	assert.True(t, second.Amount.Equal(decimal.NewFromInt(4)), "签名数量不变")
+	_, err = engine.AddOrder(second)
+	require.NoError(t, err)
+	store[second.ID] = second
+
+	// 持仓已被占满
+	assert.ErrorIs(t, guard.Clamp(newSellOrder(1, time.Now())), ErrNoHoldings)
+
+	// 持仓减少：先下单的保留，后下单的先缩减
+	balances[baseToken] = decimal.NewFromInt(4)
+	assert.Equal(t, 1, guard.Rebalance(user, baseToken))
+	resting, ok := restingOrder(engine, second.ID)
+	require.True(t, ok)
+	assert.True(t, resting.GetRemainingAmount().Equal(decimal.NewFromInt(1)))
+	assert.True(t, store[second.ID].ReducedAmount.Equal(decimal.NewFromInt(3)))
+
+	// 持仓耗尽时撤销
+	balances[baseToken] = decimal.NewFromInt(2)
+	assert.Equal(t, 2, guard.Rebalance(user, baseToken))
+	_, ok = restingOrder(engine, second.ID)
+	assert.False(t, ok)
+	assert.Equal(t, types.OrderStatusCancelled, store[second.ID].Status)
+	assert.Equal(t, types.StatusReasonReduceOnly, store[second.ID].StatusReason)
+	resting, ok = restingOrder(engine, first.ID)
+	require.True(t, ok)
+	assert.True(t, resting.GetRemainingAmount().Equal(decimal.NewFromInt(2)))
+}
@@ -0,0 +1,56 @@
+package referral
+
+import (
+	"strings"
+	"sync"
+)
+
+// MemoryStore 内存推荐关系存储，进程重启后丢失，仅用于开发和测试
+type MemoryStore struct {
+	mu        sync.RWMutex
+	referrals []*Referral
+	balances  map[string]*RebateBalance
+}
+
+// NewMemoryStore 创建内存推荐关系存储
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{balances: make(map[string]*RebateBalance)}
+}
+
+// SaveReferral 保存推荐关系
+func (s *MemoryStore) SaveReferral(referral *Referral) error {
+	copied := *referral
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.referrals = append(s.referrals, &copied)
+	return nil
+}
+
+// SaveBalance 保存返佣余额
+func (s *MemoryStore) SaveBalance(balance *RebateBalance) error {
+	copied := *balance
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.balances[strings.ToLower(balance.Referrer)+"/"+strings.ToLower(balance.Token)] = &copied
+	return nil
+}
+
+// Load 读取全部推荐关系与返佣余额
+func (s *MemoryStore) Load() ([]*Referral, []*RebateBalance, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	referrals := make([]*Referral, 0, len(s.referrals))
+	for _, referral := range s.referrals {
+		copied := *referral
+		referrals = append(referrals, &copied)
+	}
+	balances := make([]*RebateBalance, 0, len(s.balances))
+	for _, balance := range s.balances {
+		copied := *balance
+		balances = append(balances, &copied)
+	}
+	return referrals, balances, nil
+}
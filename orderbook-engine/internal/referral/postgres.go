@@ -0,0 +1,115 @@
+package referral
+
+import (
+	"database/sql"
+	"fmt"
+
+	_ "github.com/lib/pq"
+)
+
+// referralSchema 推荐关系与返佣余额表
+var referralSchema = []string{
+	`CREATE TABLE IF NOT EXISTS referrals (
+		user_address TEXT PRIMARY KEY,
+		referrer TEXT NOT NULL,
+		created_at TIMESTAMPTZ NOT NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_referrals_referrer ON referrals (lower(referrer))`,
+	`CREATE TABLE IF NOT EXISTS referral_rebates (
+		referrer TEXT NOT NULL,
+		token TEXT NOT NULL,
+		accrued NUMERIC NOT NULL,
+		claimed NUMERIC NOT NULL,
+		updated_at TIMESTAMPTZ NOT NULL,
+		PRIMARY KEY (referrer, token)
+	)`,
+}
+
+const upsertRebate = `INSERT INTO referral_rebates (referrer, token, accrued, claimed, updated_at) VALUES ($1, $2, $3, $4, $5)
+	ON CONFLICT (referrer, token) DO UPDATE SET accrued = EXCLUDED.accrued, claimed = EXCLUDED.claimed, updated_at = EXCLUDED.updated_at`
+
+// PostgresStore 基于 PostgreSQL 的推荐关系存储
+type PostgresStore struct {
+	db *sql.DB
+}
+
+// NewPostgresStore 创建 PostgreSQL 推荐关系存储并确保表存在
+func NewPostgresStore(dsn string) (*PostgresStore, error) {
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open referral database: %w", err)
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to connect to referral database: %w", err)
+	}
+
+	for _, stmt := range referralSchema {
+		if _, err := db.Exec(stmt); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("failed to create referral tables: %w", err)
+		}
+	}
+	return &PostgresStore{db: db}, nil
+}
+
+// Close 关闭数据库连接
+func (s *PostgresStore) Close() error {
+	return s.db.Close()
+}
+
+// SaveReferral 保存推荐关系
+func (s *PostgresStore) SaveReferral(referral *Referral) error {
+	_, err := s.db.Exec(`INSERT INTO referrals (user_address, referrer, created_at) VALUES ($1, $2, $3)`,
+		referral.UserAddress, referral.Referrer, referral.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save referral: %w", err)
+	}
+	return nil
+}
+
+// SaveBalance 保存返佣余额
+func (s *PostgresStore) SaveBalance(balance *RebateBalance) error {
+	_, err := s.db.Exec(upsertRebate, balance.Referrer, balance.Token, balance.Accrued, balance.Claimed, balance.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save rebate balance: %w", err)
+	}
+	return nil
+}
+
+// Load 读取全部推荐关系与返佣余额
+func (s *PostgresStore) Load() ([]*Referral, []*RebateBalance, error) {
+	rows, err := s.db.Query(`SELECT user_address, referrer, created_at FROM referrals`)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to query referrals: %w", err)
+	}
+	defer rows.Close()
+
+	var referrals []*Referral
+	for rows.Next() {
+		referral := &Referral{}
+		if err := rows.Scan(&referral.UserAddress, &referral.Referrer, &referral.CreatedAt); err != nil {
+			return nil, nil, fmt.Errorf("failed to scan referral: %w", err)
+		}
+		referrals = append(referrals, referral)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	balanceRows, err := s.db.Query(`SELECT referrer, token, accrued, claimed, updated_at FROM referral_rebates`)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to query rebate balances: %w", err)
+	}
+	defer balanceRows.Close()
+
+	var balances []*RebateBalance
+	for balanceRows.Next() {
+		balance := &RebateBalance{}
+		if err := balanceRows.Scan(&balance.Referrer, &balance.Token, &balance.Accrued, &balance.Claimed, &balance.UpdatedAt); err != nil {
+			return nil, nil, fmt.Errorf("failed to scan rebate balance: %w", err)
+		}
+		balances = append(balances, balance)
+	}
+	return referrals, balances, balanceRows.Err()
+}
@@ -0,0 +1,347 @@
+// Package referral 推荐返佣
+// 用户绑定推荐人后，其作为 taker 成交产生的手续费按配置比例记为推荐人的返佣；
+// 手续费率与结算合约的 taker 费率一致（基点），返佣以报价代币计，提取时入账到推荐人在结算合约托管的余额，再走正常提现流程
+package referral
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+
+	"orderbook-engine/internal/matching"
+	"orderbook-engine/internal/types"
+)
+
+var (
+	// ErrSelfReferral 不能推荐自己
+	ErrSelfReferral = errors.New("user cannot refer themselves")
+	// ErrAlreadyReferred 推荐关系已绑定，不可更改
+	ErrAlreadyReferred = errors.New("referrer already registered")
+	// ErrReferralCycle 推荐人是该用户直接推荐的下级
+	ErrReferralCycle = errors.New("referrer is referred by this user")
+	// ErrNothingToClaim 没有可提取的返佣
+	ErrNothingToClaim = errors.New("no rebate available to claim")
+	// ErrPayoutDisabled 未配置返佣发放
+	ErrPayoutDisabled = errors.New("rebate payout not configured")
+)
+
+// bpsDenominator 基点分母，与结算合约手续费计算一致
+var bpsDenominator = decimal.NewFromInt(10000)
+
+// Config 返佣配置
+type Config struct {
+	TakerFeeBps decimal.Decimal `json:"taker_fee_bps"` // taker 手续费率（基点），与结算合约 protocolFeeRate 一致
+	RebateShare decimal.Decimal `json:"rebate_share"`  // 返给推荐人的 taker 手续费比例（0~1）
+}
+
+// Validate 校验配置
+func (c Config) Validate() error {
+	if c.TakerFeeBps.IsNegative() || c.TakerFeeBps.GreaterThan(bpsDenominator) {
+		return fmt.Errorf("taker_fee_bps must be between 0 and 10000")
+	}
+	if c.RebateShare.IsNegative() || c.RebateShare.GreaterThan(decimal.NewFromInt(1)) {
+		return fmt.Errorf("rebate_share must be between 0 and 1")
+	}
+	return nil
+}
+
+// Referral 推荐关系
+type Referral struct {
+	UserAddress string    `json:"user_address"`
+	Referrer    string    `json:"referrer"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// RebateBalance 推荐人单个代币的返佣余额
+type RebateBalance struct {
+	Referrer  string          `json:"referrer"`
+	Token     string          `json:"token"`
+	Accrued   decimal.Decimal `json:"accrued"` // 累计返佣
+	Claimed   decimal.Decimal `json:"claimed"` // 累计已提取
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+// Available 可提取的返佣
+func (b *RebateBalance) Available() decimal.Decimal {
+	return b.Accrued.Sub(b.Claimed)
+}
+
+// Claim 返佣提取记录
+type Claim struct {
+	ID        string          `json:"id"`
+	Referrer  string          `json:"referrer"`
+	Token     string          `json:"token"`
+	Amount    decimal.Decimal `json:"amount"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// Summary 推荐人返佣概览
+type Summary struct {
+	UserAddress string           `json:"user_address"`
+	Referrer    string           `json:"referrer,omitempty"` // 该用户自己的推荐人
+	Referees    int              `json:"referees"`           // 直接推荐的用户数
+	Rebates     []*RebateBalance `json:"rebates"`
+}
+
+// Store 推荐关系与返佣余额持久化
+type Store interface {
+	SaveReferral(referral *Referral) error
+	SaveBalance(balance *RebateBalance) error
+	Load() ([]*Referral, []*RebateBalance, error)
+}
+
+// Payer 返佣发放，将返佣入账到推荐人在结算合约托管的余额
+// claimID 用于去重，同一提取重复发放只入账一次
+type Payer interface {
+	PayRebate(claimID, referrer, token string, amount decimal.Decimal) error
+}
+
+// Program 推荐返佣
+type Program struct {
+	mu        sync.RWMutex
+	config    Config
+	referrals map[string]*Referral                 // lower(user) -> 推荐关系
+	referees  map[string]int                       // lower(referrer) -> 直接推荐人数
+	balances  map[string]map[string]*RebateBalance // lower(referrer) -> lower(token) -> 余额
+	store     Store
+	payer     Payer
+	logger    *logrus.Logger
+}
+
+// NewProgram 创建推荐返佣并从存储恢复推荐关系与返佣余额
+func NewProgram(config Config, store Store, logger *logrus.Logger) (*Program, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	p := &Program{
+		config:    config,
+		referrals: make(map[string]*Referral),
+		referees:  make(map[string]int),
+		balances:  make(map[string]map[string]*RebateBalance),
+		store:     store,
+		logger:    logger,
+	}
+
+	referrals, balances, err := store.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load referrals: %w", err)
+	}
+	for _, referral := range referrals {
+		p.referrals[strings.ToLower(referral.UserAddress)] = referral
+		p.referees[strings.ToLower(referral.Referrer)]++
+	}
+	for _, balance := range balances {
+		*p.balanceUnsafe(balance.Referrer, balance.Token) = *balance
+	}
+	return p, nil
+}
+
+// SetPayer 设置返佣发放，为空时不允许提取
+func (p *Program) SetPayer(payer Payer) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.payer = payer
+}
+
+// Config 当前返佣配置
+func (p *Program) Config() Config {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.config
+}
+
+// Register 绑定推荐人，每个用户只能绑定一次
+func (p *Program) Register(userAddress, referrer string) (*Referral, error) {
+	user, ref := strings.ToLower(userAddress), strings.ToLower(referrer)
+	if user == ref {
+		return nil, ErrSelfReferral
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, exists := p.referrals[user]; exists {
+		return nil, ErrAlreadyReferred
+	}
+	if upstream, exists := p.referrals[ref]; exists && strings.EqualFold(upstream.Referrer, userAddress) {
+		return nil, ErrReferralCycle
+	}
+
+	referral := &Referral{
+		UserAddress: userAddress,
+		Referrer:    referrer,
+		CreatedAt:   time.Now(),
+	}
+	if err := p.store.SaveReferral(referral); err != nil {
+		return nil, fmt.Errorf("failed to persist referral: %w", err)
+	}
+	p.referrals[user] = referral
+	p.referees[ref]++
+
+	p.logger.WithFields(logrus.Fields{
+		"user":     userAddress,
+		"referrer": referrer,
+	}).Info("Referral registered")
+	copied := *referral
+	return &copied, nil
+}
+
+// Referrer 用户的推荐人
+func (p *Program) Referrer(userAddress string) (string, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	referral, exists := p.referrals[strings.ToLower(userAddress)]
+	if !exists {
+		return "", false
+	}
+	return referral.Referrer, true
+}
+
+// Summary 用户的推荐关系与返佣余额
+func (p *Program) Summary(userAddress string) *Summary {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	key := strings.ToLower(userAddress)
+	summary := &Summary{
+		UserAddress: userAddress,
+		Referees:    p.referees[key],
+		Rebates:     []*RebateBalance{},
+	}
+	if referral, exists := p.referrals[key]; exists {
+		summary.Referrer = referral.Referrer
+	}
+	for _, balance := range p.balances[key] {
+		copied := *balance
+		summary.Rebates = append(summary.Rebates, &copied)
+	}
+	sort.Slice(summary.Rebates, func(i, j int) bool {
+		return summary.Rebates[i].Token < summary.Rebates[j].Token
+	})
+	return summary
+}
+
+// TakerFee 成交的 taker 手续费（报价代币），与结算合约 quoteAmount * rate / 10000 一致
+func (p *Program) TakerFee(fill *types.Fill) decimal.Decimal {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return fill.Price.Mul(fill.Amount).Mul(p.config.TakerFeeBps).Div(bpsDenominator)
+}
+
+// Accrue 按成交的 taker 手续费为 taker 的推荐人记入返佣，taker 未绑定推荐人时返回零
+func (p *Program) Accrue(fill *types.Fill, quoteToken string) decimal.Decimal {
+	fee := p.TakerFee(fill)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	referral, exists := p.referrals[strings.ToLower(fill.TakerUserAddress)]
+	if !exists {
+		return decimal.Zero
+	}
+	rebate := fee.Mul(p.config.RebateShare)
+	if !rebate.IsPositive() {
+		return decimal.Zero
+	}
+
+	balance := p.balanceUnsafe(referral.Referrer, quoteToken)
+	previous := *balance
+	balance.Accrued = balance.Accrued.Add(rebate)
+	balance.UpdatedAt = time.Now()
+	if err := p.store.SaveBalance(balance); err != nil {
+		*balance = previous
+		p.logger.WithError(err).WithFields(logrus.Fields{
+			"fill_id":  fill.ID.String(),
+			"referrer": referral.Referrer,
+		}).Error("Failed to persist referral rebate")
+		return decimal.Zero
+	}
+	return rebate
+}
+
+// Claim 提取推荐人某代币的全部可用返佣，经 Payer 入账到推荐人的托管余额
+func (p *Program) Claim(referrer, token string) (*Claim, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.payer == nil {
+		return nil, ErrPayoutDisabled
+	}
+	tokens := p.balances[strings.ToLower(referrer)]
+	if tokens == nil || tokens[strings.ToLower(token)] == nil {
+		return nil, ErrNothingToClaim
+	}
+	balance := tokens[strings.ToLower(token)]
+	amount := balance.Available()
+	if !amount.IsPositive() {
+		return nil, ErrNothingToClaim
+	}
+
+	claim := &Claim{
+		ID:        "rebate-" + uuid.NewString(),
+		Referrer:  balance.Referrer,
+		Token:     balance.Token,
+		Amount:    amount,
+		CreatedAt: time.Now(),
+	}
+
+	// 先记已提取再发放，发放失败时回滚，避免重复提取
+	previous := *balance
+	balance.Claimed = balance.Claimed.Add(amount)
+	balance.UpdatedAt = claim.CreatedAt
+	if err := p.store.SaveBalance(balance); err != nil {
+		*balance = previous
+		return nil, fmt.Errorf("failed to persist rebate claim: %w", err)
+	}
+	if err := p.payer.PayRebate(claim.ID, claim.Referrer, claim.Token, claim.Amount); err != nil {
+		*balance = previous
+		if saveErr := p.store.SaveBalance(balance); saveErr != nil {
+			p.logger.WithError(saveErr).WithField("claim_id", claim.ID).Error("Failed to roll back rebate claim")
+		}
+		return nil, fmt.Errorf("failed to pay rebate: %w", err)
+	}
+
+	p.logger.WithFields(logrus.Fields{
+		"claim_id": claim.ID,
+		"referrer": claim.Referrer,
+		"token":    claim.Token,
+		"amount":   claim.Amount.String(),
+	}).Info("Referral rebate claimed")
+	return claim, nil
+}
+
+// Run 消费撮合事件，为每笔成交的 taker 推荐人记入返佣，直到订阅关闭
+func (p *Program) Run(sub *matching.Subscription) {
+	for event := range sub.Events() {
+		if event.Order == nil {
+			continue
+		}
+		switch event.Type {
+		case matching.EventOrderAdded, matching.EventAuctionUncrossed:
+			for _, fill := range event.Fills {
+				p.Accrue(fill, event.Order.QuoteToken)
+			}
+		}
+	}
+}
+
+// balanceUnsafe 获取或创建返佣余额（不加锁版本）
+func (p *Program) balanceUnsafe(referrer, token string) *RebateBalance {
+	key := strings.ToLower(referrer)
+	if p.balances[key] == nil {
+		p.balances[key] = make(map[string]*RebateBalance)
+	}
+	balance := p.balances[key][strings.ToLower(token)]
+	if balance == nil {
+		balance = &RebateBalance{Referrer: referrer, Token: token}
+		p.balances[key][strings.ToLower(token)] = balance
+	}
+	return balance
+}
@@ -0,0 +1,92 @@
+package referral
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"orderbook-engine/internal/types"
+)
+
+const quoteToken = "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"
+
+type fakePayer struct {
+	paid map[string]decimal.Decimal
+	err  error
+}
+
+func (p *fakePayer) PayRebate(claimID, referrer, token string, amount decimal.Decimal) error {
+	if p.err != nil {
+		return p.err
+	}
+	p.paid[referrer] = p.paid[referrer].Add(amount)
+	return nil
+}
+
+func newFill(taker string, price, amount int64) *types.Fill {
+	return &types.Fill{
+		ID:               uuid.New(),
+		TakerUserAddress: taker,
+		MakerUserAddress: "0xmaker",
+		TradingPair:      "WETH-USDC",
+		Price:            decimal.NewFromInt(price),
+		Amount:           decimal.NewFromInt(amount),
+	}
+}
+
+func TestReferralRebateAccrualAndClaim(t *testing.T) {
+	store := NewMemoryStore()
+	program, err := NewProgram(Config{
+		TakerFeeBps: decimal.NewFromInt(25),
+		RebateShare: decimal.NewFromFloat(0.2),
+	}, store, logrus.New())
+	require.NoError(t, err)
+
+	_, err = program.Register("0xTaker", "0xReferrer")
+	require.NoError(t, err)
+	_, err = program.Register("0xtaker", "0xother")
+	assert.ErrorIs(t, err, ErrAlreadyReferred)
+	_, err = program.Register("0xReferrer", "0xTaker")
+	assert.ErrorIs(t, err, ErrReferralCycle)
+	_, err = program.Register("0xSelf", "0xself")
+	assert.ErrorIs(t, err, ErrSelfReferral)
+
+	// 2000 * 1 * 0.25% = 5，返佣 20% = 1
+	assert.True(t, program.Accrue(newFill("0xtaker", 2000, 1), quoteToken).Equal(decimal.NewFromInt(1)))
+	assert.True(t, program.Accrue(newFill("0xnobody", 2000, 1), quoteToken).IsZero())
+
+	_, err = program.Claim("0xReferrer", quoteToken)
+	assert.ErrorIs(t, err, ErrPayoutDisabled)
+
+	// 发放失败时返佣保持可提取
+	payer := &fakePayer{paid: make(map[string]decimal.Decimal), err: errors.New("ledger unavailable")}
+	program.SetPayer(payer)
+	_, err = program.Claim("0xReferrer", quoteToken)
+	require.Error(t, err)
+	assert.True(t, program.Summary("0xReferrer").Rebates[0].Available().Equal(decimal.NewFromInt(1)))
+
+	payer.err = nil
+	claim, err := program.Claim("0xreferrer", quoteToken)
+	require.NoError(t, err)
+	assert.True(t, claim.Amount.Equal(decimal.NewFromInt(1)))
+	assert.True(t, payer.paid["0xReferrer"].Equal(decimal.NewFromInt(1)))
+	_, err = program.Claim("0xReferrer", quoteToken)
+	assert.ErrorIs(t, err, ErrNothingToClaim)
+
+	// 重启后从存储恢复
+	restored, err := NewProgram(program.Config(), store, logrus.New())
+	require.NoError(t, err)
+	summary := restored.Summary("0xReferrer")
+	assert.Equal(t, 1, summary.Referees)
+	require.Len(t, summary.Rebates, 1)
+	assert.True(t, summary.Rebates[0].Accrued.Equal(decimal.NewFromInt(1)))
+	assert.True(t, summary.Rebates[0].Available().IsZero())
+	referrer, ok := restored.Referrer("0xTAKER")
+	assert.True(t, ok)
+	assert.Equal(t, "0xReferrer", referrer)
+}
@@ -0,0 +1,312 @@
+// Package rewards 流动性挖矿积分
+// 按纪元（固定时长）统计做市用户的三项指标：maker 成交额（来自成交事件）、报价紧密度与在线率（来自周期性订单簿采样），
+// 积分 = 各项在纪元内全体用户中的占比（在线率直接使用采样命中比例）按权重加总，供激励计划按纪元排行发放
+package rewards
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+
+	"orderbook-engine/internal/matching"
+	"orderbook-engine/internal/types"
+)
+
+// Config 积分配置
+type Config struct {
+	EpochLength    time.Duration   // 纪元时长，纪元编号为 Unix 时间除以纪元时长
+	SampleInterval time.Duration   // 订单簿采样周期
+	MaxSpread      decimal.Decimal // 计入紧密度与在线率的挂单距中间价的最大比例（如 0.02 表示 2%）
+	VolumeWeight   decimal.Decimal // maker 成交额占比权重
+	SpreadWeight   decimal.Decimal // 紧密度占比权重
+	UptimeWeight   decimal.Decimal // 在线率权重
+	RetainEpochs   int             // 保留的历史纪元数，0 表示不清理
+}
+
+// Validate 校验配置
+func (c Config) Validate() error {
+	if c.EpochLength <= 0 || c.SampleInterval <= 0 {
+		return fmt.Errorf("epoch_length and sample_interval must be positive")
+	}
+	if c.SampleInterval > c.EpochLength {
+		return fmt.Errorf("sample_interval must not exceed epoch_length")
+	}
+	if !c.MaxSpread.IsPositive() {
+		return fmt.Errorf("max_spread must be positive")
+	}
+	if c.VolumeWeight.IsNegative() || c.SpreadWeight.IsNegative() || c.UptimeWeight.IsNegative() {
+		return fmt.Errorf("weights must not be negative")
+	}
+	if c.RetainEpochs < 0 {
+		return fmt.Errorf("retain_epochs must not be negative")
+	}
+	return nil
+}
+
+// Epoch 纪元时间范围
+type Epoch struct {
+	Number int64     `json:"epoch"`
+	Start  time.Time `json:"start"`
+	End    time.Time `json:"end"`
+}
+
+// Score 用户在一个纪元内的指标与积分
+type Score struct {
+	UserAddress string          `json:"user_address"`
+	Epoch       int64           `json:"epoch"`
+	MakerVolume decimal.Decimal `json:"maker_volume"` // maker 成交额（报价代币计）
+	SpreadScore decimal.Decimal `json:"spread_score"` // 紧密度：每次采样挂单数量 ×（1 - 距中间价比例 / MaxSpread）之和
+	Uptime      decimal.Decimal `json:"uptime"`       // 有合格挂单的采样次数占纪元总采样次数的比例
+	Points      decimal.Decimal `json:"points"`
+	Rank        int             `json:"rank,omitempty"`
+}
+
+// userStats 用户在一个纪元内的原始统计
+type userStats struct {
+	address     string
+	makerVolume decimal.Decimal
+	spreadScore decimal.Decimal
+	samples     int64 // 有合格挂单的采样次数
+}
+
+// epochStats 一个纪元的统计
+type epochStats struct {
+	samples int64 // 纪元内订单簿采样次数
+	users   map[string]*userStats
+}
+
+// Engine 流动性挖矿积分引擎
+type Engine struct {
+	mu     sync.RWMutex
+	engine *matching.MatchingEngine
+	config Config
+	epochs map[int64]*epochStats
+	logger *logrus.Logger
+}
+
+// NewEngine 创建积分引擎
+func NewEngine(engine *matching.MatchingEngine, config Config, logger *logrus.Logger) (*Engine, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return &Engine{
+		engine: engine,
+		config: config,
+		epochs: make(map[int64]*epochStats),
+		logger: logger,
+	}, nil
+}
+
+// EpochAt 时刻所在的纪元
+func (e *Engine) EpochAt(at time.Time) Epoch {
+	length := int64(e.config.EpochLength)
+	number := at.UnixNano() / length
+	start := time.Unix(0, number*length).UTC()
+	return Epoch{Number: number, Start: start, End: start.Add(e.config.EpochLength)}
+}
+
+// Epoch 纪元编号对应的时间范围
+func (e *Engine) Epoch(number int64) Epoch {
+	start := time.Unix(0, number*int64(e.config.EpochLength)).UTC()
+	return Epoch{Number: number, Start: start, End: start.Add(e.config.EpochLength)}
+}
+
+// Run 消费撮合事件，按成交记入 maker 成交额，直到订阅关闭
+func (e *Engine) Run(sub *matching.Subscription) {
+	for event := range sub.Events() {
+		switch event.Type {
+		case matching.EventOrderAdded, matching.EventAuctionUncrossed:
+			for _, fill := range event.Fills {
+				e.RecordFill(fill)
+			}
+		}
+	}
+}
+
+// RecordFill 记入成交的 maker 成交额
+func (e *Engine) RecordFill(fill *types.Fill) {
+	at := fill.CreatedAt
+	if at.IsZero() {
+		at = time.Now()
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	stats := e.userUnsafe(e.EpochAt(at).Number, fill.MakerUserAddress)
+	stats.makerVolume = stats.makerVolume.Add(fill.Price.Mul(fill.Amount))
+}
+
+// Start 启动周期性订单簿采样
+func (e *Engine) Start() {
+	go func() {
+		ticker := time.NewTicker(e.config.SampleInterval)
+		defer ticker.Stop()
+
+		for now := range ticker.C {
+			e.Sample(now)
+		}
+	}()
+}
+
+// Sample 采样一次全部挂单：距中间价不超过 MaxSpread 的挂单计入紧密度，有此类挂单的用户计入在线
+// 只有一侧报价的交易对没有中间价，不计分
+func (e *Engine) Sample(now time.Time) {
+	mids := make(map[string]decimal.Decimal)
+	for _, bbo := range e.engine.GetAllBBO() {
+		if bbo.MidPrice != nil && bbo.MidPrice.IsPositive() {
+			mids[bbo.TradingPair] = *bbo.MidPrice
+		}
+	}
+
+	scores := make(map[string]decimal.Decimal)
+	addresses := make(map[string]string)
+	for _, order := range e.engine.OpenOrders() {
+		mid, ok := mids[order.TradingPair]
+		if !ok || order.Type != types.OrderTypeLimit {
+			continue
+		}
+		distance := order.Price.Sub(mid).Abs().Div(mid)
+		if distance.GreaterThan(e.config.MaxSpread) {
+			continue
+		}
+		key := strings.ToLower(order.UserAddress)
+		weight := decimal.NewFromInt(1).Sub(distance.Div(e.config.MaxSpread))
+		scores[key] = scores[key].Add(order.GetRemainingAmount().Mul(weight))
+		addresses[key] = order.UserAddress
+	}
+
+	epoch := e.EpochAt(now).Number
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.epochUnsafe(epoch).samples++
+	for key, score := range scores {
+		stats := e.userUnsafe(epoch, addresses[key])
+		stats.spreadScore = stats.spreadScore.Add(score)
+		stats.samples++
+	}
+	e.pruneUnsafe(epoch)
+}
+
+// UserScore 用户在纪元内的积分与排名，没有记录时返回零分
+func (e *Engine) UserScore(userAddress string, epoch int64) *Score {
+	for _, score := range e.Leaderboard(epoch, 0) {
+		if strings.EqualFold(score.UserAddress, userAddress) {
+			return score
+		}
+	}
+	return &Score{
+		UserAddress: userAddress,
+		Epoch:       epoch,
+		MakerVolume: decimal.Zero,
+		SpreadScore: decimal.Zero,
+		Uptime:      decimal.Zero,
+		Points:      decimal.Zero,
+	}
+}
+
+// Leaderboard 纪元积分排行，按积分降序，limit 为 0 时返回全部
+func (e *Engine) Leaderboard(epoch int64, limit int) []*Score {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	stats, exists := e.epochs[epoch]
+	if !exists {
+		return []*Score{}
+	}
+
+	totalVolume, totalSpread := decimal.Zero, decimal.Zero
+	for _, user := range stats.users {
+		totalVolume = totalVolume.Add(user.makerVolume)
+		totalSpread = totalSpread.Add(user.spreadScore)
+	}
+
+	scores := make([]*Score, 0, len(stats.users))
+	for _, user := range stats.users {
+		score := &Score{
+			UserAddress: user.address,
+			Epoch:       epoch,
+			MakerVolume: user.makerVolume,
+			SpreadScore: user.spreadScore,
+			Uptime:      decimal.Zero,
+			Points:      decimal.Zero,
+		}
+		if stats.samples > 0 {
+			score.Uptime = decimal.NewFromInt(user.samples).Div(decimal.NewFromInt(stats.samples))
+		}
+		if totalVolume.IsPositive() {
+			score.Points = score.Points.Add(user.makerVolume.Div(totalVolume).Mul(e.config.VolumeWeight))
+		}
+		if totalSpread.IsPositive() {
+			score.Points = score.Points.Add(user.spreadScore.Div(totalSpread).Mul(e.config.SpreadWeight))
+		}
+		score.Points = score.Points.Add(score.Uptime.Mul(e.config.UptimeWeight))
+		scores = append(scores, score)
+	}
+
+	sort.Slice(scores, func(i, j int) bool {
+		if !scores[i].Points.Equal(scores[j].Points) {
+			return scores[i].Points.GreaterThan(scores[j].Points)
+		}
+		return scores[i].UserAddress < scores[j].UserAddress
+	})
+	for i, score := range scores {
+		score.Rank = i + 1
+	}
+	if limit > 0 && len(scores) > limit {
+		scores = scores[:limit]
+	}
+	return scores
+}
+
+// Epochs 有记录的纪元编号，按时间倒序
+func (e *Engine) Epochs() []int64 {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	epochs := make([]int64, 0, len(e.epochs))
+	for epoch := range e.epochs {
+		epochs = append(epochs, epoch)
+	}
+	sort.Slice(epochs, func(i, j int) bool { return epochs[i] > epochs[j] })
+	return epochs
+}
+
+// epochUnsafe 获取或创建纪元统计（不加锁版本）
+func (e *Engine) epochUnsafe(epoch int64) *epochStats {
+	stats := e.epochs[epoch]
+	if stats == nil {
+		stats = &epochStats{users: make(map[string]*userStats)}
+		e.epochs[epoch] = stats
+	}
+	return stats
+}
+
+// userUnsafe 获取或创建用户在纪元内的统计（不加锁版本）
+func (e *Engine) userUnsafe(epoch int64, userAddress string) *userStats {
+	stats := e.epochUnsafe(epoch)
+	key := strings.ToLower(userAddress)
+	user := stats.users[key]
+	if user == nil {
+		user = &userStats{address: userAddress}
+		stats.users[key] = user
+	}
+	return user
+}
+
+// pruneUnsafe 清理超出保留数量的历史纪元（不加锁版本）
+func (e *Engine) pruneUnsafe(current int64) {
+	if e.config.RetainEpochs <= 0 {
+		return
+	}
+	for epoch := range e.epochs {
+		if epoch <= current-int64(e.config.RetainEpochs) {
+			delete(e.epochs, epoch)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package rewards
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"orderbook-engine/internal/matching"
+	"orderbook-engine/internal/types"
+)
+
+func newOrder(user string, side types.OrderSide, price, amount int64) *types.Order {
+	return &types.Order{
+		ID:          uuid.New(),
+		UserAddress: user,
+		TradingPair: "WETH-USDC",
+		Side:        side,
+		Type:        types.OrderTypeLimit,
+		Price:       decimal.NewFromInt(price),
+		Amount:      decimal.NewFromInt(amount),
+		CreatedAt:   time.Now(),
+	}
+}
+
+func TestLeaderboardScoresVolumeSpreadAndUptime(t *testing.T) {
+	book := matching.NewMatchingEngine(logrus.New())
+	scorer, err := NewEngine(book, Config{
+		EpochLength:    time.Hour,
+		SampleInterval: time.Minute,
+		MaxSpread:      decimal.NewFromFloat(0.01),
+		VolumeWeight:   decimal.NewFromInt(1),
+		SpreadWeight:   decimal.NewFromInt(1),
+		UptimeWeight:   decimal.NewFromInt(1),
+	}, logrus.New())
+	require.NoError(t, err)
+
+	// tight 在中间价附近双边报价，wide 的报价超出 MaxSpread
+	for _, order := range []*types.Order{
+		newOrder("0xtight", types.OrderSideBuy, 1999, 1),
+		newOrder("0xtight", types.OrderSideSell, 2001, 1),
+		newOrder("0xwide", types.OrderSideBuy, 1900, 5),
+	} {
+		_, err := book.AddOrder(order)
+		require.NoError(t, err)
+	}
+
+	now := time.Now()
+	scorer.Sample(now)
+	scorer.RecordFill(&types.Fill{MakerUserAddress: "0xwide", Price: decimal.NewFromInt(1900), Amount: decimal.NewFromInt(1), CreatedAt: now})
+
+	epoch := scorer.EpochAt(now).Number
+	board := scorer.Leaderboard(epoch, 0)
+	require.Len(t, board, 2)
+
+	tight, wide := board[0], board[1]
+	assert.Equal(t, "0xtight", tight.UserAddress)
+	assert.Equal(t, 1, tight.Rank)
+	assert.True(t, tight.Uptime.Equal(decimal.NewFromInt(1)))
+	assert.True(t, tight.Points.Equal(decimal.NewFromInt(2)), "全部紧密度加满在线率")
+	assert.True(t, wide.Uptime.IsZero())
+	assert.True(t, wide.Points.Equal(decimal.NewFromInt(1)), "全部 maker 成交额")
+
+	assert.True(t, scorer.UserScore("0xnobody", epoch).Points.IsZero())
+	assert.Len(t, scorer.Leaderboard(epoch, 1), 1)
+}
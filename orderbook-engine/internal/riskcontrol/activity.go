@@ -0,0 +1,27 @@
+package riskcontrol
+
+import "time"
+
+// OrderCounter 提供用户当前挂单数量（由撮合引擎实现）
+type OrderCounter interface {
+	ActiveOrderCount(userAddress string) int
+}
+
+// OrderFlowSource 提供用户滚动窗口内的下单数与用户撤单数（由 orderflow.Tracker 实现）
+type OrderFlowSource interface {
+	CancelCounts(userAddress string, window time.Duration) (placed int, cancelled int)
+}
+
+// SetOrderCounter 设置挂单数量来源
+func (rc *RiskController) SetOrderCounter(counter OrderCounter) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.orderCounter = counter
+}
+
+// SetOrderFlowSource 设置撤单率统计来源
+func (rc *RiskController) SetOrderFlowSource(source OrderFlowSource) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.orderFlow = source
+}
@@ -0,0 +1,126 @@
+package riskcontrol
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// BlacklistStore 黑名单持久化存储，每次变化写入全部未过期条目
+type BlacklistStore interface {
+	LoadBlacklist() ([]*BlacklistEntry, error)
+	SaveBlacklist(entries []*BlacklistEntry) error
+}
+
+// FileBlacklistStore 本地 JSON 文件黑名单存储
+type FileBlacklistStore struct {
+	path string
+}
+
+// NewFileBlacklistStore 创建文件黑名单存储
+func NewFileBlacklistStore(path string) (*FileBlacklistStore, error) {
+	if path == "" {
+		return nil, errors.New("blacklist file is required")
+	}
+	return &FileBlacklistStore{path: path}, nil
+}
+
+// LoadBlacklist 读取黑名单文件，文件不存在时返回空列表
+func (s *FileBlacklistStore) LoadBlacklist() ([]*BlacklistEntry, error) {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read blacklist: %w", err)
+	}
+
+	var entries []*BlacklistEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse blacklist: %w", err)
+	}
+	return entries, nil
+}
+
+// SaveBlacklist 写入黑名单文件（先写临时文件再重命名）
+func (s *FileBlacklistStore) SaveBlacklist(entries []*BlacklistEntry) error {
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), ".blacklist-*")
+	if err != nil {
+		return fmt.Errorf("failed to write blacklist: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write blacklist: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write blacklist: %w", err)
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
+
+// SetBlacklistStore 设置黑名单持久化存储并加载已保存的未过期条目
+func (rc *RiskController) SetBlacklistStore(store BlacklistStore) error {
+	entries, err := store.LoadBlacklist()
+	if err != nil {
+		return err
+	}
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	now := time.Now()
+	for _, entry := range entries {
+		if now.After(entry.ExpiresAt) {
+			continue
+		}
+		rc.blacklist[strings.ToLower(entry.UserAddress)] = entry
+	}
+	rc.blacklistStore = store
+	return nil
+}
+
+// GetBlacklist 获取全部未过期的黑名单条目，按加入时间排序
+func (rc *RiskController) GetBlacklist() []*BlacklistEntry {
+	rc.mu.RLock()
+	defer rc.mu.RUnlock()
+	return rc.activeBlacklistLocked(time.Now())
+}
+
+// activeBlacklistLocked 未过期黑名单条目的副本（调用方持有锁）
+func (rc *RiskController) activeBlacklistLocked(now time.Time) []*BlacklistEntry {
+	result := make([]*BlacklistEntry, 0, len(rc.blacklist))
+	for _, entry := range rc.blacklist {
+		if now.After(entry.ExpiresAt) {
+			continue
+		}
+		copied := *entry
+		result = append(result, &copied)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CreatedAt.Before(result[j].CreatedAt)
+	})
+	return result
+}
+
+// persistBlacklistLocked 写入黑名单存储，未设置存储时只保存在内存（调用方持有写锁）
+func (rc *RiskController) persistBlacklistLocked() error {
+	if rc.blacklistStore == nil {
+		return nil
+	}
+	if err := rc.blacklistStore.SaveBlacklist(rc.activeBlacklistLocked(time.Now())); err != nil {
+		rc.logger.WithError(err).Error("Failed to persist blacklist")
+		return err
+	}
+	return nil
+}
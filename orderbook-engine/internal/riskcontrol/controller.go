@@ -2,12 +2,14 @@ package riskcontrol
 
 import (
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/shopspring/decimal"
 	"github.com/sirupsen/logrus"
 
+	"orderbook-engine/internal/oracle"
 	"orderbook-engine/internal/storage"
 	"orderbook-engine/internal/types"
 )
@@ -16,9 +18,19 @@ import (
 type RiskController struct {
 	mu       sync.RWMutex
 	cache    *storage.RedisCache
+	limiter  RateLimiter                // 下单与撤单限率，未配置 Redis 时在进程内计数
 	config   *RiskConfig
 	logger   *logrus.Logger
-	blacklist map[string]*BlacklistEntry // 内存黑名单缓存
+	blacklist map[string]*BlacklistEntry // 内存黑名单缓存，小写地址 -> 条目
+	blacklistStore BlacklistStore        // 可选，黑名单持久化
+	oracle   oracle.PriceOracle         // 参考价格来源
+	pairConfigs map[string]*PairRiskConfig // 交易对覆盖配置
+	orderCounter OrderCounter              // 挂单数量来源
+	orderFlow    OrderFlowSource           // 可选，用户下单与撤单统计，为空时不检查撤单率
+	onAlert      func(alert *types.RiskAlert) // 可选，黑名单及账户冻结变化时通知用户
+	onViolation  func(order *types.Order, result *RiskCheckResult) // 可选，订单未通过风控检查时通知运维
+	freezes      map[string]*AccountFreeze    // 小写地址 -> 账户冻结
+	exposure     ExposureSource               // 可选，持仓风险暴露来源
 }
 
 // RiskConfig 风控配置
@@ -35,11 +47,13 @@ type RiskConfig struct {
 	CancelRateLimit   int           `json:"cancel_rate_limit"`   // 取消限率(每分钟)
 	RateLimitWindow   time.Duration `json:"rate_limit_window"`   // 限率窗口
 	MaxCancelRatio    decimal.Decimal `json:"max_cancel_ratio"`    // 最大取消率
+	CancelRatioWindow time.Duration   `json:"cancel_ratio_window"` // 取消率统计窗口
+	CancelRatioMinOrders int          `json:"cancel_ratio_min_orders"` // 窗口内下单数达到该值才检查取消率
 
 	// 资金检查
 	EnableBalanceCheck bool            `json:"enable_balance_check"` // 是否启用资金检查
 	MinBalance         decimal.Decimal `json:"min_balance"`          // 最小账户余额
-	MaxExposure        decimal.Decimal `json:"max_exposure"`         // 最大风险暴露
+	MaxExposure        decimal.Decimal `json:"max_exposure"`         // 最大风险暴露（持仓名义金额，报价代币计），不为正时不限制
 
 	// 黑名单
 	BlacklistDuration time.Duration `json:"blacklist_duration"` // 黑名单时长
@@ -63,16 +77,67 @@ type RiskCheckResult struct {
 
 // NewRiskController 创建风控控制器
 func NewRiskController(cache *storage.RedisCache, config *RiskConfig, logger *logrus.Logger) *RiskController {
+	var limiter RateLimiter = newMemoryRateLimiter()
+	if cache != nil {
+		limiter = cache
+	}
 	return &RiskController{
 		cache:     cache,
+		limiter:   limiter,
 		config:    config,
 		logger:    logger,
 		blacklist: make(map[string]*BlacklistEntry),
+		pairConfigs: make(map[string]*PairRiskConfig),
+		freezes:     make(map[string]*AccountFreeze),
 	}
 }
 
+// SetPriceOracle 设置价格偏差检查使用的参考价格来源
+func (rc *RiskController) SetPriceOracle(priceOracle oracle.PriceOracle) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.oracle = priceOracle
+}
+
+// SetAlertHandler 设置用户风控告警回调（加入、移出黑名单，冻结、解冻账户）
+func (rc *RiskController) SetAlertHandler(handler func(alert *types.RiskAlert)) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.onAlert = handler
+}
+
+// SetViolationHandler 设置风控拒单回调（推送运维事件），在 CheckOrderRisk 返回前同步调用
+func (rc *RiskController) SetViolationHandler(handler func(order *types.Order, result *RiskCheckResult)) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.onViolation = handler
+}
+
 // CheckOrderRisk 检查订单风险
 func (rc *RiskController) CheckOrderRisk(order *types.Order, userBalance map[string]decimal.Decimal) *RiskCheckResult {
+	result := rc.checkOrderRisk(order, userBalance)
+	if !result.Allowed {
+		rc.mu.RLock()
+		onViolation := rc.onViolation
+		rc.mu.RUnlock()
+		if onViolation != nil {
+			onViolation(order, result)
+		}
+	}
+	return result
+}
+
+// checkOrderRisk 依次执行各项风控检查，返回第一项未通过的结果
+func (rc *RiskController) checkOrderRisk(order *types.Order, userBalance map[string]decimal.Decimal) *RiskCheckResult {
+	// 0. 检查账户冻结
+	if freeze, frozen := rc.GetAccountFreeze(order.UserAddress); frozen {
+		return &RiskCheckResult{
+			Allowed: false,
+			Reason:  "账户已冻结: " + freeze.Reason,
+			Code:    types.StatusReasonAccountFrozen,
+		}
+	}
+
 	// 1. 检查黑名单
 	if rc.isBlacklisted(order.UserAddress) {
 		return &RiskCheckResult{
@@ -103,13 +168,18 @@ func (rc *RiskController) CheckOrderRisk(order *types.Order, userBalance map[str
 	}
 
 	// 6. 检查资金余额
-	if rc.config.EnableBalanceCheck {
+	if rc.Config().EnableBalanceCheck {
 		if result := rc.checkBalance(order, userBalance); !result.Allowed {
 			return result
 		}
 	}
 
-	// 7. 检查订单有效期
+	// 7. 检查风险暴露
+	if result := rc.checkExposure(order); !result.Allowed {
+		return result
+	}
+
+	// 8. 检查订单有效期
 	if result := rc.checkOrderValidity(order); !result.Allowed {
 		return result
 	}
@@ -119,20 +189,30 @@ func (rc *RiskController) CheckOrderRisk(order *types.Order, userBalance map[str
 
 // checkOrderAmount 检查订单金额
 func (rc *RiskController) checkOrderAmount(order *types.Order) *RiskCheckResult {
-	orderValue := order.Amount.Mul(order.Price)
+	price := order.Price
+	if order.Type == types.OrderTypeMarket {
+		// 市价单按参考价格估算金额，无参考价格时跳过
+		refPrice, ok := rc.referencePrice(order.TradingPair)
+		if !ok {
+			return &RiskCheckResult{Allowed: true}
+		}
+		price = refPrice
+	}
+	orderValue := order.Amount.Mul(price)
+	config := rc.EffectiveConfig(order.TradingPair)
 
-	if orderValue.LessThan(rc.config.MinOrderAmount) {
+	if orderValue.LessThan(config.MinOrderAmount) {
 		return &RiskCheckResult{
 			Allowed: false,
-			Reason:  fmt.Sprintf("订单金额太小，最小%s", rc.config.MinOrderAmount.String()),
+			Reason:  fmt.Sprintf("订单金额太小，最小%s", config.MinOrderAmount.String()),
 			Code:    "ORDER_TOO_SMALL",
 		}
 	}
 
-	if orderValue.GreaterThan(rc.config.MaxOrderAmount) {
+	if orderValue.GreaterThan(config.MaxOrderAmount) {
 		return &RiskCheckResult{
 			Allowed: false,
-			Reason:  fmt.Sprintf("订单金额过大，最大%s", rc.config.MaxOrderAmount.String()),
+			Reason:  fmt.Sprintf("订单金额过大，最大%s", config.MaxOrderAmount.String()),
 			Code:    "ORDER_TOO_LARGE",
 		}
 	}
@@ -142,27 +222,63 @@ func (rc *RiskController) checkOrderAmount(order *types.Order) *RiskCheckResult
 
 // checkPriceDeviation 检查价格偏差
 func (rc *RiskController) checkPriceDeviation(order *types.Order) *RiskCheckResult {
-	// 这里需要获取市场价格（从缓存或外部API）
-	// 简化处理，假设市场价格为1000
-	marketPrice := decimal.NewFromInt(1000)
+	// 市价单没有委托价格，无需检查
+	if order.Type == types.OrderTypeMarket {
+		return &RiskCheckResult{Allowed: true}
+	}
+
+	rc.mu.RLock()
+	priceOracle := rc.oracle
+	rc.mu.RUnlock()
 
+	if priceOracle == nil {
+		return &RiskCheckResult{Allowed: true}
+	}
+
+	quote, err := priceOracle.GetPrice(order.TradingPair)
+	if err != nil {
+		// 无参考价格（新交易对或价格过期）时默认允许
+		rc.logger.WithError(err).WithField("trading_pair", order.TradingPair).Warn("No reference price for deviation check")
+		return &RiskCheckResult{Allowed: true}
+	}
+
+	marketPrice := quote.Price
+	config := rc.EffectiveConfig(order.TradingPair)
 	deviation := order.Price.Sub(marketPrice).Div(marketPrice).Abs()
-	maxDeviation := rc.config.MaxPriceDeviation.Div(decimal.NewFromInt(100))
+	maxDeviation := config.MaxPriceDeviation.Div(decimal.NewFromInt(100))
 
 	if deviation.GreaterThan(maxDeviation) {
 		return &RiskCheckResult{
 			Allowed: false,
-			Reason:  fmt.Sprintf("价格偏差过大：%.2f%%，最大允许%.2f%%", deviation.Mul(decimal.NewFromInt(100)), rc.config.MaxPriceDeviation),
-			Code:    "PRICE_DEVIATION_TOO_LARGE",
+			Reason: fmt.Sprintf("价格偏差过大：%s%%（参考价%s，来源%s），最大允许%s%%",
+				deviation.Mul(decimal.NewFromInt(100)).StringFixed(2), marketPrice.String(), quote.Source, config.MaxPriceDeviation.StringFixed(2)),
+			Code: "PRICE_DEVIATION_TOO_LARGE",
 		}
 	}
 
 	return &RiskCheckResult{Allowed: true}
 }
 
+// referencePrice 获取参考价格
+func (rc *RiskController) referencePrice(tradingPair string) (decimal.Decimal, bool) {
+	rc.mu.RLock()
+	priceOracle := rc.oracle
+	rc.mu.RUnlock()
+
+	if priceOracle == nil {
+		return decimal.Zero, false
+	}
+	quote, err := priceOracle.GetPrice(tradingPair)
+	if err != nil {
+		return decimal.Zero, false
+	}
+	return quote.Price, true
+}
+
 // checkOrderRate 检查订单限率
 func (rc *RiskController) checkOrderRate(userAddress string) *RiskCheckResult {
-	allowed, err := rc.cache.RateLimitCheck(userAddress, "order", rc.config.OrderRateLimit, rc.config.RateLimitWindow)
+	config := rc.Config()
+	allowed, err := rc.limiter.RateLimitCheck(userAddress, "order", config.OrderRateLimit, config.RateLimitWindow)
 	if err != nil {
 		rc.logger.WithError(err).Error("Failed to check order rate limit")
 		// 错误时默认允许
@@ -172,7 +288,7 @@ func (rc *RiskController) checkOrderRate(userAddress string) *RiskCheckResult {
 	if !allowed {
 		return &RiskCheckResult{
 			Allowed: false,
-			Reason:  fmt.Sprintf("订单频率过高，最大%d次/%s", rc.config.OrderRateLimit, rc.config.RateLimitWindow.String()),
+			Reason:  fmt.Sprintf("订单频率过高，最大%d次/%s", config.OrderRateLimit, config.RateLimitWindow.String()),
 			Code:    "ORDER_RATE_LIMIT_EXCEEDED",
 		}
 	}
@@ -182,14 +298,19 @@ func (rc *RiskController) checkOrderRate(userAddress string) *RiskCheckResult {
 
 // checkUserOrderCount 检查用户订单数量
 func (rc *RiskController) checkUserOrderCount(userAddress string) *RiskCheckResult {
-	// 这里需要从数据库查询用户当前活跃订单数
-	// 简化处理，假设当前有订单0个
-	currentOrderCount := 0
+	rc.mu.RLock()
+	counter := rc.orderCounter
+	rc.mu.RUnlock()
+	if counter == nil {
+		return &RiskCheckResult{Allowed: true}
+	}
 
-	if currentOrderCount >= rc.config.MaxOrdersPerUser {
+	maxOrders := rc.Config().MaxOrdersPerUser
+	currentOrderCount := counter.ActiveOrderCount(userAddress)
+	if currentOrderCount >= maxOrders {
 		return &RiskCheckResult{
 			Allowed: false,
-			Reason:  fmt.Sprintf("用户订单数过多，最大%d个", rc.config.MaxOrdersPerUser),
+			Reason:  fmt.Sprintf("用户订单数过多，最大%d个", maxOrders),
 			Code:    "TOO_MANY_ORDERS",
 		}
 	}
@@ -240,10 +361,11 @@ func (rc *RiskController) checkOrderValidity(order *types.Order) *RiskCheckResul
 
 	// 检查订单时间是否过久
 	orderAge := time.Since(order.CreatedAt)
-	if orderAge > rc.config.OrderValidityPeriod {
+	validityPeriod := rc.Config().OrderValidityPeriod
+	if orderAge > validityPeriod {
 		return &RiskCheckResult{
 			Allowed: false,
-			Reason:  fmt.Sprintf("订单时间过久：%s，最大允许%s", orderAge.String(), rc.config.OrderValidityPeriod.String()),
+			Reason:  fmt.Sprintf("订单时间过久：%s，最大允许%s", orderAge.String(), validityPeriod.String()),
 			Code:    "ORDER_TOO_OLD",
 		}
 	}
@@ -263,7 +385,8 @@ func (rc *RiskController) CheckCancelRisk(userAddress string, orderID string) *R
 	}
 
 	// 2. 检查取消限率
-	allowed, err := rc.cache.RateLimitCheck(userAddress, "cancel", rc.config.CancelRateLimit, rc.config.RateLimitWindow)
+	config := rc.Config()
+	allowed, err := rc.limiter.RateLimitCheck(userAddress, "cancel", config.CancelRateLimit, config.RateLimitWindow)
 	if err != nil {
 		rc.logger.WithError(err).Error("Failed to check cancel rate limit")
 		return &RiskCheckResult{Allowed: true}
@@ -272,7 +395,7 @@ func (rc *RiskController) CheckCancelRisk(userAddress string, orderID string) *R
 	if !allowed {
 		return &RiskCheckResult{
 			Allowed: false,
-			Reason:  fmt.Sprintf("取消频率过高，最大%d次/%s", rc.config.CancelRateLimit, rc.config.RateLimitWindow.String()),
+			Reason:  fmt.Sprintf("取消频率过高，最大%d次/%s", config.CancelRateLimit, config.RateLimitWindow.String()),
 			Code:    "CANCEL_RATE_LIMIT_EXCEEDED",
 		}
 	}
@@ -285,16 +408,29 @@ func (rc *RiskController) CheckCancelRisk(userAddress string, orderID string) *R
 	return &RiskCheckResult{Allowed: true}
 }
 
-// checkCancelRatio 检查取消率
+// checkCancelRatio 检查取消率，未设置订单流统计时不检查
 func (rc *RiskController) checkCancelRatio(userAddress string) *RiskCheckResult {
-	// 这里需要从数据库查询用户的订单和取消统计
-	// 简化处理，假设取消率为10%
-	cancelRatio := decimal.NewFromFloat(0.1)
+	rc.mu.RLock()
+	source := rc.orderFlow
+	rc.mu.RUnlock()
+	if source == nil {
+		return &RiskCheckResult{Allowed: true}
+	}
+	config := rc.Config()
+	placed, cancelled := source.CancelCounts(userAddress, config.CancelRatioWindow)
+
+	// 样本太少时不检查，避免误伤刚开始交易的用户
+	if placed == 0 || placed < config.CancelRatioMinOrders {
+		return &RiskCheckResult{Allowed: true}
+	}
+
+	// 计入本次取消
+	cancelRatio := decimal.NewFromInt(int64(cancelled + 1)).Div(decimal.NewFromInt(int64(placed)))
 
-	if cancelRatio.GreaterThan(rc.config.MaxCancelRatio) {
+	if cancelRatio.GreaterThan(config.MaxCancelRatio) {
 		return &RiskCheckResult{
 			Allowed: false,
-			Reason:  fmt.Sprintf("取消率过高：%.2f%%，最大允许%.2f%%", cancelRatio.Mul(decimal.NewFromInt(100)), rc.config.MaxCancelRatio.Mul(decimal.NewFromInt(100))),
+			Reason:  fmt.Sprintf("取消率过高：%s%%，最大允许%s%%", cancelRatio.Mul(decimal.NewFromInt(100)).StringFixed(2), config.MaxCancelRatio.Mul(decimal.NewFromInt(100)).StringFixed(2)),
 			Code:    "CANCEL_RATIO_TOO_HIGH",
 		}
 	}
@@ -302,10 +438,10 @@ func (rc *RiskController) checkCancelRatio(userAddress string) *RiskCheckResult
 	return &RiskCheckResult{Allowed: true}
 }
 
-// AddToBlacklist 添加到黑名单
-func (rc *RiskController) AddToBlacklist(userAddress string, reason string, duration time.Duration) error {
+// AddToBlacklist 添加到黑名单，重复添加覆盖原因与到期时间
+// 持久化失败时条目仍在内存中生效并返回错误
+func (rc *RiskController) AddToBlacklist(userAddress string, reason string, duration time.Duration) (*BlacklistEntry, error) {
 	rc.mu.Lock()
-	defer rc.mu.Unlock()
 
 	entry := &BlacklistEntry{
 		UserAddress: userAddress,
@@ -314,11 +450,14 @@ func (rc *RiskController) AddToBlacklist(userAddress string, reason string, dura
 		ExpiresAt:   time.Now().Add(duration),
 	}
 
-	rc.blacklist[userAddress] = entry
+	rc.blacklist[strings.ToLower(userAddress)] = entry
+	persistErr := rc.persistBlacklistLocked()
 
 	// 同步到 Redis
-	if err := rc.cache.AddToBlacklist(userAddress, reason, duration); err != nil {
-		rc.logger.WithError(err).Error("Failed to add to Redis blacklist")
+	if rc.cache != nil {
+		if err := rc.cache.AddToBlacklist(userAddress, reason, duration); err != nil {
+			rc.logger.WithError(err).Error("Failed to add to Redis blacklist")
+		}
 	}
 
 	rc.logger.WithFields(logrus.Fields{
@@ -327,34 +466,65 @@ func (rc *RiskController) AddToBlacklist(userAddress string, reason string, dura
 		"duration":     duration.String(),
 	}).Warn("User added to blacklist")
 
-	return nil
+	onAlert := rc.onAlert
+	rc.mu.Unlock()
+
+	if onAlert != nil {
+		expiresAt := entry.ExpiresAt
+		onAlert(&types.RiskAlert{
+			UserAddress: userAddress,
+			Type:        types.RiskAlertBlacklisted,
+			Reason:      reason,
+			ExpiresAt:   &expiresAt,
+			Timestamp:   entry.CreatedAt,
+		})
+	}
+	copied := *entry
+	return &copied, persistErr
 }
 
-// RemoveFromBlacklist 从黑名单移除
-func (rc *RiskController) RemoveFromBlacklist(userAddress string) {
+// RemoveFromBlacklist 从黑名单移除，不在黑名单中时返回 false
+func (rc *RiskController) RemoveFromBlacklist(userAddress string) (bool, error) {
+	key := strings.ToLower(userAddress)
 	rc.mu.Lock()
-	defer rc.mu.Unlock()
+	_, exists := rc.blacklist[key]
+	delete(rc.blacklist, key)
+	var persistErr error
+	if exists {
+		persistErr = rc.persistBlacklistLocked()
+	}
+	onAlert := rc.onAlert
+	rc.mu.Unlock()
 
-	delete(rc.blacklist, userAddress)
+	if !exists {
+		return false, nil
+	}
 	rc.logger.WithField("user_address", userAddress).Info("User removed from blacklist")
+	if onAlert != nil {
+		onAlert(&types.RiskAlert{
+			UserAddress: userAddress,
+			Type:        types.RiskAlertBlacklistRemoved,
+			Timestamp:   time.Now(),
+		})
+	}
+	return true, persistErr
 }
 
-// isBlacklisted 检查是否在黑名单中
+// isBlacklisted 检查是否在黑名单中，过期条目由定时清理移除
 func (rc *RiskController) isBlacklisted(userAddress string) bool {
 	rc.mu.RLock()
 	defer rc.mu.RUnlock()
 
 	// 先检查内存缓存
-	entry, exists := rc.blacklist[userAddress]
-	if exists {
-		if time.Now().Before(entry.ExpiresAt) {
-			return true
-		}
-		// 过期了，清理
-		delete(rc.blacklist, userAddress)
+	entry, exists := rc.blacklist[strings.ToLower(userAddress)]
+	if exists && time.Now().Before(entry.ExpiresAt) {
+		return true
 	}
 
 	// 检查 Redis
+	if rc.cache == nil {
+		return false
+	}
 	blacklisted, err := rc.cache.IsBlacklisted(userAddress)
 	if err != nil {
 		rc.logger.WithError(err).Error("Failed to check Redis blacklist")
@@ -366,13 +536,16 @@ func (rc *RiskController) isBlacklisted(userAddress string) bool {
 
 // AutoBlacklistCheck 自动黑名单检查
 func (rc *RiskController) AutoBlacklistCheck(userAddress string, violations []string) {
-	if !rc.config.AutoBlacklist {
+	config := rc.Config()
+	if !config.AutoBlacklist {
 		return
 	}
 
 	if len(violations) >= 3 { // 3次违规就拉黑
 		reason := fmt.Sprintf("多次违规: %v", violations)
-		rc.AddToBlacklist(userAddress, reason, rc.config.BlacklistDuration)
+		if _, err := rc.AddToBlacklist(userAddress, reason, config.BlacklistDuration); err != nil {
+			rc.logger.WithError(err).WithField("user_address", userAddress).Error("Failed to persist automatic blacklist entry")
+		}
 	}
 }
 
@@ -381,12 +554,13 @@ func (rc *RiskController) GetBlacklistStatus(userAddress string) (*BlacklistEntr
 	rc.mu.RLock()
 	defer rc.mu.RUnlock()
 
-	entry, exists := rc.blacklist[userAddress]
+	entry, exists := rc.blacklist[strings.ToLower(userAddress)]
 	if !exists || time.Now().After(entry.ExpiresAt) {
 		return nil, false
 	}
 
-	return entry, true
+	copied := *entry
+	return &copied, true
 }
 
 // GetRiskStats 获取风控统计
@@ -409,6 +583,7 @@ func (rc *RiskController) GetRiskStats() map[string]interface{} {
 		"max_order_amount": rc.config.MaxOrderAmount.String(),
 		"order_rate_limit": rc.config.OrderRateLimit,
 		"cancel_rate_limit": rc.config.CancelRateLimit,
+		"pair_overrides":    len(rc.pairConfigs),
 	}
 }
 
@@ -418,12 +593,17 @@ func (rc *RiskController) CleanupExpiredBlacklist() {
 	defer rc.mu.Unlock()
 
 	now := time.Now()
-	for userAddress, entry := range rc.blacklist {
+	removed := 0
+	for key, entry := range rc.blacklist {
 		if now.After(entry.ExpiresAt) {
-			delete(rc.blacklist, userAddress)
-			rc.logger.WithField("user_address", userAddress).Debug("Expired blacklist entry removed")
+			delete(rc.blacklist, key)
+			removed++
+			rc.logger.WithField("user_address", entry.UserAddress).Debug("Expired blacklist entry removed")
 		}
 	}
+	if removed > 0 {
+		rc.persistBlacklistLocked()
+	}
 }
 
 // StartCleanupTicker 启动清理定时器
@@ -449,6 +629,8 @@ func DefaultRiskConfig() *RiskConfig {
 		CancelRateLimit:   30,              // 30次/分钟
 		RateLimitWindow:   time.Minute,     // 1分钟窗口
 		MaxCancelRatio:    decimal.NewFromFloat(0.3), // 30%
+		CancelRatioWindow: time.Hour,       // 1小时窗口
+		CancelRatioMinOrders: 20,           // 至少20笔下单后检查
 
 		EnableBalanceCheck: true,
 		MinBalance:         decimal.NewFromFloat(0.001), // 0.001 ETH
@@ -0,0 +1,52 @@
+package riskcontrol
+
+import (
+	"fmt"
+
+	"github.com/shopspring/decimal"
+
+	"orderbook-engine/internal/types"
+)
+
+// ExposureSource 提供订单全部成交后的账户风险暴露（由 positions.Tracker 实现）
+type ExposureSource interface {
+	ProjectedExposure(order *types.Order, price decimal.Decimal) decimal.Decimal
+}
+
+// SetExposureSource 设置风险暴露来源，为空时不检查 MaxExposure
+func (rc *RiskController) SetExposureSource(source ExposureSource) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.exposure = source
+}
+
+// checkExposure 检查订单全部成交后的风险暴露是否超过 MaxExposure，MaxExposure 不为正时不限制
+func (rc *RiskController) checkExposure(order *types.Order) *RiskCheckResult {
+	rc.mu.RLock()
+	source := rc.exposure
+	maxExposure := rc.config.MaxExposure
+	rc.mu.RUnlock()
+	if source == nil || !maxExposure.IsPositive() {
+		return &RiskCheckResult{Allowed: true}
+	}
+
+	price := order.Price
+	if order.Type == types.OrderTypeMarket {
+		// 市价单按参考价格估算，无参考价格时跳过
+		refPrice, ok := rc.referencePrice(order.TradingPair)
+		if !ok {
+			return &RiskCheckResult{Allowed: true}
+		}
+		price = refPrice
+	}
+
+	projected := source.ProjectedExposure(order, price)
+	if projected.GreaterThan(maxExposure) {
+		return &RiskCheckResult{
+			Allowed: false,
+			Reason:  fmt.Sprintf("风险暴露过大：成交后%s，最大允许%s", projected.StringFixed(2), maxExposure.String()),
+			Code:    "EXPOSURE_LIMIT_EXCEEDED",
+		}
+	}
+	return &RiskCheckResult{Allowed: true}
+}
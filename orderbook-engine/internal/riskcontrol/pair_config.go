@@ -0,0 +1,114 @@
+package riskcontrol
+
+import (
+	"fmt"
+
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+)
+
+// PairRiskConfig 交易对风控覆盖配置
+// 为空的字段继承全局 RiskConfig
+type PairRiskConfig struct {
+	MinOrderAmount    *decimal.Decimal `json:"min_order_amount,omitempty"`    // 最小订单金额
+	MaxOrderAmount    *decimal.Decimal `json:"max_order_amount,omitempty"`    // 最大订单金额
+	MaxPriceDeviation *decimal.Decimal `json:"max_price_deviation,omitempty"` // 最大价格偏差(百分比)
+}
+
+// Validate 校验覆盖配置
+func (pc *PairRiskConfig) Validate() error {
+	if pc.MinOrderAmount != nil && pc.MinOrderAmount.IsNegative() {
+		return fmt.Errorf("min_order_amount must not be negative")
+	}
+	if pc.MaxOrderAmount != nil && !pc.MaxOrderAmount.IsPositive() {
+		return fmt.Errorf("max_order_amount must be positive")
+	}
+	if pc.MinOrderAmount != nil && pc.MaxOrderAmount != nil && pc.MinOrderAmount.GreaterThan(*pc.MaxOrderAmount) {
+		return fmt.Errorf("min_order_amount must not exceed max_order_amount")
+	}
+	if pc.MaxPriceDeviation != nil && !pc.MaxPriceDeviation.IsPositive() {
+		return fmt.Errorf("max_price_deviation must be positive")
+	}
+	return nil
+}
+
+// Config 获取当前全局风控配置
+func (rc *RiskController) Config() *RiskConfig {
+	rc.mu.RLock()
+	defer rc.mu.RUnlock()
+	return rc.config
+}
+
+// SetConfig 替换全局风控配置（热更新），交易对覆盖配置保持不变
+func (rc *RiskController) SetConfig(config *RiskConfig) {
+	rc.mu.Lock()
+	rc.config = config
+	rc.mu.Unlock()
+
+	rc.logger.Info("Global risk config updated")
+}
+
+// EffectiveConfig 获取交易对生效的风控配置（全局配置叠加交易对覆盖）
+func (rc *RiskController) EffectiveConfig(tradingPair string) *RiskConfig {
+	rc.mu.RLock()
+	defer rc.mu.RUnlock()
+
+	override, exists := rc.pairConfigs[tradingPair]
+	if !exists {
+		return rc.config
+	}
+
+	config := *rc.config
+	if override.MinOrderAmount != nil {
+		config.MinOrderAmount = *override.MinOrderAmount
+	}
+	if override.MaxOrderAmount != nil {
+		config.MaxOrderAmount = *override.MaxOrderAmount
+	}
+	if override.MaxPriceDeviation != nil {
+		config.MaxPriceDeviation = *override.MaxPriceDeviation
+	}
+	return &config
+}
+
+// SetPairConfig 设置交易对覆盖配置（立即生效）
+func (rc *RiskController) SetPairConfig(tradingPair string, pairConfig *PairRiskConfig) error {
+	if err := pairConfig.Validate(); err != nil {
+		return err
+	}
+
+	rc.mu.Lock()
+	rc.pairConfigs[tradingPair] = pairConfig
+	rc.mu.Unlock()
+
+	rc.logger.WithField("trading_pair", tradingPair).Info("Pair risk config updated")
+	return nil
+}
+
+// RemovePairConfig 删除交易对覆盖配置，恢复使用全局配置
+func (rc *RiskController) RemovePairConfig(tradingPair string) bool {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	if _, exists := rc.pairConfigs[tradingPair]; !exists {
+		return false
+	}
+	delete(rc.pairConfigs, tradingPair)
+
+	rc.logger.WithFields(logrus.Fields{
+		"trading_pair": tradingPair,
+	}).Info("Pair risk config removed")
+	return true
+}
+
+// GetPairConfigs 获取所有交易对覆盖配置
+func (rc *RiskController) GetPairConfigs() map[string]*PairRiskConfig {
+	rc.mu.RLock()
+	defer rc.mu.RUnlock()
+
+	result := make(map[string]*PairRiskConfig, len(rc.pairConfigs))
+	for pair, pairConfig := range rc.pairConfigs {
+		result[pair] = pairConfig
+	}
+	return result
+}
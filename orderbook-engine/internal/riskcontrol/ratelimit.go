@@ -0,0 +1,60 @@
+package riskcontrol
+
+import (
+	"sync"
+	"time"
+)
+
+// RateLimiter 按用户与动作计数的限率器（storage.RedisCache 已实现）
+type RateLimiter interface {
+	// RateLimitCheck 记录一次动作，窗口内次数超过 limit 时返回 false
+	RateLimitCheck(userAddress, action string, limit int, window time.Duration) (bool, error)
+}
+
+// memoryRateLimiter 进程内固定窗口限率器，未配置 Redis 时使用
+// 只有主实例接单，进程内计数即可覆盖全部下单与撤单
+type memoryRateLimiter struct {
+	mu        sync.Mutex
+	windows   map[string]*rateWindow // 用户:动作 -> 当前窗口
+	lastPrune time.Time
+}
+
+// rateWindow 固定窗口计数
+type rateWindow struct {
+	start time.Time
+	count int
+}
+
+func newMemoryRateLimiter() *memoryRateLimiter {
+	return &memoryRateLimiter{windows: make(map[string]*rateWindow)}
+}
+
+// RateLimitCheck 记录一次动作并检查窗口内次数，limit 不为正时不限制
+func (l *memoryRateLimiter) RateLimitCheck(userAddress, action string, limit int, window time.Duration) (bool, error) {
+	if limit <= 0 || window <= 0 {
+		return true, nil
+	}
+	now := time.Now()
+	key := userAddress + ":" + action
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// 每个窗口清理一次已结束的计数，避免不活跃用户的记录累积
+	if now.Sub(l.lastPrune) >= window {
+		for k, w := range l.windows {
+			if now.Sub(w.start) >= window {
+				delete(l.windows, k)
+			}
+		}
+		l.lastPrune = now
+	}
+
+	w := l.windows[key]
+	if w == nil || now.Sub(w.start) >= window {
+		w = &rateWindow{start: now}
+		l.windows[key] = w
+	}
+	w.count++
+	return w.count <= limit, nil
+}
@@ -0,0 +1,295 @@
+// Package session 用户凭证与会话管理
+// 统一管理API密钥和WebSocket连接，支持列举与即时吊销
+package session
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	ErrSessionNotFound   = errors.New("session not found")
+	ErrSessionRevoked    = errors.New("session revoked")
+	ErrSessionExpired    = errors.New("session expired")
+	ErrInvalidPermission = errors.New("invalid permission")
+)
+
+// Kind 会话类型
+type Kind string
+
+const (
+	KindAPIKey    Kind = "api_key"
+	KindWebSocket Kind = "websocket"
+)
+
+// Permission API密钥权限
+type Permission string
+
+const (
+	PermissionRead  Permission = "read"  // 查询订单、余额等用户私有数据
+	PermissionTrade Permission = "trade" // 下单、撤单及管理会话，包含 read
+)
+
+// ParsePermissions 解析权限列表，为空时默认只读
+func ParsePermissions(values []string) ([]Permission, error) {
+	if len(values) == 0 {
+		return []Permission{PermissionRead}, nil
+	}
+	permissions := make([]Permission, 0, len(values))
+	for _, value := range values {
+		switch permission := Permission(strings.ToLower(value)); permission {
+		case PermissionRead, PermissionTrade:
+			permissions = append(permissions, permission)
+		default:
+			return nil, ErrInvalidPermission
+		}
+	}
+	return permissions, nil
+}
+
+// Session 用户会话/凭证
+type Session struct {
+	ID             uuid.UUID    `json:"id"`
+	Kind           Kind         `json:"kind"`
+	UserAddress    string       `json:"user_address"`
+	Label          string       `json:"label,omitempty"`
+	KeyPrefix      string       `json:"key_prefix,omitempty"`  // API密钥前缀，便于用户识别
+	Permissions    []Permission `json:"permissions,omitempty"` // API密钥权限
+	CancelOnRevoke bool         `json:"cancel_on_revoke"`
+	CreatedAt      time.Time    `json:"created_at"`
+	ExpiresAt      *time.Time   `json:"expires_at,omitempty"`
+	LastUsedAt     *time.Time   `json:"last_used_at,omitempty"`
+	LastUsedIP     string       `json:"last_used_ip,omitempty"`
+	RevokedAt      *time.Time   `json:"revoked_at,omitempty"`
+
+	keyHash string
+	secret  string // HMAC签名密钥，校验请求签名需要原文
+}
+
+// IsActive 检查会话是否有效
+func (s *Session) IsActive() bool {
+	if s.RevokedAt != nil {
+		return false
+	}
+	return s.ExpiresAt == nil || time.Now().Before(*s.ExpiresAt)
+}
+
+// HasPermission 检查API密钥是否具有权限（trade 包含 read）
+func (s *Session) HasPermission(permission Permission) bool {
+	for _, granted := range s.Permissions {
+		if granted == permission || (granted == PermissionTrade && permission == PermissionRead) {
+			return true
+		}
+	}
+	return false
+}
+
+// RevokeHandler 会话吊销回调
+type RevokeHandler func(session *Session, cancelOrders bool)
+
+// Registry 会话注册表
+type Registry struct {
+	mu        sync.RWMutex
+	sessions  map[uuid.UUID]*Session
+	byKeyHash map[string]*Session
+	onRevoke  []RevokeHandler
+	logger    *logrus.Logger
+}
+
+// NewRegistry 创建会话注册表
+func NewRegistry(logger *logrus.Logger) *Registry {
+	return &Registry{
+		sessions:  make(map[uuid.UUID]*Session),
+		byKeyHash: make(map[string]*Session),
+		logger:    logger,
+	}
+}
+
+// OnRevoke 注册吊销回调（断开连接、撤单等）
+func (r *Registry) OnRevoke(handler RevokeHandler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.onRevoke = append(r.onRevoke, handler)
+}
+
+// CreateAPIKey 创建API密钥，明文密钥和签名密钥仅在创建时返回一次
+func (r *Registry) CreateAPIKey(userAddress, label string, permissions []Permission, ttl time.Duration, cancelOnRevoke bool) (*Session, string, string, error) {
+	key, err := randomToken("obk_")
+	if err != nil {
+		return nil, "", "", err
+	}
+	secret, err := randomToken("obs_")
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	session := r.newSession(KindAPIKey, userAddress, label, ttl, cancelOnRevoke)
+	session.KeyPrefix = key[:12]
+	session.Permissions = append([]Permission(nil), permissions...)
+	session.keyHash = hashKey(key)
+	session.secret = secret
+
+	r.mu.Lock()
+	r.sessions[session.ID] = session
+	r.byKeyHash[session.keyHash] = session
+	r.mu.Unlock()
+
+	r.logger.WithFields(logrus.Fields{
+		"session_id":   session.ID,
+		"user_address": userAddress,
+		"permissions":  permissions,
+	}).Info("API key created")
+
+	return session.snapshot(), key, secret, nil
+}
+
+// RegisterWebSocket 登记WebSocket会话
+func (r *Registry) RegisterWebSocket(userAddress, remoteAddr string) *Session {
+	session := r.newSession(KindWebSocket, userAddress, "", 0, false)
+	now := session.CreatedAt
+	session.LastUsedAt = &now
+	session.LastUsedIP = remoteAddr
+
+	r.mu.Lock()
+	r.sessions[session.ID] = session
+	r.mu.Unlock()
+
+	return session.snapshot()
+}
+
+// Remove 移除会话记录（WebSocket断开时调用）
+func (r *Registry) Remove(sessionID uuid.UUID) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if session, exists := r.sessions[sessionID]; exists {
+		delete(r.byKeyHash, session.keyHash)
+		delete(r.sessions, sessionID)
+	}
+}
+
+// activeAPIKeyLocked 按明文密钥查找有效的API密钥，调用方需持有锁
+func (r *Registry) activeAPIKeyLocked(key string) (*Session, error) {
+	session, exists := r.byKeyHash[hashKey(key)]
+	if !exists {
+		return nil, ErrSessionNotFound
+	}
+	if session.RevokedAt != nil {
+		return nil, ErrSessionRevoked
+	}
+	if !session.IsActive() {
+		return nil, ErrSessionExpired
+	}
+	return session, nil
+}
+
+// List 列出用户的会话（含已吊销、已过期的凭证）
+func (r *Registry) List(userAddress string) []*Session {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make([]*Session, 0)
+	for _, session := range r.sessions {
+		if strings.EqualFold(session.UserAddress, userAddress) {
+			result = append(result, session.snapshot())
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CreatedAt.After(result[j].CreatedAt)
+	})
+	return result
+}
+
+// Revoke 立即吊销会话
+// cancelOrders 为空时使用会话创建时的 CancelOnRevoke 设置
+func (r *Registry) Revoke(userAddress string, sessionID uuid.UUID, cancelOrders *bool) (*Session, error) {
+	r.mu.Lock()
+	session, exists := r.sessions[sessionID]
+	if !exists || !strings.EqualFold(session.UserAddress, userAddress) {
+		r.mu.Unlock()
+		return nil, ErrSessionNotFound
+	}
+	if session.RevokedAt != nil {
+		r.mu.Unlock()
+		return nil, ErrSessionRevoked
+	}
+
+	now := time.Now()
+	session.RevokedAt = &now
+	snapshot := session.snapshot()
+	handlers := append([]RevokeHandler(nil), r.onRevoke...)
+	r.mu.Unlock()
+
+	cancel := snapshot.CancelOnRevoke
+	if cancelOrders != nil {
+		cancel = *cancelOrders
+	}
+
+	r.logger.WithFields(logrus.Fields{
+		"session_id":    sessionID,
+		"kind":          snapshot.Kind,
+		"user_address":  snapshot.UserAddress,
+		"cancel_orders": cancel,
+	}).Warn("Session revoked")
+
+	for _, handler := range handlers {
+		handler(snapshot, cancel)
+	}
+
+	return snapshot, nil
+}
+
+func (r *Registry) newSession(kind Kind, userAddress, label string, ttl time.Duration, cancelOnRevoke bool) *Session {
+	session := &Session{
+		ID:             uuid.New(),
+		Kind:           kind,
+		UserAddress:    userAddress,
+		Label:          label,
+		CancelOnRevoke: cancelOnRevoke,
+		CreatedAt:      time.Now(),
+	}
+	if ttl > 0 {
+		expiresAt := session.CreatedAt.Add(ttl)
+		session.ExpiresAt = &expiresAt
+	}
+	return session
+}
+
+// touch 记录最近使用信息
+func (s *Session) touch(clientIP string) {
+	now := time.Now()
+	s.LastUsedAt = &now
+	s.LastUsedIP = clientIP
+}
+
+// snapshot 返回会话副本，避免调用方持有内部状态（不含密钥原文）
+func (s *Session) snapshot() *Session {
+	copied := *s
+	copied.Permissions = append([]Permission(nil), s.Permissions...)
+	copied.secret = ""
+	return &copied
+}
+
+func hashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// randomToken 生成带前缀的32字节随机令牌
+func randomToken(prefix string) (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return prefix + hex.EncodeToString(buf), nil
+}
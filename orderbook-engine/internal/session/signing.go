@@ -0,0 +1,85 @@
+package session
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidSignature = errors.New("invalid request signature")
+	ErrStaleTimestamp   = errors.New("request timestamp outside allowed window")
+)
+
+// SignRequest 计算API请求签名
+// 签名原文为 timestamp + METHOD + path（含查询串）+ body，结果为 HMAC-SHA256 的十六进制编码
+func SignRequest(secret, timestamp, method, path string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte(strings.ToUpper(method)))
+	mac.Write([]byte(path))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// AuthenticateRequest 校验API密钥与请求签名并记录最近使用信息
+// timestamp 为毫秒级Unix时间戳，与服务器时间相差超过 window 的请求视为重放
+func (r *Registry) AuthenticateRequest(key, timestamp, signature, method, path string, body []byte, window time.Duration, clientIP string) (*Session, error) {
+	millis, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return nil, ErrStaleTimestamp
+	}
+	if skew := time.Since(time.UnixMilli(millis)); skew > window || skew < -window {
+		return nil, ErrStaleTimestamp
+	}
+
+	provided, err := hex.DecodeString(signature)
+	if err != nil {
+		return nil, ErrInvalidSignature
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	session, err := r.activeAPIKeyLocked(key)
+	if err != nil {
+		return nil, err
+	}
+
+	expected, _ := hex.DecodeString(SignRequest(session.secret, timestamp, method, path, body))
+	if !hmac.Equal(provided, expected) {
+		return nil, ErrInvalidSignature
+	}
+
+	session.touch(clientIP)
+	return session.snapshot(), nil
+}
+
+// APIKeyMessage 创建API密钥时用户钱包签名（EIP-191 personal_sign）的消息
+// 证明请求方控制该地址；timestamp 为秒级Unix时间戳，限定签名的有效时间
+func APIKeyMessage(userAddress string, permissions []Permission, timestamp int64) string {
+	names := make([]string, len(permissions))
+	for i, permission := range permissions {
+		names[i] = string(permission)
+	}
+	return fmt.Sprintf("OrderBookEVM API key request\nAddress: %s\nPermissions: %s\nTimestamp: %d",
+		strings.ToLower(userAddress), strings.Join(names, ","), timestamp)
+}
+
+// 会话管理操作，签名消息中区分操作，列举会话的签名不能用于吊销
+const (
+	ActionListSessions  = "list_sessions"
+	ActionRevokeSession = "revoke_session"
+)
+
+// ManageMessage 未使用API密钥管理会话时用户钱包签名（EIP-191 personal_sign）的消息
+// target 为操作对象（吊销时为会话ID，列举时为空）；timestamp 为秒级Unix时间戳，限定签名的有效时间
+func ManageMessage(userAddress, action, target string, timestamp int64) string {
+	return fmt.Sprintf("OrderBookEVM session management\nAddress: %s\nAction: %s\nTarget: %s\nTimestamp: %d",
+		strings.ToLower(userAddress), action, target, timestamp)
+}
@@ -0,0 +1,68 @@
+package session
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"orderbook-engine/pkg/crypto"
+)
+
+func TestAuthenticateRequest(t *testing.T) {
+	registry := NewRegistry(logrus.New())
+	created, key, secret, err := registry.CreateAPIKey("0x1234567890123456789012345678901234567890", "bot", []Permission{PermissionRead}, 0, false)
+	require.NoError(t, err)
+	assert.Empty(t, created.secret)
+
+	body := []byte(`{"trading_pair":"WETH-USDC"}`)
+	path := "/api/v1/orders?user_address=0x1234567890123456789012345678901234567890"
+	timestamp := strconv.FormatInt(time.Now().UnixMilli(), 10)
+	signature := SignRequest(secret, timestamp, "POST", path, body)
+
+	authenticated, err := registry.AuthenticateRequest(key, timestamp, signature, "POST", path, body, 30*time.Second, "127.0.0.1")
+	require.NoError(t, err)
+	assert.Equal(t, created.ID, authenticated.ID)
+	assert.True(t, authenticated.HasPermission(PermissionRead))
+	assert.False(t, authenticated.HasPermission(PermissionTrade))
+
+	// 篡改请求体
+	_, err = registry.AuthenticateRequest(key, timestamp, signature, "POST", path, []byte(`{}`), 30*time.Second, "")
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+
+	// 超出时间窗口
+	stale := strconv.FormatInt(time.Now().Add(-time.Minute).UnixMilli(), 10)
+	_, err = registry.AuthenticateRequest(key, stale, SignRequest(secret, stale, "POST", path, body), "POST", path, body, 30*time.Second, "")
+	assert.ErrorIs(t, err, ErrStaleTimestamp)
+
+	// 吊销后拒绝
+	_, err = registry.Revoke(created.UserAddress, created.ID, nil)
+	require.NoError(t, err)
+	_, err = registry.AuthenticateRequest(key, timestamp, signature, "POST", path, body, 30*time.Second, "")
+	assert.ErrorIs(t, err, ErrSessionRevoked)
+}
+
+func TestManageMessageBindsAction(t *testing.T) {
+	key, err := ethcrypto.GenerateKey()
+	require.NoError(t, err)
+	address := ethcrypto.PubkeyToAddress(key.PublicKey).Hex()
+
+	timestamp := time.Now().Unix()
+	signature, err := ethcrypto.Sign(crypto.HashPersonalMessage(ManageMessage(address, ActionListSessions, "", timestamp)).Bytes(), key)
+	require.NoError(t, err)
+	signed := hexutil.Encode(signature)
+
+	valid, err := crypto.VerifyPersonalSignature(ManageMessage(address, ActionListSessions, "", timestamp), signed, address)
+	require.NoError(t, err)
+	assert.True(t, valid)
+
+	// 列举会话的签名不能用于吊销
+	valid, err = crypto.VerifyPersonalSignature(ManageMessage(address, ActionRevokeSession, "", timestamp), signed, address)
+	require.NoError(t, err)
+	assert.False(t, valid)
+}
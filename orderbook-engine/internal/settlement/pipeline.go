@@ -0,0 +1,223 @@
+package settlement
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"orderbook-engine/internal/matching"
+	"orderbook-engine/internal/types"
+)
+
+// Submitter 链上批量结算提交方（blockchain.SettlementManager）
+type Submitter interface {
+	SubmitFill(fill *types.Fill, takerOrder, makerOrder *types.Order) error
+}
+
+// OrderSource 订单查询（用于获取maker订单及签名）
+type OrderSource interface {
+	GetOrder(orderID uuid.UUID) (*types.Order, error)
+}
+
+// FillStore 成交记录更新（存储实现可选支持）
+type FillStore interface {
+	UpdateFill(fill *types.Fill) error
+}
+
+// Pipeline 结算流水线
+// 消费撮合成交事件，将双方均已签名的成交送入批量结算，并回写结算状态与交易哈希
+type Pipeline struct {
+	mu        sync.Mutex
+	submitter Submitter
+	orders    OrderSource
+	fills     FillStore
+	inflight  map[uuid.UUID]*inflightFill // 已提交、尚未确认的成交
+	onReject  RejectHandler               // 可选，结算被拒绝的成交回调
+	logger    *logrus.Logger
+}
+
+// RejectHandler 成交在提交前模拟执行失败、不再上链时的回调（回滚内部余额、通知双方用户）
+type RejectHandler func(fill *types.Fill, err error)
+
+// inflightFill 在途成交（私有副本）及双方用户
+type inflightFill struct {
+	fill  *types.Fill
+	users []string // taker、maker 地址（小写）
+}
+
+// NewPipeline 创建结算流水线
+func NewPipeline(submitter Submitter, orders OrderSource, logger *logrus.Logger) *Pipeline {
+	return &Pipeline{
+		submitter: submitter,
+		orders:    orders,
+		inflight:  make(map[uuid.UUID]*inflightFill),
+		logger:    logger,
+	}
+}
+
+// SetFillStore 设置成交记录存储，为空时结算状态仅保存在内存
+func (p *Pipeline) SetFillStore(store FillStore) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.fills = store
+}
+
+// SetRejectHandler 设置结算被拒绝的成交回调
+func (p *Pipeline) SetRejectHandler(handler RejectHandler) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.onReject = handler
+}
+
+// Run 消费撮合事件直到订阅关闭
+func (p *Pipeline) Run(sub *matching.Subscription) {
+	for event := range sub.Events() {
+		if (event.Type != matching.EventOrderAdded && event.Type != matching.EventAuctionUncrossed) || event.Order == nil {
+			continue
+		}
+		for _, fill := range event.Fills {
+			p.submit(fill, event.Order)
+		}
+	}
+}
+
+// submit 提交单笔成交，taker为事件中的订单快照，maker从存储中查询
+func (p *Pipeline) submit(fill *types.Fill, takerOrder *types.Order) {
+	logger := p.logger.WithFields(logrus.Fields{
+		"fill_id":        fill.ID.String(),
+		"taker_order_id": fill.TakerOrderID.String(),
+		"maker_order_id": fill.MakerOrderID.String(),
+	})
+
+	makerOrder, err := p.orders.GetOrder(fill.MakerOrderID)
+	if err != nil {
+		logger.WithError(err).Error("Failed to load maker order for settlement")
+		return
+	}
+
+	// 做市机器人等内部订单没有用户签名，无法链上结算
+	if takerOrder.Signature == "" || makerOrder.Signature == "" {
+		logger.Debug("Skipping settlement for fill with unsigned order")
+		return
+	}
+
+	// 成交指针与撮合返回值、其他消费者共享，使用副本记录结算状态
+	record := *fill
+	record.SettlementStatus = types.SettlementStatusPending
+
+	p.mu.Lock()
+	p.inflight[record.ID] = &inflightFill{
+		fill:  &record,
+		users: []string{strings.ToLower(takerOrder.UserAddress), strings.ToLower(makerOrder.UserAddress)},
+	}
+	p.mu.Unlock()
+
+	if err := p.submitter.SubmitFill(&record, takerOrder, makerOrder); err != nil {
+		logger.WithError(err).Error("Failed to queue fill for settlement")
+		p.HandleStatus([]uuid.UUID{record.ID}, types.SettlementStatusFailed, "", err)
+		p.mu.Lock()
+		delete(p.inflight, record.ID)
+		p.mu.Unlock()
+		return
+	}
+
+	logger.Debug("Fill queued for settlement")
+}
+
+// HandleStatus 处理批量结算状态回调，更新并持久化成交记录
+func (p *Pipeline) HandleStatus(fillIDs []uuid.UUID, status types.SettlementStatus, txHash string, err error) {
+	p.mu.Lock()
+	updated := make([]*types.Fill, 0, len(fillIDs))
+	for _, id := range fillIDs {
+		entry, exists := p.inflight[id]
+		if !exists {
+			continue
+		}
+		record := entry.fill
+		record.SettlementStatus = status
+		if txHash != "" {
+			record.TxHash = txHash
+		}
+		// 失败的结算会重试或进入死信队列，保留在途记录直到确认、作废或被拒绝
+		switch status {
+		case types.SettlementStatusConfirmed, types.SettlementStatusVoided, types.SettlementStatusRejected:
+			delete(p.inflight, id)
+		}
+		copied := *record
+		updated = append(updated, &copied)
+	}
+	store := p.fills
+	onReject := p.onReject
+	p.mu.Unlock()
+
+	if status == types.SettlementStatusRejected && onReject != nil {
+		for _, fill := range updated {
+			onReject(fill, err)
+		}
+	}
+
+	fields := logrus.Fields{
+		"fills":   len(updated),
+		"status":  status,
+		"tx_hash": txHash,
+	}
+	if err != nil {
+		p.logger.WithFields(fields).WithError(err).Warn("Settlement batch failed")
+	} else {
+		p.logger.WithFields(fields).Info("Settlement status updated")
+	}
+
+	if store == nil {
+		return
+	}
+	for _, fill := range updated {
+		if err := store.UpdateFill(fill); err != nil {
+			p.logger.WithError(err).WithField("fill_id", fill.ID.String()).Error("Failed to update fill settlement status")
+		}
+	}
+}
+
+// GetFill 获取在途成交的结算记录
+func (p *Pipeline) GetFill(fillID uuid.UUID) (*types.Fill, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	entry, exists := p.inflight[fillID]
+	if !exists {
+		return nil, false
+	}
+	copied := *entry.fill
+	return &copied, true
+}
+
+// UnsettledFills 获取用户尚未上链确认的成交（新的在前）
+func (p *Pipeline) UnsettledFills(userAddress string) []*types.Fill {
+	user := strings.ToLower(userAddress)
+
+	p.mu.Lock()
+	result := make([]*types.Fill, 0)
+	for _, entry := range p.inflight {
+		for _, u := range entry.users {
+			if u == user {
+				copied := *entry.fill
+				result = append(result, &copied)
+				break
+			}
+		}
+	}
+	p.mu.Unlock()
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CreatedAt.After(result[j].CreatedAt)
+	})
+	return result
+}
+
+// PendingCount 在途成交数量
+func (p *Pipeline) PendingCount() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.inflight)
+}
@@ -0,0 +1,193 @@
+package settlement
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"orderbook-engine/internal/matching"
+	"orderbook-engine/internal/types"
+)
+
+// recordingSubmitter 按提交顺序记录成交，err 不为空时拒绝提交
+type recordingSubmitter struct {
+	mu    sync.Mutex
+	fills []*types.Fill
+	err   error
+}
+
+func (s *recordingSubmitter) SubmitFill(fill *types.Fill, takerOrder, makerOrder *types.Order) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+	s.fills = append(s.fills, fill)
+	return nil
+}
+
+func (s *recordingSubmitter) submitted() []*types.Fill {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*types.Fill(nil), s.fills...)
+}
+
+// orderMap 订单查询
+type orderMap map[uuid.UUID]*types.Order
+
+func (m orderMap) GetOrder(orderID uuid.UUID) (*types.Order, error) {
+	order, exists := m[orderID]
+	if !exists {
+		return nil, errors.New("order not found")
+	}
+	return order, nil
+}
+
+// memoryFillStore 记录每笔成交最后一次回写的状态
+type memoryFillStore struct {
+	mu    sync.Mutex
+	fills map[uuid.UUID]types.Fill
+}
+
+func (s *memoryFillStore) UpdateFill(fill *types.Fill) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fills[fill.ID] = *fill
+	return nil
+}
+
+func (s *memoryFillStore) get(id uuid.UUID) types.Fill {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.fills[id]
+}
+
+func newOrder(user string, side types.OrderSide, price int64, signature string) *types.Order {
+	return &types.Order{
+		ID:          uuid.New(),
+		UserAddress: user,
+		TradingPair: "WETH-USDC",
+		Side:        side,
+		Type:        types.OrderTypeLimit,
+		Price:       decimal.NewFromInt(price),
+		Amount:      decimal.NewFromInt(1),
+		Signature:   signature,
+		Status:      types.OrderStatusPending,
+		CreatedAt:   time.Now(),
+	}
+}
+
+func newTestPipeline(orders orderMap) (*Pipeline, *recordingSubmitter, *memoryFillStore) {
+	submitter := &recordingSubmitter{}
+	store := &memoryFillStore{fills: make(map[uuid.UUID]types.Fill)}
+	pipeline := NewPipeline(submitter, orders, logrus.New())
+	pipeline.SetFillStore(store)
+	return pipeline, submitter, store
+}
+
+func TestPipelineSubmitsSignedFillsInMatchOrder(t *testing.T) {
+	engine := matching.NewMatchingEngine(logrus.New())
+	orders := orderMap{}
+	pipeline, submitter, _ := newTestPipeline(orders)
+	go pipeline.Run(engine.Subscribe(matching.SubscriptionOptions{Name: "settlement"}))
+
+	// 未签名的内部挂单不上链，其余按撮合顺序提交
+	makers := []*types.Order{
+		newOrder("0xbot", types.OrderSideSell, 2000, ""),
+		newOrder("0xMaker1", types.OrderSideSell, 2001, "0x01"),
+		newOrder("0xmaker2", types.OrderSideSell, 2002, "0x02"),
+		newOrder("0xmaker1", types.OrderSideSell, 2003, "0x03"),
+	}
+	for _, maker := range makers {
+		orders[maker.ID] = maker
+		_, err := engine.AddOrder(maker)
+		require.NoError(t, err)
+	}
+	taker := newOrder("0xtaker", types.OrderSideBuy, 2003, "0x04")
+	taker.Amount = decimal.NewFromInt(4)
+	fills, err := engine.AddOrder(taker)
+	require.NoError(t, err)
+	require.Len(t, fills, 4)
+
+	require.Eventually(t, func() bool { return len(submitter.submitted()) == 3 }, time.Second, 10*time.Millisecond)
+	submitted := submitter.submitted()
+	for i, fill := range submitted {
+		assert.Equal(t, fills[i+1].ID, fill.ID)
+		assert.Equal(t, types.SettlementStatusPending, fill.SettlementStatus)
+	}
+	// 撮合返回的成交不被结算状态修改
+	assert.Empty(t, fills[1].SettlementStatus)
+
+	assert.Equal(t, 3, pipeline.PendingCount())
+	_, tracked := pipeline.GetFill(fills[0].ID)
+	assert.False(t, tracked)
+	assert.Len(t, pipeline.UnsettledFills("0xTAKER"), 3)
+	assert.Len(t, pipeline.UnsettledFills("0xmaker1"), 2)
+}
+
+func TestPipelineKeepsFailedFillsForRetry(t *testing.T) {
+	maker := newOrder("0xmaker", types.OrderSideSell, 2000, "0x01")
+	taker := newOrder("0xtaker", types.OrderSideBuy, 2000, "0x02")
+	pipeline, _, store := newTestPipeline(orderMap{maker.ID: maker})
+
+	fill := &types.Fill{ID: uuid.New(), TakerOrderID: taker.ID, MakerOrderID: maker.ID, TradingPair: "WETH-USDC",
+		Price: decimal.NewFromInt(2000), Amount: decimal.NewFromInt(1)}
+	pipeline.submit(fill, taker)
+
+	// 失败的批次等待重试，成交保留在途
+	pipeline.HandleStatus([]uuid.UUID{fill.ID}, types.SettlementStatusFailed, "", errors.New("reverted"))
+	record, ok := pipeline.GetFill(fill.ID)
+	require.True(t, ok)
+	assert.Equal(t, types.SettlementStatusFailed, record.SettlementStatus)
+	assert.Equal(t, types.SettlementStatusFailed, store.get(fill.ID).SettlementStatus)
+
+	// 重试提交后确认，回写交易哈希并结束跟踪
+	pipeline.HandleStatus([]uuid.UUID{fill.ID}, types.SettlementStatusSubmitted, "0xabc", nil)
+	pipeline.HandleStatus([]uuid.UUID{fill.ID}, types.SettlementStatusConfirmed, "", nil)
+	_, ok = pipeline.GetFill(fill.ID)
+	assert.False(t, ok)
+	stored := store.get(fill.ID)
+	assert.Equal(t, types.SettlementStatusConfirmed, stored.SettlementStatus)
+	assert.Equal(t, "0xabc", stored.TxHash)
+
+	// 已结束跟踪的成交不再回写
+	pipeline.HandleStatus([]uuid.UUID{fill.ID}, types.SettlementStatusFailed, "", nil)
+	assert.Equal(t, types.SettlementStatusConfirmed, store.get(fill.ID).SettlementStatus)
+}
+
+func TestPipelineRejectsAndSubmitErrors(t *testing.T) {
+	maker := newOrder("0xmaker", types.OrderSideSell, 2000, "0x01")
+	taker := newOrder("0xtaker", types.OrderSideBuy, 2000, "0x02")
+	pipeline, submitter, store := newTestPipeline(orderMap{maker.ID: maker})
+	var rejected []uuid.UUID
+	pipeline.SetRejectHandler(func(fill *types.Fill, err error) {
+		rejected = append(rejected, fill.ID)
+	})
+
+	// 模拟执行失败的成交回调后不再跟踪
+	fill := &types.Fill{ID: uuid.New(), TakerOrderID: taker.ID, MakerOrderID: maker.ID}
+	pipeline.submit(fill, taker)
+	pipeline.HandleStatus([]uuid.UUID{fill.ID}, types.SettlementStatusRejected, "", errors.New("simulation failed"))
+	assert.Equal(t, []uuid.UUID{fill.ID}, rejected)
+	assert.Zero(t, pipeline.PendingCount())
+
+	// 无法进入结算队列的成交记为失败
+	submitter.err = errors.New("queue full")
+	queued := &types.Fill{ID: uuid.New(), TakerOrderID: taker.ID, MakerOrderID: maker.ID}
+	pipeline.submit(queued, taker)
+	assert.Zero(t, pipeline.PendingCount())
+	assert.Equal(t, types.SettlementStatusFailed, store.get(queued.ID).SettlementStatus)
+
+	// maker 订单查询失败时跳过
+	missing := &types.Fill{ID: uuid.New(), TakerOrderID: taker.ID, MakerOrderID: uuid.New()}
+	submitter.err = nil
+	pipeline.submit(missing, taker)
+	assert.Len(t, submitter.submitted(), 1)
+}
@@ -0,0 +1,38 @@
+package settlement
+
+import (
+	"fmt"
+
+	"orderbook-engine/internal/types"
+)
+
+// ChainRouter 按订单所属的链将成交分发给对应链的结算提交方
+type ChainRouter struct {
+	submitters map[uint64]Submitter
+}
+
+// NewChainRouter 创建多链结算分发器
+func NewChainRouter() *ChainRouter {
+	return &ChainRouter{
+		submitters: make(map[uint64]Submitter),
+	}
+}
+
+// Add 注册链的结算提交方
+func (r *ChainRouter) Add(chainID uint64, submitter Submitter) {
+	r.submitters[chainID] = submitter
+}
+
+// SubmitFill 提交成交到taker订单所属链的结算队列
+// 交易对归属唯一的链，撮合双方必然在同一链上
+func (r *ChainRouter) SubmitFill(fill *types.Fill, takerOrder, makerOrder *types.Order) error {
+	if takerOrder.ChainID != makerOrder.ChainID {
+		return fmt.Errorf("fill %s crosses chains %d and %d", fill.ID, takerOrder.ChainID, makerOrder.ChainID)
+	}
+
+	submitter, exists := r.submitters[takerOrder.ChainID]
+	if !exists {
+		return fmt.Errorf("settlement not enabled on chain %d", takerOrder.ChainID)
+	}
+	return submitter.SubmitFill(fill, takerOrder, makerOrder)
+}
@@ -2,6 +2,7 @@ package settlement
 
 import (
 	"context"
+	"encoding/hex"
 	"fmt"
 	"math/big"
 	"sync"
@@ -11,10 +12,10 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
-	"github.com/shopspring/decimal"
 	"github.com/sirupsen/logrus"
 
 	"orderbook-engine/internal/types"
+	ordercrypto "orderbook-engine/pkg/crypto"
 )
 
 // SettlementSubmitter 结算提交器
@@ -24,6 +25,7 @@ type SettlementSubmitter struct {
 	client            *ethclient.Client
 	contractAddress   common.Address
 	privateKey        string
+	hasher            *ordercrypto.OrderSigner
 	batchSize         int
 	batchTimeout      time.Duration
 	pendingFills      []*types.Fill
@@ -49,6 +51,7 @@ func NewSettlementSubmitter(
 	client *ethclient.Client,
 	contractAddress common.Address,
 	privateKey string,
+	hasher *ordercrypto.OrderSigner,
 	batchSize int,
 	batchTimeout time.Duration,
 	logger *logrus.Logger,
@@ -57,6 +60,7 @@ func NewSettlementSubmitter(
 		client:          client,
 		contractAddress: contractAddress,
 		privateKey:      privateKey,
+		hasher:          hasher,
 		batchSize:       batchSize,
 		batchTimeout:    batchTimeout,
 		pendingFills:    make([]*types.Fill, 0, batchSize),
@@ -288,14 +292,12 @@ func (s *SettlementSubmitter) clearPendingData() {
 	s.orderSignatures = make(map[string]string)          // 清空签名缓存
 }
 
-// getOrderHash 获取订单哈希（简化版本）
+// getOrderHash 获取订单的EIP-712哈希（与下单去重、链上验证一致）
 func (s *SettlementSubmitter) getOrderHash(order *types.Order) string {
-	return fmt.Sprintf("%s-%s-%s-%d",
-		order.UserAddress,
-		order.TradingPair,
-		order.Price.String(),
-		order.Nonce,
-	)
+	if order.Hash != "" {
+		return order.Hash
+	}
+	return hex.EncodeToString(s.hasher.HashTypedOrder(ordercrypto.TypedOrderFromOrder(order)).Bytes())
 }
 
 // GetPendingCount 获取待处理的成交数量
@@ -0,0 +1,130 @@
+// Package stats 成交统计聚合
+// 从撮合事件中聚合跨市场的成交数据，供行情透明度相关接口使用
+package stats
+
+import (
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+
+	"orderbook-engine/internal/types"
+)
+
+// Config 聚合配置
+type Config struct {
+	LargeTradeMinNotional decimal.Decimal `json:"large_trade_min_notional"` // 记录为大额成交的最小名义价值（计价代币）
+	LargeTradeRetention   time.Duration   `json:"large_trade_retention"`    // 大额成交保留时长
+	MaxLargeTrades        int             `json:"max_large_trades"`         // 最多保留的大额成交数
+}
+
+// LargeTrade 大额成交
+type LargeTrade struct {
+	types.Trade
+	Notional decimal.Decimal `json:"notional"` // 成交价 × 成交量
+}
+
+// Aggregator 成交统计聚合器
+type Aggregator struct {
+	mu          sync.RWMutex
+	config      *Config
+	largeTrades []*LargeTrade // 按成交时间顺序追加
+	logger      *logrus.Logger
+}
+
+// NewAggregator 创建成交统计聚合器
+func NewAggregator(config *Config, logger *logrus.Logger) *Aggregator {
+	return &Aggregator{
+		config: config,
+		logger: logger,
+	}
+}
+
+// Record 记录一笔成交
+func (a *Aggregator) Record(fill *types.Fill) {
+	notional := fill.Price.Mul(fill.Amount)
+	if notional.LessThan(a.config.LargeTradeMinNotional) {
+		return
+	}
+
+	timestamp := fill.CreatedAt
+	if timestamp.IsZero() {
+		timestamp = time.Now()
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.largeTrades = append(a.largeTrades, &LargeTrade{
+		Trade: types.Trade{
+			ID:              fill.ID,
+			TradingPair:     fill.TradingPair,
+			Price:           fill.Price,
+			Amount:          fill.Amount,
+			Side:            fill.TakerSide,
+			Timestamp:       timestamp,
+			EngineTimestamp: fill.EngineTimestamp,
+		},
+		Notional: notional,
+	})
+	a.pruneLocked(time.Now())
+
+	a.logger.WithFields(logrus.Fields{
+		"trading_pair": fill.TradingPair,
+		"notional":     notional.String(),
+	}).Debug("🐋 Large trade recorded")
+}
+
+// LargeTrades 获取名义价值不低于 minNotional 的最近大额成交（新的在前）
+// minNotional 低于配置的记录阈值时按记录阈值生效；tradingPair 为空表示全部市场
+func (a *Aggregator) LargeTrades(minNotional decimal.Decimal, tradingPair string, limit int) []*LargeTrade {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	cutoff := time.Now().Add(-a.config.LargeTradeRetention)
+	result := make([]*LargeTrade, 0)
+	for i := len(a.largeTrades) - 1; i >= 0 && (limit <= 0 || len(result) < limit); i-- {
+		trade := a.largeTrades[i]
+		if trade.Timestamp.Before(cutoff) {
+			break
+		}
+		if trade.Notional.LessThan(minNotional) {
+			continue
+		}
+		if tradingPair != "" && trade.TradingPair != tradingPair {
+			continue
+		}
+		result = append(result, trade)
+	}
+	return result
+}
+
+// GetConfig 获取聚合配置
+func (a *Aggregator) GetConfig() *Config {
+	return a.config
+}
+
+// pruneLocked 移除超出保留时长或数量上限的记录
+func (a *Aggregator) pruneLocked(now time.Time) {
+	cutoff := now.Add(-a.config.LargeTradeRetention)
+	i := 0
+	for i < len(a.largeTrades) && a.largeTrades[i].Timestamp.Before(cutoff) {
+		i++
+	}
+	if excess := len(a.largeTrades) - i - a.config.MaxLargeTrades; a.config.MaxLargeTrades > 0 && excess > 0 {
+		i += excess
+	}
+	if i > 0 {
+		a.largeTrades = append([]*LargeTrade(nil), a.largeTrades[i:]...)
+	}
+}
+
+// DefaultConfig 默认聚合配置
+func DefaultConfig() *Config {
+	return &Config{
+		LargeTradeMinNotional: decimal.NewFromInt(100000),
+		LargeTradeRetention:   24 * time.Hour,
+		MaxLargeTrades:        1000,
+	}
+}
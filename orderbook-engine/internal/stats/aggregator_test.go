@@ -0,0 +1,81 @@
+package stats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"orderbook-engine/internal/types"
+)
+
+func newFill(tradingPair string, price, amount int64, at time.Time) *types.Fill {
+	return &types.Fill{
+		ID:          uuid.New(),
+		TradingPair: tradingPair,
+		Price:       decimal.NewFromInt(price),
+		Amount:      decimal.NewFromInt(amount),
+		TakerSide:   types.OrderSideBuy,
+		CreatedAt:   at,
+	}
+}
+
+func newTestAggregator(maxTrades int) *Aggregator {
+	return NewAggregator(&Config{
+		LargeTradeMinNotional: decimal.NewFromInt(10000),
+		LargeTradeRetention:   time.Hour,
+		MaxLargeTrades:        maxTrades,
+	}, logrus.New())
+}
+
+func TestLargeTradesFilterByNotionalAndPair(t *testing.T) {
+	aggregator := newTestAggregator(0)
+	now := time.Now()
+
+	// 名义价值低于记录阈值的成交不记录
+	aggregator.Record(newFill("WETH-USDC", 2000, 4, now))
+	aggregator.Record(newFill("WETH-USDC", 2000, 5, now))
+	aggregator.Record(newFill("WBTC-USDC", 60000, 1, now))
+	aggregator.Record(newFill("WETH-USDC", 2000, 50, now))
+
+	trades := aggregator.LargeTrades(decimal.Zero, "", 0)
+	require.Len(t, trades, 3)
+	// 新的在前
+	assert.True(t, trades[0].Notional.Equal(decimal.NewFromInt(100000)))
+	assert.True(t, trades[2].Notional.Equal(decimal.NewFromInt(10000)))
+	assert.Equal(t, types.OrderSideBuy, trades[0].Side)
+
+	assert.Len(t, aggregator.LargeTrades(decimal.NewFromInt(50000), "", 0), 2)
+	assert.Len(t, aggregator.LargeTrades(decimal.Zero, "WETH-USDC", 0), 2)
+	limited := aggregator.LargeTrades(decimal.Zero, "", 1)
+	require.Len(t, limited, 1)
+	assert.Equal(t, trades[0].ID, limited[0].ID)
+}
+
+func TestLargeTradesRetention(t *testing.T) {
+	aggregator := newTestAggregator(2)
+	now := time.Now()
+
+	// 超出保留时长的成交不返回，并在下次记录时清理
+	old := newFill("WETH-USDC", 2000, 10, now.Add(-2*time.Hour))
+	aggregator.Record(old)
+	assert.Empty(t, aggregator.LargeTrades(decimal.Zero, "", 0))
+
+	first := newFill("WETH-USDC", 2000, 10, now)
+	aggregator.Record(first)
+	assert.Len(t, aggregator.largeTrades, 1)
+
+	// 超过数量上限时丢弃最早的记录
+	second := newFill("WETH-USDC", 2000, 11, now)
+	third := newFill("WETH-USDC", 2000, 12, now)
+	aggregator.Record(second)
+	aggregator.Record(third)
+	trades := aggregator.LargeTrades(decimal.Zero, "", 0)
+	require.Len(t, trades, 2)
+	assert.Equal(t, third.ID, trades[0].ID)
+	assert.Equal(t, second.ID, trades[1].ID)
+}
@@ -0,0 +1,93 @@
+package storage
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+
+	"orderbook-engine/internal/types"
+)
+
+// OrderStore 订单存储
+type OrderStore interface {
+	CreateOrder(order *types.Order) error
+	GetOrder(orderID uuid.UUID) (*types.Order, error)
+	GetOrderByHash(hash string) (*types.Order, error)
+	UpdateOrder(order *types.Order) error
+	GetUserOrders(userAddress, tradingPair, status string, limit, offset int) ([]*types.Order, error)
+	GetActiveOrders(tradingPair string) ([]*types.Order, error)
+}
+
+// FillStore 成交存储
+type FillStore interface {
+	CreateFill(fill *types.Fill) error
+	GetOrderFills(orderID uuid.UUID) ([]*types.Fill, error)
+	GetUserFills(userAddress string, limit, offset int) ([]*types.Fill, error)
+	GetRecentFills(tradingPair string, limit int) ([]*types.Fill, error)
+}
+
+// StatsStore 统计存储
+type StatsStore interface {
+	GetTradingPairStats(tradingPair string, period time.Duration) (*TradingPairStats, error)
+	GetUserStats(userAddress string, period time.Duration) (*UserStats, error)
+}
+
+// Composite 按关注点组合不同后端的存储（如订单用 PostgreSQL、成交用 Timescale、统计用 Redis），实现 Storage
+// 只暴露 Storage 的方法；后端的可选能力（历史查询、结算状态回写等）需通过对应字段检查
+type Composite struct {
+	OrderStore
+	FillStore
+	StatsStore
+}
+
+var _ Storage = (*Composite)(nil)
+
+// NewComposite 创建组合存储
+func NewComposite(orders OrderStore, fills FillStore, stats StatsStore) *Composite {
+	return &Composite{OrderStore: orders, FillStore: fills, StatsStore: stats}
+}
+
+// HealthCheck 检查全部后端，同一后端只检查一次
+func (c *Composite) HealthCheck() error {
+	var errs []error
+	for _, backend := range c.backends() {
+		if checker, ok := backend.(interface{ HealthCheck() error }); ok {
+			if err := checker.HealthCheck(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Close 关闭全部后端，同一后端只关闭一次
+func (c *Composite) Close() error {
+	var errs []error
+	for _, backend := range c.backends() {
+		if closer, ok := backend.(interface{ Close() error }); ok {
+			if err := closer.Close(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// backends 去重后的后端
+func (c *Composite) backends() []interface{} {
+	var backends []interface{}
+	for _, backend := range []interface{}{c.OrderStore, c.FillStore, c.StatsStore} {
+		duplicate := false
+		for _, seen := range backends {
+			if seen == backend {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			backends = append(backends, backend)
+		}
+	}
+	return backends
+}
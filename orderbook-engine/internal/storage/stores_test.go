@@ -0,0 +1,72 @@
+package storage
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"orderbook-engine/internal/types"
+)
+
+// fakeBackend 记录调用次数的后端
+type fakeBackend struct {
+	orders      int
+	fills       int
+	stats       int
+	closed      int
+	healthError error
+}
+
+func (f *fakeBackend) CreateOrder(order *types.Order) error { f.orders++; return nil }
+func (f *fakeBackend) GetOrder(orderID uuid.UUID) (*types.Order, error) {
+	f.orders++
+	return nil, nil
+}
+func (f *fakeBackend) GetOrderByHash(hash string) (*types.Order, error) { return nil, nil }
+func (f *fakeBackend) UpdateOrder(order *types.Order) error             { return nil }
+func (f *fakeBackend) GetUserOrders(userAddress, tradingPair, status string, limit, offset int) ([]*types.Order, error) {
+	return nil, nil
+}
+func (f *fakeBackend) GetActiveOrders(tradingPair string) ([]*types.Order, error) { return nil, nil }
+func (f *fakeBackend) CreateFill(fill *types.Fill) error                          { f.fills++; return nil }
+func (f *fakeBackend) GetOrderFills(orderID uuid.UUID) ([]*types.Fill, error)     { return nil, nil }
+func (f *fakeBackend) GetUserFills(userAddress string, limit, offset int) ([]*types.Fill, error) {
+	return nil, nil
+}
+func (f *fakeBackend) GetRecentFills(tradingPair string, limit int) ([]*types.Fill, error) {
+	return nil, nil
+}
+func (f *fakeBackend) GetTradingPairStats(tradingPair string, period time.Duration) (*TradingPairStats, error) {
+	f.stats++
+	return &TradingPairStats{TradingPair: tradingPair}, nil
+}
+func (f *fakeBackend) GetUserStats(userAddress string, period time.Duration) (*UserStats, error) {
+	return nil, nil
+}
+func (f *fakeBackend) HealthCheck() error { return f.healthError }
+func (f *fakeBackend) Close() error       { f.closed++; return nil }
+
+func TestCompositeRoutesByConcern(t *testing.T) {
+	orders, fills := &fakeBackend{}, &fakeBackend{}
+	store := NewComposite(orders, fills, fills)
+
+	assert.NoError(t, store.CreateOrder(&types.Order{}))
+	assert.NoError(t, store.CreateFill(&types.Fill{}))
+	_, err := store.GetTradingPairStats("WETH-USDC", time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, orders.orders)
+	assert.Equal(t, 0, orders.fills)
+	assert.Equal(t, 1, fills.fills)
+	assert.Equal(t, 1, fills.stats)
+
+	// 成交与统计共用的后端只关闭一次
+	assert.NoError(t, store.Close())
+	assert.Equal(t, 1, orders.closed)
+	assert.Equal(t, 1, fills.closed)
+
+	fills.healthError = errors.New("timescale unavailable")
+	assert.ErrorIs(t, store.HealthCheck(), fills.healthError)
+}
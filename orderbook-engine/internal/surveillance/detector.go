@@ -0,0 +1,320 @@
+// Package surveillance 成交异常监控
+// 检测偏离指数价格的成交、异常放量和重复成交，产生运维告警并可联动熔断
+package surveillance
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+
+	"orderbook-engine/internal/oracle"
+	"orderbook-engine/internal/types"
+)
+
+// AlertType 告警类型
+type AlertType string
+
+const (
+	AlertIndexDeviation AlertType = "index_deviation" // 成交价偏离指数价格
+	AlertVolumeSpike    AlertType = "volume_spike"    // 单笔成交量异常放大
+	AlertRepeatedPrint  AlertType = "repeated_print"  // 短时间内重复的相同成交
+)
+
+// maxStoredAlerts 内存中保留的最近告警数
+const maxStoredAlerts = 1000
+
+// Config 异常检测阈值
+type Config struct {
+	MaxIndexDeviation     decimal.Decimal `json:"max_index_deviation"`     // 偏离指数价格阈值(百分比)，0表示不检查
+	VolumeSpikeMultiplier decimal.Decimal `json:"volume_spike_multiplier"` // 单笔成交量超过平均值的倍数，0表示不检查
+	VolumeMinSamples      int             `json:"volume_min_samples"`      // 计算平均成交量所需最少样本数
+	RepeatCount           int             `json:"repeat_count"`            // 相同价格和数量的成交次数阈值，0表示不检查
+	RepeatWindow          time.Duration   `json:"repeat_window"`           // 重复成交统计窗口
+	TripCircuitBreaker    bool            `json:"trip_circuit_breaker"`    // 发现异常时是否触发熔断
+	HaltDuration          time.Duration   `json:"halt_duration"`           // 触发熔断的暂停时长
+}
+
+// Alert 异常告警
+type Alert struct {
+	ID          uuid.UUID `json:"id"`
+	Type        AlertType `json:"type"`
+	TradingPair string    `json:"trading_pair"`
+	FillID      uuid.UUID `json:"fill_id"`
+	Price       string    `json:"price"`
+	Amount      string    `json:"amount"`
+	Message     string    `json:"message"`
+	Halted      bool      `json:"halted"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Halter 暂停交易对（由熔断器实现）
+type Halter interface {
+	Halt(tradingPair, reason string, duration time.Duration)
+}
+
+// printKey 成交价格+数量
+type printKey struct {
+	price  string
+	amount string
+}
+
+// marketState 单个交易对的统计
+type marketState struct {
+	avgVolume decimal.Decimal // 成交量指数移动平均
+	samples   int
+	prints    map[printKey][]time.Time
+}
+
+// Detector 成交异常检测器
+type Detector struct {
+	mu        sync.Mutex
+	defaults  *Config
+	overrides map[string]*Config
+	markets   map[string]*marketState
+	alerts    []*Alert
+	index     oracle.PriceOracle
+	halter    Halter
+	handlers  []func(alert *Alert)
+	logger    *logrus.Logger
+}
+
+// volumeAlpha 成交量移动平均系数
+var volumeAlpha = decimal.NewFromFloat(0.1)
+
+// NewDetector 创建异常检测器
+func NewDetector(config *Config, index oracle.PriceOracle, logger *logrus.Logger) *Detector {
+	return &Detector{
+		defaults:  config,
+		overrides: make(map[string]*Config),
+		markets:   make(map[string]*marketState),
+		index:     index,
+		logger:    logger,
+	}
+}
+
+// SetHalter 设置熔断联动
+func (d *Detector) SetHalter(halter Halter) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.halter = halter
+}
+
+// OnAlert 注册告警回调
+func (d *Detector) OnAlert(handler func(alert *Alert)) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.handlers = append(d.handlers, handler)
+}
+
+// SetMarketConfig 设置交易对阈值
+func (d *Detector) SetMarketConfig(tradingPair string, config *Config) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.overrides[tradingPair] = config
+}
+
+// GetMarketConfigs 获取默认阈值与交易对阈值
+func (d *Detector) GetMarketConfigs() (*Config, map[string]*Config) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	overrides := make(map[string]*Config, len(d.overrides))
+	for pair, config := range d.overrides {
+		overrides[pair] = config
+	}
+	return d.defaults, overrides
+}
+
+// GetAlerts 获取最近告警（新的在前）
+func (d *Detector) GetAlerts(tradingPair string, limit int) []*Alert {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	result := make([]*Alert, 0)
+	for i := len(d.alerts) - 1; i >= 0 && (limit <= 0 || len(result) < limit); i-- {
+		if tradingPair == "" || d.alerts[i].TradingPair == tradingPair {
+			result = append(result, d.alerts[i])
+		}
+	}
+	return result
+}
+
+// Inspect 检查一笔成交
+func (d *Detector) Inspect(fill *types.Fill) {
+	d.mu.Lock()
+	config := d.configFor(fill.TradingPair)
+	index := d.index
+	d.mu.Unlock()
+
+	var alerts []*Alert
+
+	// 指数价格查询可能涉及网络请求，不持有锁
+	if config.MaxIndexDeviation.IsPositive() && index != nil {
+		if alert := d.checkIndexDeviation(fill, config, index); alert != nil {
+			alerts = append(alerts, alert)
+		}
+	}
+
+	d.mu.Lock()
+	market := d.getMarketLocked(fill.TradingPair)
+	if alert := d.checkVolumeSpikeLocked(fill, config, market); alert != nil {
+		alerts = append(alerts, alert)
+	}
+	if alert := d.checkRepeatedPrintLocked(fill, config, market); alert != nil {
+		alerts = append(alerts, alert)
+	}
+	d.mu.Unlock()
+
+	for _, alert := range alerts {
+		d.raise(alert, config)
+	}
+}
+
+func (d *Detector) checkIndexDeviation(fill *types.Fill, config *Config, index oracle.PriceOracle) *Alert {
+	quote, err := index.GetPrice(fill.TradingPair)
+	if err != nil || !quote.Price.IsPositive() {
+		return nil
+	}
+
+	deviation := fill.Price.Sub(quote.Price).Abs().Div(quote.Price).Mul(decimal.NewFromInt(100))
+	if deviation.LessThanOrEqual(config.MaxIndexDeviation) {
+		return nil
+	}
+
+	return newAlert(AlertIndexDeviation, fill, fmt.Sprintf("print %s deviates %s%% from index %s (%s)",
+		fill.Price.String(), deviation.StringFixed(2), quote.Price.String(), quote.Source))
+}
+
+func (d *Detector) checkVolumeSpikeLocked(fill *types.Fill, config *Config, market *marketState) *Alert {
+	var alert *Alert
+	if config.VolumeSpikeMultiplier.IsPositive() && market.samples >= config.VolumeMinSamples && market.avgVolume.IsPositive() {
+		threshold := market.avgVolume.Mul(config.VolumeSpikeMultiplier)
+		if fill.Amount.GreaterThan(threshold) {
+			alert = newAlert(AlertVolumeSpike, fill, fmt.Sprintf("fill amount %s exceeds %sx average %s",
+				fill.Amount.String(), config.VolumeSpikeMultiplier.String(), market.avgVolume.StringFixed(8)))
+		}
+	}
+
+	// 更新移动平均
+	if market.samples == 0 {
+		market.avgVolume = fill.Amount
+	} else {
+		market.avgVolume = volumeAlpha.Mul(fill.Amount).Add(decimal.NewFromInt(1).Sub(volumeAlpha).Mul(market.avgVolume))
+	}
+	market.samples++
+	return alert
+}
+
+func (d *Detector) checkRepeatedPrintLocked(fill *types.Fill, config *Config, market *marketState) *Alert {
+	if config.RepeatCount <= 0 {
+		return nil
+	}
+
+	now := fill.CreatedAt
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	// 清理窗口外的记录
+	cutoff := now.Add(-config.RepeatWindow)
+	for key, times := range market.prints {
+		i := 0
+		for i < len(times) && times[i].Before(cutoff) {
+			i++
+		}
+		if i == len(times) {
+			delete(market.prints, key)
+		} else {
+			market.prints[key] = times[i:]
+		}
+	}
+
+	key := printKey{price: fill.Price.String(), amount: fill.Amount.String()}
+	market.prints[key] = append(market.prints[key], now)
+
+	count := len(market.prints[key])
+	if count != config.RepeatCount {
+		// 只在达到阈值时告警一次，避免刷屏
+		return nil
+	}
+
+	return newAlert(AlertRepeatedPrint, fill, fmt.Sprintf("%d identical prints (price %s, amount %s) within %s",
+		count, key.price, key.amount, config.RepeatWindow))
+}
+
+// raise 记录告警、通知回调并按配置触发熔断
+func (d *Detector) raise(alert *Alert, config *Config) {
+	d.mu.Lock()
+	halter := d.halter
+	d.mu.Unlock()
+
+	if config.TripCircuitBreaker && halter != nil {
+		halter.Halt(alert.TradingPair, "surveillance: "+alert.Message, config.HaltDuration)
+		alert.Halted = true
+	}
+
+	d.mu.Lock()
+	d.alerts = append(d.alerts, alert)
+	if len(d.alerts) > maxStoredAlerts {
+		d.alerts = d.alerts[len(d.alerts)-maxStoredAlerts:]
+	}
+	handlers := append([]func(*Alert){}, d.handlers...)
+	d.mu.Unlock()
+
+	d.logger.WithFields(logrus.Fields{
+		"alert_type":   alert.Type,
+		"trading_pair": alert.TradingPair,
+		"fill_id":      alert.FillID,
+		"halted":       alert.Halted,
+	}).Warn("🔎 Trade anomaly detected: " + alert.Message)
+
+	for _, handler := range handlers {
+		handler(alert)
+	}
+}
+
+func (d *Detector) configFor(tradingPair string) *Config {
+	if config, exists := d.overrides[tradingPair]; exists {
+		return config
+	}
+	return d.defaults
+}
+
+func (d *Detector) getMarketLocked(tradingPair string) *marketState {
+	market, exists := d.markets[tradingPair]
+	if !exists {
+		market = &marketState{prints: make(map[printKey][]time.Time)}
+		d.markets[tradingPair] = market
+	}
+	return market
+}
+
+func newAlert(alertType AlertType, fill *types.Fill, message string) *Alert {
+	return &Alert{
+		ID:          uuid.New(),
+		Type:        alertType,
+		TradingPair: fill.TradingPair,
+		FillID:      fill.ID,
+		Price:       fill.Price.String(),
+		Amount:      fill.Amount.String(),
+		Message:     message,
+		CreatedAt:   time.Now(),
+	}
+}
+
+// DefaultConfig 默认异常检测阈值
+func DefaultConfig() *Config {
+	return &Config{
+		MaxIndexDeviation:     decimal.NewFromInt(5),  // 偏离指数5%
+		VolumeSpikeMultiplier: decimal.NewFromInt(20), // 20倍平均成交量
+		VolumeMinSamples:      50,
+		RepeatCount:           10, // 1分钟内10笔相同成交
+		RepeatWindow:          time.Minute,
+		TripCircuitBreaker:    false,
+		HaltDuration:          5 * time.Minute,
+	}
+}
@@ -0,0 +1,155 @@
+package surveillance
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"orderbook-engine/internal/oracle"
+	"orderbook-engine/internal/types"
+)
+
+// staticIndex 固定指数价格，err 不为空时返回错误
+type staticIndex struct {
+	price decimal.Decimal
+	err   error
+}
+
+func (i *staticIndex) GetPrice(tradingPair string) (*oracle.PriceQuote, error) {
+	if i.err != nil {
+		return nil, i.err
+	}
+	return &oracle.PriceQuote{TradingPair: tradingPair, Price: i.price, Source: "test", UpdatedAt: time.Now()}, nil
+}
+
+func (i *staticIndex) Name() string {
+	return "test"
+}
+
+// recordingHalter 记录熔断调用
+type recordingHalter struct {
+	pairs     []string
+	durations []time.Duration
+}
+
+func (h *recordingHalter) Halt(tradingPair, reason string, duration time.Duration) {
+	h.pairs = append(h.pairs, tradingPair)
+	h.durations = append(h.durations, duration)
+}
+
+func newFill(tradingPair string, price, amount float64, at time.Time) *types.Fill {
+	return &types.Fill{
+		ID:          uuid.New(),
+		TradingPair: tradingPair,
+		Price:       decimal.NewFromFloat(price),
+		Amount:      decimal.NewFromFloat(amount),
+		CreatedAt:   at,
+	}
+}
+
+// indexOnly 只检查指数偏离
+func indexOnly() *Config {
+	return &Config{MaxIndexDeviation: decimal.NewFromInt(5), TripCircuitBreaker: true, HaltDuration: time.Minute}
+}
+
+func TestIndexDeviationTripsCircuitBreaker(t *testing.T) {
+	index := &staticIndex{price: decimal.NewFromInt(2000)}
+	detector := NewDetector(indexOnly(), index, logrus.New())
+	halter := &recordingHalter{}
+	detector.SetHalter(halter)
+	var notified []*Alert
+	detector.OnAlert(func(alert *Alert) { notified = append(notified, alert) })
+
+	// 偏离在阈值内不告警
+	detector.Inspect(newFill("WETH-USDC", 2100, 1, time.Now()))
+	assert.Empty(t, detector.GetAlerts("", 0))
+
+	// 偏离 10% 告警并触发熔断
+	detector.Inspect(newFill("WETH-USDC", 2200, 1, time.Now()))
+	alerts := detector.GetAlerts("", 0)
+	require.Len(t, alerts, 1)
+	assert.Equal(t, AlertIndexDeviation, alerts[0].Type)
+	assert.True(t, alerts[0].Halted)
+	assert.Equal(t, []string{"WETH-USDC"}, halter.pairs)
+	assert.Equal(t, []time.Duration{time.Minute}, halter.durations)
+	assert.Equal(t, alerts, notified)
+
+	// 指数价格不可用时跳过检查
+	index.err = errors.New("unavailable")
+	detector.Inspect(newFill("WETH-USDC", 3000, 1, time.Now()))
+	assert.Len(t, detector.GetAlerts("", 0), 1)
+}
+
+func TestVolumeSpikeRequiresSamples(t *testing.T) {
+	config := &Config{VolumeSpikeMultiplier: decimal.NewFromInt(5), VolumeMinSamples: 3}
+	detector := NewDetector(config, nil, logrus.New())
+	halter := &recordingHalter{}
+	detector.SetHalter(halter)
+
+	// 样本不足时不告警
+	detector.Inspect(newFill("WETH-USDC", 2000, 100, time.Now()))
+	assert.Empty(t, detector.GetAlerts("", 0))
+
+	other := "WBTC-USDC"
+	for i := 0; i < 3; i++ {
+		detector.Inspect(newFill(other, 60000, 1, time.Now()))
+	}
+	// 等于平均值的倍数不告警，平均值随之更新为 1.4
+	detector.Inspect(newFill(other, 60000, 5, time.Now()))
+	assert.Empty(t, detector.GetAlerts(other, 0))
+
+	detector.Inspect(newFill(other, 60000, 7.5, time.Now()))
+	alerts := detector.GetAlerts(other, 0)
+	require.Len(t, alerts, 1)
+	assert.Equal(t, AlertVolumeSpike, alerts[0].Type)
+	// 未开启熔断联动
+	assert.False(t, alerts[0].Halted)
+	assert.Empty(t, halter.pairs)
+}
+
+func TestRepeatedPrintAlertsOncePerWindow(t *testing.T) {
+	config := &Config{RepeatCount: 3, RepeatWindow: time.Minute}
+	detector := NewDetector(config, nil, logrus.New())
+	start := time.Now()
+
+	for i := 0; i < 4; i++ {
+		detector.Inspect(newFill("WETH-USDC", 2000, 1, start.Add(time.Duration(i)*time.Second)))
+	}
+	// 不同数量的成交单独计数
+	detector.Inspect(newFill("WETH-USDC", 2000, 2, start.Add(5*time.Second)))
+
+	// 达到阈值时只告警一次
+	alerts := detector.GetAlerts("WETH-USDC", 0)
+	require.Len(t, alerts, 1)
+	assert.Equal(t, AlertRepeatedPrint, alerts[0].Type)
+
+	// 窗口外的成交重新计数
+	later := start.Add(2 * time.Minute)
+	for i := 0; i < 3; i++ {
+		detector.Inspect(newFill("WETH-USDC", 2000, 1, later.Add(time.Duration(i)*time.Second)))
+	}
+	assert.Len(t, detector.GetAlerts("WETH-USDC", 0), 2)
+}
+
+func TestMarketConfigOverridesDefaults(t *testing.T) {
+	detector := NewDetector(indexOnly(), &staticIndex{price: decimal.NewFromInt(2000)}, logrus.New())
+	detector.SetMarketConfig("WETH-USDC", &Config{})
+
+	// 交易对阈值覆盖默认阈值
+	detector.Inspect(newFill("WETH-USDC", 3000, 1, time.Now()))
+	assert.Empty(t, detector.GetAlerts("", 0))
+	detector.Inspect(newFill("WBTC-USDC", 3000, 1, time.Now()))
+	detector.Inspect(newFill("WBTC-USDC", 1000, 1, time.Now()))
+
+	// 新的告警在前，按交易对与数量过滤
+	alerts := detector.GetAlerts("WBTC-USDC", 1)
+	require.Len(t, alerts, 1)
+	assert.Equal(t, "1000", alerts[0].Price)
+	assert.Empty(t, detector.GetAlerts("WETH-USDC", 0))
+}
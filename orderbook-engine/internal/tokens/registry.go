@@ -0,0 +1,210 @@
+// Package tokens 代币元数据注册表
+// 记录各链代币的符号和精度，链上整数数量与引擎 decimal 之间的换算统一通过注册表完成
+package tokens
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/shopspring/decimal"
+)
+
+// erc20MetadataABI ERC-20 元数据方法（仅包含用到的方法）
+const erc20MetadataABI = `[
+	{
+		"inputs": [],
+		"name": "decimals",
+		"outputs": [{"internalType": "uint8", "name": "", "type": "uint8"}],
+		"stateMutability": "view",
+		"type": "function"
+	},
+	{
+		"inputs": [],
+		"name": "symbol",
+		"outputs": [{"internalType": "string", "name": "", "type": "string"}],
+		"stateMutability": "view",
+		"type": "function"
+	}
+]`
+
+// maxDecimals 允许的最大精度
+const maxDecimals = 36
+
+// Token 代币元数据
+type Token struct {
+	ChainID  uint64 `mapstructure:"chain_id" json:"chain_id"`
+	Address  string `mapstructure:"address" json:"address"`
+	Symbol   string `mapstructure:"symbol" json:"symbol"`
+	Decimals int32  `mapstructure:"decimals" json:"decimals"`
+}
+
+// Registry 代币注册表
+// 未配置的代币在设置了链上查询时通过 ERC-20 decimals()/symbol() 获取并缓存
+type Registry struct {
+	mu      sync.RWMutex
+	tokens  map[string]*Token              // chainID:地址(小写) -> 代币
+	callers map[uint64]bind.ContractCaller // 链ID -> 只读合约调用
+	erc20   abi.ABI
+}
+
+// NewRegistry 创建代币注册表
+func NewRegistry() *Registry {
+	parsed, err := abi.JSON(strings.NewReader(erc20MetadataABI))
+	if err != nil {
+		panic(fmt.Sprintf("invalid ERC-20 metadata ABI: %v", err))
+	}
+	return &Registry{
+		tokens:  make(map[string]*Token),
+		callers: make(map[uint64]bind.ContractCaller),
+		erc20:   parsed,
+	}
+}
+
+// Register 登记代币元数据
+func (r *Registry) Register(token Token) error {
+	if token.ChainID == 0 {
+		return fmt.Errorf("token %s has no chain_id", token.Symbol)
+	}
+	if !common.IsHexAddress(token.Address) {
+		return fmt.Errorf("invalid token address %q", token.Address)
+	}
+	if token.Decimals < 0 || token.Decimals > maxDecimals {
+		return fmt.Errorf("invalid decimals %d for token %s", token.Decimals, token.Address)
+	}
+
+	token.Address = common.HexToAddress(token.Address).Hex()
+	token.Symbol = strings.ToUpper(token.Symbol)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.tokens[tokenKey(token.ChainID, token.Address)] = &token
+	return nil
+}
+
+// SetContractCaller 设置链的只读合约调用，用于查询未配置代币的元数据
+func (r *Registry) SetContractCaller(chainID uint64, caller bind.ContractCaller) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.callers[chainID] = caller
+}
+
+// Lookup 获取代币元数据
+func (r *Registry) Lookup(chainID uint64, address string) (*Token, error) {
+	if !common.IsHexAddress(address) {
+		return nil, fmt.Errorf("invalid token address %q", address)
+	}
+	key := tokenKey(chainID, address)
+
+	r.mu.RLock()
+	token, exists := r.tokens[key]
+	caller := r.callers[chainID]
+	r.mu.RUnlock()
+	if exists {
+		copied := *token
+		return &copied, nil
+	}
+	if caller == nil {
+		return nil, fmt.Errorf("unknown token %s on chain %d", address, chainID)
+	}
+
+	token, err := r.fetch(chainID, common.HexToAddress(address), caller)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	r.tokens[key] = token
+	r.mu.Unlock()
+
+	copied := *token
+	return &copied, nil
+}
+
+// fetch 通过 ERC-20 调用查询代币元数据
+func (r *Registry) fetch(chainID uint64, address common.Address, caller bind.ContractCaller) (*Token, error) {
+	contract := bind.NewBoundContract(address, r.erc20, caller, nil, nil)
+
+	var out []interface{}
+	if err := contract.Call(&bind.CallOpts{}, &out, "decimals"); err != nil {
+		return nil, fmt.Errorf("decimals call failed for token %s: %w", address.Hex(), err)
+	}
+	decimals, ok := out[0].(uint8)
+	if !ok || decimals > maxDecimals {
+		return nil, fmt.Errorf("unexpected decimals %v for token %s", out[0], address.Hex())
+	}
+
+	// symbol() 为可选方法，失败时使用地址
+	symbol := address.Hex()
+	out = nil
+	if err := contract.Call(&bind.CallOpts{}, &out, "symbol"); err == nil {
+		if s, ok := out[0].(string); ok && s != "" {
+			symbol = strings.ToUpper(s)
+		}
+	}
+
+	return &Token{
+		ChainID:  chainID,
+		Address:  address.Hex(),
+		Symbol:   symbol,
+		Decimals: int32(decimals),
+	}, nil
+}
+
+// ToDecimal 将链上整数数量换算为代币单位
+func (r *Registry) ToDecimal(chainID uint64, address string, amount *big.Int) (decimal.Decimal, error) {
+	token, err := r.Lookup(chainID, address)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	return decimal.NewFromBigInt(amount, -token.Decimals), nil
+}
+
+// ToBaseUnits 将代币单位数量换算为链上整数，超出代币精度的部分视为错误
+func (r *Registry) ToBaseUnits(chainID uint64, address string, amount decimal.Decimal) (*big.Int, error) {
+	token, err := r.Lookup(chainID, address)
+	if err != nil {
+		return nil, err
+	}
+	scaled := amount.Shift(token.Decimals)
+	if !scaled.Equal(scaled.Truncate(0)) {
+		return nil, fmt.Errorf("amount %s exceeds %d decimals of token %s", amount.String(), token.Decimals, token.Symbol)
+	}
+	return scaled.BigInt(), nil
+}
+
+// Symbol 获取代币符号，未知代币返回地址
+func (r *Registry) Symbol(chainID uint64, address string) string {
+	token, err := r.Lookup(chainID, address)
+	if err != nil {
+		return address
+	}
+	return token.Symbol
+}
+
+// Tokens 获取已登记的代币（按链ID、符号排序）
+func (r *Registry) Tokens() []Token {
+	r.mu.RLock()
+	result := make([]Token, 0, len(r.tokens))
+	for _, token := range r.tokens {
+		result = append(result, *token)
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].ChainID != result[j].ChainID {
+			return result[i].ChainID < result[j].ChainID
+		}
+		return result[i].Symbol < result[j].Symbol
+	})
+	return result
+}
+
+func tokenKey(chainID uint64, address string) string {
+	return fmt.Sprintf("%d:%s", chainID, strings.ToLower(common.HexToAddress(address).Hex()))
+}
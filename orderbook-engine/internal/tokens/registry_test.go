@@ -0,0 +1,31 @@
+package tokens
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistryConversion(t *testing.T) {
+	registry := NewRegistry()
+	usdc := "0x0000000000000000000000000000000000000002"
+	require.NoError(t, registry.Register(Token{ChainID: 1, Address: usdc, Symbol: "usdc", Decimals: 6}))
+
+	value, err := registry.ToDecimal(1, usdc, big.NewInt(2500500000))
+	require.NoError(t, err)
+	assert.True(t, value.Equal(decimal.RequireFromString("2500.5")))
+
+	units, err := registry.ToBaseUnits(1, usdc, decimal.RequireFromString("2500.5"))
+	require.NoError(t, err)
+	assert.Equal(t, big.NewInt(2500500000), units)
+
+	_, err = registry.ToBaseUnits(1, usdc, decimal.RequireFromString("0.0000001"))
+	assert.Error(t, err, "precision beyond token decimals is rejected")
+
+	assert.Equal(t, "USDC", registry.Symbol(1, usdc))
+	_, err = registry.Lookup(2, usdc)
+	assert.Error(t, err, "tokens are registered per chain")
+}
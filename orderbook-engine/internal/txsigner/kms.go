@@ -0,0 +1,236 @@
+package txsigner
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// kmsKeySpec 以太坊账户对应的 KMS 密钥规格
+const kmsKeySpec = "ECC_SECG_P256K1"
+
+// KMSConfig AWS KMS 签名配置，请求以 SigV4 签名
+type KMSConfig struct {
+	KeyID        string // 密钥ID、ARN 或别名
+	Region       string
+	Endpoint     string // 为空时使用 https://kms.<region>.amazonaws.com
+	AccessKey    string
+	SecretKey    string
+	SessionToken string // 临时凭证的会话令牌，可为空
+	Timeout      time.Duration
+}
+
+// KMSSigner 由 AWS KMS 非对称密钥（ECC_SECG_P256K1）签名，私钥不离开 KMS
+type KMSSigner struct {
+	config   KMSConfig
+	endpoint *url.URL
+	client   *http.Client
+	address  common.Address
+	now      func() time.Time
+}
+
+// NewKMSSigner 创建 KMS 签名者，读取密钥公钥确定账户地址
+func NewKMSSigner(config KMSConfig) (*KMSSigner, error) {
+	if config.KeyID == "" {
+		return nil, fmt.Errorf("kms key_id is required")
+	}
+	if config.AccessKey == "" || config.SecretKey == "" {
+		return nil, fmt.Errorf("kms credentials are required")
+	}
+	if config.Region == "" {
+		config.Region = "us-east-1"
+	}
+	if config.Endpoint == "" {
+		config.Endpoint = "https://kms." + config.Region + ".amazonaws.com"
+	}
+	endpoint, err := url.Parse(strings.TrimRight(config.Endpoint, "/"))
+	if err != nil || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid kms endpoint %q", config.Endpoint)
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 10 * time.Second
+	}
+
+	s := &KMSSigner{
+		config:   config,
+		endpoint: endpoint,
+		client:   &http.Client{Timeout: config.Timeout},
+		now:      time.Now,
+	}
+	publicKey, err := s.publicKey()
+	if err != nil {
+		return nil, err
+	}
+	s.address = crypto.PubkeyToAddress(*publicKey)
+	return s, nil
+}
+
+// Address 签名账户地址
+func (s *KMSSigner) Address() common.Address {
+	return s.address
+}
+
+// SignHash 以 ECDSA_SHA_256 对摘要签名（MessageType=DIGEST，KMS 不再哈希）
+func (s *KMSSigner) SignHash(hash []byte) ([]byte, error) {
+	var response struct {
+		Signature []byte `json:"Signature"`
+	}
+	err := s.call("Sign", map[string]interface{}{
+		"KeyId":            s.config.KeyID,
+		"Message":          hash,
+		"MessageType":      "DIGEST",
+		"SigningAlgorithm": "ECDSA_SHA_256",
+	}, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	var signature struct {
+		R, S *big.Int
+	}
+	if _, err := asn1.Unmarshal(response.Signature, &signature); err != nil {
+		return nil, fmt.Errorf("invalid kms signature: %w", err)
+	}
+	return recoverableSignature(hash, signature.R, signature.S, s.address)
+}
+
+// publicKey 读取 KMS 密钥的公钥
+func (s *KMSSigner) publicKey() (*ecdsa.PublicKey, error) {
+	var response struct {
+		PublicKey []byte `json:"PublicKey"` // DER 编码的 SubjectPublicKeyInfo
+		KeySpec   string `json:"KeySpec"`
+	}
+	if err := s.call("GetPublicKey", map[string]interface{}{"KeyId": s.config.KeyID}, &response); err != nil {
+		return nil, err
+	}
+	if response.KeySpec != kmsKeySpec {
+		return nil, fmt.Errorf("kms key %s has spec %s, %s is required", s.config.KeyID, response.KeySpec, kmsKeySpec)
+	}
+
+	var info struct {
+		Algorithm pkix.AlgorithmIdentifier
+		PublicKey asn1.BitString
+	}
+	if _, err := asn1.Unmarshal(response.PublicKey, &info); err != nil {
+		return nil, fmt.Errorf("invalid kms public key: %w", err)
+	}
+	publicKey, err := crypto.UnmarshalPubkey(info.PublicKey.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid kms public key: %w", err)
+	}
+	return publicKey, nil
+}
+
+// call 调用 KMS JSON API
+func (s *KMSSigner) call(action string, request, response interface{}) error {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.config.Timeout)
+	defer cancel()
+	httpRequest, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint.String()+"/", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	httpRequest.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	httpRequest.Header.Set("X-Amz-Target", "TrentService."+action)
+	s.sign(httpRequest, body)
+
+	httpResponse, err := s.client.Do(httpRequest)
+	if err != nil {
+		return fmt.Errorf("kms %s failed: %w", action, err)
+	}
+	defer httpResponse.Body.Close()
+	data, err := io.ReadAll(httpResponse.Body)
+	if err != nil {
+		return err
+	}
+	if httpResponse.StatusCode != http.StatusOK {
+		return fmt.Errorf("kms %s returned %d: %s", action, httpResponse.StatusCode, strings.TrimSpace(string(data)))
+	}
+	if err := json.Unmarshal(data, response); err != nil {
+		return fmt.Errorf("invalid kms %s response: %w", action, err)
+	}
+	return nil
+}
+
+// sign 按 AWS Signature Version 4 签名请求
+func (s *KMSSigner) sign(request *http.Request, body []byte) {
+	now := s.now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := amzDate[:8]
+	payloadHash := sha256Hex(body)
+
+	request.Header.Set("X-Amz-Date", amzDate)
+	values := map[string]string{
+		"content-type": request.Header.Get("Content-Type"),
+		"host":         request.URL.Host,
+		"x-amz-date":   amzDate,
+		"x-amz-target": request.Header.Get("X-Amz-Target"),
+	}
+	if s.config.SessionToken != "" {
+		request.Header.Set("X-Amz-Security-Token", s.config.SessionToken)
+		values["x-amz-security-token"] = s.config.SessionToken
+	}
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + values[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		request.Method,
+		"/",
+		"",
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + s.config.Region + "/kms/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+	key := hmacSHA256([]byte("AWS4"+s.config.SecretKey), date)
+	key = hmacSHA256(key, s.config.Region)
+	key = hmacSHA256(key, "kms")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	request.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.config.AccessKey, scope, signedHeaders, signature))
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,101 @@
+// Package txsigner 链上交易签名后端
+// 结算账户的私钥可以放在本地配置、AWS KMS 或 HashiCorp Vault 中，结算管理器只依赖 Signer 接口
+package txsigner
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// 签名后端
+const (
+	BackendLocal = "local"
+	BackendKMS   = "kms"
+	BackendVault = "vault"
+)
+
+// ErrUnknownBackend 未知的签名后端
+var ErrUnknownBackend = errors.New("unknown signer backend")
+
+// Signer 交易签名者
+type Signer interface {
+	// Address 签名账户地址
+	Address() common.Address
+	// SignHash 对32字节摘要签名，返回 [R || S || V] 格式的65字节签名，V 为 0 或 1
+	SignHash(hash []byte) ([]byte, error)
+}
+
+// NewTransactOpts 由签名者创建合约调用的交易参数
+func NewTransactOpts(signer Signer, chainID *big.Int) *bind.TransactOpts {
+	txSigner := types.LatestSignerForChainID(chainID)
+	from := signer.Address()
+	return &bind.TransactOpts{
+		From: from,
+		Signer: func(address common.Address, tx *types.Transaction) (*types.Transaction, error) {
+			if address != from {
+				return nil, bind.ErrNotAuthorized
+			}
+			signature, err := signer.SignHash(txSigner.Hash(tx).Bytes())
+			if err != nil {
+				return nil, err
+			}
+			return tx.WithSignature(txSigner, signature)
+		},
+	}
+}
+
+// LocalSigner 进程内持有私钥的签名者
+type LocalSigner struct {
+	key     *ecdsa.PrivateKey
+	address common.Address
+}
+
+// NewLocalSigner 由十六进制私钥（可带 0x 前缀）创建本地签名者
+func NewLocalSigner(privateKeyHex string) (*LocalSigner, error) {
+	key, err := crypto.HexToECDSA(strings.TrimPrefix(privateKeyHex, "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key: %w", err)
+	}
+	return &LocalSigner{key: key, address: crypto.PubkeyToAddress(key.PublicKey)}, nil
+}
+
+// Address 签名账户地址
+func (s *LocalSigner) Address() common.Address {
+	return s.address
+}
+
+// SignHash 对摘要签名
+func (s *LocalSigner) SignHash(hash []byte) ([]byte, error) {
+	return crypto.Sign(hash, s.key)
+}
+
+// secp256k1HalfN 曲线阶的一半，以太坊只接受 S 不大于该值的签名
+var secp256k1HalfN = new(big.Int).Rsh(crypto.S256().Params().N, 1)
+
+// recoverableSignature 由外部签名服务返回的 (r, s) 构造以太坊签名：
+// 规范化为低 S 值，并通过公钥恢复确定恢复位 V
+func recoverableSignature(hash []byte, r, s *big.Int, address common.Address) ([]byte, error) {
+	if s.Cmp(secp256k1HalfN) > 0 {
+		s = new(big.Int).Sub(crypto.S256().Params().N, s)
+	}
+
+	signature := make([]byte, crypto.SignatureLength)
+	r.FillBytes(signature[:32])
+	s.FillBytes(signature[32:64])
+	for v := byte(0); v < 2; v++ {
+		signature[64] = v
+		publicKey, err := crypto.SigToPub(hash, signature)
+		if err == nil && crypto.PubkeyToAddress(*publicKey) == address {
+			return signature, nil
+		}
+	}
+	return nil, fmt.Errorf("signature does not recover to %s", address.Hex())
+}
@@ -0,0 +1,123 @@
+package txsigner
+
+import (
+	"crypto/ecdsa"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testKey = "4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318"
+
+func TestLocalSignerTransactOpts(t *testing.T) {
+	signer, err := NewLocalSigner("0x" + testKey)
+	require.NoError(t, err)
+
+	chainID := big.NewInt(31337)
+	opts := NewTransactOpts(signer, chainID)
+	assert.Equal(t, signer.Address(), opts.From)
+
+	tx := types.NewTransaction(0, common.HexToAddress("0x01"), big.NewInt(0), 21000, big.NewInt(1), nil)
+	signed, err := opts.Signer(opts.From, tx)
+	require.NoError(t, err)
+	sender, err := types.Sender(types.LatestSignerForChainID(chainID), signed)
+	require.NoError(t, err)
+	assert.Equal(t, signer.Address(), sender)
+
+	_, err = opts.Signer(common.HexToAddress("0x02"), tx)
+	assert.Error(t, err)
+}
+
+// fakeKMS 用本地私钥模拟 KMS GetPublicKey / Sign，签名故意返回高 S 值
+func fakeKMS(t *testing.T, key *ecdsa.PrivateKey) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.True(t, strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=access/"))
+		assert.Contains(t, r.Header.Get("Authorization"), "/kms/aws4_request")
+
+		var request struct {
+			KeyId   string
+			Message []byte
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		assert.Equal(t, "alias/settler", request.KeyId)
+
+		switch r.Header.Get("X-Amz-Target") {
+		case "TrentService.GetPublicKey":
+			curve, err := asn1.Marshal(asn1.ObjectIdentifier{1, 3, 132, 0, 10})
+			require.NoError(t, err)
+			der, err := asn1.Marshal(struct {
+				Algorithm pkix.AlgorithmIdentifier
+				PublicKey asn1.BitString
+			}{
+				Algorithm: pkix.AlgorithmIdentifier{Algorithm: asn1.ObjectIdentifier{1, 2, 840, 10045, 2, 1}, Parameters: asn1.RawValue{FullBytes: curve}},
+				PublicKey: asn1.BitString{Bytes: crypto.FromECDSAPub(&key.PublicKey), BitLength: 65 * 8},
+			})
+			require.NoError(t, err)
+			json.NewEncoder(w).Encode(map[string]interface{}{"PublicKey": der, "KeySpec": kmsKeySpec})
+		case "TrentService.Sign":
+			signature, err := crypto.Sign(request.Message, key)
+			require.NoError(t, err)
+			sigR := new(big.Int).SetBytes(signature[:32])
+			sigS := new(big.Int).SetBytes(signature[32:64])
+			der, err := asn1.Marshal(struct{ R, S *big.Int }{sigR, new(big.Int).Sub(crypto.S256().Params().N, sigS)})
+			require.NoError(t, err)
+			json.NewEncoder(w).Encode(map[string]interface{}{"Signature": der})
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+}
+
+func TestKMSSigner(t *testing.T) {
+	key, err := crypto.HexToECDSA(testKey)
+	require.NoError(t, err)
+	server := fakeKMS(t, key)
+	defer server.Close()
+
+	signer, err := NewKMSSigner(KMSConfig{KeyID: "alias/settler", Endpoint: server.URL, AccessKey: "access", SecretKey: "secret"})
+	require.NoError(t, err)
+	assert.Equal(t, crypto.PubkeyToAddress(key.PublicKey), signer.Address())
+
+	// 高 S 值被规范化，签名可恢复出 KMS 密钥地址
+	hash := crypto.Keccak256([]byte("settlement"))
+	signature, err := signer.SignHash(hash)
+	require.NoError(t, err)
+	assert.True(t, new(big.Int).SetBytes(signature[32:64]).Cmp(secp256k1HalfN) <= 0)
+	publicKey, err := crypto.SigToPub(hash, signature)
+	require.NoError(t, err)
+	assert.Equal(t, signer.Address(), crypto.PubkeyToAddress(*publicKey))
+}
+
+func TestVaultSigner(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		assert.Equal(t, "/v1/secret/data/orderbook/settler", r.URL.Path)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]interface{}{"data": map[string]interface{}{"private_key": testKey}},
+		})
+	}))
+	defer server.Close()
+
+	signer, err := NewVaultSigner(VaultConfig{Address: server.URL, Token: "token", Path: "orderbook/settler"})
+	require.NoError(t, err)
+	local, err := NewLocalSigner(testKey)
+	require.NoError(t, err)
+	assert.Equal(t, local.Address(), signer.Address())
+
+	_, err = NewVaultSigner(VaultConfig{Address: server.URL, Token: "wrong", Path: "orderbook/settler"})
+	assert.ErrorContains(t, err, "403")
+}
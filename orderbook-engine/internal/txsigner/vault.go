@@ -0,0 +1,80 @@
+package txsigner
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// VaultConfig HashiCorp Vault KV v2 私钥配置
+type VaultConfig struct {
+	Address string // 如 https://vault.internal:8200
+	Token   string
+	Mount   string // KV v2 挂载点，默认 secret
+	Path    string // 密钥路径，如 orderbook/settler
+	Field   string // 私钥字段名，默认 private_key
+	Timeout time.Duration
+}
+
+// NewVaultSigner 从 Vault KV v2 读取最新版本的结算私钥并创建签名者
+// Vault Transit 不支持 secp256k1，私钥保存在 Vault 中、签名在本进程内完成；
+// 轮换时在 Vault 写入新版本后重新读取即可，配置文件中不出现私钥
+func NewVaultSigner(config VaultConfig) (*LocalSigner, error) {
+	if config.Address == "" || config.Token == "" || config.Path == "" {
+		return nil, fmt.Errorf("vault address, token and path are required")
+	}
+	if config.Mount == "" {
+		config.Mount = "secret"
+	}
+	if config.Field == "" {
+		config.Field = "private_key"
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 10 * time.Second
+	}
+
+	endpoint, err := url.JoinPath(strings.TrimRight(config.Address, "/"), "v1", config.Mount, "data", config.Path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid vault address %q: %w", config.Address, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.Timeout)
+	defer cancel()
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("X-Vault-Token", config.Token)
+
+	response, err := (&http.Client{Timeout: config.Timeout}).Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("vault read failed: %w", err)
+	}
+	defer response.Body.Close()
+	data, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("vault read %s returned %d: %s", config.Path, response.StatusCode, strings.TrimSpace(string(data)))
+	}
+
+	var secret struct {
+		Data struct {
+			Data map[string]interface{} `json:"data"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(data, &secret); err != nil {
+		return nil, fmt.Errorf("invalid vault response: %w", err)
+	}
+	privateKey, ok := secret.Data.Data[config.Field].(string)
+	if !ok || privateKey == "" {
+		return nil, fmt.Errorf("vault secret %s has no field %q", config.Path, config.Field)
+	}
+	return NewLocalSigner(privateKey)
+}
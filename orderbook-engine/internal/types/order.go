@@ -35,6 +35,49 @@ const (
 	OrderStatusFilled          OrderStatus = "filled"
 	OrderStatusCancelled       OrderStatus = "cancelled"
 	OrderStatusRejected        OrderStatus = "rejected"
+	OrderStatusExpired         OrderStatus = "expired"
+)
+
+// 订单终止原因（StatusReason）
+const (
+	StatusReasonSlippageLimit = "SLIPPAGE_LIMIT" // 市价单成交价超出滑点保护，剩余部分已撤销
+	StatusReasonNoLiquidity   = "NO_LIQUIDITY"   // 市价单对手方流动性不足，被拒绝或剩余部分已撤销
+	StatusReasonAuction       = "AUCTION"        // 集合竞价期间不接受市价单
+	StatusReasonDraining      = "DRAINING"       // 引擎排空停机中，不接受新订单
+	StatusReasonNotLeader     = "NOT_LEADER"     // 当前实例为备用实例，不接受新订单
+	StatusReasonReduceOnly    = "REDUCE_ONLY"    // 只减仓订单没有可减的持仓，被拒绝或剩余部分已撤销
+	StatusReasonChainCancel   = "CHAIN_CANCEL"   // 链上订单已在合约中撤销，同步移出链下订单簿
+	StatusReasonChainFilled   = "CHAIN_FILLED"   // 链上订单已在合约中完全成交，同步移出链下订单簿
+
+	StatusReasonSignatureExpired    = "SIGNATURE_EXPIRED"    // 订单签名超过最长有效期
+	StatusReasonPairHalted          = "PAIR_HALTED"          // 交易对暂停撮合，拒绝会立即成交的订单
+	StatusReasonInsufficientBalance = "INSUFFICIENT_BALANCE" // 可用余额不足以锁定下单资金
+	StatusReasonDuplicateIntent     = "DUPLICATE_INTENT"     // 另一入口（REST 或链上）已提交相同意图的订单
+	StatusReasonIntegrityRepair     = "INTEGRITY_REPAIR"     // 一致性检查修复：订单簿与存储状态不一致，按存储状态撤出或关闭
+	StatusReasonAccountFrozen       = "ACCOUNT_FROZEN"       // 账户已被管理员冻结，拒绝新订单并撤销挂单
+	StatusReasonSystemHalted        = "SYSTEM_HALTED"        // 交易所紧急停机（只读模式），不接受新订单
+	StatusReasonPairNotTradable     = "PAIR_NOT_TRADABLE"    // 交易对尚未开盘或已下架
+	StatusReasonPairDelisted        = "PAIR_DELISTED"        // 交易对下架，挂单全部撤销
+	StatusReasonInvalidIncrement    = "INVALID_INCREMENT"    // 价格或数量不符合交易对的最小变动单位
+	StatusReasonPriceOutOfBand      = "PRICE_OUT_OF_BAND"    // 限价偏离参考价超出交易对价格带
+	StatusReasonComplianceRejected  = "COMPLIANCE_REJECTED"  // 用户地址未通过合规筛查（制裁名单等）
+	StatusReasonPairReversed        = "PAIR_REVERSED"        // 链上订单的代币顺序与规范交易对的基础/计价顺序相反
+
+	StatusReasonInsufficientOnchainFunds = "INSUFFICIENT_ONCHAIN_FUNDS" // 结算合约中的托管余额或钱包余额不足，订单无法在链上结算
+	StatusReasonInsufficientAllowance    = "INSUFFICIENT_ALLOWANCE"     // 对结算合约的 ERC-20 授权不足，订单无法在链上结算
+)
+
+// SettlementStatus 成交的链上结算状态
+type SettlementStatus string
+
+const (
+	SettlementStatusPending   SettlementStatus = "pending"   // 已进入结算队列
+	SettlementStatusBatched   SettlementStatus = "batched"   // 已打包进待提交批次
+	SettlementStatusSubmitted SettlementStatus = "submitted" // 批量交易已发送
+	SettlementStatusConfirmed SettlementStatus = "confirmed" // 交易已上链确认
+	SettlementStatusFailed    SettlementStatus = "failed"    // 结算失败（等待重试或已进入死信队列）
+	SettlementStatusVoided    SettlementStatus = "voided"    // 已由管理员作废，不再上链
+	SettlementStatusRejected  SettlementStatus = "rejected"  // 提交前模拟执行失败，已从批次剔除并回滚内部余额，不再上链
 )
 
 // Order 订单结构
@@ -42,6 +85,7 @@ type Order struct {
 	ID           uuid.UUID       `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
 	UserAddress  string          `json:"user_address" gorm:"not null;index"`
 	TradingPair  string          `json:"trading_pair" gorm:"not null;index"`
+	ChainID      uint64          `json:"chain_id" gorm:"not null;index"` // 订单签名和结算所在的链
 	BaseToken    string          `json:"base_token" gorm:"not null"`
 	QuoteToken   string          `json:"quote_token" gorm:"not null"`
 	Side         OrderSide       `json:"side" gorm:"not null"`
@@ -56,12 +100,23 @@ type Order struct {
 	Hash         string          `json:"hash" gorm:"not null;unique"`
 	CreatedAt    time.Time       `json:"created_at" gorm:"autoCreateTime"`
 	UpdatedAt    time.Time       `json:"updated_at" gorm:"autoUpdateTime"`
+
+	MaxSlippage     decimal.Decimal `json:"max_slippage" gorm:"type:decimal(10,4);default:0"`      // 市价单最大滑点（百分比，相对撮合前对手方最优价），0表示不限制
+	ProtectionPrice decimal.Decimal `json:"protection_price" gorm:"type:decimal(36,18);default:0"` // 市价单保护价（买单最高、卖单最低成交价），0表示不限制
+	StatusReason    string          `json:"status_reason,omitempty"`                               // 订单撤销或拒绝的原因
+	RejectReason    string          `json:"reject_reason,omitempty"`                               // 订单被拒绝的详细说明，StatusReason 为对应的原因代码
+	ReduceOnly      bool            `json:"reduce_only" gorm:"default:false"`                      // 只减仓：剩余数量不超过用户持有的卖出代币
+	ReducedAmount   decimal.Decimal `json:"reduced_amount" gorm:"type:decimal(36,18);default:0"`   // 只减仓缩减掉的数量，签名数量 Amount 保持不变
+	AvgFillPrice    decimal.Decimal `json:"avg_fill_price" gorm:"type:decimal(36,18);default:0"`   // 成交均价（QuoteFilled / FilledAmount），未成交时为0
+	QuoteFilled     decimal.Decimal `json:"quote_filled" gorm:"type:decimal(36,18);default:0"`     // 累计成交额（报价代币）
+	FeePaid         decimal.Decimal `json:"fee_paid" gorm:"type:decimal(36,18);default:0"`         // 累计手续费（报价代币），按撮合时的费率计算
 }
 
 // SignedOrder 签名订单结构（用于API传输）
 type SignedOrder struct {
 	UserAddress string          `json:"user_address"`
 	TradingPair string          `json:"trading_pair"`
+	ChainID     uint64          `json:"chain_id,omitempty"` // 签名域的链ID，为空时使用交易对所属的链
 	BaseToken   string          `json:"base_token"`
 	QuoteToken  string          `json:"quote_token"`
 	Side        OrderSide       `json:"side"`
@@ -71,19 +126,51 @@ type SignedOrder struct {
 	ExpiresAt   *time.Time      `json:"expires_at"`
 	Nonce       uint64          `json:"nonce"`
 	Signature   string          `json:"signature"`
+
+	// 市价单滑点保护，不参与签名
+	MaxSlippage     decimal.Decimal `json:"max_slippage"`
+	ProtectionPrice decimal.Decimal `json:"protection_price"`
+
+	// 只减仓标记，不参与签名
+	ReduceOnly bool `json:"reduce_only"`
+}
+
+// SignedCancel 已签名的撤单请求（EIP-712 CancelOrder）
+type SignedCancel struct {
+	OrderHash   string `json:"order_hash" binding:"required"`
+	UserAddress string `json:"user_address" binding:"required"`
+	ChainID     uint64 `json:"chain_id,omitempty"` // 签名域的链ID，为空时使用默认链
+	Nonce       uint64 `json:"nonce"`
+	ExpiresAt   int64  `json:"expires_at" binding:"required"` // Unix时间戳（秒）
+	Signature   string `json:"signature" binding:"required"`
+}
+
+// SignedNonceCancel 已签名的批量作废请求（EIP-712 CancelUpTo）
+// 作废 nonce 小于 MinNonce 的全部订单，与结算合约 userNonces 语义一致
+type SignedNonceCancel struct {
+	UserAddress string `json:"user_address" binding:"required"`
+	MinNonce    uint64 `json:"min_nonce" binding:"required"`
+	ChainID     uint64 `json:"chain_id,omitempty"`            // 签名域的链ID，为空时使用默认链
+	ExpiresAt   int64  `json:"expires_at" binding:"required"` // Unix时间戳（秒）
+	Signature   string `json:"signature" binding:"required"`
 }
 
 // Fill 成交记录
 type Fill struct {
-	ID           uuid.UUID       `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
-	TakerOrderID uuid.UUID       `json:"taker_order_id" gorm:"not null;index"`
-	MakerOrderID uuid.UUID       `json:"maker_order_id" gorm:"not null;index"`
-	TradingPair  string          `json:"trading_pair" gorm:"not null;index"`
-	Price        decimal.Decimal `json:"price" gorm:"type:decimal(36,18);not null"`
-	Amount       decimal.Decimal `json:"amount" gorm:"type:decimal(36,18);not null"`
-	TakerSide    OrderSide       `json:"taker_side" gorm:"not null"`
-	TxHash       string          `json:"tx_hash"`
-	CreatedAt    time.Time       `json:"created_at" gorm:"autoCreateTime"`
+	ID               uuid.UUID        `json:"id" gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	TakerOrderID     uuid.UUID        `json:"taker_order_id" gorm:"not null;index"`
+	MakerOrderID     uuid.UUID        `json:"maker_order_id" gorm:"not null;index"`
+	TakerUserAddress string           `json:"taker_user_address" gorm:"index"` // taker 订单的用户地址
+	MakerUserAddress string           `json:"maker_user_address" gorm:"index"` // maker（挂单方）订单的用户地址
+	TradingPair      string           `json:"trading_pair" gorm:"not null;index"`
+	Price            decimal.Decimal  `json:"price" gorm:"type:decimal(36,18);not null"`
+	Amount           decimal.Decimal  `json:"amount" gorm:"type:decimal(36,18);not null"`
+	TakerSide        OrderSide        `json:"taker_side" gorm:"not null"`
+	TxHash           string           `json:"tx_hash"`
+	SettlementStatus SettlementStatus `json:"settlement_status,omitempty" gorm:"index"`
+	TransactTime     time.Time        `json:"transact_time"`              // 撮合时刻的墙上时间
+	EngineTimestamp  int64            `json:"engine_timestamp,omitempty"` // 撮合时刻的引擎时间戳（单调递增 Unix 纳秒，见 clock 包），导入的历史成交为 0
+	CreatedAt        time.Time        `json:"created_at" gorm:"autoCreateTime"`
 }
 
 // OrderBook 订单簿快照
@@ -91,6 +178,7 @@ type OrderBookSnapshot struct {
 	TradingPair string              `json:"trading_pair"`
 	Bids        []OrderBookLevel    `json:"bids"`
 	Asks        []OrderBookLevel    `json:"asks"`
+	Sequence    uint64              `json:"sequence"` // 订单簿变更序号
 	Timestamp   time.Time           `json:"timestamp"`
 }
 
@@ -101,14 +189,110 @@ type OrderBookLevel struct {
 	Count  int             `json:"count"`
 }
 
+// OrderBookL3Snapshot 逐笔订单簿快照（L3），每个价格层级列出挂单明细
+type OrderBookL3Snapshot struct {
+	TradingPair string             `json:"trading_pair"`
+	Bids        []OrderBookL3Level `json:"bids"`
+	Asks        []OrderBookL3Level `json:"asks"`
+	Timestamp   time.Time          `json:"timestamp"`
+}
+
+// OrderBookL3Level L3 价格层级，Amount/Count 为整个层级的汇总
+type OrderBookL3Level struct {
+	Price  decimal.Decimal    `json:"price"`
+	Amount decimal.Decimal    `json:"amount"`
+	Count  int                `json:"count"`
+	Orders []OrderBookL3Order `json:"orders"`
+}
+
+// OrderBookL3Order L3 挂单明细，不包含用户地址
+type OrderBookL3Order struct {
+	OrderID   uuid.UUID       `json:"order_id"`
+	Amount    decimal.Decimal `json:"amount"`   // 剩余数量
+	Position  int             `json:"position"` // 在价格层级内的排队位置，从 0 开始
+	Timestamp time.Time       `json:"timestamp"`
+}
+
+// QueuePosition 挂单在价格层级内的排队位置
+type QueuePosition struct {
+	OrderID     uuid.UUID       `json:"order_id"`
+	TradingPair string          `json:"trading_pair"`
+	Side        OrderSide       `json:"side"`
+	Price       decimal.Decimal `json:"price"`
+	Position    int             `json:"position"`     // 从 0 开始，0 表示下一笔成交
+	OrdersAhead int             `json:"orders_ahead"` // 同价位排在前面的订单数
+	AmountAhead decimal.Decimal `json:"amount_ahead"` // 同价位排在前面的剩余数量
+	Remaining   decimal.Decimal `json:"remaining"`
+	LevelAmount decimal.Decimal `json:"level_amount"`
+	LevelCount  int             `json:"level_count"`
+	IsBestPrice bool            `json:"is_best_price"` // 是否处于本方最优价位
+}
+
+// BBO 最优买卖价（Best Bid and Offer），任一方为空时中间价和价差为 null
+type BBO struct {
+	TradingPair string           `json:"trading_pair"`
+	BestBid     *decimal.Decimal `json:"best_bid"`
+	BestAsk     *decimal.Decimal `json:"best_ask"`
+	MidPrice    *decimal.Decimal `json:"mid_price"`
+	Spread      *decimal.Decimal `json:"spread"`
+	SpreadBps   *decimal.Decimal `json:"spread_bps"` // 价差相对中间价的基点数
+	Sequence    uint64           `json:"sequence"`   // 订单簿变更序号，可用于判断报价是否更新
+	Timestamp   time.Time        `json:"timestamp"`
+}
+
+// LiquidityMetrics 交易对流动性指标，基于实时订单簿计算
+type LiquidityMetrics struct {
+	TradingPair string           `json:"trading_pair"`
+	MidPrice    *decimal.Decimal `json:"mid_price"` // 任一方为空时为 null，此时不计算深度区间和价格冲击
+	Imbalance   decimal.Decimal  `json:"imbalance"` // 整个订单簿的买卖数量失衡度 (买-卖)/(买+卖)，范围 [-1, 1]
+	Bands       []DepthBand      `json:"bands"`
+	Impact      []PriceImpact    `json:"impact"`
+	Sequence    uint64           `json:"sequence"`
+	Timestamp   time.Time        `json:"timestamp"`
+}
+
+// DepthBand 中间价上下一定基点内的累计深度
+type DepthBand struct {
+	Bps         decimal.Decimal `json:"bps"`
+	BidAmount   decimal.Decimal `json:"bid_amount"`
+	AskAmount   decimal.Decimal `json:"ask_amount"`
+	BidNotional decimal.Decimal `json:"bid_notional"`
+	AskNotional decimal.Decimal `json:"ask_notional"`
+	Imbalance   decimal.Decimal `json:"imbalance"` // 区间内 (买-卖)/(买+卖)
+}
+
+// PriceImpact 将价格相对中间价推动一定百分比所需的吃单数量
+type PriceImpact struct {
+	Percent       decimal.Decimal `json:"percent"`
+	BuyAmount     decimal.Decimal `json:"buy_amount"` // 买入推高价格需吃掉的卖单数量
+	BuyNotional   decimal.Decimal `json:"buy_notional"`
+	BuyExhausted  bool            `json:"buy_exhausted"` // 卖方全部挂单都在目标价以内
+	SellAmount    decimal.Decimal `json:"sell_amount"`   // 卖出压低价格需吃掉的买单数量
+	SellNotional  decimal.Decimal `json:"sell_notional"`
+	SellExhausted bool            `json:"sell_exhausted"`
+}
+
 // Trade 交易信息
 type Trade struct {
-	ID          uuid.UUID       `json:"id"`
+	ID              uuid.UUID       `json:"id"`
+	TradingPair     string          `json:"trading_pair"`
+	Price           decimal.Decimal `json:"price"`
+	Amount          decimal.Decimal `json:"amount"`
+	Side            OrderSide       `json:"side"`
+	Timestamp       time.Time       `json:"timestamp"`
+	EngineTimestamp int64           `json:"engine_timestamp,omitempty"` // 撮合时刻的引擎时间戳
+}
+
+// Candle K线
+type Candle struct {
 	TradingPair string          `json:"trading_pair"`
-	Price       decimal.Decimal `json:"price"`
-	Amount      decimal.Decimal `json:"amount"`
-	Side        OrderSide       `json:"side"`
-	Timestamp   time.Time       `json:"timestamp"`
+	Interval    string          `json:"interval"`  // 1m、5m、15m、1h、4h、1d
+	OpenTime    time.Time       `json:"open_time"` // 区间开始时间（UTC，按周期对齐）
+	Open        decimal.Decimal `json:"open"`
+	High        decimal.Decimal `json:"high"`
+	Low         decimal.Decimal `json:"low"`
+	Close       decimal.Decimal `json:"close"`
+	Volume      decimal.Decimal `json:"volume"`
 }
 
 // WebSocketMessage WebSocket消息
@@ -120,7 +304,7 @@ type WebSocketMessage struct {
 // OrderUpdate 订单更新消息
 type OrderUpdate struct {
 	Order     *Order `json:"order"`
-	EventType string `json:"event_type"` // created, updated, filled, cancelled
+	EventType string `json:"event_type"` // created, updated, filled, cancelled, expired, rejected
 }
 
 // TradeUpdate 交易更新消息
@@ -136,9 +320,155 @@ type OrderBookUpdate struct {
 	Timestamp   time.Time        `json:"timestamp"`
 }
 
+// PairStatus 交易对状态
+type PairStatus string
+
+const (
+	PairStatusTrading  PairStatus = "trading"
+	PairStatusHalted   PairStatus = "halted"
+	PairStatusAuction  PairStatus = "auction"  // 集合竞价中，订单只挂单不撮合
+	PairStatusPending  PairStatus = "pending"  // 已上线未开盘，不接受订单
+	PairStatusDelisted PairStatus = "delisted" // 已下架，挂单全部撤销
+)
+
+// PairStatusUpdate 交易对状态更新消息
+type PairStatusUpdate struct {
+	TradingPair string     `json:"trading_pair"`
+	Status      PairStatus `json:"status"`
+	Reason      string     `json:"reason,omitempty"`
+	ResumeAt    *time.Time `json:"resume_at,omitempty"`
+	Timestamp   time.Time  `json:"timestamp"`
+}
+
+// SystemStatus 系统运行状态
+type SystemStatus string
+
+const (
+	SystemStatusNormal   SystemStatus = "normal"
+	SystemStatusShedding SystemStatus = "shedding" // 过载降级：优先处理撤单，限制新订单
+	SystemStatusHalted   SystemStatus = "halted"   // 紧急停机：暂停撮合与结算，只提供行情与查询
+)
+
+// SystemStatusUpdate 系统状态更新消息
+type SystemStatusUpdate struct {
+	Status    SystemStatus `json:"status"`
+	Reason    string       `json:"reason,omitempty"`
+	Timestamp time.Time    `json:"timestamp"`
+}
+
+// FillUpdate 用户成交推送消息（maker、taker 双方各收到一条）
+type FillUpdate struct {
+	Fill    *Fill     `json:"fill"`
+	OrderID uuid.UUID `json:"order_id"` // 用户一方的订单
+	Side    OrderSide `json:"side"`
+	Role    string    `json:"role"` // maker、taker
+
+	SettlementError string `json:"settlement_error,omitempty"` // 结算被拒绝（settlement_status 为 rejected）时的链上执行错误
+}
+
+// ExecutionReport 订单成交回报（每笔成交对双方订单各生成一条）
+// 携带截至该笔成交的累计成交数量、成交均价与剩余数量，客户端无需从原始成交自行累计
+type ExecutionReport struct {
+	OrderID            uuid.UUID       `json:"order_id"`
+	UserAddress        string          `json:"user_address"`
+	TradingPair        string          `json:"trading_pair"`
+	Side               OrderSide       `json:"side"`
+	OrderStatus        OrderStatus     `json:"order_status"` // 该笔成交后的订单状态（partially_filled、filled）
+	OrderAmount        decimal.Decimal `json:"order_amount"`
+	CumulativeQuantity decimal.Decimal `json:"cumulative_quantity"`
+	AveragePrice       decimal.Decimal `json:"average_price"` // 按成交数量加权的成交均价
+	RemainingQuantity  decimal.Decimal `json:"remaining_quantity"`
+	FillCount          int             `json:"fill_count"`
+	LastFillID         uuid.UUID       `json:"last_fill_id"`
+	LastPrice          decimal.Decimal `json:"last_price"`
+	LastQuantity       decimal.Decimal `json:"last_quantity"`
+	LastRole           string          `json:"last_role"` // 该笔成交中订单的角色：maker、taker
+	Timestamp          time.Time       `json:"timestamp"` // 该笔成交的时间
+}
+
+// BalanceUpdate 用户余额变化推送消息
+type BalanceUpdate struct {
+	UserAddress string          `json:"user_address"`
+	Token       string          `json:"token"`
+	Total       decimal.Decimal `json:"total"`
+	Locked      decimal.Decimal `json:"locked"`
+	Available   decimal.Decimal `json:"available"`
+	Timestamp   time.Time       `json:"timestamp"`
+}
+
+// RiskAlertType 风控告警类型
+type RiskAlertType string
+
+const (
+	RiskAlertOrderRejected     RiskAlertType = "order_rejected"     // 订单被风控或撮合拒绝
+	RiskAlertBlacklisted       RiskAlertType = "blacklisted"        // 账户被加入黑名单
+	RiskAlertBlacklistRemoved  RiskAlertType = "blacklist_removed"  // 账户移出黑名单
+	RiskAlertQuotingObligation RiskAlertType = "quoting_obligation" // 做市商未满足报价义务
+	RiskAlertAccountFrozen     RiskAlertType = "account_frozen"     // 账户被管理员冻结
+	RiskAlertAccountUnfrozen   RiskAlertType = "account_unfrozen"   // 账户解除冻结
+)
+
+// RiskAlert 用户风控告警推送消息
+type RiskAlert struct {
+	UserAddress string        `json:"user_address"`
+	Type        RiskAlertType `json:"type"`
+	Code        string        `json:"code,omitempty"`
+	Reason      string        `json:"reason,omitempty"`
+	OrderID     *uuid.UUID    `json:"order_id,omitempty"`
+	TradingPair string        `json:"trading_pair,omitempty"`
+	ExpiresAt   *time.Time    `json:"expires_at,omitempty"` // 黑名单到期时间
+	Timestamp   time.Time     `json:"timestamp"`
+}
+
+// AdminAlertType 运维告警类型
+type AdminAlertType string
+
+const (
+	AdminAlertGasBudgetExceeded AdminAlertType = "gas_budget_exceeded" // 结算Gas支出超出预算
+	AdminAlertGasBudgetRestored AdminAlertType = "gas_budget_restored" // 结算Gas支出回到预算内
+)
+
+// AdminAlert 运维告警推送消息（管理员主题 admin.alerts）
+type AdminAlert struct {
+	Type      AdminAlertType `json:"type"`
+	ChainID   uint64         `json:"chain_id,omitempty"`
+	Message   string         `json:"message"`
+	Details   interface{}    `json:"details,omitempty"`
+	Timestamp time.Time      `json:"timestamp"`
+}
+
+// SystemEventType 运维事件类型
+type SystemEventType string
+
+const (
+	SystemEventSettlement        SystemEventType = "settlement"         // 结算批次确认、失败或提交前被拒绝
+	SystemEventRiskViolation     SystemEventType = "risk_violation"     // 订单未通过风控检查
+	SystemEventCircuitBreaker    SystemEventType = "circuit_breaker"    // 价格熔断暂停或恢复交易
+	SystemEventChainConnectivity SystemEventType = "chain_connectivity" // 链上事件订阅断开或恢复
+	SystemEventIntegrity         SystemEventType = "integrity"          // 一致性检查发现不一致
+)
+
+// 运维事件级别
+const (
+	SystemEventInfo     = "info"
+	SystemEventWarning  = "warning"
+	SystemEventCritical = "critical"
+)
+
+// SystemEvent 运维事件推送消息（管理员主题 system.admin）
+type SystemEvent struct {
+	Type        SystemEventType `json:"type"`
+	Severity    string          `json:"severity"`
+	ChainID     uint64          `json:"chain_id,omitempty"`
+	TradingPair string          `json:"trading_pair,omitempty"`
+	Message     string          `json:"message"`
+	Details     interface{}     `json:"details,omitempty"`
+	Timestamp   time.Time       `json:"timestamp"`
+}
+
 // GetRemainingAmount 获取订单剩余数量
 func (o *Order) GetRemainingAmount() decimal.Decimal {
-	return o.Amount.Sub(o.FilledAmount)
+	return o.Amount.Sub(o.FilledAmount).Sub(o.ReducedAmount)
 }
 
 // IsActive 检查订单是否活跃
@@ -4,9 +4,8 @@ import (
 	"fmt"
 	"sync"
 	"time"
-	"math/big"
 
-	"github.com/ethereum/go-ethereum/common"
+	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
 	"github.com/sirupsen/logrus"
 
@@ -19,6 +18,12 @@ type BalanceManager struct {
 	balances      map[string]map[string]*decimal.Decimal // user -> token -> balance
 	lockedFunds   map[string]map[string]*decimal.Decimal // user -> token -> locked amount
 	orderLocks    map[string]*OrderLock                   // order_id -> lock info
+	withdrawal    *withdrawalSettings                     // 提现手续费配置
+	deposits      map[string]*DepositRecord               // deposit_id -> 已入账充值
+	withdrawals   map[uuid.UUID]*WithdrawalRecord         // 提现ID -> 已扣款提现
+	store         BalanceStore                            // 可选，为空时余额仅保存在内存
+	changed       map[balanceKey]bool                     // 待通知的余额变化，未设置回调时为空
+	changeSignal  chan struct{}
 	mu            sync.RWMutex
 	logger        *logrus.Logger
 }
@@ -28,7 +33,9 @@ type OrderLock struct {
 	OrderID     string
 	UserAddress string
 	Token       string
-	Amount      decimal.Decimal
+	Side        types.OrderSide
+	Price       decimal.Decimal // 买单锁定价格
+	Amount      decimal.Decimal // 剩余锁定数量
 	CreatedAt   time.Time
 	ExpiresAt   *time.Time
 }
@@ -39,6 +46,9 @@ func NewBalanceManager(logger *logrus.Logger) *BalanceManager {
 		balances:    make(map[string]map[string]*decimal.Decimal),
 		lockedFunds: make(map[string]map[string]*decimal.Decimal),
 		orderLocks:  make(map[string]*OrderLock),
+		withdrawal:  newWithdrawalSettings(),
+		deposits:    make(map[string]*DepositRecord),
+		withdrawals: make(map[uuid.UUID]*WithdrawalRecord),
 		logger:      logger,
 	}
 
@@ -62,6 +72,9 @@ func (bm *BalanceManager) SetBalance(userAddress, token string, amount decimal.D
 	}
 
 	bm.balances[userAddress][token] = &amount
+	if err := bm.persistUnsafe(balanceKey{userAddress, token}); err != nil {
+		bm.logger.WithError(err).WithField("user", userAddress).Error("Failed to persist balance")
+	}
 	
 	bm.logger.WithFields(logrus.Fields{
 		"user":   userAddress,
@@ -100,59 +113,90 @@ func (bm *BalanceManager) GetAvailableBalance(userAddress, token string) decimal
 	return totalBalance.Sub(lockedAmount)
 }
 
-// LockFundsForOrder 为订单锁定资金
-func (bm *BalanceManager) LockFundsForOrder(order *types.SignedOrder) error {
-	bm.mu.Lock()
-	defer bm.mu.Unlock()
+// 内部辅助函数
 
-	var tokenToLock string
-	var amountToLock decimal.Decimal
+// getAvailableBalanceUnsafe 获取可用余额（不加锁版本）
+func (bm *BalanceManager) getAvailableBalanceUnsafe(userAddress, token string) decimal.Decimal {
+	totalBalance := decimal.Zero
+	lockedAmount := decimal.Zero
 
-	// 确定需要锁定的代币和数量
-	if order.Side == types.OrderSideBuy {
-		// 买单锁定报价代币（如USDC）
-		tokenToLock = order.QuoteToken
-		// 锁定金额 = 价格 × 数量
-		amountToLock = order.Price.Mul(order.Amount)
-	} else {
-		// 卖单锁定基础代币（如WETH）
-		tokenToLock = order.BaseToken
-		amountToLock = order.Amount
+	if bm.balances[userAddress] != nil && bm.balances[userAddress][token] != nil {
+		totalBalance = *bm.balances[userAddress][token]
 	}
 
-	// 检查可用余额
-	availableBalance := bm.getAvailableBalanceUnsafe(order.UserAddress, tokenToLock)
-	if availableBalance.LessThan(amountToLock) {
-		return fmt.Errorf("insufficient balance: need %s, available %s", 
-			amountToLock.String(), availableBalance.String())
+	if bm.lockedFunds[userAddress] != nil && bm.lockedFunds[userAddress][token] != nil {
+		lockedAmount = *bm.lockedFunds[userAddress][token]
 	}
 
-	// 初始化锁定资金映射
-	if bm.lockedFunds[order.UserAddress] == nil {
-		bm.lockedFunds[order.UserAddress] = make(map[string]*decimal.Decimal)
+	return totalBalance.Sub(lockedAmount)
+}
+
+// transferUnsafe 转移资金（不加锁版本）
+// 只能转出可用余额，不会动用用户其他订单锁定的资金
+func (bm *BalanceManager) transferUnsafe(from, to, token string, amount decimal.Decimal) error {
+	// 确保映射存在
+	if bm.balances[from] == nil {
+		bm.balances[from] = make(map[string]*decimal.Decimal)
+	}
+	if bm.balances[to] == nil {
+		bm.balances[to] = make(map[string]*decimal.Decimal)
 	}
-	if bm.lockedFunds[order.UserAddress][tokenToLock] == nil {
+	if bm.balances[from][token] == nil {
+		zero := decimal.Zero
+		bm.balances[from][token] = &zero
+	}
+	if bm.balances[to][token] == nil {
 		zero := decimal.Zero
-		bm.lockedFunds[order.UserAddress][tokenToLock] = &zero
+		bm.balances[to][token] = &zero
+	}
+
+	// 检查可用余额
+	fromBalance := *bm.balances[from][token]
+	if available := bm.getAvailableBalanceUnsafe(from, token); available.LessThan(amount) {
+		return fmt.Errorf("insufficient available balance for transfer: need %s, available %s", amount.String(), available.String())
 	}
 
-	// 增加锁定金额
-	currentLocked := *bm.lockedFunds[order.UserAddress][tokenToLock]
-	newLocked := currentLocked.Add(amountToLock)
-	bm.lockedFunds[order.UserAddress][tokenToLock] = &newLocked
+	// 执行转移
+	newFromBalance := fromBalance.Sub(amount)
+	toBalance := *bm.balances[to][token]
+	newToBalance := toBalance.Add(amount)
 
-	// 记录订单锁定信息
-	orderID := fmt.Sprintf("%s_%d", order.UserAddress, order.Nonce)
-	
-	var expiresAt *time.Time
-	if order.ExpiresAt != nil {
-		expiresAt = order.ExpiresAt
+	bm.balances[from][token] = &newFromBalance
+	bm.balances[to][token] = &newToBalance
+	bm.markChangedUnsafe(balanceKey{from, token}, balanceKey{to, token})
+
+	return nil
+}
+
+// lockUnsafe 锁定订单资金（不加锁版本）
+// 买单锁定 价格×数量 的报价代币，卖单锁定数量对应的基础代币
+func (bm *BalanceManager) lockUnsafe(orderID, userAddress string, side types.OrderSide, baseToken, quoteToken string,
+	price, amount decimal.Decimal, expiresAt *time.Time) error {
+	if _, exists := bm.orderLocks[orderID]; exists {
+		return fmt.Errorf("funds already locked for order %s", orderID)
+	}
+
+	tokenToLock := baseToken
+	amountToLock := amount
+	if side == types.OrderSideBuy {
+		tokenToLock = quoteToken
+		amountToLock = price.Mul(amount)
+	}
+
+	// 检查可用余额
+	availableBalance := bm.getAvailableBalanceUnsafe(userAddress, tokenToLock)
+	if availableBalance.LessThan(amountToLock) {
+		return fmt.Errorf("insufficient balance: need %s, available %s",
+			amountToLock.String(), availableBalance.String())
 	}
 
+	bm.addLockedUnsafe(userAddress, tokenToLock, amountToLock)
 	bm.orderLocks[orderID] = &OrderLock{
 		OrderID:     orderID,
-		UserAddress: order.UserAddress,
+		UserAddress: userAddress,
 		Token:       tokenToLock,
+		Side:        side,
+		Price:       price,
 		Amount:      amountToLock,
 		CreatedAt:   time.Now(),
 		ExpiresAt:   expiresAt,
@@ -160,41 +204,23 @@ func (bm *BalanceManager) LockFundsForOrder(order *types.SignedOrder) error {
 
 	bm.logger.WithFields(logrus.Fields{
 		"order_id": orderID,
-		"user":     order.UserAddress,
+		"user":     userAddress,
 		"token":    tokenToLock,
 		"amount":   amountToLock.String(),
-		"side":     order.Side,
+		"side":     side,
 	}).Info("🔒 Funds locked for order")
 
 	return nil
 }
 
-// UnlockFundsForOrder 解锁订单资金（订单取消时）
-func (bm *BalanceManager) UnlockFundsForOrder(orderID string) error {
-	bm.mu.Lock()
-	defer bm.mu.Unlock()
-
+// releaseUnsafe 释放订单剩余的锁定资金，订单没有锁定时返回 false
+func (bm *BalanceManager) releaseUnsafe(orderID string) bool {
 	lock, exists := bm.orderLocks[orderID]
 	if !exists {
-		return fmt.Errorf("order lock not found: %s", orderID)
+		return false
 	}
 
-	// 减少锁定金额
-	if bm.lockedFunds[lock.UserAddress] != nil && 
-	   bm.lockedFunds[lock.UserAddress][lock.Token] != nil {
-		
-		currentLocked := *bm.lockedFunds[lock.UserAddress][lock.Token]
-		newLocked := currentLocked.Sub(lock.Amount)
-		
-		// 确保不会出现负数
-		if newLocked.IsNegative() {
-			newLocked = decimal.Zero
-		}
-		
-		bm.lockedFunds[lock.UserAddress][lock.Token] = &newLocked
-	}
-
-	// 删除锁定记录
+	bm.addLockedUnsafe(lock.UserAddress, lock.Token, lock.Amount.Neg())
 	delete(bm.orderLocks, orderID)
 
 	bm.logger.WithFields(logrus.Fields{
@@ -204,57 +230,48 @@ func (bm *BalanceManager) UnlockFundsForOrder(orderID string) error {
 		"amount":   lock.Amount.String(),
 	}).Info("🔓 Funds unlocked for order")
 
-	return nil
+	return true
 }
 
-// ExecuteTrade 执行交易（转移资金）
-func (bm *BalanceManager) ExecuteTrade(
-	takerOrder *types.SignedOrder,
-	makerOrder *types.SignedOrder,
-	fillPrice decimal.Decimal,
-	fillAmount decimal.Decimal,
-) error {
-	bm.mu.Lock()
-	defer bm.mu.Unlock()
+// addLockedUnsafe 调整用户锁定资金总额（不会低于0）
+func (bm *BalanceManager) addLockedUnsafe(userAddress, token string, delta decimal.Decimal) {
+	if bm.lockedFunds[userAddress] == nil {
+		bm.lockedFunds[userAddress] = make(map[string]*decimal.Decimal)
+	}
+	locked := delta
+	if current := bm.lockedFunds[userAddress][token]; current != nil {
+		locked = current.Add(delta)
+	}
+	if locked.IsNegative() {
+		locked = decimal.Zero
+	}
+	bm.lockedFunds[userAddress][token] = &locked
+	bm.markChangedUnsafe(balanceKey{userAddress, token})
+}
 
-	// 计算交易金额
+// settleUnsafe 按成交在买卖双方之间转移资金（不加锁版本）
+// 买方：基础代币增加，报价代币减少；卖方相反
+func (bm *BalanceManager) settleUnsafe(buyer, seller, baseToken, quoteToken string, fillPrice, fillAmount decimal.Decimal) error {
 	quoteAmount := fillPrice.Mul(fillAmount)
 
-	var (
-		buyer  string
-		seller string
-		baseToken  = takerOrder.BaseToken
-		quoteToken = takerOrder.QuoteToken
-	)
-
-	// 确定买方和卖方
-	if takerOrder.Side == types.OrderSideBuy {
-		buyer = takerOrder.UserAddress
-		seller = makerOrder.UserAddress
-	} else {
-		buyer = makerOrder.UserAddress
-		seller = takerOrder.UserAddress
-	}
-
-	// 执行资金转移
-	// 买方：基础代币增加，报价代币减少
 	if err := bm.transferUnsafe(seller, buyer, baseToken, fillAmount); err != nil {
 		return fmt.Errorf("failed to transfer base token: %w", err)
 	}
-	
-	// 卖方：报价代币增加，基础代币减少
 	if err := bm.transferUnsafe(buyer, seller, quoteToken, quoteAmount); err != nil {
 		// 回滚基础代币转移
 		bm.transferUnsafe(buyer, seller, baseToken, fillAmount)
 		return fmt.Errorf("failed to transfer quote token: %w", err)
 	}
 
-	// 减少相应的锁定资金
-	takerOrderID := fmt.Sprintf("%s_%d", takerOrder.UserAddress, takerOrder.Nonce)
-	makerOrderID := fmt.Sprintf("%s_%d", makerOrder.UserAddress, makerOrder.Nonce)
-
-	bm.reduceLockForFillUnsafe(takerOrderID, takerOrder, fillAmount)
-	bm.reduceLockForFillUnsafe(makerOrderID, makerOrder, fillAmount)
+	if err := bm.persistUnsafe(
+		balanceKey{buyer, baseToken}, balanceKey{seller, baseToken},
+		balanceKey{buyer, quoteToken}, balanceKey{seller, quoteToken},
+	); err != nil {
+		// 持久化失败时回滚，保持内存与存储一致
+		bm.transferUnsafe(seller, buyer, quoteToken, quoteAmount)
+		bm.transferUnsafe(buyer, seller, baseToken, fillAmount)
+		return fmt.Errorf("failed to persist balances: %w", err)
+	}
 
 	bm.logger.WithFields(logrus.Fields{
 		"buyer":        buyer,
@@ -269,98 +286,49 @@ func (bm *BalanceManager) ExecuteTrade(
 	return nil
 }
 
-// 内部辅助函数
-
-// getAvailableBalanceUnsafe 获取可用余额（不加锁版本）
-func (bm *BalanceManager) getAvailableBalanceUnsafe(userAddress, token string) decimal.Decimal {
-	totalBalance := decimal.Zero
-	lockedAmount := decimal.Zero
-
-	if bm.balances[userAddress] != nil && bm.balances[userAddress][token] != nil {
-		totalBalance = *bm.balances[userAddress][token]
-	}
-
-	if bm.lockedFunds[userAddress] != nil && bm.lockedFunds[userAddress][token] != nil {
-		lockedAmount = *bm.lockedFunds[userAddress][token]
-	}
-
-	return totalBalance.Sub(lockedAmount)
-}
-
-// transferUnsafe 转移资金（不加锁版本）
-func (bm *BalanceManager) transferUnsafe(from, to, token string, amount decimal.Decimal) error {
-	// 确保映射存在
-	if bm.balances[from] == nil {
-		bm.balances[from] = make(map[string]*decimal.Decimal)
-	}
-	if bm.balances[to] == nil {
-		bm.balances[to] = make(map[string]*decimal.Decimal)
-	}
-	if bm.balances[from][token] == nil {
-		zero := decimal.Zero
-		bm.balances[from][token] = &zero
-	}
-	if bm.balances[to][token] == nil {
-		zero := decimal.Zero
-		bm.balances[to][token] = &zero
-	}
-
-	// 检查余额
-	fromBalance := *bm.balances[from][token]
-	if fromBalance.LessThan(amount) {
-		return fmt.Errorf("insufficient balance for transfer")
-	}
-
-	// 执行转移
-	newFromBalance := fromBalance.Sub(amount)
-	toBalance := *bm.balances[to][token]
-	newToBalance := toBalance.Add(amount)
-
-	bm.balances[from][token] = &newFromBalance
-	bm.balances[to][token] = &newToBalance
-
-	return nil
+// lockRelease 成交释放的订单锁定，成交记账失败时用于恢复
+type lockRelease struct {
+	lock   *OrderLock
+	amount decimal.Decimal
 }
 
 // reduceLockForFillUnsafe 减少订单锁定金额（部分成交时）
-func (bm *BalanceManager) reduceLockForFillUnsafe(orderID string, order *types.SignedOrder, fillAmount decimal.Decimal) {
+// 买单按锁定价格释放，成交价优于锁定价格的差额随之变为可用；未设置锁定价格时按成交价释放
+func (bm *BalanceManager) reduceLockForFillUnsafe(orderID string, fillPrice, fillAmount decimal.Decimal) *lockRelease {
 	lock, exists := bm.orderLocks[orderID]
 	if !exists {
-		return
+		return nil
 	}
 
-	var amountToUnlock decimal.Decimal
-	if order.Side == types.OrderSideBuy {
-		// 买单：解锁 = 成交价格 × 成交数量
-		amountToUnlock = order.Price.Mul(fillAmount)
-	} else {
-		// 卖单：解锁 = 成交数量
-		amountToUnlock = fillAmount
+	amountToUnlock := fillAmount
+	if lock.Side == types.OrderSideBuy {
+		price := lock.Price
+		if price.IsZero() {
+			price = fillPrice
+		}
+		amountToUnlock = price.Mul(fillAmount)
+	}
+	if amountToUnlock.GreaterThan(lock.Amount) {
+		amountToUnlock = lock.Amount
 	}
 
-	// 更新锁定金额
-	newLockAmount := lock.Amount.Sub(amountToUnlock)
-	if newLockAmount.IsNegative() || newLockAmount.IsZero() {
+	lock.Amount = lock.Amount.Sub(amountToUnlock)
+	if !lock.Amount.IsPositive() {
 		// 完全成交，删除锁定
 		delete(bm.orderLocks, orderID)
-	} else {
-		// 部分成交，更新锁定金额
-		lock.Amount = newLockAmount
 	}
+	bm.addLockedUnsafe(lock.UserAddress, lock.Token, amountToUnlock.Neg())
+	return &lockRelease{lock: lock, amount: amountToUnlock}
+}
 
-	// 更新用户锁定资金总额
-	if bm.lockedFunds[order.UserAddress] != nil && 
-	   bm.lockedFunds[order.UserAddress][lock.Token] != nil {
-		
-		currentLocked := *bm.lockedFunds[order.UserAddress][lock.Token]
-		newLocked := currentLocked.Sub(amountToUnlock)
-		
-		if newLocked.IsNegative() {
-			newLocked = decimal.Zero
-		}
-		
-		bm.lockedFunds[order.UserAddress][lock.Token] = &newLocked
+// restoreLockUnsafe 恢复成交释放的订单锁定
+func (bm *BalanceManager) restoreLockUnsafe(release *lockRelease) {
+	if release == nil {
+		return
 	}
+	release.lock.Amount = release.lock.Amount.Add(release.amount)
+	bm.orderLocks[release.lock.OrderID] = release.lock
+	bm.addLockedUnsafe(release.lock.UserAddress, release.lock.Token, release.amount)
 }
 
 // expiredLockCleaner 过期锁定清理器
@@ -402,6 +370,7 @@ func (bm *BalanceManager) cleanExpiredLocks() {
 			}
 			
 			bm.lockedFunds[lock.UserAddress][lock.Token] = &newLocked
+			bm.markChangedUnsafe(balanceKey{lock.UserAddress, lock.Token})
 		}
 
 		// 删除过期锁定
@@ -0,0 +1,98 @@
+package wallet
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+)
+
+// DepositRecord 充值入账记录
+type DepositRecord struct {
+	DepositID   string          `json:"deposit_id"` // 充值唯一标识（如链上 txHash:logIndex），用于去重
+	UserAddress string          `json:"user_address"`
+	Token       string          `json:"token"`
+	Amount      decimal.Decimal `json:"amount"`
+	Source      string          `json:"source,omitempty"`
+	CreditedAt  time.Time       `json:"credited_at"`
+}
+
+// CreditDeposit 充值入账，同一 deposit_id 只入账一次
+// 重复提交时返回首次入账的记录且 credited 为 false
+func (bm *BalanceManager) CreditDeposit(depositID, userAddress, token string, amount decimal.Decimal, source string) (record *DepositRecord, credited bool, err error) {
+	if depositID == "" || userAddress == "" || token == "" {
+		return nil, false, fmt.Errorf("deposit_id, user_address and token are required")
+	}
+	if !amount.IsPositive() {
+		return nil, false, fmt.Errorf("deposit amount must be positive")
+	}
+
+	bm.mu.Lock()
+	defer bm.mu.Unlock()
+
+	if existing, exists := bm.deposits[depositID]; exists {
+		copied := *existing
+		return &copied, false, nil
+	}
+
+	if bm.balances[userAddress] == nil {
+		bm.balances[userAddress] = make(map[string]*decimal.Decimal)
+	}
+	balance := amount
+	if current := bm.balances[userAddress][token]; current != nil {
+		balance = current.Add(amount)
+	}
+
+	record = &DepositRecord{
+		DepositID:   depositID,
+		UserAddress: userAddress,
+		Token:       token,
+		Amount:      amount,
+		Source:      source,
+		CreditedAt:  time.Now(),
+	}
+	if bm.store != nil {
+		update := BalanceUpdate{UserAddress: userAddress, Token: token, Total: balance}
+		if err := bm.store.SaveDeposit(record, update); err != nil {
+			return nil, false, fmt.Errorf("failed to persist deposit: %w", err)
+		}
+	}
+
+	bm.balances[userAddress][token] = &balance
+	bm.deposits[depositID] = record
+	bm.markChangedUnsafe(balanceKey{userAddress, token})
+
+	bm.logger.WithFields(logrus.Fields{
+		"deposit_id": depositID,
+		"user":       userAddress,
+		"token":      token,
+		"amount":     amount.String(),
+		"balance":    balance.String(),
+		"source":     source,
+	}).Info("💰 Deposit credited")
+
+	copied := *record
+	return &copied, true, nil
+}
+
+// GetTokenBalance 获取用户单个代币的余额信息
+func (bm *BalanceManager) GetTokenBalance(userAddress, token string) BalanceInfo {
+	bm.mu.RLock()
+	defer bm.mu.RUnlock()
+
+	total := decimal.Zero
+	if bm.balances[userAddress] != nil && bm.balances[userAddress][token] != nil {
+		total = *bm.balances[userAddress][token]
+	}
+	locked := decimal.Zero
+	if bm.lockedFunds[userAddress] != nil && bm.lockedFunds[userAddress][token] != nil {
+		locked = *bm.lockedFunds[userAddress][token]
+	}
+
+	return BalanceInfo{
+		Total:     total,
+		Locked:    locked,
+		Available: total.Sub(locked),
+	}
+}
@@ -0,0 +1,170 @@
+package wallet
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+
+	"orderbook-engine/internal/matching"
+	"orderbook-engine/internal/types"
+)
+
+// OrderSource 订单查询（用于获取maker订单）
+type OrderSource interface {
+	GetOrder(orderID uuid.UUID) (*types.Order, error)
+}
+
+// LockOrder 为撮合订单锁定资金，锁定记录以订单ID为键
+// lockPrice 为买单锁定价格：限价单为订单价格，市价单为按当前订单簿估算的最差成交价
+func (bm *BalanceManager) LockOrder(order *types.Order, lockPrice decimal.Decimal) error {
+	bm.mu.Lock()
+	defer bm.mu.Unlock()
+
+	return bm.lockUnsafe(order.ID.String(), order.UserAddress, order.Side, order.BaseToken, order.QuoteToken,
+		lockPrice, order.GetRemainingAmount(), order.ExpiresAt)
+}
+
+// ReleaseOrder 释放订单剩余的锁定资金（撤单、过期或订单不再挂单时），订单没有锁定时返回 false
+func (bm *BalanceManager) ReleaseOrder(orderID uuid.UUID) bool {
+	bm.mu.Lock()
+	defer bm.mu.Unlock()
+
+	return bm.releaseUnsafe(orderID.String())
+}
+
+// ShrinkOrderLock 按订单缩减后的剩余数量释放多余的锁定资金（只减仓订单缩量时）
+func (bm *BalanceManager) ShrinkOrderLock(orderID uuid.UUID, remaining decimal.Decimal) {
+	bm.mu.Lock()
+	defer bm.mu.Unlock()
+
+	lock, exists := bm.orderLocks[orderID.String()]
+	if !exists {
+		return
+	}
+	needed := remaining
+	if lock.Side == types.OrderSideBuy {
+		needed = lock.Price.Mul(remaining)
+	}
+	if !needed.LessThan(lock.Amount) {
+		return
+	}
+
+	released := lock.Amount.Sub(needed)
+	lock.Amount = needed
+	bm.addLockedUnsafe(lock.UserAddress, lock.Token, released.Neg())
+}
+
+// ApplyFill 按撮合成交扣减双方订单的锁定并转移双方资金
+// 先释放成交对应的锁定再转移：转移只能动用可用余额，超出订单锁定的成交不会占用用户其他订单锁定的资金
+func (bm *BalanceManager) ApplyFill(fill *types.Fill, takerOrder, makerOrder *types.Order) error {
+	bm.mu.Lock()
+	defer bm.mu.Unlock()
+
+	buyer, seller := takerOrder.UserAddress, makerOrder.UserAddress
+	if takerOrder.Side == types.OrderSideSell {
+		buyer, seller = seller, buyer
+	}
+
+	takerRelease := bm.reduceLockForFillUnsafe(takerOrder.ID.String(), fill.Price, fill.Amount)
+	makerRelease := bm.reduceLockForFillUnsafe(makerOrder.ID.String(), fill.Price, fill.Amount)
+	if err := bm.settleUnsafe(buyer, seller, takerOrder.BaseToken, takerOrder.QuoteToken, fill.Price, fill.Amount); err != nil {
+		bm.restoreLockUnsafe(makerRelease)
+		bm.restoreLockUnsafe(takerRelease)
+		return fmt.Errorf("fill %s: %w", fill.ID, err)
+	}
+	return nil
+}
+
+// RevertFill 回滚已记账的成交（成交未能在链上结算时），双方资金按原成交反向转移
+// 订单锁定不恢复：成交对应的订单数量已被消耗，被回滚的资金直接变为可用
+func (bm *BalanceManager) RevertFill(fill *types.Fill, takerOrder, makerOrder *types.Order) error {
+	bm.mu.Lock()
+	defer bm.mu.Unlock()
+
+	buyer, seller := takerOrder.UserAddress, makerOrder.UserAddress
+	if takerOrder.Side == types.OrderSideSell {
+		buyer, seller = seller, buyer
+	}
+
+	// 反向成交：原卖方买回基础代币，原买方收回报价代币
+	if err := bm.settleUnsafe(seller, buyer, takerOrder.BaseToken, takerOrder.QuoteToken, fill.Price, fill.Amount); err != nil {
+		return fmt.Errorf("revert fill %s: %w", fill.ID, err)
+	}
+	return nil
+}
+
+// Ledger 内部余额记账
+// 消费撮合事件（含集合竞价成交）：成交时转移双方资金，taker 未挂单的剩余部分、撤单（含签名过期撤单）及订单过期释放锁定，
+// 挂单缩量时释放多余的锁定
+type Ledger struct {
+	balances *BalanceManager
+	orders   OrderSource
+	logger   *logrus.Logger
+}
+
+// NewLedger 创建内部余额记账
+func NewLedger(balances *BalanceManager, orders OrderSource, logger *logrus.Logger) *Ledger {
+	return &Ledger{
+		balances: balances,
+		orders:   orders,
+		logger:   logger,
+	}
+}
+
+// Run 消费撮合事件直到订阅关闭
+func (l *Ledger) Run(sub *matching.Subscription) {
+	for event := range sub.Events() {
+		if event.Order == nil {
+			continue
+		}
+
+		switch event.Type {
+		case matching.EventOrderAdded, matching.EventAuctionUncrossed:
+			for _, fill := range event.Fills {
+				l.applyFill(fill, event.Order)
+			}
+			// 市价单、IOC 等成交后不挂单的剩余部分
+			if !event.Order.IsActive() {
+				l.balances.ReleaseOrder(event.Order.ID)
+			}
+		case matching.EventOrderCancelled, matching.EventOrderExpired:
+			l.balances.ReleaseOrder(event.Order.ID)
+		case matching.EventOrderReduced:
+			l.balances.ShrinkOrderLock(event.Order.ID, event.Order.GetRemainingAmount())
+		}
+	}
+}
+
+// RevertFill 回滚未能上链结算的成交，双方订单从存储中查询
+func (l *Ledger) RevertFill(fill *types.Fill) error {
+	takerOrder, err := l.orders.GetOrder(fill.TakerOrderID)
+	if err != nil {
+		return fmt.Errorf("load taker order: %w", err)
+	}
+	makerOrder, err := l.orders.GetOrder(fill.MakerOrderID)
+	if err != nil {
+		return fmt.Errorf("load maker order: %w", err)
+	}
+	return l.balances.RevertFill(fill, takerOrder, makerOrder)
+}
+
+// applyFill 记账单笔成交，taker为事件中的订单快照，maker从存储中查询
+func (l *Ledger) applyFill(fill *types.Fill, takerOrder *types.Order) {
+	logger := l.logger.WithFields(logrus.Fields{
+		"fill_id":        fill.ID.String(),
+		"taker_order_id": fill.TakerOrderID.String(),
+		"maker_order_id": fill.MakerOrderID.String(),
+	})
+
+	makerOrder, err := l.orders.GetOrder(fill.MakerOrderID)
+	if err != nil {
+		logger.WithError(err).Error("Failed to load maker order for balance transfer")
+		return
+	}
+
+	if err := l.balances.ApplyFill(fill, takerOrder, makerOrder); err != nil {
+		logger.WithError(err).Error("Failed to apply fill to internal balances")
+	}
+}
@@ -0,0 +1,133 @@
+package wallet
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"orderbook-engine/internal/types"
+)
+
+func TestOrderLocksFollowFills(t *testing.T) {
+	bm := NewBalanceManager(logrus.New())
+	bm.SetBalance("buyer", "USDC", decimal.NewFromInt(1000))
+	bm.SetBalance("seller", "WETH", decimal.NewFromInt(2))
+
+	buy := &types.Order{ID: uuid.New(), UserAddress: "buyer", Side: types.OrderSideBuy, BaseToken: "WETH", QuoteToken: "USDC",
+		Price: decimal.NewFromInt(400), Amount: decimal.NewFromInt(2)}
+	sell := &types.Order{ID: uuid.New(), UserAddress: "seller", Side: types.OrderSideSell, BaseToken: "WETH", QuoteToken: "USDC",
+		Price: decimal.NewFromInt(300), Amount: decimal.NewFromInt(1)}
+
+	require.NoError(t, bm.LockOrder(buy, buy.Price))
+	require.NoError(t, bm.LockOrder(sell, sell.Price))
+	assert.True(t, bm.GetAvailableBalance("buyer", "USDC").Equal(decimal.NewFromInt(200)))
+
+	// 可用余额不足时拒绝锁定
+	extra := &types.Order{ID: uuid.New(), UserAddress: "buyer", Side: types.OrderSideBuy, BaseToken: "WETH", QuoteToken: "USDC",
+		Price: decimal.NewFromInt(400), Amount: decimal.NewFromInt(1)}
+	assert.Error(t, bm.LockOrder(extra, extra.Price))
+
+	// 买单吃卖单 1 WETH @ 300，按锁定价格释放 400，价差变为可用
+	fill := &types.Fill{ID: uuid.New(), Price: decimal.NewFromInt(300), Amount: decimal.NewFromInt(1)}
+	require.NoError(t, bm.ApplyFill(fill, buy, sell))
+
+	assert.True(t, bm.GetBalance("buyer", "USDC").Equal(decimal.NewFromInt(700)))
+	assert.True(t, bm.GetAvailableBalance("buyer", "USDC").Equal(decimal.NewFromInt(300)))
+	assert.True(t, bm.GetBalance("buyer", "WETH").Equal(decimal.NewFromInt(1)))
+	assert.True(t, bm.GetBalance("seller", "USDC").Equal(decimal.NewFromInt(300)))
+	assert.True(t, bm.GetAvailableBalance("seller", "WETH").Equal(decimal.NewFromInt(1)))
+	assert.False(t, bm.ReleaseOrder(sell.ID), "fully filled order keeps no lock")
+
+	// 撤销剩余买单释放剩余锁定
+	assert.True(t, bm.ReleaseOrder(buy.ID))
+	assert.True(t, bm.GetAvailableBalance("buyer", "USDC").Equal(decimal.NewFromInt(700)))
+}
+
+func TestFillCannotSpendOtherOrderLocks(t *testing.T) {
+	bm := NewBalanceManager(logrus.New())
+	bm.SetBalance("buyer", "USDC", decimal.NewFromInt(1000))
+	bm.SetBalance("seller", "WETH", decimal.NewFromInt(1))
+
+	// 市价买单按 400 锁定，另一笔限价买单锁定剩余的 600
+	market := &types.Order{ID: uuid.New(), UserAddress: "buyer", Side: types.OrderSideBuy, Type: types.OrderTypeMarket,
+		BaseToken: "WETH", QuoteToken: "USDC", Amount: decimal.NewFromInt(1)}
+	resting := &types.Order{ID: uuid.New(), UserAddress: "buyer", Side: types.OrderSideBuy, BaseToken: "WETH", QuoteToken: "USDC",
+		Price: decimal.NewFromInt(300), Amount: decimal.NewFromInt(2)}
+	sell := &types.Order{ID: uuid.New(), UserAddress: "seller", Side: types.OrderSideSell, BaseToken: "WETH", QuoteToken: "USDC",
+		Price: decimal.NewFromInt(500), Amount: decimal.NewFromInt(1)}
+	require.NoError(t, bm.LockOrder(market, decimal.NewFromInt(400)))
+	require.NoError(t, bm.LockOrder(resting, resting.Price))
+	require.NoError(t, bm.LockOrder(sell, sell.Price))
+
+	// 成交价高于锁定价格，超出部分需要动用限价买单锁定的资金，拒绝记账且锁定不变
+	fill := &types.Fill{ID: uuid.New(), Price: decimal.NewFromInt(500), Amount: decimal.NewFromInt(1)}
+	assert.Error(t, bm.ApplyFill(fill, market, sell))
+	assert.True(t, bm.GetBalance("buyer", "USDC").Equal(decimal.NewFromInt(1000)))
+	assert.True(t, bm.GetAvailableBalance("buyer", "USDC").IsZero())
+	assert.True(t, bm.GetAvailableBalance("seller", "WETH").IsZero())
+
+	// 撤销限价买单后可用余额足够
+	assert.True(t, bm.ReleaseOrder(resting.ID))
+	require.NoError(t, bm.ApplyFill(fill, market, sell))
+	assert.True(t, bm.GetBalance("buyer", "USDC").Equal(decimal.NewFromInt(500)))
+	assert.True(t, bm.GetAvailableBalance("buyer", "USDC").Equal(decimal.NewFromInt(500)))
+	assert.False(t, bm.ReleaseOrder(market.ID), "fully filled order keeps no lock")
+}
+
+func TestRevertFillRestoresBalances(t *testing.T) {
+	bm := NewBalanceManager(logrus.New())
+	bm.SetBalance("buyer", "USDC", decimal.NewFromInt(1000))
+	bm.SetBalance("seller", "WETH", decimal.NewFromInt(2))
+
+	// 卖方吃买单 1 WETH @ 300
+	buy := &types.Order{ID: uuid.New(), UserAddress: "buyer", Side: types.OrderSideBuy, BaseToken: "WETH", QuoteToken: "USDC",
+		Price: decimal.NewFromInt(300), Amount: decimal.NewFromInt(1)}
+	sell := &types.Order{ID: uuid.New(), UserAddress: "seller", Side: types.OrderSideSell, BaseToken: "WETH", QuoteToken: "USDC",
+		Price: decimal.NewFromInt(300), Amount: decimal.NewFromInt(1)}
+	fill := &types.Fill{ID: uuid.New(), Price: decimal.NewFromInt(300), Amount: decimal.NewFromInt(1)}
+	require.NoError(t, bm.ApplyFill(fill, sell, buy))
+	assert.True(t, bm.GetBalance("buyer", "WETH").Equal(decimal.NewFromInt(1)))
+
+	require.NoError(t, bm.RevertFill(fill, sell, buy))
+	assert.True(t, bm.GetBalance("buyer", "USDC").Equal(decimal.NewFromInt(1000)))
+	assert.True(t, bm.GetBalance("buyer", "WETH").IsZero())
+	assert.True(t, bm.GetBalance("seller", "WETH").Equal(decimal.NewFromInt(2)))
+	assert.True(t, bm.GetBalance("seller", "USDC").IsZero())
+
+	// 买方已转出基础代币时无法回滚
+	require.NoError(t, bm.ApplyFill(fill, sell, buy))
+	bm.SetBalance("buyer", "WETH", decimal.Zero)
+	assert.Error(t, bm.RevertFill(fill, sell, buy))
+}
+
+func TestBalanceChangeNotifications(t *testing.T) {
+	bm := NewBalanceManager(logrus.New())
+	updates := make(chan *types.BalanceUpdate, 16)
+	bm.SetChangeHandler(func(update *types.BalanceUpdate) { updates <- update })
+
+	bm.SetBalance("buyer", "USDC", decimal.NewFromInt(1000))
+	buy := &types.Order{ID: uuid.New(), UserAddress: "buyer", Side: types.OrderSideBuy, BaseToken: "WETH", QuoteToken: "USDC",
+		Price: decimal.NewFromInt(400), Amount: decimal.NewFromInt(1)}
+	require.NoError(t, bm.LockOrder(buy, buy.Price))
+
+	// 回调携带变化后的余额，连续变化可能被合并为一次
+	deadline := time.After(time.Second)
+	for {
+		select {
+		case update := <-updates:
+			assert.Equal(t, "buyer", update.UserAddress)
+			assert.Equal(t, "USDC", update.Token)
+			if update.Locked.Equal(decimal.NewFromInt(400)) {
+				assert.True(t, update.Available.Equal(decimal.NewFromInt(600)))
+				return
+			}
+		case <-deadline:
+			t.Fatal("balance lock not notified")
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package wallet
+
+import (
+	"time"
+
+	"orderbook-engine/internal/types"
+)
+
+// ChangeHandler 余额变化回调（总额或锁定额变化后调用，携带变化后的余额）
+type ChangeHandler func(update *types.BalanceUpdate)
+
+// SetChangeHandler 设置余额变化回调
+// 变化在持锁路径上只做标记，由独立协程合并后回调，回调不会阻塞下单和成交
+func (bm *BalanceManager) SetChangeHandler(handler ChangeHandler) {
+	bm.mu.Lock()
+	bm.changed = make(map[balanceKey]bool)
+	bm.changeSignal = make(chan struct{}, 1)
+	signal := bm.changeSignal
+	bm.mu.Unlock()
+
+	go bm.notifyChanges(signal, handler)
+}
+
+// markChangedUnsafe 标记余额变化（不加锁版本），未设置回调时不做处理
+func (bm *BalanceManager) markChangedUnsafe(keys ...balanceKey) {
+	if bm.changed == nil {
+		return
+	}
+	for _, key := range keys {
+		bm.changed[key] = true
+	}
+	select {
+	case bm.changeSignal <- struct{}{}:
+	default:
+	}
+}
+
+// notifyChanges 取出已标记的用户代币，按当前余额回调
+func (bm *BalanceManager) notifyChanges(signal <-chan struct{}, handler ChangeHandler) {
+	for range signal {
+		bm.mu.Lock()
+		changed := bm.changed
+		bm.changed = make(map[balanceKey]bool, len(changed))
+		bm.mu.Unlock()
+
+		now := time.Now()
+		for key := range changed {
+			info := bm.GetTokenBalance(key.user, key.token)
+			handler(&types.BalanceUpdate{
+				UserAddress: key.user,
+				Token:       key.token,
+				Total:       info.Total,
+				Locked:      info.Locked,
+				Available:   info.Available,
+				Timestamp:   now,
+			})
+		}
+	}
+}
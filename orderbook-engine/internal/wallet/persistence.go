@@ -0,0 +1,154 @@
+package wallet
+
+import (
+	"fmt"
+
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+
+	"orderbook-engine/internal/types"
+)
+
+// BalanceUpdate 单个用户代币的余额快照
+type BalanceUpdate struct {
+	UserAddress string
+	Token       string
+	Total       decimal.Decimal
+}
+
+// BalanceStore 余额持久化
+// 余额与充值记录在每次变更时写穿；订单锁定不落盘，启动时由活跃订单重新推导
+type BalanceStore interface {
+	LoadBalances() ([]BalanceUpdate, error)
+	LoadDeposits() ([]*DepositRecord, error)
+	SaveBalances(updates []BalanceUpdate) error                     // 同一次变更涉及的余额在一个事务内写入
+	SaveDeposit(record *DepositRecord, balance BalanceUpdate) error // 充值记录与入账后的余额在一个事务内写入
+}
+
+// balanceKey 用户代币
+type balanceKey struct {
+	user  string
+	token string
+}
+
+// SetBalanceStore 设置余额持久化，并从中恢复余额与已入账的充值
+// 需在接受订单之前调用
+func (bm *BalanceManager) SetBalanceStore(store BalanceStore) error {
+	balances, err := store.LoadBalances()
+	if err != nil {
+		return fmt.Errorf("failed to load balances: %w", err)
+	}
+	deposits, err := store.LoadDeposits()
+	if err != nil {
+		return fmt.Errorf("failed to load deposits: %w", err)
+	}
+
+	bm.mu.Lock()
+	defer bm.mu.Unlock()
+
+	for _, balance := range balances {
+		if bm.balances[balance.UserAddress] == nil {
+			bm.balances[balance.UserAddress] = make(map[string]*decimal.Decimal)
+		}
+		total := balance.Total
+		bm.balances[balance.UserAddress][balance.Token] = &total
+	}
+	for _, record := range deposits {
+		bm.deposits[record.DepositID] = record
+	}
+	bm.store = store
+
+	bm.logger.WithFields(logrus.Fields{
+		"balances": len(balances),
+		"deposits": len(deposits),
+	}).Info("Balances restored from store")
+	return nil
+}
+
+// RestoreOrderLocks 由活跃订单重新推导订单锁定（启动恢复），返回恢复的锁定数
+// 恢复时不检查可用余额：挂单已经占用资金，余额不足只记录告警
+func (bm *BalanceManager) RestoreOrderLocks(orders []*types.Order) int {
+	bm.mu.Lock()
+	defer bm.mu.Unlock()
+
+	return bm.restoreOrderLocksUnsafe(orders)
+}
+
+// ReplaceOrderLocks 丢弃现有的全部订单锁定并由活跃订单重新推导，返回恢复的锁定数
+// 用于备用实例接管：备用期间主实例的下单、撤单与成交不经过本实例，启动时推导的锁定已过时
+func (bm *BalanceManager) ReplaceOrderLocks(orders []*types.Order) int {
+	bm.mu.Lock()
+	defer bm.mu.Unlock()
+
+	for orderID, lock := range bm.orderLocks {
+		bm.addLockedUnsafe(lock.UserAddress, lock.Token, lock.Amount.Neg())
+		delete(bm.orderLocks, orderID)
+	}
+	return bm.restoreOrderLocksUnsafe(orders)
+}
+
+// restoreOrderLocksUnsafe 为尚无锁定的活跃订单推导锁定（调用方持有 bm.mu）
+func (bm *BalanceManager) restoreOrderLocksUnsafe(orders []*types.Order) int {
+	restored := 0
+	for _, order := range orders {
+		orderID := order.ID.String()
+		if !order.IsActive() || bm.orderLocks[orderID] != nil {
+			continue
+		}
+
+		remaining := order.GetRemainingAmount()
+		token, amount := order.BaseToken, remaining
+		if order.Side == types.OrderSideBuy {
+			token, amount = order.QuoteToken, order.Price.Mul(remaining)
+		}
+		if !amount.IsPositive() {
+			continue
+		}
+
+		if available := bm.getAvailableBalanceUnsafe(order.UserAddress, token); available.LessThan(amount) {
+			bm.logger.WithFields(logrus.Fields{
+				"order_id":  orderID,
+				"user":      order.UserAddress,
+				"token":     token,
+				"required":  amount.String(),
+				"available": available.String(),
+			}).Warn("Restored order lock exceeds available balance")
+		}
+
+		bm.addLockedUnsafe(order.UserAddress, token, amount)
+		bm.orderLocks[orderID] = &OrderLock{
+			OrderID:     orderID,
+			UserAddress: order.UserAddress,
+			Token:       token,
+			Side:        order.Side,
+			Price:       order.Price,
+			Amount:      amount,
+			CreatedAt:   order.CreatedAt,
+			ExpiresAt:   order.ExpiresAt,
+		}
+		restored++
+	}
+	return restored
+}
+
+// persistUnsafe 写穿持久化指定用户代币的当前余额并标记变化（不加锁版本），未设置存储时不做持久化
+func (bm *BalanceManager) persistUnsafe(keys ...balanceKey) error {
+	bm.markChangedUnsafe(keys...)
+	if bm.store == nil {
+		return nil
+	}
+	return bm.store.SaveBalances(bm.snapshotUnsafe(keys...))
+}
+
+// snapshotUnsafe 获取指定用户代币的当前余额
+func (bm *BalanceManager) snapshotUnsafe(keys ...balanceKey) []BalanceUpdate {
+	updates := make([]BalanceUpdate, 0, len(keys))
+	for _, key := range keys {
+		total := decimal.Zero
+		if bm.balances[key.user] != nil && bm.balances[key.user][key.token] != nil {
+			total = *bm.balances[key.user][key.token]
+		}
+		updates = append(updates, BalanceUpdate{UserAddress: key.user, Token: key.token, Total: total})
+	}
+	return updates
+}
@@ -0,0 +1,88 @@
+package wallet
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"orderbook-engine/internal/types"
+)
+
+// memoryBalanceStore 测试用余额存储
+type memoryBalanceStore struct {
+	balances map[balanceKey]decimal.Decimal
+	deposits []*DepositRecord
+}
+
+func (s *memoryBalanceStore) LoadBalances() ([]BalanceUpdate, error) {
+	var result []BalanceUpdate
+	for key, total := range s.balances {
+		result = append(result, BalanceUpdate{UserAddress: key.user, Token: key.token, Total: total})
+	}
+	return result, nil
+}
+
+func (s *memoryBalanceStore) LoadDeposits() ([]*DepositRecord, error) {
+	return s.deposits, nil
+}
+
+func (s *memoryBalanceStore) SaveBalances(updates []BalanceUpdate) error {
+	for _, update := range updates {
+		s.balances[balanceKey{update.UserAddress, update.Token}] = update.Total
+	}
+	return nil
+}
+
+func (s *memoryBalanceStore) SaveDeposit(record *DepositRecord, balance BalanceUpdate) error {
+	s.deposits = append(s.deposits, record)
+	return s.SaveBalances([]BalanceUpdate{balance})
+}
+
+func TestBalancesSurviveRestart(t *testing.T) {
+	store := &memoryBalanceStore{balances: make(map[balanceKey]decimal.Decimal)}
+
+	bm := NewBalanceManager(logrus.New())
+	require.NoError(t, bm.SetBalanceStore(store))
+	_, credited, err := bm.CreditDeposit("0xabc:0", "alice", "USDC", decimal.NewFromInt(500), "chain")
+	require.NoError(t, err)
+	require.True(t, credited)
+
+	// 重启后余额和充值去重记录从存储恢复，锁定由活跃订单重新推导
+	restarted := NewBalanceManager(logrus.New())
+	require.NoError(t, restarted.SetBalanceStore(store))
+	_, credited, err = restarted.CreditDeposit("0xabc:0", "alice", "USDC", decimal.NewFromInt(500), "chain")
+	require.NoError(t, err)
+	assert.False(t, credited)
+
+	order := &types.Order{ID: uuid.New(), UserAddress: "alice", Side: types.OrderSideBuy, BaseToken: "WETH", QuoteToken: "USDC",
+		Price: decimal.NewFromInt(100), Amount: decimal.NewFromInt(3), FilledAmount: decimal.NewFromInt(1), Status: types.OrderStatusPartiallyFilled}
+	assert.Equal(t, 1, restarted.RestoreOrderLocks([]*types.Order{order}))
+
+	info := restarted.GetTokenBalance("alice", "USDC")
+	assert.True(t, info.Total.Equal(decimal.NewFromInt(500)))
+	assert.True(t, info.Locked.Equal(decimal.NewFromInt(200)))
+}
+
+func TestReplaceOrderLocksOnTakeOver(t *testing.T) {
+	bm := NewBalanceManager(logrus.New())
+	bm.SetBalance("alice", "USDC", decimal.NewFromInt(500))
+
+	// 备用实例启动时恢复的挂单，此后已在主实例上撤销
+	cancelled := &types.Order{ID: uuid.New(), UserAddress: "alice", Side: types.OrderSideBuy, BaseToken: "WETH", QuoteToken: "USDC",
+		Price: decimal.NewFromInt(100), Amount: decimal.NewFromInt(3), Status: types.OrderStatusOpen}
+	require.Equal(t, 1, bm.RestoreOrderLocks([]*types.Order{cancelled}))
+
+	// 接管时只保留订单簿中的挂单
+	placed := &types.Order{ID: uuid.New(), UserAddress: "alice", Side: types.OrderSideBuy, BaseToken: "WETH", QuoteToken: "USDC",
+		Price: decimal.NewFromInt(50), Amount: decimal.NewFromInt(2), Status: types.OrderStatusOpen}
+	assert.Equal(t, 1, bm.ReplaceOrderLocks([]*types.Order{placed}))
+
+	info := bm.GetTokenBalance("alice", "USDC")
+	assert.True(t, info.Locked.Equal(decimal.NewFromInt(100)), info.Locked.String())
+	assert.False(t, bm.ReleaseOrder(cancelled.ID))
+	assert.True(t, bm.ReleaseOrder(placed.ID))
+}
@@ -0,0 +1,144 @@
+package wallet
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	_ "github.com/lib/pq"
+)
+
+// balanceSchema 余额与充值记录表
+var balanceSchema = []string{
+	`CREATE TABLE IF NOT EXISTS wallet_balances (
+		user_address TEXT NOT NULL,
+		token TEXT NOT NULL,
+		total NUMERIC NOT NULL,
+		updated_at TIMESTAMPTZ NOT NULL,
+		PRIMARY KEY (user_address, token)
+	)`,
+	`CREATE TABLE IF NOT EXISTS wallet_deposits (
+		deposit_id TEXT PRIMARY KEY,
+		user_address TEXT NOT NULL,
+		token TEXT NOT NULL,
+		amount NUMERIC NOT NULL,
+		source TEXT NOT NULL DEFAULT '',
+		credited_at TIMESTAMPTZ NOT NULL
+	)`,
+}
+
+const upsertBalance = `INSERT INTO wallet_balances (user_address, token, total, updated_at) VALUES ($1, $2, $3, $4)
+	ON CONFLICT (user_address, token) DO UPDATE SET total = EXCLUDED.total, updated_at = EXCLUDED.updated_at`
+
+// PostgresStore 基于 PostgreSQL 的余额持久化
+type PostgresStore struct {
+	db *sql.DB
+}
+
+// NewPostgresStore 创建 PostgreSQL 余额持久化并确保表存在
+func NewPostgresStore(dsn string) (*PostgresStore, error) {
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open balance database: %w", err)
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to connect to balance database: %w", err)
+	}
+
+	for _, stmt := range balanceSchema {
+		if _, err := db.Exec(stmt); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("failed to create balance table: %w", err)
+		}
+	}
+	return &PostgresStore{db: db}, nil
+}
+
+// Close 关闭数据库连接
+func (s *PostgresStore) Close() error {
+	return s.db.Close()
+}
+
+// LoadBalances 加载全部余额
+func (s *PostgresStore) LoadBalances() ([]BalanceUpdate, error) {
+	rows, err := s.db.Query(`SELECT user_address, token, total FROM wallet_balances`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query balances: %w", err)
+	}
+	defer rows.Close()
+
+	var balances []BalanceUpdate
+	for rows.Next() {
+		var balance BalanceUpdate
+		if err := rows.Scan(&balance.UserAddress, &balance.Token, &balance.Total); err != nil {
+			return nil, fmt.Errorf("failed to scan balance: %w", err)
+		}
+		balances = append(balances, balance)
+	}
+	return balances, rows.Err()
+}
+
+// LoadDeposits 加载已入账的充值记录
+func (s *PostgresStore) LoadDeposits() ([]*DepositRecord, error) {
+	rows, err := s.db.Query(`SELECT deposit_id, user_address, token, amount, source, credited_at FROM wallet_deposits`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query deposits: %w", err)
+	}
+	defer rows.Close()
+
+	var deposits []*DepositRecord
+	for rows.Next() {
+		var record DepositRecord
+		if err := rows.Scan(&record.DepositID, &record.UserAddress, &record.Token, &record.Amount,
+			&record.Source, &record.CreditedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan deposit: %w", err)
+		}
+		deposits = append(deposits, &record)
+	}
+	return deposits, rows.Err()
+}
+
+// SaveBalances 在一个事务内写入余额
+func (s *PostgresStore) SaveBalances(updates []BalanceUpdate) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := saveBalancesTx(tx, updates); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// SaveDeposit 在一个事务内写入充值记录和入账后的余额
+func (s *PostgresStore) SaveDeposit(record *DepositRecord, balance BalanceUpdate) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(
+		`INSERT INTO wallet_deposits (deposit_id, user_address, token, amount, source, credited_at) VALUES ($1, $2, $3, $4, $5, $6)`,
+		record.DepositID, record.UserAddress, record.Token, record.Amount, record.Source, record.CreditedAt,
+	); err != nil {
+		return fmt.Errorf("failed to insert deposit: %w", err)
+	}
+	if err := saveBalancesTx(tx, []BalanceUpdate{balance}); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func saveBalancesTx(tx *sql.Tx, updates []BalanceUpdate) error {
+	now := time.Now()
+	for _, update := range updates {
+		if _, err := tx.Exec(upsertBalance, update.UserAddress, update.Token, update.Total, now); err != nil {
+			return fmt.Errorf("failed to save balance: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,309 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+)
+
+// gasPriceTTL gas价格缓存时间，报价接口公开可调用，不能每次请求都访问节点
+const gasPriceTTL = 15 * time.Second
+
+// GasPriceSource gas价格来源（ethclient.Client 已实现）
+type GasPriceSource interface {
+	SuggestGasPrice(ctx context.Context) (*big.Int, error)
+}
+
+// WithdrawalFeeConfig 代币提现手续费配置
+// 手续费 = 固定费用 + 预估gas × 当前gas价格 × 代币/ETH折算比例
+type WithdrawalFeeConfig struct {
+	Token      string          `json:"token"`
+	FlatFee    decimal.Decimal `json:"flat_fee"`     // 固定手续费（代币单位）
+	GasLimit   uint64          `json:"gas_limit"`    // 提现交易预估gas用量
+	GasToToken decimal.Decimal `json:"gas_to_token"` // 1 ETH 折合的代币数量，为0时不收取gas费
+	MinAmount  decimal.Decimal `json:"min_amount"`   // 最小提现数量
+}
+
+// WithdrawalQuote 提现报价（用户签名提现前展示）
+type WithdrawalQuote struct {
+	UserAddress string          `json:"user_address"`
+	Token       string          `json:"token"`
+	Amount      decimal.Decimal `json:"amount"`
+	FlatFee     decimal.Decimal `json:"flat_fee"`
+	GasFee      decimal.Decimal `json:"gas_fee"`
+	TotalFee    decimal.Decimal `json:"total_fee"`
+	NetAmount   decimal.Decimal `json:"net_amount"`
+	MinAmount   decimal.Decimal `json:"min_amount"`
+	Available   decimal.Decimal `json:"available"`
+	QuotedAt    time.Time       `json:"quoted_at"`
+}
+
+// WithdrawalRecord 提现记录
+type WithdrawalRecord struct {
+	ID          uuid.UUID       `json:"id"` // 客户端生成的提现ID，参与用户签名，用于去重
+	UserAddress string          `json:"user_address"`
+	Token       string          `json:"token"`
+	Amount      decimal.Decimal `json:"amount"`
+	Fee         decimal.Decimal `json:"fee"`
+	NetAmount   decimal.Decimal `json:"net_amount"`
+	FeeAccount  string          `json:"fee_account"`
+	CreatedAt   time.Time       `json:"created_at"`
+}
+
+// withdrawalSettings 提现配置
+type withdrawalSettings struct {
+	mu         sync.RWMutex
+	fees       map[string]*WithdrawalFeeConfig // lower(token) -> config
+	feeAccount string
+	gasPrice   GasPriceSource
+	gates      []func(userAddress string) error // 返回错误时拒绝提现（账户冻结、合规筛查）
+
+	gasMu       sync.Mutex // 串行刷新gas价格，缓存过期时只有一个请求访问节点
+	cachedGas   *big.Int
+	gasCachedAt time.Time
+}
+
+func newWithdrawalSettings() *withdrawalSettings {
+	return &withdrawalSettings{
+		fees: make(map[string]*WithdrawalFeeConfig),
+	}
+}
+
+// AddWithdrawalGate 注册提现闸门，任一 gate 返回错误时拒绝该用户的提现
+func (bm *BalanceManager) AddWithdrawalGate(gate func(userAddress string) error) {
+	bm.withdrawal.mu.Lock()
+	defer bm.withdrawal.mu.Unlock()
+	bm.withdrawal.gates = append(bm.withdrawal.gates, gate)
+}
+
+// SetFeeAccount 设置手续费收款账户
+func (bm *BalanceManager) SetFeeAccount(feeAccount string) {
+	bm.withdrawal.mu.Lock()
+	defer bm.withdrawal.mu.Unlock()
+	bm.withdrawal.feeAccount = feeAccount
+}
+
+// SetGasPriceSource 设置gas价格来源（为空时只收取固定手续费）
+func (bm *BalanceManager) SetGasPriceSource(source GasPriceSource) {
+	bm.withdrawal.mu.Lock()
+	defer bm.withdrawal.mu.Unlock()
+	bm.withdrawal.gasPrice = source
+
+	bm.withdrawal.gasMu.Lock()
+	bm.withdrawal.cachedGas = nil
+	bm.withdrawal.gasMu.Unlock()
+}
+
+// currentGasPrice 获取gas价格，缓存 gasPriceTTL 内直接返回；刷新失败时沿用上次的价格
+func (s *withdrawalSettings) currentGasPrice(source GasPriceSource) (*big.Int, error) {
+	s.gasMu.Lock()
+	defer s.gasMu.Unlock()
+
+	if s.cachedGas != nil && time.Since(s.gasCachedAt) < gasPriceTTL {
+		return s.cachedGas, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	price, err := source.SuggestGasPrice(ctx)
+	if err != nil {
+		if s.cachedGas != nil {
+			return s.cachedGas, nil
+		}
+		return nil, err
+	}
+	s.cachedGas = price
+	s.gasCachedAt = time.Now()
+	return price, nil
+}
+
+// SetWithdrawalFeeConfig 设置代币提现手续费配置
+func (bm *BalanceManager) SetWithdrawalFeeConfig(config *WithdrawalFeeConfig) error {
+	if config.Token == "" {
+		return fmt.Errorf("token is required")
+	}
+	if config.FlatFee.IsNegative() || config.MinAmount.IsNegative() || config.GasToToken.IsNegative() {
+		return fmt.Errorf("withdrawal fee config values must not be negative")
+	}
+
+	bm.withdrawal.mu.Lock()
+	bm.withdrawal.fees[strings.ToLower(config.Token)] = config
+	bm.withdrawal.mu.Unlock()
+
+	bm.logger.WithFields(logrus.Fields{
+		"token":      config.Token,
+		"flat_fee":   config.FlatFee.String(),
+		"gas_limit":  config.GasLimit,
+		"min_amount": config.MinAmount.String(),
+	}).Info("Withdrawal fee config updated")
+	return nil
+}
+
+// GetWithdrawalFeeConfigs 获取所有代币提现手续费配置
+func (bm *BalanceManager) GetWithdrawalFeeConfigs() []*WithdrawalFeeConfig {
+	bm.withdrawal.mu.RLock()
+	defer bm.withdrawal.mu.RUnlock()
+
+	result := make([]*WithdrawalFeeConfig, 0, len(bm.withdrawal.fees))
+	for _, config := range bm.withdrawal.fees {
+		result = append(result, config)
+	}
+	return result
+}
+
+// QuoteWithdrawal 计算提现手续费报价
+func (bm *BalanceManager) QuoteWithdrawal(userAddress, token string, amount decimal.Decimal) (*WithdrawalQuote, error) {
+	if !amount.IsPositive() {
+		return nil, fmt.Errorf("withdrawal amount must be positive")
+	}
+
+	bm.withdrawal.mu.RLock()
+	config := bm.withdrawal.fees[strings.ToLower(token)]
+	gasPrice := bm.withdrawal.gasPrice
+	gates := bm.withdrawal.gates
+	bm.withdrawal.mu.RUnlock()
+
+	quote := &WithdrawalQuote{
+		UserAddress: userAddress,
+		Token:       token,
+		Amount:      amount,
+		FlatFee:     decimal.Zero,
+		GasFee:      decimal.Zero,
+		MinAmount:   decimal.Zero,
+		Available:   bm.GetAvailableBalance(userAddress, token),
+		QuotedAt:    time.Now(),
+	}
+
+	if config != nil {
+		quote.FlatFee = config.FlatFee
+		quote.MinAmount = config.MinAmount
+
+		if gasPrice != nil && config.GasLimit > 0 && config.GasToToken.IsPositive() {
+			price, err := bm.withdrawal.currentGasPrice(gasPrice)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get gas price: %w", err)
+			}
+			// gas费(ETH) = gasLimit × gasPrice(wei) / 1e18
+			gasCostEth := decimal.NewFromBigInt(price, -18).Mul(decimal.NewFromInt(int64(config.GasLimit)))
+			quote.GasFee = gasCostEth.Mul(config.GasToToken)
+		}
+	}
+
+	quote.TotalFee = quote.FlatFee.Add(quote.GasFee)
+	quote.NetAmount = amount.Sub(quote.TotalFee)
+
+	for _, gate := range gates {
+		if err := gate(userAddress); err != nil {
+			return quote, err
+		}
+	}
+	if amount.LessThan(quote.MinAmount) {
+		return quote, fmt.Errorf("amount below minimum withdrawal %s", quote.MinAmount.String())
+	}
+	if !quote.NetAmount.IsPositive() {
+		return quote, fmt.Errorf("amount does not cover withdrawal fee %s", quote.TotalFee.String())
+	}
+	return quote, nil
+}
+
+// WithdrawalMessage 提现请求由用户钱包签名（EIP-191 personal_sign）的消息
+// 签名覆盖提现ID、数量与用户接受的最高手续费；timestamp 为秒级Unix时间戳，限定签名的有效时间
+func WithdrawalMessage(withdrawalID uuid.UUID, userAddress, token string, amount, maxFee decimal.Decimal, timestamp int64) string {
+	return fmt.Sprintf("OrderBookEVM withdrawal request\nID: %s\nAddress: %s\nToken: %s\nAmount: %s\nMax fee: %s\nTimestamp: %d",
+		withdrawalID, strings.ToLower(userAddress), strings.ToLower(token), amount.String(), maxFee.String(), timestamp)
+}
+
+// Withdraw 扣减提现资金并将手续费记入手续费账户，同一提现ID只扣款一次
+// maxFee 为用户签名时确认的最高手续费，实时手续费超出时拒绝；重复提交时返回首次的记录且 created 为 false
+func (bm *BalanceManager) Withdraw(withdrawalID uuid.UUID, userAddress, token string, amount, maxFee decimal.Decimal) (record *WithdrawalRecord, created bool, err error) {
+	bm.mu.RLock()
+	existing, exists := bm.withdrawals[withdrawalID]
+	bm.mu.RUnlock()
+	if exists {
+		copied := *existing
+		return &copied, false, nil
+	}
+
+	quote, err := bm.QuoteWithdrawal(userAddress, token, amount)
+	if err != nil {
+		return nil, false, err
+	}
+	if quote.TotalFee.GreaterThan(maxFee) {
+		return nil, false, fmt.Errorf("withdrawal fee %s exceeds accepted max fee %s", quote.TotalFee.String(), maxFee.String())
+	}
+
+	bm.withdrawal.mu.RLock()
+	feeAccount := bm.withdrawal.feeAccount
+	bm.withdrawal.mu.RUnlock()
+	if feeAccount == "" && quote.TotalFee.IsPositive() {
+		return nil, false, fmt.Errorf("fee account not configured")
+	}
+
+	bm.mu.Lock()
+	defer bm.mu.Unlock()
+
+	if existing, exists := bm.withdrawals[withdrawalID]; exists {
+		copied := *existing
+		return &copied, false, nil
+	}
+	if available := bm.getAvailableBalanceUnsafe(userAddress, token); available.LessThan(amount) {
+		return nil, false, fmt.Errorf("insufficient balance: need %s, available %s", amount.String(), available.String())
+	}
+
+	if quote.TotalFee.IsPositive() {
+		if err := bm.transferUnsafe(userAddress, feeAccount, token, quote.TotalFee); err != nil {
+			return nil, false, fmt.Errorf("failed to collect withdrawal fee: %w", err)
+		}
+	}
+
+	// 扣减实际转出的净额
+	balance := *bm.balances[userAddress][token]
+	remaining := balance.Sub(quote.NetAmount)
+	bm.balances[userAddress][token] = &remaining
+
+	keys := []balanceKey{{userAddress, token}}
+	if quote.TotalFee.IsPositive() {
+		keys = append(keys, balanceKey{feeAccount, token})
+	}
+	if err := bm.persistUnsafe(keys...); err != nil {
+		// 持久化失败时回滚扣款和手续费
+		bm.balances[userAddress][token] = &balance
+		if quote.TotalFee.IsPositive() {
+			bm.transferUnsafe(feeAccount, userAddress, token, quote.TotalFee)
+		}
+		return nil, false, fmt.Errorf("failed to persist withdrawal: %w", err)
+	}
+
+	bm.markChangedUnsafe(keys...)
+
+	record = &WithdrawalRecord{
+		ID:          withdrawalID,
+		UserAddress: userAddress,
+		Token:       token,
+		Amount:      amount,
+		Fee:         quote.TotalFee,
+		NetAmount:   quote.NetAmount,
+		FeeAccount:  feeAccount,
+		CreatedAt:   time.Now(),
+	}
+
+	bm.logger.WithFields(logrus.Fields{
+		"withdrawal_id": record.ID.String(),
+		"user":          userAddress,
+		"token":         token,
+		"amount":        amount.String(),
+		"fee":           quote.TotalFee.String(),
+		"net_amount":    quote.NetAmount.String(),
+	}).Info("💸 Withdrawal debited")
+
+	bm.withdrawals[withdrawalID] = record
+	copied := *record
+	return &copied, true, nil
+}
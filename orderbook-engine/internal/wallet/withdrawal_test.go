@@ -0,0 +1,94 @@
+package wallet
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"orderbook-engine/internal/types"
+)
+
+// countingGasPrice 记录调用次数的gas价格来源，err 不为空时返回错误
+type countingGasPrice struct {
+	calls int
+	price *big.Int
+	err   error
+}
+
+func (g *countingGasPrice) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	g.calls++
+	if g.err != nil {
+		return nil, g.err
+	}
+	return g.price, nil
+}
+
+func TestWithdrawCollectsFee(t *testing.T) {
+	bm := NewBalanceManager(logrus.New())
+	bm.SetFeeAccount("fees")
+	require.NoError(t, bm.SetWithdrawalFeeConfig(&WithdrawalFeeConfig{Token: "USDC", FlatFee: decimal.NewFromInt(2), MinAmount: decimal.NewFromInt(10)}))
+	bm.SetBalance("alice", "USDC", decimal.NewFromInt(100))
+
+	// 实时手续费超出用户签名的上限时拒绝
+	_, _, err := bm.Withdraw(uuid.New(), "alice", "USDC", decimal.NewFromInt(50), decimal.NewFromInt(1))
+	assert.Error(t, err)
+	assert.True(t, bm.GetBalance("alice", "USDC").Equal(decimal.NewFromInt(100)))
+
+	id := uuid.New()
+	record, created, err := bm.Withdraw(id, "alice", "USDC", decimal.NewFromInt(50), decimal.NewFromInt(2))
+	require.NoError(t, err)
+	assert.True(t, created)
+	assert.True(t, record.Fee.Equal(decimal.NewFromInt(2)))
+	assert.True(t, record.NetAmount.Equal(decimal.NewFromInt(48)))
+
+	// 用户扣减全部提现数量，手续费记入手续费账户
+	assert.True(t, bm.GetBalance("alice", "USDC").Equal(decimal.NewFromInt(50)))
+	assert.True(t, bm.GetBalance("fees", "USDC").Equal(decimal.NewFromInt(2)))
+
+	// 重复提交同一提现ID不再扣款
+	again, created, err := bm.Withdraw(id, "alice", "USDC", decimal.NewFromInt(50), decimal.NewFromInt(2))
+	require.NoError(t, err)
+	assert.False(t, created)
+	assert.Equal(t, record.ID, again.ID)
+	assert.True(t, bm.GetBalance("alice", "USDC").Equal(decimal.NewFromInt(50)))
+
+	// 已锁定的资金不能提现
+	bm.SetBalance("bob", "USDC", decimal.NewFromInt(100))
+	bid := &types.Order{ID: uuid.New(), UserAddress: "bob", Side: types.OrderSideBuy, BaseToken: "WETH", QuoteToken: "USDC",
+		Price: decimal.NewFromInt(80), Amount: decimal.NewFromInt(1)}
+	require.NoError(t, bm.LockOrder(bid, bid.Price))
+	_, _, err = bm.Withdraw(uuid.New(), "bob", "USDC", decimal.NewFromInt(50), decimal.NewFromInt(2))
+	assert.Error(t, err)
+	assert.True(t, bm.GetBalance("fees", "USDC").Equal(decimal.NewFromInt(2)))
+}
+
+func TestQuoteCachesGasPrice(t *testing.T) {
+	bm := NewBalanceManager(logrus.New())
+	source := &countingGasPrice{price: big.NewInt(10_000_000_000)} // 10 gwei
+	bm.SetGasPriceSource(source)
+	require.NoError(t, bm.SetWithdrawalFeeConfig(&WithdrawalFeeConfig{Token: "USDC", GasLimit: 100_000, GasToToken: decimal.NewFromInt(2000)}))
+	bm.SetBalance("alice", "USDC", decimal.NewFromInt(100))
+
+	// gas费 = 100000 × 10 gwei = 0.001 ETH = 2 USDC
+	for i := 0; i < 3; i++ {
+		quote, err := bm.QuoteWithdrawal("alice", "USDC", decimal.NewFromInt(50))
+		require.NoError(t, err)
+		assert.True(t, quote.GasFee.Equal(decimal.NewFromInt(2)), quote.GasFee.String())
+	}
+	assert.Equal(t, 1, source.calls)
+
+	// 缓存过期后刷新失败时沿用上次的价格
+	bm.withdrawal.gasCachedAt = bm.withdrawal.gasCachedAt.Add(-2 * gasPriceTTL)
+	source.err = errors.New("rpc unavailable")
+	quote, err := bm.QuoteWithdrawal("alice", "USDC", decimal.NewFromInt(50))
+	require.NoError(t, err)
+	assert.True(t, quote.GasFee.Equal(decimal.NewFromInt(2)))
+	assert.Equal(t, 2, source.calls)
+}
@@ -0,0 +1,186 @@
+package websocket
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ErrTopicForbidden 无权订阅该主题
+var ErrTopicForbidden = errors.New("not authorized to subscribe to topic")
+
+// publicTopicPrefixes 公开主题，任何连接均可订阅
+var publicTopicPrefixes = []string{"orderbook.", "trades.", "status.", "system."}
+
+// ownerTopicPrefixes 私有主题，地址部分与连接身份一致时可订阅
+var ownerTopicPrefixes = []string{"orders.", "fills.", "executions.", "balances.", "risk."}
+
+// SystemAdminTopic 运维事件主题，虽以 system. 开头但不公开
+const SystemAdminTopic = "system.admin"
+
+// 其余主题（如运维告警 admin.alerts、运维事件 system.admin）仅管理员或经额外授权的身份可订阅
+
+// Identity 连接身份
+type Identity struct {
+	Address string `json:"address,omitempty"` // 已认证的用户地址（小写）
+	Admin   bool   `json:"admin"`
+}
+
+// TopicACL 主题访问控制
+// 额外授权（审计、客服账号的只读访问）持久化到JSON文件
+type TopicACL struct {
+	mu     sync.RWMutex
+	path   string
+	grants map[string][]string // 身份地址 -> 主题模式（支持 "orders.*" 前缀通配）
+}
+
+// NewTopicACL 创建主题访问控制，path为空时仅保存在内存
+func NewTopicACL(path string) (*TopicACL, error) {
+	acl := &TopicACL{
+		path:   path,
+		grants: make(map[string][]string),
+	}
+
+	if path == "" {
+		return acl, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return acl, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ACL file: %w", err)
+	}
+	if err := json.Unmarshal(data, &acl.grants); err != nil {
+		return nil, fmt.Errorf("failed to parse ACL file: %w", err)
+	}
+	return acl, nil
+}
+
+// Allowed 检查身份是否可订阅主题
+func (a *TopicACL) Allowed(identity Identity, topic string) bool {
+	for _, prefix := range publicTopicPrefixes {
+		if strings.HasPrefix(topic, prefix) && topic != SystemAdminTopic {
+			return true
+		}
+	}
+
+	if identity.Admin {
+		return true
+	}
+
+	if identity.Address == "" {
+		return false
+	}
+
+	for _, prefix := range ownerTopicPrefixes {
+		if topic == prefix+identity.Address {
+			return true
+		}
+	}
+
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	for _, pattern := range a.grants[identity.Address] {
+		if matchTopic(pattern, topic) {
+			return true
+		}
+	}
+	return false
+}
+
+// Grant 授予身份订阅主题模式的权限
+func (a *TopicACL) Grant(address, pattern string) error {
+	address = strings.ToLower(address)
+	pattern = strings.ToLower(pattern)
+	if address == "" || pattern == "" {
+		return errors.New("address and topic are required")
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for _, existing := range a.grants[address] {
+		if existing == pattern {
+			return nil
+		}
+	}
+	a.grants[address] = append(a.grants[address], pattern)
+	return a.saveLocked()
+}
+
+// Revoke 撤销身份的主题模式授权，返回是否存在该授权
+func (a *TopicACL) Revoke(address, pattern string) (bool, error) {
+	address = strings.ToLower(address)
+	pattern = strings.ToLower(pattern)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	patterns := a.grants[address]
+	for i, existing := range patterns {
+		if existing == pattern {
+			a.grants[address] = append(patterns[:i], patterns[i+1:]...)
+			if len(a.grants[address]) == 0 {
+				delete(a.grants, address)
+			}
+			return true, a.saveLocked()
+		}
+	}
+	return false, nil
+}
+
+// GetGrants 获取全部额外授权
+func (a *TopicACL) GetGrants() map[string][]string {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	result := make(map[string][]string, len(a.grants))
+	for address, patterns := range a.grants {
+		copied := append([]string(nil), patterns...)
+		sort.Strings(copied)
+		result[address] = copied
+	}
+	return result
+}
+
+// saveLocked 写入ACL文件（先写临时文件再重命名）
+func (a *TopicACL) saveLocked() error {
+	if a.path == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(a.grants, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(a.path), ".acl-*")
+	if err != nil {
+		return fmt.Errorf("failed to write ACL file: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write ACL file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write ACL file: %w", err)
+	}
+	return os.Rename(tmp.Name(), a.path)
+}
+
+// matchTopic 匹配主题模式，"*" 结尾表示前缀匹配
+func matchTopic(pattern, topic string) bool {
+	if strings.HasSuffix(pattern, "*") {
+		return strings.HasPrefix(topic, strings.TrimSuffix(pattern, "*"))
+	}
+	return pattern == topic
+}
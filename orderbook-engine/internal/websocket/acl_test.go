@@ -0,0 +1,102 @@
+package websocket
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	owner   = "0xaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
+	auditor = "0xbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb"
+)
+
+func TestTopicACLAllowed(t *testing.T) {
+	acl, err := NewTopicACL("")
+	require.NoError(t, err)
+
+	anonymous := Identity{}
+	user := Identity{Address: owner}
+	admin := Identity{Admin: true}
+
+	// 公开主题任何连接均可订阅，运维事件主题除外
+	assert.True(t, acl.Allowed(anonymous, "orderbook.WETH-USDC"))
+	assert.True(t, acl.Allowed(anonymous, "system.status"))
+	assert.False(t, acl.Allowed(anonymous, SystemAdminTopic))
+	assert.False(t, acl.Allowed(user, SystemAdminTopic))
+	assert.True(t, acl.Allowed(admin, SystemAdminTopic))
+
+	// 私有主题只对地址一致的身份开放
+	assert.False(t, acl.Allowed(anonymous, "orders."+owner))
+	assert.True(t, acl.Allowed(user, "orders."+owner))
+	assert.False(t, acl.Allowed(user, "orders."+auditor))
+	assert.False(t, acl.Allowed(user, "admin.alerts"))
+	assert.True(t, acl.Allowed(admin, "balances."+owner))
+
+	// 额外授权不区分大小写，支持前缀通配
+	assert.False(t, acl.Allowed(Identity{Address: auditor}, "orders."+owner))
+	require.NoError(t, acl.Grant(strings.ToUpper(auditor), "ORDERS.*"))
+	assert.True(t, acl.Allowed(Identity{Address: auditor}, "orders."+owner))
+	assert.False(t, acl.Allowed(Identity{Address: auditor}, "fills."+owner))
+}
+
+func TestTopicACLPersistsGrants(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "acl.json")
+	acl, err := NewTopicACL(path)
+	require.NoError(t, err)
+	require.NoError(t, acl.Grant(auditor, "fills.*"))
+
+	reloaded, err := NewTopicACL(path)
+	require.NoError(t, err)
+	assert.True(t, reloaded.Allowed(Identity{Address: auditor}, "fills."+owner))
+
+	removed, err := reloaded.Revoke(auditor, "fills.*")
+	require.NoError(t, err)
+	assert.True(t, removed)
+	removed, err = reloaded.Revoke(auditor, "fills.*")
+	require.NoError(t, err)
+	assert.False(t, removed)
+
+	reloaded, err = NewTopicACL(path)
+	require.NoError(t, err)
+	assert.False(t, reloaded.Allowed(Identity{Address: auditor}, "fills."+owner))
+	assert.Empty(t, reloaded.GetGrants())
+}
+
+func TestHandleWebSocketUsesResolvedIdentity(t *testing.T) {
+	hub := NewHub(logrus.New())
+	go hub.Run()
+
+	// 身份与客户端IP由 HTTP 层传入，不再读取 RemoteAddr
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hub.HandleWebSocket(w, r, Identity{Address: strings.ToUpper(owner)}, "203.0.113.7")
+	}))
+	defer server.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	require.Eventually(t, func() bool { return len(hub.GetConnections()) == 1 }, time.Second, 10*time.Millisecond)
+	info := hub.GetConnections()[0]
+	assert.Equal(t, "203.0.113.7", info.RemoteAddr)
+	assert.Equal(t, owner, info.Identity.Address)
+
+	// 订阅按传入的身份校验
+	hub.mu.RLock()
+	var client *Client
+	for c := range hub.clients {
+		client = c
+	}
+	hub.mu.RUnlock()
+	assert.ErrorIs(t, hub.Subscribe(client, "orders."+auditor), ErrTopicForbidden)
+	assert.NoError(t, hub.Subscribe(client, "trades.WETH-USDC"))
+}
@@ -0,0 +1,53 @@
+package websocket
+
+// Bridge 多实例消息桥
+// 多个 API 实例各自持有 WebSocket 连接，本实例发布的主题消息经消息桥转发给其他实例；
+// 每个实例只订阅本地有订阅者的主题，消息只送达关心该主题的实例，避免全量广播
+type Bridge interface {
+	// Publish 转发本实例发布的已编码消息
+	Publish(topic string, data []byte) error
+	// Subscribe 接收其他实例发布到该主题的消息，本地出现第一个订阅者时调用
+	Subscribe(topic string, deliver func(data []byte)) error
+	// Unsubscribe 停止接收该主题，本地最后一个订阅者离开时调用
+	Unsubscribe(topic string) error
+	Close() error
+}
+
+// SetBridge 设置多实例消息桥，需在接受连接前调用
+func (h *Hub) SetBridge(bridge Bridge) {
+	h.bridge = bridge
+}
+
+// bridgeSubscribe 订阅其他实例的主题消息（调用方持有 h.mu）
+func (h *Hub) bridgeSubscribe(topic string) {
+	if h.bridge == nil {
+		return
+	}
+	err := h.bridge.Subscribe(topic, func(data []byte) {
+		// 其他实例的消息只投递本地连接，不再转发
+		h.deliver(topic, data)
+	})
+	if err != nil {
+		h.logger.WithError(err).WithField("topic", topic).Warn("Failed to subscribe WebSocket bridge topic")
+	}
+}
+
+// bridgeUnsubscribe 退订其他实例的主题消息（调用方持有 h.mu）
+func (h *Hub) bridgeUnsubscribe(topic string) {
+	if h.bridge == nil {
+		return
+	}
+	if err := h.bridge.Unsubscribe(topic); err != nil {
+		h.logger.WithError(err).WithField("topic", topic).Warn("Failed to unsubscribe WebSocket bridge topic")
+	}
+}
+
+// bridgePublish 将本实例发布的消息转发给其他实例
+func (h *Hub) bridgePublish(topic string, data []byte) {
+	if h.bridge == nil {
+		return
+	}
+	if err := h.bridge.Publish(topic, data); err != nil {
+		h.logger.WithError(err).WithField("topic", topic).Warn("Failed to publish to WebSocket bridge")
+	}
+}
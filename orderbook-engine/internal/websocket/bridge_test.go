@@ -0,0 +1,131 @@
+package websocket
+
+import (
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"orderbook-engine/internal/types"
+)
+
+// memoryBroker 进程内消息代理，按主题只投递给订阅了该主题的其他实例
+type memoryBroker struct {
+	mu   sync.Mutex
+	subs map[string]map[*memoryBridge]func([]byte)
+}
+
+type memoryBridge struct {
+	broker *memoryBroker
+}
+
+func (b *memoryBroker) bridge() *memoryBridge {
+	return &memoryBridge{broker: b}
+}
+
+func (b *memoryBroker) subscribers(topic string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.subs[topic])
+}
+
+func (m *memoryBridge) Publish(topic string, data []byte) error {
+	m.broker.mu.Lock()
+	var targets []func([]byte)
+	for bridge, deliver := range m.broker.subs[topic] {
+		if bridge != m {
+			targets = append(targets, deliver)
+		}
+	}
+	m.broker.mu.Unlock()
+	for _, deliver := range targets {
+		deliver(data)
+	}
+	return nil
+}
+
+func (m *memoryBridge) Subscribe(topic string, deliver func([]byte)) error {
+	m.broker.mu.Lock()
+	defer m.broker.mu.Unlock()
+	if m.broker.subs[topic] == nil {
+		m.broker.subs[topic] = make(map[*memoryBridge]func([]byte))
+	}
+	m.broker.subs[topic][m] = deliver
+	return nil
+}
+
+func (m *memoryBridge) Unsubscribe(topic string) error {
+	m.broker.mu.Lock()
+	defer m.broker.mu.Unlock()
+	delete(m.broker.subs[topic], m)
+	if len(m.broker.subs[topic]) == 0 {
+		delete(m.broker.subs, topic)
+	}
+	return nil
+}
+
+func (m *memoryBridge) Close() error { return nil }
+
+func newBridgedClient(broker *memoryBroker) *Client {
+	hub := NewHub(logrus.New())
+	hub.SetBridge(broker.bridge())
+	return &Client{
+		hub:           hub,
+		send:          make(chan []byte, 16),
+		subscriptions: make(map[string]bool),
+		wake:          make(chan struct{}, 1),
+	}
+}
+
+func TestBridgeDeliversAcrossInstances(t *testing.T) {
+	broker := &memoryBroker{subs: make(map[string]map[*memoryBridge]func([]byte))}
+	a, b := newBridgedClient(broker), newBridgedClient(broker)
+
+	const topic = "trades.WETH-USDC"
+	require.NoError(t, b.hub.Subscribe(b, topic))
+	require.NoError(t, a.hub.Subscribe(a, topic))
+	// 只有有本地订阅者的实例订阅代理主题
+	assert.Equal(t, 2, broker.subscribers(topic))
+	assert.Zero(t, broker.subscribers("trades.WBTC-USDC"))
+
+	a.hub.PublishTradeUpdate(&types.TradeUpdate{Trade: &types.Trade{
+		TradingPair: "WETH-USDC",
+		Price:       decimal.NewFromInt(2000),
+		Amount:      decimal.NewFromInt(1),
+	}})
+
+	// 两个实例的订阅者各收到一份，且内容一致（远端消息不重复编码、不回传）
+	require.Len(t, a.send, 1)
+	require.Len(t, b.send, 1)
+	local, remote := <-a.send, <-b.send
+	assert.Equal(t, local, remote)
+	var message Message
+	require.NoError(t, json.Unmarshal(remote, &message))
+	assert.Equal(t, "trade_update", message.Type)
+
+	// 最后一个本地订阅者离开后退订代理主题，其他实例的消息不再送达
+	b.hub.Unsubscribe(b, topic)
+	assert.Equal(t, 1, broker.subscribers(topic))
+	a.hub.PublishTradeUpdate(&types.TradeUpdate{Trade: &types.Trade{TradingPair: "WETH-USDC"}})
+	assert.Len(t, a.send, 1)
+	assert.Empty(t, b.send)
+}
+
+func TestNATSBridgeSubject(t *testing.T) {
+	bridge := &NATSBridge{prefix: "orderbook.ws"}
+
+	subject, err := bridge.subject("orders.0xabc")
+	require.NoError(t, err)
+	assert.Equal(t, "orderbook.ws.orders.0xabc", subject)
+
+	// 通配符主题会匹配其他用户的私有主题
+	for _, topic := range []string{"orders.>", "orders.*", "orders..x", "", "trades.WETH USDC"} {
+		_, err := bridge.subject(topic)
+		assert.True(t, errors.Is(err, ErrInvalidBridgeTopic), topic)
+	}
+}
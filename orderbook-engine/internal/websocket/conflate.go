@@ -0,0 +1,61 @@
+package websocket
+
+import (
+	"time"
+
+	"orderbook-engine/internal/types"
+)
+
+// ConflationStats 订单簿更新合并统计
+type ConflationStats struct {
+	Interval  string `json:"interval"`
+	Received  uint64 `json:"received"`  // 撮合侧发布的订单簿更新数
+	Published uint64 `json:"published"` // 实际推送给订阅者的更新数
+}
+
+// conflateOrderBookUpdate 记录交易对最新的订单簿状态，等待下一个合并周期推送
+func (h *Hub) conflateOrderBookUpdate(update *types.OrderBookUpdate) {
+	h.bookMu.Lock()
+	h.bookLatest[update.TradingPair] = update
+	h.bookMu.Unlock()
+}
+
+// runBookConflation 按合并周期推送各交易对区间内的最新订单簿
+// 周期内没有变化的交易对不推送，突发行情下每个交易对每周期最多一条消息
+func (h *Hub) runBookConflation(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		h.bookMu.Lock()
+		if len(h.bookLatest) == 0 {
+			h.bookMu.Unlock()
+			continue
+		}
+		latest := h.bookLatest
+		h.bookLatest = make(map[string]*types.OrderBookUpdate, len(latest))
+		h.bookMu.Unlock()
+
+		for _, update := range latest {
+			h.publishOrderBook(update)
+		}
+	}
+}
+
+// publishOrderBook 推送订单簿更新
+func (h *Hub) publishOrderBook(update *types.OrderBookUpdate) {
+	h.bookPublished.Add(1)
+	h.publishToTopic("orderbook."+update.TradingPair, Message{
+		Type: "orderbook_update",
+		Data: update,
+	})
+}
+
+// GetConflationStats 获取订单簿更新合并统计
+func (h *Hub) GetConflationStats() ConflationStats {
+	return ConflationStats{
+		Interval:  h.config.BookConflation.String(),
+		Received:  h.bookReceived.Load(),
+		Published: h.bookPublished.Load(),
+	}
+}
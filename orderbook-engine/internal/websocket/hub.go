@@ -4,12 +4,18 @@ import (
 	"encoding/json"
 	"log"
 	"net/http"
+	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
 	"github.com/sirupsen/logrus"
 
+	"orderbook-engine/internal/session"
+	"orderbook-engine/internal/clock"
 	"orderbook-engine/internal/types"
 )
 
@@ -21,7 +27,19 @@ type Hub struct {
 	unregister    chan *Client
 	subscriptions map[string]map[*Client]bool // topic -> clients
 	mu            sync.RWMutex
+	sessions      *session.Registry // 可选，登记带用户地址的连接
+	acl           *TopicACL
+	bridge        Bridge // 可选，多实例间转发主题消息
+	config        Config
 	logger        *logrus.Logger
+
+	slowDisconnects atomic.Uint64
+	running         atomic.Bool // Run 已启动
+
+	bookMu        sync.Mutex
+	bookLatest    map[string]*types.OrderBookUpdate // 交易对 -> 合并周期内最新的订单簿
+	bookReceived  atomic.Uint64
+	bookPublished atomic.Uint64
 }
 
 // Client WebSocket客户端
@@ -31,12 +49,32 @@ type Client struct {
 	send         chan []byte
 	subscriptions map[string]bool
 	mu           sync.RWMutex
+	sessionID    uuid.UUID
+	identity     Identity
+	id           uuid.UUID
+	remoteAddr   string
+	connectedAt  time.Time
+
+	sendMu   sync.Mutex // 保护 send 的关闭与 pending
+	closed   bool
+	pending  map[string][]byte // conflate 策略下待发送的订单簿主题最新消息
+	wake     chan struct{}     // 通知写协程发送 pending
+	evicting atomic.Bool
+	stats    clientStats
 }
 
 // Message WebSocket消息
+// EngineTimestamp 为发布时的引擎时间戳（单调递增 Unix 纳秒，见 clock 包），同一连接内可用于排序和计算推送延迟
 type Message struct {
-	Type string      `json:"type"`
-	Data interface{} `json:"data"`
+	Type            string      `json:"type"`
+	Data            interface{} `json:"data"`
+	EngineTimestamp int64       `json:"engine_timestamp"`
+}
+
+// encode 打上引擎时间戳并序列化
+func (m Message) encode() ([]byte, error) {
+	m.EngineTimestamp = clock.Now()
+	return json.Marshal(m)
 }
 
 // SubscribeMessage 订阅消息
@@ -62,12 +100,75 @@ func NewHub(logger *logrus.Logger) *Hub {
 		register:      make(chan *Client),
 		unregister:    make(chan *Client),
 		subscriptions: make(map[string]map[*Client]bool),
+		acl:           &TopicACL{grants: make(map[string][]string)},
+		config:        DefaultConfig(),
+		bookLatest:    make(map[string]*types.OrderBookUpdate),
 		logger:        logger,
 	}
 }
 
+// SetConfig 设置连接配置（缓冲大小、心跳与慢消费者策略），需在接受连接前调用
+func (h *Hub) SetConfig(config Config) {
+	defaults := DefaultConfig()
+	if config.SendBuffer <= 0 {
+		config.SendBuffer = defaults.SendBuffer
+	}
+	if config.PingInterval <= 0 {
+		config.PingInterval = defaults.PingInterval
+	}
+	if config.PongTimeout <= config.PingInterval {
+		config.PongTimeout = config.PingInterval + config.PingInterval/9
+	}
+	if config.WriteTimeout <= 0 {
+		config.WriteTimeout = defaults.WriteTimeout
+	}
+	if config.Policy == "" {
+		config.Policy = defaults.Policy
+	}
+	h.config = config
+}
+
+// SetTopicACL 设置主题访问控制
+func (h *Hub) SetTopicACL(acl *TopicACL) {
+	h.acl = acl
+}
+
+// SetSessionRegistry 设置会话注册表，会话被吊销时断开对应连接
+func (h *Hub) SetSessionRegistry(registry *session.Registry) {
+	h.sessions = registry
+	registry.OnRevoke(func(s *session.Session, cancelOrders bool) {
+		if s.Kind == session.KindWebSocket {
+			h.CloseSession(s.ID)
+		}
+	})
+}
+
+// CloseSession 断开指定会话的连接
+func (h *Hub) CloseSession(sessionID uuid.UUID) {
+	h.mu.RLock()
+	var target *Client
+	for client := range h.clients {
+		if client.sessionID == sessionID {
+			target = client
+			break
+		}
+	}
+	h.mu.RUnlock()
+
+	if target != nil {
+		h.unregister <- target
+	}
+}
+
 // Run 启动Hub
 func (h *Hub) Run() {
+	h.running.Store(true)
+	defer h.running.Store(false)
+
+	if h.config.BookConflation > 0 {
+		go h.runBookConflation(h.config.BookConflation)
+	}
+
 	for {
 		select {
 		case client := <-h.register:
@@ -84,12 +185,9 @@ func (h *Hub) Run() {
 					"message":   "Connected to OrderBook WebSocket",
 				},
 			}
-			if data, err := json.Marshal(welcome); err == nil {
-				select {
-				case client.send <- data:
-				default:
-					close(client.send)
-					delete(h.clients, client)
+			if data, err := welcome.encode(); err == nil {
+				if !client.enqueue("", data) {
+					h.disconnectSlowConsumer(client, "")
 				}
 			}
 
@@ -97,13 +195,17 @@ func (h *Hub) Run() {
 			h.mu.Lock()
 			if _, ok := h.clients[client]; ok {
 				delete(h.clients, client)
-				close(client.send)
+				client.closeSend()
+				if h.sessions != nil && client.sessionID != uuid.Nil {
+					h.sessions.Remove(client.sessionID)
+				}
 				
 				// 从所有订阅中移除客户端
 				for topic, clients := range h.subscriptions {
 					delete(clients, client)
 					if len(clients) == 0 {
 						delete(h.subscriptions, topic)
+						h.bridgeUnsubscribe(topic)
 					}
 				}
 			}
@@ -113,11 +215,8 @@ func (h *Hub) Run() {
 		case message := <-h.broadcast:
 			h.mu.RLock()
 			for client := range h.clients {
-				select {
-				case client.send <- message:
-				default:
-					close(client.send)
-					delete(h.clients, client)
+				if !client.enqueue("", message) {
+					h.disconnectSlowConsumer(client, "")
 				}
 			}
 			h.mu.RUnlock()
@@ -126,7 +225,10 @@ func (h *Hub) Run() {
 }
 
 // HandleWebSocket 处理WebSocket连接
-func (h *Hub) HandleWebSocket(w http.ResponseWriter, r *http.Request) {
+// identity 与 clientIP 由 HTTP 层在握手前解析（与 REST 接口相同的鉴权与客户端IP），用于私有主题授权和会话登记
+func (h *Hub) HandleWebSocket(w http.ResponseWriter, r *http.Request, identity Identity, clientIP string) {
+	identity.Address = strings.ToLower(identity.Address)
+
 	conn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
 		h.logger.WithError(err).Error("WebSocket upgrade failed")
@@ -136,8 +238,22 @@ func (h *Hub) HandleWebSocket(w http.ResponseWriter, r *http.Request) {
 	client := &Client{
 		hub:           h,
 		conn:          conn,
-		send:          make(chan []byte, 256),
+		send:          make(chan []byte, h.config.SendBuffer),
 		subscriptions: make(map[string]bool),
+		identity:      identity,
+		id:            uuid.New(),
+		remoteAddr:    clientIP,
+		connectedAt:   time.Now(),
+		wake:          make(chan struct{}, 1),
+	}
+
+	// 提供用户地址的连接登记为会话，可被用户查看和吊销
+	userAddress := identity.Address
+	if userAddress == "" {
+		userAddress = r.URL.Query().Get("user_address")
+	}
+	if userAddress != "" && h.sessions != nil {
+		client.sessionID = h.sessions.RegisterWebSocket(userAddress, clientIP).ID
 	}
 
 	client.hub.register <- client
@@ -147,13 +263,22 @@ func (h *Hub) HandleWebSocket(w http.ResponseWriter, r *http.Request) {
 	go client.readPump()
 }
 
-// Subscribe 订阅主题
-func (h *Hub) Subscribe(client *Client, topic string) {
+// Subscribe 订阅主题，按主题访问控制校验连接身份
+func (h *Hub) Subscribe(client *Client, topic string) error {
+	if !h.acl.Allowed(client.identity, topic) {
+		h.logger.WithFields(logrus.Fields{
+			"topic":    topic,
+			"identity": client.identity.Address,
+		}).Warn("WebSocket subscription denied by ACL")
+		return ErrTopicForbidden
+	}
+
 	h.mu.Lock()
 	defer h.mu.Unlock()
 
 	if h.subscriptions[topic] == nil {
 		h.subscriptions[topic] = make(map[*Client]bool)
+		h.bridgeSubscribe(topic)
 	}
 	h.subscriptions[topic][client] = true
 
@@ -164,6 +289,7 @@ func (h *Hub) Subscribe(client *Client, topic string) {
 	h.logger.WithFields(logrus.Fields{
 		"topic": topic,
 	}).Info("Client subscribed to topic")
+	return nil
 }
 
 // Unsubscribe 取消订阅
@@ -175,6 +301,7 @@ func (h *Hub) Unsubscribe(client *Client, topic string) {
 		delete(clients, client)
 		if len(clients) == 0 {
 			delete(h.subscriptions, topic)
+			h.bridgeUnsubscribe(topic)
 		}
 	}
 
@@ -188,14 +315,14 @@ func (h *Hub) Unsubscribe(client *Client, topic string) {
 }
 
 // PublishOrderBookUpdate 发布订单簿更新
+// 启用合并时只记录最新状态，由合并周期统一推送
 func (h *Hub) PublishOrderBookUpdate(update *types.OrderBookUpdate) {
-	topic := "orderbook." + update.TradingPair
-	message := Message{
-		Type: "orderbook_update",
-		Data: update,
+	h.bookReceived.Add(1)
+	if h.config.BookConflation > 0 {
+		h.conflateOrderBookUpdate(update)
+		return
 	}
-
-	h.publishToTopic(topic, message)
+	h.publishOrderBook(update)
 }
 
 // PublishTradeUpdate 发布交易更新
@@ -212,7 +339,7 @@ func (h *Hub) PublishTradeUpdate(update *types.TradeUpdate) {
 // PublishOrderUpdate 发布订单更新
 func (h *Hub) PublishOrderUpdate(update *types.OrderUpdate) {
 	// 发送给订单所有者
-	userTopic := "orders." + update.Order.UserAddress
+	userTopic := "orders." + strings.ToLower(update.Order.UserAddress)
 	message := Message{
 		Type: "order_update",
 		Data: update,
@@ -221,14 +348,89 @@ func (h *Hub) PublishOrderUpdate(update *types.OrderUpdate) {
 	h.publishToTopic(userTopic, message)
 }
 
-// publishToTopic 发布消息到指定主题
+// PublishPairStatus 发布交易对状态变化（熔断、恢复）
+func (h *Hub) PublishPairStatus(update *types.PairStatusUpdate) {
+	topic := "status." + update.TradingPair
+	message := Message{
+		Type: "pair_status",
+		Data: update,
+	}
+
+	h.publishToTopic(topic, message)
+}
+
+// PublishSystemStatus 发布系统状态变化（过载降级等）
+func (h *Hub) PublishSystemStatus(update *types.SystemStatusUpdate) {
+	message := Message{
+		Type: "system_status",
+		Data: update,
+	}
+
+	h.publishToTopic("system.status", message)
+}
+
+// PublishFillUpdate 发布用户成交（私有主题 fills.<address>）
+func (h *Hub) PublishFillUpdate(userAddress string, update *types.FillUpdate) {
+	h.publishToTopic("fills."+strings.ToLower(userAddress), Message{
+		Type: "fill_update",
+		Data: update,
+	})
+}
+
+// PublishExecutionReport 发布订单成交回报（私有主题 executions.<address>）
+func (h *Hub) PublishExecutionReport(report *types.ExecutionReport) {
+	h.publishToTopic("executions."+strings.ToLower(report.UserAddress), Message{
+		Type: "execution_report",
+		Data: report,
+	})
+}
+
+// PublishBalanceUpdate 发布用户余额变化（私有主题 balances.<address>）
+func (h *Hub) PublishBalanceUpdate(update *types.BalanceUpdate) {
+	h.publishToTopic("balances."+strings.ToLower(update.UserAddress), Message{
+		Type: "balance_update",
+		Data: update,
+	})
+}
+
+// PublishRiskAlert 发布用户风控告警（私有主题 risk.<address>）
+func (h *Hub) PublishRiskAlert(alert *types.RiskAlert) {
+	h.publishToTopic("risk."+strings.ToLower(alert.UserAddress), Message{
+		Type: "risk_alert",
+		Data: alert,
+	})
+}
+
+// PublishAdminAlert 发布运维告警（管理员主题 admin.alerts）
+func (h *Hub) PublishAdminAlert(alert *types.AdminAlert) {
+	h.publishToTopic("admin.alerts", Message{
+		Type: "admin_alert",
+		Data: alert,
+	})
+}
+
+// PublishSystemEvent 发布运维事件（管理员主题 system.admin）
+func (h *Hub) PublishSystemEvent(event *types.SystemEvent) {
+	h.publishToTopic(SystemAdminTopic, Message{
+		Type: "system_event",
+		Data: event,
+	})
+}
+
+// publishToTopic 发布消息到指定主题，启用消息桥时同时转发给其他实例
 func (h *Hub) publishToTopic(topic string, message Message) {
-	data, err := json.Marshal(message)
+	data, err := message.encode()
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to marshal message")
 		return
 	}
 
+	h.deliver(topic, data)
+	h.bridgePublish(topic, data)
+}
+
+// deliver 将已编码的消息投递给本实例的主题订阅者
+func (h *Hub) deliver(topic string, data []byte) {
 	h.mu.RLock()
 	clients, exists := h.subscriptions[topic]
 	if !exists {
@@ -243,13 +445,10 @@ func (h *Hub) publishToTopic(topic string, message Message) {
 	}
 	h.mu.RUnlock()
 
-	// 发送给所有订阅客户端
+	// 发送给所有订阅客户端，缓冲区满时按慢消费者策略处理
 	for _, client := range targetClients {
-		select {
-		case client.send <- data:
-		default:
-			// 客户端发送缓冲区满，关闭连接
-			h.unregister <- client
+		if !client.enqueue(topic, data) {
+			h.disconnectSlowConsumer(client, topic)
 		}
 	}
 }
@@ -262,11 +461,8 @@ func (c *Client) readPump() {
 	}()
 
 	c.conn.SetReadLimit(512)
-	c.conn.SetReadDeadline(time.Now().Add(60 * time.Second))
-	c.conn.SetPongHandler(func(string) error {
-		c.conn.SetReadDeadline(time.Now().Add(60 * time.Second))
-		return nil
-	})
+	c.conn.SetReadDeadline(time.Now().Add(c.hub.config.PongTimeout))
+	c.conn.SetPongHandler(c.handlePong)
 
 	for {
 		_, message, err := c.conn.ReadMessage()
@@ -289,7 +485,8 @@ func (c *Client) readPump() {
 
 // writePump 写入WebSocket消息
 func (c *Client) writePump() {
-	ticker := time.NewTicker(54 * time.Second)
+	config := c.hub.config
+	ticker := time.NewTicker(config.PingInterval)
 	defer func() {
 		ticker.Stop()
 		c.conn.Close()
@@ -298,7 +495,7 @@ func (c *Client) writePump() {
 	for {
 		select {
 		case message, ok := <-c.send:
-			c.conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+			c.conn.SetWriteDeadline(time.Now().Add(config.WriteTimeout))
 			if !ok {
 				c.conn.WriteMessage(websocket.CloseMessage, []byte{})
 				return
@@ -309,21 +506,54 @@ func (c *Client) writePump() {
 				return
 			}
 			w.Write(message)
+			c.recordWrite(message)
 
-			// 批量发送队列中的消息
+			// 批量发送队列中的消息，之后写出合并的订单簿（总是最新状态）
 			n := len(c.send)
 			for i := 0; i < n; i++ {
+				queued, ok := <-c.send
+				if !ok {
+					break
+				}
+				w.Write([]byte{'\n'})
+				w.Write(queued)
+				c.recordWrite(queued)
+			}
+			for _, pending := range c.takePending() {
 				w.Write([]byte{'\n'})
-				w.Write(<-c.send)
+				w.Write(pending)
+				c.recordWrite(pending)
 			}
 
 			if err := w.Close(); err != nil {
 				return
 			}
 
+		case <-c.wake:
+			pending := c.takePending()
+			if len(pending) == 0 {
+				continue
+			}
+			c.conn.SetWriteDeadline(time.Now().Add(config.WriteTimeout))
+			w, err := c.conn.NextWriter(websocket.TextMessage)
+			if err != nil {
+				return
+			}
+			for i, message := range pending {
+				if i > 0 {
+					w.Write([]byte{'\n'})
+				}
+				w.Write(message)
+				c.recordWrite(message)
+			}
+			if err := w.Close(); err != nil {
+				return
+			}
+
 		case <-ticker.C:
-			c.conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
-			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+			// 心跳携带发送时间，pong 返回后计算往返时间
+			c.conn.SetWriteDeadline(time.Now().Add(config.WriteTimeout))
+			if err := c.conn.WriteMessage(websocket.PingMessage, []byte(strconv.FormatInt(time.Now().UnixNano(), 10))); err != nil {
 				return
 			}
 		}
@@ -345,16 +575,46 @@ func (c *Client) handleSubscriptionMessage(msg *SubscribeMessage) {
 			return
 		}
 		topic = "trades." + msg.Symbol
-	case "orders":
-		// 需要用户地址验证
-		return
+	case "status":
+		if msg.Symbol == "" {
+			return
+		}
+		topic = "status." + msg.Symbol
+	case "system":
+		topic = "system.status"
+	case SystemAdminTopic:
+		topic = SystemAdminTopic
+	case "orders", "fills", "balances", "risk":
+		// 私有主题，默认订阅自己的地址，订阅他人需ACL授权
+		address := msg.Symbol
+		if address == "" {
+			address = c.identity.Address
+		}
+		if address == "" {
+			return
+		}
+		topic = msg.Channel + "." + strings.ToLower(address)
 	default:
 		return
 	}
 
 	switch msg.Action {
 	case "subscribe":
-		c.hub.Subscribe(c, topic)
+		if err := c.hub.Subscribe(c, topic); err != nil {
+			response := Message{
+				Type: "subscription_error",
+				Data: map[string]interface{}{
+					"channel": msg.Channel,
+					"symbol":  msg.Symbol,
+					"topic":   topic,
+					"error":   err.Error(),
+				},
+			}
+			if data, err := response.encode(); err == nil {
+				c.enqueue("", data)
+			}
+			return
+		}
 		
 		// 发送订阅确认
 		response := Message{
@@ -365,11 +625,8 @@ func (c *Client) handleSubscriptionMessage(msg *SubscribeMessage) {
 				"topic":   topic,
 			},
 		}
-		if data, err := json.Marshal(response); err == nil {
-			select {
-			case c.send <- data:
-			default:
-			}
+		if data, err := response.encode(); err == nil {
+			c.enqueue("", data)
 		}
 
 	case "unsubscribe":
@@ -384,15 +641,17 @@ func (c *Client) handleSubscriptionMessage(msg *SubscribeMessage) {
 				"topic":   topic,
 			},
 		}
-		if data, err := json.Marshal(response); err == nil {
-			select {
-			case c.send <- data:
-			default:
-			}
+		if data, err := response.encode(); err == nil {
+			c.enqueue("", data)
 		}
 	}
 }
 
+// Running Hub 事件循环是否在运行
+func (h *Hub) Running() bool {
+	return h.running.Load()
+}
+
 // GetConnectedClients 获取连接的客户端数量
 func (h *Hub) GetConnectedClients() int {
 	h.mu.RLock()
@@ -400,6 +659,11 @@ func (h *Hub) GetConnectedClients() int {
 	return len(h.clients)
 }
 
+// GetTopicACL 获取主题访问控制
+func (h *Hub) GetTopicACL() *TopicACL {
+	return h.acl
+}
+
 // GetSubscriptionStats 获取订阅统计
 func (h *Hub) GetSubscriptionStats() map[string]int {
 	h.mu.RLock()
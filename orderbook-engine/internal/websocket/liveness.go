@@ -0,0 +1,288 @@
+package websocket
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// SlowConsumerPolicy 客户端发送缓冲区满时的处理策略
+type SlowConsumerPolicy string
+
+const (
+	// PolicyDisconnect 断开连接，由客户端重连后重新订阅快照
+	PolicyDisconnect SlowConsumerPolicy = "disconnect"
+	// PolicyDropOldest 丢弃队列中最早的消息，保留最新消息
+	PolicyDropOldest SlowConsumerPolicy = "drop_oldest"
+	// PolicyConflate 订单簿更新只保留每个主题的最新一份，其余消息（成交、订单）无法合并时断开
+	PolicyConflate SlowConsumerPolicy = "conflate"
+)
+
+// ParseSlowConsumerPolicy 解析慢消费者策略
+func ParseSlowConsumerPolicy(value string) (SlowConsumerPolicy, error) {
+	switch policy := SlowConsumerPolicy(strings.ToLower(value)); policy {
+	case PolicyDisconnect, PolicyDropOldest, PolicyConflate:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("unknown slow consumer policy: %s", value)
+	}
+}
+
+// Config Hub 连接配置
+type Config struct {
+	SendBuffer     int                // 每个连接的发送缓冲（消息数）
+	PingInterval   time.Duration      // 服务端心跳间隔
+	PongTimeout    time.Duration      // 超过该时间未收到 pong 视为连接失活
+	WriteTimeout   time.Duration      // 单次写入超时
+	Policy         SlowConsumerPolicy // 发送缓冲区满时的处理策略
+	BookConflation time.Duration      // 订单簿更新合并周期，0 表示每次变化立即推送
+}
+
+// DefaultConfig 默认连接配置
+func DefaultConfig() Config {
+	return Config{
+		SendBuffer:   256,
+		PingInterval: 54 * time.Second,
+		PongTimeout:  60 * time.Second,
+		WriteTimeout: 10 * time.Second,
+		Policy:       PolicyDisconnect,
+	}
+}
+
+// clientStats 连接的发送与存活统计（原子计数，发布路径不加锁）
+type clientStats struct {
+	messagesSent  atomic.Uint64
+	bytesSent     atomic.Uint64
+	dropped       atomic.Uint64 // drop_oldest 策略丢弃的消息数
+	conflated     atomic.Uint64 // 被更新的订单簿覆盖而未发送的消息数
+	maxQueueDepth atomic.Int64
+	lastPong      atomic.Int64 // UnixNano
+	rtt           atomic.Int64 // 最近一次心跳往返时间（纳秒）
+}
+
+// ConnectionInfo 连接信息与滞后指标
+type ConnectionInfo struct {
+	ID              uuid.UUID  `json:"id"`
+	SessionID       *uuid.UUID `json:"session_id,omitempty"`
+	RemoteAddr      string     `json:"remote_addr"`
+	Identity        Identity   `json:"identity"`
+	Subscriptions   []string   `json:"subscriptions"`
+	ConnectedAt     time.Time  `json:"connected_at"`
+	LastPongAt      *time.Time `json:"last_pong_at,omitempty"`
+	RTTMillis       float64    `json:"rtt_ms"`
+	QueueDepth      int        `json:"queue_depth"` // 发送缓冲中待写出的消息数
+	QueueCapacity   int        `json:"queue_capacity"`
+	MaxQueueDepth   int64      `json:"max_queue_depth"`
+	PendingConflate int        `json:"pending_conflated"` // 等待写出的合并订单簿主题数
+	MessagesSent    uint64     `json:"messages_sent"`
+	BytesSent       uint64     `json:"bytes_sent"`
+	Dropped         uint64     `json:"dropped"`
+	Conflated       uint64     `json:"conflated"`
+}
+
+// enqueue 将消息放入连接的发送缓冲，返回 false 表示应断开该连接
+// 订单簿主题存在待发送的合并消息时直接覆盖，保证同一主题只写出最新状态
+func (c *Client) enqueue(topic string, data []byte) bool {
+	c.sendMu.Lock()
+	defer c.sendMu.Unlock()
+
+	if c.closed {
+		return true
+	}
+
+	conflatable := strings.HasPrefix(topic, "orderbook.")
+	if conflatable && c.pending[topic] != nil {
+		c.pending[topic] = data
+		c.stats.conflated.Add(1)
+		return true
+	}
+
+	select {
+	case c.send <- data:
+		c.recordQueueDepth()
+		return true
+	default:
+	}
+
+	switch c.hub.config.Policy {
+	case PolicyDropOldest:
+		select {
+		case <-c.send:
+			c.stats.dropped.Add(1)
+		default:
+		}
+		select {
+		case c.send <- data:
+		default:
+			c.stats.dropped.Add(1)
+		}
+		return true
+
+	case PolicyConflate:
+		if !conflatable {
+			return false
+		}
+		if c.pending == nil {
+			c.pending = make(map[string][]byte)
+		}
+		c.pending[topic] = data
+		c.stats.conflated.Add(1)
+		select {
+		case c.wake <- struct{}{}:
+		default:
+		}
+		return true
+
+	default:
+		return false
+	}
+}
+
+// takePending 取出待发送的合并订单簿消息
+func (c *Client) takePending() [][]byte {
+	c.sendMu.Lock()
+	defer c.sendMu.Unlock()
+
+	if len(c.pending) == 0 {
+		return nil
+	}
+	messages := make([][]byte, 0, len(c.pending))
+	for topic, data := range c.pending {
+		messages = append(messages, data)
+		delete(c.pending, topic)
+	}
+	return messages
+}
+
+// closeSend 关闭发送缓冲，之后的消息直接丢弃
+func (c *Client) closeSend() {
+	c.sendMu.Lock()
+	defer c.sendMu.Unlock()
+
+	if !c.closed {
+		c.closed = true
+		close(c.send)
+	}
+}
+
+// recordQueueDepth 记录发送缓冲的最高水位
+func (c *Client) recordQueueDepth() {
+	depth := int64(len(c.send))
+	for {
+		current := c.stats.maxQueueDepth.Load()
+		if depth <= current || c.stats.maxQueueDepth.CompareAndSwap(current, depth) {
+			return
+		}
+	}
+}
+
+// recordWrite 记录写出的消息
+func (c *Client) recordWrite(data []byte) {
+	c.stats.messagesSent.Add(1)
+	c.stats.bytesSent.Add(uint64(len(data)))
+}
+
+// handlePong 心跳响应：刷新读超时并以 ping 携带的发送时间计算往返时间
+func (c *Client) handlePong(payload string) error {
+	now := time.Now()
+	c.stats.lastPong.Store(now.UnixNano())
+	if sentAt, err := strconv.ParseInt(payload, 10, 64); err == nil {
+		c.stats.rtt.Store(now.UnixNano() - sentAt)
+	}
+	return c.conn.SetReadDeadline(now.Add(c.hub.config.PongTimeout))
+}
+
+// info 连接信息快照
+func (c *Client) info() ConnectionInfo {
+	c.mu.RLock()
+	subscriptions := make([]string, 0, len(c.subscriptions))
+	for topic := range c.subscriptions {
+		subscriptions = append(subscriptions, topic)
+	}
+	c.mu.RUnlock()
+	sort.Strings(subscriptions)
+
+	c.sendMu.Lock()
+	pending := len(c.pending)
+	c.sendMu.Unlock()
+
+	info := ConnectionInfo{
+		ID:              c.id,
+		RemoteAddr:      c.remoteAddr,
+		Identity:        c.identity,
+		Subscriptions:   subscriptions,
+		ConnectedAt:     c.connectedAt,
+		RTTMillis:       float64(c.stats.rtt.Load()) / float64(time.Millisecond),
+		QueueDepth:      len(c.send),
+		QueueCapacity:   cap(c.send),
+		MaxQueueDepth:   c.stats.maxQueueDepth.Load(),
+		PendingConflate: pending,
+		MessagesSent:    c.stats.messagesSent.Load(),
+		BytesSent:       c.stats.bytesSent.Load(),
+		Dropped:         c.stats.dropped.Load(),
+		Conflated:       c.stats.conflated.Load(),
+	}
+	if c.sessionID != uuid.Nil {
+		sessionID := c.sessionID
+		info.SessionID = &sessionID
+	}
+	if lastPong := c.stats.lastPong.Load(); lastPong > 0 {
+		at := time.Unix(0, lastPong)
+		info.LastPongAt = &at
+	}
+	return info
+}
+
+// GetConnections 列出全部连接及其订阅和滞后指标，按发送缓冲占用降序
+func (h *Hub) GetConnections() []ConnectionInfo {
+	h.mu.RLock()
+	clients := make([]*Client, 0, len(h.clients))
+	for client := range h.clients {
+		clients = append(clients, client)
+	}
+	h.mu.RUnlock()
+
+	connections := make([]ConnectionInfo, 0, len(clients))
+	for _, client := range clients {
+		connections = append(connections, client.info())
+	}
+	sort.Slice(connections, func(i, j int) bool {
+		if connections[i].QueueDepth != connections[j].QueueDepth {
+			return connections[i].QueueDepth > connections[j].QueueDepth
+		}
+		return connections[i].ConnectedAt.Before(connections[j].ConnectedAt)
+	})
+	return connections
+}
+
+// GetConfig 获取连接配置
+func (h *Hub) GetConfig() Config {
+	return h.config
+}
+
+// SlowConsumerDisconnects 因发送缓冲区满被断开的连接数
+func (h *Hub) SlowConsumerDisconnects() uint64 {
+	return h.slowDisconnects.Load()
+}
+
+// disconnectSlowConsumer 断开慢消费者，每个连接只触发一次
+func (h *Hub) disconnectSlowConsumer(client *Client, topic string) {
+	if !client.evicting.CompareAndSwap(false, true) {
+		return
+	}
+	h.slowDisconnects.Add(1)
+	h.logger.WithFields(logrus.Fields{
+		"client_id":   client.id,
+		"remote_addr": client.remoteAddr,
+		"topic":       topic,
+		"queue_depth": len(client.send),
+		"policy":      h.config.Policy,
+	}).Warn("Disconnecting slow WebSocket consumer")
+	go func() { h.unregister <- client }()
+}
@@ -0,0 +1,102 @@
+package websocket
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"orderbook-engine/internal/types"
+)
+
+func newTestClient(policy SlowConsumerPolicy) *Client {
+	hub := NewHub(logrus.New())
+	hub.SetConfig(Config{SendBuffer: 2, Policy: policy})
+	return &Client{
+		hub:           hub,
+		send:          make(chan []byte, hub.config.SendBuffer),
+		subscriptions: make(map[string]bool),
+		wake:          make(chan struct{}, 1),
+	}
+}
+
+func TestSlowConsumerPolicies(t *testing.T) {
+	// disconnect：缓冲区满即要求断开
+	client := newTestClient(PolicyDisconnect)
+	assert.True(t, client.enqueue("trades.WETH-USDC", []byte("1")))
+	assert.True(t, client.enqueue("trades.WETH-USDC", []byte("2")))
+	assert.False(t, client.enqueue("trades.WETH-USDC", []byte("3")))
+
+	// drop_oldest：丢弃最早的消息
+	client = newTestClient(PolicyDropOldest)
+	for _, message := range []string{"1", "2", "3"} {
+		assert.True(t, client.enqueue("trades.WETH-USDC", []byte(message)))
+	}
+	assert.Equal(t, "2", string(<-client.send))
+	assert.Equal(t, "3", string(<-client.send))
+	assert.Equal(t, uint64(1), client.stats.dropped.Load())
+
+	// conflate：订单簿只保留最新一份，成交无法合并时断开
+	client = newTestClient(PolicyConflate)
+	assert.True(t, client.enqueue("trades.WETH-USDC", []byte("t1")))
+	assert.True(t, client.enqueue("trades.WETH-USDC", []byte("t2")))
+	assert.True(t, client.enqueue("orderbook.WETH-USDC", []byte("b1")))
+	assert.True(t, client.enqueue("orderbook.WETH-USDC", []byte("b2")))
+	assert.False(t, client.enqueue("trades.WETH-USDC", []byte("t3")))
+
+	<-client.send
+	<-client.send
+	// 缓冲区腾出空间后，新的订单簿更新仍覆盖待发送的合并消息，不会先于旧状态写出
+	assert.True(t, client.enqueue("orderbook.WETH-USDC", []byte("b3")))
+	assert.Empty(t, client.send)
+	assert.Equal(t, [][]byte{[]byte("b3")}, client.takePending())
+	assert.Equal(t, uint64(3), client.stats.conflated.Load())
+}
+
+func TestOrderBookConflation(t *testing.T) {
+	client := newTestClient(PolicyConflate)
+	client.send = make(chan []byte, 16)
+	hub := client.hub
+	hub.SetConfig(Config{BookConflation: 10 * time.Millisecond})
+	assert.NoError(t, hub.Subscribe(client, "orderbook.WETH-USDC"))
+
+	for i := int64(1); i <= 3; i++ {
+		hub.PublishOrderBookUpdate(&types.OrderBookUpdate{
+			TradingPair: "WETH-USDC",
+			Bids:        []types.OrderBookLevel{{Price: decimal.NewFromInt(2000), Amount: decimal.NewFromInt(i), Count: 1}},
+		})
+	}
+	go hub.runBookConflation(hub.config.BookConflation)
+
+	// 同一周期内的三次更新合并为一条，内容为最新状态
+	var message struct {
+		Data types.OrderBookUpdate `json:"data"`
+	}
+	select {
+	case data := <-client.send:
+		assert.NoError(t, json.Unmarshal(data, &message))
+	case <-time.After(time.Second):
+		t.Fatal("conflated order book update not published")
+	}
+	assert.True(t, message.Data.Bids[0].Amount.Equal(decimal.NewFromInt(3)))
+	assert.Empty(t, client.send)
+	assert.Equal(t, ConflationStats{Interval: "10ms", Received: 3, Published: 1}, hub.GetConflationStats())
+}
+
+func TestSystemAdminTopicRequiresAdmin(t *testing.T) {
+	acl, err := NewTopicACL("")
+	assert.NoError(t, err)
+
+	user := Identity{Address: "0xaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"}
+	assert.True(t, acl.Allowed(Identity{}, "system.status"))
+	assert.False(t, acl.Allowed(Identity{}, SystemAdminTopic))
+	assert.False(t, acl.Allowed(user, SystemAdminTopic))
+	assert.True(t, acl.Allowed(Identity{Admin: true}, SystemAdminTopic))
+
+	// 只读运维账号可经额外授权订阅
+	assert.NoError(t, acl.Grant(user.Address, SystemAdminTopic))
+	assert.True(t, acl.Allowed(user, SystemAdminTopic))
+}
@@ -0,0 +1,101 @@
+package websocket
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/nats-io/nats.go"
+)
+
+// ErrInvalidBridgeTopic 主题无法映射为消息桥主题（含通配符或空白）
+var ErrInvalidBridgeTopic = errors.New("topic cannot be bridged")
+
+// NATSBridge 基于 NATS 核心发布订阅的多实例消息桥
+// 主题映射为 <prefix>.<topic>，NATS 按订阅兴趣路由，只送达订阅了该主题的实例；
+// 连接关闭回显，本实例不会收到自己发布的消息
+type NATSBridge struct {
+	conn   *nats.Conn
+	prefix string
+
+	mu   sync.Mutex
+	subs map[string]*nats.Subscription // 主题 -> 订阅
+}
+
+// NewNATSBridge 连接 NATS，subjectPrefix 为空时直接使用主题名
+func NewNATSBridge(url, subjectPrefix string) (*NATSBridge, error) {
+	conn, err := nats.Connect(url, nats.Name("orderbook-engine-websocket"), nats.MaxReconnects(-1), nats.NoEcho())
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to NATS: %w", err)
+	}
+	return &NATSBridge{
+		conn:   conn,
+		prefix: subjectPrefix,
+		subs:   make(map[string]*nats.Subscription),
+	}, nil
+}
+
+// subject 主题对应的 NATS 主题
+// 客户端可以订阅任意主题名，含通配符的主题会匹配其他主题（如 "orders.>"），必须拒绝
+func (b *NATSBridge) subject(topic string) (string, error) {
+	for _, token := range strings.Split(topic, ".") {
+		if token == "" || strings.ContainsAny(token, "*> \t\r\n") {
+			return "", fmt.Errorf("%w: %q", ErrInvalidBridgeTopic, topic)
+		}
+	}
+	if b.prefix == "" {
+		return topic, nil
+	}
+	return b.prefix + "." + topic, nil
+}
+
+// Publish 发布消息，NATS 客户端异步发送，连接中断期间的消息在重连缓冲内暂存
+func (b *NATSBridge) Publish(topic string, data []byte) error {
+	subject, err := b.subject(topic)
+	if err != nil {
+		return err
+	}
+	return b.conn.Publish(subject, data)
+}
+
+// Subscribe 订阅主题，重复订阅时忽略
+func (b *NATSBridge) Subscribe(topic string, deliver func(data []byte)) error {
+	subject, err := b.subject(topic)
+	if err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, exists := b.subs[topic]; exists {
+		return nil
+	}
+	sub, err := b.conn.Subscribe(subject, func(msg *nats.Msg) {
+		deliver(msg.Data)
+	})
+	if err != nil {
+		return fmt.Errorf("subscribe %s: %w", subject, err)
+	}
+	b.subs[topic] = sub
+	return nil
+}
+
+// Unsubscribe 退订主题
+func (b *NATSBridge) Unsubscribe(topic string) error {
+	b.mu.Lock()
+	sub, exists := b.subs[topic]
+	delete(b.subs, topic)
+	b.mu.Unlock()
+
+	if !exists {
+		return nil
+	}
+	return sub.Unsubscribe()
+}
+
+// Close 断开连接
+func (b *NATSBridge) Close() error {
+	return b.conn.Drain()
+}
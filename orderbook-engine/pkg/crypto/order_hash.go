@@ -0,0 +1,138 @@
+package crypto
+
+import (
+	"encoding/hex"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/shopspring/decimal"
+
+	"orderbook-engine/internal/types"
+)
+
+// EIP-712 域与订单类型定义，必须与 OptimizedSettlement 合约保持一致
+// 合约：__EIP712_init("OrderBook DEX", "1") 与 ORDER_TYPEHASH
+const (
+	DomainName    = "OrderBook DEX"
+	DomainVersion = "1"
+	DomainTypeDef = "EIP712Domain(string name,string version,uint256 chainId,address verifyingContract)"
+	OrderTypeDef  = "Order(address userAddress,address baseToken,address quoteToken,uint8 side,uint8 orderType,uint256 price,uint256 amount,uint256 expiresAt,uint256 nonce)"
+)
+
+// 撤单与批量作废请求的类型定义（仅链下校验使用）
+const (
+	CancelOrderTypeDef = "CancelOrder(bytes32 orderHash,address userAddress,uint256 nonce,uint256 expiresAt)"
+	CancelUpToTypeDef  = "CancelUpTo(address userAddress,uint256 minNonce,uint256 expiresAt)"
+)
+
+// orderTypeHash 订单类型哈希（ORDER_TYPEHASH）
+var orderTypeHash = crypto.Keccak256Hash([]byte(OrderTypeDef))
+
+// TypedOrder EIP-712订单结构，字段与合约 CompactOrder 一致
+// 订单去重、签名验证和链上结算统一基于此结构计算哈希
+type TypedOrder struct {
+	UserAddress common.Address
+	BaseToken   common.Address
+	QuoteToken  common.Address
+	Side        uint8 // 0=买入，1=卖出
+	OrderType   uint8 // 0=限价，1=市价，2=止损，3=止盈
+	Price       *big.Int
+	Amount      *big.Int
+	ExpiresAt   uint64 // Unix时间戳，0表示不过期
+	Nonce       uint64
+}
+
+// NewTypedOrder 由订单字段构造EIP-712订单结构
+// 价格和数量直接使用decimal的整数部分（前端已处理小数位）
+func NewTypedOrder(userAddress, baseToken, quoteToken string, side types.OrderSide, orderType types.OrderType, price, amount decimal.Decimal, expiresAt *time.Time, nonce uint64) *TypedOrder {
+	typed := &TypedOrder{
+		UserAddress: common.HexToAddress(userAddress),
+		BaseToken:   common.HexToAddress(baseToken),
+		QuoteToken:  common.HexToAddress(quoteToken),
+		Price:       price.BigInt(),
+		Amount:      amount.BigInt(),
+		Side:        SideCode(side),
+		OrderType:   OrderTypeCode(orderType),
+		Nonce:       nonce,
+	}
+
+	if expiresAt != nil {
+		typed.ExpiresAt = uint64(expiresAt.Unix())
+	}
+	return typed
+}
+
+// SideCode 订单方向的签名编码：0=买入，1=卖出
+func SideCode(side types.OrderSide) uint8 {
+	if side == types.OrderSideSell {
+		return 1
+	}
+	return 0
+}
+
+// OrderTypeCode 订单类型的签名编码：0=限价，1=市价，2=止损，3=止盈
+func OrderTypeCode(orderType types.OrderType) uint8 {
+	switch orderType {
+	case types.OrderTypeMarket:
+		return 1
+	case types.OrderTypeStopLoss:
+		return 2
+	case types.OrderTypeTakeProfit:
+		return 3
+	}
+	return 0
+}
+
+// TypedOrderFromSigned 由API签名订单构造EIP-712订单结构
+func TypedOrderFromSigned(order *types.SignedOrder) *TypedOrder {
+	return NewTypedOrder(order.UserAddress, order.BaseToken, order.QuoteToken, order.Side, order.Type,
+		order.Price, order.Amount, order.ExpiresAt, order.Nonce)
+}
+
+// TypedOrderFromOrder 由引擎订单构造EIP-712订单结构
+func TypedOrderFromOrder(order *types.Order) *TypedOrder {
+	return NewTypedOrder(order.UserAddress, order.BaseToken, order.QuoteToken, order.Side, order.Type,
+		order.Price, order.Amount, order.ExpiresAt, order.Nonce)
+}
+
+// StructHash 计算订单结构体哈希：keccak256(abi.encode(ORDER_TYPEHASH, ...))
+func (o *TypedOrder) StructHash() common.Hash {
+	var structData []byte
+	structData = append(structData, orderTypeHash.Bytes()...)
+	structData = append(structData, common.LeftPadBytes(o.UserAddress.Bytes(), 32)...)
+	structData = append(structData, common.LeftPadBytes(o.BaseToken.Bytes(), 32)...)
+	structData = append(structData, common.LeftPadBytes(o.QuoteToken.Bytes(), 32)...)
+	structData = append(structData, common.LeftPadBytes([]byte{o.Side}, 32)...)
+	structData = append(structData, common.LeftPadBytes([]byte{o.OrderType}, 32)...)
+	structData = append(structData, common.LeftPadBytes(o.Price.Bytes(), 32)...)
+	structData = append(structData, common.LeftPadBytes(o.Amount.Bytes(), 32)...)
+	structData = append(structData, common.LeftPadBytes(new(big.Int).SetUint64(o.ExpiresAt).Bytes(), 32)...)
+	structData = append(structData, common.LeftPadBytes(new(big.Int).SetUint64(o.Nonce).Bytes(), 32)...)
+	return crypto.Keccak256Hash(structData)
+}
+
+// HashTypedOrder 计算订单的EIP-712摘要，等价于合约 _hashTypedDataV4(structHash)
+func (s *OrderSigner) HashTypedOrder(order *TypedOrder) common.Hash {
+	structHash := order.StructHash()
+
+	// \x19\x01 是EIP-712的魔数前缀
+	var finalData []byte
+	finalData = append(finalData, []byte("\x19\x01")...)
+	finalData = append(finalData, s.domainSeparator[:]...)
+	finalData = append(finalData, structHash.Bytes()...)
+	return crypto.Keccak256Hash(finalData)
+}
+
+// GenerateOrderHash 生成订单唯一哈希（用于数据库索引和去重）
+// 与签名验证、链上结算使用同一EIP-712摘要，返回不含0x前缀的小写十六进制
+func (s *OrderSigner) GenerateOrderHash(order *types.SignedOrder) string {
+	hash := s.HashTypedOrder(TypedOrderFromSigned(order))
+	return hex.EncodeToString(hash.Bytes())
+}
+
+// DomainSeparator 获取EIP-712域分隔符
+func (s *OrderSigner) DomainSeparator() common.Hash {
+	return s.domainSeparator
+}
@@ -4,14 +4,12 @@ package crypto
 
 import (
 	"crypto/ecdsa"
-	"encoding/hex"
 	"fmt"
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/crypto"
-	"golang.org/x/crypto/sha3"
 
 	"orderbook-engine/internal/types"
 )
@@ -19,8 +17,9 @@ import (
 // OrderSigner 订单签名器
 // 实现EIP-712标准的类型化数据签名
 type OrderSigner struct {
-	chainID *big.Int         // 区块链网络ID
-	domainSeparator [32]byte // EIP-712域分隔符
+	chainID           *big.Int       // 区块链网络ID
+	verifyingContract common.Address // 签名域的验证合约地址
+	domainSeparator   [32]byte       // EIP-712域分隔符
 }
 
 // NewOrderSigner 创建订单签名器
@@ -30,9 +29,9 @@ type OrderSigner struct {
 func NewOrderSigner(chainID *big.Int, contractAddress common.Address) *OrderSigner {
 	// 计算EIP-712域分隔符
 	// 域类型哈希：EIP712Domain(string name,string version,uint256 chainId,address verifyingContract)
-	domainTypeHash := crypto.Keccak256Hash([]byte("EIP712Domain(string name,string version,uint256 chainId,address verifyingContract)"))
-	nameHash := crypto.Keccak256Hash([]byte("OrderBook DEX"))    // DEX名称
-	versionHash := crypto.Keccak256Hash([]byte("1.0"))           // 版本号
+	domainTypeHash := crypto.Keccak256Hash([]byte(DomainTypeDef))
+	nameHash := crypto.Keccak256Hash([]byte(DomainName))       // DEX名称
+	versionHash := crypto.Keccak256Hash([]byte(DomainVersion)) // 版本号
 	
 	// 按照EIP-712标准正确计算域分隔符哈希
 	// 需要直接连接各个哈希值和数据，而不是分别传递给Keccak256Hash
@@ -45,8 +44,9 @@ func NewOrderSigner(chainID *big.Int, contractAddress common.Address) *OrderSign
 	domainSeparator := crypto.Keccak256Hash(domainData)
 
 	return &OrderSigner{
-		chainID: chainID,
-		domainSeparator: domainSeparator,
+		chainID:           chainID,
+		verifyingContract: contractAddress,
+		domainSeparator:   domainSeparator,
 	}
 }
 
@@ -55,63 +55,49 @@ func NewOrderSigner(chainID *big.Int, contractAddress common.Address) *OrderSign
 // @param order 已签名订单
 // @return 订单哈希值
 func (s *OrderSigner) HashOrder(order *types.SignedOrder) (common.Hash, error) {
-	// 订单类型哈希，定义订单结构 - 匹配Solidity合约
-	orderTypeHash := crypto.Keccak256Hash([]byte(
-		"Order(address userAddress,address baseToken,address quoteToken,uint8 side,uint8 orderType,uint256 price,uint256 amount,uint256 expiresAt,uint256 nonce)",
-	))
-
-	// 将订单数据转换为字节数组
-	userAddress := common.HexToAddress(order.UserAddress)          // 用户地址
-	baseToken := common.HexToAddress(order.BaseToken)              // 基础代币地址
-	quoteToken := common.HexToAddress(order.QuoteToken)            // 报价代币地址
-	
-	// 转换订单方向：0=买入，1=卖出
-	side := uint8(0)
-	if order.Side == types.OrderSideSell {
-		side = 1
+	return s.HashTypedOrder(TypedOrderFromSigned(order)), nil
+}
+
+// VerifyOrderSignature 验证订单签名
+// 通过恢复签名的公钥地址，并与订单中的用户地址比较
+// @param order 已签名订单
+// @return 签名是否有效
+func (s *OrderSigner) VerifyOrderSignature(order *types.SignedOrder) (bool, error) {
+	// 计算订单哈希
+	orderHash, err := s.HashOrder(order)
+	if err != nil {
+		return false, fmt.Errorf("failed to hash order: %w", err)
 	}
-	
-	// 转换订单类型：0=限价，1=市价，2=止损，3=止盈
-	orderType := uint8(0)
-	switch order.Type {
-	case types.OrderTypeMarket:
-		orderType = 1
-	case types.OrderTypeStopLoss:
-		orderType = 2
-	case types.OrderTypeTakeProfit:
-		orderType = 3
-	}
-
-	// 价格和数量直接使用decimal的BigInt值（前端已处理小数位）
-	price := order.Price.BigInt()   // 价格不需要额外转换
-	amount := order.Amount.BigInt() // 数量不需要额外转换
-	
-	// 过期时间转换为Unix时间戳
-	expiresAt := big.NewInt(0)
-	if order.ExpiresAt != nil {
-		expiresAt = big.NewInt(order.ExpiresAt.Unix())
+
+	return verifySignature(orderHash, order.Signature, order.UserAddress)
+}
+
+// HashCancel 计算撤单请求的EIP-712哈希
+// @param cancel 已签名撤单请求
+// @return 撤单哈希值
+func (s *OrderSigner) HashCancel(cancel *types.SignedCancel) (common.Hash, error) {
+	cancelTypeHash := crypto.Keccak256Hash([]byte(CancelOrderTypeDef))
+
+	orderHash, err := hexutil.Decode(ensureHexPrefix(cancel.OrderHash))
+	if err != nil || len(orderHash) != 32 {
+		return common.Hash{}, fmt.Errorf("invalid order hash: %s", cancel.OrderHash)
 	}
-	
-	// 随机数
-	nonce := big.NewInt(int64(order.Nonce))
+	if cancel.ExpiresAt < 0 {
+		return common.Hash{}, fmt.Errorf("invalid expiry: %d", cancel.ExpiresAt)
+	}
+
+	userAddress := common.HexToAddress(cancel.UserAddress)
+	nonce := new(big.Int).SetUint64(cancel.Nonce)
+	expiresAt := big.NewInt(cancel.ExpiresAt)
 
-	// 计算结构体哈希
-	// 按照订单类型定义的顺序组装数据
 	var structData []byte
-	structData = append(structData, orderTypeHash.Bytes()...)
+	structData = append(structData, cancelTypeHash.Bytes()...)
+	structData = append(structData, orderHash...)
 	structData = append(structData, common.LeftPadBytes(userAddress.Bytes(), 32)...)
-	structData = append(structData, common.LeftPadBytes(baseToken.Bytes(), 32)...)
-	structData = append(structData, common.LeftPadBytes(quoteToken.Bytes(), 32)...)
-	structData = append(structData, common.LeftPadBytes([]byte{side}, 32)...)
-	structData = append(structData, common.LeftPadBytes([]byte{orderType}, 32)...)
-	structData = append(structData, common.LeftPadBytes(price.Bytes(), 32)...)
-	structData = append(structData, common.LeftPadBytes(amount.Bytes(), 32)...)
-	structData = append(structData, common.LeftPadBytes(expiresAt.Bytes(), 32)...)
 	structData = append(structData, common.LeftPadBytes(nonce.Bytes(), 32)...)
+	structData = append(structData, common.LeftPadBytes(expiresAt.Bytes(), 32)...)
 	structHash := crypto.Keccak256Hash(structData)
 
-	// 生成EIP-712类型化数据哈希
-	// \x19\x01 是EIP-712的魔数前缀
 	var finalData []byte
 	finalData = append(finalData, []byte("\x19\x01")...)
 	finalData = append(finalData, s.domainSeparator[:]...)
@@ -119,26 +105,101 @@ func (s *OrderSigner) HashOrder(order *types.SignedOrder) (common.Hash, error) {
 	return crypto.Keccak256Hash(finalData), nil
 }
 
-// VerifyOrderSignature 验证订单签名
-// 通过恢复签名的公钥地址，并与订单中的用户地址比较
-// @param order 已签名订单
+// VerifyCancelSignature 验证撤单签名
+// 签名者必须是撤单请求中的用户地址
+// @param cancel 已签名撤单请求
 // @return 签名是否有效
-func (s *OrderSigner) VerifyOrderSignature(order *types.SignedOrder) (bool, error) {
-	// 计算订单哈希
-	orderHash, err := s.HashOrder(order)
+func (s *OrderSigner) VerifyCancelSignature(cancel *types.SignedCancel) (bool, error) {
+	cancelHash, err := s.HashCancel(cancel)
 	if err != nil {
-		return false, fmt.Errorf("failed to hash order: %w", err)
+		return false, fmt.Errorf("failed to hash cancel: %w", err)
+	}
+
+	return verifySignature(cancelHash, cancel.Signature, cancel.UserAddress)
+}
+
+// HashNonceCancel 计算批量作废请求的EIP-712哈希
+// @param cancel 已签名批量作废请求
+// @return 请求哈希值
+func (s *OrderSigner) HashNonceCancel(cancel *types.SignedNonceCancel) (common.Hash, error) {
+	cancelTypeHash := crypto.Keccak256Hash([]byte(CancelUpToTypeDef))
+
+	if cancel.ExpiresAt < 0 {
+		return common.Hash{}, fmt.Errorf("invalid expiry: %d", cancel.ExpiresAt)
 	}
 
+	userAddress := common.HexToAddress(cancel.UserAddress)
+	minNonce := new(big.Int).SetUint64(cancel.MinNonce)
+	expiresAt := big.NewInt(cancel.ExpiresAt)
+
+	var structData []byte
+	structData = append(structData, cancelTypeHash.Bytes()...)
+	structData = append(structData, common.LeftPadBytes(userAddress.Bytes(), 32)...)
+	structData = append(structData, common.LeftPadBytes(minNonce.Bytes(), 32)...)
+	structData = append(structData, common.LeftPadBytes(expiresAt.Bytes(), 32)...)
+	structHash := crypto.Keccak256Hash(structData)
+
+	var finalData []byte
+	finalData = append(finalData, []byte("\x19\x01")...)
+	finalData = append(finalData, s.domainSeparator[:]...)
+	finalData = append(finalData, structHash.Bytes()...)
+	return crypto.Keccak256Hash(finalData), nil
+}
+
+// VerifyNonceCancelSignature 验证批量作废请求签名
+// @param cancel 已签名批量作废请求
+// @return 签名是否有效
+func (s *OrderSigner) VerifyNonceCancelSignature(cancel *types.SignedNonceCancel) (bool, error) {
+	cancelHash, err := s.HashNonceCancel(cancel)
+	if err != nil {
+		return false, fmt.Errorf("failed to hash cancel: %w", err)
+	}
+
+	return verifySignature(cancelHash, cancel.Signature, cancel.UserAddress)
+}
+
+// HashPersonalMessage 计算EIP-191 personal_sign 消息哈希
+// @param message 明文消息
+// @return 消息哈希值
+func HashPersonalMessage(message string) common.Hash {
+	return crypto.Keccak256Hash([]byte(fmt.Sprintf("\x19Ethereum Signed Message:\n%d%s", len(message), message)))
+}
+
+// VerifyPersonalSignature 验证钱包 personal_sign 签名
+// 签名者必须是期望的用户地址
+// @param message 明文消息
+// @param signatureHex 十六进制签名
+// @param userAddress 期望的签名地址
+// @return 签名是否有效
+func VerifyPersonalSignature(message, signatureHex, userAddress string) (bool, error) {
+	return verifySignature(HashPersonalMessage(message), signatureHex, userAddress)
+}
+
+// verifySignature 从签名恢复地址并与期望地址比较
+func verifySignature(hash common.Hash, signatureHex, expected string) (bool, error) {
+	recoveredAddress, err := RecoverAddress(hash, signatureHex)
+	if err != nil {
+		return false, err
+	}
+
+	// 比较恢复的地址与期望的用户地址
+	return recoveredAddress == common.HexToAddress(expected), nil
+}
+
+// RecoverAddress 从签名恢复签名者地址
+// @param hash 签名的消息摘要
+// @param signatureHex 十六进制签名，v值可为0/1或27/28
+// @return 签名者地址
+func RecoverAddress(hash common.Hash, signatureHex string) (common.Address, error) {
 	// 解码十六进制签名
-	signature, err := hexutil.Decode(order.Signature)
+	signature, err := hexutil.Decode(signatureHex)
 	if err != nil {
-		return false, fmt.Errorf("failed to decode signature: %w", err)
+		return common.Address{}, fmt.Errorf("failed to decode signature: %w", err)
 	}
 
 	// 验证签名长度：65字节（r:32 + s:32 + v:1）
 	if len(signature) != 65 {
-		return false, fmt.Errorf("invalid signature length: %d", len(signature))
+		return common.Address{}, fmt.Errorf("invalid signature length: %d", len(signature))
 	}
 
 	// 修正recovery ID（v值）
@@ -148,23 +209,19 @@ func (s *OrderSigner) VerifyOrderSignature(order *types.SignedOrder) (bool, erro
 	}
 
 	// 从签名中恢复公钥
-	pubkey, err := crypto.Ecrecover(orderHash.Bytes(), signature)
+	pubkey, err := crypto.Ecrecover(hash.Bytes(), signature)
 	if err != nil {
-		return false, fmt.Errorf("failed to recover pubkey: %w", err)
+		return common.Address{}, fmt.Errorf("failed to recover pubkey: %w", err)
 	}
 
 	// 解析公钥
 	recoveredPubkey, err := crypto.UnmarshalPubkey(pubkey)
 	if err != nil {
-		return false, fmt.Errorf("failed to unmarshal pubkey: %w", err)
+		return common.Address{}, fmt.Errorf("failed to unmarshal pubkey: %w", err)
 	}
 
 	// 从公钥推导出地址
-	recoveredAddress := crypto.PubkeyToAddress(*recoveredPubkey)
-	expectedAddress := common.HexToAddress(order.UserAddress)
-
-	// 比较恢复的地址与订单中的用户地址
-	return recoveredAddress == expectedAddress, nil
+	return crypto.PubkeyToAddress(*recoveredPubkey), nil
 }
 
 // SignOrder 签名订单（仅用于测试）
@@ -197,27 +254,48 @@ func SignOrder(order *types.SignedOrder, privateKey *ecdsa.PrivateKey, signer *O
 	return nil
 }
 
-// GenerateOrderHash 生成订单唯一哈希（用于数据库索引）
-// 此哈希不同于EIP-712哈希，仅用于数据库查询和去重
-// @param order 订单对象
-// @return 订单的唯一标识哈希字符串
-func GenerateOrderHash(order *types.SignedOrder) string {
-	// 拼接订单关键字段
-	data := fmt.Sprintf("%s%s%s%s%d%d%s%s%d%d",
-		order.UserAddress,
-		order.TradingPair,
-		order.BaseToken,
-		order.QuoteToken,
-		order.Side,
-		order.Type,
-		order.Price.String(),
-		order.Amount.String(),
-		order.ExpiresAt.Unix(),
-		order.Nonce,
-	)
-	
-	// 使用Keccak256生成哈希
-	hash := sha3.NewLegacyKeccak256()
-	hash.Write([]byte(data))
-	return hex.EncodeToString(hash.Sum(nil))
-}
\ No newline at end of file
+// SignCancel 签名撤单请求（仅用于测试）
+// @param cancel 待签名撤单请求
+// @param privateKey ECDSA私钥
+// @param signer 订单签名器实例
+// @return 签名错误
+func SignCancel(cancel *types.SignedCancel, privateKey *ecdsa.PrivateKey, signer *OrderSigner) error {
+	cancelHash, err := signer.HashCancel(cancel)
+	if err != nil {
+		return fmt.Errorf("failed to hash cancel: %w", err)
+	}
+
+	signature, err := crypto.Sign(cancelHash.Bytes(), privateKey)
+	if err != nil {
+		return fmt.Errorf("failed to sign cancel: %w", err)
+	}
+	if signature[64] < 27 {
+		signature[64] += 27
+	}
+
+	cancel.Signature = hexutil.Encode(signature)
+	return nil
+}
+
+// SignPersonalMessage 对消息进行 personal_sign 签名（仅用于测试）
+// @param message 明文消息
+// @param privateKey ECDSA私钥
+// @return 十六进制签名
+func SignPersonalMessage(message string, privateKey *ecdsa.PrivateKey) (string, error) {
+	signature, err := crypto.Sign(HashPersonalMessage(message).Bytes(), privateKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign message: %w", err)
+	}
+	if signature[64] < 27 {
+		signature[64] += 27
+	}
+	return hexutil.Encode(signature), nil
+}
+
+// ensureHexPrefix 补全0x前缀（数据库中的订单哈希不含前缀）
+func ensureHexPrefix(value string) string {
+	if len(value) >= 2 && (value[:2] == "0x" || value[:2] == "0X") {
+		return value
+	}
+	return "0x" + value
+}
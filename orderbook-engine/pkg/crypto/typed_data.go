@@ -0,0 +1,123 @@
+package crypto
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// TypedDataField EIP-712 类型字段
+type TypedDataField struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// EIP-712 类型字段，按 eth_signTypedData_v4 的 types 格式描述，编码后与对应的类型字符串一致
+var (
+	DomainFields = []TypedDataField{
+		{Name: "name", Type: "string"},
+		{Name: "version", Type: "string"},
+		{Name: "chainId", Type: "uint256"},
+		{Name: "verifyingContract", Type: "address"},
+	}
+	OrderFields = []TypedDataField{
+		{Name: "userAddress", Type: "address"},
+		{Name: "baseToken", Type: "address"},
+		{Name: "quoteToken", Type: "address"},
+		{Name: "side", Type: "uint8"},
+		{Name: "orderType", Type: "uint8"},
+		{Name: "price", Type: "uint256"},
+		{Name: "amount", Type: "uint256"},
+		{Name: "expiresAt", Type: "uint256"},
+		{Name: "nonce", Type: "uint256"},
+	}
+	CancelOrderFields = []TypedDataField{
+		{Name: "orderHash", Type: "bytes32"},
+		{Name: "userAddress", Type: "address"},
+		{Name: "nonce", Type: "uint256"},
+		{Name: "expiresAt", Type: "uint256"},
+	}
+	CancelUpToFields = []TypedDataField{
+		{Name: "userAddress", Type: "address"},
+		{Name: "minNonce", Type: "uint256"},
+		{Name: "expiresAt", Type: "uint256"},
+	}
+)
+
+// EncodeType 按 EIP-712 encodeType 规则生成类型字符串，如 Order(address userAddress,...)
+func EncodeType(name string, fields []TypedDataField) string {
+	parts := make([]string, len(fields))
+	for i, field := range fields {
+		parts[i] = field.Type + " " + field.Name
+	}
+	return name + "(" + strings.Join(parts, ",") + ")"
+}
+
+// Domain EIP-712 签名域
+type Domain struct {
+	Name              string `json:"name"`
+	Version           string `json:"version"`
+	ChainID           uint64 `json:"chainId"`
+	VerifyingContract string `json:"verifyingContract"`
+}
+
+// Domain 获取签名域
+func (s *OrderSigner) Domain() Domain {
+	return Domain{
+		Name:              DomainName,
+		Version:           DomainVersion,
+		ChainID:           s.chainID.Uint64(),
+		VerifyingContract: s.verifyingContract.Hex(),
+	}
+}
+
+// Message 订单的签名消息，整数以十进制字符串表示，与 eth_signTypedData_v4 的 message 格式一致
+// 即服务端实际参与哈希的取值，价格和数量的小数部分已被截断
+func (o *TypedOrder) Message() map[string]string {
+	return map[string]string{
+		"userAddress": o.UserAddress.Hex(),
+		"baseToken":   o.BaseToken.Hex(),
+		"quoteToken":  o.QuoteToken.Hex(),
+		"side":        strconv.Itoa(int(o.Side)),
+		"orderType":   strconv.Itoa(int(o.OrderType)),
+		"price":       o.Price.String(),
+		"amount":      o.Amount.String(),
+		"expiresAt":   strconv.FormatUint(o.ExpiresAt, 10),
+		"nonce":       strconv.FormatUint(o.Nonce, 10),
+	}
+}
+
+// TypedData 订单的完整 eth_signTypedData_v4 请求数据，前端可直接交给钱包签名
+type TypedData struct {
+	Types       map[string][]TypedDataField `json:"types"`
+	PrimaryType string                      `json:"primaryType"`
+	Domain      Domain                      `json:"domain"`
+	Message     map[string]string           `json:"message"`
+}
+
+// OrderTypedData 生成订单的签名请求数据
+func (s *OrderSigner) OrderTypedData(order *TypedOrder) *TypedData {
+	return &TypedData{
+		Types:       map[string][]TypedDataField{"EIP712Domain": DomainFields, "Order": OrderFields},
+		PrimaryType: "Order",
+		Domain:      s.Domain(),
+		Message:     order.Message(),
+	}
+}
+
+// OrderDigest 订单签名摘要的计算过程
+type OrderDigest struct {
+	DomainSeparator common.Hash `json:"domain_separator"`
+	StructHash      common.Hash `json:"struct_hash"`
+	Digest          common.Hash `json:"digest"` // keccak256("\x19\x01" ‖ domainSeparator ‖ structHash)，即签名的消息
+}
+
+// DigestTypedOrder 计算订单摘要并返回中间结果，用于排查签名不匹配
+func (s *OrderSigner) DigestTypedOrder(order *TypedOrder) OrderDigest {
+	return OrderDigest{
+		DomainSeparator: s.domainSeparator,
+		StructHash:      order.StructHash(),
+		Digest:          s.HashTypedOrder(order),
+	}
+}
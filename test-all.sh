@@ -151,6 +151,9 @@ test_go_backend() {
     log_info "运行Go单元测试..."
     go test ./... -v
     
+    log_info "运行撮合引擎并发测试（race检测）..."
+    go test -race -run TestConcurrentOrderEntry ./internal/matching
+    
     log_info "运行Go基准测试..."
     go test ./... -bench=. -benchmem
     